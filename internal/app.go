@@ -9,7 +9,9 @@ import (
 
 	"github.com/tolmachov/mcp-telegram/internal/server"
 	"github.com/tolmachov/mcp-telegram/internal/summarize"
+	"github.com/tolmachov/mcp-telegram/internal/templates"
 	"github.com/tolmachov/mcp-telegram/internal/tgclient"
+	"github.com/tolmachov/mcp-telegram/internal/tgdata"
 )
 
 // Version contains semantic version number of application.
@@ -34,17 +36,47 @@ func New(in io.Reader, out, errOut io.Writer) *cli.Command {
 					apiIDFlag(),
 					apiHashFlag(),
 					allowedPathsFlag(),
+					maxBackupsPerChatFlag(),
+					chatsIncludeArchivedFlag(),
+					chatsPaginateBlocksFlag(),
+					pinnedScopeFlag(),
+					messageTemplatesFlag(),
+					readOnlyFlag(),
+					enabledToolsFlag(),
+					disabledToolsFlag(),
+					confirmDestructiveFlag(),
+					rpsFlag(),
+					floodMaxWaitFlag(),
+					transportFlag(),
+					listenAddrFlag(),
+					httpTokenFlag(),
 					summarizeProviderFlag(),
 					summarizeModelFlag(),
 					ollamaURLFlag(),
 					geminiAPIKeyFlag(),
 					anthropicAPIKeyFlag(),
+					openAIAPIKeyFlag(),
+					openAIBaseURLFlag(),
 					summarizeBatchTokensFlag(),
+					summarizeMergeGapFlag(),
+					summarizeRequestTimeoutFlag(),
+					summarizeDeadlineFlag(),
+					summarizeRetryAttemptsFlag(),
+					summarizeRetryBaseDelayFlag(),
+					embedProviderFlag(),
+					embedModelFlag(),
+					embedURLFlag(),
+					embedAPIKeyFlag(),
+					transcribeProviderFlag(),
+					transcribeModelFlag(),
+					transcribeURLFlag(),
+					transcribeAPIKeyFlag(),
 				},
 				Action: func(ctx context.Context, cmd *cli.Command) error {
 					cfg := &tgclient.Config{
-						APIID:   cmd.Int(flagAPIID),
-						APIHash: cmd.String(flagAPIHash),
+						APIID:        cmd.Int(flagAPIID),
+						APIHash:      cmd.String(flagAPIHash),
+						FloodMaxWait: cmd.Duration(flagFloodMaxWait),
 					}
 					allowedPaths := cmd.StringSlice(flagAllowedPaths)
 					summarizeCfg := summarize.Config{
@@ -53,9 +85,55 @@ func New(in io.Reader, out, errOut io.Writer) *cli.Command {
 						OllamaURL:       cmd.String(flagOllamaURL),
 						GeminiAPIKey:    cmd.String(flagGeminiAPIKey),
 						AnthropicAPIKey: cmd.String(flagAnthropicAPIKey),
+						OpenAIAPIKey:    cmd.String(flagOpenAIAPIKey),
+						OpenAIBaseURL:   cmd.String(flagOpenAIBaseURL),
 						BatchTokens:     cmd.Int(flagSummarizeBatchTokens),
+						MergeGap:        cmd.Duration(flagSummarizeMergeGap),
+						RequestTimeout:  cmd.Duration(flagSummarizeReqTimeout),
+						Deadline:        cmd.Duration(flagSummarizeDeadline),
+						RetryAttempts:   cmd.Int(flagSummarizeRetries),
+						RetryBaseDelay:  cmd.Duration(flagSummarizeRetryDelay),
+						EmbedProvider:   summarize.EmbedProviderName(cmd.String(flagEmbedProvider)),
+						EmbedModel:      cmd.String(flagEmbedModel),
+						EmbedURL:        cmd.String(flagEmbedURL),
+						EmbedAPIKey:     cmd.String(flagEmbedAPIKey),
+
+						TranscribeProvider: summarize.TranscribeProviderName(cmd.String(flagTranscribeProvider)),
+						TranscribeModel:    cmd.String(flagTranscribeModel),
+						TranscribeURL:      cmd.String(flagTranscribeURL),
+						TranscribeAPIKey:   cmd.String(flagTranscribeAPIKey),
+					}
+					templateStore := templates.NewStore(cmd.String(flagMessageTemplates))
+					problems, err := templateStore.Load()
+					if err != nil {
+						return err
+					}
+					for _, p := range problems {
+						_, _ = fmt.Fprintf(cmd.Root().ErrWriter, "Warning: skipping %v\n", p)
 					}
-					srv, err := server.New(cfg, Version, allowedPaths, summarizeCfg, cmd.Root().Reader, cmd.Root().Writer, cmd.Root().ErrWriter)
+					srv, err := server.New(cfg, server.Options{
+						Version:              Version,
+						AllowedPaths:         allowedPaths,
+						MaxBackupsPerChat:    cmd.Int(flagMaxBackupsPerChat),
+						ChatsIncludeArchived: cmd.Bool(flagChatsIncludeArchived),
+						ChatsPaginateBlocks:  cmd.Bool(flagChatsPaginateBlocks),
+						PinnedScope:          tgdata.PinnedScope(cmd.String(flagPinnedScope)),
+						Templates:            templateStore,
+						Summarize:            summarizeCfg,
+						ReadOnly:             cmd.Bool(flagReadOnly),
+						EnabledTools:         cmd.StringSlice(flagEnabledTools),
+						DisabledTools:        cmd.StringSlice(flagDisabledTools),
+						ConfirmDestructive:   cmd.Bool(flagConfirmDestructive),
+						RPS:                  cmd.Int(flagRPS),
+						Transport: server.TransportConfig{
+							Kind:       cmd.String(flagTransport),
+							ListenAddr: cmd.String(flagListenAddr),
+							Token:      cmd.String(flagHTTPToken),
+						},
+						Stdin:  cmd.Root().Reader,
+						Stdout: cmd.Root().Writer,
+						ErrOut: cmd.Root().ErrWriter,
+					})
 					if err != nil {
 						return err
 					}
@@ -69,6 +147,8 @@ func New(in io.Reader, out, errOut io.Writer) *cli.Command {
 					apiIDFlag(),
 					apiHashFlag(),
 					phoneFlag(),
+					codeFromFlag(),
+					codeTimeoutFlag(),
 				},
 				Action: func(ctx context.Context, cmd *cli.Command) error {
 					phone := cmd.String(flagPhone)
@@ -79,7 +159,11 @@ func New(in io.Reader, out, errOut io.Writer) *cli.Command {
 						APIID:   cmd.Int(flagAPIID),
 						APIHash: cmd.String(flagAPIHash),
 					}
-					return tgclient.Login(ctx, cfg, phone)
+					return tgclient.Login(ctx, cfg, phone, tgclient.LoginOptions{
+						CodeFrom:    cmd.String(flagCodeFrom),
+						CodeTimeout: cmd.Duration(flagCodeTimeout),
+						Stdin:       cmd.Root().Reader,
+					})
 				},
 			},
 			{
@@ -0,0 +1,179 @@
+// Package autoreply answers incoming private messages with a configured away message,
+// at most once per sender within a cooldown.
+package autoreply
+
+import (
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/tolmachov/mcp-telegram/internal/statefile"
+)
+
+// DefaultCooldown is the minimum time between two auto-replies to the same sender.
+const DefaultCooldown = 24 * time.Hour
+
+// Settings configures the auto-reply.
+type Settings struct {
+	Message   string        `json:"message"`
+	Whitelist []int64       `json:"whitelist,omitempty"` // When set, only these users get replies
+	Blacklist []int64       `json:"blacklist,omitempty"` // These users never get replies
+	ExpiresAt time.Time     `json:"expires_at,omitzero"` // Zero means no expiry
+	Cooldown  time.Duration `json:"cooldown"`
+}
+
+// Allows reports whether userID passes the whitelist and blacklist.
+func (s Settings) Allows(userID int64) bool {
+	if slices.Contains(s.Blacklist, userID) {
+		return false
+	}
+	return len(s.Whitelist) == 0 || slices.Contains(s.Whitelist, userID)
+}
+
+// Expired reports whether the settings have expired at now.
+func (s Settings) Expired(now time.Time) bool {
+	return !s.ExpiresAt.IsZero() && !now.Before(s.ExpiresAt)
+}
+
+// ShouldReply reports whether userID should get an auto-reply at now, given when
+// they last got one (zero if never). A nil settings means auto-reply is disabled.
+func ShouldReply(s *Settings, userID int64, lastSent, now time.Time) bool {
+	if s == nil || s.Expired(now) || !s.Allows(userID) {
+		return false
+	}
+	return lastSent.IsZero() || now.Sub(lastSent) >= s.cooldown()
+}
+
+// cooldown returns the configured cooldown, or DefaultCooldown when unset.
+func (s Settings) cooldown() time.Duration {
+	if s.Cooldown <= 0 {
+		return DefaultCooldown
+	}
+	return s.Cooldown
+}
+
+// Reply records an auto-reply sent to a user.
+type Reply struct {
+	UserID int64     `json:"user_id"`
+	SentAt time.Time `json:"sent_at"`
+}
+
+// Status describes the current auto-reply state.
+type Status struct {
+	Enabled  bool      `json:"enabled"`
+	Expired  bool      `json:"expired,omitempty"`
+	Settings *Settings `json:"settings,omitempty"`
+	Replies  []Reply   `json:"recent_replies"` // Replies within the cooldown, newest first
+}
+
+// state is the on-disk format of the store.
+type state struct {
+	Settings *Settings           `json:"settings,omitempty"`
+	Sent     map[int64]time.Time `json:"sent,omitempty"`
+}
+
+// DefaultStatePath returns the default auto-reply state file based on the OS.
+func DefaultStatePath() string {
+	return statefile.DefaultPath("autoreply.json")
+}
+
+// Store keeps the auto-reply settings and the ledger of sent replies in a JSON file,
+// so restarts don't reply to the same people again. It is safe for concurrent use.
+type Store struct {
+	path string
+
+	mu    sync.Mutex
+	state state
+}
+
+// OpenStore loads the store from path; a missing file means auto-reply is disabled.
+func OpenStore(path string) (*Store, error) {
+	s := &Store{path: path, state: state{Sent: make(map[int64]time.Time)}}
+	if err := statefile.Load(path, "auto-reply state", &s.state); err != nil {
+		return nil, err
+	}
+	if s.state.Sent == nil {
+		s.state.Sent = make(map[int64]time.Time)
+	}
+	return s, nil
+}
+
+// Enable replaces the settings. The ledger is kept, so people who already got a
+// reply within the cooldown don't get another one.
+func (s *Store) Enable(settings Settings) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.state.Settings = &settings
+	return s.save()
+}
+
+// Disable turns auto-reply off.
+func (s *Store) Disable() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.state.Settings = nil
+	return s.save()
+}
+
+// Status returns the current settings and the replies sent within the cooldown.
+func (s *Store) Status(now time.Time) Status {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	st := Status{Replies: []Reply{}}
+	if s.state.Settings == nil {
+		return st
+	}
+	settings := *s.state.Settings
+	st.Settings = &settings
+	st.Expired = settings.Expired(now)
+	st.Enabled = !st.Expired
+
+	for userID, sentAt := range s.state.Sent {
+		if now.Sub(sentAt) < settings.cooldown() {
+			st.Replies = append(st.Replies, Reply{UserID: userID, SentAt: sentAt})
+		}
+	}
+	sort.Slice(st.Replies, func(i, j int) bool { return st.Replies[i].SentAt.After(st.Replies[j].SentAt) })
+	return st
+}
+
+// Claim decides whether userID gets an auto-reply at now and, if so, records it
+// before the caller sends, so concurrent updates can't reply twice. It returns the
+// message to send. Release undoes a claim whose send failed.
+func (s *Store) Claim(userID int64, now time.Time) (message string, ok bool, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !ShouldReply(s.state.Settings, userID, s.state.Sent[userID], now) {
+		return "", false, nil
+	}
+	previous, hadPrevious := s.state.Sent[userID]
+	s.state.Sent[userID] = now
+	if err := s.save(); err != nil {
+		if hadPrevious {
+			s.state.Sent[userID] = previous
+		} else {
+			delete(s.state.Sent, userID)
+		}
+		return "", false, err
+	}
+	return s.state.Settings.Message, true, nil
+}
+
+// Release forgets the claim made for userID at sentAt, if it is still the latest one.
+func (s *Store) Release(userID int64, sentAt time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.state.Sent[userID].Equal(sentAt) {
+		return nil
+	}
+	delete(s.state.Sent, userID)
+	return s.save()
+}
+
+// save writes the state to disk atomically. The caller must hold s.mu.
+func (s *Store) save() error {
+	return statefile.Save(s.path, "auto-reply state", s.state)
+}
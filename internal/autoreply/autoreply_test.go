@@ -0,0 +1,129 @@
+package autoreply
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestShouldReply(t *testing.T) {
+	now := time.Date(2024, 7, 1, 12, 0, 0, 0, time.UTC)
+	base := Settings{Message: "Away", Cooldown: 24 * time.Hour}
+	with := func(f func(*Settings)) *Settings {
+		s := base
+		f(&s)
+		return &s
+	}
+
+	tests := []struct {
+		name     string
+		settings *Settings
+		userID   int64
+		lastSent time.Time
+		want     bool
+	}{
+		{"disabled", nil, 1, time.Time{}, false},
+		{"first message", &base, 1, time.Time{}, true},
+		{"within cooldown", &base, 1, now.Add(-23 * time.Hour), false},
+		{"cooldown elapsed", &base, 1, now.Add(-24 * time.Hour), true},
+		{"default cooldown", with(func(s *Settings) { s.Cooldown = 0 }), 1, now.Add(-2 * time.Hour), false},
+		{"expired", with(func(s *Settings) { s.ExpiresAt = now }), 1, time.Time{}, false},
+		{"not yet expired", with(func(s *Settings) { s.ExpiresAt = now.Add(time.Minute) }), 1, time.Time{}, true},
+		{"whitelisted", with(func(s *Settings) { s.Whitelist = []int64{1, 2} }), 2, time.Time{}, true},
+		{"not whitelisted", with(func(s *Settings) { s.Whitelist = []int64{1, 2} }), 3, time.Time{}, false},
+		{"blacklisted", with(func(s *Settings) { s.Blacklist = []int64{1} }), 1, time.Time{}, false},
+		{"blacklist wins over whitelist", with(func(s *Settings) {
+			s.Whitelist = []int64{1}
+			s.Blacklist = []int64{1}
+		}), 1, time.Time{}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ShouldReply(tt.settings, tt.userID, tt.lastSent, now); got != tt.want {
+				t.Errorf("ShouldReply() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStoreClaimPersists(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state", "autoreply.json")
+	now := time.Date(2024, 7, 1, 12, 0, 0, 0, time.UTC)
+
+	store, err := OpenStore(path)
+	if err != nil {
+		t.Fatalf("OpenStore: %v", err)
+	}
+	if _, ok, _ := store.Claim(1, now); ok {
+		t.Fatal("claim succeeded while disabled")
+	}
+	if err := store.Enable(Settings{Message: "Away", Cooldown: time.Hour}); err != nil {
+		t.Fatalf("Enable: %v", err)
+	}
+
+	msg, ok, err := store.Claim(1, now)
+	if err != nil || !ok || msg != "Away" {
+		t.Fatalf("Claim = %q, %v, %v", msg, ok, err)
+	}
+	if _, ok, _ := store.Claim(1, now.Add(time.Minute)); ok {
+		t.Error("second claim within cooldown succeeded")
+	}
+
+	// A restart must not reply to the same sender again
+	reopened, err := OpenStore(path)
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	if _, ok, _ := reopened.Claim(1, now.Add(time.Minute)); ok {
+		t.Error("claim after restart succeeded within cooldown")
+	}
+	if _, ok, _ := reopened.Claim(1, now.Add(time.Hour)); !ok {
+		t.Error("claim after cooldown failed")
+	}
+
+	st := reopened.Status(now.Add(time.Hour))
+	if !st.Enabled || len(st.Replies) != 1 || st.Replies[0].UserID != 1 {
+		t.Errorf("status = %+v", st)
+	}
+}
+
+func TestStoreRelease(t *testing.T) {
+	store, err := OpenStore(filepath.Join(t.TempDir(), "autoreply.json"))
+	if err != nil {
+		t.Fatalf("OpenStore: %v", err)
+	}
+	now := time.Date(2024, 7, 1, 12, 0, 0, 0, time.UTC)
+	if err := store.Enable(Settings{Message: "Away"}); err != nil {
+		t.Fatalf("Enable: %v", err)
+	}
+	if _, ok, _ := store.Claim(1, now); !ok {
+		t.Fatal("claim failed")
+	}
+	if err := store.Release(1, now); err != nil {
+		t.Fatalf("Release: %v", err)
+	}
+	if _, ok, _ := store.Claim(1, now); !ok {
+		t.Error("claim after release failed")
+	}
+}
+
+func TestStoreDisable(t *testing.T) {
+	store, err := OpenStore(filepath.Join(t.TempDir(), "autoreply.json"))
+	if err != nil {
+		t.Fatalf("OpenStore: %v", err)
+	}
+	now := time.Date(2024, 7, 1, 12, 0, 0, 0, time.UTC)
+	if err := store.Enable(Settings{Message: "Away", ExpiresAt: now.Add(-time.Minute)}); err != nil {
+		t.Fatalf("Enable: %v", err)
+	}
+	if st := store.Status(now); st.Enabled || !st.Expired {
+		t.Errorf("expired status = %+v", st)
+	}
+	if err := store.Disable(); err != nil {
+		t.Fatalf("Disable: %v", err)
+	}
+	if st := store.Status(now); st.Enabled || st.Settings != nil {
+		t.Errorf("disabled status = %+v", st)
+	}
+}
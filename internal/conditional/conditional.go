@@ -0,0 +1,323 @@
+// Package conditional sends messages at a deadline unless a condition cancels them,
+// e.g. a reminder that is only sent if nobody replied in the chat by then.
+package conditional
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/tolmachov/mcp-telegram/internal/statefile"
+)
+
+// Condition decides at the deadline whether a conditional message is sent.
+type Condition string
+
+// ConditionNoIncomingSince sends the message only if no incoming message arrived in
+// the chat after the reference message.
+const ConditionNoIncomingSince Condition = "no_incoming_since"
+
+// ValidateCondition checks if the condition name is valid.
+func ValidateCondition(name string) error {
+	switch Condition(name) {
+	case ConditionNoIncomingSince:
+		return nil
+	default:
+		return fmt.Errorf("invalid condition: %q (must be 'no_incoming_since')", name)
+	}
+}
+
+// Status is the lifecycle state of a conditional message.
+type Status string
+
+const (
+	StatusPending  Status = "pending"
+	StatusSent     Status = "sent"
+	StatusCanceled Status = "canceled"
+	StatusFailed   Status = "failed"
+)
+
+// maxResolved is how many sent, canceled or failed messages are kept for listing.
+const maxResolved = 50
+
+// Message is a message waiting for its deadline, or the record of how it was resolved.
+type Message struct {
+	ID                 int       `json:"id"`
+	ChatID             int64     `json:"chat_id"`
+	Text               string    `json:"text"`
+	Deadline           time.Time `json:"deadline"`
+	Condition          Condition `json:"condition"`
+	ReferenceMessageID int       `json:"reference_message_id"`
+	CreatedAt          time.Time `json:"created_at"`
+
+	Status        Status    `json:"status"`
+	Reason        string    `json:"reason,omitempty"` // Why the message was canceled or failed
+	ResolvedAt    time.Time `json:"resolved_at,omitzero"`
+	SentMessageID int       `json:"sent_message_id,omitempty"`
+}
+
+// Due reports whether a pending message's deadline has been reached at now.
+func (m Message) Due(now time.Time) bool {
+	return m.Status == StatusPending && !now.Before(m.Deadline)
+}
+
+// IncomingChecker returns the ID of an incoming message in a chat newer than afterID,
+// or 0 when there is none.
+type IncomingChecker func(ctx context.Context, chatID int64, afterID int) (int, error)
+
+// Sender sends text to a chat and returns the new message ID.
+type Sender func(ctx context.Context, chatID int64, text string) (int, error)
+
+// Evaluate decides whether a due message is sent. It returns false with the reason
+// when the condition cancels it.
+func Evaluate(ctx context.Context, m Message, incoming IncomingChecker) (send bool, reason string, err error) {
+	switch m.Condition {
+	case ConditionNoIncomingSince:
+		id, err := incoming(ctx, m.ChatID, m.ReferenceMessageID)
+		if err != nil {
+			return false, "", err
+		}
+		if id != 0 {
+			return false, fmt.Sprintf("incoming message %d arrived after message %d", id, m.ReferenceMessageID), nil
+		}
+		return true, "", nil
+	default:
+		return false, "", fmt.Errorf("unknown condition %q", m.Condition)
+	}
+}
+
+// state is the on-disk format of the store.
+type state struct {
+	NextID   int       `json:"next_id"`
+	Messages []Message `json:"messages,omitempty"`
+}
+
+// DefaultStatePath returns the default conditional messages state file based on the OS.
+func DefaultStatePath() string {
+	return statefile.DefaultPath("conditional.json")
+}
+
+// ErrNotPending is returned when canceling a message that is unknown or already resolved.
+var ErrNotPending = errors.New("no pending conditional message with this ID")
+
+// Store keeps conditional messages in a JSON file, so they survive restarts.
+// It is safe for concurrent use.
+type Store struct {
+	path string
+
+	mu    sync.Mutex
+	state state
+}
+
+// OpenStore loads the store from path; a missing file means no messages.
+func OpenStore(path string) (*Store, error) {
+	s := &Store{path: path, state: state{NextID: 1}}
+	if err := statefile.Load(path, "conditional messages state", &s.state); err != nil {
+		return nil, err
+	}
+	if s.state.NextID < 1 {
+		s.state.NextID = 1
+	}
+	return s, nil
+}
+
+// Add stores a new pending message and returns it with its assigned ID.
+func (s *Store) Add(m Message) (Message, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	m.ID = s.state.NextID
+	m.Status = StatusPending
+	s.state.NextID++
+	s.state.Messages = append(s.state.Messages, m)
+	if err := s.save(); err != nil {
+		s.state.NextID--
+		s.state.Messages = s.state.Messages[:len(s.state.Messages)-1]
+		return Message{}, err
+	}
+	return m, nil
+}
+
+// List returns pending messages by deadline, followed by resolved ones (newest first)
+// when includeResolved is set.
+func (s *Store) List(includeResolved bool) []Message {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var pending, resolved []Message
+	for _, m := range s.state.Messages {
+		if m.Status == StatusPending {
+			pending = append(pending, m)
+		} else if includeResolved {
+			resolved = append(resolved, m)
+		}
+	}
+	sort.SliceStable(pending, func(i, j int) bool { return pending[i].Deadline.Before(pending[j].Deadline) })
+	sort.SliceStable(resolved, func(i, j int) bool { return resolved[i].ResolvedAt.After(resolved[j].ResolvedAt) })
+	return append(pending, resolved...)
+}
+
+// Due returns the pending messages whose deadline has been reached at now.
+func (s *Store) Due(now time.Time) []Message {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var due []Message
+	for _, m := range s.state.Messages {
+		if m.Due(now) {
+			due = append(due, m)
+		}
+	}
+	return due
+}
+
+// Cancel cancels a pending message with the given reason.
+func (s *Store) Cancel(id int, reason string, now time.Time) error {
+	return s.Resolve(id, StatusCanceled, reason, 0, now)
+}
+
+// Resolve records how a pending message ended. Only the newest resolved messages are kept.
+func (s *Store) Resolve(id int, status Status, reason string, sentMessageID int, now time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i := slices.IndexFunc(s.state.Messages, func(m Message) bool { return m.ID == id })
+	if i < 0 || s.state.Messages[i].Status != StatusPending {
+		return ErrNotPending
+	}
+	previous := s.state.Messages[i]
+	m := &s.state.Messages[i]
+	m.Status = status
+	m.Reason = reason
+	m.SentMessageID = sentMessageID
+	m.ResolvedAt = now
+
+	kept := s.state.Messages
+	s.state.Messages = pruneResolved(s.state.Messages)
+	if err := s.save(); err != nil {
+		s.state.Messages = kept
+		s.state.Messages[i] = previous
+		return err
+	}
+	return nil
+}
+
+// amend updates the outcome of an already resolved message.
+func (s *Store) amend(id int, status Status, reason string, sentMessageID int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i := slices.IndexFunc(s.state.Messages, func(m Message) bool { return m.ID == id })
+	if i < 0 {
+		return nil
+	}
+	m := &s.state.Messages[i]
+	m.Status = status
+	m.Reason = reason
+	m.SentMessageID = sentMessageID
+	return s.save()
+}
+
+// pruneResolved drops the oldest resolved messages beyond maxResolved.
+func pruneResolved(msgs []Message) []Message {
+	var resolved []time.Time
+	for _, m := range msgs {
+		if m.Status != StatusPending {
+			resolved = append(resolved, m.ResolvedAt)
+		}
+	}
+	if len(resolved) <= maxResolved {
+		return msgs
+	}
+	sort.Slice(resolved, func(i, j int) bool { return resolved[i].After(resolved[j]) })
+	cutoff := resolved[maxResolved-1]
+
+	kept := make([]Message, 0, len(msgs))
+	for _, m := range msgs {
+		if m.Status == StatusPending || !m.ResolvedAt.Before(cutoff) {
+			kept = append(kept, m)
+		}
+	}
+	return kept
+}
+
+// save writes the state to disk atomically. The caller must hold s.mu.
+func (s *Store) save() error {
+	return statefile.Save(s.path, "conditional messages state", s.state)
+}
+
+// DefaultInterval is how often the scheduler checks for due messages.
+const DefaultInterval = 30 * time.Second
+
+// checkRetryWindow is how long after the deadline a condition that can't be checked
+// is retried before the message is marked failed.
+const checkRetryWindow = time.Hour
+
+// Scheduler resolves due conditional messages: it evaluates their condition and
+// either sends them or cancels them with the reason.
+type Scheduler struct {
+	store    *Store
+	incoming IncomingChecker
+	send     Sender
+	now      func() time.Time
+}
+
+// NewScheduler creates a Scheduler. now is the clock, time.Now when nil.
+func NewScheduler(store *Store, incoming IncomingChecker, send Sender, now func() time.Time) *Scheduler {
+	if now == nil {
+		now = time.Now
+	}
+	return &Scheduler{store: store, incoming: incoming, send: send, now: now}
+}
+
+// Run checks for due messages every interval until ctx is done.
+func (s *Scheduler) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		s.RunDue(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunDue resolves every message that is due now. Messages whose condition could not
+// be checked stay pending and are retried on later runs for a while; failed sends are
+// recorded and not retried, so a message is never sent twice.
+func (s *Scheduler) RunDue(ctx context.Context) {
+	for _, m := range s.store.Due(s.now()) {
+		if ctx.Err() != nil {
+			return
+		}
+
+		send, reason, err := Evaluate(ctx, m, s.incoming)
+		if err != nil {
+			if s.now().Sub(m.Deadline) > checkRetryWindow {
+				_ = s.store.Resolve(m.ID, StatusFailed, fmt.Sprintf("checking the condition failed: %v", err), 0, s.now())
+			}
+			continue
+		}
+		if !send {
+			_ = s.store.Cancel(m.ID, reason, s.now())
+			continue
+		}
+
+		// Resolve before sending, so a concurrent cancel or a crash can't lead to a second send
+		if err := s.store.Resolve(m.ID, StatusSent, "", 0, s.now()); err != nil {
+			continue
+		}
+		sentID, err := s.send(ctx, m.ChatID, m.Text)
+		if err != nil {
+			_ = s.store.amend(m.ID, StatusFailed, fmt.Sprintf("sending failed: %v", err), 0)
+			continue
+		}
+		_ = s.store.amend(m.ID, StatusSent, "", sentID)
+	}
+}
@@ -0,0 +1,228 @@
+package conditional
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// fakeClock is a settable clock for the scheduler.
+type fakeClock struct{ now time.Time }
+
+func (c *fakeClock) Now() time.Time { return c.now }
+
+// fakeChats records sends and serves incoming message IDs per chat.
+type fakeChats struct {
+	incoming map[int64]int // chat ID -> newest incoming message ID
+	checkErr error
+	sendErr  error
+	sent     []string
+}
+
+func (f *fakeChats) check(_ context.Context, chatID int64, afterID int) (int, error) {
+	if f.checkErr != nil {
+		return 0, f.checkErr
+	}
+	if id := f.incoming[chatID]; id > afterID {
+		return id, nil
+	}
+	return 0, nil
+}
+
+func (f *fakeChats) send(_ context.Context, _ int64, text string) (int, error) {
+	if f.sendErr != nil {
+		return 0, f.sendErr
+	}
+	f.sent = append(f.sent, text)
+	return 100 + len(f.sent), nil
+}
+
+func openTestStore(t *testing.T) *Store {
+	t.Helper()
+	store, err := OpenStore(filepath.Join(t.TempDir(), "state", "conditional.json"))
+	if err != nil {
+		t.Fatalf("OpenStore: %v", err)
+	}
+	return store
+}
+
+func TestEvaluate(t *testing.T) {
+	chats := &fakeChats{incoming: map[int64]int{1: 50}}
+
+	tests := []struct {
+		name     string
+		msg      Message
+		wantSend bool
+		wantErr  bool
+	}{
+		{"reply after reference", Message{ChatID: 1, Condition: ConditionNoIncomingSince, ReferenceMessageID: 40}, false, false},
+		{"no reply after reference", Message{ChatID: 1, Condition: ConditionNoIncomingSince, ReferenceMessageID: 50}, true, false},
+		{"quiet chat", Message{ChatID: 2, Condition: ConditionNoIncomingSince, ReferenceMessageID: 1}, true, false},
+		{"unknown condition", Message{ChatID: 1, Condition: "whenever"}, false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			send, reason, err := Evaluate(t.Context(), tt.msg, chats.check)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Evaluate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if send != tt.wantSend {
+				t.Errorf("Evaluate() send = %v, want %v", send, tt.wantSend)
+			}
+			if !send && !tt.wantErr && reason == "" {
+				t.Error("canceled without a reason")
+			}
+		})
+	}
+}
+
+func TestSchedulerRunDue(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2024, 7, 1, 12, 0, 0, 0, time.UTC)}
+	store := openTestStore(t)
+	chats := &fakeChats{incoming: map[int64]int{2: 11}}
+	scheduler := NewScheduler(store, chats.check, chats.send, clock.Now)
+
+	add := func(chatID int64, text string, after time.Duration) Message {
+		m, err := store.Add(Message{
+			ChatID:             chatID,
+			Text:               text,
+			Deadline:           clock.now.Add(after),
+			Condition:          ConditionNoIncomingSince,
+			ReferenceMessageID: 10,
+			CreatedAt:          clock.now,
+		})
+		if err != nil {
+			t.Fatalf("Add: %v", err)
+		}
+		return m
+	}
+	quiet := add(1, "ping Bob", time.Hour)
+	replied := add(2, "ping Alice", time.Hour)
+	later := add(3, "ping Carol", 3*time.Hour)
+
+	// Nothing is due before the deadline
+	scheduler.RunDue(t.Context())
+	if len(chats.sent) != 0 || len(store.List(false)) != 3 {
+		t.Fatalf("sent %v before any deadline", chats.sent)
+	}
+
+	clock.now = clock.now.Add(time.Hour)
+	scheduler.RunDue(t.Context())
+
+	if len(chats.sent) != 1 || chats.sent[0] != "ping Bob" {
+		t.Errorf("sent = %v, want only the message to the quiet chat", chats.sent)
+	}
+	byID := make(map[int]Message)
+	for _, m := range store.List(true) {
+		byID[m.ID] = m
+	}
+	if m := byID[quiet.ID]; m.Status != StatusSent || m.SentMessageID != 101 || !m.ResolvedAt.Equal(clock.now) {
+		t.Errorf("quiet chat message = %+v, want sent", m)
+	}
+	if m := byID[replied.ID]; m.Status != StatusCanceled || m.Reason == "" {
+		t.Errorf("replied chat message = %+v, want canceled with a reason", m)
+	}
+	if m := byID[later.ID]; m.Status != StatusPending {
+		t.Errorf("later message = %+v, want pending", m)
+	}
+
+	// Resolved messages are not sent again
+	scheduler.RunDue(t.Context())
+	if len(chats.sent) != 1 {
+		t.Errorf("sent = %v after a second run", chats.sent)
+	}
+}
+
+func TestSchedulerFailures(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2024, 7, 1, 12, 0, 0, 0, time.UTC)}
+	store := openTestStore(t)
+	chats := &fakeChats{checkErr: errors.New("network down")}
+	scheduler := NewScheduler(store, chats.check, chats.send, clock.Now)
+
+	m, err := store.Add(Message{ChatID: 1, Text: "hi", Deadline: clock.now, Condition: ConditionNoIncomingSince})
+	if err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+
+	// A failed check is retried on later runs
+	scheduler.RunDue(t.Context())
+	if got := store.List(false); len(got) != 1 || got[0].Status != StatusPending {
+		t.Fatalf("after failed check = %+v, want still pending", got)
+	}
+
+	// ...until the retry window has passed
+	clock.now = clock.now.Add(checkRetryWindow + time.Minute)
+	scheduler.RunDue(t.Context())
+	if got := store.List(true); got[0].Status != StatusFailed {
+		t.Errorf("after retry window = %+v, want failed", got[0])
+	}
+
+	// A failed send is recorded and not retried
+	chats.checkErr = nil
+	chats.sendErr = errors.New("PEER_ID_INVALID")
+	m, err = store.Add(Message{ChatID: 1, Text: "hi again", Deadline: clock.now, Condition: ConditionNoIncomingSince})
+	if err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+	scheduler.RunDue(t.Context())
+	scheduler.RunDue(t.Context())
+	for _, got := range store.List(true) {
+		if got.ID == m.ID && (got.Status != StatusFailed || got.Reason == "") {
+			t.Errorf("after failed send = %+v, want failed with a reason", got)
+		}
+	}
+}
+
+func TestStoreCancelAndPersist(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "conditional.json")
+	now := time.Date(2024, 7, 1, 12, 0, 0, 0, time.UTC)
+
+	store, err := OpenStore(path)
+	if err != nil {
+		t.Fatalf("OpenStore: %v", err)
+	}
+	first, _ := store.Add(Message{ChatID: 1, Text: "a", Deadline: now.Add(2 * time.Hour)})
+	second, _ := store.Add(Message{ChatID: 2, Text: "b", Deadline: now.Add(time.Hour)})
+
+	if err := store.Cancel(first.ID, "no longer needed", now); err != nil {
+		t.Fatalf("Cancel: %v", err)
+	}
+	if err := store.Cancel(first.ID, "again", now); !errors.Is(err, ErrNotPending) {
+		t.Errorf("second Cancel error = %v, want ErrNotPending", err)
+	}
+
+	reopened, err := OpenStore(path)
+	if err != nil {
+		t.Fatalf("reopening: %v", err)
+	}
+	pending := reopened.List(false)
+	if len(pending) != 1 || pending[0].ID != second.ID {
+		t.Errorf("pending after reopen = %+v, want only message %d", pending, second.ID)
+	}
+	all := reopened.List(true)
+	if len(all) != 2 || all[1].Status != StatusCanceled || all[1].Reason != "no longer needed" {
+		t.Errorf("all after reopen = %+v", all)
+	}
+	if third, _ := reopened.Add(Message{ChatID: 3}); third.ID != second.ID+1 {
+		t.Errorf("new ID after reopen = %d, want %d", third.ID, second.ID+1)
+	}
+}
+
+func TestPruneResolved(t *testing.T) {
+	now := time.Date(2024, 7, 1, 12, 0, 0, 0, time.UTC)
+	msgs := []Message{{ID: 0, Status: StatusPending}}
+	for i := 1; i <= maxResolved+5; i++ {
+		msgs = append(msgs, Message{ID: i, Status: StatusSent, ResolvedAt: now.Add(time.Duration(i) * time.Minute)})
+	}
+
+	kept := pruneResolved(msgs)
+	if len(kept) != maxResolved+1 {
+		t.Fatalf("kept %d messages, want %d", len(kept), maxResolved+1)
+	}
+	if kept[0].ID != 0 || kept[1].ID != 6 {
+		t.Errorf("kept IDs start %d, %d; want the pending message and then the newest resolved ones", kept[0].ID, kept[1].ID)
+	}
+}
@@ -5,7 +5,11 @@ import (
 
 	"github.com/urfave/cli/v3"
 
+	"github.com/tolmachov/mcp-telegram/internal/messages"
+	"github.com/tolmachov/mcp-telegram/internal/server"
 	"github.com/tolmachov/mcp-telegram/internal/summarize"
+	"github.com/tolmachov/mcp-telegram/internal/tgclient"
+	"github.com/tolmachov/mcp-telegram/internal/tgdata"
 	"github.com/tolmachov/mcp-telegram/internal/tools"
 )
 
@@ -13,13 +17,44 @@ const (
 	flagAPIID                = "api-id"
 	flagAPIHash              = "api-hash"
 	flagAllowedPaths         = "allowed-paths"
+	flagMaxBackupsPerChat    = "max-backup-files-per-chat"
+	flagChatsIncludeArchived = "chats-include-archived"
+	flagChatsPaginateBlocks  = "chats-paginate-blocks"
+	flagPinnedScope          = "pinned-scope"
+	flagMessageTemplates     = "message-templates"
+	flagReadOnly             = "read-only"
+	flagEnabledTools         = "enabled-tools"
+	flagDisabledTools        = "disabled-tools"
+	flagConfirmDestructive   = "confirm-destructive"
+	flagRPS                  = "rps"
+	flagFloodMaxWait         = "flood-max-wait"
+	flagTransport            = "transport"
+	flagListenAddr           = "listen-addr"
+	flagHTTPToken            = "http-token" //nolint:gosec // flag name, not a credential
 	flagPhone                = "phone"
+	flagCodeFrom             = "code-from"
+	flagCodeTimeout          = "code-timeout"
 	flagSummarizeProvider    = "summarize-provider"
 	flagSummarizeModel       = "summarize-model"
 	flagOllamaURL            = "ollama-url"
 	flagGeminiAPIKey         = "gemini-api-key"    //nolint:gosec // flag name, not a credential
 	flagAnthropicAPIKey      = "anthropic-api-key" //nolint:gosec // flag name, not a credential
+	flagOpenAIAPIKey         = "openai-api-key"    //nolint:gosec // flag name, not a credential
+	flagOpenAIBaseURL        = "openai-base-url"
 	flagSummarizeBatchTokens = "summarize-batch-tokens"
+	flagSummarizeMergeGap    = "summarize-merge-gap"
+	flagSummarizeReqTimeout  = "summarize-request-timeout"
+	flagSummarizeDeadline    = "summarize-deadline"
+	flagSummarizeRetries     = "summarize-retry-attempts"
+	flagSummarizeRetryDelay  = "summarize-retry-base-delay"
+	flagEmbedProvider        = "embed-provider"
+	flagEmbedModel           = "embed-model"
+	flagEmbedURL             = "embed-url"
+	flagEmbedAPIKey          = "embed-api-key" //nolint:gosec // flag name, not a credential
+	flagTranscribeProvider   = "transcribe-provider"
+	flagTranscribeModel      = "transcribe-model"
+	flagTranscribeURL        = "transcribe-url"
+	flagTranscribeAPIKey     = "transcribe-api-key" //nolint:gosec // flag name, not a credential
 )
 
 func apiIDFlag() *cli.IntFlag {
@@ -49,6 +84,132 @@ func allowedPathsFlag() *cli.StringSliceFlag {
 	}
 }
 
+func messageTemplatesFlag() *cli.StringFlag {
+	return &cli.StringFlag{
+		Name:    flagMessageTemplates,
+		Usage:   "Path to a JSON file with outgoing message templates",
+		Sources: cli.EnvVars("TELEGRAM_MESSAGE_TEMPLATES"),
+	}
+}
+
+func maxBackupsPerChatFlag() *cli.IntFlag {
+	return &cli.IntFlag{
+		Name:    flagMaxBackupsPerChat,
+		Usage:   "Maximum auto-named backups kept per chat; older ones are deleted after each backup (0 = unlimited)",
+		Sources: cli.EnvVars("TELEGRAM_MAX_BACKUP_FILES_PER_CHAT"),
+	}
+}
+
+func chatsIncludeArchivedFlag() *cli.BoolFlag {
+	return &cli.BoolFlag{
+		Name:    flagChatsIncludeArchived,
+		Value:   true,
+		Usage:   "Include archived chats in the telegram://chats resource",
+		Sources: cli.EnvVars("TELEGRAM_CHATS_INCLUDE_ARCHIVED"),
+	}
+}
+
+func chatsPaginateBlocksFlag() *cli.BoolFlag {
+	return &cli.BoolFlag{
+		Name:    flagChatsPaginateBlocks,
+		Usage:   "Split the telegram://chats resource into several contents of bounded size",
+		Sources: cli.EnvVars("TELEGRAM_CHATS_PAGINATE_BLOCKS"),
+	}
+}
+
+func pinnedScopeFlag() *cli.StringFlag {
+	return &cli.StringFlag{
+		Name:    flagPinnedScope,
+		Value:   string(tgdata.PinnedScopeAll),
+		Usage:   "Pinned chats exposed as resources: 'all' (main list, archive and folders) or 'main' (main list only)",
+		Sources: cli.EnvVars("TELEGRAM_PINNED_SCOPE"),
+		Action: func(_ context.Context, _ *cli.Command, value string) error {
+			return tgdata.ValidatePinnedScope(value)
+		},
+	}
+}
+
+func readOnlyFlag() *cli.BoolFlag {
+	return &cli.BoolFlag{
+		Name:    flagReadOnly,
+		Usage:   "Only expose tools that read from Telegram: sending, editing, deleting, marking read and muting are disabled",
+		Sources: cli.EnvVars("TELEGRAM_READ_ONLY"),
+	}
+}
+
+func enabledToolsFlag() *cli.StringSliceFlag {
+	return &cli.StringSliceFlag{
+		Name:    flagEnabledTools,
+		Usage:   "Comma-separated tools to expose, leaving out all others (default: all tools)",
+		Sources: cli.EnvVars("TELEGRAM_ENABLED_TOOLS"),
+		Config:  cli.StringConfig{TrimSpace: true},
+	}
+}
+
+func disabledToolsFlag() *cli.StringSliceFlag {
+	return &cli.StringSliceFlag{
+		Name:    flagDisabledTools,
+		Usage:   "Comma-separated tools to leave out",
+		Sources: cli.EnvVars("TELEGRAM_DISABLED_TOOLS"),
+		Config:  cli.StringConfig{TrimSpace: true},
+	}
+}
+
+func confirmDestructiveFlag() *cli.BoolFlag {
+	return &cli.BoolFlag{
+		Name:    flagConfirmDestructive,
+		Usage:   "Ask the user through the MCP client to confirm each call of a tool that sends, changes or deletes something, including mark_read and save_draft",
+		Sources: cli.EnvVars("TELEGRAM_CONFIRM_DESTRUCTIVE"),
+	}
+}
+
+func rpsFlag() *cli.IntFlag {
+	return &cli.IntFlag{
+		Name:    flagRPS,
+		Value:   tgclient.DefaultRPS,
+		Usage:   "Maximum Telegram requests per second, shared by all tools, resources and incoming update handling (0 = unlimited)",
+		Sources: cli.EnvVars("TELEGRAM_RPS"),
+	}
+}
+
+func floodMaxWaitFlag() *cli.DurationFlag {
+	return &cli.DurationFlag{
+		Name:    flagFloodMaxWait,
+		Value:   tgclient.DefaultFloodMaxWait,
+		Usage:   "Longest FLOOD_WAIT a Telegram request waits out before failing",
+		Sources: cli.EnvVars("TELEGRAM_FLOOD_MAX_WAIT"),
+	}
+}
+
+func transportFlag() *cli.StringFlag {
+	return &cli.StringFlag{
+		Name:    flagTransport,
+		Value:   server.TransportStdio,
+		Usage:   "Transport to MCP clients: 'stdio' (a single client) or 'http' (streamable HTTP on --listen-addr, for any number of clients)",
+		Sources: cli.EnvVars("TELEGRAM_TRANSPORT"),
+		Action: func(_ context.Context, _ *cli.Command, value string) error {
+			return server.ValidateTransport(value)
+		},
+	}
+}
+
+func listenAddrFlag() *cli.StringFlag {
+	return &cli.StringFlag{
+		Name:    flagListenAddr,
+		Value:   server.DefaultListenAddr,
+		Usage:   "Address the http transport listens on",
+		Sources: cli.EnvVars("TELEGRAM_LISTEN_ADDR"),
+	}
+}
+
+func httpTokenFlag() *cli.StringFlag {
+	return &cli.StringFlag{
+		Name:    flagHTTPToken,
+		Usage:   "Bearer token the http transport requires on every request",
+		Sources: cli.EnvVars("TELEGRAM_HTTP_TOKEN"),
+	}
+}
+
 func phoneFlag() *cli.StringFlag {
 	return &cli.StringFlag{
 		Name:     flagPhone,
@@ -58,11 +219,32 @@ func phoneFlag() *cli.StringFlag {
 	}
 }
 
+func codeFromFlag() *cli.StringFlag {
+	return &cli.StringFlag{
+		Name:    flagCodeFrom,
+		Value:   tgclient.CodeFromStdin,
+		Usage:   "Where login gets the code and 2FA password: 'stdin' (prompt), 'env' (TELEGRAM_LOGIN_CODE and TELEGRAM_2FA_PASSWORD) or 'file:<path>' (polled for the code; the password comes from TELEGRAM_2FA_PASSWORD)",
+		Sources: cli.EnvVars("TELEGRAM_LOGIN_CODE_FROM"),
+		Action: func(_ context.Context, _ *cli.Command, value string) error {
+			return tgclient.ValidateCodeFrom(value)
+		},
+	}
+}
+
+func codeTimeoutFlag() *cli.DurationFlag {
+	return &cli.DurationFlag{
+		Name:    flagCodeTimeout,
+		Value:   tgclient.DefaultCodeTimeout,
+		Usage:   "How long login waits for the code before giving up (0 = no limit)",
+		Sources: cli.EnvVars("TELEGRAM_LOGIN_CODE_TIMEOUT"),
+	}
+}
+
 func summarizeProviderFlag() *cli.StringFlag {
 	return &cli.StringFlag{
 		Name:    flagSummarizeProvider,
 		Value:   string(summarize.ProviderSampling),
-		Usage:   "Provider for summarization: 'sampling', 'ollama', 'gemini', or 'anthropic'",
+		Usage:   "Provider for summarization: 'sampling', 'ollama', 'gemini', 'anthropic', or 'openai' (any OpenAI-compatible API)",
 		Sources: cli.EnvVars("SUMMARIZE_PROVIDER"),
 		Action: func(_ context.Context, _ *cli.Command, value string) error {
 			return summarize.ValidateProviderName(value)
@@ -103,6 +285,23 @@ func anthropicAPIKeyFlag() *cli.StringFlag {
 	}
 }
 
+func openAIAPIKeyFlag() *cli.StringFlag {
+	return &cli.StringFlag{
+		Name:    flagOpenAIAPIKey,
+		Usage:   "API key for the OpenAI-compatible chat API (used when summarize-provider is 'openai'; optional for local servers)",
+		Sources: cli.EnvVars("OPENAI_API_KEY"),
+	}
+}
+
+func openAIBaseURLFlag() *cli.StringFlag {
+	return &cli.StringFlag{
+		Name:    flagOpenAIBaseURL,
+		Value:   summarize.DefaultOpenAIURL,
+		Usage:   "Base URL of the OpenAI-compatible chat API, e.g. http://localhost:8000/v1 for vLLM (used when summarize-provider is 'openai')",
+		Sources: cli.EnvVars("OPENAI_BASE_URL"),
+	}
+}
+
 func summarizeBatchTokensFlag() *cli.IntFlag {
 	return &cli.IntFlag{
 		Name:    flagSummarizeBatchTokens,
@@ -111,3 +310,122 @@ func summarizeBatchTokensFlag() *cli.IntFlag {
 		Sources: cli.EnvVars("SUMMARIZE_BATCH_TOKENS"),
 	}
 }
+
+func summarizeMergeGapFlag() *cli.DurationFlag {
+	return &cli.DurationFlag{
+		Name:    flagSummarizeMergeGap,
+		Value:   messages.DefaultMergeGap,
+		Usage:   "Maximum gap between consecutive messages from one sender that are merged before summarization",
+		Sources: cli.EnvVars("SUMMARIZE_MERGE_GAP"),
+	}
+}
+
+func summarizeRequestTimeoutFlag() *cli.DurationFlag {
+	return &cli.DurationFlag{
+		Name:    flagSummarizeReqTimeout,
+		Value:   summarize.DefaultRequestTimeout,
+		Usage:   "Timeout for a single LLM request during summarization",
+		Sources: cli.EnvVars("SUMMARIZE_REQUEST_TIMEOUT"),
+	}
+}
+
+func summarizeDeadlineFlag() *cli.DurationFlag {
+	return &cli.DurationFlag{
+		Name:    flagSummarizeDeadline,
+		Value:   summarize.DefaultDeadline,
+		Usage:   "Overall time limit for a summarization; a partial summary is returned when reached (0 = no limit)",
+		Sources: cli.EnvVars("SUMMARIZE_DEADLINE"),
+	}
+}
+
+func summarizeRetryAttemptsFlag() *cli.IntFlag {
+	return &cli.IntFlag{
+		Name:    flagSummarizeRetries,
+		Value:   summarize.DefaultRetryAttempts,
+		Usage:   "Attempts per LLM request when the provider answers 408, 429 or 5xx or the network fails (1 = no retries)",
+		Sources: cli.EnvVars("SUMMARIZE_RETRY_ATTEMPTS"),
+	}
+}
+
+func summarizeRetryBaseDelayFlag() *cli.DurationFlag {
+	return &cli.DurationFlag{
+		Name:    flagSummarizeRetryDelay,
+		Value:   summarize.DefaultRetryBaseDelay,
+		Usage:   "Delay before the first retry of an LLM request; it doubles with each further retry",
+		Sources: cli.EnvVars("SUMMARIZE_RETRY_BASE_DELAY"),
+	}
+}
+
+func embedProviderFlag() *cli.StringFlag {
+	return &cli.StringFlag{
+		Name:    flagEmbedProvider,
+		Value:   string(summarize.EmbedProviderOllama),
+		Usage:   "Provider for embeddings used by semantic backup search: 'ollama' or 'openai' (any OpenAI-compatible API)",
+		Sources: cli.EnvVars("EMBED_PROVIDER"),
+		Action: func(_ context.Context, _ *cli.Command, value string) error {
+			return summarize.ValidateEmbedProviderName(value)
+		},
+	}
+}
+
+func embedModelFlag() *cli.StringFlag {
+	return &cli.StringFlag{
+		Name:    flagEmbedModel,
+		Usage:   "Embedding model (provider-specific)",
+		Sources: cli.EnvVars("EMBED_MODEL"),
+	}
+}
+
+func embedURLFlag() *cli.StringFlag {
+	return &cli.StringFlag{
+		Name:    flagEmbedURL,
+		Value:   summarize.DefaultOpenAIURL,
+		Usage:   "Base URL of the OpenAI-compatible embeddings API (used when embed-provider is 'openai')",
+		Sources: cli.EnvVars("EMBED_URL"),
+	}
+}
+
+func embedAPIKeyFlag() *cli.StringFlag {
+	return &cli.StringFlag{
+		Name:    flagEmbedAPIKey,
+		Usage:   "API key for the OpenAI-compatible embeddings API (used when embed-provider is 'openai')",
+		Sources: cli.EnvVars("EMBED_API_KEY"),
+	}
+}
+
+func transcribeProviderFlag() *cli.StringFlag {
+	return &cli.StringFlag{
+		Name:    flagTranscribeProvider,
+		Value:   string(summarize.TranscribeProviderTelegram),
+		Usage:   "Provider for voice message transcription: 'telegram' (Telegram's own, needs Premium beyond a few free trials) or 'openai' (any OpenAI-compatible API)",
+		Sources: cli.EnvVars("TRANSCRIBE_PROVIDER"),
+		Action: func(_ context.Context, _ *cli.Command, value string) error {
+			return summarize.ValidateTranscribeProviderName(value)
+		},
+	}
+}
+
+func transcribeModelFlag() *cli.StringFlag {
+	return &cli.StringFlag{
+		Name:    flagTranscribeModel,
+		Usage:   "Transcription model (used when transcribe-provider is 'openai')",
+		Sources: cli.EnvVars("TRANSCRIBE_MODEL"),
+	}
+}
+
+func transcribeURLFlag() *cli.StringFlag {
+	return &cli.StringFlag{
+		Name:    flagTranscribeURL,
+		Value:   summarize.DefaultOpenAIURL,
+		Usage:   "Base URL of the OpenAI-compatible transcription API (used when transcribe-provider is 'openai')",
+		Sources: cli.EnvVars("TRANSCRIBE_URL"),
+	}
+}
+
+func transcribeAPIKeyFlag() *cli.StringFlag {
+	return &cli.StringFlag{
+		Name:    flagTranscribeAPIKey,
+		Usage:   "API key for the OpenAI-compatible transcription API (used when transcribe-provider is 'openai')",
+		Sources: cli.EnvVars("TRANSCRIBE_API_KEY"),
+	}
+}
@@ -0,0 +1,231 @@
+package messages
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+)
+
+// maxAlbumSize is the most messages Telegram groups into one album. Album messages get
+// consecutive IDs, so every sibling lies within maxAlbumSize-1 IDs of any member.
+const maxAlbumSize = 10
+
+// ErrMessageNotFound is returned when a message doesn't exist or isn't accessible.
+var ErrMessageNotFound = errors.New("message not found")
+
+// GroupedID returns the album a message belongs to, or 0 if it's not part of one.
+func (m Message) GroupedID() int64 {
+	if m.Raw == nil {
+		return 0
+	}
+	id, _ := m.Raw.GetGroupedID()
+	return id
+}
+
+// FetchAlbum returns the messages of the album msgID belongs to in ID order, or just
+// that message when it isn't part of an album. The siblings are found with a single
+// request for the IDs around msgID.
+func (p *Provider) FetchAlbum(ctx context.Context, chatID int64, msgID int) ([]Message, error) {
+	return fetchAlbum(ctx, func(ctx context.Context, ids []int) ([]Message, error) {
+		result, err := p.FetchByIDs(ctx, chatID, ids)
+		if err != nil {
+			return nil, err
+		}
+		return result.Messages, nil
+	}, msgID)
+}
+
+// FetchAlbums returns the messages of msgIDs, each expanded to the album it belongs
+// to like FetchAlbum does, in the order of msgIDs and without repeats. The IDs around
+// all of them are fetched together, in requests of up to 100 IDs.
+func (p *Provider) FetchAlbums(ctx context.Context, chatID int64, msgIDs []int) ([]Message, error) {
+	return fetchAlbums(ctx, func(ctx context.Context, ids []int) ([]Message, error) {
+		result, err := p.FetchByIDs(ctx, chatID, ids)
+		if err != nil {
+			return nil, err
+		}
+		return result.Messages, nil
+	}, msgIDs)
+}
+
+// fetchAlbum scans the IDs around msgID with fetch and collects the messages sharing
+// its grouped ID.
+func fetchAlbum(ctx context.Context, fetch idsFetcher, msgID int) ([]Message, error) {
+	around, err := fetch(ctx, albumScanIDs(msgID))
+	if err != nil {
+		return nil, fmt.Errorf("fetching album: %w", err)
+	}
+	return albumOf(around, msgID)
+}
+
+// fetchAlbums scans the IDs around all msgIDs with fetch, in batches of
+// replyParentsBatchSize, and expands each to its album.
+func fetchAlbums(ctx context.Context, fetch idsFetcher, msgIDs []int) ([]Message, error) {
+	var ids []int
+	for _, msgID := range msgIDs {
+		ids = append(ids, albumScanIDs(msgID)...)
+	}
+	slices.Sort(ids)
+	ids = slices.Compact(ids)
+
+	var around []Message
+	for chunk := range slices.Chunk(ids, replyParentsBatchSize) {
+		batch, err := fetch(ctx, chunk)
+		if err != nil {
+			return nil, fmt.Errorf("fetching albums: %w", err)
+		}
+		around = append(around, batch...)
+	}
+
+	var out []Message
+	seen := make(map[int]bool)
+	for _, msgID := range msgIDs {
+		if seen[msgID] {
+			continue
+		}
+		album, err := albumOf(around, msgID)
+		if err != nil {
+			return nil, err
+		}
+		for _, m := range album {
+			if !seen[m.ID] {
+				seen[m.ID] = true
+				out = append(out, m)
+			}
+		}
+	}
+	return out, nil
+}
+
+// albumScanIDs returns the IDs within reach of any album msgID can belong to.
+func albumScanIDs(msgID int) []int {
+	var ids []int
+	for id := max(1, msgID-(maxAlbumSize-1)); id <= msgID+(maxAlbumSize-1); id++ {
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+// albumOf collects the messages of around sharing msgID's grouped ID, in ID order.
+func albumOf(around []Message, msgID int) ([]Message, error) {
+	i := slices.IndexFunc(around, func(m Message) bool { return m.ID == msgID })
+	if i < 0 {
+		return nil, fmt.Errorf("message %d: %w", msgID, ErrMessageNotFound)
+	}
+	target := around[i]
+	group := target.GroupedID()
+	if group == 0 {
+		return []Message{target}, nil
+	}
+
+	var album []Message
+	for _, m := range around {
+		if m.GroupedID() == group {
+			album = append(album, m)
+		}
+	}
+	slices.SortFunc(album, func(a, b Message) int { return a.ID - b.ID })
+	return album, nil
+}
+
+// GroupAlbums merges each run of consecutive messages sharing a grouped ID into one
+// message, keeping the order of msgs. The merged message takes the ID, date, sender
+// and reply of the album's first message and the text and links of the item with a
+// caption; Album lists every item's media and AlbumIDs their IDs, oldest first.
+func GroupAlbums(msgs []Message) []Message {
+	out := make([]Message, 0, len(msgs))
+	for i := 0; i < len(msgs); {
+		group := msgs[i].GroupedID()
+		j := i + 1
+		for group != 0 && j < len(msgs) && msgs[j].GroupedID() == group {
+			j++
+		}
+		if j-i == 1 {
+			out = append(out, msgs[i])
+		} else {
+			out = append(out, mergeAlbum(msgs[i:j]))
+		}
+		i = j
+	}
+	return out
+}
+
+// mergeAlbum merges the messages of one album into a single message.
+func mergeAlbum(items []Message) Message {
+	sorted := slices.Clone(items)
+	slices.SortFunc(sorted, func(a, b Message) int { return a.ID - b.ID })
+
+	merged := sorted[0]
+	merged.Text, merged.Entities = "", nil
+	merged.Album = make([]*MediaInfo, 0, len(sorted))
+	merged.AlbumIDs = make([]int, 0, len(sorted))
+	for _, m := range sorted {
+		if m.Media != nil {
+			merged.Album = append(merged.Album, m.Media)
+		}
+		merged.AlbumIDs = append(merged.AlbumIDs, m.ID)
+		if merged.Text == "" && m.Text != "" {
+			merged.Text, merged.Entities = m.Text, m.Entities
+		}
+		if m.EditDate.After(merged.EditDate) {
+			merged.EditDate = m.EditDate
+		}
+	}
+	return merged
+}
+
+// splitTrailingAlbum splits off the album msgs ends with, which may continue in the
+// next page of history, so it can be grouped once complete.
+func splitTrailingAlbum(msgs []Message) (rest, tail []Message) {
+	if len(msgs) == 0 {
+		return msgs, nil
+	}
+	group := msgs[len(msgs)-1].GroupedID()
+	if group == 0 {
+		return msgs, nil
+	}
+	i := len(msgs)
+	for i > 0 && msgs[i-1].GroupedID() == group {
+		i--
+	}
+	return msgs[:i], msgs[i:]
+}
+
+// IDs returns the IDs of the Telegram messages m stands for: the album's when it was
+// grouped, otherwise just its own.
+func (m Message) IDs() []int {
+	if len(m.AlbumIDs) > 0 {
+		return m.AlbumIDs
+	}
+	return []int{m.ID}
+}
+
+// AlbumLabel describes a grouped album, e.g. "sent 5 photos", or returns "" for any
+// other message.
+func (m Message) AlbumLabel() string {
+	n := len(m.Album)
+	if n < 2 {
+		return ""
+	}
+	kind := m.Album[0].Type
+	visual := true
+	for _, media := range m.Album {
+		if media.Type != kind {
+			kind = ""
+		}
+		visual = visual && (media.Type == "photo" || media.Type == MediaVideo)
+	}
+	switch {
+	case kind == "photo":
+		return fmt.Sprintf("sent %d photos", n)
+	case kind == MediaVideo:
+		return fmt.Sprintf("sent %d videos", n)
+	case kind == MediaAudio:
+		return fmt.Sprintf("sent %d audio files", n)
+	case visual:
+		return fmt.Sprintf("sent %d photos and videos", n)
+	default:
+		return fmt.Sprintf("sent %d files", n)
+	}
+}
@@ -0,0 +1,228 @@
+package messages
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gotd/td/tg"
+)
+
+func albumMessage(id int, groupedID int64) Message {
+	raw := &tg.Message{ID: id}
+	if groupedID != 0 {
+		raw.SetGroupedID(groupedID)
+	}
+	return Message{ID: id, Raw: raw}
+}
+
+func TestFetchAlbum(t *testing.T) {
+	// 20-22 form album 7, 23-24 album 8, 25 is a lone message
+	chat := map[int]Message{}
+	for _, m := range []Message{
+		albumMessage(19, 0),
+		albumMessage(20, 7), albumMessage(21, 7), albumMessage(22, 7),
+		albumMessage(23, 8), albumMessage(24, 8),
+		albumMessage(25, 0),
+	} {
+		chat[m.ID] = m
+	}
+
+	var requested [][]int
+	fetch := func(_ context.Context, ids []int) ([]Message, error) {
+		requested = append(requested, ids)
+		var out []Message
+		// Newest first, like Telegram returns them
+		for i := len(ids) - 1; i >= 0; i-- {
+			if m, ok := chat[ids[i]]; ok {
+				out = append(out, m)
+			}
+		}
+		return out, nil
+	}
+
+	tests := []struct {
+		name  string
+		msgID int
+		want  []int
+	}{
+		{"middle of album", 21, []int{20, 21, 22}},
+		{"first of album", 20, []int{20, 21, 22}},
+		{"next album is kept apart", 24, []int{23, 24}},
+		{"not an album", 25, []int{25}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requested = nil
+			album, err := fetchAlbum(t.Context(), fetch, tt.msgID)
+			if err != nil {
+				t.Fatalf("fetchAlbum() error = %v", err)
+			}
+			if got := messageIDs(album); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("fetchAlbum() = %v, want %v", got, tt.want)
+			}
+			if len(requested) != 1 {
+				t.Fatalf("fetch called %d times, want 1", len(requested))
+			}
+			if ids := requested[0]; ids[0] != tt.msgID-9 || ids[len(ids)-1] != tt.msgID+9 {
+				t.Errorf("scanned IDs %d..%d, want %d..%d", ids[0], ids[len(ids)-1], tt.msgID-9, tt.msgID+9)
+			}
+		})
+	}
+}
+
+func TestFetchAlbumLowIDs(t *testing.T) {
+	var requested []int
+	fetch := func(_ context.Context, ids []int) ([]Message, error) {
+		requested = ids
+		return []Message{albumMessage(2, 0)}, nil
+	}
+	if _, err := fetchAlbum(t.Context(), fetch, 2); err != nil {
+		t.Fatalf("fetchAlbum() error = %v", err)
+	}
+	if requested[0] != 1 {
+		t.Errorf("scan starts at ID %d, want 1", requested[0])
+	}
+}
+
+func TestFetchAlbumErrors(t *testing.T) {
+	missing := func(context.Context, []int) ([]Message, error) { return nil, nil }
+	if _, err := fetchAlbum(t.Context(), missing, 5); !errors.Is(err, ErrMessageNotFound) {
+		t.Errorf("missing message: error = %v, want ErrMessageNotFound", err)
+	}
+
+	boom := errors.New("boom")
+	failing := func(context.Context, []int) ([]Message, error) { return nil, boom }
+	if _, err := fetchAlbum(t.Context(), failing, 5); !errors.Is(err, boom) {
+		t.Errorf("fetch failure: error = %v, want %v", err, boom)
+	}
+}
+
+func TestFetchAlbums(t *testing.T) {
+	// 20-22 form album 7, 40-41 album 8, 25 and 30 are lone messages
+	chat := map[int]Message{}
+	for _, m := range []Message{
+		albumMessage(20, 7), albumMessage(21, 7), albumMessage(22, 7),
+		albumMessage(25, 0), albumMessage(30, 0),
+		albumMessage(40, 8), albumMessage(41, 8),
+	} {
+		chat[m.ID] = m
+	}
+	var requests int
+	fetch := func(_ context.Context, ids []int) ([]Message, error) {
+		requests++
+		if len(ids) > 100 {
+			t.Errorf("fetched %d IDs in one request, want at most 100", len(ids))
+		}
+		var out []Message
+		for _, id := range ids {
+			if m, ok := chat[id]; ok {
+				out = append(out, m)
+			}
+		}
+		return out, nil
+	}
+
+	got, err := fetchAlbums(t.Context(), fetch, []int{41, 30, 21, 22, 25})
+	if err != nil {
+		t.Fatalf("fetchAlbums() error = %v", err)
+	}
+	if want := []int{40, 41, 30, 20, 21, 22, 25}; !reflect.DeepEqual(messageIDs(got), want) {
+		t.Errorf("fetchAlbums() = %v, want %v", messageIDs(got), want)
+	}
+	if requests != 1 {
+		t.Errorf("fetch called %d times, want 1 for overlapping ranges", requests)
+	}
+
+	if _, err := fetchAlbums(t.Context(), fetch, []int{21, 99}); !errors.Is(err, ErrMessageNotFound) {
+		t.Errorf("missing message: error = %v, want ErrMessageNotFound", err)
+	}
+}
+
+func TestGroupAlbums(t *testing.T) {
+	media := func(m Message, kind string) Message {
+		m.Media = &MediaInfo{Type: kind}
+		return m
+	}
+	captioned := albumMessage(21, 7)
+	captioned.Text = "trip"
+
+	// Newest first, as history comes
+	msgs := []Message{
+		albumMessage(30, 0),
+		media(albumMessage(22, 7), "photo"), media(captioned, "photo"), media(albumMessage(20, 7), "photo"),
+		albumMessage(19, 0),
+	}
+	got := GroupAlbums(msgs)
+	if want := []int{30, 20, 19}; !reflect.DeepEqual(messageIDs(got), want) {
+		t.Fatalf("GroupAlbums() = %v, want %v", messageIDs(got), want)
+	}
+	album := got[1]
+	if want := []int{20, 21, 22}; !reflect.DeepEqual(album.IDs(), want) {
+		t.Errorf("album IDs = %v, want %v", album.IDs(), want)
+	}
+	if album.Text != "trip" || len(album.Album) != 3 {
+		t.Errorf("album text %q with %d items, want %q with 3", album.Text, len(album.Album), "trip")
+	}
+	if label := album.AlbumLabel(); label != "sent 3 photos" {
+		t.Errorf("AlbumLabel() = %q, want %q", label, "sent 3 photos")
+	}
+	if got := FormatForSummary(album); !strings.HasSuffix(got, "sent 3 photos: trip") {
+		t.Errorf("FormatForSummary() = %q, want the album label before the caption", got)
+	}
+	if got[0].AlbumLabel() != "" || !reflect.DeepEqual(got[0].IDs(), []int{30}) {
+		t.Errorf("lone message: label %q, IDs %v", got[0].AlbumLabel(), got[0].IDs())
+	}
+}
+
+func TestAlbumLabel(t *testing.T) {
+	tests := []struct {
+		kinds []string
+		want  string
+	}{
+		{[]string{MediaVideo, MediaVideo}, "sent 2 videos"},
+		{[]string{"photo", MediaVideo, "photo"}, "sent 3 photos and videos"},
+		{[]string{MediaAudio, MediaAudio}, "sent 2 audio files"},
+		{[]string{MediaDocument, "photo"}, "sent 2 files"},
+		{[]string{"photo"}, ""},
+	}
+	for _, tt := range tests {
+		var m Message
+		for _, kind := range tt.kinds {
+			m.Album = append(m.Album, &MediaInfo{Type: kind})
+		}
+		if got := m.AlbumLabel(); got != tt.want {
+			t.Errorf("AlbumLabel(%v) = %q, want %q", tt.kinds, got, tt.want)
+		}
+	}
+}
+
+func TestStreamAllCarriesAlbumAcrossPages(t *testing.T) {
+	// Album 7 (IDs 4-6) straddles the first two pages
+	pages := []*FetchResult{
+		{Messages: []Message{albumMessage(8, 0), albumMessage(6, 7), albumMessage(5, 7)}, HasMore: true, NextID: 5},
+		{Messages: []Message{albumMessage(4, 7), albumMessage(3, 0)}, HasMore: false},
+	}
+	var calls int
+	fetch := func(_ context.Context, _ FetchOptions) (*FetchResult, error) {
+		calls++
+		return pages[calls-1], nil
+	}
+
+	var batches [][]int
+	count, err := streamAll(t.Context(), fetch, FetchOptions{GroupAlbums: true}, func(msgs []Message) error {
+		batches = append(batches, messageIDs(msgs))
+		return nil
+	}, nil)
+	if err != nil {
+		t.Fatalf("streamAll() error = %v", err)
+	}
+	if want := [][]int{{8}, {4, 3}}; !reflect.DeepEqual(batches, want) {
+		t.Errorf("batches = %v, want %v", batches, want)
+	}
+	if count != 3 {
+		t.Errorf("count = %d, want 3", count)
+	}
+}
@@ -0,0 +1,102 @@
+package messages
+
+import (
+	"strconv"
+	"strings"
+)
+
+// SenderAliases formats the batches of one conversation for summarization with short
+// aliases for its senders. An alias stays the same in every batch, so a rolling
+// summary can keep referring to the same person. Without anonymizing, only the senders
+// whose name would take more tokens on their lines than in a legend get an alias,
+// e.g. P1, and the legend of a batch lists the names of the aliases it uses.
+// Anonymized, every sender is Person A, Person B and so on, without a legend.
+type SenderAliases struct {
+	anonymize bool
+	aliases   map[string]string // by senderKey
+}
+
+// NewSenderAliases creates the aliases of a conversation.
+func NewSenderAliases(anonymize bool) *SenderAliases {
+	return &SenderAliases{anonymize: anonymize, aliases: make(map[string]string)}
+}
+
+// senderKey identifies a sender by ID, or by name for senders without one such as
+// post authors and the senders of an export.
+func senderKey(id int64, name string) string {
+	if id != 0 {
+		return strconv.FormatInt(id, 10)
+	}
+	return "name:" + name
+}
+
+// alias returns the alias of key, or the one it would get.
+func (a *SenderAliases) alias(key string) string {
+	if alias, ok := a.aliases[key]; ok {
+		return alias
+	}
+	n := len(a.aliases)
+	if !a.anonymize {
+		return "P" + strconv.Itoa(n+1)
+	}
+	if n < 26 {
+		return "Person " + string(rune('A'+n))
+	}
+	return "Person " + strconv.Itoa(n+1)
+}
+
+// FormatBatch formats msgs like FormatBatchForSummary, or FormatBatchForSummaryWithIDs
+// with withIDs, naming senders by their aliases. A legend of the aliases used comes
+// first unless anonymized.
+func (a *SenderAliases) FormatBatch(msgs []Message, withIDs bool) string {
+	// A sender's name costs tokens on each of their lines
+	lines := make(map[string]int)
+	names := make(map[string]string)
+	for _, msg := range msgs {
+		if msg.Text == "" && msg.AlbumLabel() == "" {
+			continue
+		}
+		key := senderKey(msg.SenderID, msg.SenderName)
+		lines[key]++
+		names[key] = SenderLabel(msg.SenderID, msg.SenderName)
+	}
+
+	var legend []string
+	inLegend := make(map[string]bool)
+	label := func(id int64, name string) string {
+		key := senderKey(id, name)
+		if !a.anonymize {
+			full, ok := names[key]
+			if !ok || !worthAliasing(full, a.alias(key), lines[key]) {
+				return SenderLabel(id, name)
+			}
+		}
+		alias := a.alias(key)
+		a.aliases[key] = alias
+		if !a.anonymize && !inLegend[key] {
+			inLegend[key] = true
+			legend = append(legend, alias+" = "+names[key])
+		}
+		return alias
+	}
+
+	var body strings.Builder
+	for _, msg := range msgs {
+		if msg.Text == "" && msg.AlbumLabel() == "" {
+			continue
+		}
+		body.WriteString(EscapeDataBoundary(formatSummaryLine(msg, withIDs, label)))
+		body.WriteString("\n")
+	}
+	if len(legend) == 0 {
+		return body.String()
+	}
+	return EscapeDataBoundary("Senders by alias:\n"+strings.Join(legend, "\n")) + "\n\n" + body.String()
+}
+
+// worthAliasing reports whether writing alias on a sender's lines, plus a legend line
+// "alias = name", is shorter than writing the name on each of them.
+func worthAliasing(name, alias string, lines int) bool {
+	legendLine := len(alias) + len(" = ") + len(name) + 1
+	return lines*(len(name)-len(alias)) > legendLine
+}
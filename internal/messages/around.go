@@ -0,0 +1,79 @@
+package messages
+
+import (
+	"context"
+
+	"github.com/gotd/td/tg"
+)
+
+// MaxAroundCount caps the messages FetchAround returns on each side of the anchor.
+const MaxAroundCount = 50
+
+// Around is the window of messages around one message of a chat.
+type Around struct {
+	Messages    []Message // Oldest first, with the anchor if it exists
+	AnchorFound bool      // False when the anchor was deleted or never existed
+	AtStart     bool      // Fewer than the requested messages precede the anchor
+	AtEnd       bool      // Fewer than the requested messages follow the anchor
+}
+
+// FetchAround retrieves up to before messages preceding msgID, the message itself
+// and up to after messages following it, in two history requests anchored on it.
+// Counts are capped at MaxAroundCount.
+func (p *Provider) FetchAround(ctx context.Context, chatID int64, msgID, before, after int) (*Around, error) {
+	var around *Around
+	err := p.peers.WithPeer(ctx, p.client, chatID, func(peer tg.InputPeerClass) error {
+		var err error
+		around, err = fetchAround(ctx, func(ctx context.Context, opts FetchOptions) (*FetchResult, error) {
+			return p.fetchWithPeer(ctx, peer, opts)
+		}, msgID, before, after)
+		return err
+	})
+	return around, err
+}
+
+// fetchAround builds the window of FetchAround with fetch. History pages come newest
+// first and hold the messages older than OffsetID, so the first request starts just
+// above the anchor and the second shifts the page after newer messages.
+func fetchAround(ctx context.Context, fetch batchFetcher, msgID, before, after int) (*Around, error) {
+	before = min(max(before, 0), MaxAroundCount)
+	after = min(max(after, 0), MaxAroundCount)
+
+	older, err := fetch(ctx, FetchOptions{OffsetID: msgID + 1, Limit: before + 1})
+	if err != nil {
+		return nil, err
+	}
+	around := &Around{}
+	preceding := older.Messages
+	if len(preceding) > 0 && preceding[0].ID == msgID {
+		around.AnchorFound = true
+		preceding = preceding[1:]
+	}
+	if len(preceding) > before {
+		preceding = preceding[:before]
+	}
+	around.AtStart = len(preceding) < before
+
+	var following []Message
+	if after > 0 {
+		newer, err := fetch(ctx, FetchOptions{OffsetID: msgID + 1, AddOffset: -after, Limit: after})
+		if err != nil {
+			return nil, err
+		}
+		for _, msg := range newer.Messages {
+			if msg.ID > msgID {
+				following = append(following, msg)
+			}
+		}
+		around.AtEnd = len(following) < after
+	}
+
+	around.Messages = make([]Message, 0, len(preceding)+len(following)+1)
+	around.Messages = append(around.Messages, following...)
+	if around.AnchorFound {
+		around.Messages = append(around.Messages, older.Messages[0])
+	}
+	around.Messages = append(around.Messages, preceding...)
+	Reverse(around.Messages)
+	return around, nil
+}
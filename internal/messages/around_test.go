@@ -0,0 +1,69 @@
+package messages
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+// historyFetcher serves pages of a chat with the given message IDs the way
+// messages.getHistory does: newest first, starting add_offset messages past the
+// first one older than offset_id.
+func historyFetcher(ids ...int) batchFetcher {
+	return func(_ context.Context, opts FetchOptions) (*FetchResult, error) {
+		// ids are ascending; walk them newest first
+		var newestFirst []Message
+		for i := len(ids) - 1; i >= 0; i-- {
+			newestFirst = append(newestFirst, Message{ID: ids[i]})
+		}
+		pos := len(newestFirst)
+		for i, m := range newestFirst {
+			if m.ID < opts.OffsetID {
+				pos = i
+				break
+			}
+		}
+		start := max(pos+opts.AddOffset, 0)
+		end := min(pos+opts.AddOffset+opts.Limit, len(newestFirst))
+		if start >= end {
+			return &FetchResult{}, nil
+		}
+		return &FetchResult{Messages: newestFirst[start:end]}, nil
+	}
+}
+
+func TestFetchAround(t *testing.T) {
+	// Message 14 was deleted
+	fetch := historyFetcher(10, 11, 12, 13, 15, 16, 17, 18, 19, 20)
+
+	tests := []struct {
+		name          string
+		msgID         int
+		before, after int
+		wantIDs       []int
+		wantFound     bool
+		wantStart     bool
+		wantEnd       bool
+	}{
+		{"middle", 16, 2, 2, []int{13, 15, 16, 17, 18}, true, false, false},
+		{"deleted anchor", 14, 2, 2, []int{12, 13, 15, 16}, false, false, false},
+		{"start of chat", 11, 3, 1, []int{10, 11, 12}, true, true, false},
+		{"end of chat", 19, 1, 3, []int{18, 19, 20}, true, false, true},
+		{"anchor only", 12, 0, 0, []int{12}, true, false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			around, err := fetchAround(t.Context(), fetch, tt.msgID, tt.before, tt.after)
+			if err != nil {
+				t.Fatalf("fetchAround() error = %v", err)
+			}
+			if got := messageIDs(around.Messages); !reflect.DeepEqual(got, tt.wantIDs) {
+				t.Errorf("messages = %v, want %v", got, tt.wantIDs)
+			}
+			if around.AnchorFound != tt.wantFound || around.AtStart != tt.wantStart || around.AtEnd != tt.wantEnd {
+				t.Errorf("found %v, at start %v, at end %v; want %v, %v, %v",
+					around.AnchorFound, around.AtStart, around.AtEnd, tt.wantFound, tt.wantStart, tt.wantEnd)
+			}
+		})
+	}
+}
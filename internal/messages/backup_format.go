@@ -0,0 +1,178 @@
+package messages
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// Backup file formats.
+const (
+	// BackupFormatText is the delimited text format of FormatBatchForBackup.
+	BackupFormatText = "text"
+	// BackupFormatJSON is a JSON array of messages.
+	BackupFormatJSON = "json"
+	// BackupFormatNDJSON is one JSON message per line, for streaming through tools like jq.
+	BackupFormatNDJSON = "ndjson"
+)
+
+// BackupFormats lists the supported backup formats.
+var BackupFormats = []string{BackupFormatText, BackupFormatJSON, BackupFormatNDJSON}
+
+// BackupExtension returns the file extension for a backup format.
+func BackupExtension(format string) string {
+	switch format {
+	case BackupFormatJSON:
+		return ".json"
+	case BackupFormatNDJSON:
+		return ".ndjson"
+	default:
+		return ".txt"
+	}
+}
+
+// BackupFormatFromPath infers a backup's format from its file extension; anything
+// other than .json and .ndjson is read as text.
+func BackupFormatFromPath(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		return BackupFormatJSON
+	case ".ndjson", ".jsonl":
+		return BackupFormatNDJSON
+	default:
+		return BackupFormatText
+	}
+}
+
+// WriteBackup writes messages to w in the given format. The text format keeps only
+// messages with text, like FormatBatchForBackup; the JSON formats keep every message
+// with its media, entities and buttons.
+func WriteBackup(w io.Writer, msgs []Message, format string) error {
+	bw, err := NewBackupWriter(w, format)
+	if err != nil {
+		return err
+	}
+	if err := bw.Write(msgs); err != nil {
+		return err
+	}
+	return bw.Close()
+}
+
+// BackupWriter writes a backup batch by batch, so a whole chat never has to be held
+// in memory. Each Write reaches w before it returns, so an interrupted backup leaves
+// the batches written so far; Close ends the file and is needed for it to parse.
+type BackupWriter struct {
+	// Verbose adds forward origin and channel post statistics tags to the text format,
+	// see FormatBatchForBackup. The JSON formats always have them.
+	Verbose bool
+
+	w       io.Writer
+	format  string
+	written bool // Whether anything but the opening of the file was written
+}
+
+// NewBackupWriter returns a writer of a backup in the given format to w.
+func NewBackupWriter(w io.Writer, format string) (*BackupWriter, error) {
+	if !slices.Contains(BackupFormats, format) {
+		return nil, fmt.Errorf("unknown backup format %q", format)
+	}
+	return &BackupWriter{w: w, format: format}, nil
+}
+
+// Write appends a batch of messages to the backup.
+func (b *BackupWriter) Write(msgs []Message) error {
+	var buf bytes.Buffer
+	switch b.format {
+	case BackupFormatText:
+		buf.WriteString(formatBackupEntries(msgs, b.Verbose))
+	case BackupFormatJSON:
+		for _, msg := range msgs {
+			data, err := json.MarshalIndent(msg, "  ", "  ")
+			if err != nil {
+				return fmt.Errorf("marshaling message %d: %w", msg.ID, err)
+			}
+			if b.written {
+				buf.WriteString(",\n  ")
+			} else {
+				buf.WriteString("[\n  ")
+			}
+			buf.Write(data)
+			b.written = true
+		}
+	case BackupFormatNDJSON:
+		enc := json.NewEncoder(&buf)
+		enc.SetEscapeHTML(false)
+		for _, msg := range msgs {
+			if err := enc.Encode(msg); err != nil {
+				return fmt.Errorf("marshaling message %d: %w", msg.ID, err)
+			}
+		}
+	}
+	if buf.Len() == 0 {
+		return nil
+	}
+	b.written = true
+	_, err := b.w.Write(buf.Bytes())
+	return err
+}
+
+// Close ends the backup: it closes the text format's last message and the JSON
+// array. It doesn't close the underlying writer.
+func (b *BackupWriter) Close() error {
+	var end string
+	switch b.format {
+	case BackupFormatText:
+		if b.written {
+			end = backupSeparator
+		}
+	case BackupFormatJSON:
+		end = "[]\n"
+		if b.written {
+			end = "\n]\n"
+		}
+	}
+	if end == "" {
+		return nil
+	}
+	_, err := io.WriteString(b.w, end)
+	return err
+}
+
+// ReadBackup parses a backup written by WriteBackup in the given format.
+func ReadBackup(data []byte, format string) ([]Message, error) {
+	switch format {
+	case BackupFormatText:
+		return ParseBackup(string(data))
+	case BackupFormatJSON:
+		var msgs []Message
+		if err := json.Unmarshal(data, &msgs); err != nil {
+			return nil, fmt.Errorf("parsing JSON backup: %w", err)
+		}
+		return msgs, nil
+	case BackupFormatNDJSON:
+		var msgs []Message
+		scanner := bufio.NewScanner(bytes.NewReader(data))
+		scanner.Buffer(make([]byte, 0, 64<<10), 16<<20)
+		for line := 1; scanner.Scan(); line++ {
+			if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+				continue
+			}
+			var msg Message
+			if err := json.Unmarshal(scanner.Bytes(), &msg); err != nil {
+				return nil, fmt.Errorf("line %d: %w", line, err)
+			}
+			msgs = append(msgs, msg)
+		}
+		if err := scanner.Err(); err != nil {
+			return nil, fmt.Errorf("reading NDJSON backup: %w", err)
+		}
+		return msgs, nil
+	default:
+		return nil, fmt.Errorf("unknown backup format %q", format)
+	}
+}
@@ -0,0 +1,134 @@
+package messages
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBackupFormatsRoundTrip(t *testing.T) {
+	day := time.Date(2024, 3, 1, 9, 30, 0, 0, time.UTC)
+	msgs := []Message{
+		{
+			ID: 1, Date: day, SenderID: 7, SenderName: "Anna", Text: "Plan attached\nsee line two",
+			Media: &MediaInfo{Type: MediaDocument, FileName: "plan.pdf", MimeType: "application/pdf", Size: 1234,
+				ResourceURI: "telegram://document/1/2/3?ref=AQI="},
+			Entities: []string{"https://example.com"},
+		},
+		{ID: 2, Date: day.Add(time.Minute), EditDate: day.Add(time.Hour), SenderID: 8, SenderName: "Bob", Text: "ok", ReplyToID: 1},
+		{ID: 3, Date: day.Add(2 * time.Minute), SenderName: "Bot", Text: "", Media: &MediaInfo{Type: "photo", Width: 800, Height: 600},
+			Buttons: [][]Button{{{Text: "Yes", Type: "callback"}}}, Context: true},
+	}
+
+	for _, format := range []string{BackupFormatJSON, BackupFormatNDJSON} {
+		t.Run(format, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := WriteBackup(&buf, msgs, format); err != nil {
+				t.Fatalf("WriteBackup: %v", err)
+			}
+			if format == BackupFormatNDJSON {
+				if lines := strings.Count(buf.String(), "\n"); lines != len(msgs) {
+					t.Errorf("NDJSON has %d lines, want one per message (%d)", lines, len(msgs))
+				}
+			}
+			got, err := ReadBackup(buf.Bytes(), format)
+			if err != nil {
+				t.Fatalf("ReadBackup: %v", err)
+			}
+			if !reflect.DeepEqual(got, msgs) {
+				t.Errorf("round trip mismatch:\n got %+v\nwant %+v", got, msgs)
+			}
+		})
+	}
+
+	// The text format keeps only messages with text and their header fields
+	var buf bytes.Buffer
+	if err := WriteBackup(&buf, msgs, BackupFormatText); err != nil {
+		t.Fatalf("WriteBackup(text): %v", err)
+	}
+	got, err := ReadBackup(buf.Bytes(), BackupFormatText)
+	if err != nil {
+		t.Fatalf("ReadBackup(text): %v", err)
+	}
+	if len(got) != 2 || got[0].Text != msgs[0].Text || got[1].ReplyToID != 1 {
+		t.Errorf("text round trip = %+v", got)
+	}
+}
+
+func TestWriteBackupEmptyJSON(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteBackup(&buf, nil, BackupFormatJSON); err != nil {
+		t.Fatalf("WriteBackup: %v", err)
+	}
+	if got := strings.TrimSpace(buf.String()); got != "[]" {
+		t.Errorf("empty JSON backup = %q, want []", got)
+	}
+	if err := WriteBackup(&buf, nil, "xml"); err == nil {
+		t.Error("WriteBackup accepted an unknown format")
+	}
+}
+
+func TestReadBackupNDJSONError(t *testing.T) {
+	_, err := ReadBackup([]byte("{\"id\":1,\"date\":\"2024-03-01T09:30:00Z\",\"text\":\"a\"}\n\nnot json\n"), BackupFormatNDJSON)
+	if err == nil || !strings.Contains(err.Error(), "line 3") {
+		t.Errorf("ReadBackup error = %v, want one naming line 3", err)
+	}
+}
+
+func TestBackupFormatFromPath(t *testing.T) {
+	for path, want := range map[string]string{
+		"a/chat.txt":    BackupFormatText,
+		"chat.JSON":     BackupFormatJSON,
+		"chat.ndjson":   BackupFormatNDJSON,
+		"chat.jsonl":    BackupFormatNDJSON,
+		"chat-backup":   BackupFormatText,
+		"chat.json.txt": BackupFormatText,
+	} {
+		if got := BackupFormatFromPath(path); got != want {
+			t.Errorf("BackupFormatFromPath(%q) = %q, want %q", path, got, want)
+		}
+		if ext := BackupExtension(want); BackupFormatFromPath("x"+ext) != want {
+			t.Errorf("BackupExtension(%q) = %q does not map back", want, ext)
+		}
+	}
+}
+
+func TestBackupWriterBatches(t *testing.T) {
+	day := time.Date(2024, 3, 1, 9, 30, 0, 0, time.UTC)
+	msgs := []Message{
+		{ID: 3, Date: day, SenderName: "Anna", Text: "three"},
+		{ID: 2, Date: day, SenderName: "Bob", Text: ""},
+		{ID: 1, Date: day, SenderName: "Anna", Text: "one"},
+	}
+
+	for _, format := range BackupFormats {
+		t.Run(format, func(t *testing.T) {
+			var whole, batched bytes.Buffer
+			if err := WriteBackup(&whole, msgs, format); err != nil {
+				t.Fatalf("WriteBackup: %v", err)
+			}
+			w, err := NewBackupWriter(&batched, format)
+			if err != nil {
+				t.Fatalf("NewBackupWriter: %v", err)
+			}
+			for _, batch := range [][]Message{msgs[:1], nil, msgs[1:]} {
+				if err := w.Write(batch); err != nil {
+					t.Fatalf("Write: %v", err)
+				}
+			}
+			if err := w.Close(); err != nil {
+				t.Fatalf("Close: %v", err)
+			}
+			got, err := ReadBackup(batched.Bytes(), format)
+			if err != nil {
+				t.Fatalf("ReadBackup: %v", err)
+			}
+			want, _ := ReadBackup(whole.Bytes(), format)
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("batched backup = %+v, want %+v", got, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,145 @@
+package messages
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// backupSeparator separates messages in a backup file.
+const backupSeparator = "-----"
+
+// backupHeaderRe matches a message header written by FormatBatchForBackup.
+var backupHeaderRe = regexp.MustCompile(`^\[(\d{4}-\d{2}-\d{2} \d{2}:\d{2}:\d{2})\] \[(.*)\] \[id=(\d+)\](?: \[reply_to=(\d+)\])?(?: \[via=([^\]]*)\])?(?: \[signed=(.*?)\])?(?: \[media=(\w+)\])?(?: \[album=\d+\])?( \[context\])?(?: \(edited (\d{4}-\d{2}-\d{2} \d{2}:\d{2}:\d{2})\))?(?: \[forwarded_from=(.*?), (\d{4}-\d{2}-\d{2} \d{2}:\d{2}:\d{2})\])?(?: \[views=(\d+)\])?(?: \[forwards=(\d+)\])?(?: \[reactions=([^\]]*)\])?$`)
+
+// ParseBackup parses the contents of a backup file written by FormatBatchForBackup.
+// A separator line inside a message text is kept as text unless a header follows it.
+func ParseBackup(content string) ([]Message, error) {
+	lines := strings.Split(strings.ReplaceAll(content, "\r\n", "\n"), "\n")
+
+	var msgs []Message
+	var current *Message
+	var text []string
+
+	flush := func() {
+		if current != nil {
+			current.Text = strings.Join(text, "\n")
+			msgs = append(msgs, *current)
+		}
+		current = nil
+		text = nil
+	}
+
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		if line == backupSeparator {
+			if i+1 < len(lines) && backupHeaderRe.MatchString(lines[i+1]) {
+				flush()
+				msg, err := parseBackupHeader(lines[i+1])
+				if err != nil {
+					return nil, fmt.Errorf("line %d: %w", i+2, err)
+				}
+				current = &msg
+				i++
+				continue
+			}
+			if isBackupEnd(lines[i+1:]) {
+				break
+			}
+		}
+		if current == nil {
+			if strings.TrimSpace(line) == "" {
+				continue
+			}
+			return nil, fmt.Errorf("line %d: expected a message header, got %q", i+1, line)
+		}
+		text = append(text, line)
+	}
+	flush()
+
+	return msgs, nil
+}
+
+// parseBackupHeader parses a "[date] [sender] [id=N] [reply_to=N] [via=@bot] [signed=author] [media=type] [album=N] [context] (edited date)" header line,
+// followed by the verbose tags "[forwarded_from=name, date] [views=N] [forwards=N] [reactions=👍 3, ❤ 1]". The album size is informational and not restored.
+func parseBackupHeader(line string) (Message, error) {
+	m := backupHeaderRe.FindStringSubmatch(line)
+	if m == nil {
+		return Message{}, fmt.Errorf("invalid message header %q", line)
+	}
+
+	date, err := time.ParseInLocation(DateFormat, m[1], time.Local)
+	if err != nil {
+		return Message{}, fmt.Errorf("parsing date: %w", err)
+	}
+	id, err := strconv.Atoi(m[3])
+	if err != nil {
+		return Message{}, fmt.Errorf("parsing id: %w", err)
+	}
+	msg := Message{ID: id, Date: date, SenderName: m[2], ViaBot: m[5], PostAuthor: m[6], Context: m[8] != ""}
+	if m[7] != "" {
+		msg.Media = &MediaInfo{Type: m[7]}
+	}
+	if m[4] != "" {
+		if msg.ReplyToID, err = strconv.Atoi(m[4]); err != nil {
+			return Message{}, fmt.Errorf("parsing reply_to: %w", err)
+		}
+	}
+	if m[9] != "" {
+		if msg.EditDate, err = time.ParseInLocation(DateFormat, m[9], time.Local); err != nil {
+			return Message{}, fmt.Errorf("parsing edit date: %w", err)
+		}
+	}
+	if m[10] != "" {
+		fwdDate, err := time.ParseInLocation(DateFormat, m[11], time.Local)
+		if err != nil {
+			return Message{}, fmt.Errorf("parsing forward date: %w", err)
+		}
+		msg.ForwardedFrom = &ForwardInfo{From: m[10], Date: fwdDate}
+	}
+	if m[12] != "" {
+		if msg.Views, err = strconv.Atoi(m[12]); err != nil {
+			return Message{}, fmt.Errorf("parsing views: %w", err)
+		}
+	}
+	if m[13] != "" {
+		if msg.Forwards, err = strconv.Atoi(m[13]); err != nil {
+			return Message{}, fmt.Errorf("parsing forwards: %w", err)
+		}
+	}
+	if m[14] != "" {
+		if msg.Reactions, err = parseBackupReactions(m[14]); err != nil {
+			return Message{}, err
+		}
+	}
+	return msg, nil
+}
+
+// parseBackupReactions parses the "👍 3, ❤ 1" list of a [reactions] tag.
+func parseBackupReactions(s string) (map[string]int, error) {
+	counts := make(map[string]int)
+	for item := range strings.SplitSeq(s, ", ") {
+		i := strings.LastIndexByte(item, ' ')
+		if i < 0 {
+			return nil, fmt.Errorf("invalid reaction %q", item)
+		}
+		n, err := strconv.Atoi(item[i+1:])
+		if err != nil {
+			return nil, fmt.Errorf("parsing reaction count %q: %w", item, err)
+		}
+		counts[item[:i]] = n
+	}
+	return counts, nil
+}
+
+// isBackupEnd reports whether only blank lines remain.
+func isBackupEnd(rest []string) bool {
+	for _, line := range rest {
+		if strings.TrimSpace(line) != "" {
+			return false
+		}
+	}
+	return true
+}
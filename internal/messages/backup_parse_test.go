@@ -0,0 +1,107 @@
+package messages
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseBackupRoundTrip(t *testing.T) {
+	date := func(m int) time.Time { return time.Date(2024, 1, 15, 10, m, 0, 0, time.Local) }
+	msgs := []Message{
+		{ID: 1, Date: date(0), SenderName: "Anna", Text: "Hello"},
+		{ID: 2, Date: date(1), SenderName: "Bob [work]", Text: "multi\nline\n-----\nwith a separator inside", ReplyToID: 1},
+		{ID: 3, Date: date(2), SenderName: "", Text: "trailing newline\n"},
+		{ID: 4, Date: date(3), SenderName: "Anna", Text: "Последнее сообщение"},
+		{ID: 5, Date: date(4), SenderName: "Bob", Text: "context only", Context: true},
+		{ID: 6, Date: date(5), EditDate: date(9), SenderName: "Anna", Text: "fixed typo", ReplyToID: 5},
+		{ID: 7, Date: date(6), SenderName: "News", PostAuthor: "Maria [editor]", Text: "signed post"},
+		{ID: 8, Date: date(7), SenderName: "Bob", ViaBot: "@gif", Text: "via bot", Context: true},
+		{ID: 9, Date: date(8), EditDate: date(9), SenderName: "Anna", Text: "look at this", Media: &MediaInfo{Type: "photo"}, Context: true},
+	}
+
+	got, err := ParseBackup(FormatBatchForBackup(msgs, false))
+	if err != nil {
+		t.Fatalf("ParseBackup: %v", err)
+	}
+	if len(got) != len(msgs) {
+		t.Fatalf("got %d messages, want %d", len(got), len(msgs))
+	}
+	for i, want := range msgs {
+		g := got[i]
+		if g.ID != want.ID || g.SenderName != want.SenderName || g.Text != want.Text ||
+			g.ReplyToID != want.ReplyToID || g.Context != want.Context || !g.Date.Equal(want.Date) || !g.EditDate.Equal(want.EditDate) ||
+			g.PostAuthor != want.PostAuthor || g.ViaBot != want.ViaBot || !reflect.DeepEqual(g.Media, want.Media) {
+			t.Errorf("message %d = %+v, want %+v", i, g, want)
+		}
+	}
+}
+
+func TestParseBackupVerbose(t *testing.T) {
+	date := time.Date(2024, 1, 15, 10, 0, 0, 0, time.Local)
+	msg := Message{
+		ID: 1, Date: date, SenderName: "News", Text: "repost",
+		ForwardedFrom: &ForwardInfo{From: "Wire, [daily]", Date: date.Add(-time.Hour)},
+		Views:         1500,
+		Forwards:      42,
+		Reactions:     map[string]int{"❤": 3, "👍": 12, "custom emoji 99": 3},
+	}
+
+	text := FormatBatchForBackup([]Message{msg}, true)
+	wantHeader := "[2024-01-15 10:00:00] [News] [id=1] [forwarded_from=Wire, [daily], 2024-01-15 09:00:00] [views=1500] [forwards=42] [reactions=👍 12, custom emoji 99 3, ❤ 3]"
+	if header := strings.Split(text, "\n")[1]; header != wantHeader {
+		t.Errorf("header = %q, want %q", header, wantHeader)
+	}
+	if plain := FormatBatchForBackup([]Message{msg}, false); strings.Contains(plain, "views") {
+		t.Errorf("non-verbose backup has statistics: %q", plain)
+	}
+
+	got, err := ParseBackup(text)
+	if err != nil {
+		t.Fatalf("ParseBackup: %v", err)
+	}
+	if len(got) != 1 {
+		t.Fatalf("got %d messages, want 1", len(got))
+	}
+	g := got[0]
+	if !reflect.DeepEqual(g.ForwardedFrom, msg.ForwardedFrom) || g.Views != msg.Views || g.Forwards != msg.Forwards || !reflect.DeepEqual(g.Reactions, msg.Reactions) {
+		t.Errorf("parsed %+v, want %+v", g, msg)
+	}
+}
+
+func TestParseBackup(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantIDs []int
+		wantErr bool
+	}{
+		{name: "empty", content: "", wantIDs: nil},
+		{name: "windows line endings", content: "-----\r\n[2024-01-15 10:00:00] [A] [id=7]\r\nhi\r\n-----", wantIDs: []int{7}},
+		{name: "no trailing separator", content: "-----\n[2024-01-15 10:00:00] [A] [id=7]\nhi", wantIDs: []int{7}},
+		{name: "not a backup", content: "just some notes", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseBackup(tt.content)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected error, got %+v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			var ids []int
+			for _, m := range got {
+				ids = append(ids, m.ID)
+			}
+			if !reflect.DeepEqual(ids, tt.wantIDs) {
+				t.Errorf("got IDs %v, want %v", ids, tt.wantIDs)
+			}
+		})
+	}
+}
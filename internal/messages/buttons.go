@@ -0,0 +1,113 @@
+package messages
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/gotd/td/tg"
+)
+
+// Values of Button.Type
+const (
+	ButtonCallback = "callback" // Inline button that sends callback data to the bot
+	ButtonURL      = "url"      // Inline button that opens a link
+	ButtonKeyboard = "keyboard" // Reply keyboard button; pressing it sends its text as a message
+	ButtonOther    = "other"    // Any other button (switch inline, game, payment, web app, ...)
+)
+
+// Button is a keyboard button attached to a bot message.
+type Button struct {
+	Text             string `json:"text"`
+	Type             string `json:"type"`
+	Data             string `json:"data,omitempty"` // Callback data; base64 with a "base64:" prefix when not UTF-8
+	URL              string `json:"url,omitempty"`
+	RequiresPassword bool   `json:"requires_password,omitempty"`
+}
+
+// ErrButtonNotFound is returned by FindButton when no button matches.
+var ErrButtonNotFound = errors.New("button not found")
+
+// extractButtons returns the rows of buttons of a reply markup, or nil when the
+// markup has no buttons (e.g. it hides the keyboard or forces a reply).
+func extractButtons(markup tg.ReplyMarkupClass) [][]Button {
+	var result [][]Button
+	for _, row := range markupRows(markup) {
+		buttons := make([]Button, 0, len(row.Buttons))
+		for _, b := range row.Buttons {
+			buttons = append(buttons, buttonInfo(b))
+		}
+		if len(buttons) > 0 {
+			result = append(result, buttons)
+		}
+	}
+	return result
+}
+
+// markupRows returns the button rows of inline and reply keyboards.
+func markupRows(markup tg.ReplyMarkupClass) []tg.KeyboardButtonRow {
+	switch m := markup.(type) {
+	case *tg.ReplyInlineMarkup:
+		return m.Rows
+	case *tg.ReplyKeyboardMarkup:
+		return m.Rows
+	default:
+		return nil
+	}
+}
+
+// buttonInfo describes a single keyboard button.
+func buttonInfo(b tg.KeyboardButtonClass) Button {
+	switch k := b.(type) {
+	case *tg.KeyboardButtonCallback:
+		return Button{Text: k.Text, Type: ButtonCallback, Data: callbackData(k.Data), RequiresPassword: k.RequiresPassword}
+	case *tg.KeyboardButtonURL:
+		return Button{Text: k.Text, Type: ButtonURL, URL: k.URL}
+	case *tg.KeyboardButtonURLAuth:
+		return Button{Text: k.Text, Type: ButtonURL, URL: k.URL}
+	case *tg.KeyboardButton:
+		return Button{Text: k.Text, Type: ButtonKeyboard}
+	default:
+		return Button{Text: b.GetText(), Type: ButtonOther}
+	}
+}
+
+// callbackData renders callback data as text.
+func callbackData(data []byte) string {
+	if utf8.Valid(data) {
+		return string(data)
+	}
+	return "base64:" + base64.StdEncoding.EncodeToString(data)
+}
+
+// FindButton finds a button in a reply markup, by text (case-insensitive, surrounding
+// spaces ignored) when text is non-empty, otherwise by zero-based row and column.
+func FindButton(markup tg.ReplyMarkupClass, text string, row, column int) (tg.KeyboardButtonClass, error) {
+	rows := markupRows(markup)
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("%w: message has no buttons", ErrButtonNotFound)
+	}
+
+	if text != "" {
+		want := strings.TrimSpace(text)
+		for _, r := range rows {
+			for _, b := range r.Buttons {
+				if strings.EqualFold(strings.TrimSpace(b.GetText()), want) {
+					return b, nil
+				}
+			}
+		}
+		return nil, fmt.Errorf("%w: no button with text %q", ErrButtonNotFound, text)
+	}
+
+	if row < 0 || row >= len(rows) {
+		return nil, fmt.Errorf("%w: row %d out of range (message has %d rows)", ErrButtonNotFound, row, len(rows))
+	}
+	buttons := rows[row].Buttons
+	if column < 0 || column >= len(buttons) {
+		return nil, fmt.Errorf("%w: column %d out of range (row %d has %d buttons)", ErrButtonNotFound, column, row, len(buttons))
+	}
+	return buttons[column], nil
+}
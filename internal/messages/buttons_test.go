@@ -0,0 +1,133 @@
+package messages
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/gotd/td/tg"
+)
+
+// confirmMarkup is a typical bot confirmation keyboard.
+var confirmMarkup = &tg.ReplyInlineMarkup{Rows: []tg.KeyboardButtonRow{
+	{Buttons: []tg.KeyboardButtonClass{
+		&tg.KeyboardButtonCallback{Text: "Confirm", Data: []byte("order:42:yes")},
+		&tg.KeyboardButtonCallback{Text: "Cancel", Data: []byte{0xff, 0x01}},
+	}},
+	{Buttons: []tg.KeyboardButtonClass{
+		&tg.KeyboardButtonURL{Text: "Details", URL: "https://example.com/order/42"},
+		&tg.KeyboardButtonSwitchInline{Text: "Share", Query: "order 42"},
+	}},
+}}
+
+func TestExtractButtons(t *testing.T) {
+	tests := []struct {
+		name   string
+		markup tg.ReplyMarkupClass
+		want   [][]Button
+	}{
+		{
+			name:   "inline keyboard",
+			markup: confirmMarkup,
+			want: [][]Button{
+				{
+					{Text: "Confirm", Type: ButtonCallback, Data: "order:42:yes"},
+					{Text: "Cancel", Type: ButtonCallback, Data: "base64:/wE="},
+				},
+				{
+					{Text: "Details", Type: ButtonURL, URL: "https://example.com/order/42"},
+					{Text: "Share", Type: ButtonOther},
+				},
+			},
+		},
+		{
+			name: "password-protected callback and login URL",
+			markup: &tg.ReplyInlineMarkup{Rows: []tg.KeyboardButtonRow{{Buttons: []tg.KeyboardButtonClass{
+				&tg.KeyboardButtonCallback{Text: "Transfer", Data: []byte("t"), RequiresPassword: true},
+				&tg.KeyboardButtonURLAuth{Text: "Log in", URL: "https://example.com/login"},
+			}}}},
+			want: [][]Button{{
+				{Text: "Transfer", Type: ButtonCallback, Data: "t", RequiresPassword: true},
+				{Text: "Log in", Type: ButtonURL, URL: "https://example.com/login"},
+			}},
+		},
+		{
+			name: "reply keyboard",
+			markup: &tg.ReplyKeyboardMarkup{Rows: []tg.KeyboardButtonRow{
+				{Buttons: []tg.KeyboardButtonClass{&tg.KeyboardButton{Text: "Yes"}, &tg.KeyboardButton{Text: "No"}}},
+				{Buttons: []tg.KeyboardButtonClass{}},
+			}},
+			want: [][]Button{{
+				{Text: "Yes", Type: ButtonKeyboard},
+				{Text: "No", Type: ButtonKeyboard},
+			}},
+		},
+		{name: "hide keyboard", markup: &tg.ReplyKeyboardHide{}, want: nil},
+		{name: "force reply", markup: &tg.ReplyKeyboardForceReply{}, want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := extractButtons(tt.markup); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("extractButtons() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExtractMessagesButtons(t *testing.T) {
+	p := &Provider{}
+	msgs := p.extractMessages([]tg.MessageClass{
+		&tg.Message{ID: 1, Message: "Confirm your order?", PeerID: &tg.PeerUser{UserID: 7}, ReplyMarkup: confirmMarkup},
+		&tg.Message{ID: 2, Message: "plain", PeerID: &tg.PeerUser{UserID: 7}},
+	}, map[int64]string{7: "Shop Bot"}, nil, &tg.InputPeerUser{UserID: 7})
+
+	if len(msgs) != 2 {
+		t.Fatalf("got %d messages, want 2", len(msgs))
+	}
+	if len(msgs[0].Buttons) != 2 || msgs[0].Buttons[0][0].Text != "Confirm" {
+		t.Errorf("buttons = %+v", msgs[0].Buttons)
+	}
+	if msgs[1].Buttons != nil {
+		t.Errorf("plain message buttons = %+v, want nil", msgs[1].Buttons)
+	}
+}
+
+func TestFindButton(t *testing.T) {
+	tests := []struct {
+		name     string
+		markup   tg.ReplyMarkupClass
+		text     string
+		row, col int
+		wantText string
+		wantErr  bool
+	}{
+		{name: "by text", markup: confirmMarkup, text: "cancel", wantText: "Cancel"},
+		{name: "by text with spaces", markup: confirmMarkup, text: " Details ", wantText: "Details"},
+		{name: "by position", markup: confirmMarkup, row: 1, col: 0, wantText: "Details"},
+		{name: "unknown text", markup: confirmMarkup, text: "Maybe", wantErr: true},
+		{name: "row out of range", markup: confirmMarkup, row: 2, wantErr: true},
+		{name: "column out of range", markup: confirmMarkup, row: 0, col: 2, wantErr: true},
+		{name: "negative index", markup: confirmMarkup, row: -1, wantErr: true},
+		{name: "no markup", markup: nil, text: "Confirm", wantErr: true},
+		{name: "hidden keyboard", markup: &tg.ReplyKeyboardHide{}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := FindButton(tt.markup, tt.text, tt.row, tt.col)
+			if tt.wantErr {
+				if !errors.Is(err, ErrButtonNotFound) {
+					t.Fatalf("err = %v, want ErrButtonNotFound", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got.GetText() != tt.wantText {
+				t.Errorf("found %q, want %q", got.GetText(), tt.wantText)
+			}
+		})
+	}
+}
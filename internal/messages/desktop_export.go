@@ -0,0 +1,258 @@
+package messages
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DesktopExportFile is the file name Telegram Desktop uses for JSON exports.
+const DesktopExportFile = "result.json"
+
+// DesktopExport is a single chat exported with Telegram Desktop's "Export chat history" (JSON format).
+type DesktopExport struct {
+	ChatID   int64     `json:"chat_id"`
+	ChatName string    `json:"chat_name"`
+	ChatType string    `json:"chat_type"`
+	Messages []Message `json:"messages"` // chronological order, as in the export
+}
+
+// desktopExport mirrors the subset of result.json used by ImportDesktopExport.
+type desktopExport struct {
+	Name     string                 `json:"name"`
+	Type     string                 `json:"type"`
+	ID       int64                  `json:"id"`
+	Messages []desktopExportMessage `json:"messages"`
+	Chats    *struct {
+		List []json.RawMessage `json:"list"`
+	} `json:"chats"`
+}
+
+type desktopExportMessage struct {
+	ID               int             `json:"id"`
+	Type             string          `json:"type"`
+	Date             string          `json:"date"`
+	DateUnix         string          `json:"date_unixtime"`
+	From             *string         `json:"from"`
+	FromID           string          `json:"from_id"`
+	Actor            *string         `json:"actor"`
+	ActorID          string          `json:"actor_id"`
+	Action           string          `json:"action"`
+	Text             json.RawMessage `json:"text"`
+	ReplyToMessageID int             `json:"reply_to_message_id"`
+	Photo            string          `json:"photo"`
+	File             string          `json:"file"`
+	FileName         string          `json:"file_name"`
+	MimeType         string          `json:"mime_type"`
+	MediaType        string          `json:"media_type"`
+	Duration         int             `json:"duration_seconds"`
+	Width            int             `json:"width"`
+	Height           int             `json:"height"`
+	Location         json.RawMessage `json:"location_information"`
+	Contact          json.RawMessage `json:"contact_information"`
+	Poll             json.RawMessage `json:"poll"`
+}
+
+// desktopTextRun is one element of the array form of a message's "text" field.
+type desktopTextRun struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+	Href string `json:"href"`
+}
+
+// ImportDesktopExport reads a Telegram Desktop JSON export. The path may point
+// to result.json itself or to the export directory containing it.
+func ImportDesktopExport(path string) (*DesktopExport, error) {
+	if info, err := os.Stat(path); err == nil && info.IsDir() {
+		path = filepath.Join(path, DesktopExportFile)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading export: %w", err)
+	}
+	return ParseDesktopExport(data)
+}
+
+// ParseDesktopExport converts the contents of a Telegram Desktop result.json into messages.
+func ParseDesktopExport(data []byte) (*DesktopExport, error) {
+	var raw desktopExport
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("parsing export: %w", err)
+	}
+	if raw.Messages == nil {
+		if raw.Chats != nil {
+			return nil, fmt.Errorf("export contains %d chats; export a single chat's history instead", len(raw.Chats.List))
+		}
+		return nil, errors.New("export contains no messages")
+	}
+
+	export := &DesktopExport{
+		ChatID:   raw.ID,
+		ChatName: raw.Name,
+		ChatType: raw.Type,
+		Messages: make([]Message, 0, len(raw.Messages)),
+	}
+	for _, m := range raw.Messages {
+		msg, err := m.toMessage()
+		if err != nil {
+			return nil, fmt.Errorf("message %d: %w", m.ID, err)
+		}
+		export.Messages = append(export.Messages, msg)
+	}
+	return export, nil
+}
+
+func (m desktopExportMessage) toMessage() (Message, error) {
+	date, err := m.parseDate()
+	if err != nil {
+		return Message{}, err
+	}
+
+	text, entities, err := parseDesktopText(m.Text)
+	if err != nil {
+		return Message{}, err
+	}
+
+	msg := Message{
+		ID:        m.ID,
+		Date:      date,
+		Text:      text,
+		ReplyToID: m.ReplyToMessageID,
+		Entities:  entities,
+		Media:     m.media(),
+	}
+
+	if m.Type == "service" {
+		msg.SenderID = parseDesktopPeerID(m.ActorID)
+		msg.SenderName = senderName(m.Actor)
+		if msg.Text == "" {
+			msg.Text = fmt.Sprintf("[service: %s]", m.Action)
+		}
+		return msg, nil
+	}
+
+	msg.SenderID = parseDesktopPeerID(m.FromID)
+	msg.SenderName = senderName(m.From)
+	return msg, nil
+}
+
+// parseDate prefers the exact Unix timestamp of newer exports; older exports
+// only have a local time without zone.
+func (m desktopExportMessage) parseDate() (time.Time, error) {
+	if m.DateUnix != "" {
+		sec, err := strconv.ParseInt(m.DateUnix, 10, 64)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid date_unixtime %q: %w", m.DateUnix, err)
+		}
+		return time.Unix(sec, 0), nil
+	}
+	t, err := time.ParseInLocation("2006-01-02T15:04:05", m.Date, time.Local)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date %q: %w", m.Date, err)
+	}
+	return t, nil
+}
+
+// desktopMediaKinds maps an export's media_type to the kind of document-backed media;
+// any other file is a plain document.
+var desktopMediaKinds = map[string]string{
+	"video_file":    MediaVideo,
+	"video_message": MediaVideo,
+	"animation":     MediaVideo,
+	"voice_message": MediaVoice,
+	"audio_file":    MediaAudio,
+}
+
+func (m desktopExportMessage) media() *MediaInfo {
+	switch {
+	case m.Photo != "":
+		return &MediaInfo{Type: "photo", FileName: filepath.Base(m.Photo), Width: m.Width, Height: m.Height}
+	case m.File != "":
+		name := m.FileName
+		if name == "" {
+			name = filepath.Base(m.File)
+		}
+		kind, ok := desktopMediaKinds[m.MediaType]
+		if !ok {
+			kind = MediaDocument
+		}
+		return &MediaInfo{Type: kind, FileName: name, MimeType: m.MimeType, Duration: m.Duration, Width: m.Width, Height: m.Height}
+	case len(m.Location) > 0:
+		return &MediaInfo{Type: "geo"}
+	case len(m.Contact) > 0:
+		return &MediaInfo{Type: "contact"}
+	case len(m.Poll) > 0:
+		return &MediaInfo{Type: "poll"}
+	default:
+		return nil
+	}
+}
+
+// parseDesktopText handles both forms of the "text" field: a plain string, or an
+// array of plain strings and formatted runs. URLs are collected as entities,
+// matching what the Provider extracts from live messages.
+func parseDesktopText(raw json.RawMessage) (string, []string, error) {
+	if len(raw) == 0 {
+		return "", nil, nil
+	}
+
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		return s, nil, nil
+	}
+
+	var runs []json.RawMessage
+	if err := json.Unmarshal(raw, &runs); err != nil {
+		return "", nil, fmt.Errorf("invalid text: %w", err)
+	}
+
+	var sb strings.Builder
+	var entities []string
+	for _, r := range runs {
+		if err := json.Unmarshal(r, &s); err == nil {
+			sb.WriteString(s)
+			continue
+		}
+		var run desktopTextRun
+		if err := json.Unmarshal(r, &run); err != nil {
+			return "", nil, fmt.Errorf("invalid text run: %w", err)
+		}
+		sb.WriteString(run.Text)
+		switch run.Type {
+		case "link":
+			entities = append(entities, run.Text)
+		case "text_link":
+			entities = append(entities, run.Href)
+		}
+	}
+	return sb.String(), entities, nil
+}
+
+// parseDesktopPeerID extracts the numeric ID from export peer IDs such as
+// "user123", "channel456" or "chat789". Unknown formats yield 0.
+func parseDesktopPeerID(s string) int64 {
+	for _, prefix := range []string{"user", "channel", "chat"} {
+		if rest, ok := strings.CutPrefix(s, prefix); ok {
+			id, err := strconv.ParseInt(rest, 10, 64)
+			if err != nil {
+				return 0
+			}
+			return id
+		}
+	}
+	return 0
+}
+
+// senderName returns the export's display name, or "Unknown" for deleted accounts.
+func senderName(name *string) string {
+	if name == nil || *name == "" {
+		return "Unknown"
+	}
+	return *name
+}
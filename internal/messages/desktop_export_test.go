@@ -0,0 +1,129 @@
+package messages
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestImportDesktopExport(t *testing.T) {
+	export, err := ImportDesktopExport(filepath.Join("testdata", "desktop_export.json"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if export.ChatID != 4815162342 || export.ChatName != "Weekend Plans" || export.ChatType != "private_group" {
+		t.Errorf("chat metadata = %d %q %q", export.ChatID, export.ChatName, export.ChatType)
+	}
+
+	want := []Message{
+		{
+			ID:         1,
+			Date:       time.Unix(1686387600, 0),
+			SenderID:   1001,
+			SenderName: "Alice",
+			Text:       "[service: create_group]",
+		},
+		{
+			ID:         2,
+			Date:       time.Unix(1686387660, 0),
+			SenderID:   1001,
+			SenderName: "Alice",
+			Text:       "Hiking on Saturday?",
+		},
+		{
+			ID:         3,
+			Date:       time.Unix(1686387900, 0),
+			SenderID:   1002,
+			SenderName: "Bob",
+			Text:       "Sure, the trail is https://example.com/trail and the weather looks good",
+			ReplyToID:  2,
+			Entities:   []string{"https://example.com/trail", "https://example.com/weather"},
+		},
+		{
+			ID:         4,
+			Date:       time.Unix(1686387960, 0),
+			SenderID:   1002,
+			SenderName: "Bob",
+			Media:      &MediaInfo{Type: "photo", FileName: "photo_1@10-06-2023_09-06-00.jpg", Width: 1280, Height: 960},
+		},
+		{
+			ID:         5,
+			Date:       time.Unix(1686388200, 0),
+			SenderID:   1003,
+			SenderName: "Unknown",
+			Text:       "Route attached",
+			Media:      &MediaInfo{Type: "document", FileName: "route.gpx", MimeType: "application/gpx+xml"},
+		},
+		{
+			ID:         6,
+			Date:       time.Date(2023, 6, 10, 9, 12, 0, 0, time.Local),
+			SenderID:   2002,
+			SenderName: "Weekend Channel",
+			Text:       "Forecast: sunny",
+		},
+	}
+
+	if len(export.Messages) != len(want) {
+		t.Fatalf("got %d messages, want %d", len(export.Messages), len(want))
+	}
+	for i := range want {
+		if !reflect.DeepEqual(export.Messages[i], want[i]) {
+			t.Errorf("message %d:\ngot  %+v\nwant %+v", i, export.Messages[i], want[i])
+		}
+	}
+}
+
+func TestParseDesktopExportErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+	}{
+		{name: "not json", data: "<html>"},
+		{name: "full account export", data: `{"chats": {"list": [{}, {}]}}`},
+		{name: "no messages", data: `{"name": "Empty"}`},
+		{name: "bad date", data: `{"messages": [{"id": 1, "type": "message", "date": "yesterday"}]}`},
+		{name: "bad text", data: `{"messages": [{"id": 1, "type": "message", "date_unixtime": "1", "text": 42}]}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ParseDesktopExport([]byte(tt.data)); err == nil {
+				t.Fatal("expected error")
+			}
+		})
+	}
+}
+
+func TestDesktopExportMediaKinds(t *testing.T) {
+	tests := []struct {
+		name string
+		msg  desktopExportMessage
+		want *MediaInfo
+	}{
+		{
+			name: "voice message",
+			msg:  desktopExportMessage{File: "voice_messages/audio_1.ogg", MediaType: "voice_message", MimeType: "audio/ogg", Duration: 7},
+			want: &MediaInfo{Type: "voice", FileName: "audio_1.ogg", MimeType: "audio/ogg", Duration: 7},
+		},
+		{
+			name: "video file",
+			msg:  desktopExportMessage{File: "video_files/clip.mp4", MediaType: "video_file", MimeType: "video/mp4", Duration: 42, Width: 1920, Height: 1080},
+			want: &MediaInfo{Type: "video", FileName: "clip.mp4", MimeType: "video/mp4", Duration: 42, Width: 1920, Height: 1080},
+		},
+		{
+			name: "sticker as document",
+			msg:  desktopExportMessage{File: "stickers/sticker.webp", MediaType: "sticker", MimeType: "image/webp"},
+			want: &MediaInfo{Type: "document", FileName: "sticker.webp", MimeType: "image/webp"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.msg.media(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("media() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
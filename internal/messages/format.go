@@ -1,9 +1,13 @@
 package messages
 
 import (
+	"cmp"
 	"fmt"
+	"maps"
+	"slices"
 	"strconv"
 	"strings"
+	"time"
 )
 
 // DateFormat is the default timestamp format for messages.
@@ -13,18 +17,108 @@ const DateFormat = "2006-01-02 15:04:05"
 const ShortDateFormat = "2006-01-02 15:04"
 
 // FormatForSummary formats a message for LLM summarization.
-// Format: [timestamp] sender_id: text
+// Format: [timestamp] sender (via @bot) (edited) (reply to sender: "text"): text — signed: author
+// The sender is the name, or the ID when the name is unknown. The via, edited, reply
+// and signed parts only appear when they apply.
 func FormatForSummary(msg Message) string {
-	return fmt.Sprintf("[%s] %d: %s",
+	return formatSummaryLine(msg, false, SenderLabel)
+}
+
+// FormatForSummaryWithID formats a message for LLM summarization with its ID, so the
+// model can cite it.
+// Format: [timestamp] [#id] sender: text
+func FormatForSummaryWithID(msg Message) string {
+	return formatSummaryLine(msg, true, SenderLabel)
+}
+
+// SenderLabel returns how a sender appears in a summary line: the name, or the ID
+// when the name is unknown.
+func SenderLabel(id int64, name string) string {
+	switch {
+	case name != "" && name != unknownSender:
+		return name
+	case id != 0:
+		return strconv.FormatInt(id, 10)
+	default:
+		return unknownSender
+	}
+}
+
+// formatSummaryLine formats a message for summarization, naming its sender, the sender
+// it replies to and its post author with label.
+func formatSummaryLine(msg Message, withID bool, label func(id int64, name string) string) string {
+	var id string
+	if withID {
+		id = fmt.Sprintf(" [#%d]", msg.ID)
+	}
+	return fmt.Sprintf("[%s]%s %s%s: %s%s",
 		msg.Date.Format(ShortDateFormat),
-		msg.SenderID,
-		msg.Text,
+		id,
+		label(msg.SenderID, msg.SenderName),
+		summaryMarkers(msg, label),
+		summaryText(msg),
+		signature(msg, label),
 	)
 }
 
+// summaryText returns the text of a message, prefixed with what a grouped album
+// contains, e.g. "sent 5 photos: caption".
+func summaryText(msg Message) string {
+	label := msg.AlbumLabel()
+	switch {
+	case label == "":
+		return msg.Text
+	case msg.Text == "":
+		return label
+	default:
+		return label + ": " + msg.Text
+	}
+}
+
+// summaryMarkers returns the " (via @bot)", " (edited)" and resolved
+// " (reply to sender: "text")" markers that apply to a message.
+func summaryMarkers(msg Message, label func(id int64, name string) string) string {
+	var markers string
+	if msg.ViaBot != "" {
+		markers += " (via " + msg.ViaBot + ")"
+	}
+	if !msg.EditDate.IsZero() {
+		markers += " (edited)"
+	}
+	if msg.ReplyToText != "" {
+		markers += fmt.Sprintf(" (reply to %s: %q)", label(msg.ReplyToSenderID, msg.ReplyToSender), msg.ReplyToText)
+	}
+	return markers
+}
+
+// signature returns " — signed: author" for signed channel posts and "" otherwise.
+func signature(msg Message, label func(id int64, name string) string) string {
+	if msg.PostAuthor == "" {
+		return ""
+	}
+	return " — signed: " + label(0, msg.PostAuthor)
+}
+
 // FormatBatchForBackup formats a batch of messages for a backup file.
-// Format: -----\n[timestamp] [sender_name] [id=N] [reply_to=N]\n<text>\n-----
-func FormatBatchForBackup(messages []Message) string {
+// Format: -----\n[timestamp] [sender_name] [id=N] [reply_to=N] [via=@bot] [signed=author] [media=type] [album=N] [context] (edited timestamp)\n<text>\n-----
+// The [via] and [signed] tags carry inline bot and channel post author attribution.
+// The [media] tag gives the kind of media a captioned message carries, and [album=N]
+// the number of items of a grouped album.
+// The [context] tag marks messages included only because something in range replies to them.
+// The (edited timestamp) marker records the last edit of edited messages.
+// With verbose, the header goes on with [forwarded_from=name, timestamp] [views=N]
+// [forwards=N] [reactions=👍 3, ❤ 1] for the messages that have them.
+func FormatBatchForBackup(messages []Message, verbose bool) string {
+	entries := formatBackupEntries(messages, verbose)
+	if entries == "" {
+		return ""
+	}
+	return entries + backupSeparator
+}
+
+// formatBackupEntries formats the messages of FormatBatchForBackup without the closing
+// separator, so batches can be written one after another.
+func formatBackupEntries(messages []Message, verbose bool) string {
 	if len(messages) == 0 {
 		return ""
 	}
@@ -50,27 +144,123 @@ func FormatBatchForBackup(messages []Message) string {
 			sb.WriteString(strconv.Itoa(msg.ReplyToID))
 			sb.WriteByte(']')
 		}
+		if msg.ViaBot != "" {
+			sb.WriteString(" [via=")
+			sb.WriteString(msg.ViaBot)
+			sb.WriteByte(']')
+		}
+		if msg.PostAuthor != "" {
+			sb.WriteString(" [signed=")
+			sb.WriteString(msg.PostAuthor)
+			sb.WriteByte(']')
+		}
+		if msg.Media != nil {
+			sb.WriteString(" [media=")
+			sb.WriteString(msg.Media.Type)
+			sb.WriteByte(']')
+		}
+		if len(msg.Album) > 1 {
+			sb.WriteString(" [album=")
+			sb.WriteString(strconv.Itoa(len(msg.Album)))
+			sb.WriteByte(']')
+		}
+		if msg.Context {
+			sb.WriteString(" [context]")
+		}
+		if !msg.EditDate.IsZero() {
+			sb.WriteString(" (edited ")
+			sb.WriteString(msg.EditDate.Format(DateFormat))
+			sb.WriteByte(')')
+		}
+		if verbose {
+			writeVerboseTags(&sb, msg)
+		}
 
 		sb.WriteByte('\n')
 		sb.WriteString(msg.Text)
 		sb.WriteByte('\n')
 	}
 
-	if sb.Len() > 0 {
-		sb.WriteString("-----")
+	return sb.String()
+}
+
+// writeVerboseTags writes the forward origin and channel post statistics tags of a
+// verbose backup header.
+func writeVerboseTags(sb *strings.Builder, msg Message) {
+	if fwd := msg.ForwardedFrom; fwd != nil {
+		sb.WriteString(" [forwarded_from=")
+		sb.WriteString(fwd.From)
+		sb.WriteString(", ")
+		sb.WriteString(fwd.Date.Format(DateFormat))
+		sb.WriteByte(']')
+	}
+	if msg.Views > 0 {
+		sb.WriteString(" [views=")
+		sb.WriteString(strconv.Itoa(msg.Views))
+		sb.WriteByte(']')
 	}
+	if msg.Forwards > 0 {
+		sb.WriteString(" [forwards=")
+		sb.WriteString(strconv.Itoa(msg.Forwards))
+		sb.WriteByte(']')
+	}
+	if len(msg.Reactions) > 0 {
+		sb.WriteString(" [reactions=")
+		for i, reaction := range sortedReactions(msg.Reactions) {
+			if i > 0 {
+				sb.WriteString(", ")
+			}
+			sb.WriteString(reaction)
+			sb.WriteByte(' ')
+			sb.WriteString(strconv.Itoa(msg.Reactions[reaction]))
+		}
+		sb.WriteByte(']')
+	}
+}
 
-	return sb.String()
+// sortedReactions returns the reactions of counts, most frequent first.
+func sortedReactions(counts map[string]int) []string {
+	reactions := slices.Collect(maps.Keys(counts))
+	slices.SortFunc(reactions, func(a, b string) int {
+		if c := cmp.Compare(counts[b], counts[a]); c != 0 {
+			return c
+		}
+		return strings.Compare(a, b)
+	})
+	return reactions
+}
+
+// DataBoundaryPrefix starts the delimiters that enclose message content in LLM
+// prompts. The batch formatters escape it, so a message can't forge a delimiter and
+// break out of its data block.
+const DataBoundaryPrefix = "<<<TELEGRAM_DATA"
+
+// EscapeDataBoundary breaks up occurrences of DataBoundaryPrefix in untrusted text.
+func EscapeDataBoundary(s string) string {
+	return strings.ReplaceAll(s, DataBoundaryPrefix, "<< <TELEGRAM_DATA")
 }
 
 // FormatBatchForSummary formats a batch of messages for summarization.
 func FormatBatchForSummary(messages []Message) string {
 	var sb strings.Builder
 	for _, msg := range messages {
-		if msg.Text == "" {
+		if msg.Text == "" && msg.AlbumLabel() == "" {
+			continue
+		}
+		sb.WriteString(EscapeDataBoundary(FormatForSummary(msg)))
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
+
+// FormatBatchForSummaryWithIDs formats a batch of messages for summarization with citable IDs.
+func FormatBatchForSummaryWithIDs(messages []Message) string {
+	var sb strings.Builder
+	for _, msg := range messages {
+		if msg.Text == "" && msg.AlbumLabel() == "" {
 			continue
 		}
-		sb.WriteString(FormatForSummary(msg))
+		sb.WriteString(EscapeDataBoundary(FormatForSummaryWithID(msg)))
 		sb.WriteString("\n")
 	}
 	return sb.String()
@@ -87,9 +277,51 @@ func FilterTextOnly(messages []Message) []Message {
 	return result
 }
 
+// FilterEditedSince returns only messages edited after since.
+func FilterEditedSince(messages []Message, since time.Time) []Message {
+	result := make([]Message, 0, len(messages))
+	for _, msg := range messages {
+		if msg.EditDate.After(since) {
+			result = append(result, msg)
+		}
+	}
+	return result
+}
+
+// CountUnread returns how many messages are incoming and newer than the read inbox
+// position, counting each item of a grouped album. Messages without the raw message
+// are counted as incoming.
+func CountUnread(messages []Message, readInboxMaxID int) int {
+	n := 0
+	for _, msg := range messages {
+		if msg.Raw != nil && msg.Raw.Out {
+			continue
+		}
+		for _, id := range msg.IDs() {
+			if id > readInboxMaxID {
+				n++
+			}
+		}
+	}
+	return n
+}
+
 // Reverse reverses a slice of messages in place.
 func Reverse(messages []Message) {
 	for i, j := 0, len(messages)-1; i < j; i, j = i+1, j-1 {
 		messages[i], messages[j] = messages[j], messages[i]
 	}
 }
+
+// TruncateRunes truncates s to n runes without allocating a full []rune slice.
+// If s is longer than n runes, it returns the first n runes followed by "...".
+func TruncateRunes(s string, n int) string {
+	i := 0
+	for j := range s {
+		if i == n {
+			return s[:j] + "..."
+		}
+		i++
+	}
+	return s
+}
@@ -0,0 +1,168 @@
+package messages
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gotd/td/tg"
+)
+
+func TestFormatMarkers(t *testing.T) {
+	date := time.Date(2024, 1, 15, 10, 0, 0, 0, time.Local)
+	plain := Message{ID: 7, Date: date, SenderID: 42, SenderName: "Anna", Text: "hello"}
+	edited := plain
+	edited.EditDate = date.Add(5 * time.Minute)
+	signed := plain
+	signed.PostAuthor = "Maria"
+	viaBot := edited
+	viaBot.ViaBot = "@gif"
+	unnamed := plain
+	unnamed.SenderName = "Unknown"
+
+	tests := []struct {
+		name string
+		got  string
+		want string
+	}{
+		{name: "summary", got: FormatForSummary(plain), want: "[2024-01-15 10:00] Anna: hello"},
+		{name: "summary edited", got: FormatForSummary(edited), want: "[2024-01-15 10:00] Anna (edited): hello"},
+		{name: "summary with ID edited", got: FormatForSummaryWithID(edited), want: "[2024-01-15 10:00] [#7] Anna (edited): hello"},
+		{name: "summary signed", got: FormatForSummary(signed), want: "[2024-01-15 10:00] Anna: hello — signed: Maria"},
+		{name: "summary via bot", got: FormatForSummary(viaBot), want: "[2024-01-15 10:00] Anna (via @gif) (edited): hello"},
+		{name: "summary unknown name", got: FormatForSummary(unnamed), want: "[2024-01-15 10:00] 42: hello"},
+		{name: "backup", got: FormatBatchForBackup([]Message{plain}, false), want: "-----\n[2024-01-15 10:00:00] [Anna] [id=7]\nhello\n-----"},
+		{name: "backup edited", got: FormatBatchForBackup([]Message{edited}, false), want: "-----\n[2024-01-15 10:00:00] [Anna] [id=7] (edited 2024-01-15 10:05:00)\nhello\n-----"},
+		{name: "backup attribution", got: FormatBatchForBackup([]Message{viaBot, signed}, false), want: "-----\n[2024-01-15 10:00:00] [Anna] [id=7] [via=@gif] (edited 2024-01-15 10:05:00)\nhello\n-----\n[2024-01-15 10:00:00] [Anna] [id=7] [signed=Maria]\nhello\n-----"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.got != tt.want {
+				t.Errorf("got %q, want %q", tt.got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFilterEditedSince(t *testing.T) {
+	since := time.Date(2024, 1, 15, 12, 0, 0, 0, time.UTC)
+	msgs := []Message{
+		{ID: 1, Text: "never edited"},
+		{ID: 2, Text: "edited before", EditDate: since.Add(-time.Hour)},
+		{ID: 3, Text: "edited exactly at since", EditDate: since},
+		{ID: 4, Text: "edited after", EditDate: since.Add(time.Minute)},
+	}
+
+	got := FilterEditedSince(msgs, since)
+	var ids []string
+	for _, m := range got {
+		ids = append(ids, m.Text)
+	}
+	if len(got) != 1 || got[0].ID != 4 {
+		t.Errorf("got %s, want only the message edited after since", strings.Join(ids, ", "))
+	}
+}
+
+func TestCountUnread(t *testing.T) {
+	msgs := []Message{
+		{ID: 12, Raw: &tg.Message{ID: 12}},
+		{ID: 11, Raw: &tg.Message{ID: 11, Out: true}}, // My own reply isn't unread
+		{ID: 10},
+		{ID: 9, Raw: &tg.Message{ID: 9}}, // Already read
+	}
+	if got := CountUnread(msgs, 9); got != 2 {
+		t.Errorf("CountUnread() = %d, want 2", got)
+	}
+}
+
+func TestTruncateRunes(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		n        int
+		expected string
+	}{
+		{
+			name:     "empty string",
+			input:    "",
+			n:        10,
+			expected: "",
+		},
+		{
+			name:     "shorter than limit",
+			input:    "hello",
+			n:        10,
+			expected: "hello",
+		},
+		{
+			name:     "exact length",
+			input:    "hello",
+			n:        5,
+			expected: "hello",
+		},
+		{
+			name:     "longer than limit",
+			input:    "hello world",
+			n:        5,
+			expected: "hello...",
+		},
+		{
+			name:     "cyrillic shorter",
+			input:    "привет",
+			n:        10,
+			expected: "привет",
+		},
+		{
+			name:     "cyrillic exact",
+			input:    "привет",
+			n:        6,
+			expected: "привет",
+		},
+		{
+			name:     "cyrillic truncate",
+			input:    "привет мир",
+			n:        6,
+			expected: "привет...",
+		},
+		{
+			name:     "emoji truncate",
+			input:    "hello 🌍🌎🌏 world",
+			n:        8,
+			expected: "hello 🌍🌎...",
+		},
+		{
+			name:     "mixed unicode",
+			input:    "hello привет 世界",
+			n:        10,
+			expected: "hello прив...",
+		},
+		{
+			name:     "zero limit",
+			input:    "hello",
+			n:        0,
+			expected: "...",
+		},
+		{
+			name:     "limit one",
+			input:    "hello",
+			n:        1,
+			expected: "h...",
+		},
+		{
+			name:     "chinese characters",
+			input:    "你好世界",
+			n:        2,
+			expected: "你好...",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := TruncateRunes(tt.input, tt.n)
+			if result != tt.expected {
+				t.Errorf("TruncateRunes(%q, %d) = %q, want %q", tt.input, tt.n, result, tt.expected)
+			}
+		})
+	}
+}
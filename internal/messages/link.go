@@ -0,0 +1,38 @@
+package messages
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// MessageLink returns a t.me link to a message in a channel or supergroup.
+// Public chats use https://t.me/{username}/{id}; private ones use https://t.me/c/{channel_id}/{id},
+// which only opens for members. channelID is the raw MTProto channel ID (without the -100 prefix).
+// Private user chats and basic groups have no message links, so callers pass channelID 0 for them.
+func MessageLink(username string, channelID int64, msgID int) string {
+	if msgID <= 0 {
+		return ""
+	}
+	if username != "" {
+		return fmt.Sprintf("https://t.me/%s/%d", username, msgID)
+	}
+	if channelID > 0 {
+		return fmt.Sprintf("https://t.me/c/%d/%d", channelID, msgID)
+	}
+	return ""
+}
+
+// LinkDomain returns the lowercase host of a URL from a message, without "www.", or ""
+// when it has none. Links typed without a scheme, such as "example.com/page", are
+// accepted.
+func LinkDomain(link string) string {
+	if !strings.Contains(link, "://") {
+		link = "https://" + link
+	}
+	u, err := url.Parse(link)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+}
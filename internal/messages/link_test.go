@@ -0,0 +1,39 @@
+package messages
+
+import "testing"
+
+func TestMessageLink(t *testing.T) {
+	tests := []struct {
+		name      string
+		username  string
+		channelID int64
+		msgID     int
+		want      string
+	}{
+		{name: "public channel", username: "durov", channelID: 1006503122, msgID: 42, want: "https://t.me/durov/42"},
+		{name: "private channel", channelID: 1234567890, msgID: 7, want: "https://t.me/c/1234567890/7"},
+		{name: "no channel", msgID: 7, want: ""},
+		{name: "no message", username: "durov", msgID: 0, want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := MessageLink(tt.username, tt.channelID, tt.msgID); got != tt.want {
+				t.Errorf("MessageLink(%q, %d, %d) = %q, want %q", tt.username, tt.channelID, tt.msgID, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLinkDomain(t *testing.T) {
+	for link, want := range map[string]string{
+		"https://www.GitHub.com/golang/go": "github.com",
+		"go.dev/doc":                       "go.dev",
+		"http://example.org:8080/x?y=1":    "example.org",
+		"https://":                         "",
+	} {
+		if got := LinkDomain(link); got != want {
+			t.Errorf("LinkDomain(%q) = %q, want %q", link, got, want)
+		}
+	}
+}
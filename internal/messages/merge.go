@@ -0,0 +1,75 @@
+package messages
+
+import (
+	"strings"
+	"time"
+)
+
+// DefaultMergeGap is the default maximum gap between consecutive messages
+// from the same sender that are merged into one logical message.
+const DefaultMergeGap = 3 * time.Minute
+
+// MergeConsecutive merges consecutive messages from the same sender that were sent
+// within gap of the previous one into a single logical message. Messages must be in
+// chronological order. The merged message keeps the ID and timestamp of the first
+// component, joins texts with newlines, and keeps the first reply-to marker found
+// among its components. The input slice is not modified.
+func MergeConsecutive(msgs []Message, gap time.Duration) []Message {
+	if len(msgs) == 0 {
+		return nil
+	}
+
+	result := make([]Message, 0, len(msgs))
+	current := msgs[0]
+	last := msgs[0].Date
+
+	for _, msg := range msgs[1:] {
+		if canMerge(current, msg, last, gap) {
+			current = mergeInto(current, msg)
+			last = msg.Date
+			continue
+		}
+		result = append(result, current)
+		current = msg
+		last = msg.Date
+	}
+
+	return append(result, current)
+}
+
+// canMerge reports whether msg continues the logical message started by current,
+// where last is the timestamp of current's latest component.
+func canMerge(current, msg Message, last time.Time, gap time.Duration) bool {
+	if current.SenderID == 0 || msg.SenderID != current.SenderID {
+		return false
+	}
+	delta := msg.Date.Sub(last)
+	return delta >= 0 && delta <= gap
+}
+
+// mergeInto appends msg to the merged message current.
+func mergeInto(current, msg Message) Message {
+	switch {
+	case current.Text == "":
+		current.Text = msg.Text
+	case msg.Text != "":
+		var sb strings.Builder
+		sb.Grow(len(current.Text) + 1 + len(msg.Text))
+		sb.WriteString(current.Text)
+		sb.WriteByte('\n')
+		sb.WriteString(msg.Text)
+		current.Text = sb.String()
+	}
+
+	if current.ReplyToID == 0 {
+		current.ReplyToID = msg.ReplyToID
+	}
+	if current.Media == nil {
+		current.Media = msg.Media
+	}
+	if len(msg.Entities) > 0 {
+		current.Entities = append(current.Entities[:len(current.Entities):len(current.Entities)], msg.Entities...)
+	}
+
+	return current
+}
@@ -0,0 +1,136 @@
+package messages
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestMergeConsecutive(t *testing.T) {
+	base := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	at := func(minutes float64) time.Time {
+		return base.Add(time.Duration(minutes * float64(time.Minute)))
+	}
+
+	tests := []struct {
+		name  string
+		input []Message
+		gap   time.Duration
+		want  []Message
+	}{
+		{
+			name:  "empty",
+			input: nil,
+			gap:   DefaultMergeGap,
+			want:  nil,
+		},
+		{
+			name: "rapid-fire messages merged",
+			input: []Message{
+				{ID: 1, Date: at(0), SenderID: 10, Text: "hey"},
+				{ID: 2, Date: at(0.5), SenderID: 10, Text: "are you there?"},
+				{ID: 3, Date: at(2), SenderID: 10, Text: "need help"},
+			},
+			gap: DefaultMergeGap,
+			want: []Message{
+				{ID: 1, Date: at(0), SenderID: 10, Text: "hey\nare you there?\nneed help"},
+			},
+		},
+		{
+			name: "gap measured from previous component",
+			input: []Message{
+				{ID: 1, Date: at(0), SenderID: 10, Text: "a"},
+				{ID: 2, Date: at(2.5), SenderID: 10, Text: "b"},
+				{ID: 3, Date: at(5), SenderID: 10, Text: "c"},
+			},
+			gap: DefaultMergeGap,
+			want: []Message{
+				{ID: 1, Date: at(0), SenderID: 10, Text: "a\nb\nc"},
+			},
+		},
+		{
+			name: "gap exceeded starts new message",
+			input: []Message{
+				{ID: 1, Date: at(0), SenderID: 10, Text: "morning"},
+				{ID: 2, Date: at(4), SenderID: 10, Text: "anyone?"},
+			},
+			gap: DefaultMergeGap,
+			want: []Message{
+				{ID: 1, Date: at(0), SenderID: 10, Text: "morning"},
+				{ID: 2, Date: at(4), SenderID: 10, Text: "anyone?"},
+			},
+		},
+		{
+			name: "sender change breaks run",
+			input: []Message{
+				{ID: 1, Date: at(0), SenderID: 10, Text: "a"},
+				{ID: 2, Date: at(0.1), SenderID: 20, Text: "b"},
+				{ID: 3, Date: at(0.2), SenderID: 10, Text: "c"},
+				{ID: 4, Date: at(0.3), SenderID: 10, Text: "d"},
+			},
+			gap: DefaultMergeGap,
+			want: []Message{
+				{ID: 1, Date: at(0), SenderID: 10, Text: "a"},
+				{ID: 2, Date: at(0.1), SenderID: 20, Text: "b"},
+				{ID: 3, Date: at(0.2), SenderID: 10, Text: "c\nd"},
+			},
+		},
+		{
+			name: "reply marker preserved from later component",
+			input: []Message{
+				{ID: 1, Date: at(0), SenderID: 10, Text: "about that"},
+				{ID: 2, Date: at(1), SenderID: 10, Text: "I agree", ReplyToID: 99},
+				{ID: 3, Date: at(1.5), SenderID: 10, Text: "mostly", ReplyToID: 100},
+			},
+			gap: DefaultMergeGap,
+			want: []Message{
+				{ID: 1, Date: at(0), SenderID: 10, Text: "about that\nI agree\nmostly", ReplyToID: 99},
+			},
+		},
+		{
+			name: "unknown senders never merged",
+			input: []Message{
+				{ID: 1, Date: at(0), Text: "a"},
+				{ID: 2, Date: at(0.1), Text: "b"},
+			},
+			gap: DefaultMergeGap,
+			want: []Message{
+				{ID: 1, Date: at(0), Text: "a"},
+				{ID: 2, Date: at(0.1), Text: "b"},
+			},
+		},
+		{
+			name: "entities combined",
+			input: []Message{
+				{ID: 1, Date: at(0), SenderID: 10, Text: "see", Entities: []string{"https://a.example"}},
+				{ID: 2, Date: at(0.2), SenderID: 10, Text: "and", Entities: []string{"https://b.example"}},
+			},
+			gap: DefaultMergeGap,
+			want: []Message{
+				{ID: 1, Date: at(0), SenderID: 10, Text: "see\nand", Entities: []string{"https://a.example", "https://b.example"}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := MergeConsecutive(tt.input, tt.gap)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("MergeConsecutive() =\n%+v\nwant\n%+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMergeConsecutiveDoesNotModifyInput(t *testing.T) {
+	base := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	input := []Message{
+		{ID: 1, Date: base, SenderID: 10, Text: "a", Entities: []string{"x"}},
+		{ID: 2, Date: base.Add(time.Second), SenderID: 10, Text: "b", Entities: []string{"y"}},
+	}
+	_ = MergeConsecutive(input, DefaultMergeGap)
+
+	if input[0].Text != "a" || len(input[0].Entities) != 1 {
+		t.Errorf("input was modified: %+v", input[0])
+	}
+}
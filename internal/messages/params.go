@@ -0,0 +1,249 @@
+package messages
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// MaxFetchLimit is the maximum number of messages a single fetch may request.
+const MaxFetchLimit = 100
+
+// fetchParam describes a single user-facing parameter that maps onto FetchOptions.
+type fetchParam struct {
+	name  string
+	apply func(opts *FetchOptions, value any) error
+}
+
+// fetchParams is the single source of truth for parameters accepted by every
+// message-fetching surface (GetMessages tool, chat messages resource, pinned chats).
+var fetchParams = []fetchParam{
+	{
+		name: "limit",
+		apply: func(opts *FetchOptions, value any) error {
+			limit, err := paramInt(value)
+			if err != nil {
+				return err
+			}
+			if limit > 0 {
+				opts.Limit = min(limit, MaxFetchLimit)
+			}
+			return nil
+		},
+	},
+	{
+		name: "offset_id",
+		apply: func(opts *FetchOptions, value any) error {
+			offsetID, err := paramInt(value)
+			if err != nil {
+				return err
+			}
+			if offsetID > 0 {
+				opts.OffsetID = offsetID
+			}
+			return nil
+		},
+	},
+	{
+		name: "min_id",
+		apply: func(opts *FetchOptions, value any) error {
+			minID, err := paramInt(value)
+			if err != nil {
+				return err
+			}
+			if minID > 0 {
+				opts.MinID = minID
+			}
+			return nil
+		},
+	},
+	{
+		name: "max_id",
+		apply: func(opts *FetchOptions, value any) error {
+			maxID, err := paramInt(value)
+			if err != nil {
+				return err
+			}
+			if maxID > 0 {
+				opts.MaxID = maxID
+			}
+			return nil
+		},
+	},
+	{
+		name: "unread_only",
+		apply: func(opts *FetchOptions, value any) error {
+			unreadOnly, err := paramBool(value)
+			if err != nil {
+				return err
+			}
+			opts.UnreadOnly = unreadOnly
+			return nil
+		},
+	},
+	{
+		name: "resolve_replies",
+		apply: func(opts *FetchOptions, value any) error {
+			resolve, err := paramBool(value)
+			if err != nil {
+				return err
+			}
+			opts.ResolveReplies = resolve
+			return nil
+		},
+	},
+	{
+		name: "topic_id",
+		apply: func(opts *FetchOptions, value any) error {
+			topicID, err := paramInt(value)
+			if err != nil {
+				return err
+			}
+			if topicID < 0 {
+				return fmt.Errorf("must be positive, got %d", topicID)
+			}
+			opts.ThreadID = topicID
+			return nil
+		},
+	},
+	{
+		name: "since",
+		apply: func(opts *FetchOptions, value any) error {
+			since, ok := value.(string)
+			if !ok {
+				return fmt.Errorf("expected a string, got %T", value)
+			}
+			if since != "" && since != SinceLastRead {
+				return fmt.Errorf("%q must be '%s'", since, SinceLastRead)
+			}
+			opts.SinceLastRead = since == SinceLastRead
+			return nil
+		},
+	},
+	{
+		name: "edited_since",
+		apply: func(opts *FetchOptions, value any) error {
+			s, ok := value.(string)
+			if !ok {
+				return fmt.Errorf("expected a string, got %T", value)
+			}
+			editedSince, _, err := ParseDate(s, time.Local)
+			if err != nil {
+				return err
+			}
+			opts.EditedSince = editedSince
+			return nil
+		},
+	},
+}
+
+// FetchParamNames returns the names of all parameters accepted by ParseFetchParams.
+func FetchParamNames() []string {
+	names := make([]string, len(fetchParams))
+	for i, p := range fetchParams {
+		names[i] = p.name
+	}
+	return names
+}
+
+// ParseFetchParams maps a generic parameter map (tool arguments or resource query values)
+// to FetchOptions, starting from DefaultFetchOptions. Unknown keys are ignored so callers
+// can pass their full argument map.
+func ParseFetchParams(params map[string]any) (FetchOptions, error) {
+	opts := DefaultFetchOptions()
+	for _, p := range fetchParams {
+		value, ok := params[p.name]
+		if !ok || value == nil {
+			continue
+		}
+		if err := p.apply(&opts, value); err != nil {
+			return FetchOptions{}, fmt.Errorf("invalid %s: %w", p.name, err)
+		}
+	}
+	if opts.MinID > 0 && opts.MaxID > 0 && opts.MinID >= opts.MaxID {
+		return FetchOptions{}, fmt.Errorf("min_id %d must be less than max_id %d", opts.MinID, opts.MaxID)
+	}
+	if opts.SinceLastRead && opts.ThreadID != 0 {
+		return FetchOptions{}, fmt.Errorf("since='%s' follows the read position of the whole chat and can't be combined with topic_id", SinceLastRead)
+	}
+	return opts, nil
+}
+
+// ParseDate parses a date string in format YYYY-MM-DD or YYYY-MM-DD HH:MM:SS in loc.
+// hasTime reports whether a time of day was given. An empty string is the zero time.
+func ParseDate(s string, loc *time.Location) (t time.Time, hasTime bool, err error) {
+	if s == "" {
+		return time.Time{}, false, nil
+	}
+	// Try the full datetime format first
+	t, err = time.ParseInLocation("2006-01-02 15:04:05", s, loc)
+	if err == nil {
+		return t, true, nil
+	}
+	// Try a date-only format
+	t, err = time.ParseInLocation("2006-01-02", s, loc)
+	if err == nil {
+		return t, false, nil
+	}
+	return time.Time{}, false, fmt.Errorf("invalid date format %q, expected YYYY-MM-DD or YYYY-MM-DD HH:MM:SS", s)
+}
+
+// QueryParams converts URL query values into a parameter map suitable for ParseFetchParams.
+// Only the first value of each key is used.
+func QueryParams(values url.Values) map[string]any {
+	params := make(map[string]any, len(values))
+	for key, vals := range values {
+		if len(vals) > 0 {
+			params[key] = vals[0]
+		}
+	}
+	return params
+}
+
+// paramInt converts a JSON number, Go integer or decimal string to int.
+func paramInt(value any) (int, error) {
+	switch v := value.(type) {
+	case int:
+		return v, nil
+	case int64:
+		return int(v), nil
+	case float64:
+		if v != math.Trunc(v) {
+			return 0, fmt.Errorf("expected an integer, got %v", v)
+		}
+		return int(v), nil
+	case json.Number:
+		n, err := v.Int64()
+		if err != nil {
+			return 0, fmt.Errorf("expected an integer, got %q", v.String())
+		}
+		return int(n), nil
+	case string:
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return 0, fmt.Errorf("expected an integer, got %q", v)
+		}
+		return n, nil
+	default:
+		return 0, fmt.Errorf("expected an integer, got %T", value)
+	}
+}
+
+// paramBool converts a JSON boolean or a boolean string ("true", "1", ...) to bool.
+func paramBool(value any) (bool, error) {
+	switch v := value.(type) {
+	case bool:
+		return v, nil
+	case string:
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return false, fmt.Errorf("expected a boolean, got %q", v)
+		}
+		return b, nil
+	default:
+		return false, fmt.Errorf("expected a boolean, got %T", value)
+	}
+}
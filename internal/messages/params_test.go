@@ -0,0 +1,200 @@
+package messages
+
+import (
+	"net/url"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+)
+
+// fetchParamCases enumerates every supported fetch parameter once, with the value
+// as a tool would receive it (decoded JSON) and as a resource would (query string).
+var fetchParamCases = []struct {
+	name       string
+	toolValue  any
+	queryValue string
+	want       func(opts *FetchOptions)
+}{
+	{
+		name:       "limit",
+		toolValue:  float64(20),
+		queryValue: "20",
+		want:       func(opts *FetchOptions) { opts.Limit = 20 },
+	},
+	{
+		name:       "offset_id",
+		toolValue:  float64(12345),
+		queryValue: "12345",
+		want:       func(opts *FetchOptions) { opts.OffsetID = 12345 },
+	},
+	{
+		name:       "min_id",
+		toolValue:  float64(500),
+		queryValue: "500",
+		want:       func(opts *FetchOptions) { opts.MinID = 500 },
+	},
+	{
+		name:       "max_id",
+		toolValue:  float64(900),
+		queryValue: "900",
+		want:       func(opts *FetchOptions) { opts.MaxID = 900 },
+	},
+	{
+		name:       "unread_only",
+		toolValue:  true,
+		queryValue: "true",
+		want:       func(opts *FetchOptions) { opts.UnreadOnly = true },
+	},
+	{
+		name:       "resolve_replies",
+		toolValue:  true,
+		queryValue: "true",
+		want:       func(opts *FetchOptions) { opts.ResolveReplies = true },
+	},
+	{
+		name:       "topic_id",
+		toolValue:  float64(7),
+		queryValue: "7",
+		want:       func(opts *FetchOptions) { opts.ThreadID = 7 },
+	},
+	{
+		name:       "since",
+		toolValue:  SinceLastRead,
+		queryValue: SinceLastRead,
+		want:       func(opts *FetchOptions) { opts.SinceLastRead = true },
+	},
+	{
+		name:       "edited_since",
+		toolValue:  "2024-03-01 12:00:00",
+		queryValue: "2024-03-01 12:00:00",
+		want:       func(opts *FetchOptions) { opts.EditedSince = time.Date(2024, 3, 1, 12, 0, 0, 0, time.Local) },
+	},
+}
+
+func TestFetchParamCasesCoverAllParams(t *testing.T) {
+	var covered []string
+	for _, tc := range fetchParamCases {
+		covered = append(covered, tc.name)
+	}
+	names := FetchParamNames()
+	sort.Strings(covered)
+	sort.Strings(names)
+	if !reflect.DeepEqual(covered, names) {
+		t.Fatalf("test cases cover %v, but supported params are %v", covered, names)
+	}
+}
+
+func TestParseFetchParamsSurfacesAgree(t *testing.T) {
+	for _, tc := range fetchParamCases {
+		t.Run(tc.name, func(t *testing.T) {
+			want := DefaultFetchOptions()
+			tc.want(&want)
+
+			fromTool, err := ParseFetchParams(map[string]any{tc.name: tc.toolValue})
+			if err != nil {
+				t.Fatalf("tool arguments: unexpected error: %v", err)
+			}
+
+			fromQuery, err := ParseFetchParams(QueryParams(url.Values{tc.name: {tc.queryValue}}))
+			if err != nil {
+				t.Fatalf("query values: unexpected error: %v", err)
+			}
+
+			if !reflect.DeepEqual(fromTool, want) {
+				t.Errorf("tool arguments: got %+v, want %+v", fromTool, want)
+			}
+			if !reflect.DeepEqual(fromQuery, want) {
+				t.Errorf("query values: got %+v, want %+v", fromQuery, want)
+			}
+		})
+	}
+}
+
+func TestParseFetchParams(t *testing.T) {
+	tests := []struct {
+		name    string
+		params  map[string]any
+		want    FetchOptions
+		wantErr bool
+	}{
+		{
+			name:   "defaults",
+			params: nil,
+			want:   DefaultFetchOptions(),
+		},
+		{
+			name:   "limit capped",
+			params: map[string]any{"limit": float64(500)},
+			want:   FetchOptions{Limit: MaxFetchLimit},
+		},
+		{
+			name:   "non-positive values ignored",
+			params: map[string]any{"limit": float64(0), "offset_id": float64(-5)},
+			want:   DefaultFetchOptions(),
+		},
+		{
+			name:   "unknown keys ignored",
+			params: map[string]any{"chat_id": float64(42)},
+			want:   DefaultFetchOptions(),
+		},
+		{
+			name:    "fractional limit",
+			params:  map[string]any{"limit": 1.5},
+			wantErr: true,
+		},
+		{
+			name:    "malformed query limit",
+			params:  map[string]any{"limit": "ten"},
+			wantErr: true,
+		},
+		{
+			name:    "malformed boolean",
+			params:  map[string]any{"unread_only": "maybe"},
+			wantErr: true,
+		},
+		{
+			name:    "unknown since",
+			params:  map[string]any{"since": "yesterday"},
+			wantErr: true,
+		},
+		{
+			name:    "malformed edited_since",
+			params:  map[string]any{"edited_since": "yesterday"},
+			wantErr: true,
+		},
+		{
+			name:    "negative topic_id",
+			params:  map[string]any{"topic_id": float64(-1)},
+			wantErr: true,
+		},
+		{
+			name:    "since last_read in a topic",
+			params:  map[string]any{"since": SinceLastRead, "topic_id": float64(7)},
+			wantErr: true,
+		},
+		{
+			name:    "empty ID range",
+			params:  map[string]any{"min_id": float64(900), "max_id": float64(900)},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseFetchParams(tt.params)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected error, got %+v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -4,6 +4,9 @@ import (
 	"context"
 	"encoding/base64"
 	"fmt"
+	"math"
+	"net/url"
+	"strings"
 	"time"
 
 	"github.com/gotd/td/tg"
@@ -12,38 +15,77 @@ import (
 	"github.com/tolmachov/mcp-telegram/internal/tgclient"
 )
 
-// offsetDateBuffer is added to MaxDate when fetching messages because
-// Telegram's API returns messages BEFORE the offset date, and we want
-// to include messages from the MaxDate day itself.
+// offsetDateBuffer is added to a date-only MaxDate because Telegram's API
+// returns messages BEFORE the offset date, and we want to include messages
+// from the MaxDate day itself.
 const offsetDateBuffer = 24 * time.Hour
 
+// maxDateBound returns the exclusive upper bound for message dates, or zero when
+// MaxDate is unset. A date-only MaxDate covers its whole day; an exact MaxDate is
+// inclusive to the second, matching Telegram's date resolution.
+func (o FetchOptions) maxDateBound() time.Time {
+	if o.MaxDate.IsZero() {
+		return time.Time{}
+	}
+	if o.MaxDateExact {
+		return o.MaxDate.Add(time.Second)
+	}
+	return o.MaxDate.Add(offsetDateBuffer)
+}
+
 // Provider fetches messages from Telegram with a unified interface.
 type Provider struct {
 	client  *tg.Client
+	peers   *tgclient.PeerCache
 	limiter ratelimit.Limiter
 }
 
-// NewProvider creates a new message provider with 1 RPS rate limiting.
-func NewProvider(client *tg.Client) *Provider {
+// NewProvider creates a new message provider resolving chats through peers, whose
+// requests go through limiter, shared with the other paced Telegram calls.
+func NewProvider(client *tg.Client, peers *tgclient.PeerCache, limiter ratelimit.Limiter) *Provider {
 	return &Provider{
 		client:  client,
-		limiter: ratelimit.New(1),
+		peers:   peers,
+		limiter: limiter,
 	}
 }
 
 // Fetch retrieves messages from a chat with the given options.
 // It handles pagination internally and returns enriched messages with sender names.
 func (p *Provider) Fetch(ctx context.Context, chatID int64, opts FetchOptions) (*FetchResult, error) {
-	peer, err := tgclient.ResolvePeer(ctx, p.client, chatID)
-	if err != nil {
-		return nil, fmt.Errorf("resolving peer: %w", err)
-	}
-
-	result, err := p.fetchWithPeer(ctx, peer, opts)
+	var result *FetchResult
+	var readInboxMaxID int
+	err := p.peers.WithPeer(ctx, p.client, chatID, func(peer tg.InputPeerClass) error {
+		pageOpts := opts
+		if opts.SinceLastRead {
+			p.limiter.Take()
+			var err error
+			if readInboxMaxID, err = p.getReadInboxMaxID(ctx, peer); err != nil {
+				return fmt.Errorf("getting read position: %w", err)
+			}
+			pageOpts.MinID = max(pageOpts.MinID, readInboxMaxID)
+		}
+		var err error
+		result, err = p.fetchWithPeer(ctx, peer, pageOpts)
+		return err
+	})
 	if err != nil {
 		return nil, err
 	}
 	result.ChatID = chatID
+	// NextID stays the oldest message fetched, so paging resumes after it
+	if opts.GroupAlbums {
+		result.Messages = GroupAlbums(result.Messages)
+		result.Count = len(result.Messages)
+	}
+	if opts.SinceLastRead {
+		unread := CountUnread(result.Messages, readInboxMaxID)
+		result.ReadInboxMaxID, result.UnreadCovered = &readInboxMaxID, &unread
+	}
+	if !opts.EditedSince.IsZero() {
+		result.Messages = FilterEditedSince(result.Messages, opts.EditedSince)
+		result.Count = len(result.Messages)
+	}
 	return result, nil
 }
 
@@ -63,22 +105,108 @@ func (p *Provider) fetchWithPeer(ctx context.Context, peer tg.InputPeerClass, op
 	}
 
 	historyRequest := &tg.MessagesGetHistoryRequest{
-		Peer:     peer,
-		Limit:    opts.Limit,
-		OffsetID: opts.OffsetID,
+		Peer:      peer,
+		Limit:     opts.Limit,
+		OffsetID:  opts.OffsetID,
+		AddOffset: opts.AddOffset,
 	}
 
 	if !opts.OffsetDate.IsZero() {
 		historyRequest.OffsetDate = int(opts.OffsetDate.Unix())
 	}
 
-	if opts.UnreadOnly && readInboxMaxID > 0 {
+	historyRequest.MinID = opts.MinID
+	historyRequest.MaxID = opts.MaxID
+	if opts.UnreadOnly && readInboxMaxID > historyRequest.MinID {
 		historyRequest.MinID = readInboxMaxID
 	}
 
 	p.limiter.Take()
 
-	history, err := p.client.MessagesGetHistory(ctx, historyRequest)
+	var history tg.MessagesMessagesClass
+	if opts.ThreadID > 0 {
+		history, err = p.client.MessagesGetReplies(ctx, &tg.MessagesGetRepliesRequest{
+			Peer:       peer,
+			MsgID:      opts.ThreadID,
+			OffsetID:   historyRequest.OffsetID,
+			OffsetDate: historyRequest.OffsetDate,
+			AddOffset:  historyRequest.AddOffset,
+			Limit:      historyRequest.Limit,
+			MaxID:      historyRequest.MaxID,
+			MinID:      historyRequest.MinID,
+		})
+	} else {
+		history, err = p.client.MessagesGetHistory(ctx, historyRequest)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("getting messages: %w", err)
+	}
+
+	result, err := p.processHistory(history, peer)
+	if err != nil {
+		return nil, err
+	}
+	if opts.ThreadID > 0 {
+		for i := range result.Messages {
+			result.Messages[i].ThreadID = opts.ThreadID
+		}
+	}
+	if opts.ResolveReplies {
+		fetchParents := func(ctx context.Context, ids []int) ([]Message, error) {
+			parents, err := p.fetchByIDsWithPeer(ctx, peer, ids)
+			if err != nil {
+				return nil, err
+			}
+			return parents.Messages, nil
+		}
+		if opts.ThreadID > 0 {
+			// Comments reply to messages of the discussion group, not of peer
+			fetchParents = func(context.Context, []int) ([]Message, error) { return nil, nil }
+		}
+		if err := resolveReplies(ctx, fetchParents, result.Messages); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// FetchByIDs retrieves specific messages from a chat by their IDs.
+// Deleted or inaccessible messages are skipped.
+func (p *Provider) FetchByIDs(ctx context.Context, chatID int64, ids []int) (*FetchResult, error) {
+	var result *FetchResult
+	err := p.peers.WithPeer(ctx, p.client, chatID, func(peer tg.InputPeerClass) error {
+		var err error
+		result, err = p.fetchByIDsWithPeer(ctx, peer, ids)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	result.ChatID = chatID
+	result.HasMore = false
+	result.NextID = 0
+	return result, nil
+}
+
+// fetchByIDsWithPeer retrieves specific messages using an already resolved peer.
+func (p *Provider) fetchByIDsWithPeer(ctx context.Context, peer tg.InputPeerClass, ids []int) (*FetchResult, error) {
+	inputIDs := make([]tg.InputMessageClass, len(ids))
+	for i, id := range ids {
+		inputIDs[i] = &tg.InputMessageID{ID: id}
+	}
+
+	p.limiter.Take()
+
+	var history tg.MessagesMessagesClass
+	var err error
+	if channel, ok := peer.(*tg.InputPeerChannel); ok {
+		history, err = p.client.ChannelsGetMessages(ctx, &tg.ChannelsGetMessagesRequest{
+			Channel: &tg.InputChannel{ChannelID: channel.ChannelID, AccessHash: channel.AccessHash},
+			ID:      inputIDs,
+		})
+	} else {
+		history, err = p.client.MessagesGetMessages(ctx, inputIDs)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("getting messages: %w", err)
 	}
@@ -86,15 +214,63 @@ func (p *Provider) fetchWithPeer(ctx context.Context, peer tg.InputPeerClass, op
 	return p.processHistory(history, peer)
 }
 
-// FetchAll retrieves all messages matching the options, handling pagination automatically.
-// The onBatch callback is called after each batch is fetched (can be nil).
-func (p *Provider) FetchAll(ctx context.Context, chatID int64, opts FetchOptions, onBatch BatchCallback) (*FetchResult, error) {
-	peer, err := tgclient.ResolvePeer(ctx, p.client, chatID)
+// FetchDocuments retrieves one page of documents shared in a chat, newest first, using
+// Telegram's document search filter. Limit, OffsetID, MinDate and MaxDate apply as in Fetch.
+func (p *Provider) FetchDocuments(ctx context.Context, chatID int64, opts FetchOptions) (*FetchResult, error) {
+	if opts.Limit <= 0 {
+		opts.Limit = 50
+	}
+	request := &tg.MessagesSearchRequest{
+		Filter:   &tg.InputMessagesFilterDocument{},
+		Limit:    opts.Limit,
+		OffsetID: opts.OffsetID,
+	}
+	if !opts.MinDate.IsZero() {
+		request.MinDate = int(opts.MinDate.Unix())
+	}
+	if maxBound := opts.maxDateBound(); !maxBound.IsZero() {
+		request.MaxDate = int(maxBound.Unix())
+	}
+
+	var result *FetchResult
+	err := p.peers.WithPeer(ctx, p.client, chatID, func(peer tg.InputPeerClass) error {
+		request.Peer = peer
+		p.limiter.Take()
+		found, err := p.client.MessagesSearch(ctx, request)
+		if err != nil {
+			return fmt.Errorf("searching documents: %w", err)
+		}
+		result, err = p.processHistory(found, peer)
+		return err
+	})
 	if err != nil {
-		return nil, fmt.Errorf("resolving peer: %w", err)
+		return nil, err
 	}
+	result.ChatID = chatID
+	return result, nil
+}
+
+// Peers returns the cache the provider resolves chats through, shared with the
+// handlers resolving chats alongside it.
+func (p *Provider) Peers() *tgclient.PeerCache {
+	return p.peers
+}
 
-	result, err := p.fetchAllWithPeer(ctx, peer, opts, onBatch)
+// Paced returns the provider's client with every request going through the shared
+// rate limiter, for callers making their own API calls alongside the provider.
+func (p *Provider) Paced() *tg.Client {
+	return tgclient.Paced(p.client, p.limiter)
+}
+
+// FetchAll retrieves all messages matching the options, handling pagination automatically.
+// The onBatch callback is called after each batch is fetched (can be nil).
+func (p *Provider) FetchAll(ctx context.Context, chatID int64, opts FetchOptions, onBatch BatchCallback) (*FetchResult, error) {
+	var result *FetchResult
+	err := p.peers.WithPeer(ctx, p.client, chatID, func(peer tg.InputPeerClass) error {
+		var err error
+		result, err = p.fetchAllWithPeer(ctx, peer, opts, onBatch)
+		return err
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -104,53 +280,109 @@ func (p *Provider) FetchAll(ctx context.Context, chatID int64, opts FetchOptions
 
 // fetchAllWithPeer retrieves all messages using an already resolved peer.
 func (p *Provider) fetchAllWithPeer(ctx context.Context, peer tg.InputPeerClass, opts FetchOptions, onBatch BatchCallback) (*FetchResult, error) {
+	return collectAll(ctx, func(ctx context.Context, batchOpts FetchOptions) (*FetchResult, error) {
+		return p.fetchWithPeer(ctx, peer, batchOpts)
+	}, opts, onBatch)
+}
+
+// FetchStream pages through all messages matching the options like FetchAll, but hands
+// each batch to emit instead of collecting them, so memory use doesn't grow with the
+// chat. It returns the number of messages emitted, also when it fails midway.
+func (p *Provider) FetchStream(ctx context.Context, chatID int64, opts FetchOptions, emit func([]Message) error, onBatch BatchCallback) (int, error) {
+	var count int
+	err := p.peers.WithPeer(ctx, p.client, chatID, func(peer tg.InputPeerClass) error {
+		var err error
+		count, err = streamAll(ctx, func(ctx context.Context, batchOpts FetchOptions) (*FetchResult, error) {
+			return p.fetchWithPeer(ctx, peer, batchOpts)
+		}, opts, emit, onBatch)
+		return err
+	})
+	return count, err
+}
+
+// batchFetcher fetches a single page of history.
+type batchFetcher func(ctx context.Context, opts FetchOptions) (*FetchResult, error)
+
+// collectAll pages through history with fetch, applying the date and count filters of opts.
+func collectAll(ctx context.Context, fetch batchFetcher, opts FetchOptions, onBatch BatchCallback) (*FetchResult, error) {
 	result := &FetchResult{
 		Messages: make([]Message, 0),
 		Users:    make(map[int64]string),
 		Chats:    make(map[int64]string),
 	}
+	count, err := streamAll(ctx, func(ctx context.Context, batchOpts FetchOptions) (*FetchResult, error) {
+		batch, err := fetch(ctx, batchOpts)
+		if err != nil {
+			return nil, err
+		}
+		for k, v := range batch.Users {
+			result.Users[k] = v
+		}
+		for k, v := range batch.Chats {
+			result.Chats[k] = v
+		}
+		return batch, nil
+	}, opts, func(msgs []Message) error {
+		result.Messages = append(result.Messages, msgs...)
+		return nil
+	}, onBatch)
+	result.Count = count
+	result.Total = count
+	if err != nil {
+		if ctx.Err() != nil {
+			return result, err
+		}
+		return nil, err
+	}
+	return result, nil
+}
 
+// streamAll pages through history with fetch, applying the date and count filters of
+// opts, and hands the kept messages of each batch to emit. It returns the number of
+// messages emitted.
+func streamAll(ctx context.Context, fetch batchFetcher, opts FetchOptions, emit func([]Message) error, onBatch BatchCallback) (int, error) {
 	batchOpts := FetchOptions{
 		Limit: opts.Limit,
+		MinID: opts.MinID,
+		MaxID: opts.MaxID,
 	}
 	if batchOpts.Limit <= 0 {
 		batchOpts.Limit = 100
 	}
 
-	// Set the initial offset date if MaxDate is specified.
-	if !opts.MaxDate.IsZero() {
-		batchOpts.OffsetDate = opts.MaxDate.Add(offsetDateBuffer)
-	}
+	// Start paging at the upper bound if MaxDate is specified.
+	maxBound := opts.maxDateBound()
+	batchOpts.OffsetDate = maxBound
 
 	batchNum := 0
+	collected := 0
+	// With GroupAlbums, an album at the end of a page is held back until the next
+	// page has its remaining messages
+	var carry []Message
 
 	for {
 		select {
 		case <-ctx.Done():
-			result.Count = len(result.Messages)
-			result.Total = len(result.Messages)
-			return result, fmt.Errorf("context canceled: %w", ctx.Err())
+			return collected, fmt.Errorf("context canceled: %w", ctx.Err())
 		default:
 		}
 
 		batchNum++
 
-		batch, err := p.fetchWithPeer(ctx, peer, batchOpts)
+		batch, err := fetch(ctx, batchOpts)
 		if err != nil {
-			return nil, fmt.Errorf("fetching batch %d: %w", batchNum, err)
-		}
-
-		// Merge user/chat maps
-		for k, v := range batch.Users {
-			result.Users[k] = v
-		}
-		for k, v := range batch.Chats {
-			result.Chats[k] = v
+			return collected, fmt.Errorf("fetching batch %d: %w", batchNum, err)
 		}
 
 		if len(batch.Messages) == 0 {
+			if len(carry) > 0 {
+				if err := emit(GroupAlbums(carry)); err != nil {
+					return collected, err
+				}
+				collected++
+			}
 			if onBatch != nil {
-				onBatch(batchNum, len(result.Messages), time.Time{})
+				onBatch(batchNum, collected, time.Time{})
 			}
 			break
 		}
@@ -163,35 +395,72 @@ func (p *Provider) fetchAllWithPeer(ctx context.Context, peer tg.InputPeerClass,
 			}
 		}
 
-		// Filter and collect messages
+		// Filter the batch
+		kept := make([]Message, 0, len(batch.Messages))
 		reachedMinDate := false
+		reachedMinID := false
+		reachedMaxCount := false
 		for _, msg := range batch.Messages {
 			// Check min date filter
 			if !opts.MinDate.IsZero() && msg.Date.Before(opts.MinDate) {
 				reachedMinDate = true
 				break
 			}
+			// Check min ID filter; Telegram applies it too, so this only guards the boundary
+			if opts.MinID > 0 && msg.ID <= opts.MinID {
+				reachedMinID = true
+				break
+			}
+			// Check max date and max ID filters; the offset date only bounds the first page
+			if !maxBound.IsZero() && !msg.Date.Before(maxBound) {
+				continue
+			}
+			if opts.MaxID > 0 && msg.ID >= opts.MaxID {
+				continue
+			}
 
-			result.Messages = append(result.Messages, msg)
+			kept = append(kept, msg)
 
 			// Check max count
-			if opts.MaxCount > 0 && len(result.Messages) >= opts.MaxCount {
-				result.Count = len(result.Messages)
-				result.Total = len(result.Messages)
-				if onBatch != nil {
-					onBatch(batchNum, len(result.Messages), earliestTime)
-				}
-				return result, nil
+			if opts.MaxCount > 0 && collected+len(kept) >= opts.MaxCount {
+				reachedMaxCount = true
+				break
+			}
+		}
+
+		// With MinID, Telegram leaves out older messages but still reports the whole
+		// chat's count, so HasMore stays set: stop once the page ends at the boundary.
+		// IDs only grow, so nothing lies between MinID and MinID+1.
+		if opts.MinID > 0 {
+			oldest := batch.Messages[len(batch.Messages)-1].ID
+			if oldest <= opts.MinID+1 || len(batch.Messages) < batchOpts.Limit {
+				reachedMinID = true
+			}
+		}
+		done := reachedMaxCount || reachedMinDate || reachedMinID || !batch.HasMore
+
+		if opts.GroupAlbums {
+			kept = append(carry, kept...)
+			carry = nil
+			if !done {
+				kept, carry = splitTrailingAlbum(kept)
+			}
+			kept = GroupAlbums(kept)
+		}
+
+		if len(kept) > 0 {
+			if err := emit(kept); err != nil {
+				return collected, err
 			}
+			collected += len(kept)
 		}
 
 		// Call batch callback with progress info
 		if onBatch != nil {
-			onBatch(batchNum, len(result.Messages), earliestTime)
+			onBatch(batchNum, collected, earliestTime)
 		}
 
-		// Stop conditions
-		if reachedMinDate || !batch.HasMore {
+		if done {
 			break
 		}
 
@@ -200,9 +469,7 @@ func (p *Provider) fetchAllWithPeer(ctx context.Context, peer tg.InputPeerClass,
 		batchOpts.OffsetDate = time.Time{} // Reset after the first batch
 	}
 
-	result.Count = len(result.Messages)
-	result.Total = len(result.Messages)
-	return result, nil
+	return collected, nil
 }
 
 func (p *Provider) processHistory(history tg.MessagesMessagesClass, peer tg.InputPeerClass) (*FetchResult, error) {
@@ -242,17 +509,26 @@ func (p *Provider) processHistory(history tg.MessagesMessagesClass, peer tg.Inpu
 			result.Users[user.ID] = tgclient.UserName(user)
 		}
 	}
+	usernames := make(map[int64]string)
 	for _, c := range chats {
 		switch chat := c.(type) {
 		case *tg.Chat:
 			result.Chats[chat.ID] = chat.Title
 		case *tg.Channel:
 			result.Chats[chat.ID] = chat.Title
+			usernames[chat.ID] = chat.Username
 		}
 	}
 
-	// Extract messages
+	// Extract messages; only those of channels and supergroups have links. Comments
+	// fetched through a channel post live in its discussion group, so each message
+	// is linked in its own chat.
 	result.Messages = p.extractMessages(messages, result.Users, result.Chats, peer)
+	for i, msg := range result.Messages {
+		if channel, ok := msg.Raw.PeerID.(*tg.PeerChannel); ok {
+			result.Messages[i].Link = MessageLink(usernames[channel.ChannelID], channel.ChannelID, msg.ID)
+		}
+	}
 	result.Count = len(result.Messages)
 	result.Total = totalCount
 	result.HasMore = len(result.Messages) > 0 && len(result.Messages) < totalCount
@@ -279,6 +555,9 @@ func (p *Provider) extractMessages(messages []tg.MessageClass, users map[int64]s
 			Text: msg.Message,
 			Raw:  msg,
 		}
+		if editDate, ok := msg.GetEditDate(); ok {
+			m.EditDate = time.Unix(int64(editDate), 0)
+		}
 
 		// Extract sender
 		if msg.FromID != nil {
@@ -289,6 +568,24 @@ func (p *Provider) extractMessages(messages []tg.MessageClass, users map[int64]s
 			m.SenderID, m.SenderName = extractSender(peer, users, chats)
 		}
 
+		// Extract attribution: admin signature of channel posts and inline bot
+		m.PostAuthor = msg.PostAuthor
+		if botID, ok := msg.GetViaBotID(); ok {
+			m.ViaBotID = botID
+			m.ViaBot = users[botID] // tgclient.UserName prefers "@username", which every bot has
+			if m.ViaBot == "" {
+				m.ViaBot = fmt.Sprintf("bot#%d", botID)
+			}
+		}
+
+		// Extract forward origin and channel post statistics
+		if fwd, ok := msg.GetFwdFrom(); ok {
+			m.ForwardedFrom = extractForward(fwd, users, chats)
+		}
+		m.Views = msg.Views
+		m.Forwards = msg.Forwards
+		m.Reactions = extractReactions(msg.Reactions)
+
 		// Extract reply info
 		if msg.ReplyTo != nil {
 			if reply, ok := msg.ReplyTo.(*tg.MessageReplyHeader); ok {
@@ -301,6 +598,11 @@ func (p *Provider) extractMessages(messages []tg.MessageClass, users map[int64]s
 			m.Media = extractMediaType(msg.Media)
 		}
 
+		// Extract bot keyboards
+		if msg.ReplyMarkup != nil {
+			m.Buttons = extractButtons(msg.ReplyMarkup)
+		}
+
 		// Extract entities (URLs)
 		// Note: Telegram uses UTF-16 code units for offset/length
 		for _, entity := range msg.Entities {
@@ -320,6 +622,19 @@ func (p *Provider) extractMessages(messages []tg.MessageClass, users map[int64]s
 	return result
 }
 
+// ReadInboxMaxID returns the ID of the newest incoming message the user has read in
+// a chat; newer incoming messages are unread.
+func (p *Provider) ReadInboxMaxID(ctx context.Context, chatID int64) (int, error) {
+	var maxID int
+	err := p.peers.WithPeer(ctx, p.client, chatID, func(peer tg.InputPeerClass) error {
+		p.limiter.Take()
+		var err error
+		maxID, err = p.getReadInboxMaxID(ctx, peer)
+		return err
+	})
+	return maxID, err
+}
+
 func (p *Provider) getReadInboxMaxID(ctx context.Context, peer tg.InputPeerClass) (int, error) {
 	result, err := p.client.MessagesGetPeerDialogs(ctx, []tg.InputDialogPeerClass{
 		&tg.InputDialogPeer{Peer: peer},
@@ -340,13 +655,14 @@ func (p *Provider) getReadInboxMaxID(ctx context.Context, peer tg.InputPeerClass
 	return dialog.ReadInboxMaxID, nil
 }
 
+// unknownSender is the name of a sender that couldn't be resolved.
+const unknownSender = "Unknown"
+
 // extractSender extracts sender ID and name from a PeerClass or InputPeerClass.
 func extractSender(peer any, users map[int64]string, chats map[int64]string) (int64, string) {
 	var id int64
 	var name string
 
-	const unknownSender = "Unknown"
-
 	switch p := peer.(type) {
 	case interface{ GetUserID() int64 }:
 		id = p.GetUserID()
@@ -367,57 +683,92 @@ func extractSender(peer any, users map[int64]string, chats map[int64]string) (in
 	return id, name
 }
 
+// extractForward returns the origin of a forwarded message: its original sender or
+// channel, or the name Telegram shows for a sender who hides their account.
+func extractForward(fwd tg.MessageFwdHeader, users map[int64]string, chats map[int64]string) *ForwardInfo {
+	info := &ForwardInfo{Date: time.Unix(int64(fwd.Date), 0)}
+	if fwd.FromID == nil && fwd.FromName != "" {
+		info.From = fwd.FromName
+		return info
+	}
+	info.FromID, info.From = extractSender(fwd.FromID, users, chats)
+	return info
+}
+
+// extractReactions returns the count of each reaction to a message, nil if it has none.
+func extractReactions(reactions tg.MessageReactions) map[string]int {
+	if len(reactions.Results) == 0 {
+		return nil
+	}
+	counts := make(map[string]int, len(reactions.Results))
+	for _, r := range reactions.Results {
+		counts[ReactionString(r.Reaction)] += r.Count
+	}
+	return counts
+}
+
+// ReactionString renders a reaction as its emoji, or a short description for
+// custom emoji and paid reactions.
+func ReactionString(reaction tg.ReactionClass) string {
+	switch r := reaction.(type) {
+	case *tg.ReactionEmoji:
+		return r.Emoticon
+	case *tg.ReactionCustomEmoji:
+		return fmt.Sprintf("custom emoji %d", r.DocumentID)
+	case *tg.ReactionPaid:
+		return "⭐ paid"
+	default:
+		return "unknown"
+	}
+}
+
 func extractMediaType(media tg.MessageMediaClass) *MediaInfo {
 	switch m := media.(type) {
 	case *tg.MessageMediaPhoto:
 		info := &MediaInfo{Type: "photo"}
 		if photo, ok := m.GetPhoto(); ok {
 			if p, ok := photo.(*tg.Photo); ok {
-				// Get the largest photo size for dimensions and thumb type
+				// Get the largest photo size for dimensions, file size and thumb type
 				var thumbType string
+				var sizeTypes []string
 				for _, size := range p.Sizes {
-					var w, h int
+					var w, h, bytes int
 					var sizeType string
 					switch s := size.(type) {
 					case *tg.PhotoSize:
-						w, h, sizeType = s.W, s.H, s.Type
+						w, h, bytes, sizeType = s.W, s.H, s.Size, s.Type
 					case *tg.PhotoSizeProgressive:
 						w, h, sizeType = s.W, s.H, s.Type
+						if len(s.Sizes) > 0 {
+							bytes = s.Sizes[len(s.Sizes)-1]
+						}
 					case *tg.PhotoCachedSize:
-						w, h, sizeType = s.W, s.H, s.Type
+						w, h, bytes, sizeType = s.W, s.H, len(s.Bytes), s.Type
 					default:
 						continue
 					}
+					sizeTypes = append(sizeTypes, sizeType)
 					if w > info.Width {
 						info.Width = w
 						info.Height = h
+						info.Size = int64(bytes)
 						thumbType = sizeType
 					}
 				}
-				// Generate resource URI for photo download
 				if thumbType != "" {
-					fileRef := base64.URLEncoding.EncodeToString(p.FileReference)
-					info.ResourceURI = fmt.Sprintf(
-						"telegram://media/%d/%d/%d/%s?ref=%s",
-						p.ID, p.AccessHash, p.DCID, thumbType, fileRef,
-					)
+					info.MimeType = "image/jpeg" // Telegram stores photos as JPEG
+					info.ResourceURI = PhotoURI(p, thumbType, sizeTypes)
 				}
 			}
 		}
 		return info
 	case *tg.MessageMediaDocument:
-		info := &MediaInfo{Type: "document"}
 		if doc, ok := m.GetDocument(); ok {
 			if d, ok := doc.(*tg.Document); ok {
-				for _, attr := range d.Attributes {
-					if fileName, ok := attr.(*tg.DocumentAttributeFilename); ok {
-						info.FileName = fileName.FileName
-						break
-					}
-				}
+				return extractDocument(d)
 			}
 		}
-		return info
+		return &MediaInfo{Type: MediaDocument}
 	case *tg.MessageMediaGeo:
 		return &MediaInfo{Type: "geo"}
 	case *tg.MessageMediaContact:
@@ -439,39 +790,84 @@ func extractMediaType(media tg.MessageMediaClass) *MediaInfo {
 	}
 }
 
+// extractDocument describes a document: its kind (voice, audio, video or a plain
+// document), filename, MIME type, size, duration and a resource URI for downloading it. The URI
+// carries the kind, size and MIME type, so GetMedia can decide how to return the file
+// before downloading it. Attributes missing from the document are left empty.
+func extractDocument(d *tg.Document) *MediaInfo {
+	info := &MediaInfo{
+		Type:     MediaDocument,
+		MimeType: d.MimeType,
+		Size:     d.Size,
+	}
+	for _, attr := range d.Attributes {
+		switch a := attr.(type) {
+		case *tg.DocumentAttributeFilename:
+			info.FileName = a.FileName
+		case *tg.DocumentAttributeAudio:
+			info.Type = MediaAudio
+			if a.Voice {
+				info.Type = MediaVoice
+			}
+			info.Duration = a.Duration
+		case *tg.DocumentAttributeVideo:
+			info.Type = MediaVideo
+			info.Width, info.Height = a.W, a.H
+			info.Duration = int(math.Round(a.Duration))
+		}
+	}
+	if d.ID != 0 {
+		info.ResourceURI = DocumentURI(d, info.Type)
+	}
+	return info
+}
+
+// PhotoURI returns the GetMedia URI of a photo at size thumb, listing the sizes to
+// choose from. It embeds the photo's file reference, which expires after a while;
+// the message must then be fetched again for a fresh URI.
+func PhotoURI(p *tg.Photo, thumb string, sizes []string) string {
+	return fmt.Sprintf("telegram://media/%d/%d/%d/%s?ref=%s&sizes=%s",
+		p.ID, p.AccessHash, p.DCID, thumb, base64.URLEncoding.EncodeToString(p.FileReference), strings.Join(sizes, ","))
+}
+
+// DocumentURI returns the GetMedia URI of a document of the given kind. Like
+// PhotoURI, it embeds an expiring file reference.
+func DocumentURI(d *tg.Document, kind string) string {
+	return fmt.Sprintf("telegram://document/%d/%d/%d?ref=%s&kind=%s&size=%d&mime=%s",
+		d.ID, d.AccessHash, d.DCID, base64.URLEncoding.EncodeToString(d.FileReference), kind, d.Size, url.QueryEscape(d.MimeType))
+}
+
 // extractSubstring extracts a substring using UTF-16 code unit offsets.
 // Telegram uses UTF-16 for entity positions: emoji = 2 units, other chars = 1 unit.
+// An end past the string is clamped, since Telegram sometimes sends entity lengths
+// that overshoot after the message was edited; an invalid offset yields "".
 func extractSubstring(s string, offset, length int) string {
-	if offset < 0 || length <= 0 {
+	total := UTF16Len(s)
+	if offset < 0 || length <= 0 || offset >= total {
 		return ""
 	}
 
-	runes := []rune(s)
-	end := offset + length
+	end := min(offset+length, total)
 
-	// Convert UTF-16 offset to rune index
+	// Convert UTF-16 offsets to byte indexes
 	pos := 0
 	start := -1
-	stop := -1
+	stop := len(s)
 
-	for i, r := range runes {
-		if pos >= offset && start < 0 {
-			start = i
-		}
-		if r > 0xFFFF {
-			pos += 2
-		} else {
-			pos++
-		}
+	for i, r := range s {
 		if pos >= end {
-			stop = i + 1
+			stop = i
 			break
 		}
+		if pos >= offset && start < 0 {
+			start = i
+		}
+		pos += utf16RuneLen(r)
 	}
 
-	if start < 0 || stop < 0 {
+	if start < 0 {
 		return ""
 	}
 
-	return string(runes[start:stop])
+	return s[start:stop]
 }
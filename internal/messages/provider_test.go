@@ -1,6 +1,14 @@
 package messages
 
-import "testing"
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/gotd/td/tg"
+)
 
 func TestExtractSubstring(t *testing.T) {
 	tests := []struct {
@@ -127,6 +135,27 @@ func TestExtractSubstring(t *testing.T) {
 			input:  "Hello",
 			offset: 0,
 			length: 100,
+			want:   "Hello",
+		},
+		{
+			name:   "Length overshoots by one unit",
+			input:  "See https://example.com",
+			offset: 4,
+			length: 20,
+			want:   "https://example.com",
+		},
+		{
+			name:   "Overshoot after emoji",
+			input:  "👋 https://example.com",
+			offset: 3,
+			length: 20,
+			want:   "https://example.com",
+		},
+		{
+			name:   "Offset at end of string",
+			input:  "Hello",
+			offset: 5,
+			length: 1,
 			want:   "",
 		},
 		{
@@ -169,3 +198,422 @@ func TestExtractSubstring(t *testing.T) {
 		})
 	}
 }
+
+func TestCollectAllDateFilters(t *testing.T) {
+	day := time.Date(2024, 3, 10, 0, 0, 0, 0, time.UTC)
+	at := func(h, m int) time.Time { return day.Add(time.Duration(h)*time.Hour + time.Duration(m)*time.Minute) }
+	msg := func(id int, date time.Time) Message { return Message{ID: id, Date: date} }
+
+	// Newest first, as Telegram returns history. A burst straddles the 12:00 boundary
+	// across the first and second pages; later pages cross into the previous day.
+	pages := []*FetchResult{
+		{Messages: []Message{msg(9, at(12, 2)), msg(8, at(12, 1))}, HasMore: true, NextID: 8},
+		{Messages: []Message{msg(7, at(12, 1)), msg(6, at(12, 0)), msg(5, at(11, 59))}, HasMore: true, NextID: 5},
+		{Messages: []Message{msg(4, at(9, 0)), msg(3, at(-1, 0))}, HasMore: true, NextID: 3},
+		{Messages: []Message{msg(2, at(-2, 0))}, HasMore: false},
+	}
+
+	tests := []struct {
+		name       string
+		opts       FetchOptions
+		wantIDs    []int
+		wantOffset time.Time
+	}{
+		{
+			name:       "exact max date",
+			opts:       FetchOptions{MaxDate: at(12, 0), MaxDateExact: true},
+			wantIDs:    []int{6, 5, 4, 3, 2},
+			wantOffset: at(12, 0).Add(time.Second),
+		},
+		{
+			name:       "exact max date with min date",
+			opts:       FetchOptions{MinDate: day, MaxDate: at(12, 0), MaxDateExact: true},
+			wantIDs:    []int{6, 5, 4},
+			wantOffset: at(12, 0).Add(time.Second),
+		},
+		{
+			name:       "date-only max date covers the whole day",
+			opts:       FetchOptions{MaxDate: day},
+			wantIDs:    []int{9, 8, 7, 6, 5, 4, 3, 2},
+			wantOffset: day.Add(offsetDateBuffer),
+		},
+		{
+			name:       "date-only max date excludes the next day",
+			opts:       FetchOptions{MaxDate: day.Add(-24 * time.Hour)},
+			wantIDs:    []int{3, 2},
+			wantOffset: day,
+		},
+		{
+			name:    "no date filters",
+			opts:    FetchOptions{},
+			wantIDs: []int{9, 8, 7, 6, 5, 4, 3, 2},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls []FetchOptions
+			fetch := func(_ context.Context, opts FetchOptions) (*FetchResult, error) {
+				calls = append(calls, opts)
+				page := pages[len(calls)-1]
+				return &FetchResult{Messages: page.Messages, HasMore: page.HasMore, NextID: page.NextID}, nil
+			}
+
+			result, err := collectAll(context.Background(), fetch, tt.opts, nil)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			var ids []int
+			for _, m := range result.Messages {
+				ids = append(ids, m.ID)
+			}
+			if !reflect.DeepEqual(ids, tt.wantIDs) {
+				t.Errorf("got IDs %v, want %v", ids, tt.wantIDs)
+			}
+			if !calls[0].OffsetDate.Equal(tt.wantOffset) {
+				t.Errorf("first page offset date = %v, want %v", calls[0].OffsetDate, tt.wantOffset)
+			}
+			for i, c := range calls[1:] {
+				if !c.OffsetDate.IsZero() {
+					t.Errorf("page %d offset date = %v, want zero", i+2, c.OffsetDate)
+				}
+			}
+		})
+	}
+}
+
+func TestStreamAllBatches(t *testing.T) {
+	pages := []*FetchResult{
+		{Messages: []Message{{ID: 9}, {ID: 8}, {ID: 7}}, HasMore: true, NextID: 7},
+		{Messages: []Message{{ID: 6}, {ID: 5}, {ID: 4}}, HasMore: true, NextID: 4},
+		{Messages: []Message{{ID: 3}}, HasMore: false},
+	}
+	fetcher := func(calls *int) batchFetcher {
+		return func(_ context.Context, _ FetchOptions) (*FetchResult, error) {
+			*calls++
+			return pages[*calls-1], nil
+		}
+	}
+
+	var calls int
+	var batches [][]int
+	count, err := streamAll(context.Background(), fetcher(&calls), FetchOptions{MaxCount: 5}, func(msgs []Message) error {
+		var ids []int
+		for _, m := range msgs {
+			ids = append(ids, m.ID)
+		}
+		batches = append(batches, ids)
+		return nil
+	}, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := [][]int{{9, 8, 7}, {6, 5}}; !reflect.DeepEqual(batches, want) {
+		t.Errorf("batches = %v, want %v", batches, want)
+	}
+	if count != 5 || calls != 2 {
+		t.Errorf("count = %d after %d pages, want 5 after 2", count, calls)
+	}
+
+	// An emit error stops paging and reports what was emitted before it
+	calls = 0
+	errWrite := errors.New("disk full")
+	count, err = streamAll(context.Background(), fetcher(&calls), FetchOptions{}, func(msgs []Message) error {
+		if msgs[0].ID == 6 {
+			return errWrite
+		}
+		return nil
+	}, nil)
+	if !errors.Is(err, errWrite) || count != 3 || calls != 2 {
+		t.Errorf("streamAll = %d, %v after %d pages, want 3, %v after 2", count, err, calls, errWrite)
+	}
+}
+
+func TestCollectAllIDRange(t *testing.T) {
+	ids := func(ids ...int) []Message {
+		msgs := make([]Message, len(ids))
+		for i, id := range ids {
+			msgs[i] = Message{ID: id}
+		}
+		return msgs
+	}
+	// Full pages of three, newest first; the fake ignores MinID like a lagging server
+	pages := []*FetchResult{
+		{Messages: ids(12, 11, 10), HasMore: true, NextID: 10},
+		{Messages: ids(9, 7, 6), HasMore: true, NextID: 6},
+		{Messages: ids(5, 4, 3), HasMore: true, NextID: 3},
+		{Messages: ids(2, 1), HasMore: false},
+	}
+
+	tests := []struct {
+		name      string
+		opts      FetchOptions
+		wantIDs   []int
+		wantPages int
+	}{
+		{name: "min id within a page", opts: FetchOptions{Limit: 3, MinID: 6}, wantIDs: []int{12, 11, 10, 9, 7}, wantPages: 2},
+		{name: "page ends right above min id", opts: FetchOptions{Limit: 3, MinID: 9}, wantIDs: []int{12, 11, 10}, wantPages: 1},
+		{name: "gap at the boundary", opts: FetchOptions{Limit: 3, MinID: 8}, wantIDs: []int{12, 11, 10, 9}, wantPages: 2},
+		{name: "max count at a page end before min id", opts: FetchOptions{Limit: 3, MinID: 4, MaxCount: 3}, wantIDs: []int{12, 11, 10}, wantPages: 1},
+		{name: "max count and min id in the same page", opts: FetchOptions{Limit: 3, MinID: 6, MaxCount: 5}, wantIDs: []int{12, 11, 10, 9, 7}, wantPages: 2},
+		{name: "min id before max count", opts: FetchOptions{Limit: 3, MinID: 6, MaxCount: 10}, wantIDs: []int{12, 11, 10, 9, 7}, wantPages: 2},
+		{name: "max id", opts: FetchOptions{Limit: 3, MinID: 4, MaxID: 11}, wantIDs: []int{10, 9, 7, 6, 5}, wantPages: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls []FetchOptions
+			fetch := func(_ context.Context, opts FetchOptions) (*FetchResult, error) {
+				calls = append(calls, opts)
+				return pages[len(calls)-1], nil
+			}
+
+			result, err := collectAll(context.Background(), fetch, tt.opts, nil)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			var got []int
+			for _, m := range result.Messages {
+				got = append(got, m.ID)
+			}
+			if !reflect.DeepEqual(got, tt.wantIDs) {
+				t.Errorf("got IDs %v, want %v", got, tt.wantIDs)
+			}
+			if len(calls) != tt.wantPages {
+				t.Errorf("fetched %d pages, want %d", len(calls), tt.wantPages)
+			}
+			for i, c := range calls {
+				if c.MinID != tt.opts.MinID || c.MaxID != tt.opts.MaxID {
+					t.Errorf("page %d requested IDs (%d, %d), want (%d, %d)", i+1, c.MinID, c.MaxID, tt.opts.MinID, tt.opts.MaxID)
+				}
+			}
+		})
+	}
+}
+
+func TestExtractMessagesAttribution(t *testing.T) {
+	p := &Provider{}
+	signed := &tg.Message{ID: 1, Message: "Release notes", PeerID: &tg.PeerChannel{ChannelID: 5}}
+	signed.SetPostAuthor("Maria")
+	viaBot := &tg.Message{ID: 2, Message: "funny cat", FromID: &tg.PeerUser{UserID: 7}, PeerID: &tg.PeerChannel{ChannelID: 5}}
+	viaBot.SetViaBotID(100)
+	unknownBot := &tg.Message{ID: 3, Message: "min bot", FromID: &tg.PeerUser{UserID: 7}, PeerID: &tg.PeerChannel{ChannelID: 5}}
+	unknownBot.SetViaBotID(101)
+
+	msgs := p.extractMessages([]tg.MessageClass{signed, viaBot, unknownBot},
+		map[int64]string{7: "Anna", 100: "@gif"},
+		map[int64]string{5: "News"},
+		&tg.InputPeerChannel{ChannelID: 5})
+
+	if len(msgs) != 3 {
+		t.Fatalf("got %d messages, want 3", len(msgs))
+	}
+	if msgs[0].PostAuthor != "Maria" || msgs[0].SenderName != "News" || msgs[0].ViaBot != "" {
+		t.Errorf("signed post = %+v", msgs[0])
+	}
+	if msgs[1].ViaBotID != 100 || msgs[1].ViaBot != "@gif" || msgs[1].PostAuthor != "" {
+		t.Errorf("inline bot message = %+v", msgs[1])
+	}
+	if msgs[2].ViaBot != "bot#101" {
+		t.Errorf("unknown bot = %q, want bot#101", msgs[2].ViaBot)
+	}
+}
+
+func TestExtractMessagesForwardAndStats(t *testing.T) {
+	p := &Provider{}
+	fwdDate := time.Unix(1_704_877_200, 0)
+
+	fromChannel := &tg.Message{ID: 1, Message: "repost", PeerID: &tg.PeerChannel{ChannelID: 5}}
+	fromChannel.SetFwdFrom(tg.MessageFwdHeader{FromID: &tg.PeerChannel{ChannelID: 6}, Date: int(fwdDate.Unix())})
+	hidden := &tg.Message{ID: 2, Message: "secret", FromID: &tg.PeerUser{UserID: 7}, PeerID: &tg.PeerChannel{ChannelID: 5}}
+	hidden.SetFwdFrom(tg.MessageFwdHeader{FromName: "Ghost", Date: int(fwdDate.Unix())})
+	post := &tg.Message{ID: 3, Message: "popular", PeerID: &tg.PeerChannel{ChannelID: 5}}
+	post.SetViews(1500)
+	post.SetForwards(42)
+	post.SetReactions(tg.MessageReactions{Results: []tg.ReactionCount{
+		{Reaction: &tg.ReactionEmoji{Emoticon: "👍"}, Count: 12},
+		{Reaction: &tg.ReactionCustomEmoji{DocumentID: 99}, Count: 2},
+	}})
+	plain := &tg.Message{ID: 4, Message: "plain", FromID: &tg.PeerUser{UserID: 7}, PeerID: &tg.PeerChannel{ChannelID: 5}}
+
+	msgs := p.extractMessages([]tg.MessageClass{fromChannel, hidden, post, plain},
+		map[int64]string{7: "Anna"},
+		map[int64]string{5: "News", 6: "Wire"},
+		&tg.InputPeerChannel{ChannelID: 5})
+
+	if len(msgs) != 4 {
+		t.Fatalf("got %d messages, want 4", len(msgs))
+	}
+	if want := (&ForwardInfo{FromID: 6, From: "Wire", Date: fwdDate}); !reflect.DeepEqual(msgs[0].ForwardedFrom, want) {
+		t.Errorf("forward from channel = %+v, want %+v", msgs[0].ForwardedFrom, want)
+	}
+	if want := (&ForwardInfo{From: "Ghost", Date: fwdDate}); !reflect.DeepEqual(msgs[1].ForwardedFrom, want) {
+		t.Errorf("forward from hidden account = %+v, want %+v", msgs[1].ForwardedFrom, want)
+	}
+	if msgs[2].Views != 1500 || msgs[2].Forwards != 42 {
+		t.Errorf("post views %d, forwards %d, want 1500 and 42", msgs[2].Views, msgs[2].Forwards)
+	}
+	if want := map[string]int{"👍": 12, "custom emoji 99": 2}; !reflect.DeepEqual(msgs[2].Reactions, want) {
+		t.Errorf("reactions = %v, want %v", msgs[2].Reactions, want)
+	}
+	if m := msgs[3]; m.ForwardedFrom != nil || m.Views != 0 || m.Forwards != 0 || m.Reactions != nil {
+		t.Errorf("plain message = %+v, want no forward or statistics", m)
+	}
+}
+
+func TestProcessHistoryLinks(t *testing.T) {
+	p := &Provider{}
+	history := func(chats ...tg.ChatClass) *tg.MessagesChannelMessages {
+		return &tg.MessagesChannelMessages{
+			Messages: []tg.MessageClass{&tg.Message{ID: 42, Message: "hi", PeerID: &tg.PeerChannel{ChannelID: 5}}},
+			Chats:    chats,
+			Count:    1,
+		}
+	}
+
+	tests := []struct {
+		name    string
+		history tg.MessagesMessagesClass
+		peer    tg.InputPeerClass
+		want    string
+	}{
+		{"public channel", history(&tg.Channel{ID: 5, Title: "News", Username: "news"}), &tg.InputPeerChannel{ChannelID: 5}, "https://t.me/news/42"},
+		{"private supergroup", history(&tg.Channel{ID: 5, Title: "Team"}), &tg.InputPeerChannel{ChannelID: 5}, "https://t.me/c/5/42"},
+		{"comment in a discussion group", &tg.MessagesChannelMessages{
+			Messages: []tg.MessageClass{&tg.Message{ID: 42, Message: "hi", PeerID: &tg.PeerChannel{ChannelID: 9}}},
+			Chats:    []tg.ChatClass{&tg.Channel{ID: 5, Title: "News", Username: "news"}, &tg.Channel{ID: 9, Title: "News chat", Username: "newschat"}},
+		}, &tg.InputPeerChannel{ChannelID: 5}, "https://t.me/newschat/42"},
+		{"private chat", &tg.MessagesMessages{Messages: []tg.MessageClass{&tg.Message{ID: 42, Message: "hi"}}}, &tg.InputPeerUser{UserID: 7}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := p.processHistory(tt.history, tt.peer)
+			if err != nil {
+				t.Fatalf("processHistory() error = %v", err)
+			}
+			if got := result.Messages[0].Link; got != tt.want {
+				t.Errorf("link = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExtractMediaTypeDocument(t *testing.T) {
+	withDocument := func(doc tg.DocumentClass) *tg.MessageMediaDocument {
+		media := &tg.MessageMediaDocument{}
+		media.SetDocument(doc)
+		return media
+	}
+
+	tests := []struct {
+		name  string
+		media tg.MessageMediaClass
+		want  *MediaInfo
+	}{
+		{
+			name: "all attributes",
+			media: withDocument(&tg.Document{
+				ID: 10, AccessHash: -20, DCID: 2, FileReference: []byte{1, 2},
+				MimeType: "application/pdf", Size: 123456,
+				Attributes: []tg.DocumentAttributeClass{
+					&tg.DocumentAttributeFilename{FileName: "plan.pdf"},
+				},
+			}),
+			want: &MediaInfo{Type: "document", FileName: "plan.pdf", MimeType: "application/pdf", Size: 123456,
+				ResourceURI: "telegram://document/10/-20/2?ref=AQI=&kind=document&size=123456&mime=application%2Fpdf"},
+		},
+		{
+			name: "video without filename",
+			media: withDocument(&tg.Document{
+				ID: 11, DCID: 4, MimeType: "video/mp4", Size: 99,
+				Attributes: []tg.DocumentAttributeClass{&tg.DocumentAttributeVideo{W: 640, H: 480, Duration: 12.6}},
+			}),
+			want: &MediaInfo{Type: "video", MimeType: "video/mp4", Size: 99, Duration: 13, Width: 640, Height: 480,
+				ResourceURI: "telegram://document/11/0/4?ref=&kind=video&size=99&mime=video%2Fmp4"},
+		},
+		{
+			name: "voice note",
+			media: withDocument(&tg.Document{
+				ID: 14, DCID: 2, MimeType: "audio/ogg", Size: 5000,
+				Attributes: []tg.DocumentAttributeClass{&tg.DocumentAttributeAudio{Voice: true, Duration: 3}},
+			}),
+			want: &MediaInfo{Type: "voice", MimeType: "audio/ogg", Size: 5000, Duration: 3,
+				ResourceURI: "telegram://document/14/0/2?ref=&kind=voice&size=5000&mime=audio%2Fogg"},
+		},
+		{
+			name: "audio file",
+			media: withDocument(&tg.Document{
+				ID: 15, DCID: 2, MimeType: "audio/mpeg", Size: 7000,
+				Attributes: []tg.DocumentAttributeClass{
+					&tg.DocumentAttributeAudio{Title: "Song", Duration: 215},
+					&tg.DocumentAttributeFilename{FileName: "song.mp3"},
+				},
+			}),
+			want: &MediaInfo{Type: "audio", FileName: "song.mp3", MimeType: "audio/mpeg", Size: 7000, Duration: 215,
+				ResourceURI: "telegram://document/15/0/2?ref=&kind=audio&size=7000&mime=audio%2Fmpeg"},
+		},
+		{
+			name:  "no mime type, size or attributes",
+			media: withDocument(&tg.Document{ID: 12, DCID: 1}),
+			want:  &MediaInfo{Type: "document", ResourceURI: "telegram://document/12/0/1?ref=&kind=document&size=0&mime="},
+		},
+		{
+			name:  "empty document",
+			media: withDocument(&tg.DocumentEmpty{ID: 13}),
+			want:  &MediaInfo{Type: "document"},
+		},
+		{
+			name:  "document missing",
+			media: &tg.MessageMediaDocument{},
+			want:  &MediaInfo{Type: "document"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := extractMediaType(tt.media); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("extractMediaType() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExtractMediaTypePhoto(t *testing.T) {
+	withPhoto := func(photo tg.PhotoClass) *tg.MessageMediaPhoto {
+		media := &tg.MessageMediaPhoto{}
+		media.SetPhoto(photo)
+		return media
+	}
+
+	tests := []struct {
+		name  string
+		media tg.MessageMediaClass
+		want  *MediaInfo
+	}{
+		{
+			name: "largest size",
+			media: withPhoto(&tg.Photo{
+				ID: 20, AccessHash: 30, DCID: 2, FileReference: []byte{1, 2},
+				Sizes: []tg.PhotoSizeClass{
+					&tg.PhotoStrippedSize{Type: "i"},
+					&tg.PhotoSize{Type: "m", W: 320, H: 240, Size: 15000},
+					&tg.PhotoSizeProgressive{Type: "y", W: 1280, H: 960, Sizes: []int{20000, 60000, 110000}},
+				},
+			}),
+			want: &MediaInfo{Type: "photo", MimeType: "image/jpeg", Size: 110000, Width: 1280, Height: 960,
+				ResourceURI: "telegram://media/20/30/2/y?ref=AQI=&sizes=m,y"},
+		},
+		{
+			name:  "empty photo",
+			media: withPhoto(&tg.PhotoEmpty{ID: 21}),
+			want:  &MediaInfo{Type: "photo"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := extractMediaType(tt.media); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("extractMediaType() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,193 @@
+package messages
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// DefaultMaxReplyParents is the default cap on reply parents fetched for one result.
+const DefaultMaxReplyParents = 200
+
+// replyParentsBatchSize is the number of messages requested per getMessages call.
+const replyParentsBatchSize = 100
+
+// MaxResolvedReplies caps the replied-to messages ResolveReplies fetches for one page,
+// so they take a single request.
+const MaxResolvedReplies = replyParentsBatchSize
+
+// ReplySnippetLength is the number of characters of a replied-to message's text that
+// ResolveReplies keeps.
+const ReplySnippetLength = 100
+
+// idsFetcher fetches messages of one chat by ID.
+type idsFetcher func(ctx context.Context, ids []int) ([]Message, error)
+
+// MissingReplyParents returns the IDs that msgs reply to but don't contain, in
+// ascending order. At most maxIDs are returned, preferring the most recent ones;
+// skipped is the number left out by the cap.
+func MissingReplyParents(msgs []Message, maxIDs int) (ids []int, skipped int) {
+	var refs ReplyRefs
+	refs.Add(msgs)
+	return refs.Missing(maxIDs)
+}
+
+// ReplyRefs records which messages a stream of batches contains and which they reply
+// to, so the missing reply parents can be found without keeping the messages.
+// The zero value is ready to use.
+type ReplyRefs struct {
+	present map[int]bool
+	replyTo []int
+}
+
+// Add records a batch of messages.
+func (r *ReplyRefs) Add(msgs []Message) {
+	if r.present == nil {
+		r.present = make(map[int]bool, len(msgs))
+	}
+	for _, msg := range msgs {
+		for _, id := range msg.IDs() {
+			r.present[id] = true
+		}
+		if msg.ReplyToID != 0 {
+			r.replyTo = append(r.replyTo, msg.ReplyToID)
+		}
+	}
+}
+
+// Missing returns the reply parents of the recorded messages as MissingReplyParents does.
+func (r *ReplyRefs) Missing(maxIDs int) (ids []int, skipped int) {
+	seen := make(map[int]bool)
+	for _, id := range r.replyTo {
+		if r.present[id] || seen[id] {
+			continue
+		}
+		seen[id] = true
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+
+	if len(ids) > maxIDs {
+		skipped = len(ids) - maxIDs
+		ids = ids[skipped:]
+	}
+	return ids, skipped
+}
+
+// FetchReplyParents fetches the messages that msgs reply to but don't contain,
+// up to maxParents of them. The returned messages are marked as Context.
+// Requests go through the shared rate limiter in batches of 100.
+func (p *Provider) FetchReplyParents(ctx context.Context, chatID int64, msgs []Message, maxParents int) ([]Message, int, error) {
+	return fetchReplyParents(ctx, func(ctx context.Context, ids []int) ([]Message, error) {
+		result, err := p.FetchByIDs(ctx, chatID, ids)
+		if err != nil {
+			return nil, err
+		}
+		return result.Messages, nil
+	}, msgs, maxParents)
+}
+
+// FetchContext fetches messages by ID marked as Context, such as the reply parents
+// ReplyRefs finds. Requests go through the shared rate limiter in batches of 100.
+func (p *Provider) FetchContext(ctx context.Context, chatID int64, ids []int) ([]Message, error) {
+	return fetchContext(ctx, func(ctx context.Context, ids []int) ([]Message, error) {
+		result, err := p.FetchByIDs(ctx, chatID, ids)
+		if err != nil {
+			return nil, err
+		}
+		return result.Messages, nil
+	}, ids)
+}
+
+// fetchReplyParents resolves missing reply parents with fetch. Messages that can't be
+// fetched (deleted or inaccessible) are silently left out.
+func fetchReplyParents(ctx context.Context, fetch idsFetcher, msgs []Message, maxParents int) ([]Message, int, error) {
+	ids, skipped := MissingReplyParents(msgs, maxParents)
+	parents, err := fetchContext(ctx, fetch, ids)
+	if err != nil {
+		return nil, skipped, err
+	}
+	return parents, skipped, nil
+}
+
+// fetchContext fetches ids with fetch and marks them as Context.
+func fetchContext(ctx context.Context, fetch idsFetcher, ids []int) ([]Message, error) {
+	var parents []Message
+	for chunk := range slices.Chunk(ids, replyParentsBatchSize) {
+		batch, err := fetch(ctx, chunk)
+		if err != nil {
+			return nil, fmt.Errorf("fetching reply parents: %w", err)
+		}
+		for _, msg := range batch {
+			msg.Context = true
+			parents = append(parents, msg)
+		}
+	}
+	return parents, nil
+}
+
+// resolveReplies sets the reply_to sender and text of msgs from the messages they
+// reply to, taken from msgs itself or fetched in one request. Replies to the oldest
+// parents beyond MaxResolvedReplies, and to deleted messages, are left unresolved.
+func resolveReplies(ctx context.Context, fetch idsFetcher, msgs []Message) error {
+	parents := make(map[int]Message, len(msgs))
+	for _, msg := range msgs {
+		parents[msg.ID] = msg
+	}
+	if ids, _ := MissingReplyParents(msgs, MaxResolvedReplies); len(ids) > 0 {
+		fetched, err := fetch(ctx, ids)
+		if err != nil {
+			return fmt.Errorf("resolving replies: %w", err)
+		}
+		for _, msg := range fetched {
+			parents[msg.ID] = msg
+		}
+	}
+
+	for i, msg := range msgs {
+		parent, ok := parents[msg.ReplyToID]
+		if msg.ReplyToID == 0 || !ok {
+			continue
+		}
+		msgs[i].ReplyToSenderID = parent.SenderID
+		msgs[i].ReplyToSender = parent.SenderName
+		msgs[i].ReplyToText = replySnippet(parent)
+	}
+	return nil
+}
+
+// replySnippet returns the opening of a message's text on one line, or its media type
+// for a message without text.
+func replySnippet(msg Message) string {
+	text := strings.Join(strings.Fields(summaryText(msg)), " ")
+	if text == "" && msg.Media != nil {
+		return "[" + msg.Media.Type + "]"
+	}
+	i := 0
+	for j := range text {
+		if i == ReplySnippetLength {
+			return text[:j] + "..."
+		}
+		i++
+	}
+	return text
+}
+
+// MergeByID merges extra into msgs, keeping the order of msgs: newest first if its
+// first message has the highest ID, oldest first otherwise.
+func MergeByID(msgs, extra []Message) []Message {
+	merged := make([]Message, 0, len(msgs)+len(extra))
+	merged = append(merged, msgs...)
+	merged = append(merged, extra...)
+
+	newestFirst := len(msgs) > 1 && msgs[0].ID > msgs[len(msgs)-1].ID
+	sort.SliceStable(merged, func(i, j int) bool {
+		if newestFirst {
+			return merged[i].ID > merged[j].ID
+		}
+		return merged[i].ID < merged[j].ID
+	})
+	return merged
+}
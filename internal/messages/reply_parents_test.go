@@ -0,0 +1,192 @@
+package messages
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestMissingReplyParents(t *testing.T) {
+	tests := []struct {
+		name        string
+		msgs        []Message
+		maxIDs      int
+		wantIDs     []int
+		wantSkipped int
+	}{
+		{
+			name:   "no replies",
+			msgs:   []Message{{ID: 1}, {ID: 2}},
+			maxIDs: 10,
+		},
+		{
+			name:   "parents already present",
+			msgs:   []Message{{ID: 1}, {ID: 2, ReplyToID: 1}, {ID: 3, ReplyToID: 2}},
+			maxIDs: 10,
+		},
+		{
+			name:    "missing parents deduplicated and sorted",
+			msgs:    []Message{{ID: 10, ReplyToID: 5}, {ID: 11, ReplyToID: 3}, {ID: 12, ReplyToID: 5}, {ID: 13, ReplyToID: 10}},
+			maxIDs:  10,
+			wantIDs: []int{3, 5},
+		},
+		{
+			name:        "cap keeps most recent",
+			msgs:        []Message{{ID: 10, ReplyToID: 1}, {ID: 11, ReplyToID: 2}, {ID: 12, ReplyToID: 3}},
+			maxIDs:      2,
+			wantIDs:     []int{2, 3},
+			wantSkipped: 1,
+		},
+		{
+			name:        "zero cap",
+			msgs:        []Message{{ID: 10, ReplyToID: 1}},
+			maxIDs:      0,
+			wantIDs:     []int{},
+			wantSkipped: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ids, skipped := MissingReplyParents(tt.msgs, tt.maxIDs)
+			if !reflect.DeepEqual(ids, tt.wantIDs) {
+				t.Errorf("ids = %v, want %v", ids, tt.wantIDs)
+			}
+			if skipped != tt.wantSkipped {
+				t.Errorf("skipped = %d, want %d", skipped, tt.wantSkipped)
+			}
+		})
+	}
+}
+
+func TestFetchReplyParents(t *testing.T) {
+	var msgs []Message
+	for i := range 250 {
+		msgs = append(msgs, Message{ID: 1000 + i, ReplyToID: i + 1})
+	}
+
+	var calls [][]int
+	fetch := func(_ context.Context, ids []int) ([]Message, error) {
+		calls = append(calls, ids)
+		var out []Message
+		for _, id := range ids {
+			if id%2 == 0 { // odd IDs are "deleted"
+				out = append(out, Message{ID: id})
+			}
+		}
+		return out, nil
+	}
+
+	parents, skipped, err := fetchReplyParents(context.Background(), fetch, msgs, 220)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if skipped != 30 {
+		t.Errorf("skipped = %d, want 30", skipped)
+	}
+	if len(calls) != 3 || len(calls[0]) != 100 || len(calls[1]) != 100 || len(calls[2]) != 20 {
+		t.Errorf("batch sizes wrong: %d calls", len(calls))
+	}
+	if calls[0][0] != 31 {
+		t.Errorf("first requested ID = %d, want 31", calls[0][0])
+	}
+	if len(parents) != 110 {
+		t.Errorf("got %d parents, want 110", len(parents))
+	}
+	for _, p := range parents {
+		if !p.Context {
+			t.Fatalf("parent %d not marked as context", p.ID)
+		}
+	}
+}
+
+func TestFetchReplyParentsNothingMissing(t *testing.T) {
+	fetch := func(context.Context, []int) ([]Message, error) {
+		t.Fatal("fetch should not be called")
+		return nil, nil
+	}
+	msgs := []Message{{ID: 1}, {ID: 2, ReplyToID: 1}}
+	parents, skipped, err := fetchReplyParents(context.Background(), fetch, msgs, DefaultMaxReplyParents)
+	if err != nil || skipped != 0 || len(parents) != 0 {
+		t.Errorf("got %v, %d, %v; want nothing", parents, skipped, err)
+	}
+}
+
+func TestFetchReplyParentsError(t *testing.T) {
+	fetch := func(context.Context, []int) ([]Message, error) {
+		return nil, errors.New("boom")
+	}
+	_, _, err := fetchReplyParents(context.Background(), fetch, []Message{{ID: 2, ReplyToID: 1}}, 10)
+	if err == nil {
+		t.Fatal("expected error")
+	}
+}
+
+func TestResolveReplies(t *testing.T) {
+	long := strings.Repeat("a", ReplySnippetLength+20)
+	msgs := []Message{
+		{ID: 10, ReplyToID: 9},
+		{ID: 9, SenderID: 1, SenderName: "Anna", Text: "in the\npage", ReplyToID: 3},
+		{ID: 8, ReplyToID: 4},
+		{ID: 7, ReplyToID: 5},
+		{ID: 6},
+	}
+	var requested [][]int
+	fetch := func(_ context.Context, ids []int) ([]Message, error) {
+		requested = append(requested, ids)
+		// 5 was deleted
+		return []Message{
+			{ID: 3, SenderID: 2, SenderName: "Bob", Text: long},
+			{ID: 4, SenderID: 1, SenderName: "Anna", Media: &MediaInfo{Type: "photo"}},
+		}, nil
+	}
+
+	if err := resolveReplies(t.Context(), fetch, msgs); err != nil {
+		t.Fatalf("resolveReplies() error = %v", err)
+	}
+	if want := [][]int{{3, 4, 5}}; !reflect.DeepEqual(requested, want) {
+		t.Errorf("fetched %v, want %v in one request", requested, want)
+	}
+	if m := msgs[0]; m.ReplyToSenderID != 1 || m.ReplyToSender != "Anna" || m.ReplyToText != "in the page" {
+		t.Errorf("reply within the page = %+v", m)
+	}
+	if m := msgs[1]; m.ReplyToSender != "Bob" || m.ReplyToText != long[:ReplySnippetLength]+"..." {
+		t.Errorf("reply to a long message = %q by %q", m.ReplyToText, m.ReplyToSender)
+	}
+	if got := msgs[2].ReplyToText; got != "[photo]" {
+		t.Errorf("reply to media = %q, want [photo]", got)
+	}
+	if m := msgs[3]; m.ReplyToText != "" || m.ReplyToSender != "" {
+		t.Errorf("reply to a deleted message = %+v, want it unresolved", m)
+	}
+	if got := FormatForSummary(msgs[0]); !strings.Contains(got, ` (reply to Anna: "in the page"): `) {
+		t.Errorf("FormatForSummary() = %q, want the reply marker", got)
+	}
+
+	errFetch := errors.New("flood wait")
+	failing := func(context.Context, []int) ([]Message, error) { return nil, errFetch }
+	if err := resolveReplies(t.Context(), failing, []Message{{ID: 2, ReplyToID: 1}}); !errors.Is(err, errFetch) {
+		t.Errorf("resolveReplies() error = %v, want %v", err, errFetch)
+	}
+}
+
+func TestMergeByID(t *testing.T) {
+	newest := MergeByID([]Message{{ID: 9}, {ID: 5}}, []Message{{ID: 7}, {ID: 1}})
+	if got := messageIDs(newest); !reflect.DeepEqual(got, []int{9, 7, 5, 1}) {
+		t.Errorf("newest first = %v", got)
+	}
+	oldest := MergeByID([]Message{{ID: 5}, {ID: 9}}, []Message{{ID: 7}, {ID: 1}})
+	if got := messageIDs(oldest); !reflect.DeepEqual(got, []int{1, 5, 7, 9}) {
+		t.Errorf("oldest first = %v", got)
+	}
+}
+
+func messageIDs(msgs []Message) []int {
+	out := make([]int, len(msgs))
+	for i, m := range msgs {
+		out[i] = m.ID
+	}
+	return out
+}
@@ -0,0 +1,102 @@
+package messages
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gotd/td/tg"
+)
+
+// MaxSearchLimit is the maximum number of messages a single search may return.
+const MaxSearchLimit = 500
+
+// Search finds messages in a chat containing query, newest first, using Telegram's
+// server-side search. It pages through the results until opts.Limit messages are
+// collected; OffsetID, MinDate and MaxDate apply as in Fetch.
+func (p *Provider) Search(ctx context.Context, chatID int64, query string, opts FetchOptions) (*FetchResult, error) {
+	if opts.Limit <= 0 {
+		opts.Limit = 50
+	}
+	request := &tg.MessagesSearchRequest{
+		Q:      query,
+		Filter: &tg.InputMessagesFilterEmpty{},
+	}
+	if !opts.MinDate.IsZero() {
+		request.MinDate = int(opts.MinDate.Unix())
+	}
+	if maxBound := opts.maxDateBound(); !maxBound.IsZero() {
+		request.MaxDate = int(maxBound.Unix())
+	}
+
+	var result *FetchResult
+	err := p.peers.WithPeer(ctx, p.client, chatID, func(peer tg.InputPeerClass) error {
+		request.Peer = peer
+		var err error
+		result, err = collectPages(ctx, func(ctx context.Context, page FetchOptions) (*FetchResult, error) {
+			request.OffsetID = page.OffsetID
+			request.Limit = page.Limit
+			p.limiter.Take()
+			found, err := p.client.MessagesSearch(ctx, request)
+			if err != nil {
+				return nil, fmt.Errorf("searching messages: %w", err)
+			}
+			return p.processHistory(found, peer)
+		}, opts.OffsetID, opts.Limit)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	result.ChatID = chatID
+	return result, nil
+}
+
+// collectPages pages through fetch from offsetID until limit messages are collected
+// or the results run out. Total is the result count the first page reports.
+func collectPages(ctx context.Context, fetch batchFetcher, offsetID, limit int) (*FetchResult, error) {
+	result := &FetchResult{
+		Messages: make([]Message, 0),
+		Users:    make(map[int64]string),
+		Chats:    make(map[int64]string),
+	}
+
+	page := FetchOptions{OffsetID: offsetID}
+	for first := true; len(result.Messages) < limit; first = false {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		page.Limit = min(limit-len(result.Messages), MaxFetchLimit)
+		batch, err := fetch(ctx, page)
+		if err != nil {
+			return nil, err
+		}
+		if first {
+			result.Total = batch.Total
+		}
+		for k, v := range batch.Users {
+			result.Users[k] = v
+		}
+		for k, v := range batch.Chats {
+			result.Chats[k] = v
+		}
+		result.Messages = append(result.Messages, batch.Messages...)
+		// A short page is the last one; the count covers results before the offset too
+		result.HasMore = len(batch.Messages) >= page.Limit && len(result.Messages) < result.Total
+		if !result.HasMore {
+			break
+		}
+		page.OffsetID = batch.Messages[len(batch.Messages)-1].ID
+	}
+
+	// A last page may return more than asked for
+	if len(result.Messages) > limit {
+		result.Messages = result.Messages[:limit]
+		result.HasMore = true
+	}
+	result.Count = len(result.Messages)
+	result.NextID = 0
+	if result.HasMore && result.Count > 0 {
+		result.NextID = result.Messages[result.Count-1].ID
+	}
+	return result, nil
+}
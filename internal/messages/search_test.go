@@ -0,0 +1,68 @@
+package messages
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestCollectPages(t *testing.T) {
+	// Search results, newest first, served in pages of the requested size
+	var all []Message
+	for id := 250; id > 0; id -= 2 {
+		all = append(all, Message{ID: id})
+	}
+
+	tests := []struct {
+		name        string
+		offsetID    int
+		limit       int
+		wantCount   int
+		wantFirst   int
+		wantHasMore bool
+		wantNextID  int
+		wantPages   []int // Requested page sizes
+	}{
+		{name: "single page", limit: 30, wantCount: 30, wantFirst: 250, wantHasMore: true, wantNextID: 192, wantPages: []int{30}},
+		{name: "several pages", limit: 210, wantCount: 125, wantFirst: 250, wantPages: []int{100, 100}},
+		{name: "exact limit", limit: 125, wantCount: 125, wantFirst: 250, wantPages: []int{100, 25}},
+		{name: "from offset", offsetID: 200, limit: 150, wantCount: 99, wantFirst: 198, wantPages: []int{100}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var pages []int
+			fetch := func(_ context.Context, opts FetchOptions) (*FetchResult, error) {
+				pages = append(pages, opts.Limit)
+				var rest []Message
+				for _, m := range all {
+					if opts.OffsetID == 0 || m.ID < opts.OffsetID {
+						rest = append(rest, m)
+					}
+				}
+				batch := rest[:min(opts.Limit, len(rest))]
+				return &FetchResult{Messages: batch, Total: len(all)}, nil
+			}
+
+			result, err := collectPages(context.Background(), fetch, tt.offsetID, tt.limit)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result.Count != tt.wantCount || len(result.Messages) != tt.wantCount {
+				t.Errorf("count = %d (%d messages), want %d", result.Count, len(result.Messages), tt.wantCount)
+			}
+			if len(result.Messages) > 0 && result.Messages[0].ID != tt.wantFirst {
+				t.Errorf("first ID = %d, want %d", result.Messages[0].ID, tt.wantFirst)
+			}
+			if result.HasMore != tt.wantHasMore || result.NextID != tt.wantNextID {
+				t.Errorf("has_more = %v, next_id = %d, want %v, %d", result.HasMore, result.NextID, tt.wantHasMore, tt.wantNextID)
+			}
+			if result.Total != len(all) {
+				t.Errorf("total = %d, want %d", result.Total, len(all))
+			}
+			if !reflect.DeepEqual(pages, tt.wantPages) {
+				t.Errorf("page sizes = %v, want %v", pages, tt.wantPages)
+			}
+		})
+	}
+}
@@ -8,25 +8,72 @@ import (
 
 // Message represents a Telegram message with parsed metadata.
 type Message struct {
-	ID         int         `json:"id"`
-	Date       time.Time   `json:"date"`
-	SenderID   int64       `json:"sender_id,omitempty"`
-	SenderName string      `json:"sender_name,omitempty"`
-	Text       string      `json:"text"`
-	ReplyToID  int         `json:"reply_to_id,omitempty"`
-	Media      *MediaInfo  `json:"media,omitempty"`
-	Entities   []string    `json:"entities,omitempty"`
-	Raw        *tg.Message `json:"-"` // Original message for advanced use cases
+	ID         int       `json:"id"`
+	Date       time.Time `json:"date"`
+	EditDate   time.Time `json:"edit_date,omitzero"` // Last edit, zero if never edited
+	SenderID   int64     `json:"sender_id,omitempty"`
+	SenderName string    `json:"sender_name,omitempty"`
+	PostAuthor string    `json:"post_author,omitempty"` // Admin signature of a channel post
+	ViaBotID   int64     `json:"via_bot_id,omitempty"`  // Inline bot the message was sent via
+	ViaBot     string    `json:"via_bot,omitempty"`     // Username of that bot, e.g. "@gif"
+	Text       string    `json:"text"`
+	ReplyToID  int       `json:"reply_to_id,omitempty"`
+	ThreadID   int       `json:"thread_id,omitempty"` // Post or thread start the message replies under, set by FetchOptions.ThreadID
+	// The replied-to message's sender and opening text, set by FetchOptions.ResolveReplies
+	ReplyToSenderID int64        `json:"reply_to_sender_id,omitempty"`
+	ReplyToSender   string       `json:"reply_to_sender,omitempty"`
+	ReplyToText     string       `json:"reply_to_text,omitempty"`
+	Media           *MediaInfo   `json:"media,omitempty"`
+	Album           []*MediaInfo `json:"album,omitempty"`     // Media of every item of an album grouped into this message
+	AlbumIDs        []int        `json:"album_ids,omitempty"` // IDs of the grouped album's messages, oldest first
+	Entities        []string     `json:"entities,omitempty"`
+	Buttons         [][]Button   `json:"buttons,omitempty"` // Keyboard rows of bot messages
+	Context         bool         `json:"context,omitempty"` // Fetched only as context, outside the requested range
+	Link            string       `json:"link,omitempty"`    // t.me link, for channels and supergroups only
+	// ForwardedFrom is the origin of a forwarded message, nil for original ones
+	ForwardedFrom *ForwardInfo   `json:"forwarded_from,omitempty"`
+	Views         int            `json:"views,omitempty"`     // Views of a channel post
+	Forwards      int            `json:"forwards,omitempty"`  // Times a channel post was forwarded
+	Reactions     map[string]int `json:"reactions,omitempty"` // Reaction, e.g. "👍", -> count
+	Raw           *tg.Message    `json:"-"`                   // Original message for advanced use cases
 }
 
+// ForwardInfo describes where a forwarded message came from.
+type ForwardInfo struct {
+	FromID int64     `json:"from_id,omitempty"` // Original sender or channel, 0 if they hide their account
+	From   string    `json:"from"`              // Its name, or the name shown for a hidden account
+	Date   time.Time `json:"date"`              // When the original message was sent
+}
+
+// Kinds of document-backed media; every one of them can be downloaded by its document URI.
+const (
+	MediaDocument = "document"
+	MediaVideo    = "video"
+	MediaVoice    = "voice"
+	MediaAudio    = "audio"
+)
+
 // MediaInfo represents media attached to a message.
 type MediaInfo struct {
 	Type        string `json:"type"`
 	URL         string `json:"url,omitempty"`          // URL for webpage media
 	FileName    string `json:"file_name,omitempty"`    // Filename for documents
+	MimeType    string `json:"mime_type,omitempty"`    // MIME type for photos, documents, videos and audio
+	Size        int64  `json:"size,omitempty"`         // Size in bytes of the file, or of the largest photo size
+	Duration    int    `json:"duration,omitempty"`     // Length in seconds of videos and audio
 	Width       int    `json:"width,omitempty"`        // Width for photos/videos
 	Height      int    `json:"height,omitempty"`       // Height for photos/videos
-	ResourceURI string `json:"resource_uri,omitempty"` // MCP resource URI for downloading
+	ResourceURI string `json:"resource_uri,omitempty"` // URI for GetMedia, for photos and documents
+}
+
+// IsDocument reports whether the media is a file: a document, video or audio.
+func (m *MediaInfo) IsDocument() bool {
+	switch m.Type {
+	case MediaDocument, MediaVideo, MediaVoice, MediaAudio:
+		return true
+	default:
+		return false
+	}
 }
 
 // FetchResult contains messages and metadata from a fetch operation.
@@ -39,17 +86,46 @@ type FetchResult struct {
 	HasMore  bool             `json:"has_more"`
 	NextID   int              `json:"next_id,omitempty"`
 	Total    int              `json:"-"` // Total messages in the chat (from API)
+	// With SinceLastRead, the read inbox position the page starts after and how many
+	// unread messages the scanned page covered, before EditedSince filtering
+	ReadInboxMaxID *int `json:"read_inbox_max_id,omitempty"`
+	UnreadCovered  *int `json:"unread_covered,omitempty"`
 }
 
+// SinceLastRead is the "since" value selecting messages after the user's read position
+// in a chat rather than after a date.
+const SinceLastRead = "last_read"
+
 // FetchOptions configures message fetching.
 type FetchOptions struct {
 	Limit      int
 	OffsetID   int
+	AddOffset  int // Shifts the page from OffsetID; -n starts n messages newer, so it returns IDs >= OffsetID
 	OffsetDate time.Time
 	MinDate    time.Time // Filter: only messages after this date
-	MaxDate    time.Time // Filter: only messages before this date
-	UnreadOnly bool
-	MaxCount   int // Stop after collecting this many messages (0 = no limit)
+	MaxDate    time.Time // Filter: only messages up to this date (its whole day unless MaxDateExact)
+	// MaxDateExact treats MaxDate as an exact instant rather than a calendar day
+	MaxDateExact bool
+	UnreadOnly   bool
+	MinID        int // Only messages with a greater ID, e.g. the read inbox position
+	MaxID        int // Only messages with a smaller ID (0 = no limit)
+	MaxCount     int // Stop after collecting this many messages (0 = no limit)
+	// GroupAlbums merges the messages of each album into one, see GroupAlbums
+	GroupAlbums bool
+	// ThreadID fetches the comments on this channel post, or the replies in this
+	// thread of a group, instead of the chat's history. Telegram finds a channel's
+	// linked discussion group itself.
+	ThreadID int
+	// ResolveReplies fills in the sender and text of the messages each page replies
+	// to, fetching those outside the page in one extra request
+	ResolveReplies bool
+	// SinceLastRead starts after the read inbox position, raising MinID to it, and
+	// reports the position and the unread messages covered in the result
+	SinceLastRead bool
+	// EditedSince keeps only the messages edited after this time. Telegram can't
+	// filter by edit date, so only the requested page is scanned and pagination
+	// (HasMore, NextID) follows it
+	EditedSince time.Time
 }
 
 // BatchCallback is called after each batch is fetched.
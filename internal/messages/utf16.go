@@ -0,0 +1,20 @@
+package messages
+
+// UTF16Len returns the length of s in UTF-16 code units, the unit Telegram uses
+// for message entity offsets and lengths.
+func UTF16Len(s string) int {
+	n := 0
+	for _, r := range s {
+		n += utf16RuneLen(r)
+	}
+	return n
+}
+
+// utf16RuneLen returns the number of UTF-16 code units needed to encode r:
+// two for characters outside the Basic Multilingual Plane (most emoji), one otherwise.
+func utf16RuneLen(r rune) int {
+	if r > 0xFFFF {
+		return 2
+	}
+	return 1
+}
@@ -0,0 +1,36 @@
+package messages
+
+import "testing"
+
+func TestUTF16Len(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  int
+	}{
+		{name: "empty", input: "", want: 0},
+		{name: "ASCII", input: "Hello", want: 5},
+		{name: "Cyrillic", input: "Привет", want: 6},
+		{name: "emoji", input: "🔥", want: 2},
+		{name: "flag", input: "🇺🇸", want: 4},
+		{name: "mixed", input: "Привет 👋 мир", want: 13},
+		{name: "invalid UTF-8", input: "a\xffb", want: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := UTF16Len(tt.input); got != tt.want {
+				t.Errorf("UTF16Len(%q) = %d, want %d", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+// Every substring extracted with full-length entities must round-trip through UTF16Len.
+func TestExtractSubstringFullLength(t *testing.T) {
+	for _, s := range []string{"Hello", "Привет 👋 мир", "Hi 🇺🇸 there", "🔥"} {
+		if got := extractSubstring(s, 0, UTF16Len(s)); got != s {
+			t.Errorf("extractSubstring(%q, 0, UTF16Len) = %q", s, got)
+		}
+	}
+}
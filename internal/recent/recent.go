@@ -0,0 +1,124 @@
+// Package recent tracks chats recently used by tools so failures can suggest likely candidates.
+package recent
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// DefaultSize is the default number of chats remembered by a Tracker.
+const DefaultSize = 20
+
+// NameLookup resolves a chat ID to its display name.
+type NameLookup func(ctx context.Context, chatID int64) (string, error)
+
+// Entry describes the most recent use of a chat.
+type Entry struct {
+	ChatID   int64     `json:"chat_id"`
+	ChatName string    `json:"chat_name,omitempty"`
+	Tool     string    `json:"last_tool"`
+	Time     time.Time `json:"last_used"`
+}
+
+// Tracker remembers the last distinct chats touched by tools in a bounded
+// most-recently-used list, so one busy chat cannot push all others out.
+// It is safe for concurrent use.
+type Tracker struct {
+	mu      sync.Mutex
+	size    int
+	entries []Entry // newest first, at most size entries, one per chat
+	names   map[int64]string
+	lookup  NameLookup
+	now     func() time.Time
+}
+
+// NewTracker creates a Tracker remembering the last size chats (DefaultSize if zero).
+// lookup fills in chat names on demand and may be nil.
+func NewTracker(size int, lookup NameLookup) *Tracker {
+	if size <= 0 {
+		size = DefaultSize
+	}
+	return &Tracker{
+		size:   size,
+		names:  make(map[int64]string),
+		lookup: lookup,
+		now:    time.Now,
+	}
+}
+
+// Touch records that tool used the given chat.
+func (t *Tracker) Touch(chatID int64, tool string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	entries := make([]Entry, 0, t.size)
+	entries = append(entries, Entry{ChatID: chatID, Tool: tool, Time: t.now()})
+	for _, e := range t.entries {
+		if e.ChatID != chatID && len(entries) < t.size {
+			entries = append(entries, e)
+		}
+	}
+	t.entries = entries
+}
+
+// SetName remembers a chat's display name so List does not need to look it up.
+func (t *Tracker) SetName(chatID int64, name string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.names[chatID] = name
+}
+
+// Name returns a chat's display name from the cache, resolving and caching it with
+// the lookup function when missing. The chat does not need to be in the buffer.
+func (t *Tracker) Name(ctx context.Context, chatID int64) (string, error) {
+	t.mu.Lock()
+	name, ok := t.names[chatID]
+	t.mu.Unlock()
+	if ok {
+		return name, nil
+	}
+	if t.lookup == nil {
+		return "", errors.New("no chat name lookup configured")
+	}
+
+	name, err := t.lookup(ctx, chatID)
+	if err != nil {
+		return "", err
+	}
+	if name != "" {
+		t.SetName(chatID, name)
+	}
+	return name, nil
+}
+
+// List returns the distinct chats in the buffer, most recently used first.
+// Missing names are resolved with the lookup function and cached; lookup failures
+// leave the name empty.
+func (t *Tracker) List(ctx context.Context) []Entry {
+	entries := t.snapshot()
+
+	for i := range entries {
+		if entries[i].ChatName != "" || t.lookup == nil {
+			continue
+		}
+		if name, err := t.Name(ctx, entries[i].ChatID); err == nil {
+			entries[i].ChatName = name
+		}
+	}
+	return entries
+}
+
+// snapshot returns a copy of the entries with cached names filled in.
+func (t *Tracker) snapshot() []Entry {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	entries := make([]Entry, len(t.entries))
+	for i, e := range t.entries {
+		e.ChatName = t.names[e.ChatID]
+		entries[i] = e
+	}
+	return entries
+}
@@ -0,0 +1,139 @@
+package recent
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// newTestTracker returns a tracker whose clock advances one second per Touch.
+func newTestTracker(size int, lookup NameLookup) *Tracker {
+	tr := NewTracker(size, lookup)
+	start := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	calls := 0
+	tr.now = func() time.Time {
+		calls++
+		return start.Add(time.Duration(calls) * time.Second)
+	}
+	return tr
+}
+
+func chatIDs(entries []Entry) []int64 {
+	ids := make([]int64, len(entries))
+	for i, e := range entries {
+		ids[i] = e.ChatID
+	}
+	return ids
+}
+
+func TestTrackerList(t *testing.T) {
+	tests := []struct {
+		name    string
+		size    int
+		touches []int64
+		want    []int64
+	}{
+		{name: "empty", size: 3, touches: nil, want: []int64{}},
+		{name: "newest first", size: 3, touches: []int64{1, 2, 3}, want: []int64{3, 2, 1}},
+		{name: "wraps around", size: 3, touches: []int64{1, 2, 3, 4, 5}, want: []int64{5, 4, 3}},
+		{name: "repeated chat listed once", size: 5, touches: []int64{1, 2, 1, 3, 1}, want: []int64{1, 3, 2}},
+		{name: "repeats do not push out other chats", size: 3, touches: []int64{1, 2, 3, 3, 3}, want: []int64{3, 2, 1}},
+		{name: "least recently used evicted", size: 3, touches: []int64{1, 2, 3, 1, 4}, want: []int64{4, 1, 3}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tr := newTestTracker(tt.size, nil)
+			for _, id := range tt.touches {
+				tr.Touch(id, "GetMessages")
+			}
+			if got := chatIDs(tr.List(context.Background())); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTrackerKeepsLatestUse(t *testing.T) {
+	tr := newTestTracker(5, nil)
+	tr.Touch(1, "GetMessages")
+	tr.Touch(1, "SendMessage")
+
+	entries := tr.List(context.Background())
+	if len(entries) != 1 {
+		t.Fatalf("got %d entries, want 1", len(entries))
+	}
+	if entries[0].Tool != "SendMessage" {
+		t.Errorf("last tool = %q, want SendMessage", entries[0].Tool)
+	}
+	if want := time.Date(2024, 1, 15, 10, 0, 2, 0, time.UTC); !entries[0].Time.Equal(want) {
+		t.Errorf("time = %v, want %v", entries[0].Time, want)
+	}
+}
+
+func TestTrackerNames(t *testing.T) {
+	lookups := 0
+	lookup := func(_ context.Context, chatID int64) (string, error) {
+		lookups++
+		if chatID == 2 {
+			return "", errors.New("not found")
+		}
+		return "Chat One", nil
+	}
+
+	tr := newTestTracker(5, lookup)
+	tr.Touch(1, "GetMessages")
+	tr.Touch(2, "GetMessages")
+	tr.Touch(3, "GetMessages")
+	tr.SetName(3, "Known")
+
+	entries := tr.List(context.Background())
+	names := map[int64]string{}
+	for _, e := range entries {
+		names[e.ChatID] = e.ChatName
+	}
+	if want := map[int64]string{1: "Chat One", 2: "", 3: "Known"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("names = %v, want %v", names, want)
+	}
+	if lookups != 2 {
+		t.Errorf("lookups = %d, want 2", lookups)
+	}
+
+	// Resolved names are cached; only the failed one is retried
+	tr.List(context.Background())
+	if lookups != 3 {
+		t.Errorf("lookups after second List = %d, want 3", lookups)
+	}
+}
+
+func TestTrackerName(t *testing.T) {
+	lookups := 0
+	lookup := func(_ context.Context, chatID int64) (string, error) {
+		lookups++
+		if chatID == 2 {
+			return "", errors.New("not found")
+		}
+		return "Chat One", nil
+	}
+
+	tr := NewTracker(5, lookup)
+	for range 2 {
+		name, err := tr.Name(context.Background(), 1)
+		if err != nil || name != "Chat One" {
+			t.Fatalf("Name(1) = %q, %v", name, err)
+		}
+	}
+	if lookups != 1 {
+		t.Errorf("lookups = %d, want 1 (name cached)", lookups)
+	}
+
+	if _, err := tr.Name(context.Background(), 2); err == nil {
+		t.Error("expected lookup error")
+	}
+
+	if _, err := NewTracker(5, nil).Name(context.Background(), 1); err == nil {
+		t.Error("expected error without lookup")
+	}
+}
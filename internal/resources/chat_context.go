@@ -0,0 +1,67 @@
+package resources
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/tolmachov/mcp-telegram/internal/messages"
+	"github.com/tolmachov/mcp-telegram/internal/tgdata"
+)
+
+// ChatContextHandler handles the telegram://chat/{chat_id}/context resource template
+type ChatContextHandler struct {
+	provider *messages.Provider
+}
+
+// NewChatContextHandler creates a new ChatContextHandler
+func NewChatContextHandler(provider *messages.Provider) *ChatContextHandler {
+	return &ChatContextHandler{provider: provider}
+}
+
+// Template returns the MCP resource template definition
+func (h *ChatContextHandler) Template() mcp.ResourceTemplate {
+	return mcp.NewResourceTemplate(
+		"telegram://chat/{chat_id}/context{?max_chars}",
+		"Chat Context",
+		mcp.WithTemplateDescription("Compact grounding document for a chat: info, pinned message, last 30 messages, unread count and draft. Same as the GetChatContext tool."),
+		mcp.WithTemplateMIMEType("application/json"),
+	)
+}
+
+// Handle processes the telegram://chat/{chat_id}/context resource request
+func (h *ChatContextHandler) Handle(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+	chatID, params, err := parseChatResourceURI(request.Params.URI, "context")
+	if err != nil {
+		return nil, err
+	}
+
+	budget := tgdata.DefaultContextBudget
+	if v, ok := params["max_chars"].(string); ok {
+		budget, err = strconv.Atoi(v)
+		if err != nil || budget <= 0 {
+			return nil, fmt.Errorf("invalid max_chars %q", v)
+		}
+	}
+
+	cc, err := tgdata.GetChatContext(ctx, h.provider, chatID, budget)
+	if err != nil {
+		return nil, fmt.Errorf("getting chat context: %w", err)
+	}
+
+	data, err := json.Marshal(cc)
+	if err != nil {
+		return nil, fmt.Errorf("marshaling chat context: %w", err)
+	}
+
+	return []mcp.ResourceContents{
+		mcp.TextResourceContents{
+			URI:      request.Params.URI,
+			MIMEType: "application/json",
+			Text:     string(data),
+		},
+	}, nil
+}
@@ -0,0 +1,91 @@
+package resources
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/tolmachov/mcp-telegram/internal/messages"
+)
+
+// ChatMessagesHandler handles the telegram://chat/{chat_id}/messages resource template
+type ChatMessagesHandler struct {
+	provider *messages.Provider
+}
+
+// NewChatMessagesHandler creates a new ChatMessagesHandler
+func NewChatMessagesHandler(provider *messages.Provider) *ChatMessagesHandler {
+	return &ChatMessagesHandler{provider: provider}
+}
+
+// Template returns the MCP resource template definition
+func (h *ChatMessagesHandler) Template() mcp.ResourceTemplate {
+	uriTemplate := fmt.Sprintf("telegram://chat/{chat_id}/messages{?%s}", strings.Join(messages.FetchParamNames(), ","))
+	return mcp.NewResourceTemplate(
+		uriTemplate,
+		"Chat Messages",
+		mcp.WithTemplateDescription("Messages from a chat. Accepts the same query parameters as the GetMessages tool, except mark_read; chat_id is numeric."),
+		mcp.WithTemplateMIMEType("application/json"),
+	)
+}
+
+// Handle processes the telegram://chat/{chat_id}/messages resource request
+func (h *ChatMessagesHandler) Handle(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+	chatID, params, err := parseChatResourceURI(request.Params.URI, "messages")
+	if err != nil {
+		return nil, err
+	}
+
+	opts, err := messages.ParseFetchParams(params)
+	if err != nil {
+		return nil, fmt.Errorf("invalid parameters: %w", err)
+	}
+	opts.GroupAlbums = true
+
+	result, err := h.provider.Fetch(ctx, chatID, opts)
+	if err != nil {
+		return nil, fmt.Errorf("fetching messages: %w", err)
+	}
+
+	data, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("marshaling messages: %w", err)
+	}
+
+	return []mcp.ResourceContents{
+		mcp.TextResourceContents{
+			URI:      request.Params.URI,
+			MIMEType: "application/json",
+			Text:     string(data),
+		},
+	}, nil
+}
+
+// parseChatResourceURI extracts the chat ID and query parameters from a
+// telegram://chat/{chat_id}/{section} URI.
+func parseChatResourceURI(uri, section string) (int64, map[string]any, error) {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return 0, nil, fmt.Errorf("parsing resource URI: %w", err)
+	}
+	if u.Scheme != "telegram" || u.Host != "chat" {
+		return 0, nil, fmt.Errorf("unexpected resource URI %q", uri)
+	}
+
+	parts := strings.Split(strings.Trim(u.Path, "/"), "/")
+	if len(parts) != 2 || parts[1] != section {
+		return 0, nil, fmt.Errorf("unexpected resource URI %q, expected telegram://chat/{chat_id}/%s", uri, section)
+	}
+
+	chatID, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil || chatID == 0 {
+		return 0, nil, fmt.Errorf("invalid chat_id %q in resource URI", parts[0])
+	}
+
+	return chatID, messages.QueryParams(u.Query()), nil
+}
@@ -0,0 +1,107 @@
+package resources
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/tolmachov/mcp-telegram/internal/messages"
+)
+
+func TestChatMessagesTemplateDeclaresAllFetchParams(t *testing.T) {
+	raw := NewChatMessagesHandler(nil).Template().URITemplate.Raw()
+	for _, name := range messages.FetchParamNames() {
+		if !strings.Contains(raw, name) {
+			t.Errorf("resource template %q does not declare fetch parameter %q", raw, name)
+		}
+	}
+}
+
+func TestParseChatResourceURI(t *testing.T) {
+	tests := []struct {
+		name       string
+		uri        string
+		wantChatID int64
+		wantParams map[string]any
+		wantErr    bool
+	}{
+		{
+			name:       "no query",
+			uri:        "telegram://chat/123/messages",
+			wantChatID: 123,
+			wantParams: map[string]any{},
+		},
+		{
+			name:       "channel with query",
+			uri:        "telegram://chat/-1001234567890/messages?unread_only=true&limit=5",
+			wantChatID: -1001234567890,
+			wantParams: map[string]any{"unread_only": "true", "limit": "5"},
+		},
+		{
+			name:    "wrong section",
+			uri:     "telegram://chat/123/context",
+			wantErr: true,
+		},
+		{
+			name:    "invalid chat id",
+			uri:     "telegram://chat/abc/messages",
+			wantErr: true,
+		},
+		{
+			name:    "wrong host",
+			uri:     "telegram://chats/123/messages",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chatID, params, err := parseChatResourceURI(tt.uri, "messages")
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected error for %q", tt.uri)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if chatID != tt.wantChatID {
+				t.Errorf("chatID = %d, want %d", chatID, tt.wantChatID)
+			}
+			if !reflect.DeepEqual(params, tt.wantParams) {
+				t.Errorf("params = %v, want %v", params, tt.wantParams)
+			}
+		})
+	}
+}
+
+func TestChatMessagesResourceMatchesToolParsing(t *testing.T) {
+	fromResource, params, err := parseChatResourceURI("telegram://chat/42/messages?limit=30&offset_id=100&unread_only=1&since=last_read&edited_since=2024-03-01", "messages")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if fromResource != 42 {
+		t.Fatalf("chatID = %d, want 42", fromResource)
+	}
+
+	resourceOpts, err := messages.ParseFetchParams(params)
+	if err != nil {
+		t.Fatalf("resource params: %v", err)
+	}
+	toolOpts, err := messages.ParseFetchParams(map[string]any{
+		"chat_id":      float64(42),
+		"limit":        float64(30),
+		"offset_id":    float64(100),
+		"unread_only":  true,
+		"since":        "last_read",
+		"edited_since": "2024-03-01",
+	})
+	if err != nil {
+		t.Fatalf("tool params: %v", err)
+	}
+
+	if !reflect.DeepEqual(resourceOpts, toolOpts) {
+		t.Errorf("resource options %+v differ from tool options %+v", resourceOpts, toolOpts)
+	}
+}
@@ -14,12 +14,16 @@ import (
 
 // ChatsHandler handles the telegram://chats resource
 type ChatsHandler struct {
-	client *tg.Client
+	client          *tg.Client
+	includeArchived bool
+	paginateBlocks  bool
 }
 
-// NewChatsHandler creates a new ChatsHandler
-func NewChatsHandler(client *tg.Client) *ChatsHandler {
-	return &ChatsHandler{client: client}
+// NewChatsHandler creates a new ChatsHandler.
+// includeArchived controls whether chats from the archive folder are listed;
+// paginateBlocks splits the list into contents of tgdata.DefaultChatsPerPart chats each.
+func NewChatsHandler(client *tg.Client, includeArchived, paginateBlocks bool) *ChatsHandler {
+	return &ChatsHandler{client: client, includeArchived: includeArchived, paginateBlocks: paginateBlocks}
 }
 
 // Resource returns the MCP resource definition
@@ -43,21 +47,37 @@ func (h *ChatsHandler) Handle(ctx context.Context, _ mcp.ReadResourceRequest) ([
 		}
 	}
 
-	result, err := tgdata.GetChats(ctx, h.client, onProgress)
+	result, err := tgdata.GetChats(ctx, h.client, h.includeArchived, 0, onProgress)
 	if err != nil {
 		return nil, err
 	}
 
-	data, err := json.MarshalIndent(result, "", "  ")
-	if err != nil {
-		return nil, fmt.Errorf("marshaling chats: %w", err)
+	if !h.paginateBlocks {
+		data, err := json.MarshalIndent(result, "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("marshaling chats: %w", err)
+		}
+		return []mcp.ResourceContents{
+			mcp.TextResourceContents{
+				URI:      "telegram://chats",
+				MIMEType: "application/json",
+				Text:     string(data),
+			},
+		}, nil
 	}
 
-	return []mcp.ResourceContents{
-		mcp.TextResourceContents{
+	parts := tgdata.SplitChats(result, tgdata.DefaultChatsPerPart)
+	contents := make([]mcp.ResourceContents, 0, len(parts))
+	for _, part := range parts {
+		data, err := json.MarshalIndent(part, "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("marshaling chats part %d: %w", part.Part, err)
+		}
+		contents = append(contents, mcp.TextResourceContents{
 			URI:      "telegram://chats",
 			MIMEType: "application/json",
 			Text:     string(data),
-		},
-	}, nil
+		})
+	}
+	return contents, nil
 }
@@ -0,0 +1,53 @@
+package resources
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/gotd/td/tg"
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/tolmachov/mcp-telegram/internal/tgdata"
+)
+
+// FoldersHandler handles the telegram://folders resource
+type FoldersHandler struct {
+	client *tg.Client
+}
+
+// NewFoldersHandler creates a new FoldersHandler
+func NewFoldersHandler(client *tg.Client) *FoldersHandler {
+	return &FoldersHandler{client: client}
+}
+
+// Resource returns the MCP resource definition
+func (h *FoldersHandler) Resource() mcp.Resource {
+	return mcp.NewResource(
+		"telegram://folders",
+		"Chat Folders",
+		mcp.WithResourceDescription("Chat folders with their IDs, titles, chat categories and included, excluded and pinned chat counts; pass an ID as folder_id to GetChats"),
+		mcp.WithMIMEType("application/json"),
+	)
+}
+
+// Handle processes the telegram://folders resource request
+func (h *FoldersHandler) Handle(ctx context.Context, _ mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+	folders, err := tgdata.GetFolders(ctx, h.client)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := json.MarshalIndent(folders, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("marshaling folders: %w", err)
+	}
+
+	return []mcp.ResourceContents{
+		mcp.TextResourceContents{
+			URI:      "telegram://folders",
+			MIMEType: "application/json",
+			Text:     string(data),
+		},
+	}, nil
+}
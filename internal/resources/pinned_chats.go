@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"strings"
 
 	"github.com/gotd/td/tg"
 	"github.com/mark3labs/mcp-go/mcp"
@@ -19,22 +20,26 @@ type PinnedChatsProvider struct {
 	client      *tg.Client
 	provider    *messages.Provider
 	server      *server.MCPServer
+	scope       tgdata.PinnedScope
 	currentURIs []string           // track current pinned resource URIs for cleanup
 	sfGroup     singleflight.Group // deduplicates concurrent refresh calls
 }
 
 // PinnedChatResource represents a pinned chat resource content
 type PinnedChatResource struct {
-	Chat     tgdata.ChatInfo    `json:"chat"`
-	Messages []messages.Message `json:"messages"`
+	Chat     tgdata.ChatInfo     `json:"chat"`
+	Pins     []tgdata.PinnedChat `json:"pins"` // Containers the chat is pinned in
+	Messages []messages.Message  `json:"messages"`
 }
 
-// NewPinnedChatsProvider creates a new PinnedChatsProvider
-func NewPinnedChatsProvider(client *tg.Client, provider *messages.Provider, srv *server.MCPServer) *PinnedChatsProvider {
+// NewPinnedChatsProvider creates a new PinnedChatsProvider.
+// scope limits the exposed pins, e.g. to the main chat list.
+func NewPinnedChatsProvider(client *tg.Client, provider *messages.Provider, srv *server.MCPServer, scope tgdata.PinnedScope) *PinnedChatsProvider {
 	return &PinnedChatsProvider{
 		client:   client,
 		provider: provider,
 		server:   srv,
+		scope:    scope,
 	}
 }
 
@@ -51,7 +56,7 @@ func (p *PinnedChatsProvider) RefreshResources(ctx context.Context) error {
 }
 
 func (p *PinnedChatsProvider) doRefresh(ctx context.Context) error {
-	chats, err := tgdata.GetPinnedChats(ctx, p.client)
+	pins, err := tgdata.GetPinnedChats(ctx, p.client, p.scope)
 	if err != nil {
 		return fmt.Errorf("getting pinned chats: %w", err)
 	}
@@ -65,20 +70,20 @@ func (p *PinnedChatsProvider) doRefresh(ctx context.Context) error {
 	var pinnedResources []server.ServerResource
 	var newURIs []string
 
-	for _, chat := range chats {
+	for _, chatPins := range groupPins(pins) {
+		chat := chatPins[0].ChatInfo
 		uri := fmt.Sprintf("telegram://chats/%d", chat.ID)
-		chatCopy := chat // capture for closure
 		newURIs = append(newURIs, uri)
 
 		pinnedResources = append(pinnedResources, server.ServerResource{
 			Resource: mcp.NewResource(
 				uri,
-				fmt.Sprintf("Messages from %s", chat.Name),
+				pinnedResourceName(chatPins),
 				mcp.WithResourceDescription(fmt.Sprintf("Last 100 messages from chat: %s (%s)", chat.Name, chat.Type)),
 				mcp.WithMIMEType("application/json"),
 			),
 			Handler: func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
-				return p.handlePinnedChat(ctx, request, chatCopy)
+				return p.handlePinnedChat(ctx, request, chat, chatPins)
 			},
 		})
 	}
@@ -88,14 +93,43 @@ func (p *PinnedChatsProvider) doRefresh(ctx context.Context) error {
 	return nil
 }
 
+// groupPins groups pins by chat, keeping the order of each chat's first pin,
+// so a chat pinned in several containers gets a single resource.
+func groupPins(pins []tgdata.PinnedChat) [][]tgdata.PinnedChat {
+	var groups [][]tgdata.PinnedChat
+	index := make(map[int64]int)
+	for _, pin := range pins {
+		i, ok := index[pin.ID]
+		if !ok {
+			i = len(groups)
+			index[pin.ID] = i
+			groups = append(groups, nil)
+		}
+		groups[i] = append(groups[i], pin)
+	}
+	return groups
+}
+
+// pinnedResourceName names a pinned chat resource after its containers,
+// e.g. "Pinned in Work: Standup".
+func pinnedResourceName(pins []tgdata.PinnedChat) string {
+	containers := make([]string, len(pins))
+	for i, pin := range pins {
+		containers[i] = pin.ContainerName()
+	}
+	return fmt.Sprintf("Pinned in %s: %s", strings.Join(containers, ", "), pins[0].Name)
+}
+
 // handlePinnedChat fetches the last 100 messages for a pinned chat
 func (p *PinnedChatsProvider) handlePinnedChat(
 	ctx context.Context,
 	request mcp.ReadResourceRequest,
 	chat tgdata.ChatInfo,
+	pins []tgdata.PinnedChat,
 ) ([]mcp.ResourceContents, error) {
-	opts := messages.FetchOptions{
-		Limit: 100,
+	opts, err := messages.ParseFetchParams(map[string]any{"limit": messages.MaxFetchLimit})
+	if err != nil {
+		return nil, fmt.Errorf("building fetch options: %w", err)
 	}
 
 	lastMessages, err := p.provider.Fetch(ctx, chat.ID, opts)
@@ -105,6 +139,7 @@ func (p *PinnedChatsProvider) handlePinnedChat(
 
 	result := PinnedChatResource{
 		Chat:     chat,
+		Pins:     pins,
 		Messages: lastMessages.Messages,
 	}
 
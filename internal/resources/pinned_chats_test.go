@@ -0,0 +1,33 @@
+package resources
+
+import (
+	"testing"
+
+	"github.com/tolmachov/mcp-telegram/internal/tgdata"
+)
+
+func TestPinnedResourceNames(t *testing.T) {
+	standup := tgdata.ChatInfo{ID: 1, Name: "Standup"}
+	family := tgdata.ChatInfo{ID: 3, Name: "Family"}
+	pins := []tgdata.PinnedChat{
+		{ChatInfo: family, Container: tgdata.PinnedInMain, PinOrder: 1},
+		{ChatInfo: standup, Container: tgdata.PinnedInArchive, PinOrder: 1},
+		{ChatInfo: family, Container: "Home", FolderID: 7, PinOrder: 1},
+		{ChatInfo: standup, Container: "Work", FolderID: 8, PinOrder: 1},
+	}
+
+	var names []string
+	for _, group := range groupPins(pins) {
+		names = append(names, pinnedResourceName(group))
+	}
+
+	want := []string{"Pinned in main list, Home: Family", "Pinned in archive, Work: Standup"}
+	if len(names) != len(want) {
+		t.Fatalf("got names %q, want %q", names, want)
+	}
+	for i := range want {
+		if names[i] != want[i] {
+			t.Errorf("name %d = %q, want %q", i, names[i], want[i])
+		}
+	}
+}
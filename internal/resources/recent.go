@@ -0,0 +1,47 @@
+package resources
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/tolmachov/mcp-telegram/internal/recent"
+)
+
+// RecentHandler handles the telegram://recent resource
+type RecentHandler struct {
+	tracker *recent.Tracker
+}
+
+// NewRecentHandler creates a new RecentHandler
+func NewRecentHandler(tracker *recent.Tracker) *RecentHandler {
+	return &RecentHandler{tracker: tracker}
+}
+
+// Resource returns the MCP resource definition
+func (h *RecentHandler) Resource() mcp.Resource {
+	return mcp.NewResource(
+		"telegram://recent",
+		"Recent Chats",
+		mcp.WithResourceDescription("Chats recently used by tools in this session, most recent first"),
+		mcp.WithMIMEType("application/json"),
+	)
+}
+
+// Handle processes the telegram://recent resource request
+func (h *RecentHandler) Handle(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+	data, err := json.MarshalIndent(h.tracker.List(ctx), "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("marshaling recent chats: %w", err)
+	}
+
+	return []mcp.ResourceContents{
+		mcp.TextResourceContents{
+			URI:      request.Params.URI,
+			MIMEType: "application/json",
+			Text:     string(data),
+		},
+	}, nil
+}
@@ -2,9 +2,12 @@ package resources
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/tolmachov/mcp-telegram/internal/tgclient"
 )
 
 // ResourceHandler defines the interface for static resource handlers
@@ -13,9 +16,35 @@ type ResourceHandler interface {
 	Handle(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error)
 }
 
+// ResourceTemplateHandler defines the interface for parameterized resource handlers
+type ResourceTemplateHandler interface {
+	Template() mcp.ResourceTemplate
+	Handle(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error)
+}
+
 // RegisterResources registers all resource handlers with the MCP server
 func RegisterResources(s *server.MCPServer, handlers []ResourceHandler) {
 	for _, r := range handlers {
 		s.AddResource(r.Resource(), r.Handle)
 	}
 }
+
+// RegisterResourceTemplates registers all resource template handlers with the MCP server
+func RegisterResourceTemplates(s *server.MCPServer, handlers []ResourceTemplateHandler) {
+	for _, r := range handlers {
+		s.AddResourceTemplate(r.Template(), r.Handle)
+	}
+}
+
+// ConnectionMiddleware makes resource reads wait for the Telegram connection made in
+// the background, failing with the reason when it isn't usable.
+func ConnectionMiddleware(status *tgclient.Status) server.ResourceHandlerMiddleware {
+	return func(next server.ResourceHandlerFunc) server.ResourceHandlerFunc {
+		return func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+			if err := status.Wait(ctx); err != nil {
+				return nil, fmt.Errorf("telegram is not connected: %w", err)
+			}
+			return next(ctx, request)
+		}
+	}
+}
@@ -0,0 +1,97 @@
+// Package semantic provides a small embedding index over backed-up chat messages
+// with brute-force cosine similarity search.
+package semantic
+
+import (
+	"strings"
+	"time"
+
+	"github.com/tolmachov/mcp-telegram/internal/messages"
+)
+
+// DefaultChunkRunes is the default maximum size of a chunk's text.
+const DefaultChunkRunes = 500
+
+// Chunk is a run of consecutive messages embedded as a single vector.
+type Chunk struct {
+	FirstID int
+	LastID  int
+	Start   time.Time
+	End     time.Time
+	Text    string
+	Vector  []float32
+}
+
+// ChunkMessages groups consecutive messages into chunks of at most maxRunes runes of
+// text, one "sender: text" line per message. A message longer than maxRunes is split
+// across several chunks of its own. Messages without text are skipped.
+func ChunkMessages(msgs []messages.Message, maxRunes int) []Chunk {
+	if maxRunes <= 0 {
+		maxRunes = DefaultChunkRunes
+	}
+
+	var chunks []Chunk
+	var current *Chunk
+	var currentRunes int
+
+	flush := func() {
+		if current != nil {
+			chunks = append(chunks, *current)
+		}
+		current = nil
+		currentRunes = 0
+	}
+
+	for _, msg := range msgs {
+		text := strings.TrimSpace(msg.Text)
+		if text == "" {
+			continue
+		}
+		line := text
+		if msg.SenderName != "" {
+			line = msg.SenderName + ": " + text
+		}
+		lineRunes := len([]rune(line))
+
+		if lineRunes > maxRunes {
+			flush()
+			for _, part := range splitRunes(line, maxRunes) {
+				chunks = append(chunks, Chunk{
+					FirstID: msg.ID,
+					LastID:  msg.ID,
+					Start:   msg.Date,
+					End:     msg.Date,
+					Text:    part,
+				})
+			}
+			continue
+		}
+
+		// +1 for the newline joining lines within a chunk
+		if current != nil && currentRunes+1+lineRunes > maxRunes {
+			flush()
+		}
+		if current == nil {
+			current = &Chunk{FirstID: msg.ID, Start: msg.Date, Text: line}
+			currentRunes = lineRunes
+		} else {
+			current.Text += "\n" + line
+			currentRunes += 1 + lineRunes
+		}
+		current.LastID = msg.ID
+		current.End = msg.Date
+	}
+	flush()
+
+	return chunks
+}
+
+// splitRunes splits s into consecutive parts of at most n runes.
+func splitRunes(s string, n int) []string {
+	runes := []rune(s)
+	parts := make([]string, 0, (len(runes)+n-1)/n)
+	for start := 0; start < len(runes); start += n {
+		parts = append(parts, string(runes[start:min(start+n, len(runes))]))
+	}
+	return parts
+}
@@ -0,0 +1,76 @@
+package semantic
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tolmachov/mcp-telegram/internal/messages"
+)
+
+func TestChunkMessages(t *testing.T) {
+	base := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	msg := func(id int, sender, text string) messages.Message {
+		return messages.Message{ID: id, SenderName: sender, Text: text, Date: base.Add(time.Duration(id) * time.Minute)}
+	}
+
+	t.Run("groups consecutive messages up to the limit", func(t *testing.T) {
+		msgs := []messages.Message{
+			msg(1, "Anna", "hi"),         // "Anna: hi" = 8 runes
+			msg(2, "Bob", "hello"),       // "Bob: hello" = 10 runes
+			msg(3, "Anna", "flat?"),      // "Anna: flat?" = 11 runes
+			msg(4, "", "no sender"),      // 9 runes
+			msg(5, "Bob", "   "),         // skipped
+			msg(6, "Bob", "квартира ок"), // "Bob: квартира ок" = 16 runes
+		}
+		chunks := ChunkMessages(msgs, 31)
+
+		want := []struct {
+			first, last int
+			text        string
+		}{
+			{1, 3, "Anna: hi\nBob: hello\nAnna: flat?"},
+			{4, 6, "no sender\nBob: квартира ок"},
+		}
+		if len(chunks) != len(want) {
+			t.Fatalf("got %d chunks, want %d: %+v", len(chunks), len(want), chunks)
+		}
+		for i, w := range want {
+			c := chunks[i]
+			if c.FirstID != w.first || c.LastID != w.last || c.Text != w.text {
+				t.Errorf("chunk %d = {%d %d %q}, want {%d %d %q}", i, c.FirstID, c.LastID, c.Text, w.first, w.last, w.text)
+			}
+			if !c.Start.Equal(base.Add(time.Duration(w.first)*time.Minute)) || !c.End.Equal(base.Add(time.Duration(w.last)*time.Minute)) {
+				t.Errorf("chunk %d dates = %v..%v", i, c.Start, c.End)
+			}
+		}
+	})
+
+	t.Run("splits long messages", func(t *testing.T) {
+		long := strings.Repeat("я", 25)
+		chunks := ChunkMessages([]messages.Message{msg(1, "A", "short"), msg(2, "", long), msg(3, "B", "after")}, 10)
+
+		var texts []string
+		for _, c := range chunks {
+			texts = append(texts, c.Text)
+			if n := len([]rune(c.Text)); n > 10 {
+				t.Errorf("chunk %q has %d runes, limit is 10", c.Text, n)
+			}
+		}
+		want := []string{"A: short", strings.Repeat("я", 10), strings.Repeat("я", 10), strings.Repeat("я", 5), "B: after"}
+		if strings.Join(texts, "|") != strings.Join(want, "|") {
+			t.Errorf("got %q, want %q", texts, want)
+		}
+		for _, c := range chunks[1:4] {
+			if c.FirstID != 2 || c.LastID != 2 {
+				t.Errorf("split chunk IDs = %d..%d, want 2..2", c.FirstID, c.LastID)
+			}
+		}
+	})
+
+	t.Run("empty input", func(t *testing.T) {
+		if chunks := ChunkMessages(nil, 0); len(chunks) != 0 {
+			t.Errorf("got %d chunks, want 0", len(chunks))
+		}
+	})
+}
@@ -0,0 +1,141 @@
+package semantic
+
+import (
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// IndexVersion is the version of the index file format.
+const IndexVersion = 1
+
+// IndexSuffix is appended to a backup's path to name its index file.
+const IndexSuffix = ".index.gob"
+
+// ErrDimensionMismatch is returned when a query vector doesn't match the index.
+var ErrDimensionMismatch = errors.New("query embedding dimension does not match the index")
+
+// Index holds the embedded chunks of one backup file.
+type Index struct {
+	Version  int
+	Source   string    // absolute path of the indexed backup
+	SourceAt time.Time // modification time of the backup when it was indexed
+	Model    string    // embedding provider and model, e.g. "ollama/nomic-embed-text"
+	Created  time.Time
+	Dim      int
+	Chunks   []Chunk
+}
+
+// Result is a chunk matching a query.
+type Result struct {
+	Chunk Chunk
+	Score float64
+}
+
+// IndexPath returns the default index file path for a backup file.
+func IndexPath(backupPath string) string {
+	return backupPath + IndexSuffix
+}
+
+// NewIndex builds an index from chunks whose vectors are already set.
+// All vectors must have the same non-zero dimension.
+func NewIndex(source string, sourceAt time.Time, model string, chunks []Chunk) (*Index, error) {
+	idx := &Index{
+		Version:  IndexVersion,
+		Source:   source,
+		SourceAt: sourceAt,
+		Model:    model,
+		Created:  time.Now(),
+		Chunks:   chunks,
+	}
+	for i, c := range chunks {
+		if len(c.Vector) == 0 {
+			return nil, fmt.Errorf("chunk %d has no embedding", i)
+		}
+		if idx.Dim == 0 {
+			idx.Dim = len(c.Vector)
+		} else if len(c.Vector) != idx.Dim {
+			return nil, fmt.Errorf("chunk %d has dimension %d, expected %d", i, len(c.Vector), idx.Dim)
+		}
+	}
+	return idx, nil
+}
+
+// Write encodes the index to w.
+func (idx *Index) Write(w io.Writer) error {
+	if err := gob.NewEncoder(w).Encode(idx); err != nil {
+		return fmt.Errorf("encoding index: %w", err)
+	}
+	return nil
+}
+
+// ReadIndex decodes an index from r and checks its version.
+func ReadIndex(r io.Reader) (*Index, error) {
+	var idx Index
+	if err := gob.NewDecoder(r).Decode(&idx); err != nil {
+		return nil, fmt.Errorf("decoding index: %w", err)
+	}
+	if idx.Version != IndexVersion {
+		return nil, fmt.Errorf("unsupported index version %d (expected %d), rebuild the index", idx.Version, IndexVersion)
+	}
+	return &idx, nil
+}
+
+// Save writes the index to path, replacing any existing file atomically.
+func (idx *Index) Save(path string) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("creating temp file: %w", err)
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+
+	if err := idx.Write(tmp); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("closing temp file: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("renaming index file: %w", err)
+	}
+	return nil
+}
+
+// LoadIndex reads an index file.
+func LoadIndex(path string) (*Index, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("opening index: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+	return ReadIndex(f)
+}
+
+// Search returns the k chunks most similar to query, best first.
+func (idx *Index) Search(query []float32, k int) ([]Result, error) {
+	if len(query) != idx.Dim {
+		return nil, fmt.Errorf("%w: got %d, index has %d", ErrDimensionMismatch, len(query), idx.Dim)
+	}
+	if k <= 0 {
+		return nil, nil
+	}
+
+	results := make([]Result, len(idx.Chunks))
+	for i, c := range idx.Chunks {
+		results[i] = Result{Chunk: c, Score: Cosine(query, c.Vector)}
+	}
+	// Stable so equal scores keep chronological order
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].Score > results[j].Score
+	})
+	if len(results) > k {
+		results = results[:k]
+	}
+	return results, nil
+}
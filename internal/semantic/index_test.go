@@ -0,0 +1,134 @@
+package semantic
+
+import (
+	"bytes"
+	"encoding/gob"
+	"errors"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func testIndex(t *testing.T) *Index {
+	t.Helper()
+	at := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	idx, err := NewIndex("/backups/chat.txt", at, "ollama/nomic-embed-text", []Chunk{
+		{FirstID: 1, LastID: 2, Start: at, End: at.Add(time.Minute), Text: "rent a flat", Vector: []float32{1, 0, 0}},
+		{FirstID: 3, LastID: 3, Start: at.Add(2 * time.Minute), End: at.Add(2 * time.Minute), Text: "weather", Vector: []float32{0, 1, 0}},
+		{FirstID: 4, LastID: 5, Start: at.Add(3 * time.Minute), End: at.Add(4 * time.Minute), Text: "housing", Vector: []float32{0.9, 0.1, 0}},
+	})
+	if err != nil {
+		t.Fatalf("NewIndex: %v", err)
+	}
+	return idx
+}
+
+func TestNewIndexValidatesVectors(t *testing.T) {
+	if _, err := NewIndex("x", time.Time{}, "m", []Chunk{{Vector: []float32{1, 2}}, {Vector: []float32{1}}}); err == nil {
+		t.Error("expected error for mismatched dimensions")
+	}
+	if _, err := NewIndex("x", time.Time{}, "m", []Chunk{{Text: "no vector"}}); err == nil {
+		t.Error("expected error for a chunk without a vector")
+	}
+}
+
+func TestIndexRoundTrip(t *testing.T) {
+	idx := testIndex(t)
+
+	var buf bytes.Buffer
+	if err := idx.Write(&buf); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	got, err := ReadIndex(&buf)
+	if err != nil {
+		t.Fatalf("ReadIndex: %v", err)
+	}
+
+	if got.Version != IndexVersion || got.Dim != 3 || got.Model != idx.Model || got.Source != idx.Source {
+		t.Errorf("header = %+v", got)
+	}
+	if !got.SourceAt.Equal(idx.SourceAt) {
+		t.Errorf("SourceAt = %v, want %v", got.SourceAt, idx.SourceAt)
+	}
+	if len(got.Chunks) != len(idx.Chunks) {
+		t.Fatalf("got %d chunks, want %d", len(got.Chunks), len(idx.Chunks))
+	}
+	for i := range idx.Chunks {
+		w, g := idx.Chunks[i], got.Chunks[i]
+		if g.FirstID != w.FirstID || g.LastID != w.LastID || g.Text != w.Text ||
+			!g.Start.Equal(w.Start) || !g.End.Equal(w.End) || !reflect.DeepEqual(g.Vector, w.Vector) {
+			t.Errorf("chunk %d = %+v, want %+v", i, g, w)
+		}
+	}
+}
+
+func TestIndexSaveLoad(t *testing.T) {
+	idx := testIndex(t)
+	path := filepath.Join(t.TempDir(), "chat.txt"+IndexSuffix)
+
+	if err := idx.Save(path); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	// Saving again replaces the file
+	if err := idx.Save(path); err != nil {
+		t.Fatalf("second Save: %v", err)
+	}
+	got, err := LoadIndex(path)
+	if err != nil {
+		t.Fatalf("LoadIndex: %v", err)
+	}
+	if len(got.Chunks) != 3 {
+		t.Errorf("got %d chunks, want 3", len(got.Chunks))
+	}
+}
+
+func TestReadIndexRejectsOtherVersions(t *testing.T) {
+	idx := testIndex(t)
+	idx.Version = IndexVersion + 1
+
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(idx); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ReadIndex(&buf); err == nil {
+		t.Error("expected error for unsupported version")
+	}
+	if _, err := ReadIndex(bytes.NewReader([]byte("not gob"))); err == nil {
+		t.Error("expected error for garbage input")
+	}
+}
+
+func TestIndexSearch(t *testing.T) {
+	idx := testIndex(t)
+
+	results, err := idx.Search([]float32{1, 0, 0}, 2)
+	if err != nil {
+		t.Fatalf("Search: %v", err)
+	}
+	var ids []int
+	for _, r := range results {
+		ids = append(ids, r.Chunk.FirstID)
+	}
+	if !reflect.DeepEqual(ids, []int{1, 4}) {
+		t.Errorf("got chunks %v, want [1 4]", ids)
+	}
+	if results[0].Score < results[1].Score {
+		t.Errorf("results not sorted by score: %v", results)
+	}
+
+	all, err := idx.Search([]float32{1, 0, 0}, 10)
+	if err != nil || len(all) != 3 {
+		t.Errorf("Search with k > chunks: got %d results, err %v", len(all), err)
+	}
+
+	if _, err := idx.Search([]float32{1, 0}, 2); !errors.Is(err, ErrDimensionMismatch) {
+		t.Errorf("expected ErrDimensionMismatch, got %v", err)
+	}
+}
+
+func TestIndexPath(t *testing.T) {
+	if got := IndexPath("/b/chat.txt"); got != "/b/chat.txt.index.gob" {
+		t.Errorf("IndexPath() = %q", got)
+	}
+}
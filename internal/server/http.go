@@ -0,0 +1,110 @@
+package server
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// Transports the server can talk to MCP clients over.
+const (
+	// TransportStdio serves a single client over stdin and stdout.
+	TransportStdio = "stdio"
+	// TransportHTTP serves any number of clients over streamable HTTP.
+	TransportHTTP = "http"
+)
+
+const (
+	// DefaultListenAddr is where TransportHTTP listens unless configured otherwise.
+	DefaultListenAddr = "127.0.0.1:8080"
+	// HTTPEndpoint is the path MCP clients connect to with TransportHTTP.
+	HTTPEndpoint = "/mcp"
+	// httpShutdownTimeout bounds how long open requests may finish on shutdown.
+	httpShutdownTimeout = 10 * time.Second
+)
+
+// TransportConfig selects how the server talks to MCP clients.
+type TransportConfig struct {
+	Kind       string // TransportStdio or TransportHTTP
+	ListenAddr string // Address TransportHTTP listens on
+	Token      string // Bearer token TransportHTTP requires on every request, if set
+}
+
+// ValidateTransport checks that a transport name is supported.
+func ValidateTransport(kind string) error {
+	if kind != TransportStdio && kind != TransportHTTP {
+		return fmt.Errorf("invalid transport %q: must be %q or %q", kind, TransportStdio, TransportHTTP)
+	}
+	return nil
+}
+
+// serveHTTP serves mcpServer over streamable HTTP at HTTPEndpoint until ctx is done,
+// then lets open requests finish before returning.
+func serveHTTP(ctx context.Context, mcpServer *server.MCPServer, cfg TransportConfig, errLogger *log.Logger) error {
+	listener, err := net.Listen("tcp", cfg.ListenAddr)
+	if err != nil {
+		return fmt.Errorf("listening on %s: %w", cfg.ListenAddr, err)
+	}
+	return serveHTTPListener(ctx, listener, mcpServer, cfg.Token, errLogger)
+}
+
+func serveHTTPListener(ctx context.Context, listener net.Listener, mcpServer *server.MCPServer, token string, errLogger *log.Logger) error {
+	mux := http.NewServeMux()
+	mux.Handle(HTTPEndpoint, requireBearerToken(token, server.NewStreamableHTTPServer(mcpServer)))
+	httpServer := &http.Server{
+		Handler:           mux,
+		ErrorLog:          errLogger,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	if token == "" {
+		errLogger.Printf("Warning: serving HTTP on %s without a bearer token", listener.Addr())
+	}
+	errLogger.Printf("Serving MCP over HTTP at http://%s%s", listener.Addr(), HTTPEndpoint)
+
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- httpServer.Serve(listener)
+	}()
+
+	select {
+	case err := <-serveErr:
+		return fmt.Errorf("serving HTTP: %w", err)
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), httpShutdownTimeout)
+	defer cancel()
+	if err := httpServer.Shutdown(shutdownCtx); err != nil {
+		return fmt.Errorf("shutting down HTTP server: %w", err)
+	}
+	if err := <-serveErr; !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("serving HTTP: %w", err)
+	}
+	return nil
+}
+
+// requireBearerToken rejects requests without "Authorization: Bearer <token>". An
+// empty token lets every request through.
+func requireBearerToken(token string, next http.Handler) http.Handler {
+	if token == "" {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(got), []byte(token)) != 1 {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="mcp-telegram"`)
+			http.Error(w, "invalid or missing bearer token", http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,115 @@
+package server
+
+import (
+	"context"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/client"
+	"github.com/mark3labs/mcp-go/client/transport"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// startHTTP serves a server with a single GetMe tool over HTTP, returning its URL and
+// a function stopping it and returning what serving returned.
+func startHTTP(t *testing.T, token string) (string, func() error) {
+	t.Helper()
+	mcpServer := server.NewMCPServer("mcp-telegram", "test", server.WithToolCapabilities(true))
+	mcpServer.AddTool(mcp.NewTool("GetMe"), func(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultText("me"), nil
+	})
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- serveHTTPListener(ctx, listener, mcpServer, token, log.New(io.Discard, "", 0))
+	}()
+
+	stop := func() error {
+		cancel()
+		select {
+		case err := <-done:
+			return err
+		case <-time.After(5 * time.Second):
+			t.Fatal("HTTP server did not stop in time")
+			return nil
+		}
+	}
+	return "http://" + listener.Addr().String() + HTTPEndpoint, stop
+}
+
+func connect(t *testing.T, url string, headers map[string]string) (*client.Client, error) {
+	t.Helper()
+	c, err := client.NewStreamableHttpClient(url, transport.WithHTTPHeaders(headers))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = c.Close() })
+
+	var init mcp.InitializeRequest
+	init.Params.ProtocolVersion = mcp.LATEST_PROTOCOL_VERSION
+	init.Params.ClientInfo = mcp.Implementation{Name: "test", Version: "1.0.0"}
+	_, err = c.Initialize(context.Background(), init)
+	return c, err
+}
+
+func TestServeHTTPListTools(t *testing.T) {
+	url, stop := startHTTP(t, "secret")
+
+	c, err := connect(t, url, map[string]string{"Authorization": "Bearer secret"})
+	if err != nil {
+		t.Fatalf("Initialize: %v", err)
+	}
+	tools, err := c.ListTools(context.Background(), mcp.ListToolsRequest{})
+	if err != nil {
+		t.Fatalf("ListTools: %v", err)
+	}
+	if len(tools.Tools) != 1 || tools.Tools[0].Name != "GetMe" {
+		t.Errorf("tools = %+v, want GetMe", tools.Tools)
+	}
+
+	if err := stop(); err != nil {
+		t.Errorf("shutdown: %v", err)
+	}
+}
+
+func TestServeHTTPRequiresToken(t *testing.T) {
+	url, stop := startHTTP(t, "secret")
+	defer func() { _ = stop() }()
+
+	for _, headers := range []map[string]string{nil, {"Authorization": "Bearer wrong"}} {
+		if _, err := connect(t, url, headers); err == nil {
+			t.Errorf("Initialize with headers %v succeeded, want unauthorized", headers)
+		}
+	}
+
+	resp, err := http.Post(url, "application/json", strings.NewReader(`{"jsonrpc":"2.0","id":1,"method":"ping"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("status = %d, want %d", resp.StatusCode, http.StatusUnauthorized)
+	}
+}
+
+func TestValidateTransport(t *testing.T) {
+	for _, kind := range []string{TransportStdio, TransportHTTP} {
+		if err := ValidateTransport(kind); err != nil {
+			t.Errorf("ValidateTransport(%q): %v", kind, err)
+		}
+	}
+	if err := ValidateTransport("sse"); err == nil {
+		t.Error("ValidateTransport accepted an unknown transport")
+	}
+}
@@ -6,119 +6,311 @@ import (
 	"io"
 	"log"
 
+	"github.com/gotd/td/telegram/updates"
+	"github.com/gotd/td/tg"
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
 
+	"github.com/tolmachov/mcp-telegram/internal/autoreply"
+	"github.com/tolmachov/mcp-telegram/internal/conditional"
 	"github.com/tolmachov/mcp-telegram/internal/messages"
+	"github.com/tolmachov/mcp-telegram/internal/recent"
 	"github.com/tolmachov/mcp-telegram/internal/resources"
 	"github.com/tolmachov/mcp-telegram/internal/summarize"
+	"github.com/tolmachov/mcp-telegram/internal/templates"
 	"github.com/tolmachov/mcp-telegram/internal/tgclient"
+	"github.com/tolmachov/mcp-telegram/internal/tgdata"
 	"github.com/tolmachov/mcp-telegram/internal/tools"
+	"github.com/tolmachov/mcp-telegram/internal/watch"
 )
 
 // Server represents the MCP server for Telegram
 type Server struct {
-	mcpServer    *server.MCPServer
-	hooks        *server.Hooks
-	tgConfig     *tgclient.Config
-	allowedPaths []string
-	summarizeCfg summarize.Config
-	stdin        io.Reader
-	stdout       io.Writer
-	errOut       io.Writer
+	mcpServer *server.MCPServer
+	hooks     *server.Hooks
+	tgConfig  *tgclient.Config
+	opts      Options
+	status    *tgclient.Status
+}
+
+// Options configures a Server beyond its Telegram connection.
+type Options struct {
+	Version              string             // Reported to MCP clients
+	AllowedPaths         []string           // Directories tools may read files from and write them to
+	MaxBackupsPerChat    int                // Auto-named backups kept per chat, 0 for all
+	ChatsIncludeArchived bool               // The telegram://chats resource lists archived chats too
+	ChatsPaginateBlocks  bool               // The telegram://chats resource is split into bounded contents
+	PinnedScope          tgdata.PinnedScope // Which pinned chats are exposed as resources
+	Templates            *templates.Store   // Message templates, already loaded
+	Summarize            summarize.Config   // Summarization, translation and transcription
+	ReadOnly             bool               // Leave out the tools writing to Telegram
+	EnabledTools         []string           // Only these tools, if set
+	DisabledTools        []string           // Leave out these tools
+	ConfirmDestructive   bool               // Ask the user before tools that change something
+	RPS                  int                // Telegram requests per second of all tools, resources and updates, 0 for no limit
+	Transport            TransportConfig    // How MCP clients connect
+	Stdin                io.Reader          // Stdio transport input
+	Stdout               io.Writer          // Stdio transport output
+	ErrOut               io.Writer          // Logs
 }
 
 // New creates a new MCP server
-func New(cfg *tgclient.Config, version string, allowedPaths []string, summarizeCfg summarize.Config, stdin io.Reader, stdout, errOut io.Writer) (*Server, error) {
+func New(cfg *tgclient.Config, opts Options) (*Server, error) {
 	hooks := &server.Hooks{}
+	status := tgclient.NewStatus()
+	if opts.ReadOnly {
+		hooks.AddOnRequestInitialization(tools.ReadOnlyHook)
+	}
 
-	mcpServer := server.NewMCPServer(
-		"mcp-telegram",
-		version,
+	serverOpts := []server.ServerOption{
 		server.WithToolCapabilities(true),
 		server.WithResourceCapabilities(true, true),
 		server.WithHooks(hooks),
-	)
+		server.WithResourceHandlerMiddleware(resources.ConnectionMiddleware(status)),
+	}
+	if opts.ConfirmDestructive {
+		serverOpts = append(serverOpts, server.WithElicitation())
+	}
+	mcpServer := server.NewMCPServer("mcp-telegram", opts.Version, serverOpts...)
 
 	// Enable sampling capability for LLM requests
 	mcpServer.EnableSampling()
 
 	return &Server{
-		mcpServer:    mcpServer,
-		hooks:        hooks,
-		tgConfig:     cfg,
-		allowedPaths: allowedPaths,
-		summarizeCfg: summarizeCfg,
-		stdin:        stdin,
-		stdout:       stdout,
-		errOut:       errOut,
+		mcpServer: mcpServer,
+		hooks:     hooks,
+		tgConfig:  cfg,
+		opts:      opts,
+		status:    status,
 	}, nil
 }
 
-// Run starts the MCP server over stdio
+// Run starts the MCP server over stdio or, with TransportHTTP, over HTTP
 func (s *Server) Run(ctx context.Context) error {
-	// Create a Telegram client with flood wait handling
-	client, waiter := tgclient.CreateClient(s.tgConfig)
-
-	// waiter.Run wraps a client.Run to handle FLOOD_WAIT errors automatically
-	err := waiter.Run(ctx, func(ctx context.Context) error {
-		return client.Run(ctx, func(ctx context.Context) error {
-			// Check if authorized
-			status, err := client.Auth().Status(ctx)
-			if err != nil {
-				return fmt.Errorf("checking auth status: %w", err)
-			}
-
-			if !status.Authorized {
-				return fmt.Errorf("not authorized, please run 'login' command first")
-			}
-
-			// Create a shared message provider with rate limiting
-			msgProvider := messages.NewProvider(client.API())
-
-			tools.RegisterTools(s.mcpServer, []tools.Handler{
-				tools.NewMeGetHandler(client.API()),
-				tools.NewChatsGetHandler(client.API()),
-				tools.NewChatsSearchHandler(client.API()),
-				tools.NewChatInfoGetHandler(client.API()),
-				tools.NewMessagesGetHandler(msgProvider),
-				tools.NewMessageDraftHandler(client.API()),
-				tools.NewMessageSendHandler(client.API()),
-				tools.NewMessageReadHandler(client.API()),
-				tools.NewMessageEditHandler(client.API()),
-				tools.NewMessageDeleteHandler(client.API()),
-				tools.NewMessageReplyHandler(client.API()),
-				tools.NewMessageForwardHandler(client.API()),
-				tools.NewMessageScheduleHandler(client.API()),
-				tools.NewScheduledGetHandler(client.API()),
-				tools.NewScheduledDeleteHandler(client.API()),
-				tools.NewUsernameResolveHandler(client.API()),
-				tools.NewMessageBackupHandler(client.API(), msgProvider, s.allowedPaths),
-				tools.NewChatMuteHandler(client.API()),
-				tools.NewChatUnmuteHandler(client.API()),
-				tools.NewChatSummarizeHandler(msgProvider, s.mcpServer, s.summarizeCfg),
-				tools.NewMediaGetHandler(client.API()),
-			})
+	autoReplies, err := autoreply.OpenStore(autoreply.DefaultStatePath())
+	if err != nil {
+		return err
+	}
+	conditionals, err := conditional.OpenStore(conditional.DefaultStatePath())
+	if err != nil {
+		return err
+	}
+	watches, err := watch.OpenStore(watch.DefaultStatePath())
+	if err != nil {
+		return err
+	}
 
-			resources.RegisterResources(s.mcpServer, []resources.ResourceHandler{
-				resources.NewMeHandler(client.API()),
-				resources.NewChatsHandler(client.API()),
-			})
+	// Create a Telegram client with flood wait handling, dispatching incoming updates;
+	// tool calls waiting out a FLOOD_WAIT tell their client through progress notifications.
+	// Telegram sends most private and basic group messages as short updates, which the
+	// update manager converts to new message updates once running, also fetching the
+	// updates missed in gaps
+	dispatcher := tg.NewUpdateDispatcher()
+	gaps := updates.New(updates.Config{Handler: dispatcher})
+	floodWaits := make(chan tgclient.FloodWait, 16)
+	client, waiter := tgclient.CreateClient(s.tgConfig, gaps, floodWaits)
+	go tools.ReportFloodWaits(ctx, floodWaits)
 
-			// Set up dynamic pinned chat resources
-			pinnedProvider := resources.NewPinnedChatsProvider(client.API(), msgProvider, s.mcpServer)
-			s.hooks.AddBeforeListResources(func(ctx context.Context, id any, req *mcp.ListResourcesRequest) {
-				_ = pinnedProvider.RefreshResources(ctx)
-			})
+	// Create the session's peer cache and a shared message provider; every other
+	// Telegram request goes through its rate limiter too, so parallel tool calls share it
+	peers := tgclient.NewPeerCache(tgclient.DefaultPeerCacheSize)
+	msgProvider := messages.NewProvider(client.API(), peers, tgclient.NewLimiter(s.opts.RPS))
+	api := msgProvider.Paced()
 
-			// Run MCP server over stdio
-			errLogger := log.New(s.errOut, "[mcp-telegram] ", log.LstdFlags)
-			stdioServer := server.NewStdioServer(s.mcpServer)
-			stdioServer.SetErrorLogger(errLogger)
+	// Handlers must be registered before the client starts receiving updates. Both
+	// auto-replies and keyword watch alerts send messages, so read-only mode leaves
+	// them out
+	if !s.opts.ReadOnly {
+		onNewMessages(dispatcher,
+			tools.AutoReplyUpdateHandler(msgProvider, autoReplies),
+			tools.KeywordWatchUpdateHandler(msgProvider, watches))
+	}
 
-			return stdioServer.Listen(ctx, s.stdin, s.stdout)
-		})
+	// Track recently used chats to suggest candidates when a chat can't be found
+	recentChats := recent.NewTracker(recent.DefaultSize, func(ctx context.Context, chatID int64) (string, error) {
+		info, err := tgdata.GetChatInfo(ctx, api, peers, chatID)
+		if err != nil {
+			return "", err
+		}
+		return info.Name, nil
+	})
+
+	handlers := []tools.Handler{
+		tools.NewAuthStatusHandler(s.status),
+		tools.NewMeGetHandler(api),
+		tools.NewChatsGetHandler(api),
+		tools.NewChatsSearchHandler(msgProvider),
+		tools.NewChatInfoGetHandler(api, peers),
+		tools.NewUserInfoGetHandler(api, peers),
+		tools.NewChatMembersGetHandler(msgProvider),
+		tools.NewAdminLogGetHandler(api, peers),
+		tools.NewGroupCallGetHandler(msgProvider),
+		tools.NewChatContextGetHandler(msgProvider),
+		tools.NewMessagesGetHandler(api, msgProvider),
+		tools.NewMessagesSearchHandler(msgProvider),
+		tools.NewMessageContextHandler(api, msgProvider),
+		tools.NewMessageRepliesHandler(api, msgProvider),
+		tools.NewForumTopicsGetHandler(api, peers),
+		tools.NewMessageDraftHandler(api, peers),
+		tools.NewMessageSendHandler(api, peers, recentChats.Name),
+		tools.NewFileSendHandler(api, peers, s.opts.AllowedPaths, recentChats.Name),
+		tools.NewCanSendHandler(msgProvider),
+		tools.NewTemplatesListHandler(s.opts.Templates),
+		tools.NewTemplateSendHandler(api, peers, s.opts.Templates),
+		tools.NewTemplatesReloadHandler(s.opts.Templates),
+		tools.NewMessageReadHandler(msgProvider),
+		tools.NewMessageUnreadHandler(msgProvider),
+		tools.NewReactionsGetHandler(api, peers),
+		tools.NewMentionsGetHandler(api, peers),
+		tools.NewMessageReactionsGetHandler(api, peers),
+		tools.NewReactionSendHandler(api, peers),
+		tools.NewPendingRepliesHandler(api, msgProvider),
+		tools.NewMessageEditHandler(api, peers),
+		tools.NewMessageDeleteHandler(api, peers),
+		tools.NewChatLeaveHandler(api, peers),
+		tools.NewDialogDeleteHandler(api, peers),
+		tools.NewChatEditHandler(api, peers, s.opts.AllowedPaths),
+		tools.NewChatJoinHandler(api, peers),
+		tools.NewInviteCheckHandler(api),
+		tools.NewMessageReplyHandler(api, peers, recentChats.Name),
+		tools.NewMessageForwardHandler(api, msgProvider, recentChats.Name),
+		tools.NewButtonClickHandler(api, msgProvider),
+		tools.NewMessageScheduleHandler(api, peers, recentChats.Name),
+		tools.NewConditionalScheduleHandler(msgProvider, conditionals, recentChats.Name),
+		tools.NewConditionalListHandler(conditionals),
+		tools.NewConditionalCancelHandler(conditionals),
+		tools.NewAutoReplySetHandler(autoReplies),
+		tools.NewAutoReplyStatusHandler(autoReplies),
+		tools.NewAutoReplyDisableHandler(autoReplies),
+		tools.NewKeywordWatchAddHandler(watches),
+		tools.NewKeywordWatchListHandler(watches),
+		tools.NewKeywordWatchRemoveHandler(watches),
+		tools.NewScheduledGetHandler(api, peers),
+		tools.NewScheduledEditHandler(api, peers),
+		tools.NewScheduledSendNowHandler(api, peers),
+		tools.NewScheduledDeleteHandler(api, peers),
+		tools.NewUsernameResolveHandler(api),
+		tools.NewWhoIsHandler(api, peers),
+		tools.NewMessageBackupHandler(api, client, msgProvider, s.opts.AllowedPaths, s.opts.MaxBackupsPerChat, s.opts.Summarize),
+		tools.NewBackupMatchingHandler(api, msgProvider, s.opts.AllowedPaths, s.opts.MaxBackupsPerChat),
+		tools.NewBackupCleanupHandler(s.opts.AllowedPaths, s.opts.MaxBackupsPerChat),
+		tools.NewBackupIndexHandler(s.opts.Summarize, s.opts.AllowedPaths),
+		tools.NewBackupSemanticSearchHandler(s.opts.Summarize, s.opts.AllowedPaths),
+		tools.NewChatMuteHandler(api, msgProvider),
+		tools.NewChatUnmuteHandler(api, peers),
+		tools.NewChatsMuteHandler(msgProvider),
+		tools.NewChatsUnmuteHandler(msgProvider),
+		tools.NewChatMuteStatusHandler(msgProvider),
+		tools.NewChatArchiveHandler(msgProvider),
+		tools.NewChatUnarchiveHandler(msgProvider),
+		tools.NewChatPinHandler(msgProvider),
+		tools.NewChatUnpinHandler(msgProvider),
+		tools.NewChatFolderMoveHandler(api, peers),
+		tools.NewChatNotificationsHandler(api, peers),
+		tools.NewChatStatsHandler(msgProvider, s.opts.AllowedPaths),
+		tools.NewLinksExtractHandler(msgProvider),
+		tools.NewChatSummarizeHandler(api, msgProvider, s.mcpServer, s.opts.Summarize, s.opts.AllowedPaths),
+		tools.NewUnreadSummarizeHandler(api, msgProvider, s.mcpServer, s.opts.Summarize),
+		tools.NewReplySuggestHandler(api, msgProvider, s.mcpServer, s.opts.Summarize),
+		tools.NewMessagesTranslateHandler(api, msgProvider, s.mcpServer, s.opts.Summarize),
+		tools.NewChatFilesHandler(msgProvider),
+		tools.NewMediaGetHandler(api, client, s.opts.AllowedPaths),
+		tools.NewProfilePhotoGetHandler(api, peers, client, s.opts.AllowedPaths),
+		tools.NewVoiceTranscribeHandler(api, client, msgProvider, s.opts.Summarize),
+	}
+	// Fail before connecting when the tool selection names unknown tools
+	handlers, err = tools.FilterHandlers(handlers, s.opts.EnabledTools, s.opts.DisabledTools)
+	if err != nil {
+		return err
+	}
+	middlewares := []server.ToolHandlerMiddleware{tools.ConnectionMiddleware(s.status), tools.RecentChatsMiddleware(recentChats, peers), tools.FloodWaitMiddleware()}
+	if s.opts.ReadOnly {
+		// Leave out tools writing to Telegram, and auto-replies, conditional
+		// messages and keyword watches set up before
+		handlers = tools.ReadOnlyHandlers(handlers)
+		middlewares = append(middlewares, tools.ReadOnlyMiddleware())
+	}
+	if s.opts.ConfirmDestructive {
+		handlers = tools.ConfirmHandlers(handlers, s.mcpServer, peers, recentChats.Name)
+	}
+	tools.RegisterTools(s.mcpServer, handlers, middlewares...)
+
+	resources.RegisterResources(s.mcpServer, []resources.ResourceHandler{
+		resources.NewMeHandler(api),
+		resources.NewChatsHandler(api, s.opts.ChatsIncludeArchived, s.opts.ChatsPaginateBlocks),
+		resources.NewFoldersHandler(api),
+		resources.NewRecentHandler(recentChats),
 	})
+
+	resources.RegisterResourceTemplates(s.mcpServer, []resources.ResourceTemplateHandler{
+		resources.NewChatMessagesHandler(msgProvider),
+		resources.NewChatContextHandler(msgProvider),
+	})
+
+	// Set up dynamic pinned chat resources once Telegram is connected
+	pinnedProvider := resources.NewPinnedChatsProvider(api, msgProvider, s.mcpServer, s.opts.PinnedScope)
+	s.hooks.AddBeforeListResources(func(ctx context.Context, id any, req *mcp.ListResourcesRequest) {
+		if state, _, _ := s.status.Get(); state == tgclient.ConnAuthorized {
+			_ = pinnedProvider.RefreshResources(ctx)
+		}
+	})
+
+	errLogger := log.New(s.opts.ErrOut, "[mcp-telegram] ", log.LstdFlags)
+
+	// Connect to Telegram in the background, so the MCP server answers right away;
+	// tools and resources wait for the connection through s.status
+	connCtx, stopConn := context.WithCancel(ctx)
+	defer stopConn()
+	connDone := make(chan struct{})
+	go func() {
+		defer close(connDone)
+		// waiter.Run wraps a client.Run to handle FLOOD_WAIT errors automatically
+		err := waiter.Run(connCtx, func(ctx context.Context) error {
+			return client.Run(ctx, func(ctx context.Context) error {
+				auth, err := client.Auth().Status(ctx)
+				if err != nil {
+					return fmt.Errorf("checking auth status: %w", err)
+				}
+				if !auth.Authorized {
+					errLogger.Printf("Telegram session is not authorized; run 'mcp-telegram login' and restart the server")
+					s.status.SetUnauthorized()
+					return nil
+				}
+				s.status.SetAuthorized(auth.User)
+
+				// Send conditional messages whose deadline has passed
+				if !s.opts.ReadOnly {
+					scheduler := conditional.NewScheduler(conditionals,
+						tools.ConditionalIncomingChecker(msgProvider),
+						tools.ConditionalSender(msgProvider), nil)
+					go scheduler.Run(ctx, conditional.DefaultInterval)
+
+					// Deliver incoming updates in order until the connection closes
+					return gaps.Run(ctx, api, auth.User.ID, updates.AuthOptions{})
+				}
+
+				<-ctx.Done()
+				return ctx.Err()
+			})
+		})
+		if err != nil && connCtx.Err() == nil {
+			errLogger.Printf("Telegram connection failed: %v", err)
+			s.status.SetFailed(err)
+		}
+	}()
+
+	if s.opts.Transport.Kind == TransportHTTP {
+		err = serveHTTP(ctx, s.mcpServer, s.opts.Transport, errLogger)
+	} else {
+		// Run MCP server over stdio
+		stdioServer := server.NewStdioServer(s.mcpServer)
+		stdioServer.SetErrorLogger(errLogger)
+		err = stdioServer.Listen(ctx, s.opts.Stdin, s.opts.Stdout)
+	}
+
+	// Close the Telegram client before returning
+	stopConn()
+	<-connDone
 	if err != nil {
 		return fmt.Errorf("running server: %w", err)
 	}
@@ -0,0 +1,19 @@
+package server
+
+import (
+	"context"
+	"errors"
+
+	"github.com/gotd/td/tg"
+)
+
+// onNewMessages passes the new messages dispatcher gets to autoReply and, with channel
+// posts, to keywordWatch.
+func onNewMessages(dispatcher tg.UpdateDispatcher, autoReply tg.NewMessageHandler, keywordWatch func(ctx context.Context, e tg.Entities, msg tg.MessageClass) error) {
+	dispatcher.OnNewMessage(func(ctx context.Context, e tg.Entities, update *tg.UpdateNewMessage) error {
+		return errors.Join(autoReply(ctx, e, update), keywordWatch(ctx, e, update.Message))
+	})
+	dispatcher.OnNewChannelMessage(func(ctx context.Context, e tg.Entities, update *tg.UpdateNewChannelMessage) error {
+		return keywordWatch(ctx, e, update.Message)
+	})
+}
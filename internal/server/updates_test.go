@@ -0,0 +1,95 @@
+package server
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gotd/td/telegram/updates"
+	"github.com/gotd/td/tg"
+)
+
+// stateAPI answers the update manager's state requests as an account without
+// missed updates.
+type stateAPI struct{}
+
+func (stateAPI) UpdatesGetState(context.Context) (*tg.UpdatesState, error) {
+	return &tg.UpdatesState{Pts: 1, Seq: 1, Date: int(time.Now().Unix())}, nil
+}
+
+func (stateAPI) UpdatesGetDifference(context.Context, *tg.UpdatesGetDifferenceRequest) (tg.UpdatesDifferenceClass, error) {
+	return &tg.UpdatesDifferenceEmpty{Seq: 1, Date: int(time.Now().Unix())}, nil
+}
+
+func (stateAPI) UpdatesGetChannelDifference(context.Context, *tg.UpdatesGetChannelDifferenceRequest) (tg.UpdatesChannelDifferenceClass, error) {
+	return &tg.UpdatesChannelDifferenceEmpty{Final: true, Pts: 1}, nil
+}
+
+func TestOnNewMessagesShortUpdates(t *testing.T) {
+	tests := []struct {
+		name     string
+		update   tg.UpdatesClass
+		wantPeer tg.PeerClass
+	}{
+		{
+			name:     "private message",
+			update:   &tg.UpdateShortMessage{ID: 10, UserID: 42, Message: "invoice attached", Pts: 2, PtsCount: 1, Date: int(time.Now().Unix())},
+			wantPeer: &tg.PeerUser{UserID: 42},
+		},
+		{
+			name:     "basic group message",
+			update:   &tg.UpdateShortChatMessage{ID: 11, FromID: 42, ChatID: 7, Message: "invoice attached", Pts: 2, PtsCount: 1, Date: int(time.Now().Unix())},
+			wantPeer: &tg.PeerChat{ChatID: 7},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			autoReplies := make(chan *tg.UpdateNewMessage, 1)
+			watched := make(chan tg.MessageClass, 1)
+			dispatcher := tg.NewUpdateDispatcher()
+			onNewMessages(dispatcher,
+				func(_ context.Context, _ tg.Entities, update *tg.UpdateNewMessage) error {
+					autoReplies <- update
+					return nil
+				},
+				func(_ context.Context, _ tg.Entities, msg tg.MessageClass) error {
+					watched <- msg
+					return nil
+				})
+			gaps := updates.New(updates.Config{Handler: dispatcher})
+
+			ctx, cancel := context.WithCancel(t.Context())
+			defer cancel()
+			started := make(chan struct{})
+			done := make(chan error, 1)
+			go func() {
+				done <- gaps.Run(ctx, stateAPI{}, 1, updates.AuthOptions{OnStart: func(context.Context) { close(started) }})
+			}()
+			<-started
+
+			if err := gaps.Handle(ctx, tt.update); err != nil {
+				t.Fatalf("Handle() error = %v", err)
+			}
+			select {
+			case msg := <-watched:
+				if got := msg.(*tg.Message).PeerID; got.String() != tt.wantPeer.String() {
+					t.Errorf("keyword watch got a message in %v, want %v", got, tt.wantPeer)
+				}
+			case <-time.After(5 * time.Second):
+				t.Fatal("keyword watch got no message")
+			}
+			select {
+			case update := <-autoReplies:
+				if got := update.Message.(*tg.Message).PeerID; got.String() != tt.wantPeer.String() {
+					t.Errorf("auto-reply got a message in %v, want %v", got, tt.wantPeer)
+				}
+			default:
+				t.Error("auto-reply got no message")
+			}
+
+			cancel()
+			<-done
+		})
+	}
+}
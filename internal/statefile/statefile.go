@@ -0,0 +1,68 @@
+// Package statefile keeps the JSON files of the stores whose state survives restarts,
+// such as auto-replies, conditional messages and keyword watches.
+package statefile
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+)
+
+// DefaultPath returns the default path of the state file name based on the OS.
+func DefaultPath(name string) string {
+	homeDir, _ := os.UserHomeDir()
+
+	switch runtime.GOOS {
+	case "darwin":
+		return filepath.Join(homeDir, "Library", "Application Support", "mcp-telegram", name)
+	case "windows":
+		if appData := os.Getenv("APPDATA"); appData != "" {
+			return filepath.Join(appData, "mcp-telegram", name)
+		}
+		return filepath.Join(homeDir, "AppData", "Roaming", "mcp-telegram", name)
+	default: // linux and others
+		if stateHome := os.Getenv("XDG_STATE_HOME"); stateHome != "" {
+			return filepath.Join(stateHome, "mcp-telegram", name)
+		}
+		return filepath.Join(homeDir, ".local", "state", "mcp-telegram", name)
+	}
+}
+
+// Load decodes the state file at path into v, leaving v unchanged when the file
+// doesn't exist yet. what names the state in errors, e.g. "auto-reply state".
+func Load(path, what string, v any) error {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("reading %s: %w", what, err)
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("parsing %s: %w", what, err)
+	}
+	return nil
+}
+
+// Save writes v to the state file at path atomically, creating its directory.
+// what names the state in errors.
+func Save(path, what string, v any) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshaling %s: %w", what, err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("creating state directory: %w", err)
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("writing %s: %w", what, err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("writing %s: %w", what, err)
+	}
+	return nil
+}
@@ -0,0 +1,57 @@
+package statefile
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+type testState struct {
+	NextID int      `json:"next_id"`
+	Items  []string `json:"items"`
+}
+
+func TestSaveAndLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", "state.json")
+
+	loaded := testState{NextID: 1}
+	if err := Load(path, "test state", &loaded); err != nil {
+		t.Fatalf("Load() of a missing file = %v", err)
+	}
+	if loaded.NextID != 1 {
+		t.Errorf("Load() of a missing file changed the state to %+v", loaded)
+	}
+
+	want := testState{NextID: 3, Items: []string{"a", "b"}}
+	if err := Save(path, "test state", want); err != nil {
+		t.Fatalf("Save() = %v", err)
+	}
+	if _, err := os.Stat(path + ".tmp"); !os.IsNotExist(err) {
+		t.Errorf("temporary file left behind: %v", err)
+	}
+	var got testState
+	if err := Load(path, "test state", &got); err != nil {
+		t.Fatalf("Load() = %v", err)
+	}
+	if got.NextID != want.NextID || strings.Join(got.Items, ",") != "a,b" {
+		t.Errorf("Load() = %+v, want %+v", got, want)
+	}
+}
+
+func TestLoadCorrupt(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	if err := os.WriteFile(path, []byte("{not json"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	var s testState
+	if err := Load(path, "test state", &s); err == nil || !strings.Contains(err.Error(), "parsing test state") {
+		t.Errorf("Load() = %v, want a parsing error", err)
+	}
+}
+
+func TestDefaultPath(t *testing.T) {
+	if got := DefaultPath("watches.json"); filepath.Base(got) != "watches.json" || filepath.Base(filepath.Dir(got)) != "mcp-telegram" {
+		t.Errorf("DefaultPath() = %q", got)
+	}
+}
@@ -0,0 +1,194 @@
+// Package stats aggregates chat activity over a time window and compares two windows.
+package stats
+
+import (
+	"cmp"
+	"slices"
+	"time"
+
+	"github.com/tolmachov/mcp-telegram/internal/messages"
+)
+
+// SenderStats is the activity of one participant in a window.
+type SenderStats struct {
+	ID       int64  `json:"id"`
+	Name     string `json:"name,omitempty"`
+	Messages int    `json:"messages"`
+}
+
+// DayCount is the number of messages sent on one calendar day.
+type DayCount struct {
+	Date     string `json:"date"` // YYYY-MM-DD, local time
+	Messages int    `json:"messages"`
+}
+
+// DomainCount is how often links to one domain were posted.
+type DomainCount struct {
+	Domain string `json:"domain"`
+	Links  int    `json:"links"`
+}
+
+// MaxDomains is the number of linked domains a Window lists.
+const MaxDomains = 10
+
+// Window is the aggregated activity of a chat between Since and Until.
+type Window struct {
+	Since       time.Time     `json:"since"`
+	Until       time.Time     `json:"until"`
+	Messages    int           `json:"messages"`
+	Media       int           `json:"media"`       // Messages with media attached
+	Text        int           `json:"text"`        // Messages without media
+	MediaShare  float64       `json:"media_share"` // Media / Messages, 0 without messages
+	ActiveDays  int           `json:"active_days"` // Calendar days with at least one message
+	PerDay      []DayCount    `json:"messages_per_day"`
+	Hours       [24]int       `json:"hours"` // Messages per hour of the day, local time
+	TopDomains  []DomainCount `json:"top_domains"`
+	SenderCount int           `json:"sender_count"`
+	Senders     []SenderStats `json:"senders"` // Most active first
+}
+
+// Aggregator accumulates messages of one window. Messages can be added in any order.
+type Aggregator struct {
+	window  Window
+	senders map[int64]*SenderStats
+	days    map[string]int
+	domains map[string]int
+}
+
+// NewAggregator creates an Aggregator for the window between since and until.
+func NewAggregator(since, until time.Time) *Aggregator {
+	return &Aggregator{
+		window:  Window{Since: since, Until: until},
+		senders: make(map[int64]*SenderStats),
+		days:    make(map[string]int),
+		domains: make(map[string]int),
+	}
+}
+
+// Add counts a message.
+func (a *Aggregator) Add(msg messages.Message) {
+	a.window.Messages++
+	if msg.Media != nil {
+		a.window.Media++
+	} else {
+		a.window.Text++
+	}
+	a.days[msg.Date.Format("2006-01-02")]++
+	a.window.Hours[msg.Date.Hour()]++
+	for _, link := range msg.Entities {
+		if domain := messages.LinkDomain(link); domain != "" {
+			a.domains[domain]++
+		}
+	}
+
+	s, ok := a.senders[msg.SenderID]
+	if !ok {
+		s = &SenderStats{ID: msg.SenderID}
+		a.senders[msg.SenderID] = s
+	}
+	if s.Name == "" {
+		s.Name = msg.SenderName
+	}
+	s.Messages++
+}
+
+// Window returns the aggregates of the messages added so far.
+func (a *Aggregator) Window() Window {
+	w := a.window
+	if w.Messages > 0 {
+		w.MediaShare = float64(w.Media) / float64(w.Messages)
+	}
+
+	w.ActiveDays = len(a.days)
+	w.PerDay = make([]DayCount, 0, len(a.days))
+	for date, n := range a.days {
+		w.PerDay = append(w.PerDay, DayCount{Date: date, Messages: n})
+	}
+	slices.SortFunc(w.PerDay, func(x, y DayCount) int { return cmp.Compare(x.Date, y.Date) })
+
+	w.TopDomains = make([]DomainCount, 0, len(a.domains))
+	for domain, n := range a.domains {
+		w.TopDomains = append(w.TopDomains, DomainCount{Domain: domain, Links: n})
+	}
+	slices.SortFunc(w.TopDomains, func(x, y DomainCount) int {
+		if c := cmp.Compare(y.Links, x.Links); c != 0 {
+			return c
+		}
+		return cmp.Compare(x.Domain, y.Domain)
+	})
+	w.TopDomains = w.TopDomains[:min(len(w.TopDomains), MaxDomains)]
+
+	w.SenderCount = len(a.senders)
+	w.Senders = make([]SenderStats, 0, len(a.senders))
+	for _, s := range a.senders {
+		w.Senders = append(w.Senders, *s)
+	}
+	slices.SortFunc(w.Senders, func(x, y SenderStats) int {
+		if c := cmp.Compare(y.Messages, x.Messages); c != 0 {
+			return c
+		}
+		return cmp.Compare(x.ID, y.ID)
+	})
+	return w
+}
+
+// SenderDelta is the change in one participant's activity between two windows.
+type SenderDelta struct {
+	ID       int64  `json:"id"`
+	Name     string `json:"name,omitempty"`
+	Recent   int    `json:"recent"`
+	Previous int    `json:"previous"`
+	Change   int    `json:"change"`
+}
+
+// Diff compares a recent window with a previous one.
+type Diff struct {
+	MessagesChange int `json:"messages_change"`
+	// MessagesChangePercent is relative to the previous window; nil when it had no messages
+	MessagesChangePercent *float64      `json:"messages_change_percent,omitempty"`
+	Senders               []SenderDelta `json:"senders"`          // Biggest increase first, biggest decrease last
+	NewParticipants       []SenderStats `json:"new_participants"` // Active only in the recent window
+	WentSilent            []SenderStats `json:"went_silent"`      // Active only in the previous window
+}
+
+// Compare computes how activity changed from previous to recent.
+func Compare(recent, previous Window) Diff {
+	d := Diff{
+		MessagesChange:  recent.Messages - previous.Messages,
+		Senders:         []SenderDelta{},
+		NewParticipants: []SenderStats{},
+		WentSilent:      []SenderStats{},
+	}
+	if previous.Messages > 0 {
+		pct := float64(d.MessagesChange) / float64(previous.Messages) * 100
+		d.MessagesChangePercent = &pct
+	}
+
+	before := make(map[int64]SenderStats, len(previous.Senders))
+	for _, s := range previous.Senders {
+		before[s.ID] = s
+	}
+	seen := make(map[int64]bool, len(recent.Senders))
+	for _, s := range recent.Senders {
+		seen[s.ID] = true
+		prev, ok := before[s.ID]
+		if !ok {
+			d.NewParticipants = append(d.NewParticipants, s)
+		}
+		d.Senders = append(d.Senders, SenderDelta{ID: s.ID, Name: s.Name, Recent: s.Messages, Previous: prev.Messages, Change: s.Messages - prev.Messages})
+	}
+	for _, s := range previous.Senders {
+		if !seen[s.ID] {
+			d.WentSilent = append(d.WentSilent, s)
+			d.Senders = append(d.Senders, SenderDelta{ID: s.ID, Name: s.Name, Previous: s.Messages, Change: -s.Messages})
+		}
+	}
+
+	slices.SortStableFunc(d.Senders, func(x, y SenderDelta) int {
+		if c := cmp.Compare(y.Change, x.Change); c != 0 {
+			return c
+		}
+		return cmp.Compare(x.ID, y.ID)
+	})
+	return d
+}
@@ -0,0 +1,87 @@
+package stats
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/tolmachov/mcp-telegram/internal/messages"
+)
+
+func TestAggregator(t *testing.T) {
+	day := time.Date(2024, 3, 1, 10, 0, 0, 0, time.Local)
+	agg := NewAggregator(day, day.Add(72*time.Hour))
+	for _, msg := range []messages.Message{
+		{ID: 1, Date: day, SenderID: 1, SenderName: "Anna", Entities: []string{"https://www.GitHub.com/a", "go.dev/doc"}},
+		{ID: 2, Date: day.Add(time.Hour), SenderID: 2, SenderName: "Bob", Media: &messages.MediaInfo{Type: "photo"}},
+		{ID: 3, Date: day.Add(48 * time.Hour), SenderID: 2, SenderName: "Bob", Entities: []string{"https://github.com/b"}},
+	} {
+		agg.Add(msg)
+	}
+
+	got := agg.Window()
+	want := Window{
+		Since:       day,
+		Until:       day.Add(72 * time.Hour),
+		Messages:    3,
+		Media:       1,
+		Text:        2,
+		MediaShare:  1.0 / 3,
+		ActiveDays:  2,
+		PerDay:      []DayCount{{Date: "2024-03-01", Messages: 2}, {Date: "2024-03-03", Messages: 1}},
+		TopDomains:  []DomainCount{{Domain: "github.com", Links: 2}, {Domain: "go.dev", Links: 1}},
+		SenderCount: 2,
+		Senders:     []SenderStats{{ID: 2, Name: "Bob", Messages: 2}, {ID: 1, Name: "Anna", Messages: 1}},
+	}
+	want.Hours[10], want.Hours[11] = 2, 1
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Window() = %+v, want %+v", got, want)
+	}
+}
+
+func TestCompare(t *testing.T) {
+	previous := Window{Messages: 10, Senders: []SenderStats{
+		{ID: 1, Name: "Anna", Messages: 6},
+		{ID: 2, Name: "Bob", Messages: 3},
+		{ID: 3, Name: "Carol", Messages: 1},
+	}}
+	recent := Window{Messages: 15, Senders: []SenderStats{
+		{ID: 2, Name: "Bob", Messages: 9},
+		{ID: 1, Name: "Anna", Messages: 4},
+		{ID: 4, Name: "Dan", Messages: 2},
+	}}
+
+	d := Compare(recent, previous)
+
+	if d.MessagesChange != 5 {
+		t.Errorf("MessagesChange = %d, want 5", d.MessagesChange)
+	}
+	if d.MessagesChangePercent == nil || *d.MessagesChangePercent != 50 {
+		t.Errorf("MessagesChangePercent = %v, want 50", d.MessagesChangePercent)
+	}
+	wantSenders := []SenderDelta{
+		{ID: 2, Name: "Bob", Recent: 9, Previous: 3, Change: 6},
+		{ID: 4, Name: "Dan", Recent: 2, Change: 2},
+		{ID: 3, Name: "Carol", Previous: 1, Change: -1},
+		{ID: 1, Name: "Anna", Recent: 4, Previous: 6, Change: -2},
+	}
+	if !reflect.DeepEqual(d.Senders, wantSenders) {
+		t.Errorf("Senders = %+v, want %+v", d.Senders, wantSenders)
+	}
+	if want := []SenderStats{{ID: 4, Name: "Dan", Messages: 2}}; !reflect.DeepEqual(d.NewParticipants, want) {
+		t.Errorf("NewParticipants = %+v, want %+v", d.NewParticipants, want)
+	}
+	if want := []SenderStats{{ID: 3, Name: "Carol", Messages: 1}}; !reflect.DeepEqual(d.WentSilent, want) {
+		t.Errorf("WentSilent = %+v, want %+v", d.WentSilent, want)
+	}
+}
+
+func TestCompareEmptyPrevious(t *testing.T) {
+	d := Compare(Window{Messages: 3, Senders: []SenderStats{{ID: 1, Messages: 3}}}, Window{})
+	if d.MessagesChangePercent != nil {
+		t.Errorf("MessagesChangePercent = %v, want nil for an empty previous window", *d.MessagesChangePercent)
+	}
+	if len(d.NewParticipants) != 1 || len(d.WentSilent) != 0 {
+		t.Errorf("NewParticipants = %+v, WentSilent = %+v", d.NewParticipants, d.WentSilent)
+	}
+}
@@ -14,22 +14,23 @@ const anthropicAPIURL = "https://api.anthropic.com/v1/messages"
 
 // AnthropicProvider implements Provider using Anthropic API.
 type AnthropicProvider struct {
-	apiKey string
-	model  string
-	client *http.Client
+	apiKey         string
+	model          string
+	client         *http.Client
+	requestTimeout time.Duration
 }
 
-// NewAnthropicProvider creates a new AnthropicProvider.
-func NewAnthropicProvider(apiKey, model string) *AnthropicProvider {
+// NewAnthropicProvider creates a new AnthropicProvider. Each request is limited to
+// requestTimeout (DefaultRequestTimeout if zero).
+func NewAnthropicProvider(apiKey, model string, requestTimeout time.Duration) *AnthropicProvider {
 	if model == "" {
 		model = "claude-haiku-4-5-20251001"
 	}
 	return &AnthropicProvider{
-		apiKey: apiKey,
-		model:  model,
-		client: &http.Client{
-			Timeout: 10 * time.Minute,
-		},
+		apiKey:         apiKey,
+		model:          model,
+		client:         &http.Client{},
+		requestTimeout: requestTimeout,
 	}
 }
 
@@ -65,6 +66,9 @@ func (e *anthropicError) Error() string {
 
 // Summarize sends a prompt to Anthropic and returns the response.
 func (p *AnthropicProvider) Summarize(ctx context.Context, prompt string) (string, error) {
+	ctx, cancel := withRequestTimeout(ctx, p.requestTimeout)
+	defer cancel()
+
 	reqBody := anthropicRequest{
 		Model:     p.model,
 		MaxTokens: 4096,
@@ -98,7 +102,7 @@ func (p *AnthropicProvider) Summarize(ctx context.Context, prompt string) (strin
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("anthropic returned status %d: %s", resp.StatusCode, string(respBody))
+		return "", newStatusError("anthropic", resp, respBody)
 	}
 
 	var anthropicResp anthropicResponse
@@ -0,0 +1,101 @@
+package summarize
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// citationInstructions are added to the batch prompt in citation mode.
+const citationInstructions = `- After each factual claim, cite the supporting messages by their IDs in brackets, e.g. [#123] or [#123, #456]
+- Keep citations from the current summary attached to their claims
+- Only cite IDs that appear in the messages as [#id]
+`
+
+// citationRe matches a bracketed citation group such as "[#12]" or "[#12, #15]",
+// with the whitespace before it so removed groups don't leave a gap.
+var citationRe = regexp.MustCompile(`[ \t]*\[#\d+(?:\s*,\s*#?\d+)*\]`)
+
+// citationIDRe matches one ID inside a citation group.
+var citationIDRe = regexp.MustCompile(`\d+`)
+
+// CitationStats counts the citations found in a summary.
+type CitationStats struct {
+	Valid   int // cited IDs present in the summarized messages
+	Invalid int // cited IDs not among them, removed from the summary
+}
+
+// ValidateCitations removes cited message IDs that are not in known, dropping
+// citation groups that end up empty, and de-duplicates IDs within a group.
+func ValidateCitations(summary string, known map[int]bool) (string, CitationStats) {
+	var stats CitationStats
+	text := rewriteCitations(summary, func(ids []int) []int {
+		var kept []int
+		for _, id := range ids {
+			if known[id] {
+				kept = append(kept, id)
+				stats.Valid++
+			} else {
+				stats.Invalid++
+			}
+		}
+		return kept
+	}, formatCitation)
+	return text, stats
+}
+
+// LinkCitations turns each citation into markdown links using link, e.g. a t.me link
+// for public chats. IDs for which link returns "" are left as bare "#id".
+func LinkCitations(summary string, link func(msgID int) string) string {
+	return rewriteCitations(summary, func(ids []int) []int { return ids }, func(ids []int) string {
+		parts := make([]string, len(ids))
+		linked := false
+		for i, id := range ids {
+			if url := link(id); url != "" {
+				parts[i] = "[#" + strconv.Itoa(id) + "](" + url + ")"
+				linked = true
+			} else {
+				parts[i] = "#" + strconv.Itoa(id)
+			}
+		}
+		if !linked {
+			return formatCitation(ids)
+		}
+		return "(" + strings.Join(parts, ", ") + ")"
+	})
+}
+
+// rewriteCitations replaces every citation group with format(filter(ids)), removing
+// the group (and the whitespace before it) when filter leaves no IDs.
+func rewriteCitations(summary string, filter func([]int) []int, format func([]int) string) string {
+	return citationRe.ReplaceAllStringFunc(summary, func(group string) string {
+		trimmed := strings.TrimLeft(group, " \t")
+		leading := group[:len(group)-len(trimmed)]
+
+		seen := make(map[int]bool)
+		var ids []int
+		for _, s := range citationIDRe.FindAllString(trimmed, -1) {
+			id, err := strconv.Atoi(s)
+			if err != nil || seen[id] {
+				continue
+			}
+			seen[id] = true
+			ids = append(ids, id)
+		}
+
+		ids = filter(ids)
+		if len(ids) == 0 {
+			return ""
+		}
+		return leading + format(ids)
+	})
+}
+
+// formatCitation renders IDs as a citation group, e.g. "[#12, #15]".
+func formatCitation(ids []int) string {
+	parts := make([]string, len(ids))
+	for i, id := range ids {
+		parts[i] = "#" + strconv.Itoa(id)
+	}
+	return "[" + strings.Join(parts, ", ") + "]"
+}
@@ -0,0 +1,173 @@
+package summarize
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/tolmachov/mcp-telegram/internal/messages"
+)
+
+func TestValidateCitations(t *testing.T) {
+	known := map[int]bool{10: true, 11: true, 12: true}
+
+	tests := []struct {
+		name      string
+		summary   string
+		want      string
+		wantStats CitationStats
+	}{
+		{
+			name:      "no citations",
+			summary:   "Budget approved in [2024].",
+			want:      "Budget approved in [2024].",
+			wantStats: CitationStats{},
+		},
+		{
+			name:      "valid citation",
+			summary:   "Budget approved [#10].",
+			want:      "Budget approved [#10].",
+			wantStats: CitationStats{Valid: 1},
+		},
+		{
+			name:      "invalid citation removed with its space",
+			summary:   "Budget approved [#999]. Launch moved [#11].",
+			want:      "Budget approved. Launch moved [#11].",
+			wantStats: CitationStats{Valid: 1, Invalid: 1},
+		},
+		{
+			name:      "group keeps valid IDs",
+			summary:   "Agreed [#10, #999, #12].",
+			want:      "Agreed [#10, #12].",
+			wantStats: CitationStats{Valid: 2, Invalid: 1},
+		},
+		{
+			name:      "overlapping citations",
+			summary:   "Agreed [#10][#10, #11] and later [#11,#10].",
+			want:      "Agreed [#10][#10, #11] and later [#11, #10].",
+			wantStats: CitationStats{Valid: 5},
+		},
+		{
+			name:      "duplicate IDs in a group",
+			summary:   "Agreed [#12, #12].",
+			want:      "Agreed [#12].",
+			wantStats: CitationStats{Valid: 1},
+		},
+		{
+			name:      "hash omitted after the first ID",
+			summary:   "Agreed [#10, 11].",
+			want:      "Agreed [#10, #11].",
+			wantStats: CitationStats{Valid: 2},
+		},
+		{
+			name:      "all invalid in a group",
+			summary:   "Rumor [#1, #2]\nNext line",
+			want:      "Rumor\nNext line",
+			wantStats: CitationStats{Invalid: 2},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, stats := ValidateCitations(tt.summary, known)
+			if got != tt.want {
+				t.Errorf("summary = %q, want %q", got, tt.want)
+			}
+			if stats != tt.wantStats {
+				t.Errorf("stats = %+v, want %+v", stats, tt.wantStats)
+			}
+		})
+	}
+}
+
+func TestLinkCitations(t *testing.T) {
+	public := func(id int) string { return messages.MessageLink("devchat", 0, id) }
+	private := func(id int) string { return messages.MessageLink("", 0, id) }
+
+	tests := []struct {
+		name    string
+		summary string
+		link    func(int) string
+		want    string
+	}{
+		{
+			name:    "public chat",
+			summary: "Agreed [#10, #11].",
+			link:    public,
+			want:    "Agreed ([#10](https://t.me/devchat/10), [#11](https://t.me/devchat/11)).",
+		},
+		{
+			name:    "chat without public links keeps bare IDs",
+			summary: "Agreed [#10, #11].",
+			link:    private,
+			want:    "Agreed [#10, #11].",
+		},
+		{
+			name:    "adjacent citations",
+			summary: "Agreed [#10][#11]",
+			link:    public,
+			want:    "Agreed ([#10](https://t.me/devchat/10))([#11](https://t.me/devchat/11))",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := LinkCitations(tt.summary, tt.link); got != tt.want {
+				t.Errorf("LinkCitations() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSummarizeMessagesCitations(t *testing.T) {
+	msgs := chattyMessages(1)
+	known := msgs[0].ID
+
+	provider := &scriptedProvider{replies: []string{
+		"Plan agreed [#" + strconv.Itoa(known) + "]. Invented claim [#99999].",
+	}}
+	s := NewSummarizer(provider, nil, DefaultBatchTokens)
+	result, err := s.SummarizeMessages(context.Background(), msgs, Options{
+		Goal:         "key points",
+		Citations:    true,
+		CitationLink: func(id int) string { return messages.MessageLink("devchat", 0, id) },
+	}, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !strings.Contains(provider.prompts[0], "[#"+strconv.Itoa(known)+"]") {
+		t.Errorf("prompt does not show citable message IDs: %q", provider.prompts[0])
+	}
+	if !strings.Contains(provider.prompts[0], "cite the supporting messages") {
+		t.Errorf("prompt lacks citation instructions")
+	}
+	want := "Plan agreed ([#" + strconv.Itoa(known) + "](https://t.me/devchat/" + strconv.Itoa(known) + ")). Invented claim."
+	if result.Summary != want {
+		t.Errorf("summary = %q, want %q", result.Summary, want)
+	}
+	if result.Stats.Citations != 1 || result.Stats.InvalidCitations != 1 {
+		t.Errorf("stats = %+v, want 1 valid and 1 invalid citation", result.Stats)
+	}
+	if !strings.Contains(result.Stats.String(), "1 citations (1 invalid removed)") {
+		t.Errorf("stats note = %q", result.Stats.String())
+	}
+}
+
+func TestSummarizeMessagesCitationsUseMessageLinks(t *testing.T) {
+	msgs := chattyMessages(1)
+	known := msgs[0].ID
+	msgs[0].Link = messages.MessageLink("", 777, known)
+
+	provider := &scriptedProvider{replies: []string{"Plan agreed [#" + strconv.Itoa(known) + "]."}}
+	s := NewSummarizer(provider, nil, DefaultBatchTokens)
+	result, err := s.SummarizeMessages(context.Background(), msgs, Options{Goal: "key points", Citations: true}, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := "Plan agreed ([#" + strconv.Itoa(known) + "](https://t.me/c/777/" + strconv.Itoa(known) + "))."
+	if result.Summary != want {
+		t.Errorf("summary = %q, want %q", result.Summary, want)
+	}
+}
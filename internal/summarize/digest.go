@@ -0,0 +1,102 @@
+package summarize
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/tolmachov/mcp-telegram/internal/messages"
+)
+
+// digestPromptTemplate adds one chat's messages to a digest spanning several chats.
+const digestPromptTemplate = `You are writing a digest of unread messages across several Telegram chats.
+
+User's goal for this digest:
+%s
+
+Digest so far:
+%s
+
+Unread messages in the chat %q to incorporate:
+%s
+
+Instructions:
+` + untrustedInstruction + `
+- Keep the digest grouped by chat, with one section per chat headed by the chat's name
+- Add the new messages to the section of this chat, creating it if needed, and keep the other sections as they are
+- Focus on what is relevant to the user's goal: news, decisions, questions and requests waiting for the user
+- Keep each section short; skip small talk
+- Write in the same language as the messages
+- Output as plain text (markdown allowed)
+` + aliasInstructions + `
+Updated digest:`
+
+// DigestChat is a chat's unread messages, in chronological order, for Digest.
+type DigestChat struct {
+	Name     string
+	Messages []messages.Message
+}
+
+// DigestResult is the outcome of a digest run.
+type DigestResult struct {
+	Summary string
+	Chats   int  // chats added to the digest
+	Partial bool // the deadline was reached before all chats were added
+}
+
+// Digest summarizes the messages of several chats into one summary grouped by chat,
+// adding the chats one by one in order. opts.Goal, MergeConsecutive, MergeGap and
+// Deadline apply as in SummarizeMessages; when the deadline is reached, the digest of
+// the chats added so far is returned.
+func (s *Summarizer) Digest(ctx context.Context, chats []DigestChat, opts Options, onProgress ProgressCallback) (DigestResult, error) {
+	batchCtx := ctx
+	if opts.Deadline > 0 {
+		var cancel context.CancelFunc
+		batchCtx, cancel = context.WithTimeout(ctx, opts.Deadline)
+		defer cancel()
+	}
+
+	var result DigestResult
+	var digest string
+	for i, chat := range chats {
+		textMessages := messages.FilterTextOnly(chat.Messages)
+		if len(textMessages) == 0 {
+			continue
+		}
+		if opts.MergeConsecutive {
+			gap := opts.MergeGap
+			if gap <= 0 {
+				gap = messages.DefaultMergeGap
+			}
+			textMessages = messages.MergeConsecutive(textMessages, gap)
+		}
+
+		// A busy chat takes several calls, each adding a batch of its messages
+		aliases := messages.NewSenderAliases(false)
+		for _, batch := range splitIntoBatchesByTokens(textMessages, s.batchTokens) {
+			if onProgress != nil {
+				onProgress(i+1, len(chats), fmt.Sprintf("Summarizing %s (chat %d/%d)", chat.Name, i+1, len(chats)))
+			}
+			prompt := fmt.Sprintf(digestPromptTemplate, opts.Goal, digest, messages.EscapeDataBoundary(chat.Name), dataBlock(aliases.FormatBatch(batch, false)))
+			summary, err := s.summarizeWithProgress(batchCtx, prompt, i+1, len(chats), " ("+chat.Name+")", onProgress)
+			if err != nil {
+				if result.Chats > 0 && ctx.Err() == nil && errors.Is(batchCtx.Err(), context.DeadlineExceeded) {
+					result.Summary = fmt.Sprintf("[Partial digest: the %s deadline was reached after %d of %d chats.]\n\n%s", opts.Deadline, result.Chats, len(chats), digest)
+					result.Partial = true
+					return result, nil
+				}
+				return DigestResult{}, fmt.Errorf("summarizing %s: %w", chat.Name, err)
+			}
+			digest = strings.TrimSpace(summary)
+		}
+		result.Chats++
+	}
+
+	if result.Chats == 0 {
+		result.Summary = "No unread text messages found."
+		return result, nil
+	}
+	result.Summary = digest
+	return result, nil
+}
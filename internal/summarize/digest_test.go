@@ -0,0 +1,39 @@
+package summarize
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/tolmachov/mcp-telegram/internal/messages"
+)
+
+func TestDigest(t *testing.T) {
+	provider := &scriptedProvider{replies: []string{"## Team\nrelease moved", "## Team\nrelease moved\n## Family\ndinner at 7"}}
+	s := NewSummarizer(provider, nil, DefaultBatchTokens)
+
+	var progress []string
+	result, err := s.Digest(context.Background(), []DigestChat{
+		{Name: "Team", Messages: chattyMessages(2)},
+		{Name: "Photos", Messages: []messages.Message{{ID: 1, SenderName: "Ann"}}},
+		{Name: "Family", Messages: chattyMessages(1)},
+	}, Options{Goal: "briefing"}, func(_, _ int, message string) {
+		progress = append(progress, message)
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if result.Chats != 2 || result.Summary != provider.replies[1] {
+		t.Errorf("got %d chats, summary %q; want 2 chats and the last reply", result.Chats, result.Summary)
+	}
+	if len(provider.prompts) != 2 {
+		t.Fatalf("provider calls = %d, want 2: media-only chats are skipped", len(provider.prompts))
+	}
+	if !strings.Contains(provider.prompts[1], `"Family"`) || !strings.Contains(provider.prompts[1], provider.replies[0]) {
+		t.Errorf("second prompt lacks the chat name or the digest so far: %q", provider.prompts[1])
+	}
+	if len(progress) == 0 || progress[0] != "Summarizing Team (chat 1/3)" {
+		t.Errorf("progress = %q", progress)
+	}
+}
@@ -0,0 +1,63 @@
+package summarize
+
+import (
+	"context"
+	"fmt"
+)
+
+// Embedder is an interface for providers that turn texts into embedding vectors.
+type Embedder interface {
+	// Embed returns one vector per input text, in input order.
+	Embed(ctx context.Context, texts []string) ([][]float32, error)
+}
+
+// EmbedProviderName represents a valid embedding provider name.
+type EmbedProviderName string
+
+const (
+	EmbedProviderOllama EmbedProviderName = "ollama"
+	EmbedProviderOpenAI EmbedProviderName = "openai"
+)
+
+const (
+	// DefaultEmbedModel is the default Ollama embedding model.
+	DefaultEmbedModel = "nomic-embed-text"
+	// DefaultOpenAIEmbedModel is the default model for OpenAI-compatible endpoints.
+	DefaultOpenAIEmbedModel = "text-embedding-3-small"
+	// DefaultOpenAIURL is the default base URL of the OpenAI-compatible API.
+	DefaultOpenAIURL = "https://api.openai.com/v1"
+)
+
+// ValidateEmbedProviderName checks if the embedding provider name is valid.
+func ValidateEmbedProviderName(name string) error {
+	switch EmbedProviderName(name) {
+	case EmbedProviderOllama, EmbedProviderOpenAI:
+		return nil
+	default:
+		return fmt.Errorf("invalid embedding provider: %q (must be 'ollama' or 'openai')", name)
+	}
+}
+
+// NewEmbedder creates the embedding provider selected by the configuration.
+func NewEmbedder(cfg Config) (Embedder, error) {
+	switch cfg.EmbedProvider {
+	case EmbedProviderOllama, "":
+		model := cfg.EmbedModel
+		if model == "" {
+			model = DefaultEmbedModel
+		}
+		return NewOllamaProvider(cfg.OllamaURL, model, cfg.RequestTimeout), nil
+	case EmbedProviderOpenAI:
+		model := cfg.EmbedModel
+		if model == "" {
+			model = DefaultOpenAIEmbedModel
+		}
+		baseURL := cfg.EmbedURL
+		if baseURL == "" {
+			baseURL = DefaultOpenAIURL
+		}
+		return NewOpenAIEmbedder(baseURL, cfg.EmbedAPIKey, model, cfg.RequestTimeout), nil
+	default:
+		return nil, ValidateEmbedProviderName(string(cfg.EmbedProvider))
+	}
+}
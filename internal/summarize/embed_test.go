@@ -0,0 +1,164 @@
+package summarize
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestOllamaEmbed(t *testing.T) {
+	var prompts []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/embeddings" || r.Method != http.MethodPost {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		var req ollamaEmbedRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Fatalf("decoding request: %v", err)
+		}
+		if req.Model != "nomic-embed-text" {
+			t.Errorf("model = %q", req.Model)
+		}
+		prompts = append(prompts, req.Prompt)
+		_ = json.NewEncoder(w).Encode(map[string]any{"embedding": []float32{float32(len(req.Prompt)), 1}})
+	}))
+	defer srv.Close()
+
+	p := NewOllamaProvider(srv.URL, "nomic-embed-text", time.Second)
+	got, err := p.Embed(t.Context(), []string{"a", "bbb"})
+	if err != nil {
+		t.Fatalf("Embed: %v", err)
+	}
+	if want := [][]float32{{1, 1}, {3, 1}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	if !reflect.DeepEqual(prompts, []string{"a", "bbb"}) {
+		t.Errorf("prompts = %v", prompts)
+	}
+}
+
+func TestOllamaEmbedErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		body    string
+		wantErr string
+	}{
+		{"http error", http.StatusNotFound, `model not found`, "status 404"},
+		{"api error", http.StatusOK, `{"error":"model \"x\" not found"}`, "ollama error"},
+		{"empty embedding", http.StatusOK, `{"embedding":[]}`, "empty embedding"},
+		{"bad json", http.StatusOK, `{`, "unmarshaling response"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(tt.body))
+			}))
+			defer srv.Close()
+
+			_, err := NewOllamaProvider(srv.URL, "m", time.Second).Embed(t.Context(), []string{"x"})
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("got error %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestOpenAIEmbed(t *testing.T) {
+	var batches [][]string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/embeddings" {
+			t.Errorf("path = %q", r.URL.Path)
+		}
+		if got := r.Header.Get("Authorization"); got != "Bearer secret" {
+			t.Errorf("Authorization = %q", got)
+		}
+		var req openAIEmbedRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Fatalf("decoding request: %v", err)
+		}
+		batches = append(batches, req.Input)
+
+		// Reply in reverse order to check that indexes are honored
+		type item struct {
+			Index     int       `json:"index"`
+			Embedding []float32 `json:"embedding"`
+		}
+		var data []item
+		for i := len(req.Input) - 1; i >= 0; i-- {
+			data = append(data, item{Index: i, Embedding: []float32{float32(len(req.Input[i]))}})
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"data": data})
+	}))
+	defer srv.Close()
+
+	texts := make([]string, openAIEmbedBatchSize+2)
+	for i := range texts {
+		texts[i] = strings.Repeat("x", i+1)
+	}
+
+	e := NewOpenAIEmbedder(srv.URL+"/v1/", "secret", "text-embedding-3-small", time.Second)
+	got, err := e.Embed(t.Context(), texts)
+	if err != nil {
+		t.Fatalf("Embed: %v", err)
+	}
+	if len(batches) != 2 || len(batches[0]) != openAIEmbedBatchSize || len(batches[1]) != 2 {
+		t.Errorf("unexpected batching: %d batches", len(batches))
+	}
+	for i, v := range got {
+		if len(v) != 1 || v[0] != float32(i+1) {
+			t.Fatalf("vector %d = %v, want [%d]", i, v, i+1)
+		}
+	}
+}
+
+func TestOpenAIEmbedErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		body    string
+		wantErr string
+	}{
+		{"api error", http.StatusUnauthorized, `{"error":{"message":"invalid api key"}}`, "invalid api key"},
+		{"non-json error", http.StatusBadGateway, `upstream down`, "status 502"},
+		{"missing item", http.StatusOK, `{"data":[{"index":0,"embedding":[1]}]}`, "missing embedding for text 1"},
+		{"index out of range", http.StatusOK, `{"data":[{"index":5,"embedding":[1]}]}`, "out of range"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(tt.body))
+			}))
+			defer srv.Close()
+
+			_, err := NewOpenAIEmbedder(srv.URL, "", "m", time.Second).Embed(t.Context(), []string{"a", "b"})
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("got error %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNewEmbedder(t *testing.T) {
+	if e, err := NewEmbedder(Config{OllamaURL: "http://localhost:11434"}); err != nil {
+		t.Errorf("default provider: %v", err)
+	} else if p, ok := e.(*OllamaProvider); !ok || p.model != DefaultEmbedModel {
+		t.Errorf("default provider = %#v", e)
+	}
+	if e, err := NewEmbedder(Config{EmbedProvider: EmbedProviderOpenAI}); err != nil {
+		t.Errorf("openai provider: %v", err)
+	} else if o, ok := e.(*OpenAIEmbedder); !ok || o.baseURL != DefaultOpenAIURL || o.model != DefaultOpenAIEmbedModel {
+		t.Errorf("openai provider = %#v", e)
+	}
+	if _, err := NewEmbedder(Config{EmbedProvider: "bogus"}); err == nil {
+		t.Error("expected error for unknown provider")
+	}
+}
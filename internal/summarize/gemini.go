@@ -14,22 +14,23 @@ const geminiAPIURL = "https://generativelanguage.googleapis.com/v1beta/models/%s
 
 // GeminiProvider implements Provider using Google Gemini API.
 type GeminiProvider struct {
-	apiKey string
-	model  string
-	client *http.Client
+	apiKey         string
+	model          string
+	client         *http.Client
+	requestTimeout time.Duration
 }
 
-// NewGeminiProvider creates a new GeminiProvider.
-func NewGeminiProvider(apiKey, model string) *GeminiProvider {
+// NewGeminiProvider creates a new GeminiProvider. Each request is limited to
+// requestTimeout (DefaultRequestTimeout if zero).
+func NewGeminiProvider(apiKey, model string, requestTimeout time.Duration) *GeminiProvider {
 	if model == "" {
 		model = "gemini-2.0-flash"
 	}
 	return &GeminiProvider{
-		apiKey: apiKey,
-		model:  model,
-		client: &http.Client{
-			Timeout: 10 * time.Minute,
-		},
+		apiKey:         apiKey,
+		model:          model,
+		client:         &http.Client{},
+		requestTimeout: requestTimeout,
 	}
 }
 
@@ -61,6 +62,9 @@ type geminiError struct {
 
 // Summarize sends a prompt to Gemini and returns the response.
 func (p *GeminiProvider) Summarize(ctx context.Context, prompt string) (string, error) {
+	ctx, cancel := withRequestTimeout(ctx, p.requestTimeout)
+	defer cancel()
+
 	reqBody := geminiRequest{
 		Contents: []geminiContent{
 			{
@@ -95,7 +99,7 @@ func (p *GeminiProvider) Summarize(ctx context.Context, prompt string) (string,
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("gemini returned status %d: %s", resp.StatusCode, string(respBody))
+		return "", newStatusError("gemini", resp, respBody)
 	}
 
 	var geminiResp geminiResponse
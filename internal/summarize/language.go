@@ -0,0 +1,97 @@
+package summarize
+
+import (
+	"fmt"
+	"unicode"
+)
+
+// Script is the writing system text is predominantly written in. It stands in for the
+// language: telling Russian from English is reliable, telling English from Spanish is not.
+type Script string
+
+// ScriptUnknown means the text has too few letters or no dominant script.
+const ScriptUnknown Script = ""
+
+// knownScripts lists the scripts DetectScript recognizes.
+var knownScripts = []struct {
+	name  Script
+	table *unicode.RangeTable
+}{
+	{"Latin", unicode.Latin},
+	{"Cyrillic", unicode.Cyrillic},
+	{"Greek", unicode.Greek},
+	{"Arabic", unicode.Arabic},
+	{"Hebrew", unicode.Hebrew},
+	{"Han", unicode.Han},
+	{"Hiragana", unicode.Hiragana},
+	{"Katakana", unicode.Katakana},
+	{"Hangul", unicode.Hangul},
+	{"Devanagari", unicode.Devanagari},
+	{"Thai", unicode.Thai},
+	{"Georgian", unicode.Georgian},
+	{"Armenian", unicode.Armenian},
+}
+
+const (
+	// minScriptLetters is the number of letters needed to detect a script.
+	minScriptLetters = 3
+	// minScriptShare is the share of letters the dominant script must have.
+	minScriptShare = 0.6
+)
+
+// DetectScript returns the script most letters of text are written in, or ScriptUnknown
+// when there are fewer than minScriptLetters letters or no script reaches minScriptShare.
+func DetectScript(text string) Script {
+	counts := make(map[Script]int)
+	letters := 0
+	for _, r := range text {
+		if !unicode.IsLetter(r) {
+			continue
+		}
+		letters++
+		for _, s := range knownScripts {
+			if unicode.Is(s.table, r) {
+				counts[s.name]++
+				break
+			}
+		}
+	}
+	if letters < minScriptLetters {
+		return ScriptUnknown
+	}
+
+	best, bestCount := ScriptUnknown, 0
+	for _, s := range knownScripts {
+		if counts[s.name] > bestCount {
+			best, bestCount = s.name, counts[s.name]
+		}
+	}
+	if float64(bestCount)/float64(letters) < minScriptShare {
+		return ScriptUnknown
+	}
+	return best
+}
+
+// translationNeeded decides whether a summary should be translated to match the goal:
+// both scripts must be detected and differ. It returns the detected scripts.
+func translationNeeded(goal, summary string) (from, to Script, needed bool) {
+	to = DetectScript(goal)
+	from = DetectScript(summary)
+	if to == ScriptUnknown || from == ScriptUnknown {
+		return from, to, false
+	}
+	return from, to, from != to
+}
+
+const translatePromptTemplate = `Translate the summary below into the language this request is written in:
+%s
+
+Keep the structure, formatting, names, numbers and [#id] citations unchanged. Output only the translated summary.
+
+Summary:
+%s`
+
+// translatePrompt builds the prompt for translating summary into the goal's language.
+func translatePrompt(goal, summary string) string {
+	return fmt.Sprintf(translatePromptTemplate, goal, summary)
+}
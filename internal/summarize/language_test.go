@@ -0,0 +1,60 @@
+package summarize
+
+import "testing"
+
+func TestDetectScript(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want Script
+	}{
+		{"english", "Summarize the key decisions", "Latin"},
+		{"russian", "Главные решения за неделю", "Cyrillic"},
+		{"ukrainian", "Ключові рішення", "Cyrillic"},
+		{"greek", "Σύνοψη συζήτησης", "Greek"},
+		{"chinese", "总结主要决定", "Han"},
+		{"korean", "주요 결정 요약", "Hangul"},
+		{"arabic", "ملخص المحادثة", "Arabic"},
+		{"mostly russian with english names", "Обсудили релиз Kubernetes и бюджет на квартал", "Cyrillic"},
+		{"digits and punctuation only", "2024-01-15 !!!", ScriptUnknown},
+		{"too short", "ok", ScriptUnknown},
+		{"empty", "", ScriptUnknown},
+		{"evenly mixed", "hello привет", ScriptUnknown},
+		{"emoji ignored", "🎉🎉🎉 great news", "Latin"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DetectScript(tt.text); got != tt.want {
+				t.Errorf("DetectScript(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTranslationNeeded(t *testing.T) {
+	tests := []struct {
+		name     string
+		goal     string
+		summary  string
+		wantFrom Script
+		wantTo   Script
+		want     bool
+	}{
+		{"same script", "key points", "The team agreed", "Latin", "Latin", false},
+		{"russian summary for english goal", "key points", "Команда договорилась", "Cyrillic", "Latin", true},
+		{"english summary for russian goal", "главное", "The team agreed", "Latin", "Cyrillic", true},
+		{"short goal", "TL;DR", "Команда договорилась", "Cyrillic", "Latin", true},
+		{"goal has no letters", "???", "Команда договорилась", "Cyrillic", ScriptUnknown, false},
+		{"summary undetectable", "key points", "hello привет", ScriptUnknown, "Latin", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			from, to, got := translationNeeded(tt.goal, tt.summary)
+			if got != tt.want || from != tt.wantFrom || to != tt.wantTo {
+				t.Errorf("translationNeeded() = (%q, %q, %v), want (%q, %q, %v)", from, to, got, tt.wantFrom, tt.wantTo, tt.want)
+			}
+		})
+	}
+}
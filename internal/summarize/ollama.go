@@ -12,19 +12,20 @@ import (
 
 // OllamaProvider implements Provider using Ollama API.
 type OllamaProvider struct {
-	baseURL string
-	model   string
-	client  *http.Client
+	baseURL        string
+	model          string
+	client         *http.Client
+	requestTimeout time.Duration
 }
 
-// NewOllamaProvider creates a new OllamaProvider.
-func NewOllamaProvider(baseURL, model string) *OllamaProvider {
+// NewOllamaProvider creates a new OllamaProvider. Each request is limited to
+// requestTimeout (DefaultRequestTimeout if zero).
+func NewOllamaProvider(baseURL, model string, requestTimeout time.Duration) *OllamaProvider {
 	return &OllamaProvider{
-		baseURL: baseURL,
-		model:   model,
-		client: &http.Client{
-			Timeout: 10 * time.Minute, // LLM inference can take a long time
-		},
+		baseURL:        baseURL,
+		model:          model,
+		client:         &http.Client{},
+		requestTimeout: requestTimeout,
 	}
 }
 
@@ -42,6 +43,9 @@ type ollamaResponse struct {
 
 // Summarize sends a prompt to Ollama and returns the response.
 func (p *OllamaProvider) Summarize(ctx context.Context, prompt string) (string, error) {
+	ctx, cancel := withRequestTimeout(ctx, p.requestTimeout)
+	defer cancel()
+
 	reqBody := ollamaRequest{
 		Model:  p.model,
 		Prompt: prompt,
@@ -67,7 +71,7 @@ func (p *OllamaProvider) Summarize(ctx context.Context, prompt string) (string,
 
 	if resp.StatusCode != http.StatusOK {
 		respBody, _ := io.ReadAll(resp.Body)
-		return "", fmt.Errorf("ollama returned status %d: %s", resp.StatusCode, string(respBody))
+		return "", newStatusError("ollama", resp, respBody)
 	}
 
 	respBody, err := io.ReadAll(resp.Body)
@@ -86,3 +90,69 @@ func (p *OllamaProvider) Summarize(ctx context.Context, prompt string) (string,
 
 	return ollamaResp.Response, nil
 }
+
+type ollamaEmbedRequest struct {
+	Model  string `json:"model"`
+	Prompt string `json:"prompt"`
+}
+
+type ollamaEmbedResponse struct {
+	Embedding []float32 `json:"embedding"`
+	Error     string    `json:"error,omitempty"`
+}
+
+// Embed returns embeddings for texts using Ollama's /api/embeddings endpoint,
+// which accepts one text per request.
+func (p *OllamaProvider) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	vectors := make([][]float32, 0, len(texts))
+	for i, text := range texts {
+		vector, err := p.embedOne(ctx, text)
+		if err != nil {
+			return nil, fmt.Errorf("embedding text %d: %w", i, err)
+		}
+		vectors = append(vectors, vector)
+	}
+	return vectors, nil
+}
+
+func (p *OllamaProvider) embedOne(ctx context.Context, text string) ([]float32, error) {
+	ctx, cancel := withRequestTimeout(ctx, p.requestTimeout)
+	defer cancel()
+
+	body, err := json.Marshal(ollamaEmbedRequest{Model: p.model, Prompt: text})
+	if err != nil {
+		return nil, fmt.Errorf("marshaling request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.baseURL+"/api/embeddings", bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("creating request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("sending request: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("reading response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("ollama returned status %d: %s", resp.StatusCode, string(respBody))
+	}
+
+	var embedResp ollamaEmbedResponse
+	if err := json.Unmarshal(respBody, &embedResp); err != nil {
+		return nil, fmt.Errorf("unmarshaling response: %w", err)
+	}
+	if embedResp.Error != "" {
+		return nil, fmt.Errorf("ollama error: %s", embedResp.Error)
+	}
+	if len(embedResp.Embedding) == 0 {
+		return nil, fmt.Errorf("ollama returned an empty embedding")
+	}
+	return embedResp.Embedding, nil
+}
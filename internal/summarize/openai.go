@@ -0,0 +1,250 @@
+package summarize
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// DefaultOpenAIModel is the default chat model for OpenAI-compatible endpoints.
+const DefaultOpenAIModel = "gpt-4o-mini"
+
+// openAIEmbedBatchSize is the number of texts sent in a single embeddings request.
+const openAIEmbedBatchSize = 64
+
+// OpenAIEmbedder implements Embedder using an OpenAI-compatible /embeddings endpoint.
+type OpenAIEmbedder struct {
+	baseURL        string
+	apiKey         string
+	model          string
+	client         *http.Client
+	requestTimeout time.Duration
+}
+
+// NewOpenAIEmbedder creates a new OpenAIEmbedder. apiKey may be empty for local
+// servers that don't require authentication. Each request is limited to
+// requestTimeout (DefaultRequestTimeout if zero).
+func NewOpenAIEmbedder(baseURL, apiKey, model string, requestTimeout time.Duration) *OpenAIEmbedder {
+	return &OpenAIEmbedder{
+		baseURL:        strings.TrimSuffix(baseURL, "/"),
+		apiKey:         apiKey,
+		model:          model,
+		client:         &http.Client{},
+		requestTimeout: requestTimeout,
+	}
+}
+
+type openAIEmbedRequest struct {
+	Model string   `json:"model"`
+	Input []string `json:"input"`
+}
+
+type openAIEmbedResponse struct {
+	Data []struct {
+		Index     int       `json:"index"`
+		Embedding []float32 `json:"embedding"`
+	} `json:"data"`
+	Error *struct {
+		Message string `json:"message"`
+	} `json:"error,omitempty"`
+}
+
+// Embed returns embeddings for texts, sending them in batches.
+func (e *OpenAIEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	vectors := make([][]float32, 0, len(texts))
+	for start := 0; start < len(texts); start += openAIEmbedBatchSize {
+		end := min(start+openAIEmbedBatchSize, len(texts))
+		batch, err := e.embedBatch(ctx, texts[start:end])
+		if err != nil {
+			return nil, fmt.Errorf("embedding texts %d-%d: %w", start, end-1, err)
+		}
+		vectors = append(vectors, batch...)
+	}
+	return vectors, nil
+}
+
+func (e *OpenAIEmbedder) embedBatch(ctx context.Context, texts []string) ([][]float32, error) {
+	ctx, cancel := withRequestTimeout(ctx, e.requestTimeout)
+	defer cancel()
+
+	body, err := json.Marshal(openAIEmbedRequest{Model: e.model, Input: texts})
+	if err != nil {
+		return nil, fmt.Errorf("marshaling request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.baseURL+"/embeddings", bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("creating request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if e.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+e.apiKey)
+	}
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("sending request: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("reading response: %w", err)
+	}
+
+	var embedResp openAIEmbedResponse
+	if err := json.Unmarshal(respBody, &embedResp); err != nil {
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("embeddings endpoint returned status %d: %s", resp.StatusCode, string(respBody))
+		}
+		return nil, fmt.Errorf("unmarshaling response: %w", err)
+	}
+	if embedResp.Error != nil {
+		return nil, fmt.Errorf("embeddings API error: %s", embedResp.Error.Message)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("embeddings endpoint returned status %d: %s", resp.StatusCode, string(respBody))
+	}
+
+	// Responses carry an index per item; don't rely on their order
+	vectors := make([][]float32, len(texts))
+	for _, d := range embedResp.Data {
+		if d.Index < 0 || d.Index >= len(texts) {
+			return nil, fmt.Errorf("embedding index %d out of range", d.Index)
+		}
+		vectors[d.Index] = d.Embedding
+	}
+	for i, v := range vectors {
+		if len(v) == 0 {
+			return nil, fmt.Errorf("missing embedding for text %d", i)
+		}
+	}
+	return vectors, nil
+}
+
+// OpenAIProvider implements Provider using an OpenAI-compatible /chat/completions
+// endpoint, e.g. OpenAI itself or a local vLLM or llama.cpp server.
+type OpenAIProvider struct {
+	baseURL        string
+	apiKey         string
+	model          string
+	client         *http.Client
+	requestTimeout time.Duration
+}
+
+// NewOpenAIProvider creates a new OpenAIProvider. baseURL includes the API version,
+// like DefaultOpenAIURL; apiKey may be empty for local servers that don't require
+// authentication. Each request is limited to requestTimeout (DefaultRequestTimeout if zero).
+func NewOpenAIProvider(baseURL, apiKey, model string, requestTimeout time.Duration) *OpenAIProvider {
+	if baseURL == "" {
+		baseURL = DefaultOpenAIURL
+	}
+	if model == "" {
+		model = DefaultOpenAIModel
+	}
+	return &OpenAIProvider{
+		baseURL:        strings.TrimSuffix(baseURL, "/"),
+		apiKey:         apiKey,
+		model:          model,
+		client:         &http.Client{},
+		requestTimeout: requestTimeout,
+	}
+}
+
+type openAIChatRequest struct {
+	Model    string              `json:"model"`
+	Messages []openAIChatMessage `json:"messages"`
+	Stream   bool                `json:"stream"`
+}
+
+type openAIChatMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type openAIChatResponse struct {
+	Choices []struct {
+		Message      openAIChatMessage `json:"message"`
+		FinishReason string            `json:"finish_reason"`
+	} `json:"choices"`
+	Error *openAIError `json:"error,omitempty"`
+}
+
+type openAIError struct {
+	Message string `json:"message"`
+	Type    string `json:"type"`
+}
+
+func (e *openAIError) Error() string {
+	if e.Type == "" {
+		return e.Message
+	}
+	return fmt.Sprintf("%s (%s)", e.Message, e.Type)
+}
+
+// Summarize sends a prompt to the chat completions endpoint and returns the response.
+func (p *OpenAIProvider) Summarize(ctx context.Context, prompt string) (string, error) {
+	ctx, cancel := withRequestTimeout(ctx, p.requestTimeout)
+	defer cancel()
+
+	body, err := json.Marshal(openAIChatRequest{
+		Model:    p.model,
+		Messages: []openAIChatMessage{{Role: "user", Content: prompt}},
+	})
+	if err != nil {
+		return "", fmt.Errorf("marshaling request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.baseURL+"/chat/completions", bytes.NewReader(body))
+	if err != nil {
+		return "", fmt.Errorf("creating request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if p.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+p.apiKey)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("sending request: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("reading response: %w", err)
+	}
+
+	// Error envelopes come with a non-200 status; prefer their message over the raw body
+	var chatResp openAIChatResponse
+	if err := json.Unmarshal(respBody, &chatResp); err != nil {
+		if resp.StatusCode != http.StatusOK {
+			return "", newStatusError("openai", resp, respBody)
+		}
+		return "", fmt.Errorf("unmarshaling response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		statusErr := newStatusError("openai", resp, respBody)
+		if chatResp.Error != nil {
+			statusErr.Body = chatResp.Error.Error()
+		}
+		return "", statusErr
+	}
+	if chatResp.Error != nil {
+		return "", fmt.Errorf("openai: %w", chatResp.Error)
+	}
+
+	if len(chatResp.Choices) == 0 {
+		return "", fmt.Errorf("no choices in response")
+	}
+	content := chatResp.Choices[0].Message.Content
+	if content == "" {
+		return "", fmt.Errorf("empty response (finish reason %q)", chatResp.Choices[0].FinishReason)
+	}
+	return content, nil
+}
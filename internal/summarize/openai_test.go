@@ -0,0 +1,74 @@
+package summarize
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestOpenAISummarize(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/chat/completions" || r.Method != http.MethodPost {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		if got := r.Header.Get("Authorization"); got != "Bearer secret" {
+			t.Errorf("Authorization = %q", got)
+		}
+		var req openAIChatRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Fatalf("decoding request: %v", err)
+		}
+		if req.Model != "local-model" || req.Stream {
+			t.Errorf("model = %q, stream = %v", req.Model, req.Stream)
+		}
+		if len(req.Messages) != 1 || req.Messages[0].Role != "user" || req.Messages[0].Content != "summarize this" {
+			t.Errorf("messages = %+v", req.Messages)
+		}
+		_, _ = w.Write([]byte(`{"choices":[{"index":0,"message":{"role":"assistant","content":"the summary"},"finish_reason":"stop"}]}`))
+	}))
+	defer srv.Close()
+
+	p := NewOpenAIProvider(srv.URL+"/v1/", "secret", "local-model", time.Second)
+	got, err := p.Summarize(t.Context(), "summarize this")
+	if err != nil {
+		t.Fatalf("Summarize: %v", err)
+	}
+	if got != "the summary" {
+		t.Errorf("got %q, want %q", got, "the summary")
+	}
+}
+
+func TestOpenAISummarizeErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		body    string
+		wantErr string
+	}{
+		{"error envelope", http.StatusUnauthorized, `{"error":{"message":"Incorrect API key provided","type":"invalid_request_error"}}`, "status 401: Incorrect API key provided (invalid_request_error)"},
+		{"error envelope without type", http.StatusNotFound, `{"error":{"message":"The model does not exist"}}`, "status 404: The model does not exist"},
+		{"error envelope with 200", http.StatusOK, `{"error":{"message":"context length exceeded"}}`, "openai: context length exceeded"},
+		{"http error", http.StatusBadGateway, `upstream unavailable`, "status 502"},
+		{"no choices", http.StatusOK, `{"choices":[]}`, "no choices"},
+		{"empty content", http.StatusOK, `{"choices":[{"message":{"role":"assistant","content":""},"finish_reason":"length"}]}`, `finish reason "length"`},
+		{"bad json", http.StatusOK, `{`, "unmarshaling response"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(tt.body))
+			}))
+			defer srv.Close()
+
+			_, err := NewOpenAIProvider(srv.URL, "", "", time.Second).Summarize(t.Context(), "prompt")
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("err = %v, want containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -3,6 +3,7 @@ package summarize
 import (
 	"context"
 	"fmt"
+	"time"
 )
 
 // Provider is an interface for LLM providers that can summarize text.
@@ -18,27 +19,60 @@ const (
 	ProviderOllama    ProviderName = "ollama"
 	ProviderGemini    ProviderName = "gemini"
 	ProviderAnthropic ProviderName = "anthropic"
+	ProviderOpenAI    ProviderName = "openai"
 )
 
 // ValidateProviderName checks if the provider name is valid.
 func ValidateProviderName(name string) error {
 	switch ProviderName(name) {
-	case ProviderSampling, ProviderOllama, ProviderGemini, ProviderAnthropic:
+	case ProviderSampling, ProviderOllama, ProviderGemini, ProviderAnthropic, ProviderOpenAI:
 		return nil
 	default:
-		return fmt.Errorf("invalid provider: %q (must be 'sampling', 'ollama', 'gemini', or 'anthropic')", name)
+		return fmt.Errorf("invalid provider: %q (must be 'sampling', 'ollama', 'gemini', 'anthropic', or 'openai')", name)
 	}
 }
 
 // Config holds configuration for summarization providers.
 type Config struct {
-	Provider        ProviderName // "sampling", "ollama", "gemini", or "anthropic"
-	Model           string       // provider-specific model name
-	OllamaURL       string       // URL for Ollama API
-	GeminiAPIKey    string       // API key for Gemini
-	AnthropicAPIKey string       // API key for Anthropic
-	BatchTokens     int          // approximate number of tokens per batch for summarization
+	Provider        ProviderName      // "sampling", "ollama", "gemini", "anthropic", or "openai"
+	Model           string            // provider-specific model name
+	OllamaURL       string            // URL for Ollama API
+	GeminiAPIKey    string            // API key for Gemini
+	AnthropicAPIKey string            // API key for Anthropic
+	OpenAIAPIKey    string            // API key for the OpenAI-compatible chat API
+	OpenAIBaseURL   string            // base URL of the OpenAI-compatible chat API
+	BatchTokens     int               // approximate number of tokens per batch for summarization
+	MergeGap        time.Duration     // max gap between merged consecutive messages from one sender
+	RequestTimeout  time.Duration     // limit for a single LLM call
+	RetryAttempts   int               // attempts per LLM call of HTTP providers (DefaultRetryAttempts if zero)
+	RetryBaseDelay  time.Duration     // delay before the first retry, doubling per retry (DefaultRetryBaseDelay if zero)
+	Deadline        time.Duration     // limit for a whole summarization run; a partial summary is returned when hit
+	EmbedProvider   EmbedProviderName // "ollama" or "openai"
+	EmbedModel      string            // embedding model name
+	EmbedURL        string            // base URL of the OpenAI-compatible embeddings API
+	EmbedAPIKey     string            // API key for the OpenAI-compatible embeddings API
+
+	TranscribeProvider TranscribeProviderName // "telegram" or "openai"
+	TranscribeModel    string                 // transcription model name
+	TranscribeURL      string                 // base URL of the OpenAI-compatible transcription API
+	TranscribeAPIKey   string                 // API key for the OpenAI-compatible transcription API
 }
 
 // DefaultBatchTokens is the default number of tokens per batch.
 const DefaultBatchTokens = 8000
+
+const (
+	// DefaultRequestTimeout is the default limit for a single LLM call.
+	// LLM inference can take a long time.
+	DefaultRequestTimeout = 10 * time.Minute
+	// DefaultDeadline is the default limit for a whole summarization run.
+	DefaultDeadline = 30 * time.Minute
+)
+
+// withRequestTimeout limits ctx to a single LLM call's timeout (DefaultRequestTimeout if zero).
+func withRequestTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		timeout = DefaultRequestTimeout
+	}
+	return context.WithTimeout(ctx, timeout)
+}
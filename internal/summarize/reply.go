@@ -0,0 +1,128 @@
+package summarize
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/tolmachov/mcp-telegram/internal/messages"
+)
+
+// DefaultReplySuggestions is how many replies SuggestReplies asks for by default.
+const DefaultReplySuggestions = 3
+
+// replyPromptTemplate asks for replies the user could send next in a chat.
+const replyPromptTemplate = `You are suggesting replies the user could send next in a Telegram chat.
+
+The user's own messages are those of %q.
+%s
+Recent messages of the chat, oldest first:
+%s
+
+Instructions:
+` + untrustedInstruction + `
+- Suggest %d different replies the user could send next, answering what is still open in the conversation
+- Match the tone, formality and usual message length of the user's own messages; without any, match the chat
+- Write in the same language as the conversation
+- Write each reply exactly as it would be sent, without quotes, explanations or sender names
+- Reply with only a numbered list, one reply per item
+
+Suggested replies:`
+
+// suggestionMarker matches the number or bullet of a list item.
+var suggestionMarker = regexp.MustCompile(`^(?:\d+[.)]|[-*•])\s+`)
+
+// ReplyOptions controls a SuggestReplies run.
+type ReplyOptions struct {
+	Suggestions int    // replies to ask for (DefaultReplySuggestions if zero)
+	Hint        string // what the user wants to say, if anything
+}
+
+// SuggestReplies asks the provider for replies the user, sender selfID, could send
+// next after msgs, which are in chronological order. When the messages exceed the
+// batch size, the oldest are left out.
+func (s *Summarizer) SuggestReplies(ctx context.Context, msgs []messages.Message, selfID int64, opts ReplyOptions) ([]string, error) {
+	suggestions := opts.Suggestions
+	if suggestions <= 0 {
+		suggestions = DefaultReplySuggestions
+	}
+
+	recent := recentWithinTokens(messages.FilterTextOnly(msgs), s.batchTokens)
+	if len(recent) == 0 {
+		return nil, errors.New("no text messages to reply to")
+	}
+
+	var hint string
+	if opts.Hint != "" {
+		hint = fmt.Sprintf("What the user wants to say: %s\n", opts.Hint)
+	}
+	prompt := fmt.Sprintf(replyPromptTemplate, messages.EscapeDataBoundary(selfLabel(recent, selfID)), hint, dataBlock(messages.FormatBatchForSummary(recent)), suggestions)
+	text, err := s.summarizeWithProgress(ctx, prompt, 1, 1, "", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	replies := parseSuggestions(text)
+	if len(replies) == 0 {
+		return nil, errors.New("the model returned no suggestions")
+	}
+	if len(replies) > suggestions {
+		replies = replies[:suggestions]
+	}
+	return replies, nil
+}
+
+// recentWithinTokens returns the newest of msgs that fit in maxTokens, keeping at
+// least the last one.
+func recentWithinTokens(msgs []messages.Message, maxTokens int) []messages.Message {
+	tokens := 0
+	for i := len(msgs) - 1; i >= 0; i-- {
+		tokens += estimateTokens(messages.FormatForSummary(msgs[i]))
+		if tokens > maxTokens && i < len(msgs)-1 {
+			return msgs[i+1:]
+		}
+	}
+	return msgs
+}
+
+// selfLabel returns the user's sender label in msgs, as the batch formatter writes it.
+func selfLabel(msgs []messages.Message, selfID int64) string {
+	for _, msg := range msgs {
+		if msg.SenderID == selfID {
+			return messages.SenderLabel(selfID, msg.SenderName)
+		}
+	}
+	return messages.SenderLabel(selfID, "")
+}
+
+// parseSuggestions splits a model's numbered list into its items. Lines without a
+// marker continue the item before them; text without any list is one suggestion.
+func parseSuggestions(text string) []string {
+	var items []string
+	for _, line := range strings.Split(strings.TrimSpace(text), "\n") {
+		line = strings.TrimSpace(line)
+		if marker := suggestionMarker.FindString(line); marker != "" {
+			items = append(items, strings.TrimPrefix(line, marker))
+			continue
+		}
+		if line == "" || len(items) == 0 {
+			continue
+		}
+		items[len(items)-1] += "\n" + line
+	}
+	if len(items) == 0 {
+		if text = strings.TrimSpace(text); text != "" {
+			items = append(items, text)
+		}
+	}
+
+	replies := items[:0]
+	for _, item := range items {
+		if item = strings.Trim(strings.TrimSpace(item), `"«»“”`); item != "" {
+			replies = append(replies, item)
+		}
+	}
+	return replies
+}
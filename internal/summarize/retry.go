@@ -0,0 +1,144 @@
+package summarize
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+const (
+	// DefaultRetryAttempts is the default number of attempts of a single LLM call.
+	DefaultRetryAttempts = 4
+	// DefaultRetryBaseDelay is the default delay before the first retry; it doubles
+	// with every further one.
+	DefaultRetryBaseDelay = 2 * time.Second
+	// maxRetryDelay caps the backoff and Retry-After delays between two attempts.
+	maxRetryDelay = 2 * time.Minute
+)
+
+// StatusError is an unexpected HTTP status returned by a provider's API.
+type StatusError struct {
+	Provider   string
+	StatusCode int
+	Body       string
+	RetryAfter time.Duration // from the Retry-After header, zero if absent
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("%s returned status %d: %s", e.Provider, e.StatusCode, e.Body)
+}
+
+// newStatusError returns the StatusError of resp with its already read body.
+func newStatusError(provider string, resp *http.Response, body []byte) *StatusError {
+	return &StatusError{
+		Provider:   provider,
+		StatusCode: resp.StatusCode,
+		Body:       string(body),
+		RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()),
+	}
+}
+
+// parseRetryAfter parses a Retry-After header given in seconds or as an HTTP date.
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if t, err := http.ParseTime(value); err == nil && t.After(now) {
+		return t.Sub(now)
+	}
+	return 0
+}
+
+// RetryPolicy controls how failed LLM calls are retried.
+type RetryPolicy struct {
+	MaxAttempts int           // attempts per call including the first (DefaultRetryAttempts if zero)
+	BaseDelay   time.Duration // delay before the first retry (DefaultRetryBaseDelay if zero)
+}
+
+// retryProvider retries the calls of a Provider that fail transiently.
+type retryProvider struct {
+	provider Provider
+	policy   RetryPolicy
+}
+
+// WithRetry wraps an HTTP provider so that calls failing with 408, 429 or 5xx
+// statuses or transient network errors are retried with exponential backoff and
+// jitter, waiting at least as long as a Retry-After header asks.
+func WithRetry(p Provider, policy RetryPolicy) Provider {
+	if policy.MaxAttempts <= 0 {
+		policy.MaxAttempts = DefaultRetryAttempts
+	}
+	if policy.BaseDelay <= 0 {
+		policy.BaseDelay = DefaultRetryBaseDelay
+	}
+	return &retryProvider{provider: p, policy: policy}
+}
+
+// Summarize calls the wrapped provider until it succeeds, fails permanently, the
+// attempts run out or ctx is done.
+func (r *retryProvider) Summarize(ctx context.Context, prompt string) (string, error) {
+	for attempt := 1; ; attempt++ {
+		summary, err := r.provider.Summarize(ctx, prompt)
+		if err == nil {
+			return summary, nil
+		}
+		if !retryable(err) || ctx.Err() != nil {
+			if attempt > 1 {
+				return "", fmt.Errorf("after %d attempts: %w", attempt, err)
+			}
+			return "", err
+		}
+		if attempt >= r.policy.MaxAttempts {
+			return "", fmt.Errorf("giving up after %d attempts: %w", attempt, err)
+		}
+
+		timer := time.NewTimer(r.delay(attempt, err))
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return "", fmt.Errorf("after %d attempts: %w (retry canceled: %w)", attempt, err, ctx.Err())
+		}
+	}
+}
+
+// delay returns the wait before the retry following attempt: the base delay doubled
+// per attempt with up to half of it as jitter, or the server's Retry-After if longer.
+func (r *retryProvider) delay(attempt int, err error) time.Duration {
+	backoff := min(r.policy.BaseDelay<<(attempt-1), maxRetryDelay)
+	backoff = backoff/2 + rand.N(backoff/2+1)
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) && statusErr.RetryAfter > backoff {
+		return min(statusErr.RetryAfter, maxRetryDelay)
+	}
+	return backoff
+}
+
+// retryable reports whether err is worth retrying: a 408, 429 or 5xx status, or a
+// network error other than the request's own timeout or cancellation.
+func retryable(err error) bool {
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		code := statusErr.StatusCode
+		return code == http.StatusRequestTimeout || code == http.StatusTooManyRequests || code >= 500
+	}
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	if errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, io.EOF) ||
+		errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.ECONNREFUSED) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
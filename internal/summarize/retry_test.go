@@ -0,0 +1,123 @@
+package summarize
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWithRetry(t *testing.T) {
+	tests := []struct {
+		name      string
+		statuses  []int // statuses answered in turn; the last one repeats
+		wantCalls int32
+		wantErr   string
+	}{
+		{"success", []int{http.StatusOK}, 1, ""},
+		{"rate limited then success", []int{http.StatusTooManyRequests, http.StatusOK}, 2, ""},
+		{"overloaded then success", []int{529, http.StatusBadGateway, http.StatusOK}, 3, ""},
+		{"request timeout then success", []int{http.StatusRequestTimeout, http.StatusOK}, 2, ""},
+		{"exhausted", []int{http.StatusServiceUnavailable}, 3, "giving up after 3 attempts: openai returned status 503"},
+		{"not retryable", []int{http.StatusBadRequest}, 1, "openai returned status 400"},
+		{"permanent after retry", []int{http.StatusTooManyRequests, http.StatusUnauthorized}, 2, "after 2 attempts: openai returned status 401"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls atomic.Int32
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				n := int(calls.Add(1))
+				status := tt.statuses[min(n, len(tt.statuses))-1]
+				w.WriteHeader(status)
+				if status == http.StatusOK {
+					_, _ = w.Write([]byte(`{"choices":[{"message":{"role":"assistant","content":"summary"}}]}`))
+				}
+			}))
+			defer srv.Close()
+
+			p := NewOpenAIProvider(srv.URL, "", "", time.Second)
+			got, err := WithRetry(p, RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond}).Summarize(t.Context(), "prompt")
+			if tt.wantErr == "" {
+				if err != nil || got != "summary" {
+					t.Errorf("got %q, %v; want summary", got, err)
+				}
+			} else if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("err = %v, want containing %q", err, tt.wantErr)
+			}
+			if calls.Load() != tt.wantCalls {
+				t.Errorf("calls = %d, want %d", calls.Load(), tt.wantCalls)
+			}
+		})
+	}
+}
+
+// errProvider fails every call with err.
+type errProvider struct {
+	err   error
+	calls int
+}
+
+func (p *errProvider) Summarize(context.Context, string) (string, error) {
+	p.calls++
+	return "", p.err
+}
+
+func TestWithRetryHonorsRetryAfterAndContext(t *testing.T) {
+	p := &errProvider{err: &StatusError{Provider: "gemini", StatusCode: http.StatusTooManyRequests, RetryAfter: time.Hour}}
+	ctx, cancel := context.WithTimeout(t.Context(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := WithRetry(p, RetryPolicy{MaxAttempts: 5, BaseDelay: time.Millisecond}).Summarize(ctx, "prompt")
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("err = %v, want the context's deadline", err)
+	}
+	if p.calls != 1 {
+		t.Errorf("calls = %d, want 1: Retry-After should outweigh the base delay", p.calls)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("waited %v after the context ended", elapsed)
+	}
+}
+
+func TestWithRetryNetworkErrors(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+	url := srv.URL
+	srv.Close() // connections are refused from now on
+
+	p := NewOllamaProvider(url, "model", time.Second)
+	_, err := WithRetry(p, RetryPolicy{MaxAttempts: 2, BaseDelay: time.Millisecond}).Summarize(t.Context(), "prompt")
+	if err == nil || !strings.Contains(err.Error(), "giving up after 2 attempts") {
+		t.Errorf("err = %v, want retried network error", err)
+	}
+
+	canceled := &errProvider{err: context.Canceled}
+	if _, err := WithRetry(canceled, RetryPolicy{}).Summarize(t.Context(), "prompt"); !errors.Is(err, context.Canceled) || canceled.calls != 1 {
+		t.Errorf("err = %v after %d calls, want cancellation without retries", err, canceled.calls)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	tests := []struct {
+		value string
+		want  time.Duration
+	}{
+		{"", 0},
+		{"30", 30 * time.Second},
+		{"-1", 0},
+		{now.Add(90 * time.Second).Format(http.TimeFormat), 90 * time.Second},
+		{now.Add(-time.Minute).Format(http.TimeFormat), 0},
+		{"soon", 0},
+	}
+	for _, tt := range tests {
+		if got := parseRetryAfter(tt.value, now); got != tt.want {
+			t.Errorf("parseRetryAfter(%q) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+}
@@ -3,6 +3,7 @@ package summarize
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
@@ -10,16 +11,21 @@ import (
 
 // SamplingProvider implements Provider using MCP Sampling.
 type SamplingProvider struct {
-	mcpServer *server.MCPServer
+	mcpServer      *server.MCPServer
+	requestTimeout time.Duration
 }
 
-// NewSamplingProvider creates a new SamplingProvider.
-func NewSamplingProvider(mcpServer *server.MCPServer) *SamplingProvider {
-	return &SamplingProvider{mcpServer: mcpServer}
+// NewSamplingProvider creates a new SamplingProvider. Each request is limited to
+// requestTimeout (DefaultRequestTimeout if zero).
+func NewSamplingProvider(mcpServer *server.MCPServer, requestTimeout time.Duration) *SamplingProvider {
+	return &SamplingProvider{mcpServer: mcpServer, requestTimeout: requestTimeout}
 }
 
 // Summarize sends a prompt via MCP Sampling and returns the response.
 func (p *SamplingProvider) Summarize(ctx context.Context, prompt string) (string, error) {
+	ctx, cancel := withRequestTimeout(ctx, p.requestTimeout)
+	defer cancel()
+
 	samplingRequest := mcp.CreateMessageRequest{
 		CreateMessageParams: mcp.CreateMessageParams{
 			Messages: []mcp.SamplingMessage{
@@ -0,0 +1,99 @@
+package summarize
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// structuredPromptTemplate replaces promptTemplate in structured mode. The running
+// summary is the JSON object itself, so each batch updates it.
+const structuredPromptTemplate = `You are summarizing a Telegram chat conversation into structured data.
+
+User's goal for this summary:
+%s
+
+Current summary so far (a JSON object, empty at the start):
+%s
+
+New messages to incorporate:
+%s
+
+Instructions:
+` + untrustedInstruction + `
+- Update the current summary with the new messages, keeping what is still relevant to the user's goal
+- Reply with only a JSON object, without code fences or any other text, in this shape:
+  {"topics": ["..."], "decisions": ["..."], "action_items": [{"task": "...", "assignee": "...", "deadline": "..."}], "open_questions": ["..."]}
+- Leave out an action item's assignee or deadline when the messages don't state it
+- Use an empty array for a section with nothing in it
+- Write the values in the same language as the messages
+%s
+JSON object:`
+
+// repairPromptTemplate asks the model to fix a structured summary that didn't parse.
+const repairPromptTemplate = `The following text should be a single JSON object with the keys "topics", "decisions", "action_items" (objects with "task" and optionally "assignee" and "deadline") and "open_questions", but it is not valid: %s
+
+%s
+
+Reply with only the corrected JSON object, keeping its content, without code fences or any other text.`
+
+// StructuredSummary is the summary of structured mode.
+type StructuredSummary struct {
+	Topics        []string     `json:"topics"`
+	Decisions     []string     `json:"decisions"`
+	ActionItems   []ActionItem `json:"action_items"`
+	OpenQuestions []string     `json:"open_questions"`
+}
+
+// ActionItem is a task found in the chat.
+type ActionItem struct {
+	Task     string `json:"task"`
+	Assignee string `json:"assignee,omitempty"`
+	Deadline string `json:"deadline,omitempty"`
+}
+
+// ParseStructured parses a model's structured summary. Code fences and text around
+// the JSON object are ignored; sections the model left out are empty.
+func ParseStructured(text string) (*StructuredSummary, error) {
+	start := strings.Index(text, "{")
+	end := strings.LastIndex(text, "}")
+	if start < 0 || end < start {
+		return nil, errors.New("no JSON object found")
+	}
+	var summary StructuredSummary
+	if err := json.Unmarshal([]byte(text[start:end+1]), &summary); err != nil {
+		return nil, err
+	}
+
+	// Empty sections render as [] rather than null
+	if summary.Topics == nil {
+		summary.Topics = []string{}
+	}
+	if summary.Decisions == nil {
+		summary.Decisions = []string{}
+	}
+	if summary.ActionItems == nil {
+		summary.ActionItems = []ActionItem{}
+	}
+	if summary.OpenQuestions == nil {
+		summary.OpenQuestions = []string{}
+	}
+	return &summary, nil
+}
+
+// rewriteTexts replaces every free-text value of the summary with f's result.
+func (st *StructuredSummary) rewriteTexts(f func(string) string) {
+	for _, section := range [][]string{st.Topics, st.Decisions, st.OpenQuestions} {
+		for i := range section {
+			section[i] = f(section[i])
+		}
+	}
+	for i := range st.ActionItems {
+		st.ActionItems[i].Task = f(st.ActionItems[i].Task)
+	}
+}
+
+func repairPrompt(text string, parseErr error) string {
+	return fmt.Sprintf(repairPromptTemplate, parseErr, text)
+}
@@ -0,0 +1,89 @@
+package summarize
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSummarizeMessagesStructured(t *testing.T) {
+	msgs := chattyMessages(1)
+	const valid = `{"topics":["release"],"decisions":["ship on Friday"],"action_items":[{"task":"write notes","assignee":"Ann","deadline":"Thursday"}],"open_questions":[]}`
+	want := &StructuredSummary{
+		Topics:        []string{"release"},
+		Decisions:     []string{"ship on Friday"},
+		ActionItems:   []ActionItem{{Task: "write notes", Assignee: "Ann", Deadline: "Thursday"}},
+		OpenQuestions: []string{},
+	}
+
+	tests := []struct {
+		name       string
+		replies    []string
+		wantCalls  int
+		wantParsed bool
+	}{
+		{"valid", []string{valid}, 1, true},
+		{"fenced", []string{"```json\n" + valid + "\n```"}, 1, true},
+		{"repaired", []string{`{"topics": ["release"],`, valid}, 2, true},
+		{"repair fails", []string{"Topics: release", "still not JSON"}, 2, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider := &scriptedProvider{replies: tt.replies}
+			s := NewSummarizer(provider, nil, DefaultBatchTokens)
+			result, err := s.SummarizeMessages(context.Background(), msgs, Options{Goal: "action items", Structured: true, AutoTranslate: true}, nil)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(provider.prompts) != tt.wantCalls {
+				t.Errorf("provider calls = %d, want %d", len(provider.prompts), tt.wantCalls)
+			}
+			if !strings.Contains(provider.prompts[0], `"action_items"`) {
+				t.Errorf("prompt does not ask for JSON: %q", provider.prompts[0])
+			}
+
+			if !tt.wantParsed {
+				if result.Structured != nil || !result.Stats.StructuredFailed {
+					t.Errorf("got structured %+v, StructuredFailed %v; want a text fallback", result.Structured, result.Stats.StructuredFailed)
+				}
+				if result.Summary != tt.replies[0] {
+					t.Errorf("summary = %q, want the unrepaired text", result.Summary)
+				}
+				return
+			}
+			if !reflect.DeepEqual(result.Structured, want) {
+				t.Errorf("structured = %+v, want %+v", result.Structured, want)
+			}
+			if tt.wantCalls == 2 && !strings.Contains(provider.prompts[1], "not valid") {
+				t.Errorf("second prompt is not a repair prompt: %q", provider.prompts[1])
+			}
+		})
+	}
+}
+
+func TestSummarizeMessagesStructuredCitations(t *testing.T) {
+	msgs := chattyMessages(1)
+	provider := &scriptedProvider{replies: []string{`{"topics":["release [#1]"],"decisions":["ship [#1, #999]"],"action_items":[{"task":"notes [#999]"}]}`}}
+	s := NewSummarizer(provider, nil, DefaultBatchTokens)
+	result, err := s.SummarizeMessages(context.Background(), msgs, Options{Goal: "key points", Structured: true, Citations: true}, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Structured == nil {
+		t.Fatalf("no structured summary: %q", result.Summary)
+	}
+	if got := result.Structured.Decisions[0]; got != "ship [#1]" {
+		t.Errorf("decision = %q, want the invalid citation removed", got)
+	}
+	if got := result.Structured.ActionItems[0].Task; got != "notes" {
+		t.Errorf("task = %q, want the invalid citation removed", got)
+	}
+	if result.Stats.Citations != 2 || result.Stats.InvalidCitations != 2 {
+		t.Errorf("citations = %d valid, %d invalid; want 2 and 2", result.Stats.Citations, result.Stats.InvalidCitations)
+	}
+	if result.Structured.OpenQuestions == nil {
+		t.Error("missing sections should be empty, not nil")
+	}
+}
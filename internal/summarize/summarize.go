@@ -2,6 +2,8 @@ package summarize
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"strings"
 	"time"
@@ -12,6 +14,14 @@ import (
 
 const batchSize = 50
 
+// aliasInstructions explain the sender aliases of messages.SenderAliases.
+const aliasInstructions = `- Some senders appear by an alias such as P1, listed with their names at the start of the data block: call them by their names
+`
+
+// anonymizeInstructions replace aliasInstructions when senders are anonymized.
+const anonymizeInstructions = `- Senders appear as Person A, Person B and so on: call people only that way, and leave out names, usernames and phone numbers found in the messages
+`
+
 const promptTemplate = `You are summarizing a Telegram chat conversation.
 
 User's goal for this summary:
@@ -24,6 +34,7 @@ New messages to incorporate:
 %s
 
 Instructions:
+` + untrustedInstruction + `
 - Focus on information relevant to the user's goal
 - Identify key topics and themes discussed
 - Note important decisions or conclusions
@@ -31,9 +42,111 @@ Instructions:
 - Keep the summary concise but comprehensive
 - Write in the same language as the messages
 - Output as plain text (markdown allowed)
-
+%s
 Updated summary:`
 
+// Options controls a single summarization run.
+type Options struct {
+	Goal  string    // what the user wants from the summary
+	Since time.Time // only messages after this time are summarized
+	Until time.Time // only messages up to this instant are summarized (no limit if zero)
+	// MinID fetches only messages with a greater ID, e.g. the chat's read inbox position
+	// to summarize what arrived since the user last read it.
+	MinID int
+	// ThreadID summarizes the comments on this channel post, or the replies in this
+	// thread of a group, instead of the chat's history.
+	ThreadID int
+
+	// MergeConsecutive merges rapid-fire messages from the same sender into one
+	// logical message before batching, saving tokens and keeping thoughts together.
+	MergeConsecutive bool
+	// MergeGap is the maximum gap between merged messages (messages.DefaultMergeGap if zero).
+	MergeGap time.Duration
+
+	// Deadline limits the summarization of all batches (no limit if zero). When it is
+	// reached, the summary of the batches completed so far is returned with a banner.
+	Deadline time.Duration
+
+	// AutoTranslate runs one extra provider call to translate the summary when it came
+	// back in a different script than the goal was written in.
+	AutoTranslate bool
+
+	// Structured asks for a StructuredSummary instead of free text. Malformed JSON is
+	// sent back to the model once for repair; AutoTranslate doesn't apply.
+	Structured bool
+
+	// Citations asks the model to cite message IDs after factual claims. Cited IDs
+	// that weren't among the summarized messages are removed.
+	Citations bool
+	// CitationLink turns a cited message ID into a link. When nil, the links of the
+	// summarized messages are used; when that or CitationLink gives "", the bare ID is kept.
+	CitationLink func(msgID int) string
+
+	// AnonymizeSenders names senders Person A, Person B and so on instead of by their
+	// names, and asks the model to leave out the names it finds in the messages.
+	AnonymizeSenders bool
+}
+
+// Stats describes how a summary was produced.
+type Stats struct {
+	Messages       int    // text messages summarized, after merging
+	Batches        int    // batches summarized
+	TotalBatches   int    // batches the messages were split into
+	Partial        bool   // the deadline was reached before all batches were summarized
+	Translated     bool   // a translation pass converted the summary to the goal's language
+	TranslatedFrom Script // script of the summary before translation
+	TranslatedTo   Script // script of the goal
+
+	// TranslationFailed means a translation was needed but the extra call failed,
+	// so the summary is left in its original language.
+	TranslationFailed bool
+
+	// First and Last are the dates of the oldest and newest summarized messages,
+	// the range the summary actually covers.
+	First, Last time.Time
+
+	Unread int // incoming messages after MinID covered by the summary
+	LastID int // newest message fetched from the chat
+
+	Citations        int // valid message IDs cited in citation mode
+	InvalidCitations int // cited IDs that weren't among the messages, removed
+
+	// StructuredFailed means the model's structured summary didn't parse even after a
+	// repair attempt, so the summary is returned as text.
+	StructuredFailed bool
+}
+
+// String renders the stats as a short one-line note.
+func (st Stats) String() string {
+	note := fmt.Sprintf("%d messages, %d/%d batches", st.Messages, st.Batches, st.TotalBatches)
+	if st.Partial {
+		note += ", partial"
+	}
+	if st.Translated {
+		note += fmt.Sprintf(", translated to the goal's language (%s → %s)", st.TranslatedFrom, st.TranslatedTo)
+	}
+	if st.TranslationFailed {
+		note += fmt.Sprintf(", translation to the goal's language failed (%s → %s)", st.TranslatedFrom, st.TranslatedTo)
+	}
+	if st.Citations > 0 || st.InvalidCitations > 0 {
+		note += fmt.Sprintf(", %d citations", st.Citations)
+		if st.InvalidCitations > 0 {
+			note += fmt.Sprintf(" (%d invalid removed)", st.InvalidCitations)
+		}
+	}
+	if st.StructuredFailed {
+		note += ", structured output failed"
+	}
+	return note
+}
+
+// Result is the outcome of a summarization run.
+type Result struct {
+	Summary    string
+	Structured *StructuredSummary // in structured mode, unless Stats.StructuredFailed
+	Stats      Stats
+}
+
 // ProgressCallback is called with the current batch number, total batches, and a message.
 type ProgressCallback func(current, total int, message string)
 
@@ -57,69 +170,258 @@ func NewSummarizer(provider Provider, msgProvider *messages.Provider, batchToken
 }
 
 // Summarize performs rolling summarization of a chat.
-func (s *Summarizer) Summarize(ctx context.Context, chatID int64, goal string, since time.Time, onProgress ProgressCallback) (string, error) {
+func (s *Summarizer) Summarize(ctx context.Context, chatID int64, opts Options, onProgress ProgressCallback) (Result, error) {
 	// Fetch all messages since the given time
-	opts := messages.FetchOptions{
-		Limit:   batchSize,
-		MinDate: since,
+	fetchOpts := messages.FetchOptions{
+		Limit:          batchSize,
+		MinDate:        opts.Since,
+		MaxDate:        opts.Until,
+		MaxDateExact:   true,
+		MinID:          opts.MinID,
+		ThreadID:       opts.ThreadID,
+		GroupAlbums:    true,
+		ResolveReplies: true,
 	}
-	result, err := s.msgProvider.FetchAll(ctx, chatID, opts, nil)
+	result, err := s.msgProvider.FetchAll(ctx, chatID, fetchOpts, nil)
 	if err != nil {
-		return "", fmt.Errorf("fetching messages: %w", err)
+		return Result{}, fmt.Errorf("fetching messages: %w", err)
 	}
 
 	if len(result.Messages) == 0 {
-		return "No messages found in the specified period.", nil
+		return Result{Summary: "No messages found in the specified period."}, nil
 	}
+	lastID := result.Messages[0].ID
+	unread := messages.CountUnread(result.Messages, opts.MinID)
 
 	// Reverse to chronological order (FetchAll returns reverse chronological)
 	messages.Reverse(result.Messages)
 
+	res, err := s.SummarizeMessages(ctx, result.Messages, opts, onProgress)
+	res.Stats.LastID = lastID
+	if opts.MinID > 0 {
+		res.Stats.Unread = unread
+	}
+	return res, err
+}
+
+// SummarizeMessages performs rolling summarization of already loaded messages in
+// chronological order, e.g. from an offline export. Messages before opts.Since or
+// after opts.Until are skipped.
+func (s *Summarizer) SummarizeMessages(ctx context.Context, msgs []messages.Message, opts Options, onProgress ProgressCallback) (Result, error) {
+	if !opts.Since.IsZero() || !opts.Until.IsZero() {
+		inWindow := make([]messages.Message, 0, len(msgs))
+		for _, msg := range msgs {
+			if msg.Date.Before(opts.Since) || (!opts.Until.IsZero() && msg.Date.After(opts.Until)) {
+				continue
+			}
+			inWindow = append(inWindow, msg)
+		}
+		msgs = inWindow
+	}
+	if len(msgs) == 0 {
+		return Result{Summary: "No messages found in the specified period."}, nil
+	}
+
+	// Remember every message that may be cited, including ones merged into others,
+	// and the links of those that have one
+	known := make(map[int]bool, len(msgs))
+	links := make(map[int]string)
+	for _, msg := range msgs {
+		known[msg.ID] = true
+		if msg.Link != "" {
+			links[msg.ID] = msg.Link
+		}
+	}
+	if opts.CitationLink == nil && len(links) > 0 {
+		opts.CitationLink = func(msgID int) string { return links[msgID] }
+	}
+
 	// Filter text-only messages (ignore media-only)
-	textMessages := messages.FilterTextOnly(result.Messages)
+	textMessages := messages.FilterTextOnly(msgs)
 	if len(textMessages) == 0 {
-		return "No text messages found in the specified period.", nil
+		return Result{Summary: "No text messages found in the specified period."}, nil
+	}
+
+	if opts.MergeConsecutive {
+		gap := opts.MergeGap
+		if gap <= 0 {
+			gap = messages.DefaultMergeGap
+		}
+		textMessages = messages.MergeConsecutive(textMessages, gap)
 	}
 
 	// Split into batches by token count
 	batches := splitIntoBatchesByTokens(textMessages, s.batchTokens)
 	totalBatches := len(batches)
+	stats := Stats{
+		Messages:     len(textMessages),
+		TotalBatches: totalBatches,
+		First:        textMessages[0].Date,
+		Last:         textMessages[len(textMessages)-1].Date,
+	}
+	window := windowLabel(opts)
+
+	batchCtx := ctx
+	if opts.Deadline > 0 {
+		var cancel context.CancelFunc
+		batchCtx, cancel = context.WithTimeout(ctx, opts.Deadline)
+		defer cancel()
+	}
+
+	template := promptTemplate
+	if opts.Structured {
+		template = structuredPromptTemplate
+	}
+	extraInstructions := aliasInstructions
+	if opts.AnonymizeSenders {
+		extraInstructions = anonymizeInstructions
+	}
+	if opts.Citations {
+		extraInstructions += citationInstructions
+	}
+	aliases := messages.NewSenderAliases(opts.AnonymizeSenders)
 
 	var runningSummary string
 
 	for i, batch := range batches {
 		if onProgress != nil {
-			onProgress(i+1, totalBatches, fmt.Sprintf("Processing batch %d/%d", i+1, totalBatches))
+			onProgress(i+1, totalBatches, fmt.Sprintf("Processing batch %d/%d%s", i+1, totalBatches, window))
 		}
 
-		formattedMessages := messages.FormatBatchForSummary(batch)
-		prompt := fmt.Sprintf(promptTemplate, goal, runningSummary, formattedMessages)
+		formattedMessages := aliases.FormatBatch(batch, opts.Citations)
+		prompt := fmt.Sprintf(template, opts.Goal, runningSummary, dataBlock(formattedMessages), extraInstructions)
 
-		summary, err := s.summarizeWithProgress(ctx, prompt, i+1, totalBatches, onProgress)
+		summary, err := s.summarizeWithProgress(batchCtx, prompt, i+1, totalBatches, window, onProgress)
 		if err != nil {
-			return "", fmt.Errorf("summarizing batch %d: %w", i+1, err)
+			// The overall deadline (not the caller) ended the run: return what is covered so far
+			if i > 0 && ctx.Err() == nil && errors.Is(batchCtx.Err(), context.DeadlineExceeded) {
+				coveredUntil := batches[i-1][len(batches[i-1])-1].Date
+				stats.Partial = true
+				if opts.Structured {
+					// No time is left to repair the JSON
+					res := s.finishStructured(batchCtx, runningSummary, known, opts, stats, false, onProgress)
+					if res.Structured == nil {
+						res.Summary = partialSummary(res.Summary, opts.Deadline, coveredUntil, i, totalBatches)
+					}
+					return res, nil
+				}
+				summary := finishCitations(runningSummary, known, opts, &stats)
+				return Result{
+					Summary: partialSummary(summary, opts.Deadline, coveredUntil, i, totalBatches),
+					Stats:   stats,
+				}, nil
+			}
+			return Result{}, fmt.Errorf("summarizing batch %d: %w", i+1, err)
 		}
 
 		runningSummary = strings.TrimSpace(summary)
+		stats.Batches++
+	}
+
+	if opts.Structured {
+		return s.finishStructured(batchCtx, runningSummary, known, opts, stats, true, onProgress), nil
+	}
+
+	if opts.AutoTranslate {
+		if from, to, needed := translationNeeded(opts.Goal, runningSummary); needed {
+			if onProgress != nil {
+				onProgress(totalBatches, totalBatches, "Translating summary to the goal's language")
+			}
+			// A failed translation keeps the untranslated summary rather than losing it
+			translated, err := s.summarizeWithProgress(batchCtx, translatePrompt(opts.Goal, runningSummary), totalBatches, totalBatches, window, onProgress)
+			if err == nil && strings.TrimSpace(translated) != "" {
+				runningSummary = strings.TrimSpace(translated)
+				stats.Translated = true
+			} else {
+				stats.TranslationFailed = true
+			}
+			stats.TranslatedFrom = from
+			stats.TranslatedTo = to
+		}
+	}
+
+	return Result{Summary: finishCitations(runningSummary, known, opts, &stats), Stats: stats}, nil
+}
+
+// windowLabel describes the date window of opts for progress messages, e.g.
+// " of 2024-03-01 00:00 – 2024-03-07 23:59", or "" when no window is set.
+func windowLabel(opts Options) string {
+	const layout = "2006-01-02 15:04"
+	switch {
+	case opts.Since.IsZero() && opts.Until.IsZero():
+		return ""
+	case opts.Until.IsZero():
+		return " of messages since " + opts.Since.Format(layout)
+	case opts.Since.IsZero():
+		return " of messages until " + opts.Until.Format(layout)
+	default:
+		return fmt.Sprintf(" of %s – %s", opts.Since.Format(layout), opts.Until.Format(layout))
+	}
+}
+
+// finishCitations validates the summary's citations against the known message IDs and
+// links them in citation mode, recording the counts in stats.
+func finishCitations(summary string, known map[int]bool, opts Options, stats *Stats) string {
+	if !opts.Citations {
+		return summary
+	}
+	summary, citations := ValidateCitations(summary, known)
+	stats.Citations += citations.Valid
+	stats.InvalidCitations += citations.Invalid
+	if opts.CitationLink != nil {
+		summary = LinkCitations(summary, opts.CitationLink)
+	}
+	return summary
+}
+
+// finishStructured parses the final summary of structured mode, asking the model
+// once to repair malformed JSON when repair is set. If the JSON still doesn't parse,
+// the summary is returned as text with stats.StructuredFailed set.
+func (s *Summarizer) finishStructured(ctx context.Context, summary string, known map[int]bool, opts Options, stats Stats, repair bool, onProgress ProgressCallback) Result {
+	structured, err := ParseStructured(summary)
+	if err != nil && repair {
+		if onProgress != nil {
+			onProgress(stats.TotalBatches, stats.TotalBatches, "Repairing the structured summary")
+		}
+		repaired, repairErr := s.summarizeWithProgress(ctx, repairPrompt(summary, err), stats.TotalBatches, stats.TotalBatches, "", onProgress)
+		if repairErr == nil {
+			if structured, err = ParseStructured(repaired); err == nil {
+				summary = repaired
+			}
+		}
+	}
+	if err != nil {
+		stats.StructuredFailed = true
+		return Result{Summary: finishCitations(summary, known, opts, &stats), Stats: stats}
 	}
 
-	return runningSummary, nil
+	structured.rewriteTexts(func(text string) string {
+		return finishCitations(text, known, opts, &stats)
+	})
+	data, _ := json.MarshalIndent(structured, "", "  ")
+	return Result{Summary: string(data), Structured: structured, Stats: stats}
+}
+
+// partialSummary prefixes a summary cut short by the deadline with a banner saying what it covers.
+func partialSummary(summary string, deadline time.Duration, coveredUntil time.Time, doneBatches, totalBatches int) string {
+	return fmt.Sprintf("[Partial summary: the %s deadline was reached. Covered messages up to %s (%d of %d batches).]\n\n%s",
+		deadline, coveredUntil.Format("2006-01-02 15:04"), doneBatches, totalBatches, summary)
 }
 
 // estimateTokens provides a rough token estimate for text.
-// Uses the common approximation of ~4 characters per token for English
-// but adjusts for other languages that may have different ratios.
+// Uses the common approximation of ~4 characters per token for ASCII text such as
+// English, timestamps and IDs, and ~2 for the letters of other scripts (Cyrillic,
+// CJK), counted apart since sender names often mix scripts within a line.
 func estimateTokens(text string) int {
-	// Rough approximation: ~4 chars per token for English
-	// For non-ASCII text (like Cyrillic, CJK), tokens can be ~1-2 chars
-	charCount := len(text)
-	runeCount := utf8.RuneCountInString(text)
-
-	// If there are many multi-byte characters, use a lower ratio
-	if charCount > runeCount*2 {
-		return runeCount / 2
+	ascii, other := 0, 0
+	for _, r := range text {
+		if r < utf8.RuneSelf {
+			ascii++
+		} else {
+			other++
+		}
 	}
-	return charCount / 4
+	return ascii/4 + other/2
 }
 
 // splitIntoBatchesByTokens splits messages into batches where each batch
@@ -134,7 +436,9 @@ func splitIntoBatchesByTokens(msgs []messages.Message, maxTokens int) [][]messag
 	currentTokens := 0
 
 	for _, msg := range msgs {
-		// Estimate tokens for this message including formatting overhead
+		// Estimate tokens for this message including formatting overhead. The sender's
+		// full name is an upper bound: an alias only replaces it when that, legend
+		// included, is shorter.
 		msgTokens := estimateTokens(messages.FormatForSummary(msg))
 
 		// If adding this message exceeds the limit, start a new batch
@@ -161,7 +465,7 @@ const progressInterval = 5 * time.Second
 
 // summarizeWithProgress calls the provider and sends periodic progress updates
 // to prevent client timeout during long LLM calls.
-func (s *Summarizer) summarizeWithProgress(ctx context.Context, prompt string, currentBatch, totalBatches int, onProgress ProgressCallback) (string, error) {
+func (s *Summarizer) summarizeWithProgress(ctx context.Context, prompt string, currentBatch, totalBatches int, window string, onProgress ProgressCallback) (string, error) {
 	type result struct {
 		summary string
 		err     error
@@ -185,7 +489,7 @@ func (s *Summarizer) summarizeWithProgress(ctx context.Context, prompt string, c
 		case <-ticker.C:
 			elapsed += int(progressInterval.Seconds())
 			if onProgress != nil {
-				onProgress(currentBatch, totalBatches, fmt.Sprintf("Processing batch %d/%d (%ds elapsed)", currentBatch, totalBatches, elapsed))
+				onProgress(currentBatch, totalBatches, fmt.Sprintf("Processing batch %d/%d%s (%ds elapsed)", currentBatch, totalBatches, window, elapsed))
 			}
 		case <-ctx.Done():
 			return "", fmt.Errorf("summarization canceled: %w", ctx.Err())
@@ -0,0 +1,309 @@
+package summarize
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tolmachov/mcp-telegram/internal/messages"
+)
+
+// batchTokens sums the estimated tokens of every batch as it would be sent to the model.
+func batchTokens(msgs []messages.Message, maxTokens int) int {
+	total := 0
+	for _, batch := range splitIntoBatchesByTokens(msgs, maxTokens) {
+		total += estimateTokens(messages.FormatBatchForSummary(batch))
+	}
+	return total
+}
+
+func TestMergeConsecutiveReducesBatchTokens(t *testing.T) {
+	start := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+
+	// Two people chatting in bursts of short messages, as in a typical group chat.
+	var msgs []messages.Message
+	for i := range 60 {
+		sender := int64(1 + (i/5)%2)
+		msgs = append(msgs, messages.Message{
+			ID:         i + 1,
+			Date:       start.Add(time.Duration(i) * 20 * time.Second),
+			SenderID:   sender,
+			SenderName: fmt.Sprintf("User %d", sender),
+			Text:       fmt.Sprintf("short message %d", i),
+		})
+	}
+
+	before := batchTokens(msgs, DefaultBatchTokens)
+	after := batchTokens(messages.MergeConsecutive(msgs, messages.DefaultMergeGap), DefaultBatchTokens)
+
+	if after >= before {
+		t.Fatalf("expected merging to reduce tokens, got %d before and %d after", before, after)
+	}
+	t.Logf("estimated tokens: %d before merge, %d after", before, after)
+}
+
+// fakeProvider answers the first fastCalls prompts immediately and blocks on the
+// rest until the context is done.
+type fakeProvider struct {
+	fastCalls int
+	calls     int
+}
+
+func (p *fakeProvider) Summarize(ctx context.Context, _ string) (string, error) {
+	p.calls++
+	if p.calls <= p.fastCalls {
+		return fmt.Sprintf("summary after batch %d", p.calls), nil
+	}
+	<-ctx.Done()
+	return "", ctx.Err()
+}
+
+// chattyMessages returns n distinct messages one minute apart, large enough that
+// each forms its own batch with a tiny token budget.
+func chattyMessages(n int) []messages.Message {
+	start := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	msgs := make([]messages.Message, n)
+	for i := range msgs {
+		msgs[i] = messages.Message{
+			ID:         i + 1,
+			Date:       start.Add(time.Duration(i) * time.Minute),
+			SenderID:   int64(i + 1),
+			SenderName: fmt.Sprintf("User %d", i+1),
+			Text:       fmt.Sprintf("message number %d with some words", i),
+		}
+	}
+	return msgs
+}
+
+func TestSummarizeMessagesDeadline(t *testing.T) {
+	tests := []struct {
+		name        string
+		fastCalls   int
+		wantErr     bool
+		wantPartial bool
+		wantSummary string
+		wantCovered string
+	}{
+		{
+			name:        "all batches finish",
+			fastCalls:   3,
+			wantSummary: "summary after batch 3",
+		},
+		{
+			name:        "deadline after two batches",
+			fastCalls:   2,
+			wantPartial: true,
+			wantSummary: "summary after batch 2",
+			wantCovered: "covered messages up to 2024-01-15 10:01 (2 of 3 batches)",
+		},
+		{
+			name:      "deadline before first batch",
+			fastCalls: 0,
+			wantErr:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider := &fakeProvider{fastCalls: tt.fastCalls}
+			s := NewSummarizer(provider, nil, 1)
+			opts := Options{Goal: "key points", Deadline: 50 * time.Millisecond}
+
+			result, err := s.SummarizeMessages(context.Background(), chattyMessages(3), opts, nil)
+			got := result.Summary
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected error, got %q", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if isPartial := strings.HasPrefix(got, "[Partial summary"); isPartial != tt.wantPartial {
+				t.Errorf("partial = %v, want %v: %q", isPartial, tt.wantPartial, got)
+			}
+			if result.Stats.Partial != tt.wantPartial {
+				t.Errorf("stats partial = %v, want %v", result.Stats.Partial, tt.wantPartial)
+			}
+			if !strings.HasSuffix(got, tt.wantSummary) {
+				t.Errorf("summary %q does not end with %q", got, tt.wantSummary)
+			}
+			if tt.wantCovered != "" && !strings.Contains(strings.ToLower(got), strings.ToLower(tt.wantCovered)) {
+				t.Errorf("summary %q does not mention %q", got, tt.wantCovered)
+			}
+		})
+	}
+}
+
+func TestSummarizeMessagesCallerCancel(t *testing.T) {
+	provider := &fakeProvider{fastCalls: 1}
+	s := NewSummarizer(provider, nil, 1)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	// The caller giving up is an error, not a partial summary
+	if got, err := s.SummarizeMessages(ctx, chattyMessages(3), Options{Deadline: time.Hour}, nil); err == nil {
+		t.Fatalf("expected error, got %q", got.Summary)
+	}
+}
+
+// scriptedProvider returns its replies in order and records the prompts it was sent.
+type scriptedProvider struct {
+	replies []string
+	err     error
+	prompts []string
+}
+
+func (p *scriptedProvider) Summarize(_ context.Context, prompt string) (string, error) {
+	p.prompts = append(p.prompts, prompt)
+	if len(p.prompts) > len(p.replies) {
+		return "", p.err
+	}
+	return p.replies[len(p.prompts)-1], nil
+}
+
+func TestSummarizeMessagesAutoTranslate(t *testing.T) {
+	msgs := chattyMessages(1)
+
+	tests := []struct {
+		name          string
+		goal          string
+		autoTranslate bool
+		provider      *scriptedProvider
+		wantSummary   string
+		wantCalls     int
+		wantStats     Stats
+	}{
+		{
+			name:          "summary matches goal",
+			goal:          "key points",
+			autoTranslate: true,
+			provider:      &scriptedProvider{replies: []string{"The team agreed on the plan"}},
+			wantSummary:   "The team agreed on the plan",
+			wantCalls:     1,
+			wantStats:     Stats{Messages: 1, Batches: 1, TotalBatches: 1},
+		},
+		{
+			name:          "translated to goal language",
+			goal:          "key points",
+			autoTranslate: true,
+			provider:      &scriptedProvider{replies: []string{"Команда согласовала план", "The team agreed on the plan"}},
+			wantSummary:   "The team agreed on the plan",
+			wantCalls:     2,
+			wantStats:     Stats{Messages: 1, Batches: 1, TotalBatches: 1, Translated: true, TranslatedFrom: "Cyrillic", TranslatedTo: "Latin"},
+		},
+		{
+			name:          "disabled",
+			goal:          "key points",
+			autoTranslate: false,
+			provider:      &scriptedProvider{replies: []string{"Команда согласовала план"}},
+			wantSummary:   "Команда согласовала план",
+			wantCalls:     1,
+			wantStats:     Stats{Messages: 1, Batches: 1, TotalBatches: 1},
+		},
+		{
+			name:          "translation fails",
+			goal:          "ключевые моменты",
+			autoTranslate: true,
+			provider:      &scriptedProvider{replies: []string{"The team agreed on the plan"}, err: fmt.Errorf("boom")},
+			wantSummary:   "The team agreed on the plan",
+			wantCalls:     2,
+			wantStats:     Stats{Messages: 1, Batches: 1, TotalBatches: 1, TranslationFailed: true, TranslatedFrom: "Latin", TranslatedTo: "Cyrillic"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewSummarizer(tt.provider, nil, DefaultBatchTokens)
+			result, err := s.SummarizeMessages(context.Background(), msgs, Options{Goal: tt.goal, AutoTranslate: tt.autoTranslate}, nil)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result.Summary != tt.wantSummary {
+				t.Errorf("summary = %q, want %q", result.Summary, tt.wantSummary)
+			}
+			if len(tt.provider.prompts) != tt.wantCalls {
+				t.Errorf("provider calls = %d, want %d", len(tt.provider.prompts), tt.wantCalls)
+			}
+			want := tt.wantStats
+			want.First, want.Last = msgs[0].Date, msgs[0].Date
+			if result.Stats != want {
+				t.Errorf("stats = %+v, want %+v", result.Stats, want)
+			}
+			if tt.wantCalls == 2 && !strings.Contains(tt.provider.prompts[1], tt.goal) {
+				t.Errorf("translation prompt does not include the goal: %q", tt.provider.prompts[1])
+			}
+		})
+	}
+}
+
+func TestSummarizeMessagesWindow(t *testing.T) {
+	msgs := chattyMessages(10)
+	s := NewSummarizer(&fakeProvider{fastCalls: 100}, nil, DefaultBatchTokens)
+
+	var progress []string
+	opts := Options{Goal: "key points", Since: msgs[2].Date, Until: msgs[6].Date}
+	res, err := s.SummarizeMessages(context.Background(), msgs, opts, func(_, _ int, message string) {
+		progress = append(progress, message)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Stats.Messages != 5 {
+		t.Errorf("Messages = %d, want 5 (since and until inclusive)", res.Stats.Messages)
+	}
+	if !res.Stats.First.Equal(msgs[2].Date) || !res.Stats.Last.Equal(msgs[6].Date) {
+		t.Errorf("range = %v – %v, want %v – %v", res.Stats.First, res.Stats.Last, msgs[2].Date, msgs[6].Date)
+	}
+	want := "Processing batch 1/1 of 2024-01-15 10:02 – 2024-01-15 10:06"
+	if len(progress) == 0 || progress[0] != want {
+		t.Errorf("progress = %q, want first %q", progress, want)
+	}
+}
+
+func TestSummarizeMessagesSenderNames(t *testing.T) {
+	msgs := chattyMessages(3)
+	msgs[2].Text = "ask User 1 about it"
+
+	for _, anonymize := range []bool{false, true} {
+		provider := &scriptedProvider{replies: []string{"summary"}}
+		s := NewSummarizer(provider, nil, DefaultBatchTokens)
+		if _, err := s.SummarizeMessages(context.Background(), msgs, Options{Goal: "key points", AnonymizeSenders: anonymize}, nil); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		prompt := provider.prompts[0]
+
+		if anonymize {
+			if !strings.Contains(prompt, "] Person A: message number 0") || !strings.Contains(prompt, anonymizeInstructions) || strings.Contains(prompt, "] User 2:") {
+				t.Errorf("anonymized prompt names senders: %q", prompt)
+			}
+			continue
+		}
+		if !strings.Contains(prompt, "] User 1: message number 0") || !strings.Contains(prompt, aliasInstructions) {
+			t.Errorf("prompt lacks sender names: %q", prompt)
+		}
+	}
+}
+
+func TestEstimateTokens(t *testing.T) {
+	tests := []struct {
+		text string
+		want int
+	}{
+		{text: "", want: 0},
+		{text: "12345678", want: 2},
+		{text: "привет", want: 3},
+		// An ASCII line with a Cyrillic name counts each part at its own rate
+		{text: "[2024-01-15 10:00] Иван: hello world", want: 8 + 2},
+	}
+	for _, tt := range tests {
+		if got := estimateTokens(tt.text); got != tt.want {
+			t.Errorf("estimateTokens(%q) = %d, want %d", tt.text, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,153 @@
+package summarize
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Transcriber is an interface for providers that turn speech into text.
+type Transcriber interface {
+	// Transcribe returns the text spoken in audio, a file named filename.
+	Transcribe(ctx context.Context, filename string, audio []byte) (string, error)
+}
+
+// TranscribeProviderName represents a valid transcription provider name.
+type TranscribeProviderName string
+
+const (
+	// TranscribeProviderTelegram uses Telegram's own transcription, which needs
+	// Telegram Premium beyond a few free trials.
+	TranscribeProviderTelegram TranscribeProviderName = "telegram"
+	TranscribeProviderOpenAI   TranscribeProviderName = "openai"
+)
+
+// DefaultTranscribeModel is the default model for OpenAI-compatible transcription endpoints.
+const DefaultTranscribeModel = "whisper-1"
+
+// ValidateTranscribeProviderName checks if the transcription provider name is valid.
+func ValidateTranscribeProviderName(name string) error {
+	switch TranscribeProviderName(name) {
+	case TranscribeProviderTelegram, TranscribeProviderOpenAI:
+		return nil
+	default:
+		return fmt.Errorf("invalid transcription provider: %q (must be 'telegram' or 'openai')", name)
+	}
+}
+
+// NewTranscriber creates the transcription provider selected by the configuration,
+// or returns nil when Telegram transcribes voice messages itself.
+func NewTranscriber(cfg Config) (Transcriber, error) {
+	switch cfg.TranscribeProvider {
+	case TranscribeProviderTelegram, "":
+		return nil, nil
+	case TranscribeProviderOpenAI:
+		model := cfg.TranscribeModel
+		if model == "" {
+			model = DefaultTranscribeModel
+		}
+		baseURL := cfg.TranscribeURL
+		if baseURL == "" {
+			baseURL = DefaultOpenAIURL
+		}
+		return NewOpenAITranscriber(baseURL, cfg.TranscribeAPIKey, model, cfg.RequestTimeout), nil
+	default:
+		return nil, ValidateTranscribeProviderName(string(cfg.TranscribeProvider))
+	}
+}
+
+// OpenAITranscriber implements Transcriber using an OpenAI-compatible
+// /audio/transcriptions endpoint, e.g. OpenAI itself or a local Whisper server.
+type OpenAITranscriber struct {
+	baseURL        string
+	apiKey         string
+	model          string
+	client         *http.Client
+	requestTimeout time.Duration
+}
+
+// NewOpenAITranscriber creates a new OpenAITranscriber. apiKey may be empty for
+// local servers that don't require authentication. Each request is limited to
+// requestTimeout (DefaultRequestTimeout if zero).
+func NewOpenAITranscriber(baseURL, apiKey, model string, requestTimeout time.Duration) *OpenAITranscriber {
+	return &OpenAITranscriber{
+		baseURL:        strings.TrimSuffix(baseURL, "/"),
+		apiKey:         apiKey,
+		model:          model,
+		client:         &http.Client{},
+		requestTimeout: requestTimeout,
+	}
+}
+
+type openAITranscribeResponse struct {
+	Text  string `json:"text"`
+	Error *struct {
+		Message string `json:"message"`
+	} `json:"error,omitempty"`
+}
+
+// Transcribe uploads audio and returns its transcript.
+func (t *OpenAITranscriber) Transcribe(ctx context.Context, filename string, audio []byte) (string, error) {
+	ctx, cancel := withRequestTimeout(ctx, t.requestTimeout)
+	defer cancel()
+
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	if err := form.WriteField("model", t.model); err != nil {
+		return "", fmt.Errorf("building request: %w", err)
+	}
+	if err := form.WriteField("response_format", "json"); err != nil {
+		return "", fmt.Errorf("building request: %w", err)
+	}
+	part, err := form.CreateFormFile("file", filename)
+	if err != nil {
+		return "", fmt.Errorf("building request: %w", err)
+	}
+	if _, err := part.Write(audio); err != nil {
+		return "", fmt.Errorf("building request: %w", err)
+	}
+	if err := form.Close(); err != nil {
+		return "", fmt.Errorf("building request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, t.baseURL+"/audio/transcriptions", &body)
+	if err != nil {
+		return "", fmt.Errorf("creating request: %w", err)
+	}
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	if t.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+t.apiKey)
+	}
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("sending request: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("reading response: %w", err)
+	}
+
+	var transcribeResp openAITranscribeResponse
+	if err := json.Unmarshal(respBody, &transcribeResp); err != nil {
+		if resp.StatusCode != http.StatusOK {
+			return "", fmt.Errorf("transcription endpoint returned status %d: %s", resp.StatusCode, string(respBody))
+		}
+		return "", fmt.Errorf("unmarshaling response: %w", err)
+	}
+	if transcribeResp.Error != nil {
+		return "", fmt.Errorf("transcription API error: %s", transcribeResp.Error.Message)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("transcription endpoint returned status %d: %s", resp.StatusCode, string(respBody))
+	}
+	return strings.TrimSpace(transcribeResp.Text), nil
+}
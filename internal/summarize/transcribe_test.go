@@ -0,0 +1,87 @@
+package summarize
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestOpenAITranscribe(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/audio/transcriptions" || r.Method != http.MethodPost {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		if got := r.Header.Get("Authorization"); got != "Bearer secret" {
+			t.Errorf("Authorization = %q", got)
+		}
+		if got := r.FormValue("model"); got != "whisper-local" {
+			t.Errorf("model = %q", got)
+		}
+		file, header, err := r.FormFile("file")
+		if err != nil {
+			t.Fatalf("reading file: %v", err)
+		}
+		data, _ := io.ReadAll(file)
+		if header.Filename != "voice_1.ogg" || string(data) != "OggS..." {
+			t.Errorf("file %q = %q", header.Filename, data)
+		}
+		_, _ = w.Write([]byte(`{"text":" Hello there \n"}`))
+	}))
+	defer srv.Close()
+
+	tr := NewOpenAITranscriber(srv.URL+"/v1/", "secret", "whisper-local", time.Second)
+	got, err := tr.Transcribe(t.Context(), "voice_1.ogg", []byte("OggS..."))
+	if err != nil {
+		t.Fatalf("Transcribe: %v", err)
+	}
+	if got != "Hello there" {
+		t.Errorf("got %q, want %q", got, "Hello there")
+	}
+}
+
+func TestOpenAITranscribeErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		body    string
+		wantErr string
+	}{
+		{"error envelope", http.StatusUnauthorized, `{"error":{"message":"Incorrect API key provided"}}`, "Incorrect API key provided"},
+		{"http error", http.StatusBadGateway, `upstream unavailable`, "status 502"},
+		{"bad json", http.StatusOK, `{`, "unmarshaling response"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(tt.body))
+			}))
+			defer srv.Close()
+
+			_, err := NewOpenAITranscriber(srv.URL, "", "m", time.Second).Transcribe(t.Context(), "a.ogg", []byte("x"))
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNewTranscriber(t *testing.T) {
+	if tr, err := NewTranscriber(Config{}); tr != nil || err != nil {
+		t.Errorf("default provider = %v, %v, want Telegram's (nil)", tr, err)
+	}
+	tr, err := NewTranscriber(Config{TranscribeProvider: TranscribeProviderOpenAI})
+	if err != nil {
+		t.Fatalf("NewTranscriber(openai): %v", err)
+	}
+	if o, ok := tr.(*OpenAITranscriber); !ok || o.model != DefaultTranscribeModel || o.baseURL != DefaultOpenAIURL {
+		t.Errorf("NewTranscriber(openai) = %+v", tr)
+	}
+	if _, err := NewTranscriber(Config{TranscribeProvider: "siri"}); err == nil {
+		t.Error("NewTranscriber(siri) succeeded, want error")
+	}
+}
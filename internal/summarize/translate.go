@@ -0,0 +1,83 @@
+package summarize
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/tolmachov/mcp-telegram/internal/messages"
+)
+
+// messagesTranslatePromptTemplate asks for the translation of a batch of messages,
+// one per message ID.
+const messagesTranslatePromptTemplate = `You are translating Telegram chat messages into %s.
+
+Messages to translate:
+%s
+
+Instructions:
+` + untrustedInstruction + `
+- Translate the text of every message, identified by its [#id], into %[1]s
+- Keep names, usernames, links, numbers, emoji and line breaks unchanged
+- Leave out the timestamp, the sender ID and the markers such as (edited) in front of the text
+- Leave a message already written in %[1]s unchanged
+- Reply with only a JSON object mapping each message ID to its translation, without code fences or any other text, e.g. {"12": "...", "13": "..."}
+
+JSON object:`
+
+// TranslateMessages translates the text of msgs into lang with the provider, in
+// batches split as SummarizeMessages splits them. It returns the translations by
+// message ID; messages without text, or that the model left out, are missing.
+func (s *Summarizer) TranslateMessages(ctx context.Context, msgs []messages.Message, lang string, onProgress ProgressCallback) (map[int]string, error) {
+	textMessages := messages.FilterTextOnly(msgs)
+	batches := splitIntoBatchesByTokens(textMessages, s.batchTokens)
+
+	translations := make(map[int]string, len(textMessages))
+	for i, batch := range batches {
+		if onProgress != nil {
+			onProgress(i+1, len(batches), fmt.Sprintf("Translating batch %d/%d", i+1, len(batches)))
+		}
+		prompt := fmt.Sprintf(messagesTranslatePromptTemplate, lang, dataBlock(messages.FormatBatchForSummaryWithIDs(batch)))
+		text, err := s.summarizeWithProgress(ctx, prompt, i+1, len(batches), "", onProgress)
+		if err != nil {
+			return nil, fmt.Errorf("translating batch %d: %w", i+1, err)
+		}
+		translated, err := parseTranslations(text)
+		if err != nil {
+			return nil, fmt.Errorf("parsing translation of batch %d: %w", i+1, err)
+		}
+		for _, msg := range batch {
+			if t, ok := translated[msg.ID]; ok {
+				translations[msg.ID] = t
+			}
+		}
+	}
+	return translations, nil
+}
+
+// parseTranslations parses a model's JSON object of translations by message ID. Code
+// fences and text around the object are ignored, and so are keys that aren't IDs.
+func parseTranslations(text string) (map[int]string, error) {
+	start := strings.Index(text, "{")
+	end := strings.LastIndex(text, "}")
+	if start < 0 || end < start {
+		return nil, errors.New("no JSON object found")
+	}
+	var raw map[string]string
+	if err := json.Unmarshal([]byte(text[start:end+1]), &raw); err != nil {
+		return nil, err
+	}
+
+	translations := make(map[int]string, len(raw))
+	for key, t := range raw {
+		id, err := strconv.Atoi(strings.TrimPrefix(strings.TrimSpace(key), "#"))
+		if err != nil {
+			continue
+		}
+		translations[id] = strings.TrimSpace(t)
+	}
+	return translations, nil
+}
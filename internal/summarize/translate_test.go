@@ -0,0 +1,58 @@
+package summarize
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/tolmachov/mcp-telegram/internal/messages"
+)
+
+func TestTranslateMessages(t *testing.T) {
+	msgs := chattyMessages(20)
+	batchTokens := 10 * estimateTokens(messages.FormatForSummary(msgs[19]))
+	batches := splitIntoBatchesByTokens(msgs, batchTokens)
+	if len(batches) != 2 {
+		t.Fatalf("test messages split into %d batches, want 2", len(batches))
+	}
+	secondID := batches[1][0].ID
+	provider := &scriptedProvider{replies: []string{
+		"```json\n" + `{"1": "eins", "#2": " zwei ", "99": "unknown", "x": "bad key"}` + "\n```",
+		fmt.Sprintf(`{"%d": "later"}`, secondID),
+	}}
+	s := NewSummarizer(provider, nil, batchTokens)
+
+	var progress []string
+	got, err := s.TranslateMessages(context.Background(), msgs, "German", func(_, _ int, message string) {
+		progress = append(progress, message)
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := map[int]string{1: "eins", 2: "zwei", secondID: "later"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("translations = %v, want %v", got, want)
+	}
+	if len(provider.prompts) != 2 {
+		t.Fatalf("provider calls = %d, want one per batch", len(provider.prompts))
+	}
+	for _, part := range []string{"into German", "[#1]", untrustedInstruction} {
+		if !strings.Contains(provider.prompts[0], part) {
+			t.Errorf("prompt lacks %q: %q", part, provider.prompts[0])
+		}
+	}
+	if len(progress) == 0 || progress[0] != "Translating batch 1/2" {
+		t.Errorf("progress = %q", progress)
+	}
+}
+
+func TestTranslateMessagesMalformed(t *testing.T) {
+	provider := &scriptedProvider{replies: []string{"Sorry, I can't do that."}}
+	s := NewSummarizer(provider, nil, DefaultBatchTokens)
+	if _, err := s.TranslateMessages(context.Background(), chattyMessages(2), "en", nil); err == nil {
+		t.Error("expected an error for a reply without JSON")
+	}
+}
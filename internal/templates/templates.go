@@ -0,0 +1,216 @@
+// Package templates loads named outgoing message templates with {{variable}} placeholders.
+package templates
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// placeholderRe matches a {{variable}} placeholder, allowing spaces inside the braces.
+var placeholderRe = regexp.MustCompile(`\{\{\s*([A-Za-z_][A-Za-z0-9_]*)\s*\}\}`)
+
+// Template is a named message template.
+type Template struct {
+	Name        string            `json:"name"`
+	Description string            `json:"description,omitempty"`
+	Text        string            `json:"text"`
+	Defaults    map[string]string `json:"defaults,omitempty"`
+}
+
+// file is the on-disk format of a templates file.
+type file struct {
+	Templates map[string]Template `json:"templates"`
+}
+
+// MissingVariablesError is returned when rendering a template without all required variables.
+type MissingVariablesError struct {
+	Template string
+	Missing  []string
+}
+
+func (e *MissingVariablesError) Error() string {
+	return fmt.Sprintf("template %q is missing required variables: %s", e.Template, strings.Join(e.Missing, ", "))
+}
+
+// Variables returns the names of the template's placeholders in order of first appearance.
+func (t Template) Variables() []string {
+	seen := make(map[string]bool)
+	var vars []string
+	for _, m := range placeholderRe.FindAllStringSubmatch(t.Text, -1) {
+		if !seen[m[1]] {
+			seen[m[1]] = true
+			vars = append(vars, m[1])
+		}
+	}
+	return vars
+}
+
+// Required returns the variables that have no default value.
+func (t Template) Required() []string {
+	var required []string
+	for _, v := range t.Variables() {
+		if _, ok := t.Defaults[v]; !ok {
+			required = append(required, v)
+		}
+	}
+	return required
+}
+
+// Render substitutes vars, falling back to defaults, into the template text.
+// Variables that are not used by the template are ignored.
+func (t Template) Render(vars map[string]string) (string, error) {
+	var missing []string
+	for _, v := range t.Required() {
+		if _, ok := vars[v]; !ok {
+			missing = append(missing, v)
+		}
+	}
+	if len(missing) > 0 {
+		return "", &MissingVariablesError{Template: t.Name, Missing: missing}
+	}
+
+	return placeholderRe.ReplaceAllStringFunc(t.Text, func(placeholder string) string {
+		name := placeholderRe.FindStringSubmatch(placeholder)[1]
+		if value, ok := vars[name]; ok {
+			return value
+		}
+		return t.Defaults[name]
+	}), nil
+}
+
+// validate checks the template text and defaults.
+func (t Template) validate() error {
+	if strings.TrimSpace(t.Text) == "" {
+		return errors.New("text is empty")
+	}
+	rest := placeholderRe.ReplaceAllString(t.Text, "")
+	if i := strings.Index(rest, "{{"); i >= 0 {
+		return fmt.Errorf("malformed placeholder near %q (expected {{name}} with letters, digits or underscores)", snippet(rest, i))
+	}
+	if i := strings.Index(rest, "}}"); i >= 0 {
+		return fmt.Errorf("unmatched }} near %q", snippet(rest, i))
+	}
+	vars := make(map[string]bool)
+	for _, v := range t.Variables() {
+		vars[v] = true
+	}
+	for name := range t.Defaults {
+		if !vars[name] {
+			return fmt.Errorf("default given for unknown variable %q", name)
+		}
+	}
+	return nil
+}
+
+// snippet returns a short piece of s starting at byte offset i, for error messages.
+func snippet(s string, i int) string {
+	runes := []rune(s[i:])
+	if len(runes) > 20 {
+		runes = runes[:20]
+	}
+	return string(runes)
+}
+
+// Parse parses a templates file. Templates that fail validation are skipped and reported
+// as per-template errors; the returned templates are sorted by name. A non-nil error
+// means the file itself could not be parsed.
+func Parse(data []byte) ([]Template, []error, error) {
+	var f file
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, nil, fmt.Errorf("parsing templates file: %w", err)
+	}
+
+	names := make([]string, 0, len(f.Templates))
+	for name := range f.Templates {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var templates []Template
+	var problems []error
+	for _, name := range names {
+		t := f.Templates[name]
+		t.Name = name
+		if strings.TrimSpace(name) == "" {
+			problems = append(problems, errors.New("template with an empty name"))
+			continue
+		}
+		if err := t.validate(); err != nil {
+			problems = append(problems, fmt.Errorf("template %q: %w", name, err))
+			continue
+		}
+		templates = append(templates, t)
+	}
+	return templates, problems, nil
+}
+
+// Store holds the templates loaded from a file and can reload them.
+// It is safe for concurrent use.
+type Store struct {
+	path string
+
+	mu        sync.RWMutex
+	templates map[string]Template
+}
+
+// NewStore creates a Store for the templates file at path. An empty path means
+// no templates are configured.
+func NewStore(path string) *Store {
+	return &Store{path: path, templates: make(map[string]Template)}
+}
+
+// Path returns the templates file path.
+func (s *Store) Path() string {
+	return s.path
+}
+
+// Load (re)reads the templates file. Valid templates replace the current set; invalid
+// ones are returned as per-template errors. On a file-level error the current set is kept.
+func (s *Store) Load() ([]error, error) {
+	if s.path == "" {
+		return nil, nil
+	}
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		return nil, fmt.Errorf("reading templates file: %w", err)
+	}
+	templates, problems, err := Parse(data)
+	if err != nil {
+		return nil, err
+	}
+
+	loaded := make(map[string]Template, len(templates))
+	for _, t := range templates {
+		loaded[t.Name] = t
+	}
+	s.mu.Lock()
+	s.templates = loaded
+	s.mu.Unlock()
+	return problems, nil
+}
+
+// Get returns the template with the given name.
+func (s *Store) Get(name string) (Template, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	t, ok := s.templates[name]
+	return t, ok
+}
+
+// List returns all templates sorted by name.
+func (s *Store) List() []Template {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	list := make([]Template, 0, len(s.templates))
+	for _, t := range s.templates {
+		list = append(list, t)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list
+}
@@ -0,0 +1,185 @@
+package templates
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	data := []byte(`{
+	  "templates": {
+	    "weekly-status": {
+	      "description": "Monday status ping",
+	      "text": "Hi {{name}}! Status for week {{ week }}: {{status}}. Thanks, {{name}}",
+	      "defaults": {"status": "on track"}
+	    },
+	    "invoice": {"text": "Invoice {{number}} for {{amount}} is due."},
+	    "empty": {"text": "   "},
+	    "broken": {"text": "Hello {{user name}}"},
+	    "unclosed": {"text": "Hello {{name"},
+	    "stray": {"text": "Hello name}}"},
+	    "bad-default": {"text": "Hi {{name}}", "defaults": {"nmae": "x"}}
+	  }
+	}`)
+
+	got, problems, err := Parse(data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var names []string
+	for _, tmpl := range got {
+		names = append(names, tmpl.Name)
+	}
+	if want := []string{"invoice", "weekly-status"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("templates = %v, want %v", names, want)
+	}
+
+	wantProblems := []string{
+		`template "bad-default": default given for unknown variable "nmae"`,
+		`template "broken": malformed placeholder`,
+		`template "empty": text is empty`,
+		`template "stray": unmatched }}`,
+		`template "unclosed": malformed placeholder`,
+	}
+	if len(problems) != len(wantProblems) {
+		t.Fatalf("got %d problems, want %d: %v", len(problems), len(wantProblems), problems)
+	}
+	for i, want := range wantProblems {
+		if !strings.HasPrefix(problems[i].Error(), want) {
+			t.Errorf("problem %d = %q, want prefix %q", i, problems[i], want)
+		}
+	}
+}
+
+func TestParseInvalidFile(t *testing.T) {
+	if _, _, err := Parse([]byte(`{"templates": [`)); err == nil {
+		t.Error("expected error for invalid JSON")
+	}
+}
+
+func TestVariablesAndRequired(t *testing.T) {
+	tmpl := Template{
+		Name:     "t",
+		Text:     "{{b}} {{ a }} {{b}} {{c}} {{not valid}}",
+		Defaults: map[string]string{"c": "3"},
+	}
+	if got, want := tmpl.Variables(), []string{"b", "a", "c"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Variables() = %v, want %v", got, want)
+	}
+	if got, want := tmpl.Required(), []string{"b", "a"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Required() = %v, want %v", got, want)
+	}
+	if got := (Template{Text: "plain"}).Required(); got != nil {
+		t.Errorf("Required() for plain text = %v, want nil", got)
+	}
+}
+
+func TestRender(t *testing.T) {
+	tmpl := Template{
+		Name:     "weekly",
+		Text:     "Hi {{name}}, status: {{ status }}. Bye {{name}}",
+		Defaults: map[string]string{"status": "on track"},
+	}
+
+	tests := []struct {
+		name    string
+		vars    map[string]string
+		want    string
+		missing []string
+	}{
+		{"uses default", map[string]string{"name": "Anna"}, "Hi Anna, status: on track. Bye Anna", nil},
+		{"overrides default", map[string]string{"name": "Anna", "status": "late"}, "Hi Anna, status: late. Bye Anna", nil},
+		{"empty value is allowed", map[string]string{"name": ""}, "Hi , status: on track. Bye ", nil},
+		{"extra variables ignored", map[string]string{"name": "Bob", "x": "y"}, "Hi Bob, status: on track. Bye Bob", nil},
+		{"no substitution inside values", map[string]string{"name": "{{status}}"}, "Hi {{status}}, status: on track. Bye {{status}}", nil},
+		{"missing required", nil, "", []string{"name"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tmpl.Render(tt.vars)
+			if tt.missing != nil {
+				var missing *MissingVariablesError
+				if !errors.As(err, &missing) {
+					t.Fatalf("expected MissingVariablesError, got %v", err)
+				}
+				if !reflect.DeepEqual(missing.Missing, tt.missing) {
+					t.Errorf("missing = %v, want %v", missing.Missing, tt.missing)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Render() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMissingVariablesErrorListsAll(t *testing.T) {
+	_, err := Template{Name: "invoice", Text: "{{number}} {{amount}} {{due}}"}.Render(map[string]string{"amount": "5"})
+	if err == nil || err.Error() != `template "invoice" is missing required variables: number, due` {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestStoreLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "templates.json")
+	write := func(content string) {
+		t.Helper()
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	store := NewStore(path)
+	write(`{"templates": {"a": {"text": "A {{x}}"}, "b": {"text": ""}}}`)
+	problems, err := store.Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if len(problems) != 1 {
+		t.Errorf("got %d problems, want 1", len(problems))
+	}
+	if _, ok := store.Get("a"); !ok {
+		t.Error("template a not loaded")
+	}
+
+	// A broken file keeps the previous templates
+	write(`not json`)
+	if _, err := store.Load(); err == nil {
+		t.Error("expected error for invalid file")
+	}
+	if len(store.List()) != 1 {
+		t.Errorf("templates after failed reload = %d, want 1", len(store.List()))
+	}
+
+	// A successful reload replaces the set
+	write(`{"templates": {"c": {"text": "C"}}}`)
+	if _, err := store.Load(); err != nil {
+		t.Fatalf("reload: %v", err)
+	}
+	if _, ok := store.Get("a"); ok {
+		t.Error("template a should be gone after reload")
+	}
+	if _, ok := store.Get("c"); !ok {
+		t.Error("template c not loaded")
+	}
+}
+
+func TestStoreWithoutPath(t *testing.T) {
+	store := NewStore("")
+	if problems, err := store.Load(); err != nil || problems != nil {
+		t.Errorf("Load() = %v, %v; want nil, nil", problems, err)
+	}
+	if len(store.List()) != 0 {
+		t.Error("expected no templates")
+	}
+}
@@ -0,0 +1,302 @@
+package tgclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/gotd/td/tg"
+)
+
+// ChatRefKind is the type of identifier in a ChatRef.
+type ChatRefKind string
+
+// Chat reference kinds.
+const (
+	ChatRefID       ChatRefKind = "id"
+	ChatRefUsername ChatRefKind = "username"
+	ChatRefPhone    ChatRefKind = "phone"
+	ChatRefSelf     ChatRefKind = "self" // the user's own Saved Messages
+)
+
+// ChatRef is a normalized reference to a user, group or channel.
+type ChatRef struct {
+	Kind     ChatRefKind
+	ID       int64  // for ChatRefID, in user-facing format
+	Username string // for ChatRefUsername, without @
+	Phone    string // for ChatRefPhone, digits only
+}
+
+var (
+	usernamePattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_]{3,31}$`)
+	phonePattern    = regexp.MustCompile(`^\+[0-9 ()\-]{7,20}$`)
+)
+
+// tmeHosts are the hosts of Telegram's public links.
+var tmeHosts = map[string]bool{"t.me": true, "telegram.me": true, "telegram.dog": true}
+
+// ParseChatRef detects whether s is a numeric chat ID, @username, bare username,
+// phone number, a t.me / tg://resolve link or "me" for Saved Messages, and
+// normalizes it.
+func ParseChatRef(s string) (ChatRef, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return ChatRef{}, errors.New("identifier is empty")
+	}
+
+	// Too short to be a username, so they can't shadow one
+	if strings.EqualFold(s, "me") || strings.EqualFold(s, "self") {
+		return ChatRef{Kind: ChatRefSelf}, nil
+	}
+
+	// Checked before IDs: ParseInt accepts a leading plus sign
+	if strings.HasPrefix(s, "+") {
+		return parsePhone(s)
+	}
+
+	if id, err := strconv.ParseInt(s, 10, 64); err == nil {
+		if id == 0 {
+			return ChatRef{}, errors.New("chat ID must not be zero")
+		}
+		return ChatRef{Kind: ChatRefID, ID: id}, nil
+	}
+
+	if name, ok := strings.CutPrefix(s, "@"); ok {
+		return parseUsername(name)
+	}
+
+	if strings.Contains(s, "/") || strings.HasPrefix(s, "tg:") {
+		return parseLink(s)
+	}
+
+	return parseUsername(s)
+}
+
+func parsePhone(s string) (ChatRef, error) {
+	if !phonePattern.MatchString(s) {
+		return ChatRef{}, fmt.Errorf("invalid phone number %q", s)
+	}
+	digits := strings.Map(func(r rune) rune {
+		if r >= '0' && r <= '9' {
+			return r
+		}
+		return -1
+	}, s)
+	return ChatRef{Kind: ChatRefPhone, Phone: digits}, nil
+}
+
+func parseUsername(name string) (ChatRef, error) {
+	if !usernamePattern.MatchString(name) {
+		return ChatRef{}, fmt.Errorf("invalid username %q", name)
+	}
+	return ChatRef{Kind: ChatRefUsername, Username: name}, nil
+}
+
+// parseLink handles t.me/<username>[/<msg>], t.me/+<phone>, t.me/c/<channel>[/<msg>]
+// and tg://resolve?domain=<username> links.
+func parseLink(s string) (ChatRef, error) {
+	if !strings.Contains(s, "://") {
+		s = "https://" + s
+	}
+	u, err := url.Parse(s)
+	if err != nil {
+		return ChatRef{}, fmt.Errorf("invalid link: %w", err)
+	}
+
+	if u.Scheme == "tg" {
+		if u.Host != "resolve" {
+			return ChatRef{}, fmt.Errorf("unsupported link %q", s)
+		}
+		if phone := u.Query().Get("phone"); phone != "" {
+			return parsePhone("+" + phone)
+		}
+		return parseUsername(u.Query().Get("domain"))
+	}
+
+	if !tmeHosts[strings.ToLower(strings.TrimPrefix(u.Host, "www."))] {
+		return ChatRef{}, fmt.Errorf("unsupported link host %q", u.Host)
+	}
+
+	parts := strings.Split(strings.Trim(u.Path, "/"), "/")
+	switch {
+	case parts[0] == "":
+		return ChatRef{}, fmt.Errorf("link %q has no username", s)
+	case parts[0] == "c" && len(parts) >= 2:
+		channelID, err := strconv.ParseInt(parts[1], 10, 64)
+		if err != nil || channelID <= 0 {
+			return ChatRef{}, fmt.Errorf("invalid channel link %q", s)
+		}
+		return ChatRef{Kind: ChatRefID, ID: ChannelDialogID(channelID)}, nil
+	case parts[0] == "joinchat" || (strings.HasPrefix(parts[0], "+") && !isDigits(parts[0][1:])):
+		return ChatRef{}, errors.New("invite links cannot be resolved without joining")
+	case strings.HasPrefix(parts[0], "+"):
+		return parsePhone(parts[0])
+	default:
+		return parseUsername(parts[0])
+	}
+}
+
+// invitePattern matches invite hashes: URL-safe base64, as in t.me/+<hash>.
+var invitePattern = regexp.MustCompile(`^[A-Za-z0-9_\-]{8,}$`)
+
+// ParseInviteLink returns the hash of a t.me/+<hash>, t.me/joinchat/<hash> or
+// tg://join?invite=<hash> invite link, or of a bare +<hash>.
+func ParseInviteLink(s string) (string, bool) {
+	s = strings.TrimSpace(s)
+	if hash, ok := strings.CutPrefix(s, "+"); ok {
+		return hash, !isDigits(hash) && invitePattern.MatchString(hash)
+	}
+	if !strings.Contains(s, "://") {
+		s = "https://" + s
+	}
+	u, err := url.Parse(s)
+	if err != nil {
+		return "", false
+	}
+
+	var hash string
+	switch {
+	case u.Scheme == "tg" && u.Host == "join":
+		hash = u.Query().Get("invite")
+	case tmeHosts[strings.ToLower(strings.TrimPrefix(u.Host, "www."))]:
+		parts := strings.Split(strings.Trim(u.Path, "/"), "/")
+		if parts[0] == "joinchat" && len(parts) >= 2 {
+			hash = parts[1]
+		} else if h, ok := strings.CutPrefix(parts[0], "+"); ok && !isDigits(h) {
+			hash = h
+		}
+	}
+	return hash, invitePattern.MatchString(hash)
+}
+
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// cacheKey is the PeerCache key of a username, phone or self reference. Usernames
+// are case-insensitive.
+func (r ChatRef) cacheKey() string {
+	if r.Kind == ChatRefSelf {
+		return "self"
+	}
+	if r.Kind == ChatRefPhone {
+		return "+" + r.Phone
+	}
+	return "@" + strings.ToLower(r.Username)
+}
+
+// CachedChatID returns the dialog ID of a chat reference without making requests:
+// numeric IDs and links as given, usernames and phone numbers only once
+// ResolvePeerFlexible has resolved them.
+func (c *PeerCache) CachedChatID(ref string) (int64, bool) {
+	parsed, err := ParseChatRef(ref)
+	if err != nil {
+		return 0, false
+	}
+	if parsed.Kind == ChatRefID {
+		return parsed.ID, true
+	}
+	dialogID, _, ok := c.ref(parsed.cacheKey())
+	return dialogID, ok
+}
+
+// ResolvePeerFlexible resolves a chat given as a numeric dialog ID, @username, bare
+// username, phone number, t.me link or "me", returning its dialog ID (-100 prefixed
+// for channels) and input peer. "me" is Saved Messages: the user's own ID with
+// InputPeerSelf. Usernames, phone numbers and the own ID are looked up once; their
+// peers are cached, also for ResolvePeer.
+func (c *PeerCache) ResolvePeerFlexible(ctx context.Context, client *tg.Client, ref string) (int64, tg.InputPeerClass, error) {
+	parsed, err := ParseChatRef(ref)
+	if err != nil {
+		return 0, nil, err
+	}
+	if parsed.Kind == ChatRefID {
+		peer, err := c.ResolvePeer(ctx, client, parsed.ID)
+		if err != nil {
+			return 0, nil, err
+		}
+		return parsed.ID, peer, nil
+	}
+
+	key := parsed.cacheKey()
+	if dialogID, peer, ok := c.ref(key); ok {
+		return dialogID, peer, nil
+	}
+
+	var dialogID int64
+	var peer tg.InputPeerClass
+	if parsed.Kind == ChatRefSelf {
+		dialogID, err = selfID(ctx, client)
+		peer = &tg.InputPeerSelf{}
+	} else {
+		var resolved *tg.ContactsResolvedPeer
+		if parsed.Kind == ChatRefPhone {
+			resolved, err = client.ContactsResolvePhone(ctx, parsed.Phone)
+		} else {
+			resolved, err = client.ContactsResolveUsername(ctx, &tg.ContactsResolveUsernameRequest{Username: parsed.Username})
+		}
+		if err == nil {
+			dialogID, peer, err = resolvedInputPeer(resolved)
+		}
+	}
+	if err != nil {
+		return 0, nil, err
+	}
+
+	c.putRef(key, dialogID, peer)
+	return dialogID, peer, nil
+}
+
+// selfID returns the ID of the logged-in user, which is also the dialog ID of their
+// Saved Messages.
+func selfID(ctx context.Context, client *tg.Client) (int64, error) {
+	users, err := client.UsersGetUsers(ctx, []tg.InputUserClass{&tg.InputUserSelf{}})
+	if err != nil {
+		return 0, fmt.Errorf("getting own user: %w", err)
+	}
+	if len(users) == 0 {
+		return 0, errors.New("own user missing from response")
+	}
+	user, ok := users[0].(*tg.User)
+	if !ok {
+		return 0, fmt.Errorf("unexpected user type %T", users[0])
+	}
+	return user.ID, nil
+}
+
+// resolvedInputPeer returns the dialog ID and input peer, with its access hash, of a
+// username or phone lookup result.
+func resolvedInputPeer(resolved *tg.ContactsResolvedPeer) (int64, tg.InputPeerClass, error) {
+	switch p := resolved.Peer.(type) {
+	case *tg.PeerUser:
+		for _, u := range resolved.Users {
+			if user, ok := u.(*tg.User); ok && user.ID == p.UserID {
+				return user.ID, &tg.InputPeerUser{UserID: user.ID, AccessHash: user.AccessHash}, nil
+			}
+		}
+		return 0, nil, fmt.Errorf("user %d missing from response", p.UserID)
+	case *tg.PeerChannel:
+		for _, c := range resolved.Chats {
+			if channel, ok := c.(*tg.Channel); ok && channel.ID == p.ChannelID {
+				return ChannelDialogID(channel.ID), &tg.InputPeerChannel{ChannelID: channel.ID, AccessHash: channel.AccessHash}, nil
+			}
+		}
+		return 0, nil, fmt.Errorf("channel %d missing from response", p.ChannelID)
+	case *tg.PeerChat:
+		return p.ChatID, &tg.InputPeerChat{ChatID: p.ChatID}, nil
+	default:
+		return 0, nil, fmt.Errorf("unexpected peer type %T", resolved.Peer)
+	}
+}
@@ -0,0 +1,180 @@
+package tgclient
+
+import (
+	"testing"
+
+	"github.com/gotd/td/tg"
+)
+
+func TestParseChatRef(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    ChatRef
+		wantErr bool
+	}{
+		// Numeric IDs
+		{input: "123456789", want: ChatRef{Kind: ChatRefID, ID: 123456789}},
+		{input: " -1001234567890 ", want: ChatRef{Kind: ChatRefID, ID: -1001234567890}},
+		{input: "0", wantErr: true},
+
+		// Usernames
+		{input: "@durov", want: ChatRef{Kind: ChatRefUsername, Username: "durov"}},
+		{input: "telegram_bot", want: ChatRef{Kind: ChatRefUsername, Username: "telegram_bot"}},
+		{input: "@ab", wantErr: true},
+		{input: "@1abc", wantErr: true},
+		{input: "hello world", wantErr: true},
+
+		// Phone numbers
+		{input: "+1 (555) 123-4567", want: ChatRef{Kind: ChatRefPhone, Phone: "15551234567"}},
+		{input: "+79991234567", want: ChatRef{Kind: ChatRefPhone, Phone: "79991234567"}},
+		{input: "+12", wantErr: true},
+		{input: "+1 555 CALL NOW", wantErr: true},
+
+		// Links
+		{input: "https://t.me/durov", want: ChatRef{Kind: ChatRefUsername, Username: "durov"}},
+		{input: "t.me/durov/123", want: ChatRef{Kind: ChatRefUsername, Username: "durov"}},
+		{input: "https://telegram.me/durov", want: ChatRef{Kind: ChatRefUsername, Username: "durov"}},
+		{input: "https://t.me/c/1234567890/42", want: ChatRef{Kind: ChatRefID, ID: -1001234567890}},
+		{input: "https://t.me/+79991234567", want: ChatRef{Kind: ChatRefPhone, Phone: "79991234567"}},
+		{input: "tg://resolve?domain=durov", want: ChatRef{Kind: ChatRefUsername, Username: "durov"}},
+		{input: "tg://resolve?phone=79991234567", want: ChatRef{Kind: ChatRefPhone, Phone: "79991234567"}},
+		{input: "https://t.me/+AbCdEf123", wantErr: true},
+		{input: "https://t.me/joinchat/AbCdEf", wantErr: true},
+		{input: "https://example.com/durov", wantErr: true},
+		{input: "https://t.me/", wantErr: true},
+		{input: "tg://msg?to=durov", wantErr: true},
+
+		// Saved Messages
+		{input: "me", want: ChatRef{Kind: ChatRefSelf}},
+		{input: " Self ", want: ChatRef{Kind: ChatRefSelf}},
+		{input: "@me", wantErr: true},
+
+		{input: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseChatRef(tt.input)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected error, got %+v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseInviteLink(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+		ok    bool
+	}{
+		{input: "https://t.me/+AbCdEf123", want: "AbCdEf123", ok: true},
+		{input: "t.me/joinchat/AbCdEf_-12", want: "AbCdEf_-12", ok: true},
+		{input: "tg://join?invite=AbCdEf123", want: "AbCdEf123", ok: true},
+		{input: " +AbCdEf123 ", want: "AbCdEf123", ok: true},
+		{input: "https://t.me/+79991234567"},
+		{input: "+79991234567"},
+		{input: "https://t.me/durov"},
+		{input: "@durov"},
+		{input: "https://example.com/+AbCdEf123"},
+		{input: "https://t.me/joinchat/"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, ok := ParseInviteLink(tt.input)
+			if ok != tt.ok || (ok && got != tt.want) {
+				t.Errorf("ParseInviteLink(%q) = %q, %v, want %q, %v", tt.input, got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
+
+func TestResolvePeerFlexible(t *testing.T) {
+	peers := NewPeerCache(DefaultPeerCacheSize)
+	client, inv := newScriptedClient(map[uint32][]any{
+		tg.ContactsResolveUsernameRequestTypeID: {&tg.ContactsResolvedPeer{
+			Peer:  &tg.PeerChannel{ChannelID: 77},
+			Chats: []tg.ChatClass{&tg.Channel{ID: 77, AccessHash: 7, Title: "Flex", Username: "flexchannel", Photo: &tg.ChatPhotoEmpty{}}},
+		}},
+		tg.ContactsResolvePhoneRequestTypeID: {&tg.ContactsResolvedPeer{
+			Peer:  &tg.PeerUser{UserID: 78},
+			Users: []tg.UserClass{&tg.User{ID: 78, AccessHash: 8, FirstName: "Flex"}},
+		}},
+	})
+
+	want := &tg.InputPeerChannel{ChannelID: 77, AccessHash: 7}
+	for _, ref := range []string{"@flexchannel", "FlexChannel", "https://t.me/flexchannel/5"} {
+		id, peer, err := peers.ResolvePeerFlexible(t.Context(), client, ref)
+		if err != nil {
+			t.Fatalf("ResolvePeerFlexible(%q) error = %v", ref, err)
+		}
+		if id != -1000000000077 || *peer.(*tg.InputPeerChannel) != *want {
+			t.Errorf("ResolvePeerFlexible(%q) = %d, %+v, want %d, %+v", ref, id, peer, int64(-1000000000077), want)
+		}
+	}
+	if got := inv.calls[tg.ContactsResolveUsernameRequestTypeID]; got != 1 {
+		t.Errorf("username resolved %d times, want once", got)
+	}
+
+	// The cached access hash serves plain ID lookups too, without a request
+	peer, err := peers.ResolvePeer(t.Context(), client, -1000000000077)
+	if err != nil || *peer.(*tg.InputPeerChannel) != *want {
+		t.Errorf("ResolvePeer() = %+v, %v, want %+v", peer, err, want)
+	}
+
+	id, peer, err := peers.ResolvePeerFlexible(t.Context(), client, "+1 555 000 1234")
+	if err != nil {
+		t.Fatalf("ResolvePeerFlexible(phone) error = %v", err)
+	}
+	if id != 78 || *peer.(*tg.InputPeerUser) != (tg.InputPeerUser{UserID: 78, AccessHash: 8}) {
+		t.Errorf("ResolvePeerFlexible(phone) = %d, %+v", id, peer)
+	}
+
+	peers.forget(-1000000000077)
+	if _, ok := peers.get(-1000000000077); ok {
+		t.Error("forgotten peer still cached")
+	}
+	if _, _, err := peers.ResolvePeerFlexible(t.Context(), client, "@flexchannel"); err == nil {
+		t.Error("forgotten username served from cache")
+	}
+}
+
+func TestResolvePeerFlexibleSelf(t *testing.T) {
+	peers := NewPeerCache(DefaultPeerCacheSize)
+	client, inv := newScriptedClient(map[uint32][]any{
+		tg.UsersGetUsersRequestTypeID: {&tg.UserClassVector{Elems: []tg.UserClass{&tg.User{ID: 42, Self: true, FirstName: "Me"}}}},
+	})
+
+	for _, ref := range []string{"me", "ME", "self"} {
+		id, peer, err := peers.ResolvePeerFlexible(t.Context(), client, ref)
+		if err != nil {
+			t.Fatalf("ResolvePeerFlexible(%q) error = %v", ref, err)
+		}
+		if _, ok := peer.(*tg.InputPeerSelf); id != 42 || !ok {
+			t.Errorf("ResolvePeerFlexible(%q) = %d, %+v, want 42, InputPeerSelf", ref, id, peer)
+		}
+	}
+	if got := inv.calls[tg.UsersGetUsersRequestTypeID]; got != 1 {
+		t.Errorf("own user fetched %d times, want once", got)
+	}
+
+	// The own ID resolves to Saved Messages without a request
+	if peer, err := peers.ResolvePeer(t.Context(), client, 42); err != nil {
+		t.Errorf("ResolvePeer() error = %v", err)
+	} else if _, ok := peer.(*tg.InputPeerSelf); !ok {
+		t.Errorf("ResolvePeer() = %+v, want InputPeerSelf", peer)
+	}
+	if id, ok := peers.CachedChatID("me"); !ok || id != 42 {
+		t.Errorf("CachedChatID(me) = %d, %v, want 42, true", id, ok)
+	}
+}
@@ -1,65 +1,53 @@
 package tgclient
 
 import (
-	"bufio"
 	"context"
+	"errors"
 	"fmt"
-	"os"
-	"strings"
 	"time"
 
 	"github.com/gotd/contrib/middleware/floodwait"
 	"github.com/gotd/td/telegram"
 	"github.com/gotd/td/telegram/auth"
 	"github.com/gotd/td/tg"
-	"golang.org/x/term"
 )
 
-// Config holds Telegram API credentials
+// Config holds Telegram API credentials and client settings
 type Config struct {
 	APIID   int
 	APIHash string
+	// FloodMaxWait is the longest FLOOD_WAIT waited out; DefaultFloodMaxWait when 0
+	FloodMaxWait time.Duration
 }
 
 // userAuthenticator implements auth.UserAuthenticator
 type userAuthenticator struct {
-	phone string
+	phone       string
+	input       loginInput
+	codeTimeout time.Duration
 }
 
 func (a userAuthenticator) Phone(_ context.Context) (string, error) {
 	return a.phone, nil
 }
 
-func (a userAuthenticator) Code(_ context.Context, _ *tg.AuthSentCode) (string, error) {
-	fmt.Print("Enter login code: ")
-	reader := bufio.NewReader(os.Stdin)
-	code, err := reader.ReadString('\n')
-	if err != nil {
-		return "", fmt.Errorf("reading code: %w", err)
+func (a userAuthenticator) Code(ctx context.Context, _ *tg.AuthSentCode) (string, error) {
+	sentAt := time.Now()
+	codeCtx := ctx
+	if a.codeTimeout > 0 {
+		var cancel context.CancelFunc
+		codeCtx, cancel = context.WithTimeout(ctx, a.codeTimeout)
+		defer cancel()
 	}
-	return strings.TrimSpace(code), nil
-}
-
-func (a userAuthenticator) Password(_ context.Context) (string, error) {
-	fmt.Print("Enter 2FA password: ")
-
-	// Use hidden input if running in a real terminal, otherwise fall back to plain input
-	if term.IsTerminal(int(os.Stdin.Fd())) {
-		password, err := term.ReadPassword(int(os.Stdin.Fd()))
-		fmt.Println() // Print newline after hidden input
-		if err != nil {
-			return "", fmt.Errorf("reading password: %w", err)
-		}
-		return string(password), nil
+	code, err := a.input.Code(codeCtx, sentAt)
+	if err != nil && ctx.Err() == nil && errors.Is(err, context.DeadlineExceeded) {
+		return "", fmt.Errorf("no login code arrived within %s: %s, then run login again", a.codeTimeout, a.input.Missing())
 	}
+	return code, err
+}
 
-	// Fallback for non-TTY environments (e.g., IDE)
-	reader := bufio.NewReader(os.Stdin)
-	password, err := reader.ReadString('\n')
-	if err != nil {
-		return "", fmt.Errorf("reading password: %w", err)
-	}
-	return strings.TrimSpace(password), nil
+func (a userAuthenticator) Password(ctx context.Context) (string, error) {
+	return a.input.Password(ctx)
 }
 
 func (a userAuthenticator) AcceptTermsOfService(_ context.Context, _ tg.HelpTermsOfService) error {
@@ -71,22 +59,25 @@ func (a userAuthenticator) SignUp(_ context.Context) (auth.UserInfo, error) {
 }
 
 // CreateClient creates a new Telegram client with session storage and flood wait handling.
+// updates receives incoming updates while the client runs and may be nil. floodWaits,
+// when not nil, receives each FLOOD_WAIT a request waits out.
 // Returns the client and a floodwait.Waiter that should wrap the client.Run() call.
-func CreateClient(cfg *Config) (*telegram.Client, *floodwait.Waiter) {
+func CreateClient(cfg *Config, updates telegram.UpdateHandler, floodWaits chan<- FloodWait) (*telegram.Client, *floodwait.Waiter) {
 	storage := NewSessionStorage()
-	waiter := floodwait.NewWaiter().WithMaxWait(60 * time.Second)
+	waiter := newFloodWaiter(cfg.FloodMaxWait, floodWaits)
 
 	client := telegram.NewClient(cfg.APIID, cfg.APIHash, telegram.Options{
 		SessionStorage: storage,
 		Middlewares:    []telegram.Middleware{waiter},
+		UpdateHandler:  updates,
 	})
 
 	return client, waiter
 }
 
-// Login performs interactive sign-in to Telegram
-func Login(ctx context.Context, cfg *Config, phone string) error {
-	client, waiter := CreateClient(cfg)
+// Login signs in to Telegram, getting the login code and 2FA password as opts say
+func Login(ctx context.Context, cfg *Config, phone string, opts LoginOptions) error {
+	client, waiter := CreateClient(cfg, nil, nil)
 
 	err := waiter.Run(ctx, func(ctx context.Context) error {
 		return client.Run(ctx, func(ctx context.Context) error {
@@ -106,7 +97,7 @@ func Login(ctx context.Context, cfg *Config, phone string) error {
 
 			// Perform authentication
 			flow := auth.NewFlow(
-				userAuthenticator{phone: phone},
+				userAuthenticator{phone: phone, input: newLoginInput(opts), codeTimeout: opts.CodeTimeout},
 				auth.SendCodeOptions{},
 			)
 
@@ -133,7 +124,7 @@ func Login(ctx context.Context, cfg *Config, phone string) error {
 
 // Logout logs out from Telegram
 func Logout(ctx context.Context, cfg *Config) error {
-	client, waiter := CreateClient(cfg)
+	client, waiter := CreateClient(cfg, nil, nil)
 
 	err := waiter.Run(ctx, func(ctx context.Context) error {
 		return client.Run(ctx, func(ctx context.Context) error {
@@ -0,0 +1,13 @@
+package tgclient
+
+import (
+	"context"
+
+	"github.com/gotd/td/telegram"
+)
+
+// DCConnector opens connections to a specific Telegram data center, for files stored
+// outside the account's home DC. *telegram.Client implements it.
+type DCConnector interface {
+	DC(ctx context.Context, dc int, max int64) (telegram.CloseInvoker, error)
+}
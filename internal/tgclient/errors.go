@@ -0,0 +1,180 @@
+package tgclient
+
+import (
+	"fmt"
+
+	"github.com/gotd/td/tgerr"
+)
+
+// PremiumRequiredError reports that a Telegram API call failed because the
+// account does not have Telegram Premium.
+type PremiumRequiredError struct {
+	Feature     string // human-readable name of the premium-only feature
+	Alternative string // free-tier alternative offered by this server, if any
+	Err         error  // original RPC error
+}
+
+func (e *PremiumRequiredError) Error() string {
+	msg := fmt.Sprintf("requires Telegram Premium: %s", e.Feature)
+	if e.Alternative != "" {
+		msg += ". Free-tier alternative: " + e.Alternative
+	}
+	return msg
+}
+
+func (e *PremiumRequiredError) Unwrap() error {
+	return e.Err
+}
+
+// PeerNotFoundMessage prefixes PeerNotFoundError messages so callers can recognize
+// unresolvable chats in tool results.
+const PeerNotFoundMessage = "chat not found or not accessible"
+
+// PeerNotFoundError reports that a chat ID does not refer to a chat the account can access.
+type PeerNotFoundError struct {
+	Err error // original RPC error
+}
+
+func (e *PeerNotFoundError) Error() string {
+	return fmt.Sprintf("%s (%v)", PeerNotFoundMessage, e.Err)
+}
+
+func (e *PeerNotFoundError) Unwrap() error {
+	return e.Err
+}
+
+// ChatNotFoundMessage is the stable part of ChatNotFoundError messages.
+const ChatNotFoundMessage = "not found in your dialogs"
+
+// ChatNotFoundError reports a positive chat ID that is neither a user the session
+// knows nor a chat in the dialog list, so no usable peer exists for it.
+type ChatNotFoundError struct {
+	ChatID int64
+}
+
+func (e *ChatNotFoundError) Error() string {
+	return fmt.Sprintf("chat %d %s; try ResolveUsername or SearchChats", e.ChatID, ChatNotFoundMessage)
+}
+
+// peerErrors are RPC error types meaning the requested peer is unknown or inaccessible.
+var peerErrors = []string{
+	"PEER_ID_INVALID",
+	"CHAT_ID_INVALID",
+	"CHANNEL_INVALID",
+	"CHANNEL_PRIVATE",
+	"USER_ID_INVALID",
+	"USERNAME_NOT_OCCUPIED",
+	"USERNAME_INVALID",
+}
+
+// IsPeerError reports whether err means Telegram doesn't accept the requested peer,
+// which ClassifyError reports as a PeerNotFoundError.
+func IsPeerError(err error) bool {
+	return tgerr.Is(err, peerErrors...)
+}
+
+// premiumFeature describes a premium-only capability behind an RPC error type.
+type premiumFeature struct {
+	feature     string
+	alternative string
+}
+
+// premiumErrors maps RPC error types returned to free accounts onto the premium
+// feature they hit.
+var premiumErrors = map[string]premiumFeature{
+	"PREMIUM_ACCOUNT_REQUIRED": {
+		feature: "this action is only available to Premium accounts",
+	},
+	"MEDIA_CAPTION_TOO_LONG": {
+		feature:     "captions longer than 1024 characters",
+		alternative: "send the text as a separate message with SendMessage",
+	},
+	"FILE_PARTS_TOO_MUCH": {
+		feature: "uploads larger than 2 GB",
+	},
+	"PINNED_DIALOGS_TOO_MUCH": {
+		feature:     "more than 5 pinned chats",
+		alternative: "unpin another chat first",
+	},
+	"DIALOG_FILTERS_TOO_MUCH": {
+		feature: "more than 10 chat folders",
+	},
+	"CHANNELS_TOO_MUCH": {
+		feature: "joining more than 500 channels and supergroups",
+	},
+}
+
+// RefusedError reports a Telegram API call refused for a reason other than Premium,
+// such as the other user's privacy settings or a used up quota.
+type RefusedError struct {
+	Reason      string // human-readable reason
+	Alternative string // what to do instead, if anything
+	Err         error  // original RPC error
+}
+
+func (e *RefusedError) Error() string {
+	msg := e.Reason
+	if e.Alternative != "" {
+		msg += ". Instead: " + e.Alternative
+	}
+	return msg
+}
+
+func (e *RefusedError) Unwrap() error {
+	return e.Err
+}
+
+// refusal describes why an RPC error type refuses a request.
+type refusal struct {
+	reason      string
+	alternative string
+}
+
+// refusedErrors maps RPC error types that buying Premium wouldn't avoid onto why
+// the request was refused.
+var refusedErrors = map[string]refusal{
+	"VOICE_MESSAGES_FORBIDDEN": {
+		reason:      "the recipient's privacy settings don't allow voice messages",
+		alternative: "send the audio as a file or the content as a text message",
+	},
+	"TRANSCRIPTION_FAILED": {
+		reason:      "Telegram couldn't transcribe the voice message",
+		alternative: "try again later",
+	},
+	"TRANSLATE_REQ_QUOTA_EXCEEDED": {
+		reason:      "Telegram's translation quota is used up for now",
+		alternative: "use TranslateMessages with method 'ai' to translate with the configured AI provider",
+	},
+}
+
+// ClassifyError maps known Telegram RPC errors onto typed errors with clearer
+// explanations. Unknown errors are returned unchanged.
+func ClassifyError(err error) error {
+	if err == nil {
+		return nil
+	}
+	rpcErr, ok := tgerr.As(err)
+	if !ok {
+		return err
+	}
+	if p, ok := premiumErrors[rpcErr.Type]; ok {
+		return &PremiumRequiredError{Feature: p.feature, Alternative: p.alternative, Err: err}
+	}
+	if r, ok := refusedErrors[rpcErr.Type]; ok {
+		return &RefusedError{Reason: r.reason, Alternative: r.alternative, Err: err}
+	}
+	if IsPeerError(err) {
+		return &PeerNotFoundError{Err: err}
+	}
+	return err
+}
+
+// FileMigrateDC reports the DC a file must be downloaded from when err is a
+// FILE_MIGRATE_X error.
+func FileMigrateDC(err error) (int, bool) {
+	rpcErr, ok := tgerr.As(err)
+	if !ok || !rpcErr.IsType("FILE_MIGRATE") {
+		return 0, false
+	}
+	return rpcErr.Argument, true
+}
@@ -0,0 +1,105 @@
+package tgclient
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/gotd/td/tgerr"
+)
+
+func TestClassifyErrorPremium(t *testing.T) {
+	for errType, want := range premiumErrors {
+		t.Run(errType, func(t *testing.T) {
+			rpcErr := tgerr.New(400, errType)
+			err := ClassifyError(fmt.Errorf("sending message: %w", rpcErr))
+
+			var premiumErr *PremiumRequiredError
+			if !errors.As(err, &premiumErr) {
+				t.Fatalf("expected PremiumRequiredError, got %T: %v", err, err)
+			}
+			if premiumErr.Feature != want.feature {
+				t.Errorf("feature = %q, want %q", premiumErr.Feature, want.feature)
+			}
+			if !strings.Contains(err.Error(), "requires Telegram Premium") {
+				t.Errorf("message %q does not mention Telegram Premium", err.Error())
+			}
+			if want.alternative != "" && !strings.Contains(err.Error(), want.alternative) {
+				t.Errorf("message %q does not suggest alternative %q", err.Error(), want.alternative)
+			}
+			if !tgerr.Is(err, errType) {
+				t.Errorf("classified error no longer unwraps to %s", errType)
+			}
+		})
+	}
+}
+
+func TestClassifyErrorRefused(t *testing.T) {
+	for errType, want := range refusedErrors {
+		t.Run(errType, func(t *testing.T) {
+			err := ClassifyError(fmt.Errorf("sending message: %w", tgerr.New(400, errType)))
+
+			var refusedErr *RefusedError
+			if !errors.As(err, &refusedErr) {
+				t.Fatalf("expected RefusedError, got %T: %v", err, err)
+			}
+			if strings.Contains(err.Error(), "Premium") {
+				t.Errorf("message %q blames the missing Premium", err.Error())
+			}
+			if !strings.HasPrefix(err.Error(), want.reason) {
+				t.Errorf("message %q does not start with %q", err.Error(), want.reason)
+			}
+			if !tgerr.Is(err, errType) {
+				t.Errorf("classified error no longer unwraps to %s", errType)
+			}
+		})
+	}
+}
+
+func TestClassifyErrorPeerNotFound(t *testing.T) {
+	for _, errType := range peerErrors {
+		t.Run(errType, func(t *testing.T) {
+			err := ClassifyError(fmt.Errorf("getting messages: %w", tgerr.New(400, errType)))
+
+			var peerErr *PeerNotFoundError
+			if !errors.As(err, &peerErr) {
+				t.Fatalf("expected PeerNotFoundError, got %T: %v", err, err)
+			}
+			if !strings.HasPrefix(err.Error(), PeerNotFoundMessage) {
+				t.Errorf("message %q does not start with %q", err.Error(), PeerNotFoundMessage)
+			}
+		})
+	}
+}
+
+func TestClassifyErrorPassthrough(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+	}{
+		{name: "nil", err: nil},
+		{name: "plain error", err: errors.New("connection reset")},
+		{name: "unrelated rpc error", err: tgerr.New(400, "MESSAGE_ID_INVALID")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ClassifyError(tt.err); got != tt.err {
+				t.Errorf("got %v, want unchanged %v", got, tt.err)
+			}
+		})
+	}
+}
+
+func TestFileMigrateDC(t *testing.T) {
+	dc, ok := FileMigrateDC(fmt.Errorf("downloading: %w", tgerr.New(303, "FILE_MIGRATE_4")))
+	if !ok || dc != 4 {
+		t.Errorf("FileMigrateDC(FILE_MIGRATE_4) = %d, %v; want 4, true", dc, ok)
+	}
+	for _, err := range []error{nil, errors.New("EOF"), tgerr.New(303, "USER_MIGRATE_2"), tgerr.New(400, "FILE_REFERENCE_EXPIRED")} {
+		if dc, ok := FileMigrateDC(err); ok {
+			t.Errorf("FileMigrateDC(%v) = %d, true; want false", err, dc)
+		}
+	}
+}
@@ -0,0 +1,42 @@
+package tgclient
+
+import (
+	"context"
+	"time"
+
+	"github.com/gotd/contrib/middleware/floodwait"
+)
+
+// DefaultFloodMaxWait is the longest FLOOD_WAIT a request waits out before failing.
+const DefaultFloodMaxWait = 60 * time.Second
+
+// FloodWait reports Telegram asking a request to wait before it's retried.
+type FloodWait struct {
+	// Ctx is the context of the waiting request, e.g. that of the tool call making it
+	Ctx      context.Context
+	Duration time.Duration
+}
+
+// newFloodWaiter returns a waiter retrying requests after FLOOD_WAIT errors of up to
+// maxWait (DefaultFloodMaxWait when 0), sending each wait to waits unless it's nil.
+func newFloodWaiter(maxWait time.Duration, waits chan<- FloodWait) *floodwait.Waiter {
+	if maxWait <= 0 {
+		maxWait = DefaultFloodMaxWait
+	}
+	waiter := floodwait.NewWaiter().WithMaxWait(maxWait)
+	if waits != nil {
+		waiter = waiter.WithCallback(forwardFloodWaits(waits))
+	}
+	return waiter
+}
+
+// forwardFloodWaits returns a waiter callback sending waits to ch. A wait is dropped
+// rather than blocking the request when nobody keeps up with ch.
+func forwardFloodWaits(ch chan<- FloodWait) func(context.Context, floodwait.FloodWait) {
+	return func(ctx context.Context, wait floodwait.FloodWait) {
+		select {
+		case ch <- FloodWait{Ctx: ctx, Duration: wait.Duration}:
+		default:
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package tgclient
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gotd/contrib/middleware/floodwait"
+	"github.com/gotd/td/bin"
+	"github.com/gotd/td/tg"
+	"github.com/gotd/td/tgerr"
+)
+
+// floodOnceInvoker fails its first request with FLOOD_WAIT_1.
+type floodOnceInvoker struct {
+	calls int
+}
+
+func (i *floodOnceInvoker) Invoke(_ context.Context, _ bin.Encoder, _ bin.Decoder) error {
+	i.calls++
+	if i.calls == 1 {
+		return tgerr.New(420, "FLOOD_WAIT_1")
+	}
+	return nil
+}
+
+type ctxKey struct{}
+
+func TestFloodWaiterForwardsWaits(t *testing.T) {
+	waits := make(chan FloodWait, 1)
+	waiter := newFloodWaiter(0, waits)
+	invoker := &floodOnceInvoker{}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	err := waiter.Run(ctx, func(ctx context.Context) error {
+		client := tg.NewClient(waiter.Handle(invoker))
+		reqCtx := context.WithValue(ctx, ctxKey{}, "tool call")
+		_, err := client.MessagesReadHistory(reqCtx, &tg.MessagesReadHistoryRequest{Peer: &tg.InputPeerSelf{}})
+		return err
+	})
+	if err != nil {
+		t.Fatalf("request after the wait failed: %v", err)
+	}
+	if invoker.calls != 2 {
+		t.Errorf("made %d requests, want 2", invoker.calls)
+	}
+
+	select {
+	case wait := <-waits:
+		if wait.Duration != time.Second {
+			t.Errorf("wait duration = %v, want 1s", wait.Duration)
+		}
+		if v := wait.Ctx.Value(ctxKey{}); v != "tool call" {
+			t.Errorf("wait context carries %v, want the request's context", v)
+		}
+	default:
+		t.Fatal("no flood wait forwarded")
+	}
+}
+
+func TestForwardFloodWaitsDoesNotBlock(t *testing.T) {
+	waits := make(chan FloodWait, 1)
+	forward := forwardFloodWaits(waits)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		forward(context.Background(), floodwait.FloodWait{Duration: time.Second})
+		forward(context.Background(), floodwait.FloodWait{Duration: 2 * time.Second})
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("forwarding to a full channel blocked")
+	}
+	if wait := <-waits; wait.Duration != time.Second {
+		t.Errorf("forwarded %v, want the first wait of 1s", wait.Duration)
+	}
+}
@@ -4,25 +4,62 @@ package tgclient
 
 import (
 	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
 
 	"github.com/gotd/td/session"
 )
 
-// SessionStorage implements session.Storage using file storage on non-macOS platforms.
+const (
+	plainSessionFile     = "session.json"
+	encryptedSessionFile = "session.enc"
+	// SessionPassphraseEnv holds the passphrase encrypting the session file when no
+	// OS keyring is available.
+	SessionPassphraseEnv = "TELEGRAM_SESSION_PASSPHRASE" //nolint:gosec // variable name, not a credential
+)
+
+// errSecretNotFound reports that the keyring holds no session key yet.
+var errSecretNotFound = errors.New("secret not found")
+
+// secretStore keeps the session key in an OS keyring.
+type secretStore interface {
+	Name() string
+	Get() ([]byte, error) // errSecretNotFound when there is none
+	Set(secret []byte) error
+	Delete() error
+}
+
+// SessionStorage implements session.Storage on platforms other than macOS. The
+// session is kept, in order of preference:
+//
+//  1. in session.enc, encrypted with a random key kept in the OS keyring: the Secret
+//     Service on Linux (through secret-tool) or the Windows Credential Manager;
+//  2. in session.enc, encrypted with a key derived from TELEGRAM_SESSION_PASSPHRASE,
+//     when no keyring is available;
+//  3. in plain session.json otherwise.
+//
+// A plain session.json left from earlier versions is moved into session.enc the
+// first time an encrypted backend loads the session.
 type SessionStorage struct {
-	path string
+	dir        string
+	keyring    secretStore // nil when no keyring is available
+	passphrase string
 }
 
-// NewSessionStorage creates a new SessionStorage with file-based storage.
+// NewSessionStorage creates a new SessionStorage, picking the backend as described
+// on SessionStorage.
 func NewSessionStorage() *SessionStorage {
 	return &SessionStorage{
-		path: getSessionPath(),
+		dir:        getSessionDir(),
+		keyring:    osKeyring(),
+		passphrase: os.Getenv(SessionPassphraseEnv),
 	}
 }
 
-func getSessionPath() string {
+func getSessionDir() string {
 	stateHome := os.Getenv("XDG_STATE_HOME")
 	if stateHome == "" {
 		homeDir, _ := os.UserHomeDir()
@@ -31,35 +68,107 @@ func getSessionPath() string {
 
 	sessionDir := filepath.Join(stateHome, "mcp-telegram")
 	_ = os.MkdirAll(sessionDir, 0o700)
-
-	return filepath.Join(sessionDir, "session.json")
+	return sessionDir
 }
 
-// LoadSession loads session data from file.
-func (s *SessionStorage) LoadSession(_ context.Context) ([]byte, error) {
-	data, err := os.ReadFile(s.path)
-	if os.IsNotExist(err) {
-		return nil, session.ErrNotFound
+// LoadSession loads session data from the chosen backend.
+func (s *SessionStorage) LoadSession(ctx context.Context) ([]byte, error) {
+	file, err := s.encryptedFile(false)
+	if err != nil {
+		return nil, err
 	}
+	if file == nil {
+		return loadPlainSession(s.plainPath())
+	}
+
+	data, err := file.Load()
+	if !errors.Is(err, session.ErrNotFound) {
+		return data, err
+	}
+
+	// Move a plain session from earlier versions into the encrypted file
+	data, err = loadPlainSession(s.plainPath())
 	if err != nil {
 		return nil, err
 	}
-	if len(data) == 0 {
-		return nil, session.ErrNotFound
+	if err := s.StoreSession(ctx, data); err != nil {
+		return nil, fmt.Errorf("encrypting the existing session: %w", err)
+	}
+	if err := os.Remove(s.plainPath()); err != nil {
+		return nil, fmt.Errorf("removing the plain session file: %w", err)
 	}
 	return data, nil
 }
 
-// StoreSession stores session data to file.
+// StoreSession stores session data in the chosen backend.
 func (s *SessionStorage) StoreSession(_ context.Context, data []byte) error {
-	return os.WriteFile(s.path, data, 0o600)
+	file, err := s.encryptedFile(true)
+	if err != nil {
+		return err
+	}
+	if file == nil {
+		return os.WriteFile(s.plainPath(), data, 0o600)
+	}
+	return file.Store(data)
 }
 
-// DeleteSession removes session file.
+// DeleteSession wipes the session from every backend, so none is left behind when
+// the backend in use changed, e.g. after setting a passphrase.
 func (s *SessionStorage) DeleteSession() error {
-	err := os.Remove(s.path)
-	if os.IsNotExist(err) {
-		return nil
+	var errs []error
+	if s.keyring != nil {
+		if err := s.keyring.Delete(); err != nil && !errors.Is(err, errSecretNotFound) {
+			errs = append(errs, fmt.Errorf("deleting session key from %s: %w", s.keyring.Name(), err))
+		}
 	}
-	return err
+	for _, name := range []string{encryptedSessionFile, plainSessionFile} {
+		if err := os.Remove(filepath.Join(s.dir, name)); err != nil && !errors.Is(err, os.ErrNotExist) {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// encryptedFile returns the encrypted session file, or nil when neither a keyring nor
+// a passphrase is available. A keyring without a key yet gets a new one if create is
+// set; otherwise the file has no key and loads as not found.
+func (s *SessionStorage) encryptedFile(create bool) (*encryptedFile, error) {
+	path := filepath.Join(s.dir, encryptedSessionFile)
+	if s.keyring == nil {
+		if s.passphrase == "" {
+			return nil, nil
+		}
+		return &encryptedFile{path: path, passphrase: s.passphrase}, nil
+	}
+
+	key, err := s.keyring.Get()
+	if errors.Is(err, errSecretNotFound) {
+		if !create {
+			return &encryptedFile{path: path}, nil
+		}
+		key = make([]byte, sessionKeySize)
+		if _, err := rand.Read(key); err != nil {
+			return nil, err
+		}
+		err = s.keyring.Set(key)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("session key in %s: %w", s.keyring.Name(), err)
+	}
+	return &encryptedFile{path: path, key: key}, nil
+}
+
+func (s *SessionStorage) plainPath() string {
+	return filepath.Join(s.dir, plainSessionFile)
+}
+
+func loadPlainSession(path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) || (err == nil && len(data) == 0) {
+		return nil, session.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return data, nil
 }
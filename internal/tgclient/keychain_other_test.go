@@ -0,0 +1,90 @@
+//go:build !darwin
+
+package tgclient
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// memoryKeyring is a secretStore kept in memory.
+type memoryKeyring struct {
+	secret []byte
+}
+
+func (*memoryKeyring) Name() string { return "the test keyring" }
+
+func (k *memoryKeyring) Get() ([]byte, error) {
+	if k.secret == nil {
+		return nil, errSecretNotFound
+	}
+	return k.secret, nil
+}
+
+func (k *memoryKeyring) Set(secret []byte) error {
+	k.secret = secret
+	return nil
+}
+
+func (k *memoryKeyring) Delete() error {
+	if k.secret == nil {
+		return errSecretNotFound
+	}
+	k.secret = nil
+	return nil
+}
+
+func TestSessionStorageMigratesPlainSession(t *testing.T) {
+	dir := t.TempDir()
+	data := []byte(`{"Version":1}`)
+	if err := os.WriteFile(filepath.Join(dir, plainSessionFile), data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	keyring := &memoryKeyring{}
+	storage := &SessionStorage{dir: dir, keyring: keyring}
+
+	got, err := storage.LoadSession(context.Background())
+	if err != nil || !bytes.Equal(got, data) {
+		t.Fatalf("LoadSession = %q, %v, want %q", got, err, data)
+	}
+	if _, err := os.Stat(filepath.Join(dir, plainSessionFile)); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("plain session file left behind: %v", err)
+	}
+	if keyring.secret == nil {
+		t.Error("no session key stored in the keyring")
+	}
+	if got, err := storage.LoadSession(context.Background()); err != nil || !bytes.Equal(got, data) {
+		t.Errorf("LoadSession after migration = %q, %v, want %q", got, err, data)
+	}
+}
+
+func TestSessionStorageDeleteSession(t *testing.T) {
+	dir := t.TempDir()
+	keyring := &memoryKeyring{}
+	storage := &SessionStorage{dir: dir, keyring: keyring}
+	if err := storage.StoreSession(context.Background(), []byte("data")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, plainSessionFile), []byte("stale"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := storage.DeleteSession(); err != nil {
+		t.Fatal(err)
+	}
+	if keyring.secret != nil {
+		t.Error("session key left in the keyring")
+	}
+	for _, name := range []string{encryptedSessionFile, plainSessionFile} {
+		if _, err := os.Stat(filepath.Join(dir, name)); !errors.Is(err, os.ErrNotExist) {
+			t.Errorf("%s left behind: %v", name, err)
+		}
+	}
+	if err := storage.DeleteSession(); err != nil {
+		t.Errorf("DeleteSession without a session: %v", err)
+	}
+}
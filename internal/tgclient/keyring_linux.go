@@ -0,0 +1,81 @@
+package tgclient
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// secretToolAttrs identify the session key among the Secret Service's items.
+var secretToolAttrs = []string{"service", "mcp-telegram", "account", "session-key"}
+
+// secretTool keeps the session key in the Secret Service (GNOME Keyring, KWallet,
+// KeePassXC) through the secret-tool command of libsecret.
+type secretTool struct {
+	path string
+}
+
+// osKeyring returns the Secret Service when secret-tool is installed and a session
+// bus answers it, and nil otherwise, e.g. on headless servers.
+func osKeyring() secretStore {
+	if os.Getenv("DBUS_SESSION_BUS_ADDRESS") == "" {
+		return nil
+	}
+	path, err := exec.LookPath("secret-tool")
+	if err != nil {
+		return nil
+	}
+	store := secretTool{path: path}
+	if _, err := store.Get(); err != nil && !errors.Is(err, errSecretNotFound) {
+		return nil
+	}
+	return store
+}
+
+func (secretTool) Name() string {
+	return "the Secret Service"
+}
+
+func (s secretTool) Get() ([]byte, error) {
+	out, err := s.run(nil, "lookup")
+	var exitErr *exec.ExitError
+	// lookup exits with 1 and prints nothing when there is no such secret
+	if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 && len(bytes.TrimSpace(exitErr.Stderr)) == 0 {
+		return nil, errSecretNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	secret, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(out)))
+	if err != nil {
+		return nil, fmt.Errorf("decoding the session key: %w", err)
+	}
+	return secret, nil
+}
+
+func (s secretTool) Set(secret []byte) error {
+	_, err := s.run([]byte(base64.StdEncoding.EncodeToString(secret)), "store", "--label=Telegram MCP Session Key")
+	return err
+}
+
+func (s secretTool) Delete() error {
+	_, err := s.run(nil, "clear")
+	return err
+}
+
+func (s secretTool) run(stdin []byte, args ...string) ([]byte, error) {
+	cmd := exec.Command(s.path, append(args, secretToolAttrs...)...)
+	if stdin != nil {
+		cmd.Stdin = bytes.NewReader(stdin)
+	}
+	out, err := cmd.Output()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && len(exitErr.Stderr) > 0 {
+		return out, fmt.Errorf("secret-tool %s: %w: %s", args[0], err, bytes.TrimSpace(exitErr.Stderr))
+	}
+	return out, err
+}
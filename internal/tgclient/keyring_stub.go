@@ -0,0 +1,8 @@
+//go:build !darwin && !linux && !windows
+
+package tgclient
+
+// osKeyring returns nil: no keyring is supported on this platform.
+func osKeyring() secretStore {
+	return nil
+}
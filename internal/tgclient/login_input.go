@@ -0,0 +1,222 @@
+package tgclient
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"golang.org/x/term"
+)
+
+// Login code sources.
+const (
+	// CodeFromStdin prompts for the code and the 2FA password on standard input.
+	CodeFromStdin = "stdin"
+	// CodeFromEnv reads them from TELEGRAM_LOGIN_CODE and TELEGRAM_2FA_PASSWORD.
+	CodeFromEnv = "env"
+	// CodeFromFilePrefix, followed by a path, polls that file for the code; the 2FA
+	// password comes from TELEGRAM_2FA_PASSWORD.
+	CodeFromFilePrefix = "file:"
+)
+
+// Environment variables read by the env and file code sources.
+const (
+	LoginCodeEnv = "TELEGRAM_LOGIN_CODE"
+	PasswordEnv  = "TELEGRAM_2FA_PASSWORD" //nolint:gosec // variable name, not a credential
+)
+
+const (
+	// DefaultCodeTimeout is how long login waits for the code unless configured otherwise.
+	DefaultCodeTimeout = 5 * time.Minute
+	// codeFilePoll is how often a code file is checked.
+	codeFilePoll = time.Second
+)
+
+// LoginOptions configure how Login gets the login code and the 2FA password.
+type LoginOptions struct {
+	CodeFrom    string        // CodeFromStdin, CodeFromEnv or CodeFromFilePrefix+path
+	CodeTimeout time.Duration // How long to wait for the code; 0 waits indefinitely
+	Stdin       io.Reader     // Read by CodeFromStdin
+}
+
+// ValidateCodeFrom checks a login code source.
+func ValidateCodeFrom(codeFrom string) error {
+	switch {
+	case codeFrom == CodeFromStdin, codeFrom == CodeFromEnv:
+		return nil
+	case strings.HasPrefix(codeFrom, CodeFromFilePrefix):
+		if strings.TrimPrefix(codeFrom, CodeFromFilePrefix) == "" {
+			return errors.New("file: code source needs a path, e.g. file:/run/secrets/telegram-code")
+		}
+		return nil
+	default:
+		return fmt.Errorf("invalid code source %q: must be %q, %q or %q followed by a path", codeFrom, CodeFromStdin, CodeFromEnv, CodeFromFilePrefix)
+	}
+}
+
+// loginInput supplies the login code and the 2FA password.
+type loginInput interface {
+	Code(ctx context.Context, sentAt time.Time) (string, error)
+	Password(ctx context.Context) (string, error)
+	// Missing tells the user how to supply a code that didn't arrive in time.
+	Missing() string
+}
+
+func newLoginInput(opts LoginOptions) loginInput {
+	switch {
+	case opts.CodeFrom == CodeFromEnv:
+		return envInput{}
+	case strings.HasPrefix(opts.CodeFrom, CodeFromFilePrefix):
+		return fileInput{path: strings.TrimPrefix(opts.CodeFrom, CodeFromFilePrefix), poll: codeFilePoll}
+	default:
+		stdin := opts.Stdin
+		if stdin == nil {
+			stdin = os.Stdin
+		}
+		return &stdinInput{in: stdin, reader: bufio.NewReader(stdin)}
+	}
+}
+
+// stdinInput prompts on the terminal, hiding the password when it is one.
+type stdinInput struct {
+	in     io.Reader
+	reader *bufio.Reader
+}
+
+func (s *stdinInput) Code(ctx context.Context, _ time.Time) (string, error) {
+	fmt.Print("Enter login code: ")
+	code, err := s.readLine(ctx)
+	if err != nil {
+		return "", fmt.Errorf("reading code: %w", err)
+	}
+	return code, nil
+}
+
+func (s *stdinInput) Password(ctx context.Context) (string, error) {
+	fmt.Print("Enter 2FA password: ")
+
+	// Use hidden input if running in a real terminal, otherwise fall back to plain input
+	if f, ok := s.in.(*os.File); ok && term.IsTerminal(int(f.Fd())) {
+		password, err := term.ReadPassword(int(f.Fd()))
+		fmt.Println() // Print newline after hidden input
+		if err != nil {
+			return "", fmt.Errorf("reading password: %w", err)
+		}
+		return string(password), nil
+	}
+
+	// Fallback for non-TTY environments (e.g., IDE)
+	password, err := s.readLine(ctx)
+	if err != nil {
+		return "", fmt.Errorf("reading password: %w", err)
+	}
+	return password, nil
+}
+
+// readLine reads a line, giving up when ctx is done. The read itself can't be
+// interrupted, but login ends right after.
+func (s *stdinInput) readLine(ctx context.Context) (string, error) {
+	type line struct {
+		text string
+		err  error
+	}
+	done := make(chan line, 1)
+	go func() {
+		text, err := s.reader.ReadString('\n')
+		if err == io.EOF && text != "" {
+			err = nil
+		}
+		done <- line{strings.TrimSpace(text), err}
+	}()
+	select {
+	case l := <-done:
+		return l.text, l.err
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+}
+
+func (s *stdinInput) Missing() string {
+	return "enter the code Telegram sent you"
+}
+
+// envInput reads the code and password from the environment.
+type envInput struct{}
+
+func (envInput) Code(context.Context, time.Time) (string, error) {
+	return requireEnv(LoginCodeEnv)
+}
+
+func (envInput) Password(context.Context) (string, error) {
+	return requireEnv(PasswordEnv)
+}
+
+func (envInput) Missing() string {
+	return "set " + LoginCodeEnv
+}
+
+// fileInput polls a file for the code. Files older than the code request are left
+// alone, so a code from an earlier login isn't reused.
+type fileInput struct {
+	path string
+	poll time.Duration
+}
+
+func (f fileInput) Code(ctx context.Context, sentAt time.Time) (string, error) {
+	fmt.Printf("Waiting for the login code in %s\n", f.path)
+	ticker := time.NewTicker(f.poll)
+	defer ticker.Stop()
+	for {
+		if code, ok, err := f.read(sentAt); err != nil || ok {
+			return code, err
+		}
+		select {
+		case <-ctx.Done():
+			return "", ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// read returns the code when the file holds one written after sentAt.
+func (f fileInput) read(sentAt time.Time) (string, bool, error) {
+	info, err := os.Stat(f.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, fmt.Errorf("reading code file: %w", err)
+	}
+	// File times may be coarser than the clock, so allow a second of slack
+	if info.ModTime().Before(sentAt.Add(-time.Second)) {
+		return "", false, nil
+	}
+	data, err := os.ReadFile(f.path)
+	if err != nil {
+		return "", false, fmt.Errorf("reading code file: %w", err)
+	}
+	code, _, _ := strings.Cut(strings.TrimSpace(string(data)), "\n")
+	code = strings.TrimSpace(code)
+	return code, code != "", nil
+}
+
+func (fileInput) Password(context.Context) (string, error) {
+	return requireEnv(PasswordEnv)
+}
+
+func (f fileInput) Missing() string {
+	return "write the code Telegram sent you to " + f.path
+}
+
+func requireEnv(name string) (string, error) {
+	value := strings.TrimSpace(os.Getenv(name))
+	if value == "" {
+		return "", fmt.Errorf("%s is not set", name)
+	}
+	return value, nil
+}
@@ -0,0 +1,90 @@
+package tgclient
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestValidateCodeFrom(t *testing.T) {
+	for _, valid := range []string{"stdin", "env", "file:/run/secrets/code"} {
+		if err := ValidateCodeFrom(valid); err != nil {
+			t.Errorf("ValidateCodeFrom(%q): %v", valid, err)
+		}
+	}
+	for _, invalid := range []string{"", "file:", "tty"} {
+		if err := ValidateCodeFrom(invalid); err == nil {
+			t.Errorf("ValidateCodeFrom(%q) accepted it", invalid)
+		}
+	}
+}
+
+func TestStdinInput(t *testing.T) {
+	input := newLoginInput(LoginOptions{CodeFrom: CodeFromStdin, Stdin: strings.NewReader("12345\nsecret")})
+	code, err := input.Code(context.Background(), time.Now())
+	if err != nil || code != "12345" {
+		t.Errorf("Code = %q, %v, want 12345", code, err)
+	}
+	password, err := input.Password(context.Background())
+	if err != nil || password != "secret" {
+		t.Errorf("Password = %q, %v, want secret", password, err)
+	}
+}
+
+func TestEnvInput(t *testing.T) {
+	input := newLoginInput(LoginOptions{CodeFrom: CodeFromEnv})
+	t.Setenv(LoginCodeEnv, "")
+	if _, err := input.Code(context.Background(), time.Now()); err == nil {
+		t.Error("Code succeeded without TELEGRAM_LOGIN_CODE")
+	}
+
+	t.Setenv(LoginCodeEnv, " 12345 ")
+	t.Setenv(PasswordEnv, "secret")
+	if code, err := input.Code(context.Background(), time.Now()); err != nil || code != "12345" {
+		t.Errorf("Code = %q, %v, want 12345", code, err)
+	}
+	if password, err := input.Password(context.Background()); err != nil || password != "secret" {
+		t.Errorf("Password = %q, %v, want secret", password, err)
+	}
+}
+
+func TestFileInputWaitsForCode(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "code")
+	input := fileInput{path: path, poll: 10 * time.Millisecond}
+
+	// A code left from an earlier login is ignored
+	if err := os.WriteFile(path, []byte("11111\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	old := time.Now().Add(-time.Hour)
+	if err := os.Chtimes(path, old, old); err != nil {
+		t.Fatal(err)
+	}
+
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		_ = os.WriteFile(path, []byte("  22222\n"), 0o600)
+	}()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	code, err := input.Code(ctx, time.Now())
+	if err != nil || code != "22222" {
+		t.Errorf("Code = %q, %v, want 22222", code, err)
+	}
+}
+
+func TestAuthenticatorCodeTimeout(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "code")
+	a := userAuthenticator{input: fileInput{path: path, poll: 10 * time.Millisecond}, codeTimeout: 50 * time.Millisecond}
+
+	_, err := a.Code(context.Background(), nil)
+	if err == nil {
+		t.Fatal("Code succeeded without a code")
+	}
+	if want := "no login code arrived within 50ms: write the code Telegram sent you to " + path; !strings.Contains(err.Error(), want) {
+		t.Errorf("error = %q, want it to contain %q", err, want)
+	}
+}
@@ -2,8 +2,14 @@ package tgclient
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"reflect"
 
+	"github.com/gotd/td/telegram/query"
+	"github.com/gotd/td/telegram/query/dialogs"
 	"github.com/gotd/td/tg"
+	"github.com/gotd/td/tgerr"
 )
 
 // ResolvePeer resolves a dialog ID to an InputPeerClass.
@@ -18,31 +24,24 @@ import (
 //   - Bot API / user-facing format adds -100 prefix (e.g., -1001234567890)
 //
 // This function automatically converts from user-facing format to MTProto format.
-func ResolvePeer(ctx context.Context, client *tg.Client, dialogID int64) (tg.InputPeerClass, error) {
-	// Try as user first
+// Resolved peers are cached by dialog ID; WithPeer drops an entry when Telegram
+// doesn't accept it.
+func (c *PeerCache) ResolvePeer(ctx context.Context, client *tg.Client, dialogID int64) (tg.InputPeerClass, error) {
+	if peer, ok := c.get(dialogID); ok {
+		return peer, nil
+	}
+
 	if dialogID > 0 {
-		users, err := client.UsersGetUsers(ctx, []tg.InputUserClass{
-			&tg.InputUser{UserID: dialogID},
-		})
-		if err == nil && len(users) > 0 {
-			if user, ok := users[0].(*tg.User); ok && user.AccessHash != 0 {
-				return &tg.InputPeerUser{
-					UserID:     dialogID,
-					AccessHash: user.AccessHash,
-				}, nil
-			}
+		peer, err := resolvePositivePeer(ctx, client, dialogID)
+		if err != nil {
+			return nil, err
 		}
-
-		return &tg.InputPeerChat{ChatID: dialogID}, nil
+		c.put(dialogID, peer)
+		return peer, nil
 	}
 
-	// Negative IDs are channels or supergroups.
-	// Convert from user-facing format to MTProto format.
-	channelID := -dialogID // Remove minus sign: -(-1001234567890) = 1001234567890
-	if channelID > 1000000000000 {
-		// Has -100 prefix, remove it: 1001234567890 - 1000000000000 = 1234567890
-		channelID -= 1000000000000
-	}
+	// Negative IDs are channels or supergroups
+	channelID := RawChannelID(dialogID)
 
 	channels, err := client.ChannelsGetChannels(ctx, []tg.InputChannelClass{
 		&tg.InputChannel{ChannelID: channelID},
@@ -53,12 +52,208 @@ func ResolvePeer(ctx context.Context, client *tg.Client, dialogID int64) (tg.Inp
 
 	if chats, ok := channels.(*tg.MessagesChats); ok && len(chats.Chats) > 0 {
 		if channel, ok := chats.Chats[0].(*tg.Channel); ok {
-			return &tg.InputPeerChannel{
+			peer := &tg.InputPeerChannel{
 				ChannelID:  channel.ID,
 				AccessHash: channel.AccessHash,
-			}, nil
+			}
+			c.put(dialogID, peer)
+			return peer, nil
 		}
 	}
 
 	return &tg.InputPeerChannel{ChannelID: channelID}, nil
 }
+
+// resolvePositivePeer resolves a positive dialog ID, which is a user or a basic group.
+// Users known to the session are found directly and basic groups through their
+// dialog; users whose access hash the session lacks are looked up in the dialog list.
+func resolvePositivePeer(ctx context.Context, client *tg.Client, dialogID int64) (tg.InputPeerClass, error) {
+	users, err := client.UsersGetUsers(ctx, []tg.InputUserClass{
+		&tg.InputUser{UserID: dialogID},
+	})
+	if err != nil && !IsStaleHashError(err) {
+		return nil, fmt.Errorf("getting user %d: %w", dialogID, err)
+	}
+	if err == nil && len(users) > 0 {
+		if user, ok := users[0].(*tg.User); ok && user.AccessHash != 0 {
+			return &tg.InputPeerUser{UserID: dialogID, AccessHash: user.AccessHash}, nil
+		}
+	}
+
+	chat := &tg.InputPeerChat{ChatID: dialogID}
+	dialogs, err := client.MessagesGetPeerDialogs(ctx, []tg.InputDialogPeerClass{&tg.InputDialogPeer{Peer: chat}})
+	if err != nil && !tgerr.Is(err, peerErrors...) {
+		return nil, fmt.Errorf("getting chat %d: %w", dialogID, err)
+	}
+	if err == nil && len(dialogs.Dialogs) > 0 {
+		return chat, nil
+	}
+
+	peer, err := ResolvePeerFromDialogs(ctx, client, dialogID)
+	if errors.Is(err, errNotInDialogs) {
+		return nil, &ChatNotFoundError{ChatID: dialogID}
+	}
+	return peer, err
+}
+
+// staleHashErrors are RPC error types Telegram returns for a peer whose access hash
+// is stale or missing, e.g. after leaving and rejoining a channel.
+var staleHashErrors = []string{
+	"CHANNEL_INVALID",
+	"USER_ID_INVALID",
+	"PEER_ID_INVALID",
+}
+
+// IsStaleHashError reports whether err may be caused by a stale or missing access hash.
+func IsStaleHashError(err error) bool {
+	return tgerr.Is(err, staleHashErrors...)
+}
+
+// errPeerFound stops the dialog iteration once the peer is found.
+var errPeerFound = errors.New("peer found")
+
+// errNotInDialogs is returned by ResolvePeerFromDialogs for a chat missing from the dialogs.
+var errNotInDialogs = errors.New("not found in dialogs")
+
+// ResolvePeerFromDialogs looks the peer up in the dialog list, which always carries
+// current access hashes. It is slower than ResolvePeer, paging through the dialogs
+// until the chat is found.
+func ResolvePeerFromDialogs(ctx context.Context, client *tg.Client, dialogID int64) (tg.InputPeerClass, error) {
+	var found tg.InputPeerClass
+	err := query.GetDialogs(client).BatchSize(100).ForEach(ctx, func(_ context.Context, dlg dialogs.Elem) error {
+		if id, ok := DialogPeerID(dlg.Peer); ok && id == dialogID {
+			found = dlg.Peer
+			return errPeerFound
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, errPeerFound) {
+		return nil, fmt.Errorf("listing dialogs: %w", err)
+	}
+	if found == nil {
+		return nil, fmt.Errorf("chat %d: %w", dialogID, errNotInDialogs)
+	}
+	return found, nil
+}
+
+// channelIDOffset separates the user-facing ID of a channel, -100 followed by its
+// MTProto ID, from the MTProto ID: -1001234567890 is channel 1234567890.
+const channelIDOffset = 1000000000000
+
+// ChannelDialogID converts an MTProto channel ID to its user-facing dialog ID.
+func ChannelDialogID(channelID int64) int64 {
+	return -channelIDOffset - channelID
+}
+
+// RawChannelID converts the dialog ID of a channel to its MTProto channel ID. IDs
+// without the -100 prefix are taken as the negated MTProto ID.
+func RawChannelID(dialogID int64) int64 {
+	channelID := -dialogID
+	if channelID > channelIDOffset {
+		channelID -= channelIDOffset
+	}
+	return channelID
+}
+
+// DialogPeerID converts an input peer to its dialog ID (-100 prefixed for channels).
+func DialogPeerID(peer tg.InputPeerClass) (int64, bool) {
+	switch p := peer.(type) {
+	case *tg.InputPeerUser:
+		return p.UserID, true
+	case *tg.InputPeerChat:
+		return p.ChatID, true
+	case *tg.InputPeerChannel:
+		return ChannelDialogID(p.ChannelID), true
+	default:
+		return 0, false
+	}
+}
+
+// WithPeer resolves dialogID and calls fn with the peer. When fn fails because
+// Telegram doesn't accept the peer, as ClassifyError tells, the cached peer is
+// dropped. For a stale access hash the peer is then resolved again from the dialog
+// list and fn is retried once with it. Telegram rejects such requests before acting
+// on them, so retrying a mutating call can't apply it twice; any other error is
+// returned as is.
+func (c *PeerCache) WithPeer(ctx context.Context, client *tg.Client, dialogID int64, fn func(peer tg.InputPeerClass) error) error {
+	peer, err := c.ResolvePeer(ctx, client, dialogID)
+	if err != nil {
+		return fmt.Errorf("resolving peer: %w", err)
+	}
+
+	err = fn(peer)
+	if !IsPeerError(err) {
+		return err
+	}
+	c.forget(dialogID)
+	if !IsStaleHashError(err) {
+		return err
+	}
+
+	fresh, resolveErr := ResolvePeerFromDialogs(ctx, client, dialogID)
+	if resolveErr != nil || reflect.DeepEqual(fresh, peer) {
+		// Nothing fresher to retry with; report the original failure
+		return err
+	}
+	c.put(dialogID, fresh)
+	err = fn(fresh)
+	if IsPeerError(err) {
+		c.forget(dialogID)
+	}
+	return err
+}
+
+// WithPeers is WithPeer for calls that take two peers, such as forwarding from one
+// chat to another. Telegram doesn't say which of the peers it rejected, so for a
+// stale access hash both are looked up in the dialog list and fn is retried once
+// with only the peers whose hashes changed replaced; a peer the dialog list still
+// has unchanged stays cached.
+func (c *PeerCache) WithPeers(ctx context.Context, client *tg.Client, firstID, secondID int64, fn func(first, second tg.InputPeerClass) error) error {
+	first, err := c.ResolvePeer(ctx, client, firstID)
+	if err != nil {
+		return fmt.Errorf("resolving peer: %w", err)
+	}
+	second, err := c.ResolvePeer(ctx, client, secondID)
+	if err != nil {
+		return fmt.Errorf("resolving peer: %w", err)
+	}
+
+	err = fn(first, second)
+	if !IsPeerError(err) {
+		return err
+	}
+	if !IsStaleHashError(err) {
+		c.forget(firstID)
+		c.forget(secondID)
+		return err
+	}
+
+	freshFirst, firstChanged := c.refreshPeer(ctx, client, firstID, first)
+	freshSecond, secondChanged := c.refreshPeer(ctx, client, secondID, second)
+	if !firstChanged && !secondChanged {
+		// Nothing fresher to retry with; report the original failure
+		return err
+	}
+	err = fn(freshFirst, freshSecond)
+	if IsPeerError(err) {
+		c.forget(firstID)
+		c.forget(secondID)
+	}
+	return err
+}
+
+// refreshPeer looks dialogID up in the dialog list after Telegram rejected peer,
+// caching and returning the fresh peer and whether it differs. A peer missing from
+// the dialogs is dropped from the cache.
+func (c *PeerCache) refreshPeer(ctx context.Context, client *tg.Client, dialogID int64, peer tg.InputPeerClass) (tg.InputPeerClass, bool) {
+	fresh, err := ResolvePeerFromDialogs(ctx, client, dialogID)
+	if err != nil {
+		c.forget(dialogID)
+		return peer, false
+	}
+	if reflect.DeepEqual(fresh, peer) {
+		return peer, false
+	}
+	c.put(dialogID, fresh)
+	return fresh, true
+}
@@ -0,0 +1,137 @@
+package tgclient
+
+import (
+	"container/list"
+	"sync"
+
+	"github.com/gotd/td/tg"
+)
+
+// DefaultPeerCacheSize is how many chats a server's PeerCache keeps.
+const DefaultPeerCacheSize = 1000
+
+// PeerCache keeps resolved peers: by dialog ID, so repeated ResolvePeer calls for a
+// chat skip the users.getUsers or channels.getChannels request, and by username or
+// phone number, so repeated ResolvePeerFlexible lookups skip the resolve request. It
+// holds the size chats used most recently, and drops a chat when Telegram doesn't
+// accept its peer. A nil PeerCache caches nothing.
+type PeerCache struct {
+	mu    sync.Mutex
+	size  int
+	order *list.List              // Dialog IDs, most recently used first
+	peers map[int64]*list.Element // Values are *cachedPeer
+	refs  map[string]int64        // Dialog IDs by ChatRef.cacheKey
+}
+
+// cachedPeer is a PeerCache entry.
+type cachedPeer struct {
+	dialogID int64
+	peer     tg.InputPeerClass
+}
+
+// NewPeerCache creates a cache of up to size chats.
+func NewPeerCache(size int) *PeerCache {
+	return &PeerCache{
+		size:  max(size, 1),
+		order: list.New(),
+		peers: make(map[int64]*list.Element),
+		refs:  make(map[string]int64),
+	}
+}
+
+// get returns the cached peer of a dialog ID.
+func (c *PeerCache) get(dialogID int64) (tg.InputPeerClass, bool) {
+	if c == nil {
+		return nil, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.getLocked(dialogID)
+}
+
+func (c *PeerCache) getLocked(dialogID int64) (tg.InputPeerClass, bool) {
+	e, ok := c.peers[dialogID]
+	if !ok {
+		return nil, false
+	}
+	c.order.MoveToFront(e)
+	return e.Value.(*cachedPeer).peer, true
+}
+
+// put stores the peer of a dialog ID, dropping the least recently used chat when the
+// cache is full.
+func (c *PeerCache) put(dialogID int64, peer tg.InputPeerClass) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.putLocked(dialogID, peer)
+}
+
+func (c *PeerCache) putLocked(dialogID int64, peer tg.InputPeerClass) {
+	if e, ok := c.peers[dialogID]; ok {
+		e.Value.(*cachedPeer).peer = peer
+		c.order.MoveToFront(e)
+		return
+	}
+	c.peers[dialogID] = c.order.PushFront(&cachedPeer{dialogID: dialogID, peer: peer})
+	if c.order.Len() > c.size {
+		c.forgetLocked(c.order.Back().Value.(*cachedPeer).dialogID)
+	}
+}
+
+// Remember stores the peer of a dialog ID that came with an update, so requests to
+// the chat skip resolving it.
+func (c *PeerCache) Remember(dialogID int64, peer tg.InputPeerClass) {
+	c.put(dialogID, peer)
+}
+
+// ref returns the dialog ID and peer a username, phone or self reference resolved to.
+func (c *PeerCache) ref(key string) (int64, tg.InputPeerClass, bool) {
+	if c == nil {
+		return 0, nil, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	dialogID, ok := c.refs[key]
+	if !ok {
+		return 0, nil, false
+	}
+	peer, ok := c.getLocked(dialogID)
+	return dialogID, peer, ok
+}
+
+// putRef stores the peer a username, phone or self reference resolved to.
+func (c *PeerCache) putRef(key string, dialogID int64, peer tg.InputPeerClass) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.putLocked(dialogID, peer)
+	c.refs[key] = dialogID
+}
+
+// forget drops a cached peer Telegram didn't accept, with the usernames and phone
+// numbers that resolved to it.
+func (c *PeerCache) forget(dialogID int64) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.forgetLocked(dialogID)
+}
+
+func (c *PeerCache) forgetLocked(dialogID int64) {
+	if e, ok := c.peers[dialogID]; ok {
+		c.order.Remove(e)
+		delete(c.peers, dialogID)
+	}
+	for key, id := range c.refs {
+		if id == dialogID {
+			delete(c.refs, key)
+		}
+	}
+}
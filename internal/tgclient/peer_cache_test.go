@@ -0,0 +1,60 @@
+package tgclient
+
+import (
+	"testing"
+
+	"github.com/gotd/td/tg"
+	"github.com/gotd/td/tgerr"
+)
+
+func TestPeerCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	peers := NewPeerCache(2)
+	peers.put(1, &tg.InputPeerChat{ChatID: 1})
+	peers.putRef("@two", 2, &tg.InputPeerUser{UserID: 2, AccessHash: 20})
+	peers.get(1)
+	peers.put(3, &tg.InputPeerChat{ChatID: 3})
+
+	if _, ok := peers.get(2); ok {
+		t.Error("least recently used peer still cached")
+	}
+	if _, ok := peers.CachedChatID("@two"); ok {
+		t.Error("username of an evicted peer still cached")
+	}
+	for _, id := range []int64{1, 3} {
+		if _, ok := peers.get(id); !ok {
+			t.Errorf("peer %d evicted", id)
+		}
+	}
+}
+
+func TestNilPeerCache(t *testing.T) {
+	var peers *PeerCache
+	peers.put(1, &tg.InputPeerChat{ChatID: 1})
+	if _, ok := peers.get(1); ok {
+		t.Error("nil cache returned a peer")
+	}
+	if id, ok := peers.CachedChatID("-1001"); !ok || id != -1001 {
+		t.Errorf("CachedChatID(numeric) = %d, %v", id, ok)
+	}
+}
+
+func TestWithPeerForgetsInaccessiblePeer(t *testing.T) {
+	peers := NewPeerCache(DefaultPeerCacheSize)
+	peers.put(-1000000000005, &tg.InputPeerChannel{ChannelID: 5, AccessHash: 50})
+	client, inv := newScriptedClient(nil)
+
+	calls := 0
+	err := peers.WithPeer(t.Context(), client, -1000000000005, func(tg.InputPeerClass) error {
+		calls++
+		return tgerr.New(400, "CHANNEL_PRIVATE")
+	})
+	if !tgerr.Is(err, "CHANNEL_PRIVATE") || calls != 1 {
+		t.Fatalf("WithPeer() = %v after %d calls, want CHANNEL_PRIVATE after 1", err, calls)
+	}
+	if _, ok := peers.get(-1000000000005); ok {
+		t.Error("inaccessible peer still cached")
+	}
+	if len(inv.calls) != 0 {
+		t.Errorf("made requests %v, want none", inv.calls)
+	}
+}
@@ -0,0 +1,365 @@
+package tgclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/gotd/td/bin"
+	"github.com/gotd/td/tg"
+	"github.com/gotd/td/tgerr"
+)
+
+// scriptedInvoker answers Telegram requests with scripted responses, one per call,
+// queued by request type. A queued error is returned instead of a response.
+type scriptedInvoker struct {
+	script map[uint32][]any
+	calls  map[uint32]int
+}
+
+func newScriptedClient(script map[uint32][]any) (*tg.Client, *scriptedInvoker) {
+	inv := &scriptedInvoker{script: script, calls: make(map[uint32]int)}
+	return tg.NewClient(inv), inv
+}
+
+func (s *scriptedInvoker) Invoke(_ context.Context, input bin.Encoder, output bin.Decoder) error {
+	typed, ok := input.(interface{ TypeID() uint32 })
+	if !ok {
+		return fmt.Errorf("untyped request %T", input)
+	}
+	id := typed.TypeID()
+	s.calls[id]++
+	queue := s.script[id]
+	if len(queue) == 0 {
+		return fmt.Errorf("unexpected request %T", input)
+	}
+	resp := queue[0]
+	s.script[id] = queue[1:]
+
+	if err, ok := resp.(error); ok {
+		return err
+	}
+	var buf bin.Buffer
+	if err := resp.(bin.Encoder).Encode(&buf); err != nil {
+		return err
+	}
+	return output.Decode(&buf)
+}
+
+func TestChannelIDs(t *testing.T) {
+	if got := ChannelDialogID(1234567890); got != -1001234567890 {
+		t.Errorf("ChannelDialogID() = %d, want -1001234567890", got)
+	}
+	for _, dialogID := range []int64{-1001234567890, -1234567890} {
+		if got := RawChannelID(dialogID); got != 1234567890 {
+			t.Errorf("RawChannelID(%d) = %d, want 1234567890", dialogID, got)
+		}
+	}
+	if id, ok := DialogPeerID(&tg.InputPeerChannel{ChannelID: 1234567890}); !ok || id != -1001234567890 {
+		t.Errorf("DialogPeerID(channel) = %d, %v", id, ok)
+	}
+}
+
+func TestIsStaleHashError(t *testing.T) {
+	for _, errType := range staleHashErrors {
+		if !IsStaleHashError(fmt.Errorf("getting messages: %w", tgerr.New(400, errType))) {
+			t.Errorf("IsStaleHashError(%s) = false, want true", errType)
+		}
+	}
+	for _, err := range []error{
+		nil,
+		errors.New("connection reset"),
+		tgerr.New(403, "CHANNEL_PRIVATE"),
+		tgerr.New(400, "MESSAGE_ID_INVALID"),
+		tgerr.New(420, "FLOOD_WAIT_5"),
+	} {
+		if IsStaleHashError(err) {
+			t.Errorf("IsStaleHashError(%v) = true, want false", err)
+		}
+	}
+}
+
+func TestWithPeer(t *testing.T) {
+	const chatID = -1000000000005
+	channels := func(hash int64) *tg.MessagesChats {
+		return &tg.MessagesChats{Chats: []tg.ChatClass{&tg.Channel{ID: 5, AccessHash: hash, Title: "News", Photo: &tg.ChatPhotoEmpty{}}}}
+	}
+	dialogsWith := func(hash int64) *tg.MessagesDialogs {
+		return &tg.MessagesDialogs{
+			Dialogs:  []tg.DialogClass{&tg.Dialog{Peer: &tg.PeerChannel{ChannelID: 5}, TopMessage: 1}},
+			Messages: []tg.MessageClass{&tg.Message{ID: 1, PeerID: &tg.PeerChannel{ChannelID: 5}}},
+			Chats:    []tg.ChatClass{&tg.Channel{ID: 5, AccessHash: hash, Title: "News", Photo: &tg.ChatPhotoEmpty{}}},
+		}
+	}
+	stale := tgerr.New(400, "CHANNEL_INVALID")
+
+	tests := []struct {
+		name        string
+		dialogs     []any
+		results     []error // fn results per call
+		wantHashes  []int64 // access hashes fn was called with
+		wantErr     error
+		wantDialogs int
+	}{
+		{
+			name:       "success needs no lookup",
+			results:    []error{nil},
+			wantHashes: []int64{1},
+		},
+		{
+			name:        "stale hash is re-resolved from dialogs and retried",
+			dialogs:     []any{dialogsWith(2)},
+			results:     []error{stale, nil},
+			wantHashes:  []int64{1, 2},
+			wantDialogs: 1,
+		},
+		{
+			name:        "only one retry",
+			dialogs:     []any{dialogsWith(2)},
+			results:     []error{stale, stale},
+			wantHashes:  []int64{1, 2},
+			wantErr:     stale,
+			wantDialogs: 1,
+		},
+		{
+			name:       "other errors are not retried",
+			results:    []error{tgerr.New(400, "MESSAGE_TOO_LONG")},
+			wantHashes: []int64{1},
+			wantErr:    tgerr.New(400, "MESSAGE_TOO_LONG"),
+		},
+		{
+			name:        "same hash in dialogs is not retried",
+			dialogs:     []any{dialogsWith(1)},
+			results:     []error{stale},
+			wantHashes:  []int64{1},
+			wantErr:     stale,
+			wantDialogs: 1,
+		},
+		{
+			name:        "chat missing from dialogs",
+			dialogs:     []any{&tg.MessagesDialogs{}},
+			results:     []error{stale},
+			wantHashes:  []int64{1},
+			wantErr:     stale,
+			wantDialogs: 1,
+		},
+		{
+			name:        "dialogs lookup fails",
+			dialogs:     []any{errors.New("connection reset")},
+			results:     []error{stale},
+			wantHashes:  []int64{1},
+			wantErr:     stale,
+			wantDialogs: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			peers := NewPeerCache(DefaultPeerCacheSize)
+			client, inv := newScriptedClient(map[uint32][]any{
+				tg.ChannelsGetChannelsRequestTypeID: {channels(1)},
+				tg.MessagesGetDialogsRequestTypeID:  tt.dialogs,
+			})
+
+			var hashes []int64
+			err := peers.WithPeer(t.Context(), client, chatID, func(peer tg.InputPeerClass) error {
+				channel, ok := peer.(*tg.InputPeerChannel)
+				if !ok {
+					t.Fatalf("peer = %T, want *tg.InputPeerChannel", peer)
+				}
+				hashes = append(hashes, channel.AccessHash)
+				return tt.results[len(hashes)-1]
+			})
+
+			if tt.wantErr == nil && err != nil {
+				t.Fatalf("WithPeer() error = %v", err)
+			}
+			if tt.wantErr != nil && (err == nil || err.Error() != tt.wantErr.Error()) {
+				t.Fatalf("WithPeer() error = %v, want %v", err, tt.wantErr)
+			}
+			if fmt.Sprint(hashes) != fmt.Sprint(tt.wantHashes) {
+				t.Errorf("called with access hashes %v, want %v", hashes, tt.wantHashes)
+			}
+			if got := inv.calls[tg.MessagesGetDialogsRequestTypeID]; got != tt.wantDialogs {
+				t.Errorf("dialogs requested %d times, want %d", got, tt.wantDialogs)
+			}
+		})
+	}
+}
+
+func TestWithPeers(t *testing.T) {
+	const fromID, toID = -1000000000005, -1000000000006
+	channel := func(id, hash int64) *tg.Channel {
+		return &tg.Channel{ID: id, AccessHash: hash, Title: "News", Photo: &tg.ChatPhotoEmpty{}}
+	}
+	dialogs := &tg.MessagesDialogs{
+		Dialogs: []tg.DialogClass{
+			&tg.Dialog{Peer: &tg.PeerChannel{ChannelID: 5}, TopMessage: 1},
+			&tg.Dialog{Peer: &tg.PeerChannel{ChannelID: 6}, TopMessage: 2},
+		},
+		Messages: []tg.MessageClass{
+			&tg.Message{ID: 1, PeerID: &tg.PeerChannel{ChannelID: 5}},
+			&tg.Message{ID: 2, PeerID: &tg.PeerChannel{ChannelID: 6}},
+		},
+		Chats: []tg.ChatClass{channel(5, 1), channel(6, 3)},
+	}
+	stale := tgerr.New(400, "CHANNEL_INVALID")
+
+	peers := NewPeerCache(DefaultPeerCacheSize)
+	client, inv := newScriptedClient(map[uint32][]any{
+		tg.ChannelsGetChannelsRequestTypeID: {
+			&tg.MessagesChats{Chats: []tg.ChatClass{channel(5, 1)}},
+			&tg.MessagesChats{Chats: []tg.ChatClass{channel(6, 2)}},
+		},
+		tg.MessagesGetDialogsRequestTypeID: {dialogs, dialogs},
+	})
+
+	var calls []string
+	err := peers.WithPeers(t.Context(), client, fromID, toID, func(from, to tg.InputPeerClass) error {
+		calls = append(calls, fmt.Sprintf("%d->%d", from.(*tg.InputPeerChannel).AccessHash, to.(*tg.InputPeerChannel).AccessHash))
+		if len(calls) == 1 {
+			return stale
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("WithPeers() error = %v", err)
+	}
+	if want := "[1->2 1->3]"; fmt.Sprint(calls) != want {
+		t.Errorf("called with access hashes %v, want %s", calls, want)
+	}
+
+	// Both peers are cached now: the unchanged one was kept, the stale one replaced
+	for id, want := range map[int64]int64{fromID: 1, toID: 3} {
+		peer, err := peers.ResolvePeer(t.Context(), client, id)
+		if err != nil {
+			t.Fatalf("ResolvePeer(%d) error = %v", id, err)
+		}
+		if got := peer.(*tg.InputPeerChannel).AccessHash; got != want {
+			t.Errorf("cached access hash of %d = %d, want %d", id, got, want)
+		}
+	}
+	if got := inv.calls[tg.ChannelsGetChannelsRequestTypeID]; got != 2 {
+		t.Errorf("channels requested %d times, want 2", got)
+	}
+}
+
+func TestResolvePeerCache(t *testing.T) {
+	peers := NewPeerCache(DefaultPeerCacheSize)
+	client, inv := newScriptedClient(map[uint32][]any{
+		tg.UsersGetUsersRequestTypeID: {&tg.UserClassVector{Elems: []tg.UserClass{&tg.User{ID: 10, AccessHash: 100}}}},
+		tg.ChannelsGetChannelsRequestTypeID: {
+			&tg.MessagesChats{Chats: []tg.ChatClass{&tg.Channel{ID: 5, AccessHash: 50, Title: "News", Photo: &tg.ChatPhotoEmpty{}}}},
+			&tg.MessagesChats{Chats: []tg.ChatClass{&tg.Channel{ID: 5, AccessHash: 51, Title: "News", Photo: &tg.ChatPhotoEmpty{}}}},
+		},
+		tg.MessagesGetDialogsRequestTypeID: {&tg.MessagesDialogs{}},
+	})
+
+	for range 3 {
+		user, err := peers.ResolvePeer(t.Context(), client, 10)
+		if err != nil || user.(*tg.InputPeerUser).AccessHash != 100 {
+			t.Fatalf("ResolvePeer(user) = %+v, %v", user, err)
+		}
+		channel, err := peers.ResolvePeer(t.Context(), client, -1000000000005)
+		if err != nil || channel.(*tg.InputPeerChannel).AccessHash != 50 {
+			t.Fatalf("ResolvePeer(channel) = %+v, %v", channel, err)
+		}
+	}
+	if users, channels := inv.calls[tg.UsersGetUsersRequestTypeID], inv.calls[tg.ChannelsGetChannelsRequestTypeID]; users != 1 || channels != 1 {
+		t.Errorf("resolved user %d times and channel %d times, want once each", users, channels)
+	}
+
+	// A rejected access hash drops the entry, so the next resolve asks again
+	err := peers.WithPeer(t.Context(), client, -1000000000005, func(tg.InputPeerClass) error {
+		return tgerr.New(400, "PEER_ID_INVALID")
+	})
+	if !IsStaleHashError(err) {
+		t.Fatalf("WithPeer() error = %v, want the stale hash error", err)
+	}
+	channel, err := peers.ResolvePeer(t.Context(), client, -1000000000005)
+	if err != nil || channel.(*tg.InputPeerChannel).AccessHash != 51 {
+		t.Errorf("ResolvePeer(channel) after invalidation = %+v, %v, want hash 51", channel, err)
+	}
+	if got := inv.calls[tg.ChannelsGetChannelsRequestTypeID]; got != 2 {
+		t.Errorf("channel resolved %d times, want 2", got)
+	}
+}
+
+func TestResolvePositivePeer(t *testing.T) {
+	noUser := &tg.UserClassVector{}
+	noDialog := &tg.MessagesPeerDialogs{}
+	dialogsWithUser := &tg.MessagesDialogs{
+		Dialogs:  []tg.DialogClass{&tg.Dialog{Peer: &tg.PeerUser{UserID: 12}, TopMessage: 1}},
+		Messages: []tg.MessageClass{&tg.Message{ID: 1, PeerID: &tg.PeerUser{UserID: 12}}},
+		Users:    []tg.UserClass{&tg.User{ID: 12, AccessHash: 120}},
+	}
+
+	tests := []struct {
+		name    string
+		script  map[uint32][]any
+		want    tg.InputPeerClass
+		wantErr string
+	}{
+		{
+			name:   "known user",
+			script: map[uint32][]any{tg.UsersGetUsersRequestTypeID: {&tg.UserClassVector{Elems: []tg.UserClass{&tg.User{ID: 12, AccessHash: 120}}}}},
+			want:   &tg.InputPeerUser{UserID: 12, AccessHash: 120},
+		},
+		{
+			name: "basic group in dialogs",
+			script: map[uint32][]any{
+				tg.UsersGetUsersRequestTypeID:          {noUser},
+				tg.MessagesGetPeerDialogsRequestTypeID: {&tg.MessagesPeerDialogs{Dialogs: []tg.DialogClass{&tg.Dialog{Peer: &tg.PeerChat{ChatID: 12}}}}},
+			},
+			want: &tg.InputPeerChat{ChatID: 12},
+		},
+		{
+			name: "user only known from dialogs",
+			script: map[uint32][]any{
+				tg.UsersGetUsersRequestTypeID:          {tgerr.New(400, "USER_ID_INVALID")},
+				tg.MessagesGetPeerDialogsRequestTypeID: {tgerr.New(400, "PEER_ID_INVALID")},
+				tg.MessagesGetDialogsRequestTypeID:     {dialogsWithUser},
+			},
+			want: &tg.InputPeerUser{UserID: 12, AccessHash: 120},
+		},
+		{
+			name: "unknown chat",
+			script: map[uint32][]any{
+				tg.UsersGetUsersRequestTypeID:          {noUser},
+				tg.MessagesGetPeerDialogsRequestTypeID: {noDialog},
+				tg.MessagesGetDialogsRequestTypeID:     {&tg.MessagesDialogs{}},
+			},
+			wantErr: "chat 12 not found in your dialogs; try ResolveUsername or SearchChats",
+		},
+		{
+			name:    "lookup failure is not reported as a missing chat",
+			script:  map[uint32][]any{tg.UsersGetUsersRequestTypeID: {errors.New("connection reset")}},
+			wantErr: "getting user 12: connection reset",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			peers := NewPeerCache(DefaultPeerCacheSize)
+			client, _ := newScriptedClient(tt.script)
+
+			peer, err := peers.ResolvePeer(t.Context(), client, 12)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("ResolvePeer() error = %v, want %q", err, tt.wantErr)
+				}
+				if _, cached := peers.get(12); cached {
+					t.Error("failed resolve was cached")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ResolvePeer() error = %v", err)
+			}
+			if fmt.Sprint(peer) != fmt.Sprint(tt.want) {
+				t.Errorf("ResolvePeer() = %v, want %v", peer, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,39 @@
+package tgclient
+
+import (
+	"context"
+
+	"github.com/gotd/td/bin"
+	"github.com/gotd/td/tg"
+	"go.uber.org/ratelimit"
+)
+
+// DefaultRPS is the default number of Telegram requests per second, low enough to
+// avoid FLOOD_WAIT on long paginations.
+const DefaultRPS = 1
+
+// NewLimiter returns the limiter shared by all paced Telegram requests, allowing rps
+// requests per second, or any number when rps is 0 or less.
+func NewLimiter(rps int, opts ...ratelimit.Option) ratelimit.Limiter {
+	if rps <= 0 {
+		return ratelimit.NewUnlimited()
+	}
+	return ratelimit.New(rps, opts...)
+}
+
+// Paced returns a client whose every request first waits for limiter, for loops
+// making many requests through helpers that take a *tg.Client, e.g. dialog paging.
+func Paced(client *tg.Client, limiter ratelimit.Limiter) *tg.Client {
+	return tg.NewClient(pacedInvoker{next: client.Invoker(), limiter: limiter})
+}
+
+// pacedInvoker waits for the limiter before each request.
+type pacedInvoker struct {
+	next    tg.Invoker
+	limiter ratelimit.Limiter
+}
+
+func (i pacedInvoker) Invoke(ctx context.Context, input bin.Encoder, output bin.Decoder) error {
+	i.limiter.Take()
+	return i.next.Invoke(ctx, input, output)
+}
@@ -0,0 +1,71 @@
+package tgclient
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gotd/td/bin"
+	"github.com/gotd/td/tg"
+	"go.uber.org/ratelimit"
+)
+
+// fakeClock is a ratelimit.Clock whose Sleep advances time instantly.
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time { return c.now }
+
+func (c *fakeClock) Sleep(d time.Duration) { c.now = c.now.Add(d) }
+
+// recordingInvoker records the fake time of each request.
+type recordingInvoker struct {
+	clock *fakeClock
+	times []time.Time
+}
+
+func (i *recordingInvoker) Invoke(_ context.Context, _ bin.Encoder, _ bin.Decoder) error {
+	i.times = append(i.times, i.clock.Now())
+	return nil
+}
+
+func TestPacedSpacesRequests(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(1_700_000_000, 0)}
+	invoker := &recordingInvoker{clock: clock}
+	limiter := NewLimiter(4, ratelimit.WithClock(clock))
+	client := Paced(tg.NewClient(invoker), limiter)
+
+	for range 5 {
+		if _, err := client.MessagesReadHistory(context.Background(), &tg.MessagesReadHistoryRequest{Peer: &tg.InputPeerSelf{}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if len(invoker.times) != 5 {
+		t.Fatalf("made %d requests, want 5", len(invoker.times))
+	}
+	for i := 1; i < len(invoker.times); i++ {
+		if gap := invoker.times[i].Sub(invoker.times[i-1]); gap < 250*time.Millisecond {
+			t.Errorf("request %d came %v after the previous one, want at least 250ms", i, gap)
+		}
+	}
+	if total := invoker.times[4].Sub(invoker.times[0]); total != time.Second {
+		t.Errorf("5 requests at 4 RPS took %v, want 1s", total)
+	}
+}
+
+func TestNewLimiterUnlimited(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(1_700_000_000, 0)}
+	invoker := &recordingInvoker{clock: clock}
+	client := Paced(tg.NewClient(invoker), NewLimiter(0, ratelimit.WithClock(clock)))
+
+	for range 3 {
+		if _, err := client.MessagesReadHistory(context.Background(), &tg.MessagesReadHistoryRequest{Peer: &tg.InputPeerSelf{}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if !invoker.times[2].Equal(invoker.times[0]) {
+		t.Errorf("unlimited requests were delayed by %v", invoker.times[2].Sub(invoker.times[0]))
+	}
+}
@@ -0,0 +1,148 @@
+package tgclient
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/gotd/td/session"
+	"golang.org/x/crypto/scrypt"
+)
+
+// Encrypted session files start with sessionFileMagic, a byte saying how the key is
+// obtained, the scrypt salt and the AES-GCM nonce; the header is authenticated too.
+var sessionFileMagic = []byte("MCPTGSE1")
+
+// Key kinds of an encrypted session file.
+const (
+	sessionKeyRaw        byte = 0 // The key is used as is, e.g. one kept in the OS keyring
+	sessionKeyPassphrase byte = 1 // The key is derived from a passphrase with scrypt
+)
+
+const (
+	sessionKeySize  = 32
+	sessionSaltSize = 16
+	// scrypt parameters recommended for interactive logins as of 2017
+	scryptN = 1 << 15
+	scryptR = 8
+	scryptP = 1
+)
+
+// errWrongSessionKey reports a session file that doesn't decrypt with the key given.
+var errWrongSessionKey = errors.New("wrong key or passphrase, or the file is corrupted")
+
+// encryptedFile keeps session data in a file encrypted with AES-256-GCM, under either
+// a key (e.g. a random one kept in the OS keyring) or a passphrase.
+type encryptedFile struct {
+	path       string
+	key        []byte // Used when passphrase is empty
+	passphrase string
+}
+
+// Load decrypts the session file, returning session.ErrNotFound when there is none
+// or no key to read it with.
+func (f *encryptedFile) Load() ([]byte, error) {
+	if f.key == nil && f.passphrase == "" {
+		return nil, session.ErrNotFound
+	}
+	data, err := os.ReadFile(f.path)
+	if errors.Is(err, os.ErrNotExist) || (err == nil && len(data) == 0) {
+		return nil, session.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	headerSize := len(sessionFileMagic) + 1 + sessionSaltSize
+	if len(data) < headerSize || !bytes.HasPrefix(data, sessionFileMagic) {
+		return nil, fmt.Errorf("%s is not an encrypted session file", f.path)
+	}
+	kind := data[len(sessionFileMagic)]
+	salt := data[len(sessionFileMagic)+1 : headerSize]
+	if want := f.kind(); kind != want {
+		return nil, fmt.Errorf("decrypting %s: %w", f.path, errWrongSessionKey)
+	}
+	gcm, err := f.cipher(salt)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < headerSize+gcm.NonceSize() {
+		return nil, fmt.Errorf("%s is truncated", f.path)
+	}
+	nonce := data[headerSize : headerSize+gcm.NonceSize()]
+	plain, err := gcm.Open(nil, nonce, data[headerSize+gcm.NonceSize():], data[:headerSize])
+	if err != nil {
+		return nil, fmt.Errorf("decrypting %s: %w", f.path, errWrongSessionKey)
+	}
+	return plain, nil
+}
+
+// Store encrypts data into the session file, replacing it atomically.
+func (f *encryptedFile) Store(data []byte) error {
+	salt := make([]byte, sessionSaltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return err
+	}
+	gcm, err := f.cipher(salt)
+	if err != nil {
+		return err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return err
+	}
+
+	header := append(append(append([]byte{}, sessionFileMagic...), f.kind()), salt...)
+	out := append(append(header, nonce...), gcm.Seal(nil, nonce, data, header)...)
+
+	tmp, err := os.CreateTemp(filepath.Dir(f.path), ".session-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(out); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), f.path)
+}
+
+// Delete removes the session file.
+func (f *encryptedFile) Delete() error {
+	err := os.Remove(f.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+func (f *encryptedFile) kind() byte {
+	if f.passphrase != "" {
+		return sessionKeyPassphrase
+	}
+	return sessionKeyRaw
+}
+
+func (f *encryptedFile) cipher(salt []byte) (cipher.AEAD, error) {
+	key := f.key
+	if f.passphrase != "" {
+		var err error
+		key, err = scrypt.Key([]byte(f.passphrase), salt, scryptN, scryptR, scryptP, sessionKeySize)
+		if err != nil {
+			return nil, fmt.Errorf("deriving session key: %w", err)
+		}
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("creating session cipher: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
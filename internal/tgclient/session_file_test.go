@@ -0,0 +1,90 @@
+package tgclient
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gotd/td/session"
+)
+
+func TestEncryptedFileRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "session.enc")
+	file := &encryptedFile{path: path, passphrase: "correct horse"}
+	data := []byte(`{"Version":1}`)
+
+	if err := file.Store(data); err != nil {
+		t.Fatal(err)
+	}
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(raw, data) {
+		t.Error("session file holds the session in plain text")
+	}
+
+	got, err := file.Load()
+	if err != nil || !bytes.Equal(got, data) {
+		t.Errorf("Load = %q, %v, want %q", got, err, data)
+	}
+}
+
+func TestEncryptedFileWrongPassphrase(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "session.enc")
+	if err := (&encryptedFile{path: path, passphrase: "correct horse"}).Store([]byte("data")); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := (&encryptedFile{path: path, passphrase: "battery staple"}).Load()
+	if !errors.Is(err, errWrongSessionKey) {
+		t.Errorf("Load with a wrong passphrase = %v, want errWrongSessionKey", err)
+	}
+	_, err = (&encryptedFile{path: path, key: make([]byte, sessionKeySize)}).Load()
+	if !errors.Is(err, errWrongSessionKey) {
+		t.Errorf("Load with a key instead of the passphrase = %v, want errWrongSessionKey", err)
+	}
+}
+
+func TestEncryptedFileTampered(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "session.enc")
+	file := &encryptedFile{path: path, key: bytes.Repeat([]byte{7}, sessionKeySize)}
+	if err := file.Store([]byte("data")); err != nil {
+		t.Fatal(err)
+	}
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw[len(raw)-1] ^= 0xff
+	if err := os.WriteFile(path, raw, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := file.Load(); !errors.Is(err, errWrongSessionKey) {
+		t.Errorf("Load of a tampered file = %v, want errWrongSessionKey", err)
+	}
+}
+
+func TestEncryptedFileMissing(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "session.enc")
+	file := &encryptedFile{path: path, passphrase: "correct horse"}
+	if _, err := file.Load(); !errors.Is(err, session.ErrNotFound) {
+		t.Errorf("Load of a missing file = %v, want session.ErrNotFound", err)
+	}
+
+	if err := file.Store([]byte("data")); err != nil {
+		t.Fatal(err)
+	}
+	if err := file.Delete(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := file.Load(); !errors.Is(err, session.ErrNotFound) {
+		t.Errorf("Load after Delete = %v, want session.ErrNotFound", err)
+	}
+	if err := file.Delete(); err != nil {
+		t.Errorf("Delete of a missing file: %v", err)
+	}
+}
@@ -0,0 +1,84 @@
+package tgclient
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/gotd/td/tg"
+)
+
+// ConnState is the state of the server's Telegram connection.
+type ConnState string
+
+// Connection states.
+const (
+	ConnConnecting   ConnState = "connecting"
+	ConnAuthorized   ConnState = "authorized"
+	ConnUnauthorized ConnState = "unauthorized"
+	ConnFailed       ConnState = "failed"
+)
+
+// ErrNotAuthorized reports that the stored session isn't logged in to Telegram.
+var ErrNotAuthorized = errors.New("not authorized: run 'mcp-telegram login' in a terminal, then restart the MCP server")
+
+// Status tracks a Telegram connection made in the background, so requests arriving
+// before it is ready can wait for it and those arriving after it failed can say why.
+type Status struct {
+	mu    sync.Mutex
+	state ConnState
+	self  *tg.User
+	err   error
+	done  chan struct{} // Closed once the state is no longer ConnConnecting
+}
+
+// NewStatus returns the status of a connection that is being made.
+func NewStatus() *Status {
+	return &Status{state: ConnConnecting, done: make(chan struct{})}
+}
+
+// SetAuthorized records a connection logged in as self.
+func (s *Status) SetAuthorized(self *tg.User) {
+	s.set(ConnAuthorized, self, nil)
+}
+
+// SetUnauthorized records a connection whose session isn't logged in.
+func (s *Status) SetUnauthorized() {
+	s.set(ConnUnauthorized, nil, ErrNotAuthorized)
+}
+
+// SetFailed records a connection that couldn't be made or was lost.
+func (s *Status) SetFailed(err error) {
+	s.set(ConnFailed, nil, err)
+}
+
+func (s *Status) set(state ConnState, self *tg.User, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.state, s.self, s.err = state, self, err
+	select {
+	case <-s.done:
+	default:
+		close(s.done)
+	}
+}
+
+// Get returns the connection state, the logged-in user when authorized and the
+// error when unauthorized or failed.
+func (s *Status) Get() (ConnState, *tg.User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.state, s.self, s.err
+}
+
+// Wait waits until the connection is made, returning nil once it is authorized and
+// the reason otherwise.
+func (s *Status) Wait(ctx context.Context) error {
+	select {
+	case <-s.done:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	_, _, err := s.Get()
+	return err
+}
@@ -0,0 +1,48 @@
+package tgclient
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/gotd/td/tg"
+)
+
+func TestStatusWait(t *testing.T) {
+	s := NewStatus()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := s.Wait(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Wait while connecting = %v, want the context's error", err)
+	}
+
+	done := make(chan error, 1)
+	go func() { done <- s.Wait(context.Background()) }()
+	s.SetAuthorized(&tg.User{ID: 1, Username: "alice"})
+	if err := <-done; err != nil {
+		t.Errorf("Wait once authorized = %v, want nil", err)
+	}
+	if state, self, _ := s.Get(); state != ConnAuthorized || self.Username != "alice" {
+		t.Errorf("Get = %s, %v, want authorized as alice", state, self)
+	}
+}
+
+func TestStatusWaitFailures(t *testing.T) {
+	s := NewStatus()
+	s.SetUnauthorized()
+	if err := s.Wait(context.Background()); !errors.Is(err, ErrNotAuthorized) {
+		t.Errorf("Wait when unauthorized = %v, want ErrNotAuthorized", err)
+	}
+
+	// A connection lost later replaces the earlier state
+	connErr := errors.New("connection reset")
+	s.SetFailed(connErr)
+	if err := s.Wait(context.Background()); !errors.Is(err, connErr) {
+		t.Errorf("Wait after failure = %v, want %v", err, connErr)
+	}
+	if state, _, _ := s.Get(); state != ConnFailed {
+		t.Errorf("state = %s, want %s", state, ConnFailed)
+	}
+}
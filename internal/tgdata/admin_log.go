@@ -0,0 +1,254 @@
+package tgdata
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gotd/td/tg"
+
+	"github.com/tolmachov/mcp-telegram/internal/messages"
+)
+
+// Admin log event types of AdminLogQuery.Types.
+const (
+	AdminLogJoins     = "joins"     // Members joining or being added
+	AdminLogLeaves    = "leaves"    // Members leaving
+	AdminLogEdits     = "edits"     // Edited messages and chat info
+	AdminLogDeletes   = "deletes"   // Deleted messages
+	AdminLogRestricts = "restricts" // Bans, restrictions and removals, and lifting them
+)
+
+// AdminLogTypes lists the admin log event types in display order.
+var AdminLogTypes = []string{AdminLogJoins, AdminLogLeaves, AdminLogEdits, AdminLogDeletes, AdminLogRestricts}
+
+// adminLogTextLimit caps message texts quoted in event descriptions.
+const adminLogTextLimit = 200
+
+// AdminLogEvent is one entry of a channel's admin log.
+type AdminLogEvent struct {
+	ID          int64     `json:"id"`
+	Date        time.Time `json:"date"`
+	ActorID     int64     `json:"actor_id"`
+	Actor       string    `json:"actor"`
+	Description string    `json:"description"`
+}
+
+// AdminLogQuery selects admin log events. Empty Types selects all events.
+type AdminLogQuery struct {
+	Types  []string
+	Search string
+	Limit  int
+}
+
+// Validate checks the event types.
+func (q AdminLogQuery) Validate() error {
+	_, err := eventsFilter(q.Types)
+	return err
+}
+
+// eventsFilter builds the admin log filter for the given event types, nil for all.
+func eventsFilter(types []string) (*tg.ChannelAdminLogEventsFilter, error) {
+	if len(types) == 0 {
+		return nil, nil
+	}
+	var f tg.ChannelAdminLogEventsFilter
+	for _, t := range types {
+		switch t {
+		case AdminLogJoins:
+			f.Join, f.Invite = true, true
+		case AdminLogLeaves:
+			f.Leave = true
+		case AdminLogEdits:
+			f.Edit, f.Info = true, true
+		case AdminLogDeletes:
+			f.Delete = true
+		case AdminLogRestricts:
+			f.Ban, f.Unban, f.Kick, f.Unkick = true, true, true, true
+		default:
+			return nil, fmt.Errorf("invalid event type: %q (must be one of %s)", t, strings.Join(AdminLogTypes, ", "))
+		}
+	}
+	return &f, nil
+}
+
+// GetAdminLog returns the most recent admin log events of a channel or supergroup,
+// newest first.
+func GetAdminLog(ctx context.Context, client *tg.Client, channel tg.InputChannelClass, q AdminLogQuery) ([]AdminLogEvent, error) {
+	filter, err := eventsFilter(q.Types)
+	if err != nil {
+		return nil, err
+	}
+	req := &tg.ChannelsGetAdminLogRequest{Channel: channel, Q: q.Search, Limit: q.Limit}
+	if filter != nil {
+		req.SetEventsFilter(*filter)
+	}
+	result, err := client.ChannelsGetAdminLog(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	users := usersByID(result.Users)
+	events := make([]AdminLogEvent, 0, len(result.Events))
+	for _, e := range result.Events {
+		events = append(events, AdminLogEvent{
+			ID:          e.ID,
+			Date:        time.Unix(int64(e.Date), 0),
+			ActorID:     e.UserID,
+			Actor:       memberName(e.UserID, users),
+			Description: describeAdminAction(e.Action, users),
+		})
+	}
+	return events, nil
+}
+
+// describeAdminAction describes what an admin log action changed.
+func describeAdminAction(action tg.ChannelAdminLogEventActionClass, users map[int64]*tg.User) string {
+	switch a := action.(type) {
+	case *tg.ChannelAdminLogEventActionParticipantJoin:
+		return "joined"
+	case *tg.ChannelAdminLogEventActionParticipantJoinByInvite:
+		return "joined via an invite link"
+	case *tg.ChannelAdminLogEventActionParticipantJoinByRequest:
+		return "joined after their join request was approved"
+	case *tg.ChannelAdminLogEventActionParticipantLeave:
+		return "left"
+	case *tg.ChannelAdminLogEventActionParticipantInvite:
+		return "added " + participantName(a.Participant, users)
+	case *tg.ChannelAdminLogEventActionChangeTitle:
+		return fmt.Sprintf("changed the title from %q to %q", a.PrevValue, a.NewValue)
+	case *tg.ChannelAdminLogEventActionChangeAbout:
+		return fmt.Sprintf("changed the description from %q to %q", a.PrevValue, a.NewValue)
+	case *tg.ChannelAdminLogEventActionChangeUsername:
+		return fmt.Sprintf("changed the username from %q to %q", a.PrevValue, a.NewValue)
+	case *tg.ChannelAdminLogEventActionChangePhoto:
+		return "changed the chat photo"
+	case *tg.ChannelAdminLogEventActionEditMessage:
+		return fmt.Sprintf("edited message %d from %q to %q", a.NewMessage.GetID(), messageText(a.PrevMessage), messageText(a.NewMessage))
+	case *tg.ChannelAdminLogEventActionDeleteMessage:
+		return fmt.Sprintf("deleted message %d: %q", a.Message.GetID(), messageText(a.Message))
+	case *tg.ChannelAdminLogEventActionUpdatePinned:
+		if msg, ok := a.Message.(*tg.Message); ok && !msg.Pinned {
+			return fmt.Sprintf("unpinned message %d", msg.ID)
+		}
+		return fmt.Sprintf("pinned message %d: %q", a.Message.GetID(), messageText(a.Message))
+	case *tg.ChannelAdminLogEventActionParticipantToggleBan:
+		return describeBan(a.PrevParticipant, a.NewParticipant, users)
+	case *tg.ChannelAdminLogEventActionParticipantToggleAdmin:
+		name := participantName(a.NewParticipant, users)
+		switch a.NewParticipant.(type) {
+		case *tg.ChannelParticipantAdmin, *tg.ChannelParticipantCreator:
+			if _, wasAdmin := a.PrevParticipant.(*tg.ChannelParticipantAdmin); wasAdmin {
+				return "changed the admin rights of " + name
+			}
+			return "promoted " + name + " to admin"
+		default:
+			return "demoted " + name
+		}
+	case *tg.ChannelAdminLogEventActionToggleSlowMode:
+		if a.NewValue == 0 {
+			return "turned slow mode off"
+		}
+		return fmt.Sprintf("changed slow mode from %ds to %ds", a.PrevValue, a.NewValue)
+	case *tg.ChannelAdminLogEventActionToggleInvites:
+		if a.NewValue {
+			return "allowed members to add others"
+		}
+		return "stopped members from adding others"
+	default:
+		// Rarer actions: name them by their type, e.g. "toggle signatures"
+		name := strings.TrimPrefix(action.TypeName(), "channelAdminLogEventAction")
+		return "did " + strings.ToLower(strings.Join(splitCamel(name), " "))
+	}
+}
+
+// describeBan describes a change of a member's restrictions.
+func describeBan(prev, next tg.ChannelParticipantClass, users map[int64]*tg.User) string {
+	name := participantName(next, users)
+	banned, ok := next.(*tg.ChannelParticipantBanned)
+	switch {
+	case ok && banned.BannedRights.ViewMessages:
+		return "banned " + name
+	case ok:
+		s := "restricted " + name
+		if until := banned.BannedRights.UntilDate; until != 0 && until < 1<<31-1 {
+			s += " until " + time.Unix(int64(until), 0).Format(messages.DateFormat)
+		}
+		return s
+	}
+	if _, left := next.(*tg.ChannelParticipantLeft); left {
+		return "removed " + name
+	}
+	if _, wasBanned := prev.(*tg.ChannelParticipantBanned); wasBanned {
+		return "lifted the restrictions of " + name
+	}
+	return "changed the restrictions of " + name
+}
+
+// participantName names the user behind a channel participant.
+func participantName(p tg.ChannelParticipantClass, users map[int64]*tg.User) string {
+	var id int64
+	switch p := p.(type) {
+	case *tg.ChannelParticipant:
+		id = p.UserID
+	case *tg.ChannelParticipantSelf:
+		id = p.UserID
+	case *tg.ChannelParticipantCreator:
+		id = p.UserID
+	case *tg.ChannelParticipantAdmin:
+		id = p.UserID
+	case *tg.ChannelParticipantBanned:
+		if user, ok := p.Peer.(*tg.PeerUser); ok {
+			id = user.UserID
+		}
+	case *tg.ChannelParticipantLeft:
+		if user, ok := p.Peer.(*tg.PeerUser); ok {
+			id = user.UserID
+		}
+	}
+	return memberName(id, users)
+}
+
+// memberName names a user by ID, falling back to the ID for unknown users.
+func memberName(id int64, users map[int64]*tg.User) string {
+	member := ChatMember{UserID: id}
+	describeMember(&member, users)
+	if member.Name == "Unknown" {
+		return fmt.Sprintf("user %d", id)
+	}
+	return member.Name
+}
+
+// messageText returns a message's text for quoting, truncated.
+func messageText(msg tg.MessageClass) string {
+	m, ok := msg.(*tg.Message)
+	if !ok {
+		return ""
+	}
+	text := m.Message
+	if runes := []rune(text); len(runes) > adminLogTextLimit {
+		text = string(runes[:adminLogTextLimit]) + "..."
+	}
+	if text == "" && m.Media != nil {
+		return "[media]"
+	}
+	return text
+}
+
+// splitCamel splits a camelCase name into its words.
+func splitCamel(s string) []string {
+	var words []string
+	start := 0
+	for i, r := range s {
+		if i > 0 && r >= 'A' && r <= 'Z' {
+			words = append(words, s[start:i])
+			start = i
+		}
+	}
+	if start < len(s) {
+		words = append(words, s[start:])
+	}
+	return slices.DeleteFunc(words, func(w string) bool { return w == "" })
+}
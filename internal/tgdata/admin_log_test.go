@@ -0,0 +1,94 @@
+package tgdata
+
+import (
+	"testing"
+
+	"github.com/gotd/td/tg"
+)
+
+func TestDescribeAdminAction(t *testing.T) {
+	users := memberUsers()
+	tests := []struct {
+		name   string
+		action tg.ChannelAdminLogEventActionClass
+		want   string
+	}{
+		{name: "join", action: &tg.ChannelAdminLogEventActionParticipantJoin{}, want: "joined"},
+		{name: "leave", action: &tg.ChannelAdminLogEventActionParticipantLeave{}, want: "left"},
+		{
+			name:   "title",
+			action: &tg.ChannelAdminLogEventActionChangeTitle{PrevValue: "Team", NewValue: "Team 2025"},
+			want:   `changed the title from "Team" to "Team 2025"`,
+		},
+		{
+			name: "edit",
+			action: &tg.ChannelAdminLogEventActionEditMessage{
+				PrevMessage: &tg.Message{ID: 5, Message: "helo"},
+				NewMessage:  &tg.Message{ID: 5, Message: "hello"},
+			},
+			want: `edited message 5 from "helo" to "hello"`,
+		},
+		{
+			name:   "delete",
+			action: &tg.ChannelAdminLogEventActionDeleteMessage{Message: &tg.Message{ID: 6, Media: &tg.MessageMediaPhoto{}}},
+			want:   `deleted message 6: "[media]"`,
+		},
+		{
+			name: "ban",
+			action: &tg.ChannelAdminLogEventActionParticipantToggleBan{
+				PrevParticipant: &tg.ChannelParticipant{UserID: 2},
+				NewParticipant:  &tg.ChannelParticipantBanned{Peer: &tg.PeerUser{UserID: 2}, BannedRights: tg.ChatBannedRights{ViewMessages: true}},
+			},
+			want: "banned Bob Stone",
+		},
+		{
+			name: "unrestrict",
+			action: &tg.ChannelAdminLogEventActionParticipantToggleBan{
+				PrevParticipant: &tg.ChannelParticipantBanned{Peer: &tg.PeerUser{UserID: 2}, BannedRights: tg.ChatBannedRights{SendMedia: true}},
+				NewParticipant:  &tg.ChannelParticipant{UserID: 2},
+			},
+			want: "lifted the restrictions of Bob Stone",
+		},
+		{
+			name: "promote",
+			action: &tg.ChannelAdminLogEventActionParticipantToggleAdmin{
+				PrevParticipant: &tg.ChannelParticipant{UserID: 1},
+				NewParticipant:  &tg.ChannelParticipantAdmin{UserID: 1},
+			},
+			want: "promoted Alice to admin",
+		},
+		{
+			name: "unknown user",
+			action: &tg.ChannelAdminLogEventActionParticipantInvite{
+				Participant: &tg.ChannelParticipant{UserID: 99},
+			},
+			want: "added user 99",
+		},
+		{name: "other actions by type", action: &tg.ChannelAdminLogEventActionToggleSignatures{}, want: "did toggle signatures"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := describeAdminAction(tt.action, users); got != tt.want {
+				t.Errorf("describeAdminAction() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEventsFilter(t *testing.T) {
+	if f, err := eventsFilter(nil); f != nil || err != nil {
+		t.Errorf("eventsFilter(nil) = %v, %v, want no filter", f, err)
+	}
+
+	f, err := eventsFilter([]string{AdminLogJoins, AdminLogRestricts})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !f.Join || !f.Invite || !f.Ban || !f.Kick || f.Leave || f.Delete {
+		t.Errorf("eventsFilter(joins, restricts) = %+v", f)
+	}
+
+	if _, err := eventsFilter([]string{"pins"}); err == nil {
+		t.Error("eventsFilter() accepted an unknown type")
+	}
+}
@@ -0,0 +1,194 @@
+package tgdata
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"golang.org/x/sync/errgroup"
+
+	"github.com/tolmachov/mcp-telegram/internal/messages"
+)
+
+const (
+	// DefaultContextBudget is the default maximum size of a chat context document in characters.
+	DefaultContextBudget = 12000
+	// contextMessages is the number of recent messages included in a chat context.
+	contextMessages = 30
+	// contextParallelism bounds concurrent Telegram requests while assembling a context.
+	contextParallelism = 2
+)
+
+// contextTextCaps are the successively tighter per-field text limits (in runes)
+// tried before whole messages are dropped to fit the budget.
+var contextTextCaps = []int{1000, 300, 100}
+
+// ChatEssentials is the subset of chat information useful for grounding.
+type ChatEssentials struct {
+	ID           int64  `json:"id"`
+	Type         string `json:"type"`
+	Name         string `json:"name"`
+	Username     string `json:"username,omitempty"`
+	Description  string `json:"description,omitempty"`
+	MembersCount int    `json:"members_count,omitempty"`
+}
+
+// ChatContext is a compact grounding document for a chat.
+type ChatContext struct {
+	Chat          *ChatEssentials    `json:"chat,omitempty"`
+	UnreadCount   int                `json:"unread_count"`
+	Draft         string             `json:"draft,omitempty"`
+	PinnedMessage *messages.Message  `json:"pinned_message,omitempty"`
+	Messages      []messages.Message `json:"messages"` // chronological order
+	Notes         []string           `json:"notes,omitempty"`
+}
+
+// GetChatContext assembles chat info, the pinned message, recent messages, unread count
+// and draft into one document of at most budget characters (DefaultContextBudget if zero).
+// Sections that fail to load are omitted with a note instead of failing the whole request.
+func GetChatContext(ctx context.Context, provider *messages.Provider, chatID int64, budget int) (*ChatContext, error) {
+	if budget <= 0 {
+		budget = DefaultContextBudget
+	}
+
+	cc := &ChatContext{Messages: []messages.Message{}}
+	var info *ChatFullInfo
+	var pinned *messages.Message
+	var recent []messages.Message
+	var infoErr, pinnedErr, recentErr error
+
+	g, gctx := errgroup.WithContext(ctx)
+	g.SetLimit(contextParallelism)
+
+	g.Go(func() error {
+		info, infoErr = GetChatInfo(gctx, provider.Paced(), provider.Peers(), chatID)
+		if infoErr != nil || info.PinnedMessageID == 0 {
+			return nil
+		}
+		result, err := provider.FetchByIDs(gctx, chatID, []int{info.PinnedMessageID})
+		if err != nil {
+			pinnedErr = err
+			return nil
+		}
+		if len(result.Messages) > 0 {
+			pinned = &result.Messages[0]
+		}
+		return nil
+	})
+
+	g.Go(func() error {
+		result, err := provider.Fetch(gctx, chatID, messages.FetchOptions{Limit: contextMessages})
+		if err != nil {
+			recentErr = err
+			return nil
+		}
+		recent = result.Messages
+		return nil
+	})
+
+	_ = g.Wait()
+
+	if infoErr != nil && recentErr != nil {
+		return nil, fmt.Errorf("loading chat context: %w", infoErr)
+	}
+
+	if infoErr != nil {
+		cc.Notes = append(cc.Notes, fmt.Sprintf("chat info omitted: %v", infoErr))
+	} else {
+		cc.Chat = &ChatEssentials{
+			ID:           info.ID,
+			Type:         info.Type,
+			Name:         info.Name,
+			Username:     info.Username,
+			Description:  info.Description,
+			MembersCount: info.MembersCount,
+		}
+		cc.UnreadCount = info.UnreadCount
+		cc.Draft = info.Draft
+	}
+
+	if pinnedErr != nil {
+		cc.Notes = append(cc.Notes, fmt.Sprintf("pinned message omitted: %v", pinnedErr))
+	}
+	cc.PinnedMessage = pinned
+
+	if recentErr != nil {
+		cc.Notes = append(cc.Notes, fmt.Sprintf("messages omitted: %v", recentErr))
+	} else {
+		messages.Reverse(recent)
+		cc.Messages = recent
+	}
+
+	cc.FitBudget(budget)
+	return cc, nil
+}
+
+// Size returns the length of the context's JSON encoding.
+func (c *ChatContext) Size() int {
+	data, err := json.Marshal(c)
+	if err != nil {
+		return 0
+	}
+	return len(data)
+}
+
+// FitBudget shrinks the context until its JSON encoding fits within budget characters.
+// Long texts are capped first, then the oldest messages are dropped, and finally the
+// draft, pinned message and description are removed. Each step taken is recorded in
+// Notes, which count towards the budget. Chat essentials and notes are always kept,
+// so a budget smaller than those alone is exceeded.
+func (c *ChatContext) FitBudget(budget int) {
+	if c.Size() <= budget {
+		return
+	}
+
+	c.Notes = append(c.Notes, "")
+	note := &c.Notes[len(c.Notes)-1]
+	for _, limit := range contextTextCaps {
+		c.capTexts(limit)
+		*note = fmt.Sprintf("texts truncated to %d characters to fit budget", limit)
+		if c.Size() <= budget {
+			return
+		}
+	}
+
+	c.Notes = append(c.Notes, "")
+	dropped := 0
+	for len(c.Messages) > 0 && c.Size() > budget {
+		c.Messages = c.Messages[1:]
+		dropped++
+		c.Notes[len(c.Notes)-1] = fmt.Sprintf("%d oldest messages omitted to fit budget", dropped)
+	}
+	if dropped == 0 {
+		c.Notes = c.Notes[:len(c.Notes)-1]
+	}
+
+	if c.Size() > budget && c.Draft != "" {
+		c.Draft = ""
+		c.Notes = append(c.Notes, "draft omitted to fit budget")
+	}
+	if c.Size() > budget && c.PinnedMessage != nil {
+		c.PinnedMessage = nil
+		c.Notes = append(c.Notes, "pinned message omitted to fit budget")
+	}
+	if c.Size() > budget && c.Chat != nil && c.Chat.Description != "" {
+		c.Chat.Description = ""
+		c.Notes = append(c.Notes, "chat description omitted to fit budget")
+	}
+}
+
+// capTexts truncates every free-text field to limit runes.
+func (c *ChatContext) capTexts(limit int) {
+	if c.Chat != nil {
+		c.Chat.Description = messages.TruncateRunes(c.Chat.Description, limit)
+	}
+	c.Draft = messages.TruncateRunes(c.Draft, limit)
+	if c.PinnedMessage != nil {
+		pinned := *c.PinnedMessage
+		pinned.Text = messages.TruncateRunes(pinned.Text, limit)
+		c.PinnedMessage = &pinned
+	}
+	for i := range c.Messages {
+		c.Messages[i].Text = messages.TruncateRunes(c.Messages[i].Text, limit)
+	}
+}
@@ -0,0 +1,148 @@
+package tgdata
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tolmachov/mcp-telegram/internal/messages"
+)
+
+// newTestContext builds a context with n messages of the given text length.
+func newTestContext(n, textLen int) *ChatContext {
+	cc := &ChatContext{
+		Chat: &ChatEssentials{
+			ID:          42,
+			Type:        "supergroup",
+			Name:        "Test Group",
+			Description: strings.Repeat("d", textLen),
+		},
+		UnreadCount:   3,
+		Draft:         strings.Repeat("r", textLen),
+		PinnedMessage: &messages.Message{ID: 1, SenderName: "Alice", Text: strings.Repeat("p", textLen)},
+	}
+	start := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	for i := range n {
+		cc.Messages = append(cc.Messages, messages.Message{
+			ID:         i + 10,
+			Date:       start.Add(time.Duration(i) * time.Minute),
+			SenderID:   7,
+			SenderName: "Bob",
+			Text:       strings.Repeat("m", textLen),
+		})
+	}
+	return cc
+}
+
+func TestFitBudget(t *testing.T) {
+	tests := []struct {
+		name         string
+		messages     int
+		textLen      int
+		budget       int
+		wantMessages int
+		wantTextMax  int
+		wantNotes    []string
+		wantPinned   bool
+		wantDraft    bool
+	}{
+		{
+			name:         "fits unchanged",
+			messages:     5,
+			textLen:      50,
+			budget:       10000,
+			wantMessages: 5,
+			wantTextMax:  50,
+			wantPinned:   true,
+			wantDraft:    true,
+		},
+		{
+			name:         "long texts capped",
+			messages:     5,
+			textLen:      2000,
+			budget:       12000,
+			wantMessages: 5,
+			wantTextMax:  1000 + len("..."),
+			wantNotes:    []string{"texts truncated to 1000 characters"},
+			wantPinned:   true,
+			wantDraft:    true,
+		},
+		{
+			name:         "oldest messages dropped after tightest cap",
+			messages:     30,
+			textLen:      500,
+			budget:       3000,
+			wantTextMax:  100 + len("..."),
+			wantNotes:    []string{"texts truncated to 100 characters", "oldest messages omitted"},
+			wantPinned:   true,
+			wantDraft:    true,
+			wantMessages: -1, // some, but fewer than 30
+		},
+		{
+			name:        "optional sections dropped when messages are not enough",
+			messages:    1,
+			textLen:     500,
+			budget:      450,
+			wantTextMax: 100 + len("..."),
+			wantNotes:   []string{"1 oldest messages omitted", "draft omitted", "pinned message omitted"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cc := newTestContext(tt.messages, tt.textLen)
+			cc.FitBudget(tt.budget)
+
+			if size := cc.Size(); size > tt.budget {
+				t.Errorf("size %d exceeds budget %d", size, tt.budget)
+			}
+
+			switch {
+			case tt.wantMessages >= 0 && len(cc.Messages) != tt.wantMessages:
+				t.Errorf("got %d messages, want %d", len(cc.Messages), tt.wantMessages)
+			case tt.wantMessages < 0 && (len(cc.Messages) == 0 || len(cc.Messages) >= tt.messages):
+				t.Errorf("got %d messages, want between 1 and %d", len(cc.Messages), tt.messages-1)
+			}
+
+			for _, m := range cc.Messages {
+				if len(m.Text) > tt.wantTextMax {
+					t.Errorf("message %d text length %d exceeds %d", m.ID, len(m.Text), tt.wantTextMax)
+				}
+			}
+			// Newest messages must survive
+			if n := len(cc.Messages); n > 0 && cc.Messages[n-1].ID != tt.messages+9 {
+				t.Errorf("last message ID %d, want newest %d", cc.Messages[n-1].ID, tt.messages+9)
+			}
+
+			if (cc.PinnedMessage != nil) != tt.wantPinned {
+				t.Errorf("pinned message present = %v, want %v", cc.PinnedMessage != nil, tt.wantPinned)
+			}
+			if (cc.Draft != "") != tt.wantDraft {
+				t.Errorf("draft present = %v, want %v", cc.Draft != "", tt.wantDraft)
+			}
+
+			notes := strings.Join(cc.Notes, "\n")
+			if len(tt.wantNotes) == 0 && len(cc.Notes) > 0 {
+				t.Errorf("unexpected notes: %v", cc.Notes)
+			}
+			for _, want := range tt.wantNotes {
+				if !strings.Contains(notes, want) {
+					t.Errorf("notes %q do not mention %q", notes, want)
+				}
+			}
+		})
+	}
+}
+
+func TestCapTextsDoesNotMutatePinnedSource(t *testing.T) {
+	pinned := messages.Message{ID: 1, Text: strings.Repeat("p", 500)}
+	cc := &ChatContext{PinnedMessage: &pinned}
+	cc.capTexts(100)
+
+	if len(pinned.Text) != 500 {
+		t.Errorf("source pinned message was modified")
+	}
+	if len(cc.PinnedMessage.Text) != 100+len("...") {
+		t.Errorf("pinned text length %d, want %d", len(cc.PinnedMessage.Text), 100+len("..."))
+	}
+}
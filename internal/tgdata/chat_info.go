@@ -11,8 +11,8 @@ import (
 )
 
 // GetChatInfo retrieves detailed information about a specific chat
-func GetChatInfo(ctx context.Context, client *tg.Client, chatID int64) (*ChatFullInfo, error) {
-	peer, err := tgclient.ResolvePeer(ctx, client, chatID)
+func GetChatInfo(ctx context.Context, client *tg.Client, peers *tgclient.PeerCache, chatID int64) (*ChatFullInfo, error) {
+	peer, err := peers.ResolvePeer(ctx, client, chatID)
 	if err != nil {
 		return nil, fmt.Errorf("resolving peer: %w", err)
 	}
@@ -20,6 +20,8 @@ func GetChatInfo(ctx context.Context, client *tg.Client, chatID int64) (*ChatFul
 	var info ChatFullInfo
 	info.ID = chatID
 	now := time.Now().Unix()
+	var call tg.InputGroupCallClass
+	hasCall := false
 
 	switch p := peer.(type) {
 	case *tg.InputPeerUser:
@@ -40,14 +42,17 @@ func GetChatInfo(ctx context.Context, client *tg.Client, chatID int64) (*ChatFul
 				}
 			}
 			info.Description = fullUser.FullUser.About
+			info.PinnedMessageID = fullUser.FullUser.PinnedMsgID
 		}
 
 	case *tg.InputPeerChat:
 		info.Type = "group"
 		fullChat, err := client.MessagesGetFullChat(ctx, p.ChatID)
 		if err == nil {
+			call, hasCall = groupCallOf(fullChat.FullChat)
 			if chat, ok := fullChat.FullChat.(*tg.ChatFull); ok {
 				info.Description = chat.About
+				info.PinnedMessageID = chat.PinnedMsgID
 				if participants, ok := chat.Participants.(*tg.ChatParticipants); ok {
 					info.MembersCount = len(participants.Participants)
 				}
@@ -67,9 +72,11 @@ func GetChatInfo(ctx context.Context, client *tg.Client, chatID int64) (*ChatFul
 			AccessHash: p.AccessHash,
 		})
 		if err == nil {
+			call, hasCall = groupCallOf(fullChannel.FullChat)
 			if full, ok := fullChannel.FullChat.(*tg.ChannelFull); ok {
 				info.Description = full.About
 				info.MembersCount = full.ParticipantsCount
+				info.PinnedMessageID = full.PinnedMsgID
 			}
 			for _, c := range fullChannel.Chats {
 				if channel, ok := c.(*tg.Channel); ok {
@@ -84,6 +91,12 @@ func GetChatInfo(ctx context.Context, client *tg.Client, chatID int64) (*ChatFul
 		}
 	}
 
+	if hasCall {
+		if gc, err := groupCallSummary(ctx, client, call); err == nil {
+			info.GroupCall = gc
+		}
+	}
+
 	// Get chat info for unread count, mute status, etc.
 	dialogs, err := client.MessagesGetPeerDialogs(ctx, []tg.InputDialogPeerClass{
 		&tg.InputDialogPeer{Peer: peer},
@@ -92,11 +105,37 @@ func GetChatInfo(ctx context.Context, client *tg.Client, chatID int64) (*ChatFul
 		if dialog, ok := dialogs.Dialogs[0].(*tg.Dialog); ok {
 			info.UnreadCount = dialog.UnreadCount
 			info.MentionCount = dialog.UnreadMentionsCount
+			info.UnreadReactionsCount = dialog.UnreadReactionsCount
 			info.Muted = dialog.NotifySettings.MuteUntil > int(now)
 			info.Pinned = dialog.Pinned
 			info.Archived = dialog.FolderID != 0
+			if draft, ok := dialog.Draft.(*tg.DraftMessage); ok {
+				info.Draft = draft.Message
+			}
 		}
 	}
 
 	return &info, nil
 }
+
+// UnreadMarks is how many messages of a chat have unread mentions and unread reactions.
+type UnreadMarks struct {
+	Mentions  int
+	Reactions int
+}
+
+// GetUnreadMarks retrieves the unread mention and reaction counts of a chat's dialog.
+func GetUnreadMarks(ctx context.Context, client *tg.Client, peer tg.InputPeerClass) (UnreadMarks, error) {
+	dialogs, err := client.MessagesGetPeerDialogs(ctx, []tg.InputDialogPeerClass{
+		&tg.InputDialogPeer{Peer: peer},
+	})
+	if err != nil {
+		return UnreadMarks{}, fmt.Errorf("getting dialog: %w", err)
+	}
+	for _, d := range dialogs.Dialogs {
+		if dialog, ok := d.(*tg.Dialog); ok {
+			return UnreadMarks{Mentions: dialog.UnreadMentionsCount, Reactions: dialog.UnreadReactionsCount}, nil
+		}
+	}
+	return UnreadMarks{}, fmt.Errorf("dialog not found")
+}
@@ -2,7 +2,9 @@ package tgdata
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"slices"
 	"time"
 
 	"github.com/gotd/td/telegram/query"
@@ -15,115 +17,219 @@ import (
 // ProgressFunc is a callback for reporting progress
 type ProgressFunc func(current int, message string)
 
-// GetChats retrieves a list of all chats
-func GetChats(ctx context.Context, client *tg.Client, onProgress ProgressFunc) (*ChatsList, error) {
-	var chatsList []ChatInfo
+// Dialog folder IDs. Telegram keeps archived chats in folder 1 and everything else in
+// folder 0; asking for the main list alone doesn't reliably include the archive.
+const (
+	mainFolderID    = 0
+	archiveFolderID = 1
+)
+
+// folderIterator calls fn for every chat in a dialog folder.
+type folderIterator func(ctx context.Context, folderID int, fn func(ChatInfo) error) error
 
+// GetChats retrieves a list of all chats. Archived chats are fetched from the archive
+// folder separately and included only when includeArchived is set. A non-zero folderID
+// limits the list to the chats of that user folder (dialog filter).
+func GetChats(ctx context.Context, client *tg.Client, includeArchived bool, folderID int, onProgress ProgressFunc) (*ChatsList, error) {
 	startTime := time.Now()
-	chatCount := 0
 
-	err := query.GetDialogs(client).BatchSize(100).ForEach(ctx, func(ctx context.Context, dlg dialogs.Elem) error {
-		chatCount++
-		if chatCount%100 == 0 && onProgress != nil {
-			onProgress(chatCount, fmt.Sprintf("Processed %d chats...", chatCount))
-		}
+	folders := []int{mainFolderID}
+	// Folders can hold archived chats, so they are matched against the whole list
+	if includeArchived || folderID != 0 {
+		folders = append(folders, archiveFolderID)
+	}
+	chatsList, err := collectChats(ctx, dialogFolderIterator(client, startTime), folders, includeArchived || folderID != 0, onProgress)
+	if err == nil && folderID != 0 {
+		chatsList, err = onlyFolder(ctx, dialogFolderLister(client), contactIDsLister(client), folderID, chatsList, includeArchived)
+	}
 
-		dialog, ok := dlg.Dialog.(*tg.Dialog)
-		if !ok {
-			return nil
-		}
+	if onProgress != nil {
+		onProgress(len(chatsList), fmt.Sprintf("Finished: %d chats fetched in %v", len(chatsList), time.Since(startTime)))
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("listing chats: %w", err)
+	}
+
+	return &ChatsList{
+		Chats: chatsList,
+		Count: len(chatsList),
+	}, nil
+}
 
-		var name string
-		var username string
-		var id int64
-		var chatType string
-
-		users := dlg.Entities.Users()
-		chats := dlg.Entities.Chats()
-		channels := dlg.Entities.Channels()
-
-		switch p := dlg.Peer.(type) {
-		case *tg.InputPeerUser:
-			id = p.UserID
-			chatType = "user"
-			if user, ok := users[p.UserID]; ok {
-				name = tgclient.UserName(user)
-				username = user.Username
-				if user.Bot {
-					chatType = "bot"
-				}
+// collectChats iterates the given folders in order and merges their chats. A chat seen
+// in several folders is listed once and marked archived if any of them is the archive.
+// Archived chats are dropped unless includeArchived is set.
+func collectChats(ctx context.Context, iter folderIterator, folders []int, includeArchived bool, onProgress ProgressFunc) ([]ChatInfo, error) {
+	var chatsList []ChatInfo
+	index := make(map[int64]int)
+	processed := 0
+
+	for _, folderID := range folders {
+		err := iter(ctx, folderID, func(chat ChatInfo) error {
+			processed++
+			if processed%100 == 0 && onProgress != nil {
+				onProgress(processed, fmt.Sprintf("Processed %d chats...", processed))
 			}
-		case *tg.InputPeerChat:
-			id = p.ChatID
-			chatType = "group"
-			if chat, ok := chats[p.ChatID]; ok {
-				name = chat.Title
+
+			if folderID == archiveFolderID {
+				chat.Archived = true
 			}
-		case *tg.InputPeerChannel:
-			// Convert to user-facing format with -100 prefix
-			id = -1000000000000 - p.ChannelID
-			chatType = "channel"
-			if channel, ok := channels[p.ChannelID]; ok {
-				name = channel.Title
-				username = channel.Username
-				if channel.Megagroup {
-					chatType = "supergroup"
-				}
+			if i, ok := index[chat.ID]; ok {
+				chatsList[i].Archived = chatsList[i].Archived || chat.Archived
+				return nil
 			}
+			index[chat.ID] = len(chatsList)
+			chatsList = append(chatsList, chat)
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("folder %d: %w", folderID, err)
 		}
+	}
 
-		if name == "" {
-			name = "Unknown"
+	if includeArchived {
+		return chatsList, nil
+	}
+	active := chatsList[:0]
+	for _, chat := range chatsList {
+		if !chat.Archived {
+			active = append(active, chat)
 		}
+	}
+	return active, nil
+}
 
-		muted := dialog.NotifySettings.MuteUntil > int(startTime.Unix())
-		archived := dialog.FolderID != 0
-		if dlg.Deleted() {
-			return nil
-		}
+// onlyFolder keeps the chats of the user folder with the given ID, dropping archived
+// ones unless includeArchived is set.
+func onlyFolder(ctx context.Context, listFolders folderLister, listContacts contactsLister, folderID int, chats []ChatInfo, includeArchived bool) ([]ChatInfo, error) {
+	folders, err := listFolders(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("listing folders: %w", err)
+	}
+	i := slices.IndexFunc(folders, func(f dialogFolder) bool { return f.ID == folderID })
+	if i < 0 {
+		return nil, fmt.Errorf("folder %d not found, see the telegram://folders resource for folder IDs", folderID)
+	}
+	selected, err := chatsInFolder(ctx, folders[i], chats, listContacts)
+	if err != nil || includeArchived {
+		return selected, err
+	}
+	return slices.DeleteFunc(selected, func(c ChatInfo) bool { return c.Archived }), nil
+}
 
-		chatsList = append(chatsList, ChatInfo{
-			ID:           id,
-			Type:         chatType,
-			Name:         name,
-			Username:     username,
-			UnreadCount:  dialog.UnreadCount,
-			MentionCount: dialog.UnreadMentionsCount,
-			Muted:        muted,
-			Pinned:       dialog.Pinned,
-			Archived:     archived,
-		})
+// errEnoughChats stops a folder iteration once enough chats are collected.
+var errEnoughChats = errors.New("enough chats")
 
+// GetTopChats returns up to n chats from the top of the main chat list, in the order
+// Telegram shows them: pinned chats first, then by latest activity. Chats for which skip
+// returns true are left out and don't count towards n.
+func GetTopChats(ctx context.Context, client *tg.Client, n int, skip func(ChatInfo) bool) ([]ChatInfo, error) {
+	chats, err := topChats(ctx, dialogFolderIterator(client, time.Now()), n, skip)
+	if err != nil {
+		return nil, fmt.Errorf("listing chats: %w", err)
+	}
+	return chats, nil
+}
+
+// topChats collects up to n chats of the main folder, stopping the iteration early.
+func topChats(ctx context.Context, iter folderIterator, n int, skip func(ChatInfo) bool) ([]ChatInfo, error) {
+	var chats []ChatInfo
+	err := iter(ctx, mainFolderID, func(chat ChatInfo) error {
+		if chat.Archived || (skip != nil && skip(chat)) {
+			return nil
+		}
+		chats = append(chats, chat)
+		if len(chats) >= n {
+			return errEnoughChats
+		}
 		return nil
 	})
-
-	if onProgress != nil {
-		onProgress(chatCount, fmt.Sprintf("Finished: %d chats fetched in %v", chatCount, time.Since(startTime)))
+	if err != nil && !errors.Is(err, errEnoughChats) {
+		return nil, err
 	}
+	return chats, nil
+}
 
-	if err != nil {
-		return nil, fmt.Errorf("listing chats: %w", err)
+// dialogFolderIterator returns a folderIterator backed by messages.getDialogs.
+func dialogFolderIterator(client *tg.Client, now time.Time) folderIterator {
+	return func(ctx context.Context, folderID int, fn func(ChatInfo) error) error {
+		return query.GetDialogs(client).FolderID(folderID).BatchSize(100).ForEach(ctx, func(ctx context.Context, dlg dialogs.Elem) error {
+			chat, ok := chatFromDialog(dlg, now)
+			if !ok {
+				return nil
+			}
+			return fn(chat)
+		})
 	}
-
-	return &ChatsList{
-		Chats: chatsList,
-		Count: len(chatsList),
-	}, nil
 }
 
-// GetPinnedChats retrieves only pinned chats
-func GetPinnedChats(ctx context.Context, client *tg.Client) ([]ChatInfo, error) {
-	result, err := GetChats(ctx, client, nil)
-	if err != nil {
-		return nil, err
+// chatFromDialog converts a dialog to ChatInfo. It reports false for deleted dialogs
+// and dialog types other than *tg.Dialog.
+func chatFromDialog(dlg dialogs.Elem, now time.Time) (ChatInfo, bool) {
+	dialog, ok := dlg.Dialog.(*tg.Dialog)
+	if !ok || dlg.Deleted() {
+		return ChatInfo{}, false
 	}
 
-	var pinned []ChatInfo
-	for _, chat := range result.Chats {
-		if chat.Pinned {
-			pinned = append(pinned, chat)
+	var name string
+	var username string
+	var id int64
+	var chatType string
+
+	users := dlg.Entities.Users()
+	chats := dlg.Entities.Chats()
+	channels := dlg.Entities.Channels()
+
+	switch p := dlg.Peer.(type) {
+	case *tg.InputPeerUser:
+		id = p.UserID
+		chatType = "user"
+		if user, ok := users[p.UserID]; ok {
+			name = tgclient.UserName(user)
+			username = user.Username
+			if user.Bot {
+				chatType = "bot"
+			}
+		}
+	case *tg.InputPeerChat:
+		id = p.ChatID
+		chatType = "group"
+		if chat, ok := chats[p.ChatID]; ok {
+			name = chat.Title
+		}
+	case *tg.InputPeerChannel:
+		id = tgclient.ChannelDialogID(p.ChannelID)
+		chatType = "channel"
+		if channel, ok := channels[p.ChannelID]; ok {
+			name = channel.Title
+			username = channel.Username
+			if channel.Megagroup {
+				chatType = "supergroup"
+			}
 		}
 	}
 
-	return pinned, nil
+	if name == "" {
+		name = "Unknown"
+	}
+
+	var lastActivity time.Time
+	if dlg.Last != nil {
+		lastActivity = time.Unix(int64(dlg.Last.GetDate()), 0)
+	}
+
+	return ChatInfo{
+		ID:                   id,
+		Type:                 chatType,
+		Name:                 name,
+		Username:             username,
+		UnreadCount:          dialog.UnreadCount,
+		MentionCount:         dialog.UnreadMentionsCount,
+		UnreadReactionsCount: dialog.UnreadReactionsCount,
+		Muted:                dialog.NotifySettings.MuteUntil > int(now.Unix()),
+		Pinned:               dialog.Pinned,
+		Archived:             dialog.FolderID != 0,
+		LastActivity:         lastActivity,
+	}, true
 }
@@ -0,0 +1,38 @@
+package tgdata
+
+// DefaultChatsPerPart is the default number of chats in one content block.
+const DefaultChatsPerPart = 50
+
+// ChatsPart is one standalone slice of a chats list, for MCP clients that render
+// content blocks separately and truncate long ones.
+type ChatsPart struct {
+	Part       int        `json:"part"` // 1-based
+	TotalParts int        `json:"total_parts"`
+	Count      int        `json:"count"` // Chats across all parts
+	Chats      []ChatInfo `json:"chats"`
+}
+
+// SplitChats splits list into parts of at most perPart chats, keeping list order.
+// An empty list yields a single empty part; perPart < 1 falls back to DefaultChatsPerPart.
+func SplitChats(list *ChatsList, perPart int) []ChatsPart {
+	if perPart < 1 {
+		perPart = DefaultChatsPerPart
+	}
+
+	total := (len(list.Chats) + perPart - 1) / perPart
+	if total == 0 {
+		return []ChatsPart{{Part: 1, TotalParts: 1, Count: list.Count, Chats: []ChatInfo{}}}
+	}
+
+	parts := make([]ChatsPart, 0, total)
+	for i := 0; i < len(list.Chats); i += perPart {
+		end := min(i+perPart, len(list.Chats))
+		parts = append(parts, ChatsPart{
+			Part:       len(parts) + 1,
+			TotalParts: total,
+			Count:      list.Count,
+			Chats:      list.Chats[i:end],
+		})
+	}
+	return parts
+}
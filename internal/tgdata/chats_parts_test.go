@@ -0,0 +1,56 @@
+package tgdata
+
+import (
+	"testing"
+)
+
+func TestSplitChats(t *testing.T) {
+	chatsN := func(n int) *ChatsList {
+		list := &ChatsList{Count: n}
+		for i := range n {
+			list.Chats = append(list.Chats, ChatInfo{ID: int64(i + 1)})
+		}
+		return list
+	}
+
+	tests := []struct {
+		name      string
+		list      *ChatsList
+		perPart   int
+		wantSizes []int
+	}{
+		{name: "empty", list: chatsN(0), perPart: 50, wantSizes: []int{0}},
+		{name: "single part", list: chatsN(3), perPart: 50, wantSizes: []int{3}},
+		{name: "exact multiple", list: chatsN(100), perPart: 50, wantSizes: []int{50, 50}},
+		{name: "remainder", list: chatsN(120), perPart: 50, wantSizes: []int{50, 50, 20}},
+		{name: "default size", list: chatsN(51), perPart: 0, wantSizes: []int{50, 1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parts := SplitChats(tt.list, tt.perPart)
+			if len(parts) != len(tt.wantSizes) {
+				t.Fatalf("got %d parts, want %d", len(parts), len(tt.wantSizes))
+			}
+
+			var next int64 = 1
+			for i, p := range parts {
+				if p.Part != i+1 || p.TotalParts != len(parts) || p.Count != tt.list.Count {
+					t.Errorf("part %d metadata = %d/%d count %d", i, p.Part, p.TotalParts, p.Count)
+				}
+				if len(p.Chats) != tt.wantSizes[i] {
+					t.Errorf("part %d has %d chats, want %d", i, len(p.Chats), tt.wantSizes[i])
+				}
+				if p.Chats == nil {
+					t.Errorf("part %d chats is nil, want empty slice", i)
+				}
+				for _, c := range p.Chats {
+					if c.ID != next {
+						t.Fatalf("part %d: got chat %d, want %d (order not preserved)", i, c.ID, next)
+					}
+					next++
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,168 @@
+package tgdata
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/gotd/td/telegram/message/peer"
+	"github.com/gotd/td/telegram/query/dialogs"
+	"github.com/gotd/td/tg"
+)
+
+// fakeFolders is a folderIterator serving fixed chats per folder.
+func fakeFolders(folders map[int][]ChatInfo, calls *[]int) folderIterator {
+	return func(_ context.Context, folderID int, fn func(ChatInfo) error) error {
+		*calls = append(*calls, folderID)
+		for _, chat := range folders[folderID] {
+			if err := fn(chat); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+func TestCollectChats(t *testing.T) {
+	folders := map[int][]ChatInfo{
+		mainFolderID: {
+			{ID: 1, Name: "Alice"},
+			{ID: 2, Name: "Work"},
+			// Returned by the main list but flagged as archived by the dialog itself
+			{ID: 3, Name: "Old group", Archived: true},
+		},
+		archiveFolderID: {
+			{ID: 4, Name: "Archived channel"},
+			{ID: 2, Name: "Work"},
+			{ID: 3, Name: "Old group"},
+		},
+	}
+
+	tests := []struct {
+		name            string
+		folders         []int
+		includeArchived bool
+		want            []ChatInfo
+	}{
+		{
+			name:            "both folders merged",
+			folders:         []int{mainFolderID, archiveFolderID},
+			includeArchived: true,
+			want: []ChatInfo{
+				{ID: 1, Name: "Alice"},
+				{ID: 2, Name: "Work", Archived: true},
+				{ID: 3, Name: "Old group", Archived: true},
+				{ID: 4, Name: "Archived channel", Archived: true},
+			},
+		},
+		{
+			name:            "main folder only",
+			folders:         []int{mainFolderID},
+			includeArchived: false,
+			want: []ChatInfo{
+				{ID: 1, Name: "Alice"},
+				{ID: 2, Name: "Work"},
+			},
+		},
+		{
+			name:            "archived filtered after merge",
+			folders:         []int{mainFolderID, archiveFolderID},
+			includeArchived: false,
+			want: []ChatInfo{
+				{ID: 1, Name: "Alice"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls []int
+			got, err := collectChats(t.Context(), fakeFolders(folders, &calls), tt.folders, tt.includeArchived, nil)
+			if err != nil {
+				t.Fatalf("collectChats() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("collectChats() = %+v, want %+v", got, tt.want)
+			}
+			if !reflect.DeepEqual(calls, tt.folders) {
+				t.Errorf("folders iterated = %v, want %v", calls, tt.folders)
+			}
+		})
+	}
+}
+
+func TestCollectChatsError(t *testing.T) {
+	errBoom := errors.New("boom")
+	iter := func(_ context.Context, folderID int, fn func(ChatInfo) error) error {
+		if folderID == archiveFolderID {
+			return errBoom
+		}
+		return fn(ChatInfo{ID: 1})
+	}
+
+	_, err := collectChats(t.Context(), iter, []int{mainFolderID, archiveFolderID}, true, nil)
+	if !errors.Is(err, errBoom) {
+		t.Errorf("collectChats() error = %v, want %v", err, errBoom)
+	}
+}
+
+func TestTopChats(t *testing.T) {
+	folders := map[int][]ChatInfo{
+		mainFolderID: {
+			{ID: 1, Type: "user"},
+			{ID: 2, Type: "channel"},
+			{ID: 3, Type: "group", Archived: true},
+			{ID: 4, Type: "group"},
+			{ID: 5, Type: "user"},
+		},
+	}
+	skipChannels := func(chat ChatInfo) bool { return chat.Type == "channel" }
+
+	var calls []int
+	got, err := topChats(t.Context(), fakeFolders(folders, &calls), 2, skipChannels)
+	if err != nil {
+		t.Fatalf("topChats() error = %v", err)
+	}
+	if want := []ChatInfo{folders[mainFolderID][0], folders[mainFolderID][3]}; !reflect.DeepEqual(got, want) {
+		t.Errorf("topChats() = %+v, want %+v", got, want)
+	}
+
+	got, err = topChats(t.Context(), fakeFolders(folders, &calls), 10, nil)
+	if err != nil {
+		t.Fatalf("topChats() error = %v", err)
+	}
+	if len(got) != 4 {
+		t.Errorf("topChats() returned %d chats, want all 4 non-archived", len(got))
+	}
+}
+
+func TestChatFromDialogArchivedAndPinned(t *testing.T) {
+	entities := peer.NewEntities(map[int64]*tg.User{7: {ID: 7, FirstName: "Alice"}}, nil, nil)
+	now := time.Unix(1_700_000_000, 0)
+
+	tests := []struct {
+		name         string
+		dialog       *tg.Dialog
+		wantArchived bool
+		wantPinned   bool
+	}{
+		{name: "main list", dialog: &tg.Dialog{}},
+		// As left by ArchiveChat and PinChat
+		{name: "archived and pinned", dialog: &tg.Dialog{FolderID: 1, Pinned: true}, wantArchived: true, wantPinned: true},
+		// As left by UnarchiveChat and UnpinChat
+		{name: "unarchived and unpinned", dialog: &tg.Dialog{FolderID: 0, Pinned: false}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chat, ok := chatFromDialog(dialogs.Elem{Dialog: tt.dialog, Peer: &tg.InputPeerUser{UserID: 7}, Entities: entities}, now)
+			if !ok {
+				t.Fatal("chatFromDialog() skipped the dialog")
+			}
+			if chat.Archived != tt.wantArchived || chat.Pinned != tt.wantPinned {
+				t.Errorf("chatFromDialog() archived = %v, pinned = %v, want %v, %v", chat.Archived, chat.Pinned, tt.wantArchived, tt.wantPinned)
+			}
+		})
+	}
+}
@@ -0,0 +1,130 @@
+package tgdata
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/gotd/td/tg"
+
+	"github.com/tolmachov/mcp-telegram/internal/tgclient"
+)
+
+// Folder describes a user chat folder (dialog filter).
+type Folder struct {
+	ID       int    `json:"id"`
+	Title    string `json:"title"`
+	Emoticon string `json:"emoticon,omitempty"`
+	Shared   bool   `json:"shared,omitempty"` // A chat list shared by invite link
+	// Chats added to or removed from the folder one by one; pinned chats are included too
+	IncludedPeers int `json:"included_peers"`
+	ExcludedPeers int `json:"excluded_peers"`
+	PinnedPeers   int `json:"pinned_peers"`
+	// Chat categories the folder takes in as a whole: contacts, non_contacts, groups,
+	// channels and bots
+	Categories []string `json:"categories,omitempty"`
+}
+
+// GetFolders lists the user's chat folders in display order.
+func GetFolders(ctx context.Context, client *tg.Client) ([]Folder, error) {
+	folders, err := dialogFolderLister(client)(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("listing folders: %w", err)
+	}
+	result := make([]Folder, len(folders))
+	for i, f := range folders {
+		result[i] = folderSummary(f)
+	}
+	return result, nil
+}
+
+// folderSummary describes a folder by the number of its explicit peers and categories.
+func folderSummary(f dialogFolder) Folder {
+	included := len(f.IncludeIDs)
+	for _, id := range f.PinnedIDs {
+		// Pinned chats are listed apart from the included ones but belong to the folder
+		if !slices.Contains(f.IncludeIDs, id) {
+			included++
+		}
+	}
+	folder := Folder{
+		ID:            f.ID,
+		Title:         f.Title,
+		Emoticon:      f.Emoticon,
+		Shared:        f.Shared,
+		IncludedPeers: included,
+		ExcludedPeers: len(f.ExcludeIDs),
+		PinnedPeers:   len(f.PinnedIDs),
+	}
+	for _, c := range []struct {
+		on   bool
+		name string
+	}{
+		{f.Contacts, "contacts"},
+		{f.NonContacts, "non_contacts"},
+		{f.Groups, "groups"},
+		{f.Broadcasts, "channels"},
+		{f.Bots, "bots"},
+	} {
+		if c.on {
+			folder.Categories = append(folder.Categories, c.name)
+		}
+	}
+	return folder
+}
+
+// AddChatToFolder adds a chat to the include list of the folder with the given ID,
+// taking it off the exclude list. It returns the folder's title and whether the
+// folder changed; a chat already included is left as is.
+func AddChatToFolder(ctx context.Context, client *tg.Client, folderID int, peer tg.InputPeerClass) (string, bool, error) {
+	filters, err := client.MessagesGetDialogFilters(ctx)
+	if err != nil {
+		return "", false, fmt.Errorf("listing folders: %w", err)
+	}
+
+	var filter *tg.DialogFilter
+	for _, f := range filters.Filters {
+		switch f := f.(type) {
+		case *tg.DialogFilter:
+			if f.ID == folderID {
+				filter = f
+			}
+		case *tg.DialogFilterChatlist:
+			if f.ID == folderID {
+				return "", false, fmt.Errorf("folder %q is shared by an invite link; add chats to it in a Telegram app", f.Title.Text)
+			}
+		}
+	}
+	if filter == nil {
+		return "", false, fmt.Errorf("folder %d not found, see the telegram://folders resource for folder IDs", folderID)
+	}
+
+	if !includePeer(filter, peer) {
+		return filter.Title.Text, false, nil
+	}
+	if _, err := client.MessagesUpdateDialogFilter(ctx, &tg.MessagesUpdateDialogFilterRequest{ID: folderID, Filter: filter}); err != nil {
+		return "", false, fmt.Errorf("updating folder %q: %w", filter.Title.Text, err)
+	}
+	return filter.Title.Text, true, nil
+}
+
+// includePeer adds peer to the filter's include list and drops it from the exclude
+// list, reporting whether the filter changed.
+func includePeer(filter *tg.DialogFilter, peer tg.InputPeerClass) bool {
+	id, ok := tgclient.DialogPeerID(peer)
+	if !ok {
+		return false
+	}
+	isPeer := func(p tg.InputPeerClass) bool {
+		other, ok := tgclient.DialogPeerID(p)
+		return ok && other == id
+	}
+
+	changed := slices.ContainsFunc(filter.ExcludePeers, isPeer)
+	filter.ExcludePeers = slices.DeleteFunc(filter.ExcludePeers, isPeer)
+	if !slices.ContainsFunc(filter.IncludePeers, isPeer) && !slices.ContainsFunc(filter.PinnedPeers, isPeer) {
+		filter.IncludePeers = append(filter.IncludePeers, peer)
+		changed = true
+	}
+	return changed
+}
@@ -0,0 +1,141 @@
+package tgdata
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/gotd/td/tg"
+)
+
+// fixtureFilters are dialog filters as returned by messages.getDialogFilters.
+func fixtureFilters() []tg.DialogFilterClass {
+	return []tg.DialogFilterClass{
+		&tg.DialogFilterDefault{},
+		&tg.DialogFilter{
+			ID:       2,
+			Title:    tg.TextWithEntities{Text: "Work"},
+			Emoticon: "💼",
+			Groups:   true,
+			PinnedPeers: []tg.InputPeerClass{
+				&tg.InputPeerChannel{ChannelID: 555},
+			},
+			IncludePeers: []tg.InputPeerClass{
+				&tg.InputPeerUser{UserID: 7},
+				&tg.InputPeerChat{ChatID: 8},
+				&tg.InputPeerSelf{}, // Not a chat ID, skipped
+			},
+			ExcludePeers: []tg.InputPeerClass{
+				&tg.InputPeerChat{ChatID: 9},
+			},
+		},
+		&tg.DialogFilterChatlist{
+			ID:           3,
+			Title:        tg.TextWithEntities{Text: "Community"},
+			IncludePeers: []tg.InputPeerClass{&tg.InputPeerChannel{ChannelID: 42}},
+		},
+	}
+}
+
+func TestFoldersFromFilters(t *testing.T) {
+	got := foldersFromFilters(fixtureFilters())
+	want := []dialogFolder{
+		{
+			ID:         2,
+			Title:      "Work",
+			Emoticon:   "💼",
+			PinnedIDs:  []int64{-1000000000555},
+			IncludeIDs: []int64{7, 8},
+			ExcludeIDs: []int64{9},
+			Groups:     true,
+		},
+		{
+			ID:         3,
+			Title:      "Community",
+			Shared:     true,
+			IncludeIDs: []int64{-1000000000042},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("foldersFromFilters() = %+v, want %+v", got, want)
+	}
+}
+
+func TestFolderSummary(t *testing.T) {
+	folders := foldersFromFilters(fixtureFilters())
+	got := folderSummary(folders[0])
+	want := Folder{
+		ID:            2,
+		Title:         "Work",
+		Emoticon:      "💼",
+		IncludedPeers: 3, // Two included chats and the pinned channel
+		ExcludedPeers: 1,
+		PinnedPeers:   1,
+		Categories:    []string{"groups"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("folderSummary() = %+v, want %+v", got, want)
+	}
+}
+
+func TestOnlyFolder(t *testing.T) {
+	chats := []ChatInfo{
+		{ID: 7, Type: "user", Name: "Alice"},
+		{ID: 8, Type: "group", Name: "Standup"},
+		{ID: 9, Type: "group", Name: "Excluded"},
+		{ID: 10, Type: "group", Name: "Old team", Archived: true},
+		{ID: 11, Type: "user", Name: "Bob"},
+		{ID: -1000000000555, Type: "channel", Name: "Releases"},
+	}
+	lister := func(context.Context) ([]dialogFolder, error) {
+		return foldersFromFilters(fixtureFilters()), nil
+	}
+
+	tests := []struct {
+		name            string
+		folderID        int
+		includeArchived bool
+		want            []int64
+		wantErr         bool
+	}{
+		{name: "folder with archived chats", folderID: 2, includeArchived: true, want: []int64{7, 8, 10, -1000000000555}},
+		{name: "archived chats dropped", folderID: 2, want: []int64{7, 8, -1000000000555}},
+		{name: "unknown folder", folderID: 99, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := onlyFolder(t.Context(), lister, nil, tt.folderID, chats, tt.includeArchived)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("onlyFolder() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			var ids []int64
+			for _, c := range got {
+				ids = append(ids, c.ID)
+			}
+			if !reflect.DeepEqual(ids, tt.want) {
+				t.Errorf("onlyFolder() = %v, want %v", ids, tt.want)
+			}
+		})
+	}
+}
+
+func TestIncludePeer(t *testing.T) {
+	filter := fixtureFilters()[1].(*tg.DialogFilter)
+
+	if includePeer(filter, &tg.InputPeerUser{UserID: 7, AccessHash: 1}) {
+		t.Error("includePeer() changed the folder for an already included chat")
+	}
+	if includePeer(filter, &tg.InputPeerChannel{ChannelID: 555}) {
+		t.Error("includePeer() changed the folder for a pinned chat")
+	}
+
+	if !includePeer(filter, &tg.InputPeerChat{ChatID: 9}) {
+		t.Fatal("includePeer() didn't change the folder for an excluded chat")
+	}
+	if len(filter.ExcludePeers) != 0 {
+		t.Errorf("excluded chat still in ExcludePeers: %v", filter.ExcludePeers)
+	}
+	if got := inputPeerChatIDs(filter.IncludePeers); !reflect.DeepEqual(got, []int64{7, 8, 9}) {
+		t.Errorf("IncludePeers = %v, want [7 8 9]", got)
+	}
+}
@@ -0,0 +1,101 @@
+package tgdata
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gotd/td/tg"
+	"github.com/gotd/td/tgerr"
+)
+
+// ErrNotForum is returned for topic requests in a chat without topics.
+var ErrNotForum = errors.New("chat has no topics; topic_id only applies to supergroups with topics enabled")
+
+// ErrTopicNotFound is returned when a forum has no topic with the requested ID.
+var ErrTopicNotFound = errors.New("topic not found")
+
+// ForumTopic is a topic of a supergroup with topics enabled.
+type ForumTopic struct {
+	ID              int       `json:"id"` // Also the ID of the message that created it
+	Title           string    `json:"title"`
+	UnreadCount     int       `json:"unread_count"`
+	LastMessageDate time.Time `json:"last_message_date,omitzero"`
+	Pinned          bool      `json:"pinned,omitempty"`
+	Closed          bool      `json:"closed,omitempty"`
+	Hidden          bool      `json:"hidden,omitempty"` // The General topic, hidden from the list
+}
+
+// GetForumTopics lists the topics of a forum supergroup, most recently active
+// first, optionally only those whose title matches query.
+func GetForumTopics(ctx context.Context, client *tg.Client, peer tg.InputPeerClass, query string, limit int) ([]ForumTopic, error) {
+	if _, ok := peer.(*tg.InputPeerChannel); !ok {
+		return nil, ErrNotForum
+	}
+	result, err := client.MessagesGetForumTopics(ctx, &tg.MessagesGetForumTopicsRequest{
+		Peer:  peer,
+		Q:     query,
+		Limit: limit,
+	})
+	if err != nil {
+		return nil, forumError(err)
+	}
+	return forumTopics(result), nil
+}
+
+// GetForumTopic returns one topic of a forum supergroup.
+func GetForumTopic(ctx context.Context, client *tg.Client, peer tg.InputPeerClass, topicID int) (ForumTopic, error) {
+	if _, ok := peer.(*tg.InputPeerChannel); !ok {
+		return ForumTopic{}, ErrNotForum
+	}
+	result, err := client.MessagesGetForumTopicsByID(ctx, &tg.MessagesGetForumTopicsByIDRequest{
+		Peer:   peer,
+		Topics: []int{topicID},
+	})
+	if err != nil {
+		return ForumTopic{}, forumError(err)
+	}
+	topics := forumTopics(result)
+	if len(topics) == 0 {
+		return ForumTopic{}, fmt.Errorf("%w: %d", ErrTopicNotFound, topicID)
+	}
+	return topics[0], nil
+}
+
+// forumError reports the error of a topic request in a chat without topics as ErrNotForum.
+func forumError(err error) error {
+	if tgerr.Is(err, "CHANNEL_FORUM_MISSING") {
+		return ErrNotForum
+	}
+	return fmt.Errorf("getting forum topics: %w", err)
+}
+
+// forumTopics converts the topics of a messages.forumTopics result, dating each by
+// its newest message. Deleted topics are left out.
+func forumTopics(result *tg.MessagesForumTopics) []ForumTopic {
+	dates := make(map[int]time.Time, len(result.Messages))
+	for _, m := range result.Messages {
+		if msg, ok := m.(*tg.Message); ok {
+			dates[msg.ID] = time.Unix(int64(msg.Date), 0)
+		}
+	}
+
+	topics := make([]ForumTopic, 0, len(result.Topics))
+	for _, t := range result.Topics {
+		topic, ok := t.(*tg.ForumTopic)
+		if !ok {
+			continue
+		}
+		topics = append(topics, ForumTopic{
+			ID:              topic.ID,
+			Title:           topic.Title,
+			UnreadCount:     topic.UnreadCount,
+			LastMessageDate: dates[topic.TopMessage],
+			Pinned:          topic.Pinned,
+			Closed:          topic.Closed,
+			Hidden:          topic.Hidden,
+		})
+	}
+	return topics
+}
@@ -0,0 +1,39 @@
+package tgdata
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/gotd/td/tg"
+)
+
+func TestForumTopics(t *testing.T) {
+	result := &tg.MessagesForumTopics{
+		Topics: []tg.ForumTopicClass{
+			&tg.ForumTopic{ID: 1, Title: "General", TopMessage: 120, Hidden: true},
+			&tg.ForumTopic{ID: 15, Title: "Releases", TopMessage: 118, UnreadCount: 4, Pinned: true},
+			&tg.ForumTopicDeleted{ID: 16},
+		},
+		Messages: []tg.MessageClass{
+			&tg.Message{ID: 120, Date: 1_700_000_200},
+			&tg.Message{ID: 118, Date: 1_700_000_100},
+		},
+	}
+
+	want := []ForumTopic{
+		{ID: 1, Title: "General", LastMessageDate: time.Unix(1_700_000_200, 0), Hidden: true},
+		{ID: 15, Title: "Releases", UnreadCount: 4, LastMessageDate: time.Unix(1_700_000_100, 0), Pinned: true},
+	}
+	if got := forumTopics(result); !reflect.DeepEqual(got, want) {
+		t.Errorf("forumTopics() = %+v, want %+v", got, want)
+	}
+}
+
+func TestGetForumTopicNotChannel(t *testing.T) {
+	// Users and basic groups never have topics, so no request is made
+	if _, err := GetForumTopic(t.Context(), nil, &tg.InputPeerChat{ChatID: 5}, 3); !errors.Is(err, ErrNotForum) {
+		t.Errorf("GetForumTopic() error = %v, want ErrNotForum", err)
+	}
+}
@@ -0,0 +1,194 @@
+package tgdata
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gotd/td/tg"
+
+	"github.com/tolmachov/mcp-telegram/internal/tgclient"
+)
+
+// speakingWindow is how recently an unmuted participant must have been active to count
+// as speaking. Telegram reports the last time a participant spoke, not a live flag.
+const speakingWindow = 10 * time.Second
+
+// participantsPageSize is the number of participants requested per page.
+const participantsPageSize = 100
+
+// GroupCallInfo describes the voice or video chat of a group or channel.
+type GroupCallInfo struct {
+	Active            bool                   `json:"active"`
+	Title             string                 `json:"title,omitempty"`
+	ParticipantsCount int                    `json:"participants_count"`
+	ScheduledFor      *time.Time             `json:"scheduled_for,omitempty"`
+	LiveStream        bool                   `json:"live_stream,omitempty"`
+	RecordingSince    *time.Time             `json:"recording_since,omitempty"`
+	Participants      []GroupCallParticipant `json:"participants,omitempty"`
+}
+
+// GroupCallParticipant is a member of a group call.
+type GroupCallParticipant struct {
+	ID         int64      `json:"id"`
+	Name       string     `json:"name"`
+	Muted      bool       `json:"muted"`
+	Speaking   bool       `json:"speaking"`
+	RaisedHand bool       `json:"raised_hand,omitempty"`
+	Video      bool       `json:"video,omitempty"`
+	JoinedAt   time.Time  `json:"joined_at"`
+	LastActive *time.Time `json:"last_active,omitempty"`
+}
+
+// groupCallOf returns the group call referenced by a ChatFull or ChannelFull, if any.
+func groupCallOf(full tg.ChatFullClass) (tg.InputGroupCallClass, bool) {
+	switch f := full.(type) {
+	case *tg.ChatFull:
+		return f.GetCall()
+	case *tg.ChannelFull:
+		return f.GetCall()
+	default:
+		return nil, false
+	}
+}
+
+// chatGroupCall looks up the group call of a chat. It reports false when the chat is
+// a private chat or has no call.
+func chatGroupCall(ctx context.Context, client *tg.Client, peers *tgclient.PeerCache, chatID int64) (tg.InputGroupCallClass, bool, error) {
+	var full *tg.MessagesChatFull
+	err := peers.WithPeer(ctx, client, chatID, func(peer tg.InputPeerClass) error {
+		var err error
+		switch p := peer.(type) {
+		case *tg.InputPeerChat:
+			full, err = client.MessagesGetFullChat(ctx, p.ChatID)
+		case *tg.InputPeerChannel:
+			full, err = client.ChannelsGetFullChannel(ctx, &tg.InputChannel{
+				ChannelID:  p.ChannelID,
+				AccessHash: p.AccessHash,
+			})
+		}
+		return err
+	})
+	if err != nil {
+		return nil, false, fmt.Errorf("getting full chat: %w", err)
+	}
+	if full == nil {
+		return nil, false, nil
+	}
+
+	call, ok := groupCallOf(full.FullChat)
+	return call, ok, nil
+}
+
+// groupCallSummary fetches the state of a call without its participants.
+func groupCallSummary(ctx context.Context, client *tg.Client, call tg.InputGroupCallClass) (*GroupCallInfo, error) {
+	result, err := client.PhoneGetGroupCall(ctx, &tg.PhoneGetGroupCallRequest{
+		Call:  call,
+		Limit: 0,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("getting group call: %w", err)
+	}
+	return newGroupCallInfo(result.Call), nil
+}
+
+// newGroupCallInfo converts a group call. A discarded call is reported as inactive.
+func newGroupCallInfo(call tg.GroupCallClass) *GroupCallInfo {
+	gc, ok := call.(*tg.GroupCall)
+	if !ok {
+		return &GroupCallInfo{}
+	}
+
+	info := &GroupCallInfo{
+		Active:            true,
+		Title:             gc.Title,
+		ParticipantsCount: gc.ParticipantsCount,
+		LiveStream:        gc.RtmpStream,
+	}
+	if date, ok := gc.GetScheduleDate(); ok {
+		// A scheduled call hasn't started yet
+		info.Active = false
+		t := time.Unix(int64(date), 0)
+		info.ScheduledFor = &t
+	}
+	if date, ok := gc.GetRecordStartDate(); ok {
+		t := time.Unix(int64(date), 0)
+		info.RecordingSince = &t
+	}
+	return info
+}
+
+// GetGroupCall returns the current group call of a chat with its participants.
+// Callers paging through large calls pass a paced client to respect rate limits.
+// A chat without a call yields an inactive GroupCallInfo.
+func GetGroupCall(ctx context.Context, client *tg.Client, peers *tgclient.PeerCache, chatID int64, limit int) (*GroupCallInfo, error) {
+	call, ok, err := chatGroupCall(ctx, client, peers, chatID)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return &GroupCallInfo{}, nil
+	}
+
+	info, err := groupCallSummary(ctx, client, call)
+	if err != nil {
+		return nil, err
+	}
+	if !info.Active {
+		return info, nil
+	}
+
+	now := time.Now()
+	offset := ""
+	for len(info.Participants) < limit {
+		page, err := client.PhoneGetGroupParticipants(ctx, &tg.PhoneGetGroupParticipantsRequest{
+			Call:    call,
+			IDs:     []tg.InputPeerClass{},
+			Sources: []int{},
+			Offset:  offset,
+			Limit:   min(participantsPageSize, limit-len(info.Participants)),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("getting group call participants: %w", err)
+		}
+
+		names := peerNames(page.Users, page.Chats)
+		for _, p := range page.Participants {
+			if p.Left {
+				continue
+			}
+			info.Participants = append(info.Participants, newGroupCallParticipant(p, names, now))
+		}
+		info.ParticipantsCount = max(info.ParticipantsCount, page.Count)
+
+		if page.NextOffset == "" || page.NextOffset == offset || len(page.Participants) == 0 {
+			break
+		}
+		offset = page.NextOffset
+	}
+	return info, nil
+}
+
+// newGroupCallParticipant converts a participant, resolving its name from names.
+func newGroupCallParticipant(p tg.GroupCallParticipant, names map[int64]string, now time.Time) GroupCallParticipant {
+	id := peerID(p.Peer)
+	name := names[id]
+	if name == "" {
+		name = "Unknown"
+	}
+
+	participant := GroupCallParticipant{
+		ID:         id,
+		Name:       name,
+		Muted:      p.Muted,
+		RaisedHand: p.RaiseHandRating != 0,
+		Video:      p.VideoJoined,
+		JoinedAt:   time.Unix(int64(p.Date), 0),
+	}
+	if active, ok := p.GetActiveDate(); ok {
+		t := time.Unix(int64(active), 0)
+		participant.LastActive = &t
+		participant.Speaking = !p.Muted && now.Sub(t) <= speakingWindow
+	}
+	return participant
+}
@@ -0,0 +1,90 @@
+package tgdata
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gotd/td/tg"
+)
+
+func TestNewGroupCallInfo(t *testing.T) {
+	t.Run("discarded", func(t *testing.T) {
+		info := newGroupCallInfo(&tg.GroupCallDiscarded{ID: 1})
+		if info.Active || info.ParticipantsCount != 0 {
+			t.Errorf("newGroupCallInfo(discarded) = %+v, want inactive", info)
+		}
+	})
+
+	t.Run("live", func(t *testing.T) {
+		info := newGroupCallInfo(&tg.GroupCall{Title: "Standup", ParticipantsCount: 5})
+		if !info.Active || info.Title != "Standup" || info.ParticipantsCount != 5 {
+			t.Errorf("newGroupCallInfo(live) = %+v", info)
+		}
+	})
+
+	t.Run("scheduled", func(t *testing.T) {
+		call := &tg.GroupCall{}
+		call.SetScheduleDate(1700000000)
+		info := newGroupCallInfo(call)
+		if info.Active {
+			t.Error("scheduled call reported as active")
+		}
+		if info.ScheduledFor == nil || info.ScheduledFor.Unix() != 1700000000 {
+			t.Errorf("ScheduledFor = %v, want 1700000000", info.ScheduledFor)
+		}
+	})
+}
+
+func TestNewGroupCallParticipant(t *testing.T) {
+	now := time.Unix(1700000100, 0)
+	names := map[int64]string{7: "Alice"}
+
+	tests := []struct {
+		name         string
+		participant  tg.GroupCallParticipant
+		activeDate   int
+		wantName     string
+		wantSpeaking bool
+	}{
+		{
+			name:         "speaking",
+			participant:  tg.GroupCallParticipant{Peer: &tg.PeerUser{UserID: 7}},
+			activeDate:   1700000095,
+			wantName:     "Alice",
+			wantSpeaking: true,
+		},
+		{
+			name:        "muted while recently active",
+			participant: tg.GroupCallParticipant{Peer: &tg.PeerUser{UserID: 7}, Muted: true},
+			activeDate:  1700000095,
+			wantName:    "Alice",
+		},
+		{
+			name:        "silent for a while",
+			participant: tg.GroupCallParticipant{Peer: &tg.PeerUser{UserID: 7}},
+			activeDate:  1700000000,
+			wantName:    "Alice",
+		},
+		{
+			name:        "unknown peer never spoke",
+			participant: tg.GroupCallParticipant{Peer: &tg.PeerUser{UserID: 8}},
+			wantName:    "Unknown",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := tt.participant
+			if tt.activeDate != 0 {
+				p.SetActiveDate(tt.activeDate)
+			}
+			got := newGroupCallParticipant(p, names, now)
+			if got.Name != tt.wantName {
+				t.Errorf("Name = %q, want %q", got.Name, tt.wantName)
+			}
+			if got.Speaking != tt.wantSpeaking {
+				t.Errorf("Speaking = %v, want %v", got.Speaking, tt.wantSpeaking)
+			}
+		})
+	}
+}
@@ -0,0 +1,166 @@
+package tgdata
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gotd/td/tg"
+	"github.com/gotd/td/tgerr"
+
+	"github.com/tolmachov/mcp-telegram/internal/tgclient"
+)
+
+// InvitePreview describes the chat behind an invite link.
+type InvitePreview struct {
+	// ChatID is known when you are already a member or the invite lets you peek in
+	ChatID        int64     `json:"chat_id,omitempty"`
+	Title         string    `json:"title"`
+	About         string    `json:"about,omitempty"`
+	Type          string    `json:"type"` // group, supergroup or channel
+	MembersCount  int       `json:"members_count"`
+	AlreadyJoined bool      `json:"already_joined"`
+	RequestNeeded bool      `json:"request_needed,omitempty"` // Joining needs an admin's approval
+	Public        bool      `json:"public,omitempty"`
+	Scam          bool      `json:"scam,omitempty"`
+	Fake          bool      `json:"fake,omitempty"`
+	PeekUntil     time.Time `json:"peek_until,omitzero"`
+}
+
+// JoinResult is the outcome of joining a chat.
+type JoinResult struct {
+	ChatID        int64  `json:"chat_id,omitempty"`
+	Title         string `json:"title"`
+	Type          string `json:"type"`
+	AlreadyMember bool   `json:"already_member,omitempty"`
+	RequestSent   bool   `json:"request_sent,omitempty"` // Waiting for an admin's approval
+}
+
+// CheckInvite previews the chat of an invite link without joining it.
+func CheckInvite(ctx context.Context, client *tg.Client, hash string) (*InvitePreview, error) {
+	invite, err := client.MessagesCheckChatInvite(ctx, hash)
+	if err != nil {
+		return nil, err
+	}
+	preview := invitePreview(invite)
+	return &preview, nil
+}
+
+// invitePreview converts a messages.checkChatInvite result.
+func invitePreview(invite tg.ChatInviteClass) InvitePreview {
+	switch inv := invite.(type) {
+	case *tg.ChatInviteAlready:
+		id, title, chatType, members, _ := chatIdentity(inv.Chat)
+		return InvitePreview{ChatID: id, Title: title, Type: chatType, MembersCount: members, AlreadyJoined: true}
+	case *tg.ChatInvitePeek:
+		id, title, chatType, members, _ := chatIdentity(inv.Chat)
+		return InvitePreview{ChatID: id, Title: title, Type: chatType, MembersCount: members, PeekUntil: time.Unix(int64(inv.Expires), 0)}
+	case *tg.ChatInvite:
+		chatType := "group"
+		switch {
+		case inv.Broadcast:
+			chatType = "channel"
+		case inv.Megagroup:
+			chatType = "supergroup"
+		}
+		return InvitePreview{
+			Title:         inv.Title,
+			About:         inv.About,
+			Type:          chatType,
+			MembersCount:  inv.ParticipantsCount,
+			RequestNeeded: inv.RequestNeeded,
+			Public:        inv.Public,
+			Scam:          inv.Scam,
+			Fake:          inv.Fake,
+		}
+	default:
+		return InvitePreview{}
+	}
+}
+
+// JoinByInvite joins the chat of an invite link. An invite to a chat you are in is
+// reported as AlreadyMember, and one needing approval as RequestSent.
+func JoinByInvite(ctx context.Context, client *tg.Client, hash string) (*JoinResult, error) {
+	updates, err := client.MessagesImportChatInvite(ctx, hash)
+	switch {
+	case tgerr.Is(err, "USER_ALREADY_PARTICIPANT"):
+		preview, err := CheckInvite(ctx, client, hash)
+		if err != nil {
+			return nil, err
+		}
+		return &JoinResult{ChatID: preview.ChatID, Title: preview.Title, Type: preview.Type, AlreadyMember: true}, nil
+	case tgerr.Is(err, "INVITE_REQUEST_SENT"):
+		result := &JoinResult{RequestSent: true}
+		if preview, err := CheckInvite(ctx, client, hash); err == nil {
+			result.Title, result.Type = preview.Title, preview.Type
+		}
+		return result, nil
+	case err != nil:
+		return nil, err
+	}
+
+	for _, chat := range updatesChats(updates) {
+		if id, title, chatType, _, ok := chatIdentity(chat); ok {
+			return &JoinResult{ChatID: id, Title: title, Type: chatType}, nil
+		}
+	}
+	return nil, fmt.Errorf("joined chat missing from response")
+}
+
+// JoinPublic joins a public channel or supergroup.
+func JoinPublic(ctx context.Context, client *tg.Client, channel *tg.InputChannel) (*JoinResult, error) {
+	chats, err := client.ChannelsGetChannels(ctx, []tg.InputChannelClass{channel})
+	if err != nil {
+		return nil, fmt.Errorf("getting channel: %w", err)
+	}
+	var result JoinResult
+	for _, c := range chats.GetChats() {
+		if ch, ok := c.(*tg.Channel); ok && ch.ID == channel.ChannelID {
+			result.ChatID, result.Title, result.Type, _, _ = chatIdentity(ch)
+			if !ch.Left {
+				result.AlreadyMember = true
+				return &result, nil
+			}
+		}
+	}
+
+	_, err = client.ChannelsJoinChannel(ctx, channel)
+	switch {
+	case tgerr.Is(err, "USER_ALREADY_PARTICIPANT"):
+		result.AlreadyMember = true
+	case tgerr.Is(err, "INVITE_REQUEST_SENT"):
+		result.RequestSent = true
+	case err != nil:
+		return nil, err
+	}
+	return &result, nil
+}
+
+// updatesChats returns the chats carried by an updates result.
+func updatesChats(updates tg.UpdatesClass) []tg.ChatClass {
+	switch u := updates.(type) {
+	case *tg.Updates:
+		return u.Chats
+	case *tg.UpdatesCombined:
+		return u.Chats
+	default:
+		return nil
+	}
+}
+
+// chatIdentity returns the user-facing ID, title, type and member count of a group
+// or channel.
+func chatIdentity(chat tg.ChatClass) (id int64, title, chatType string, members int, ok bool) {
+	switch c := chat.(type) {
+	case *tg.Chat:
+		return c.ID, c.Title, "group", c.ParticipantsCount, true
+	case *tg.Channel:
+		chatType = "channel"
+		if c.Megagroup {
+			chatType = "supergroup"
+		}
+		return tgclient.ChannelDialogID(c.ID), c.Title, chatType, c.ParticipantsCount, true
+	default:
+		return 0, "", "", 0, false
+	}
+}
@@ -0,0 +1,45 @@
+package tgdata
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gotd/td/tg"
+)
+
+func TestInvitePreview(t *testing.T) {
+	tests := []struct {
+		name   string
+		invite tg.ChatInviteClass
+		want   InvitePreview
+	}{
+		{
+			name:   "already joined supergroup",
+			invite: &tg.ChatInviteAlready{Chat: &tg.Channel{ID: 1234567890, Title: "Gophers", Megagroup: true, ParticipantsCount: 42}},
+			want:   InvitePreview{ChatID: -1001234567890, Title: "Gophers", Type: "supergroup", MembersCount: 42, AlreadyJoined: true},
+		},
+		{
+			name:   "peek into basic group",
+			invite: &tg.ChatInvitePeek{Chat: &tg.Chat{ID: 555, Title: "Family", ParticipantsCount: 5}, Expires: 1_700_000_000},
+			want:   InvitePreview{ChatID: 555, Title: "Family", Type: "group", MembersCount: 5, PeekUntil: time.Unix(1_700_000_000, 0)},
+		},
+		{
+			name:   "channel needing approval",
+			invite: &tg.ChatInvite{Title: "News", About: "Daily news", Broadcast: true, Channel: true, ParticipantsCount: 1000, RequestNeeded: true},
+			want:   InvitePreview{Title: "News", About: "Daily news", Type: "channel", MembersCount: 1000, RequestNeeded: true},
+		},
+		{
+			name:   "basic group invite",
+			invite: &tg.ChatInvite{Title: "Club", ParticipantsCount: 3, Fake: true},
+			want:   InvitePreview{Title: "Club", Type: "group", MembersCount: 3, Fake: true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := invitePreview(tt.invite); got != tt.want {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
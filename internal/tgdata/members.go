@@ -0,0 +1,275 @@
+package tgdata
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gotd/td/tg"
+	"github.com/gotd/td/tgerr"
+
+	"github.com/tolmachov/mcp-telegram/internal/tgclient"
+)
+
+// Member filters of GetChatMembers.
+const (
+	MembersRecent = "recent" // All members, most recently joined first
+	MembersAdmins = "admins" // Creator and admins
+	MembersBots   = "bots"   // Bots
+)
+
+// Member roles of ChatMember.Role.
+const (
+	RoleCreator    = "creator"
+	RoleAdmin      = "admin"
+	RoleMember     = "member"
+	RoleRestricted = "restricted" // A member with restricted rights
+)
+
+// maxMembersPerRequest is the most participants channels.getParticipants returns.
+const maxMembersPerRequest = 200
+
+// ChatMember is a member of a group or channel.
+type ChatMember struct {
+	UserID   int64     `json:"user_id"`
+	Name     string    `json:"name"`
+	Username string    `json:"username,omitempty"`
+	Bot      bool      `json:"bot,omitempty"`
+	Role     string    `json:"role"`
+	Rank     string    `json:"rank,omitempty"`     // Custom admin title
+	JoinedAt time.Time `json:"joined_at,omitzero"` // Unknown for creators
+}
+
+// ChatMembers is one page of a chat's members.
+type ChatMembers struct {
+	ChatID     int64        `json:"chat_id"`
+	Total      int          `json:"total"` // Members matching the filter, across all pages
+	Members    []ChatMember `json:"members"`
+	NextOffset int          `json:"next_offset,omitempty"`
+	Note       string       `json:"note,omitempty"`
+}
+
+// MembersQuery selects a page of members.
+type MembersQuery struct {
+	Filter string // MembersRecent (default), MembersAdmins or MembersBots
+	Search string // Name or username substring; only with MembersRecent
+	Limit  int
+	Offset int
+}
+
+// Validate checks the filter and its combination with a search query.
+func (q MembersQuery) Validate() error {
+	switch q.Filter {
+	case "", MembersRecent:
+	case MembersAdmins, MembersBots:
+		if q.Search != "" {
+			return fmt.Errorf("query can't be combined with the %s filter", q.Filter)
+		}
+	default:
+		return fmt.Errorf("invalid filter: %q (must be 'recent', 'admins' or 'bots')", q.Filter)
+	}
+	if q.Limit <= 0 || q.Offset < 0 {
+		return fmt.Errorf("limit must be positive and offset not negative")
+	}
+	return nil
+}
+
+// GetChatMembers lists members of a basic group from its full info, or of a channel
+// or supergroup by paging through channels.getParticipants. Pass a paced client: a
+// large limit takes several requests.
+func GetChatMembers(ctx context.Context, client *tg.Client, chatID int64, peer tg.InputPeerClass, q MembersQuery) (*ChatMembers, error) {
+	if err := q.Validate(); err != nil {
+		return nil, err
+	}
+	switch p := peer.(type) {
+	case *tg.InputPeerChat:
+		full, err := client.MessagesGetFullChat(ctx, p.ChatID)
+		if err != nil {
+			return nil, fmt.Errorf("getting full chat: %w", err)
+		}
+		chatFull, ok := full.FullChat.(*tg.ChatFull)
+		if !ok {
+			return nil, fmt.Errorf("unexpected full chat type %T", full.FullChat)
+		}
+		result := basicGroupMembers(chatFull.Participants, usersByID(full.Users), q)
+		result.ChatID = chatID
+		return &result, nil
+	case *tg.InputPeerChannel:
+		return channelMembers(ctx, client, chatID, &tg.InputChannel{ChannelID: p.ChannelID, AccessHash: p.AccessHash}, q)
+	default:
+		return nil, fmt.Errorf("chat %d is not a group or channel", chatID)
+	}
+}
+
+// basicGroupMembers filters and pages a basic group's participant list.
+func basicGroupMembers(participants tg.ChatParticipantsClass, users map[int64]*tg.User, q MembersQuery) ChatMembers {
+	list, ok := participants.(*tg.ChatParticipants)
+	if !ok {
+		return ChatMembers{Members: []ChatMember{}, Note: "The member list of this group is hidden from you"}
+	}
+
+	var matched []ChatMember
+	for _, p := range list.Participants {
+		var member ChatMember
+		switch p := p.(type) {
+		case *tg.ChatParticipantCreator:
+			member = ChatMember{UserID: p.UserID, Role: RoleCreator}
+		case *tg.ChatParticipantAdmin:
+			member = ChatMember{UserID: p.UserID, Role: RoleAdmin, JoinedAt: unixTime(p.Date)}
+		case *tg.ChatParticipant:
+			member = ChatMember{UserID: p.UserID, Role: RoleMember, JoinedAt: unixTime(p.Date)}
+		default:
+			continue
+		}
+		describeMember(&member, users)
+		if matchesMembersQuery(member, q) {
+			matched = append(matched, member)
+		}
+	}
+
+	page := ChatMembers{Total: len(matched), Members: []ChatMember{}}
+	if q.Offset < len(matched) {
+		end := min(q.Offset+q.Limit, len(matched))
+		page.Members = matched[q.Offset:end]
+		if end < len(matched) {
+			page.NextOffset = end
+		}
+	}
+	return page
+}
+
+// matchesMembersQuery applies a members query to a basic group member.
+func matchesMembersQuery(member ChatMember, q MembersQuery) bool {
+	switch q.Filter {
+	case MembersAdmins:
+		return member.Role == RoleCreator || member.Role == RoleAdmin
+	case MembersBots:
+		return member.Bot
+	}
+	if q.Search == "" {
+		return true
+	}
+	search := strings.ToLower(q.Search)
+	return strings.Contains(strings.ToLower(member.Name), search) || strings.Contains(strings.ToLower(member.Username), search)
+}
+
+// channelMembers pages through a channel's participants.
+func channelMembers(ctx context.Context, client *tg.Client, chatID int64, channel *tg.InputChannel, q MembersQuery) (*ChatMembers, error) {
+	result := &ChatMembers{ChatID: chatID, Members: []ChatMember{}}
+
+	full, err := client.ChannelsGetFullChannel(ctx, channel)
+	if err != nil {
+		return nil, fmt.Errorf("getting full channel: %w", err)
+	}
+	if channelFull, ok := full.FullChat.(*tg.ChannelFull); ok {
+		switch {
+		case !channelFull.CanViewParticipants:
+			result.Note = "The member list of this chat is only visible to its admins"
+			result.Total = channelFull.ParticipantsCount
+			return result, nil
+		case channelFull.ParticipantsHidden:
+			result.Note = "Members are hidden by the group's settings; only admins and members you can see are listed"
+		}
+	}
+
+	var filter tg.ChannelParticipantsFilterClass
+	switch {
+	case q.Filter == MembersAdmins:
+		filter = &tg.ChannelParticipantsAdmins{}
+	case q.Filter == MembersBots:
+		filter = &tg.ChannelParticipantsBots{}
+	case q.Search != "":
+		filter = &tg.ChannelParticipantsSearch{Q: q.Search}
+	default:
+		filter = &tg.ChannelParticipantsRecent{}
+	}
+
+	offset := q.Offset
+	for len(result.Members) < q.Limit {
+		batch := min(q.Limit-len(result.Members), maxMembersPerRequest)
+		resp, err := client.ChannelsGetParticipants(ctx, &tg.ChannelsGetParticipantsRequest{
+			Channel: channel,
+			Filter:  filter,
+			Offset:  offset,
+			Limit:   batch,
+		})
+		if tgerr.Is(err, "CHAT_ADMIN_REQUIRED") {
+			result.Note = "The member list of this chat is only visible to its admins"
+			return result, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("getting participants: %w", err)
+		}
+		page, ok := resp.(*tg.ChannelsChannelParticipants)
+		if !ok {
+			break
+		}
+		result.Total = page.Count
+		result.Members = append(result.Members, channelParticipants(page.Participants, usersByID(page.Users))...)
+		offset += len(page.Participants)
+		if len(page.Participants) < batch {
+			break
+		}
+	}
+	if offset < result.Total {
+		result.NextOffset = offset
+	}
+	return result, nil
+}
+
+// channelParticipants converts channel participants to members, skipping those who
+// left and non-user participants.
+func channelParticipants(participants []tg.ChannelParticipantClass, users map[int64]*tg.User) []ChatMember {
+	members := make([]ChatMember, 0, len(participants))
+	for _, p := range participants {
+		var member ChatMember
+		switch p := p.(type) {
+		case *tg.ChannelParticipantCreator:
+			member = ChatMember{UserID: p.UserID, Role: RoleCreator, Rank: p.Rank}
+		case *tg.ChannelParticipantAdmin:
+			member = ChatMember{UserID: p.UserID, Role: RoleAdmin, Rank: p.Rank, JoinedAt: unixTime(p.Date)}
+		case *tg.ChannelParticipant:
+			member = ChatMember{UserID: p.UserID, Role: RoleMember, JoinedAt: unixTime(p.Date)}
+		case *tg.ChannelParticipantSelf:
+			member = ChatMember{UserID: p.UserID, Role: RoleMember, JoinedAt: unixTime(p.Date)}
+		case *tg.ChannelParticipantBanned:
+			user, ok := p.Peer.(*tg.PeerUser)
+			if !ok || p.Left {
+				continue
+			}
+			member = ChatMember{UserID: user.UserID, Role: RoleRestricted, JoinedAt: unixTime(p.Date)}
+		default:
+			continue
+		}
+		describeMember(&member, users)
+		members = append(members, member)
+	}
+	return members
+}
+
+// describeMember fills in a member's name, username and bot flag.
+func describeMember(member *ChatMember, users map[int64]*tg.User) {
+	user, ok := users[member.UserID]
+	if !ok {
+		member.Name = "Unknown"
+		return
+	}
+	member.Name = strings.TrimSpace(user.FirstName + " " + user.LastName)
+	if member.Name == "" {
+		member.Name = tgclient.UserName(user)
+	}
+	member.Username = user.Username
+	member.Bot = user.Bot
+}
+
+// usersByID indexes users by ID.
+func usersByID(users []tg.UserClass) map[int64]*tg.User {
+	byID := make(map[int64]*tg.User, len(users))
+	for _, u := range users {
+		if user, ok := u.(*tg.User); ok {
+			byID[user.ID] = user
+		}
+	}
+	return byID
+}
@@ -0,0 +1,113 @@
+package tgdata
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/gotd/td/tg"
+)
+
+func memberUsers() map[int64]*tg.User {
+	return usersByID([]tg.UserClass{
+		&tg.User{ID: 1, FirstName: "Alice", Username: "alice"},
+		&tg.User{ID: 2, FirstName: "Bob", LastName: "Stone"},
+		&tg.User{ID: 3, FirstName: "Helper", Username: "helper_bot", Bot: true},
+	})
+}
+
+func TestBasicGroupMembers(t *testing.T) {
+	participants := &tg.ChatParticipants{Participants: []tg.ChatParticipantClass{
+		&tg.ChatParticipantCreator{UserID: 1},
+		&tg.ChatParticipantAdmin{UserID: 2, Date: 1_700_000_000},
+		&tg.ChatParticipant{UserID: 3, Date: 1_700_000_100},
+		&tg.ChatParticipant{UserID: 4, Date: 1_700_000_200},
+	}}
+	alice := ChatMember{UserID: 1, Name: "Alice", Username: "alice", Role: RoleCreator}
+	bob := ChatMember{UserID: 2, Name: "Bob Stone", Role: RoleAdmin, JoinedAt: time.Unix(1_700_000_000, 0)}
+	helper := ChatMember{UserID: 3, Name: "Helper", Username: "helper_bot", Bot: true, Role: RoleMember, JoinedAt: time.Unix(1_700_000_100, 0)}
+	unknown := ChatMember{UserID: 4, Name: "Unknown", Role: RoleMember, JoinedAt: time.Unix(1_700_000_200, 0)}
+
+	tests := []struct {
+		name string
+		q    MembersQuery
+		want ChatMembers
+	}{
+		{
+			name: "first page",
+			q:    MembersQuery{Limit: 3},
+			want: ChatMembers{Total: 4, Members: []ChatMember{alice, bob, helper}, NextOffset: 3},
+		},
+		{
+			name: "last page",
+			q:    MembersQuery{Limit: 3, Offset: 3},
+			want: ChatMembers{Total: 4, Members: []ChatMember{unknown}},
+		},
+		{
+			name: "admins",
+			q:    MembersQuery{Filter: MembersAdmins, Limit: 10},
+			want: ChatMembers{Total: 2, Members: []ChatMember{alice, bob}},
+		},
+		{
+			name: "bots",
+			q:    MembersQuery{Filter: MembersBots, Limit: 10},
+			want: ChatMembers{Total: 1, Members: []ChatMember{helper}},
+		},
+		{
+			name: "search by username",
+			q:    MembersQuery{Search: "ALI", Limit: 10},
+			want: ChatMembers{Total: 1, Members: []ChatMember{alice}},
+		},
+		{
+			name: "offset past the end",
+			q:    MembersQuery{Limit: 10, Offset: 10},
+			want: ChatMembers{Total: 4, Members: []ChatMember{}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := basicGroupMembers(participants, memberUsers(), tt.q)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("basicGroupMembers() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBasicGroupMembersHidden(t *testing.T) {
+	got := basicGroupMembers(&tg.ChatParticipantsForbidden{}, nil, MembersQuery{Limit: 10})
+	if got.Note == "" || len(got.Members) != 0 {
+		t.Errorf("basicGroupMembers(forbidden) = %+v, want no members and a note", got)
+	}
+}
+
+func TestChannelParticipants(t *testing.T) {
+	got := channelParticipants([]tg.ChannelParticipantClass{
+		&tg.ChannelParticipantCreator{UserID: 1, Rank: "Founder"},
+		&tg.ChannelParticipantAdmin{UserID: 2, Rank: "Mod", Date: 1_700_000_000},
+		&tg.ChannelParticipantBanned{Peer: &tg.PeerUser{UserID: 3}, Date: 1_700_000_100},
+		&tg.ChannelParticipantBanned{Peer: &tg.PeerUser{UserID: 4}, Left: true},
+		&tg.ChannelParticipantLeft{Peer: &tg.PeerUser{UserID: 5}},
+	}, memberUsers())
+
+	want := []ChatMember{
+		{UserID: 1, Name: "Alice", Username: "alice", Role: RoleCreator, Rank: "Founder"},
+		{UserID: 2, Name: "Bob Stone", Role: RoleAdmin, Rank: "Mod", JoinedAt: time.Unix(1_700_000_000, 0)},
+		{UserID: 3, Name: "Helper", Username: "helper_bot", Bot: true, Role: RoleRestricted, JoinedAt: time.Unix(1_700_000_100, 0)},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("channelParticipants() = %+v, want %+v", got, want)
+	}
+}
+
+func TestMembersQueryValidate(t *testing.T) {
+	if err := (MembersQuery{Filter: MembersAdmins, Search: "bob", Limit: 10}).Validate(); err == nil {
+		t.Error("Validate() accepted a query with the admins filter")
+	}
+	if err := (MembersQuery{Filter: "kicked", Limit: 10}).Validate(); err == nil {
+		t.Error("Validate() accepted an unknown filter")
+	}
+	if err := (MembersQuery{Search: "bob", Limit: 10}).Validate(); err != nil {
+		t.Errorf("Validate() = %v for a search of all members", err)
+	}
+}
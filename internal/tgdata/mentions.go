@@ -0,0 +1,93 @@
+package tgdata
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gotd/td/tg"
+
+	"github.com/tolmachov/mcp-telegram/internal/messages"
+)
+
+// Mention is an unread message that mentions me or replies to one of my messages.
+type Mention struct {
+	MessageID int       `json:"message_id"`
+	SenderID  int64     `json:"sender_id,omitempty"`
+	Sender    string    `json:"sender"`
+	Text      string    `json:"text"`
+	Date      time.Time `json:"date"`
+	ReplyToID int       `json:"reply_to_id,omitempty"`
+	Link      string    `json:"link,omitempty"` // Only in channels and supergroups
+}
+
+// GetUnreadMentions retrieves the unread messages in a chat that mention me or reply
+// to my messages, newest first.
+func GetUnreadMentions(ctx context.Context, client *tg.Client, peer tg.InputPeerClass, limit int) ([]Mention, error) {
+	result, err := client.MessagesGetUnreadMentions(ctx, &tg.MessagesGetUnreadMentionsRequest{
+		Peer:  peer,
+		Limit: limit,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("getting unread mentions: %w", err)
+	}
+
+	modified, ok := result.AsModified()
+	if !ok {
+		return nil, fmt.Errorf("unexpected response type: %T", result)
+	}
+	return mentionsFromMessages(modified.GetMessages(), modified.GetUsers(), modified.GetChats()), nil
+}
+
+// mentionsFromMessages converts the messages of an unread mentions response, naming
+// their senders and linking those posted in channels and supergroups.
+func mentionsFromMessages(msgs []tg.MessageClass, users []tg.UserClass, chats []tg.ChatClass) []Mention {
+	names := peerNames(users, chats)
+	usernames := make(map[int64]string)
+	for _, c := range chats {
+		if channel, ok := c.(*tg.Channel); ok {
+			usernames[channel.ID] = channel.Username
+		}
+	}
+
+	mentions := make([]Mention, 0, len(msgs))
+	for _, m := range msgs {
+		msg, ok := m.(*tg.Message)
+		if !ok {
+			continue
+		}
+		// Private chats leave the sender out: it is the chat itself
+		from := msg.FromID
+		if from == nil {
+			from = msg.PeerID
+		}
+		senderID := peerID(from)
+		sender := names[senderID]
+		if sender == "" {
+			sender = "Unknown"
+		}
+		mention := Mention{
+			MessageID: msg.ID,
+			SenderID:  senderID,
+			Sender:    sender,
+			Text:      msg.Message,
+			Date:      time.Unix(int64(msg.Date), 0),
+		}
+		if reply, ok := msg.ReplyTo.(*tg.MessageReplyHeader); ok {
+			mention.ReplyToID = reply.ReplyToMsgID
+		}
+		if channel, ok := msg.PeerID.(*tg.PeerChannel); ok {
+			mention.Link = messages.MessageLink(usernames[channel.ChannelID], channel.ChannelID, msg.ID)
+		}
+		mentions = append(mentions, mention)
+	}
+	return mentions
+}
+
+// ReadMentions marks all mentions in a chat as read.
+func ReadMentions(ctx context.Context, client *tg.Client, peer tg.InputPeerClass) error {
+	if _, err := client.MessagesReadMentions(ctx, &tg.MessagesReadMentionsRequest{Peer: peer}); err != nil {
+		return fmt.Errorf("reading mentions: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,47 @@
+package tgdata
+
+import (
+	"testing"
+
+	"github.com/gotd/td/tg"
+)
+
+func TestMentionsFromMessages(t *testing.T) {
+	msgs := []tg.MessageClass{
+		&tg.Message{
+			ID:      30,
+			PeerID:  &tg.PeerChannel{ChannelID: 500},
+			FromID:  &tg.PeerUser{UserID: 10},
+			Message: "@me can you check this?",
+			Date:    1_700_000_000,
+		},
+		&tg.Message{
+			ID:      31,
+			PeerID:  &tg.PeerChannel{ChannelID: 600},
+			FromID:  &tg.PeerUser{UserID: 11},
+			Message: "agreed",
+			ReplyTo: &tg.MessageReplyHeader{ReplyToMsgID: 29},
+		},
+		&tg.MessageService{ID: 32, PeerID: &tg.PeerChannel{ChannelID: 500}},
+		&tg.Message{ID: 7, PeerID: &tg.PeerUser{UserID: 10}, Message: "hey"},
+	}
+	users := []tg.UserClass{&tg.User{ID: 10, FirstName: "Alice"}}
+	chats := []tg.ChatClass{
+		&tg.Channel{ID: 500, Title: "Public", Username: "publicgroup"},
+		&tg.Channel{ID: 600, Title: "Private"},
+	}
+
+	got := mentionsFromMessages(msgs, users, chats)
+	if len(got) != 3 {
+		t.Fatalf("got %d mentions, want 3 (service message skipped)", len(got))
+	}
+	if got[0].Sender != "Alice" || got[0].SenderID != 10 || got[0].Link != "https://t.me/publicgroup/30" {
+		t.Errorf("public group mention = %+v", got[0])
+	}
+	if got[1].Sender != "Unknown" || got[1].ReplyToID != 29 || got[1].Link != "https://t.me/c/600/31" {
+		t.Errorf("private group reply = %+v", got[1])
+	}
+	if got[2].Sender != "Alice" || got[2].Link != "" {
+		t.Errorf("private chat mention = %+v, want sender from the chat and no link", got[2])
+	}
+}
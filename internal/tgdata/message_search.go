@@ -0,0 +1,101 @@
+package tgdata
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gotd/td/tg"
+
+	"github.com/tolmachov/mcp-telegram/internal/tgclient"
+)
+
+// MessageHit is a message found by a global message search, with the chat it belongs to.
+type MessageHit struct {
+	Chat      ChatInfo
+	MessageID int
+	Text      string
+	Date      time.Time
+}
+
+// SearchMessagesGlobal searches all chats for messages containing query, newest first.
+func SearchMessagesGlobal(ctx context.Context, client *tg.Client, query string, limit int) ([]MessageHit, error) {
+	result, err := client.MessagesSearchGlobal(ctx, &tg.MessagesSearchGlobalRequest{
+		Q:          query,
+		Filter:     &tg.InputMessagesFilterEmpty{},
+		OffsetPeer: &tg.InputPeerEmpty{},
+		Limit:      limit,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("searching messages: %w", err)
+	}
+
+	modified, ok := result.AsModified()
+	if !ok {
+		return nil, fmt.Errorf("unexpected response type: %T", result)
+	}
+
+	users := make(map[int64]*tg.User)
+	for _, u := range modified.GetUsers() {
+		if user, ok := u.(*tg.User); ok {
+			users[user.ID] = user
+		}
+	}
+	chats := make(map[int64]tg.ChatClass)
+	for _, c := range modified.GetChats() {
+		chats[c.GetID()] = c
+	}
+
+	var hits []MessageHit
+	for _, m := range modified.GetMessages() {
+		msg, ok := m.(*tg.Message)
+		if !ok {
+			continue
+		}
+		chat, ok := peerChatInfo(msg.PeerID, users, chats)
+		if !ok {
+			continue
+		}
+		hits = append(hits, MessageHit{
+			Chat:      chat,
+			MessageID: msg.ID,
+			Text:      msg.Message,
+			Date:      time.Unix(int64(msg.Date), 0),
+		})
+	}
+	return hits, nil
+}
+
+// peerChatInfo builds basic chat info for a message peer from the entities of a response.
+func peerChatInfo(peer tg.PeerClass, users map[int64]*tg.User, chats map[int64]tg.ChatClass) (ChatInfo, bool) {
+	switch p := peer.(type) {
+	case *tg.PeerUser:
+		user, ok := users[p.UserID]
+		if !ok {
+			return ChatInfo{}, false
+		}
+		chatType := "user"
+		if user.Bot {
+			chatType = "bot"
+		}
+		return ChatInfo{ID: user.ID, Type: chatType, Name: tgclient.UserName(user), Username: user.Username}, true
+	case *tg.PeerChat:
+		chat, ok := chats[p.ChatID].(*tg.Chat)
+		if !ok {
+			return ChatInfo{}, false
+		}
+		return ChatInfo{ID: chat.ID, Type: "group", Name: chat.Title}, true
+	case *tg.PeerChannel:
+		channel, ok := chats[p.ChannelID].(*tg.Channel)
+		if !ok {
+			return ChatInfo{}, false
+		}
+		chatType := "channel"
+		if channel.Megagroup {
+			chatType = "supergroup"
+		}
+		return ChatInfo{ID: tgclient.ChannelDialogID(channel.ID), Type: chatType, Name: channel.Title, Username: channel.Username}, true
+	default:
+		return ChatInfo{}, false
+	}
+}
@@ -0,0 +1,194 @@
+package tgdata
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gotd/td/tg"
+
+	"github.com/tolmachov/mcp-telegram/internal/tgclient"
+)
+
+// PinnedScope selects which pins GetPinnedChats reports.
+type PinnedScope string
+
+const (
+	PinnedScopeAll  PinnedScope = "all"  // main list, archive and folder pins
+	PinnedScopeMain PinnedScope = "main" // main list pins only
+)
+
+// ValidatePinnedScope checks if the pinned scope is valid.
+func ValidatePinnedScope(scope string) error {
+	switch PinnedScope(scope) {
+	case PinnedScopeAll, PinnedScopeMain:
+		return nil
+	default:
+		return fmt.Errorf("invalid pinned scope: %q (must be 'all' or 'main')", scope)
+	}
+}
+
+// Pin containers other than folders, which are named by their title.
+const (
+	PinnedInMain    = "main"
+	PinnedInArchive = "archive"
+)
+
+// PinnedChat is a chat pinned in one container: the main list, the archive, or a folder.
+// A chat pinned in several containers is reported once per container.
+type PinnedChat struct {
+	ChatInfo
+	Container string `json:"container"`           // PinnedInMain, PinnedInArchive, or the folder title
+	FolderID  int    `json:"folder_id,omitempty"` // Dialog filter ID of a folder container
+	PinOrder  int    `json:"pin_order"`           // 1-based position among the container's pins
+}
+
+// ContainerName returns a human-readable name of the pin's container.
+func (c PinnedChat) ContainerName() string {
+	switch {
+	case c.FolderID != 0:
+		return c.Container
+	case c.Container == PinnedInArchive:
+		return "archive"
+	default:
+		return "main list"
+	}
+}
+
+// pinContainer is a dialog list whose pinned dialogs come first, in pin order.
+type pinContainer struct {
+	folderID int
+	name     string
+}
+
+// dialogFolder is a user-defined chat folder with its pinned chats in display order
+// and the rules deciding which other chats it contains.
+type dialogFolder struct {
+	ID         int
+	Title      string
+	Emoticon   string
+	Shared     bool    // A chat list shared by invite link
+	PinnedIDs  []int64 // User-facing chat IDs
+	IncludeIDs []int64
+	ExcludeIDs []int64
+	// Chat categories the folder includes, and which of their chats it leaves out
+	Contacts, NonContacts, Groups, Broadcasts, Bots bool
+	ExcludeMuted, ExcludeRead, ExcludeArchived      bool
+}
+
+// folderLister returns the user's chat folders in display order.
+type folderLister func(ctx context.Context) ([]dialogFolder, error)
+
+// GetPinnedChats retrieves pinned chats grouped by container, in the order the Telegram
+// apps show them: main list pins, archive pins, then each folder's pins.
+func GetPinnedChats(ctx context.Context, client *tg.Client, scope PinnedScope) ([]PinnedChat, error) {
+	return collectPinned(ctx, dialogFolderIterator(client, time.Now()), dialogFolderLister(client), scope)
+}
+
+// collectPinned gathers pins from the main list and archive (whose dialogs come pinned
+// first, in pin order) and, unless scope is main-only, from every folder.
+func collectPinned(ctx context.Context, iter folderIterator, listFolders folderLister, scope PinnedScope) ([]PinnedChat, error) {
+	containers := []pinContainer{{mainFolderID, PinnedInMain}}
+	if scope != PinnedScopeMain {
+		containers = append(containers, pinContainer{archiveFolderID, PinnedInArchive})
+	}
+
+	var pinned []PinnedChat
+	known := make(map[int64]ChatInfo)
+	for _, container := range containers {
+		order := 0
+		err := iter(ctx, container.folderID, func(chat ChatInfo) error {
+			if container.folderID == archiveFolderID {
+				chat.Archived = true
+			}
+			known[chat.ID] = chat
+			if chat.Pinned {
+				order++
+				pinned = append(pinned, PinnedChat{ChatInfo: chat, Container: container.name, PinOrder: order})
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("folder %d: %w", container.folderID, err)
+		}
+	}
+	if scope == PinnedScopeMain {
+		return pinned, nil
+	}
+
+	folders, err := listFolders(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("listing folders: %w", err)
+	}
+	for _, folder := range folders {
+		for i, id := range folder.PinnedIDs {
+			chat, ok := known[id]
+			if !ok {
+				chat = ChatInfo{ID: id, Name: "Unknown"}
+			}
+			// Pinned describes the chat's own list; here it is pinned in the folder
+			chat.Pinned = true
+			pinned = append(pinned, PinnedChat{ChatInfo: chat, Container: folder.Title, FolderID: folder.ID, PinOrder: i + 1})
+		}
+	}
+	return pinned, nil
+}
+
+// dialogFolderLister returns a folderLister backed by messages.getDialogFilters.
+func dialogFolderLister(client *tg.Client) folderLister {
+	return func(ctx context.Context) ([]dialogFolder, error) {
+		filters, err := client.MessagesGetDialogFilters(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return foldersFromFilters(filters.Filters), nil
+	}
+}
+
+// foldersFromFilters converts dialog filters to folders, skipping the "All chats" entry.
+func foldersFromFilters(filters []tg.DialogFilterClass) []dialogFolder {
+	var folders []dialogFolder
+	for _, f := range filters {
+		var folder dialogFolder
+		var pinned, include, exclude []tg.InputPeerClass
+		switch filter := f.(type) {
+		case *tg.DialogFilter:
+			folder = dialogFolder{
+				ID:              filter.ID,
+				Title:           filter.Title.Text,
+				Emoticon:        filter.Emoticon,
+				Contacts:        filter.Contacts,
+				NonContacts:     filter.NonContacts,
+				Groups:          filter.Groups,
+				Broadcasts:      filter.Broadcasts,
+				Bots:            filter.Bots,
+				ExcludeMuted:    filter.ExcludeMuted,
+				ExcludeRead:     filter.ExcludeRead,
+				ExcludeArchived: filter.ExcludeArchived,
+			}
+			pinned, include, exclude = filter.PinnedPeers, filter.IncludePeers, filter.ExcludePeers
+		case *tg.DialogFilterChatlist:
+			folder = dialogFolder{ID: filter.ID, Title: filter.Title.Text, Emoticon: filter.Emoticon, Shared: true}
+			pinned, include = filter.PinnedPeers, filter.IncludePeers
+		default:
+			// The "All chats" entry; its pins are the main list's
+			continue
+		}
+		folder.PinnedIDs = inputPeerChatIDs(pinned)
+		folder.IncludeIDs = inputPeerChatIDs(include)
+		folder.ExcludeIDs = inputPeerChatIDs(exclude)
+		folders = append(folders, folder)
+	}
+	return folders
+}
+
+// inputPeerChatIDs converts input peers to user-facing chat IDs, skipping unknown kinds.
+func inputPeerChatIDs(peers []tg.InputPeerClass) []int64 {
+	var ids []int64
+	for _, peer := range peers {
+		if id, ok := tgclient.DialogPeerID(peer); ok {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
@@ -0,0 +1,117 @@
+package tgdata
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// fakeFolderLister is a folderLister serving fixed folders.
+func fakeFolderLister(folders []dialogFolder, err error, calls *int) folderLister {
+	return func(context.Context) ([]dialogFolder, error) {
+		*calls++
+		return folders, err
+	}
+}
+
+func TestCollectPinned(t *testing.T) {
+	dialogs := map[int][]ChatInfo{
+		mainFolderID: {
+			// Pinned dialogs come first, in pin order
+			{ID: 3, Name: "Family", Pinned: true},
+			{ID: 1, Name: "Standup", Pinned: true},
+			{ID: 2, Name: "Alice"},
+		},
+		archiveFolderID: {
+			{ID: 4, Name: "Old project", Pinned: true},
+			{ID: 5, Name: "Newsletter"},
+		},
+	}
+	folders := []dialogFolder{
+		{ID: 10, Title: "Work", PinnedIDs: []int64{5, 1}},
+		{ID: 11, Title: "Empty"},
+		{ID: 12, Title: "Friends", PinnedIDs: []int64{99}},
+	}
+
+	tests := []struct {
+		name        string
+		scope       PinnedScope
+		want        []PinnedChat
+		wantFolders []int
+		wantLists   int
+	}{
+		{
+			name:  "all containers",
+			scope: PinnedScopeAll,
+			want: []PinnedChat{
+				{ChatInfo: ChatInfo{ID: 3, Name: "Family", Pinned: true}, Container: PinnedInMain, PinOrder: 1},
+				{ChatInfo: ChatInfo{ID: 1, Name: "Standup", Pinned: true}, Container: PinnedInMain, PinOrder: 2},
+				{ChatInfo: ChatInfo{ID: 4, Name: "Old project", Pinned: true, Archived: true}, Container: PinnedInArchive, PinOrder: 1},
+				{ChatInfo: ChatInfo{ID: 5, Name: "Newsletter", Pinned: true, Archived: true}, Container: "Work", FolderID: 10, PinOrder: 1},
+				{ChatInfo: ChatInfo{ID: 1, Name: "Standup", Pinned: true}, Container: "Work", FolderID: 10, PinOrder: 2},
+				{ChatInfo: ChatInfo{ID: 99, Name: "Unknown", Pinned: true}, Container: "Friends", FolderID: 12, PinOrder: 1},
+			},
+			wantFolders: []int{mainFolderID, archiveFolderID},
+			wantLists:   1,
+		},
+		{
+			name:  "main list only",
+			scope: PinnedScopeMain,
+			want: []PinnedChat{
+				{ChatInfo: ChatInfo{ID: 3, Name: "Family", Pinned: true}, Container: PinnedInMain, PinOrder: 1},
+				{ChatInfo: ChatInfo{ID: 1, Name: "Standup", Pinned: true}, Container: PinnedInMain, PinOrder: 2},
+			},
+			wantFolders: []int{mainFolderID},
+			wantLists:   0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls []int
+			var lists int
+			got, err := collectPinned(t.Context(), fakeFolders(dialogs, &calls), fakeFolderLister(folders, nil, &lists), tt.scope)
+			if err != nil {
+				t.Fatalf("collectPinned() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("collectPinned() =\n%+v\nwant\n%+v", got, tt.want)
+			}
+			if !reflect.DeepEqual(calls, tt.wantFolders) {
+				t.Errorf("iterated folders %v, want %v", calls, tt.wantFolders)
+			}
+			if lists != tt.wantLists {
+				t.Errorf("listed folders %d times, want %d", lists, tt.wantLists)
+			}
+		})
+	}
+}
+
+func TestCollectPinnedFolderError(t *testing.T) {
+	var calls []int
+	var lists int
+	errFolders := errors.New("flood wait")
+	_, err := collectPinned(t.Context(), fakeFolders(nil, &calls), fakeFolderLister(nil, errFolders, &lists), PinnedScopeAll)
+	if !errors.Is(err, errFolders) {
+		t.Errorf("collectPinned() error = %v, want %v", err, errFolders)
+	}
+}
+
+func TestPinnedChatContainerName(t *testing.T) {
+	tests := []struct {
+		pin  PinnedChat
+		want string
+	}{
+		{PinnedChat{Container: PinnedInMain}, "main list"},
+		{PinnedChat{Container: PinnedInArchive}, "archive"},
+		{PinnedChat{Container: "Work", FolderID: 10}, "Work"},
+		// A folder may be titled like a built-in container
+		{PinnedChat{Container: "archive", FolderID: 11}, "archive"},
+	}
+	for _, tt := range tests {
+		if got := tt.pin.ContainerName(); got != tt.want {
+			t.Errorf("ContainerName(%+v) = %q, want %q", tt.pin, got, tt.want)
+		}
+	}
+}
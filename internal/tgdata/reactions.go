@@ -0,0 +1,223 @@
+package tgdata
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/gotd/td/tg"
+
+	"github.com/tolmachov/mcp-telegram/internal/messages"
+	"github.com/tolmachov/mcp-telegram/internal/tgclient"
+)
+
+// Reaction is a single reaction someone left on a message.
+type Reaction struct {
+	Reaction string    `json:"reaction"`
+	FromID   int64     `json:"from_id"`
+	From     string    `json:"from"`
+	Unread   bool      `json:"unread"`
+	Date     time.Time `json:"date"`
+}
+
+// ReactedMessage is one of my messages that received unread reactions.
+type ReactedMessage struct {
+	MessageID int        `json:"message_id"`
+	Text      string     `json:"text"`
+	Date      time.Time  `json:"date"`
+	Reactions []Reaction `json:"reactions"`
+}
+
+// GetUnreadReactions retrieves my messages in a chat that have reactions I haven't seen.
+func GetUnreadReactions(ctx context.Context, client *tg.Client, peer tg.InputPeerClass, limit int) ([]ReactedMessage, error) {
+	result, err := client.MessagesGetUnreadReactions(ctx, &tg.MessagesGetUnreadReactionsRequest{
+		Peer:  peer,
+		Limit: limit,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("getting unread reactions: %w", err)
+	}
+
+	modified, ok := result.AsModified()
+	if !ok {
+		return nil, fmt.Errorf("unexpected response type: %T", result)
+	}
+
+	names := peerNames(modified.GetUsers(), modified.GetChats())
+
+	var reacted []ReactedMessage
+	for _, m := range modified.GetMessages() {
+		msg, ok := m.(*tg.Message)
+		if !ok {
+			continue
+		}
+		rm := ReactedMessage{
+			MessageID: msg.ID,
+			Text:      msg.Message,
+			Date:      time.Unix(int64(msg.Date), 0),
+			Reactions: []Reaction{},
+		}
+		for _, r := range msg.Reactions.RecentReactions {
+			fromID := peerID(r.PeerID)
+			from := names[fromID]
+			if from == "" {
+				from = "Unknown"
+			}
+			rm.Reactions = append(rm.Reactions, Reaction{
+				Reaction: messages.ReactionString(r.Reaction),
+				FromID:   fromID,
+				From:     from,
+				Unread:   r.Unread,
+				Date:     time.Unix(int64(r.Date), 0),
+			})
+		}
+		reacted = append(reacted, rm)
+	}
+	return reacted, nil
+}
+
+// ReactionCount is how many times a reaction was left on a message.
+type ReactionCount struct {
+	Reaction string `json:"reaction"`
+	Count    int    `json:"count"`
+	Mine     bool   `json:"mine,omitempty"`
+}
+
+// MessageReactions are the reactions on a message and, where Telegram shows them,
+// who left them.
+type MessageReactions struct {
+	MessageID int             `json:"message_id"`
+	Total     int             `json:"total"`
+	Counts    []ReactionCount `json:"counts"`
+	Reactors  []Reaction      `json:"reactors,omitempty"`
+	// ReactorsHidden explains why Reactors is empty despite reactions, e.g. in channels
+	ReactorsHidden string `json:"reactors_hidden,omitempty"`
+	NextOffset     string `json:"next_offset,omitempty"`
+}
+
+// GetMessageReactions retrieves the reaction counts of a message and up to limit of
+// the people who reacted, starting at offset (from an earlier NextOffset).
+func GetMessageReactions(ctx context.Context, client *tg.Client, peer tg.InputPeerClass, msgID, limit int, offset string) (*MessageReactions, error) {
+	updates, err := client.MessagesGetMessagesReactions(ctx, &tg.MessagesGetMessagesReactionsRequest{
+		Peer: peer,
+		ID:   []int{msgID},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("getting reactions: %w", err)
+	}
+	result := &MessageReactions{MessageID: msgID, Counts: []ReactionCount{}}
+	reactions, found := findMessageReactions(updates, msgID)
+	if found {
+		result.Counts, result.Total = reactionCounts(reactions)
+	}
+	if result.Total == 0 {
+		return result, nil
+	}
+	if !reactions.CanSeeList {
+		result.ReactorsHidden = "Telegram doesn't show who reacted in channels and large groups"
+		return result, nil
+	}
+
+	list, err := client.MessagesGetMessageReactionsList(ctx, &tg.MessagesGetMessageReactionsListRequest{
+		Peer:   peer,
+		ID:     msgID,
+		Offset: offset,
+		Limit:  limit,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("listing who reacted: %w", err)
+	}
+	result.Reactors = reactorsFromList(list)
+	result.NextOffset = list.NextOffset
+	return result, nil
+}
+
+// findMessageReactions finds the reactions of msgID in an updates response.
+func findMessageReactions(updates tg.UpdatesClass, msgID int) (tg.MessageReactions, bool) {
+	u, ok := updates.(*tg.Updates)
+	if !ok {
+		return tg.MessageReactions{}, false
+	}
+	for _, update := range u.Updates {
+		if r, ok := update.(*tg.UpdateMessageReactions); ok && r.MsgID == msgID {
+			return r.Reactions, true
+		}
+	}
+	return tg.MessageReactions{}, false
+}
+
+// reactionCounts converts reaction counts, most frequent first, and sums them up.
+func reactionCounts(reactions tg.MessageReactions) ([]ReactionCount, int) {
+	counts := make([]ReactionCount, 0, len(reactions.Results))
+	total := 0
+	for _, r := range reactions.Results {
+		_, mine := r.GetChosenOrder()
+		counts = append(counts, ReactionCount{Reaction: messages.ReactionString(r.Reaction), Count: r.Count, Mine: mine})
+		total += r.Count
+	}
+	slices.SortStableFunc(counts, func(a, b ReactionCount) int { return b.Count - a.Count })
+	return counts, total
+}
+
+// reactorsFromList converts a list of who reacted, naming each reactor.
+func reactorsFromList(list *tg.MessagesMessageReactionsList) []Reaction {
+	names := peerNames(list.Users, list.Chats)
+	reactors := make([]Reaction, 0, len(list.Reactions))
+	for _, r := range list.Reactions {
+		fromID := peerID(r.PeerID)
+		from := names[fromID]
+		if from == "" {
+			from = "Unknown"
+		}
+		reactors = append(reactors, Reaction{
+			Reaction: messages.ReactionString(r.Reaction),
+			FromID:   fromID,
+			From:     from,
+			Unread:   r.Unread,
+			Date:     time.Unix(int64(r.Date), 0),
+		})
+	}
+	return reactors
+}
+
+// ReadReactions marks all reactions in a chat as read.
+func ReadReactions(ctx context.Context, client *tg.Client, peer tg.InputPeerClass) error {
+	if _, err := client.MessagesReadReactions(ctx, &tg.MessagesReadReactionsRequest{Peer: peer}); err != nil {
+		return fmt.Errorf("reading reactions: %w", err)
+	}
+	return nil
+}
+
+// peerNames maps user, chat and channel IDs to display names.
+func peerNames(users []tg.UserClass, chats []tg.ChatClass) map[int64]string {
+	names := make(map[int64]string, len(users)+len(chats))
+	for _, u := range users {
+		if user, ok := u.(*tg.User); ok {
+			names[user.ID] = tgclient.UserName(user)
+		}
+	}
+	for _, c := range chats {
+		switch chat := c.(type) {
+		case *tg.Chat:
+			names[chat.ID] = chat.Title
+		case *tg.Channel:
+			names[chat.ID] = chat.Title
+		}
+	}
+	return names
+}
+
+// peerID returns the raw ID of a peer.
+func peerID(peer tg.PeerClass) int64 {
+	switch p := peer.(type) {
+	case *tg.PeerUser:
+		return p.UserID
+	case *tg.PeerChat:
+		return p.ChatID
+	case *tg.PeerChannel:
+		return p.ChannelID
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,65 @@
+package tgdata
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/gotd/td/tg"
+)
+
+func TestReactionCounts(t *testing.T) {
+	mine := tg.ReactionCount{Reaction: &tg.ReactionEmoji{Emoticon: "🔥"}, Count: 5}
+	mine.SetChosenOrder(0)
+	reactions := tg.MessageReactions{Results: []tg.ReactionCount{
+		{Reaction: &tg.ReactionEmoji{Emoticon: "👍"}, Count: 2},
+		mine,
+		{Reaction: &tg.ReactionCustomEmoji{DocumentID: 9}, Count: 2},
+	}}
+
+	counts, total := reactionCounts(reactions)
+	want := []ReactionCount{
+		{Reaction: "🔥", Count: 5, Mine: true},
+		{Reaction: "👍", Count: 2},
+		{Reaction: "custom emoji 9", Count: 2},
+	}
+	if !reflect.DeepEqual(counts, want) {
+		t.Errorf("reactionCounts() = %v, want %v", counts, want)
+	}
+	if total != 9 {
+		t.Errorf("total = %d, want 9", total)
+	}
+}
+
+func TestFindMessageReactions(t *testing.T) {
+	updates := &tg.Updates{Updates: []tg.UpdateClass{
+		&tg.UpdateMessageReactions{MsgID: 1, Reactions: tg.MessageReactions{CanSeeList: false}},
+		&tg.UpdateMessageReactions{MsgID: 2, Reactions: tg.MessageReactions{CanSeeList: true}},
+	}}
+	got, ok := findMessageReactions(updates, 2)
+	if !ok || !got.CanSeeList {
+		t.Errorf("findMessageReactions(2) = %+v, %v, want message 2's reactions", got, ok)
+	}
+	if _, ok := findMessageReactions(updates, 3); ok {
+		t.Error("findMessageReactions(3) found reactions of a message not in the updates")
+	}
+}
+
+func TestReactorsFromList(t *testing.T) {
+	list := &tg.MessagesMessageReactionsList{
+		Reactions: []tg.MessagePeerReaction{
+			{PeerID: &tg.PeerUser{UserID: 10}, Reaction: &tg.ReactionEmoji{Emoticon: "👍"}, Date: 1_700_000_000},
+			{PeerID: &tg.PeerUser{UserID: 11}, Reaction: &tg.ReactionEmoji{Emoticon: "❤"}},
+		},
+		Users: []tg.UserClass{&tg.User{ID: 10, FirstName: "Alice"}},
+	}
+	reactors := reactorsFromList(list)
+	if len(reactors) != 2 {
+		t.Fatalf("got %d reactors, want 2", len(reactors))
+	}
+	if reactors[0].From != "Alice" || reactors[0].Reaction != "👍" || reactors[0].FromID != 10 {
+		t.Errorf("first reactor = %+v, want Alice with 👍", reactors[0])
+	}
+	if reactors[1].From != "Unknown" {
+		t.Errorf("reactor without a user = %q, want Unknown", reactors[1].From)
+	}
+}
@@ -0,0 +1,264 @@
+package tgdata
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gotd/td/tg"
+)
+
+// Chat type selectors of ChatSelector.Type.
+const (
+	SelectChannels = "channels" // Broadcast channels
+	SelectGroups   = "groups"   // Basic groups and supergroups
+	SelectBots     = "bots"     // Private chats with bots
+)
+
+// ChatSelector picks chats for bulk operations: an explicit list of chat IDs, every
+// chat of a type, or the chats of a folder. Exactly one of them must be set.
+type ChatSelector struct {
+	ChatIDs []int64
+	Type    string // SelectChannels, SelectGroups or SelectBots
+	Folder  string // Folder title, matched case-insensitively
+}
+
+// Validate checks that exactly one valid selector is set.
+func (s ChatSelector) Validate() error {
+	set := 0
+	for _, ok := range []bool{len(s.ChatIDs) > 0, s.Type != "", s.Folder != ""} {
+		if ok {
+			set++
+		}
+	}
+	if set != 1 {
+		return errors.New("exactly one of chat_ids, type or folder is required")
+	}
+	switch s.Type {
+	case "", SelectChannels, SelectGroups, SelectBots:
+		return nil
+	default:
+		return fmt.Errorf("invalid type: %q (must be 'channels', 'groups' or 'bots')", s.Type)
+	}
+}
+
+// contactsLister returns the user IDs of the user's contacts.
+type contactsLister func(ctx context.Context) (map[int64]bool, error)
+
+// SelectChats expands a selector to the chats it covers, in chat list order, archived
+// chats included. Explicit chat IDs are returned as given without fetching the chat list.
+func SelectChats(ctx context.Context, client *tg.Client, sel ChatSelector, onProgress ProgressFunc) ([]ChatInfo, error) {
+	return selectChats(ctx, dialogFolderIterator(client, time.Now()), dialogFolderLister(client), contactIDsLister(client), sel, onProgress)
+}
+
+// selectChats implements SelectChats over injectable chat, folder and contact sources.
+func selectChats(ctx context.Context, iter folderIterator, listFolders folderLister, listContacts contactsLister, sel ChatSelector, onProgress ProgressFunc) ([]ChatInfo, error) {
+	if err := sel.Validate(); err != nil {
+		return nil, err
+	}
+	if len(sel.ChatIDs) > 0 {
+		selected := make([]ChatInfo, len(sel.ChatIDs))
+		for i, id := range sel.ChatIDs {
+			selected[i] = ChatInfo{ID: id}
+		}
+		return selected, nil
+	}
+
+	chats, err := collectChats(ctx, iter, []int{mainFolderID, archiveFolderID}, true, onProgress)
+	if err != nil {
+		return nil, fmt.Errorf("listing chats: %w", err)
+	}
+
+	if sel.Type != "" {
+		var selected []ChatInfo
+		for _, chat := range chats {
+			if matchesType(chat, sel.Type) {
+				selected = append(selected, chat)
+			}
+		}
+		return selected, nil
+	}
+
+	folders, err := listFolders(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("listing folders: %w", err)
+	}
+	i := slices.IndexFunc(folders, func(f dialogFolder) bool { return strings.EqualFold(f.Title, sel.Folder) })
+	if i < 0 {
+		titles := make([]string, len(folders))
+		for j, f := range folders {
+			titles[j] = f.Title
+		}
+		return nil, fmt.Errorf("folder %q not found (folders: %s)", sel.Folder, strings.Join(titles, ", "))
+	}
+	return chatsInFolder(ctx, folders[i], chats, listContacts)
+}
+
+// chatsInFolder returns the chats that belong to a folder, in chat list order.
+func chatsInFolder(ctx context.Context, folder dialogFolder, chats []ChatInfo, listContacts contactsLister) ([]ChatInfo, error) {
+	// Contacts only matter when the folder includes one kind of private chat but not the other
+	var contacts map[int64]bool
+	if folder.Contacts != folder.NonContacts {
+		var err error
+		if contacts, err = listContacts(ctx); err != nil {
+			return nil, fmt.Errorf("listing contacts: %w", err)
+		}
+	}
+
+	var selected []ChatInfo
+	for _, chat := range chats {
+		if inFolder(folder, chat, contacts) {
+			selected = append(selected, chat)
+		}
+	}
+	return selected, nil
+}
+
+// matchesType reports whether a chat is of the selected type.
+func matchesType(chat ChatInfo, selType string) bool {
+	switch selType {
+	case SelectChannels:
+		return chat.Type == "channel"
+	case SelectGroups:
+		return chat.Type == "group" || chat.Type == "supergroup"
+	case SelectBots:
+		return chat.Type == "bot"
+	default:
+		return false
+	}
+}
+
+// Archived chat filters of ChatFilter.Archived.
+const (
+	ArchivedInclude = "include" // Archived and other chats alike
+	ArchivedOnly    = "only"    // Only archived chats
+	ArchivedExclude = "exclude" // Only chats outside the archive
+)
+
+// ChatFilter narrows the chat list down by attributes. Unset fields match every chat.
+type ChatFilter struct {
+	Type         string        // SelectChannels, SelectGroups or SelectBots
+	InactiveFor  time.Duration // Latest message older than this
+	NameContains string        // Case-insensitive substring of the chat name
+	Archived     string        // ArchivedInclude (default), ArchivedOnly or ArchivedExclude
+}
+
+// Validate checks the filter's type and archived values.
+func (f ChatFilter) Validate() error {
+	switch f.Type {
+	case "", SelectChannels, SelectGroups, SelectBots:
+	default:
+		return fmt.Errorf("invalid type: %q (must be 'channels', 'groups' or 'bots')", f.Type)
+	}
+	switch f.Archived {
+	case "", ArchivedInclude, ArchivedOnly, ArchivedExclude:
+	default:
+		return fmt.Errorf("invalid archived: %q (must be 'include', 'only' or 'exclude')", f.Archived)
+	}
+	if f.InactiveFor < 0 {
+		return errors.New("inactivity must not be negative")
+	}
+	return nil
+}
+
+// Matches reports whether a chat passes the filter at time now. With InactiveFor set,
+// chats of unknown last activity don't match.
+func (f ChatFilter) Matches(chat ChatInfo, now time.Time) bool {
+	if f.Type != "" && !matchesType(chat, f.Type) {
+		return false
+	}
+	if f.NameContains != "" && !strings.Contains(strings.ToLower(chat.Name), strings.ToLower(f.NameContains)) {
+		return false
+	}
+	switch f.Archived {
+	case ArchivedOnly:
+		if !chat.Archived {
+			return false
+		}
+	case ArchivedExclude:
+		if chat.Archived {
+			return false
+		}
+	}
+	if f.InactiveFor > 0 && (chat.LastActivity.IsZero() || now.Sub(chat.LastActivity) < f.InactiveFor) {
+		return false
+	}
+	return true
+}
+
+// FilterChats lists all chats, archived ones included, that match the filter, in chat
+// list order.
+func FilterChats(ctx context.Context, client *tg.Client, filter ChatFilter, onProgress ProgressFunc) ([]ChatInfo, error) {
+	now := time.Now()
+	return filterChats(ctx, dialogFolderIterator(client, now), filter, now, onProgress)
+}
+
+// filterChats implements FilterChats over an injectable chat source.
+func filterChats(ctx context.Context, iter folderIterator, filter ChatFilter, now time.Time, onProgress ProgressFunc) ([]ChatInfo, error) {
+	if err := filter.Validate(); err != nil {
+		return nil, err
+	}
+	chats, err := collectChats(ctx, iter, []int{mainFolderID, archiveFolderID}, true, onProgress)
+	if err != nil {
+		return nil, fmt.Errorf("listing chats: %w", err)
+	}
+	var matched []ChatInfo
+	for _, chat := range chats {
+		if filter.Matches(chat, now) {
+			matched = append(matched, chat)
+		}
+	}
+	return matched, nil
+}
+
+// inFolder applies a folder's rules the way the Telegram apps do: pinned and included
+// chats are always in it, excluded ones never, and other chats are in it when their
+// category is included and none of the exclusion flags rule them out.
+func inFolder(folder dialogFolder, chat ChatInfo, contacts map[int64]bool) bool {
+	if slices.Contains(folder.PinnedIDs, chat.ID) || slices.Contains(folder.IncludeIDs, chat.ID) {
+		return true
+	}
+	if slices.Contains(folder.ExcludeIDs, chat.ID) {
+		return false
+	}
+
+	var category bool
+	switch chat.Type {
+	case "user":
+		category = (folder.Contacts && folder.NonContacts) ||
+			(folder.Contacts && contacts[chat.ID]) ||
+			(folder.NonContacts && !contacts[chat.ID])
+	case "bot":
+		category = folder.Bots
+	case "group", "supergroup":
+		category = folder.Groups
+	case "channel":
+		category = folder.Broadcasts
+	}
+	if !category {
+		return false
+	}
+	return !(folder.ExcludeMuted && chat.Muted) &&
+		!(folder.ExcludeRead && chat.UnreadCount == 0) &&
+		!(folder.ExcludeArchived && chat.Archived)
+}
+
+// contactIDsLister returns a contactsLister backed by contacts.getContacts.
+func contactIDsLister(client *tg.Client) contactsLister {
+	return func(ctx context.Context) (map[int64]bool, error) {
+		result, err := client.ContactsGetContacts(ctx, 0)
+		if err != nil {
+			return nil, err
+		}
+		contacts := make(map[int64]bool)
+		if c, ok := result.AsModified(); ok {
+			for _, contact := range c.Contacts {
+				contacts[contact.UserID] = true
+			}
+		}
+		return contacts, nil
+	}
+}
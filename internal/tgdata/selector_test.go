@@ -0,0 +1,192 @@
+package tgdata
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestChatSelectorValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		sel     ChatSelector
+		wantErr bool
+	}{
+		{"chat IDs", ChatSelector{ChatIDs: []int64{1, 2}}, false},
+		{"type", ChatSelector{Type: SelectChannels}, false},
+		{"folder", ChatSelector{Folder: "Work"}, false},
+		{"nothing", ChatSelector{}, true},
+		{"two selectors", ChatSelector{Type: SelectBots, Folder: "Work"}, true},
+		{"unknown type", ChatSelector{Type: "users"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.sel.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSelectChats(t *testing.T) {
+	dialogs := map[int][]ChatInfo{
+		mainFolderID: {
+			{ID: 1, Type: "user", Name: "Alice"},
+			{ID: 2, Type: "user", Name: "Stranger"},
+			{ID: 3, Type: "bot", Name: "@helper_bot"},
+			{ID: 4, Type: "group", Name: "Family", UnreadCount: 2},
+			{ID: -1001, Type: "supergroup", Name: "Conference", Muted: true, UnreadCount: 5},
+			{ID: -1002, Type: "channel", Name: "News", UnreadCount: 1},
+		},
+		archiveFolderID: {
+			{ID: -1003, Type: "channel", Name: "Old news"},
+		},
+	}
+	folders := []dialogFolder{
+		{ID: 10, Title: "Work", PinnedIDs: []int64{2}, IncludeIDs: []int64{1}, Groups: true, ExcludeIDs: []int64{4}},
+		{ID: 11, Title: "Unread", Groups: true, Broadcasts: true, ExcludeMuted: true, ExcludeRead: true},
+		{ID: 12, Title: "Contacts", Contacts: true},
+		{ID: 13, Title: "People", Contacts: true, NonContacts: true, ExcludeArchived: true},
+	}
+	contacts := map[int64]bool{1: true}
+
+	ids := func(chats []ChatInfo) []int64 {
+		var out []int64
+		for _, c := range chats {
+			out = append(out, c.ID)
+		}
+		return out
+	}
+
+	tests := []struct {
+		name         string
+		sel          ChatSelector
+		want         []int64
+		wantLists    []int
+		wantContacts int
+		wantErr      bool
+	}{
+		{"chat IDs as given", ChatSelector{ChatIDs: []int64{4, 99}}, []int64{4, 99}, nil, 0, false},
+		{"channels include archived", ChatSelector{Type: SelectChannels}, []int64{-1002, -1003}, []int{mainFolderID, archiveFolderID}, 0, false},
+		{"groups", ChatSelector{Type: SelectGroups}, []int64{4, -1001}, []int{mainFolderID, archiveFolderID}, 0, false},
+		{"bots", ChatSelector{Type: SelectBots}, []int64{3}, []int{mainFolderID, archiveFolderID}, 0, false},
+		{"folder with pinned, included and excluded chats", ChatSelector{Folder: "work"}, []int64{1, 2, -1001}, []int{mainFolderID, archiveFolderID}, 0, false},
+		{"folder exclusion flags", ChatSelector{Folder: "Unread"}, []int64{4, -1002}, []int{mainFolderID, archiveFolderID}, 0, false},
+		{"folder of contacts", ChatSelector{Folder: "Contacts"}, []int64{1}, []int{mainFolderID, archiveFolderID}, 1, false},
+		{"folder of all people", ChatSelector{Folder: "People"}, []int64{1, 2}, []int{mainFolderID, archiveFolderID}, 0, false},
+		{"unknown folder", ChatSelector{Folder: "Travel"}, nil, []int{mainFolderID, archiveFolderID}, 0, true},
+		{"invalid selector", ChatSelector{}, nil, nil, 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls []int
+			contactCalls := 0
+			listContacts := func(context.Context) (map[int64]bool, error) {
+				contactCalls++
+				return contacts, nil
+			}
+			lists := 0
+			got, err := selectChats(t.Context(), fakeFolders(dialogs, &calls), fakeFolderLister(folders, nil, &lists), listContacts, tt.sel, nil)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("selectChats() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(ids(got), tt.want) {
+				t.Errorf("selectChats() = %v, want %v", ids(got), tt.want)
+			}
+			if !reflect.DeepEqual(calls, tt.wantLists) {
+				t.Errorf("folders iterated = %v, want %v", calls, tt.wantLists)
+			}
+			if contactCalls != tt.wantContacts {
+				t.Errorf("contacts listed %d times, want %d", contactCalls, tt.wantContacts)
+			}
+		})
+	}
+}
+
+func TestSelectChatsFolderError(t *testing.T) {
+	var calls []int
+	lists := 0
+	_, err := selectChats(t.Context(), fakeFolders(nil, &calls), fakeFolderLister(nil, errors.New("boom"), &lists), nil, ChatSelector{Folder: "Work"}, nil)
+	if err == nil {
+		t.Fatal("selectChats() succeeded despite a folder listing error")
+	}
+}
+
+func TestChatFilterValidate(t *testing.T) {
+	valid := []ChatFilter{
+		{},
+		{Type: SelectGroups, Archived: ArchivedOnly, InactiveFor: time.Hour, NameContains: "x"},
+	}
+	for _, f := range valid {
+		if err := f.Validate(); err != nil {
+			t.Errorf("Validate(%+v) error = %v", f, err)
+		}
+	}
+	invalid := []ChatFilter{
+		{Type: "users"},
+		{Archived: "yes"},
+		{InactiveFor: -time.Hour},
+	}
+	for _, f := range invalid {
+		if err := f.Validate(); err == nil {
+			t.Errorf("Validate(%+v) succeeded, want error", f)
+		}
+	}
+}
+
+func TestFilterChats(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	yearAgo := now.AddDate(-1, 0, -1)
+	dialogs := map[int][]ChatInfo{
+		mainFolderID: {
+			{ID: 1, Type: "user", Name: "Alice", LastActivity: now.Add(-time.Hour)},
+			{ID: 4, Type: "group", Name: "School friends", LastActivity: yearAgo},
+			{ID: -1001, Type: "supergroup", Name: "Project Alpha", LastActivity: now.AddDate(0, -1, 0)},
+			{ID: -1002, Type: "channel", Name: "Alpha news", LastActivity: yearAgo},
+			{ID: 5, Type: "group", Name: "No messages"},
+		},
+		archiveFolderID: {
+			{ID: -1003, Type: "supergroup", Name: "Old alpha team", LastActivity: yearAgo},
+		},
+	}
+	ids := func(chats []ChatInfo) []int64 {
+		var out []int64
+		for _, c := range chats {
+			out = append(out, c.ID)
+		}
+		return out
+	}
+
+	tests := []struct {
+		name   string
+		filter ChatFilter
+		want   []int64
+	}{
+		{"no filter", ChatFilter{}, []int64{1, 4, -1001, -1002, 5, -1003}},
+		{"groups inactive for a year", ChatFilter{Type: SelectGroups, InactiveFor: 365 * 24 * time.Hour}, []int64{4, -1003}},
+		{"name substring", ChatFilter{NameContains: "ALPHA"}, []int64{-1001, -1002, -1003}},
+		{"archived only", ChatFilter{Archived: ArchivedOnly}, []int64{-1003}},
+		{"archive excluded", ChatFilter{NameContains: "alpha", Archived: ArchivedExclude}, []int64{-1001, -1002}},
+		{"everything combined", ChatFilter{Type: SelectGroups, NameContains: "alpha", InactiveFor: 24 * time.Hour, Archived: ArchivedExclude}, []int64{-1001}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls []int
+			got, err := filterChats(t.Context(), fakeFolders(dialogs, &calls), tt.filter, now, nil)
+			if err != nil {
+				t.Fatalf("filterChats() error = %v", err)
+			}
+			if !reflect.DeepEqual(ids(got), tt.want) {
+				t.Errorf("filterChats() = %v, want %v", ids(got), tt.want)
+			}
+		})
+	}
+
+	if _, err := filterChats(t.Context(), fakeFolders(dialogs, new([]int)), ChatFilter{Type: "users"}, now, nil); err == nil {
+		t.Error("filterChats() with an invalid filter succeeded")
+	}
+}
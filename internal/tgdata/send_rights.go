@@ -0,0 +1,313 @@
+package tgdata
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gotd/td/tg"
+
+	"github.com/tolmachov/mcp-telegram/internal/tgclient"
+)
+
+// SendRights describes whether the current user can post in a chat.
+type SendRights struct {
+	ChatID       int64  `json:"chat_id"`
+	Name         string `json:"name,omitempty"`
+	Type         string `json:"type,omitempty"`
+	CanSendText  bool   `json:"can_send_text"`
+	CanSendMedia bool   `json:"can_send_media"`
+	// AdminsOnly marks broadcast channels, where only admins with the post right can post
+	AdminsOnly           bool      `json:"admins_only,omitempty"`
+	Banned               bool      `json:"banned,omitempty"`     // Kicked or banned from the chat
+	Restricted           bool      `json:"restricted,omitempty"` // Personally restricted from sending
+	RestrictedUntil      time.Time `json:"restricted_until,omitzero"`
+	SlowmodeSeconds      int       `json:"slowmode_seconds,omitempty"`
+	SlowmodeNextSendDate time.Time `json:"slowmode_next_send_date,omitzero"` // Earliest next post under slow mode
+	Reason               string    `json:"reason,omitempty"`                 // Why sending is not possible
+	Error                string    `json:"error,omitempty"`
+}
+
+// chatAccess is what deriveSendRights needs to know about a chat and the user's place in it.
+type chatAccess struct {
+	Type           string // "user", "bot", "group", "supergroup" or "channel"
+	Name           string
+	Deleted        bool // Deleted user account
+	Forbidden      bool // Kicked or banned from the chat
+	ForbiddenUntil int
+	Left           bool // Not a member
+	Deactivated    bool // Basic group migrated to a supergroup
+	Creator        bool
+	Admin          *tg.ChatAdminRights
+	Banned         *tg.ChatBannedRights // Restrictions of the user
+	Default        *tg.ChatBannedRights // Restrictions of all members
+	// Slow mode state from the full channel; only fetched for restricted members
+	SlowmodeSeconds      int
+	SlowmodeNextSendDate int
+}
+
+// deriveSendRights works out what the user can post from the chat type, the user's
+// admin rights, and the personal and chat-wide banned rights.
+func deriveSendRights(chatID int64, a chatAccess, now time.Time) SendRights {
+	r := SendRights{ChatID: chatID, Name: a.Name, Type: a.Type}
+
+	switch {
+	case a.Deleted:
+		r.Reason = "the account is deleted"
+		return r
+	case a.Forbidden:
+		r.Banned = true
+		r.RestrictedUntil = unixTime(a.ForbiddenUntil)
+		r.Reason = "you are banned from this chat"
+		return r
+	case a.Deactivated:
+		r.Reason = "the group was upgraded to a supergroup; post there instead"
+		return r
+	case a.Left:
+		r.Reason = "you are not a member of this chat"
+		return r
+	}
+
+	switch a.Type {
+	case "user", "bot":
+		r.CanSendText, r.CanSendMedia = true, true
+		return r
+	case "channel":
+		r.AdminsOnly = true
+		if a.Creator || (a.Admin != nil && a.Admin.PostMessages) {
+			r.CanSendText, r.CanSendMedia = true, true
+		} else {
+			r.Reason = "only admins can post in this channel"
+		}
+		return r
+	}
+
+	// Groups: creators and admins are exempt from restrictions and slow mode
+	if a.Creator || a.Admin != nil {
+		r.CanSendText, r.CanSendMedia = true, true
+		return r
+	}
+
+	banned := a.Banned
+	if banned != nil && banned.UntilDate != 0 && unixTime(banned.UntilDate).Before(now) {
+		banned = nil // Expired restriction
+	}
+	if banned != nil && banned.ViewMessages {
+		r.Banned = true
+		r.RestrictedUntil = unixTime(banned.UntilDate)
+		r.Reason = "you are banned from this chat"
+		return r
+	}
+
+	r.CanSendText = !blocksText(banned) && !blocksText(a.Default)
+	r.CanSendMedia = !blocksMedia(banned) && !blocksMedia(a.Default)
+	if blocksText(banned) || blocksMedia(banned) {
+		r.Restricted = true
+		r.RestrictedUntil = unixTime(banned.UntilDate)
+	}
+	switch {
+	case !r.CanSendText && !r.CanSendMedia:
+		r.Reason = "sending messages is restricted"
+	case !r.CanSendText:
+		r.Reason = "sending text is restricted"
+	case !r.CanSendMedia:
+		r.Reason = "sending media is restricted"
+	}
+
+	r.SlowmodeSeconds = a.SlowmodeSeconds
+	if next := unixTime(a.SlowmodeNextSendDate); next.After(now) {
+		r.SlowmodeNextSendDate = next
+	}
+	return r
+}
+
+// blocksText reports whether banned rights forbid sending text messages.
+func blocksText(b *tg.ChatBannedRights) bool {
+	return b != nil && (b.SendMessages || b.SendPlain)
+}
+
+// blocksMedia reports whether banned rights forbid sending every kind of media.
+func blocksMedia(b *tg.ChatBannedRights) bool {
+	if b == nil {
+		return false
+	}
+	return b.SendMessages || b.SendMedia ||
+		(b.SendPhotos && b.SendVideos && b.SendDocs && b.SendAudios && b.SendVoices && b.SendRoundvideos)
+}
+
+// unixTime converts a Telegram timestamp, returning the zero time for 0.
+func unixTime(ts int) time.Time {
+	if ts == 0 {
+		return time.Time{}
+	}
+	return time.Unix(int64(ts), 0)
+}
+
+// GetSendRights reports for each chat whether the current user can post there, in the
+// order of chatIDs. Chats are resolved through the peer cache, then looked up in
+// batches by kind; the full channel is only fetched for slow mode of supergroups where
+// it applies. Callers checking many chats pass a paced client to share a rate limiter.
+func GetSendRights(ctx context.Context, client *tg.Client, peers *tgclient.PeerCache, chatIDs []int64) []SendRights {
+	access := make(map[int64]chatAccess, len(chatIDs))
+	errs := make(map[int64]error)
+
+	var userIDs, groupIDs, channelIDs []int64
+	var users []tg.InputUserClass
+	var channels []tg.InputChannelClass
+	for _, id := range chatIDs {
+		peer, err := peers.ResolvePeer(ctx, client, id)
+		if err != nil {
+			errs[id] = err
+			continue
+		}
+		switch p := peer.(type) {
+		case *tg.InputPeerUser:
+			userIDs = append(userIDs, id)
+			users = append(users, &tg.InputUser{UserID: p.UserID, AccessHash: p.AccessHash})
+		case *tg.InputPeerChat:
+			groupIDs = append(groupIDs, p.ChatID)
+		case *tg.InputPeerChannel:
+			channelIDs = append(channelIDs, id)
+			channels = append(channels, &tg.InputChannel{ChannelID: p.ChannelID, AccessHash: p.AccessHash})
+		}
+	}
+
+	if len(users) > 0 {
+		found, err := client.UsersGetUsers(ctx, users)
+		if err != nil {
+			for _, id := range userIDs {
+				errs[id] = err
+			}
+		}
+		for _, u := range found {
+			if user, ok := u.(*tg.User); ok {
+				access[user.ID] = userAccess(user)
+			}
+		}
+	}
+
+	if len(groupIDs) > 0 {
+		chats, err := client.MessagesGetChats(ctx, groupIDs)
+		if err != nil {
+			for _, id := range groupIDs {
+				errs[id] = err
+			}
+		} else {
+			for _, c := range chats.GetChats() {
+				if id, a, ok := chatClassAccess(c); ok {
+					access[id] = a
+				}
+			}
+		}
+	}
+
+	if len(channels) > 0 {
+		chats, err := client.ChannelsGetChannels(ctx, channels)
+		if err != nil {
+			for _, id := range channelIDs {
+				errs[id] = err
+			}
+		} else {
+			for _, c := range chats.GetChats() {
+				id, a, ok := chatClassAccess(c)
+				if !ok {
+					continue
+				}
+				if channel, isChannel := c.(*tg.Channel); isChannel && channel.SlowmodeEnabled && a.Admin == nil && !a.Creator && !a.Left {
+					if err := addSlowmode(ctx, client, channel, &a); err != nil {
+						errs[id] = err
+						continue
+					}
+				}
+				access[id] = a
+			}
+		}
+	}
+
+	now := time.Now()
+	rights := make([]SendRights, len(chatIDs))
+	for i, id := range chatIDs {
+		if a, ok := access[id]; ok {
+			rights[i] = deriveSendRights(id, a, now)
+			continue
+		}
+		rights[i] = SendRights{ChatID: id, Error: "chat not found"}
+		if err, ok := errs[id]; ok {
+			rights[i].Error = err.Error()
+		}
+	}
+	return rights
+}
+
+// userAccess describes a private chat with a user or bot.
+func userAccess(user *tg.User) chatAccess {
+	a := chatAccess{Type: "user", Name: tgclient.UserName(user), Deleted: user.Deleted}
+	if user.Bot {
+		a.Type = "bot"
+	}
+	return a
+}
+
+// chatClassAccess describes a group or channel, keyed by its user-facing chat ID.
+func chatClassAccess(c tg.ChatClass) (int64, chatAccess, bool) {
+	switch chat := c.(type) {
+	case *tg.Chat:
+		a := chatAccess{
+			Type:        "group",
+			Name:        chat.Title,
+			Left:        chat.Left,
+			Deactivated: chat.Deactivated,
+			Creator:     chat.Creator,
+		}
+		if admin, ok := chat.GetAdminRights(); ok {
+			a.Admin = &admin
+		}
+		if def, ok := chat.GetDefaultBannedRights(); ok {
+			a.Default = &def
+		}
+		return chat.ID, a, true
+	case *tg.ChatForbidden:
+		return chat.ID, chatAccess{Type: "group", Name: chat.Title, Forbidden: true}, true
+	case *tg.Channel:
+		a := chatAccess{
+			Type:    "channel",
+			Name:    chat.Title,
+			Left:    chat.Left,
+			Creator: chat.Creator,
+		}
+		if chat.Megagroup {
+			a.Type = "supergroup"
+		}
+		if admin, ok := chat.GetAdminRights(); ok {
+			a.Admin = &admin
+		}
+		if banned, ok := chat.GetBannedRights(); ok {
+			a.Banned = &banned
+		}
+		if def, ok := chat.GetDefaultBannedRights(); ok {
+			a.Default = &def
+		}
+		return tgclient.ChannelDialogID(chat.ID), a, true
+	case *tg.ChannelForbidden:
+		a := chatAccess{Type: "channel", Name: chat.Title, Forbidden: true, ForbiddenUntil: chat.UntilDate}
+		if chat.Megagroup {
+			a.Type = "supergroup"
+		}
+		return tgclient.ChannelDialogID(chat.ID), a, true
+	default:
+		return 0, chatAccess{}, false
+	}
+}
+
+// addSlowmode fills in the slow mode state of a supergroup from its full info.
+func addSlowmode(ctx context.Context, client *tg.Client, channel *tg.Channel, a *chatAccess) error {
+	full, err := client.ChannelsGetFullChannel(ctx, &tg.InputChannel{ChannelID: channel.ID, AccessHash: channel.AccessHash})
+	if err != nil {
+		return fmt.Errorf("getting full channel: %w", err)
+	}
+	if cf, ok := full.FullChat.(*tg.ChannelFull); ok {
+		a.SlowmodeSeconds = cf.SlowmodeSeconds
+		a.SlowmodeNextSendDate = cf.SlowmodeNextSendDate
+	}
+	return nil
+}
@@ -0,0 +1,140 @@
+package tgdata
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/gotd/td/tg"
+)
+
+func TestDeriveSendRights(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	later := int(now.Add(time.Hour).Unix())
+	earlier := int(now.Add(-time.Hour).Unix())
+
+	tests := []struct {
+		name   string
+		access chatAccess
+		want   SendRights
+	}{
+		{
+			name:   "user",
+			access: chatAccess{Type: "user"},
+			want:   SendRights{Type: "user", CanSendText: true, CanSendMedia: true},
+		},
+		{
+			name:   "deleted account",
+			access: chatAccess{Type: "user", Deleted: true},
+			want:   SendRights{Type: "user", Reason: "the account is deleted"},
+		},
+		{
+			name:   "channel subscriber",
+			access: chatAccess{Type: "channel"},
+			want:   SendRights{Type: "channel", AdminsOnly: true, Reason: "only admins can post in this channel"},
+		},
+		{
+			name:   "channel admin without post right",
+			access: chatAccess{Type: "channel", Admin: &tg.ChatAdminRights{EditMessages: true}},
+			want:   SendRights{Type: "channel", AdminsOnly: true, Reason: "only admins can post in this channel"},
+		},
+		{
+			name:   "channel admin with post right",
+			access: chatAccess{Type: "channel", Admin: &tg.ChatAdminRights{PostMessages: true}},
+			want:   SendRights{Type: "channel", AdminsOnly: true, CanSendText: true, CanSendMedia: true},
+		},
+		{
+			name:   "channel creator",
+			access: chatAccess{Type: "channel", Creator: true},
+			want:   SendRights{Type: "channel", AdminsOnly: true, CanSendText: true, CanSendMedia: true},
+		},
+		{
+			name:   "group member",
+			access: chatAccess{Type: "group"},
+			want:   SendRights{Type: "group", CanSendText: true, CanSendMedia: true},
+		},
+		{
+			name:   "left group",
+			access: chatAccess{Type: "supergroup", Left: true},
+			want:   SendRights{Type: "supergroup", Reason: "you are not a member of this chat"},
+		},
+		{
+			name:   "migrated group",
+			access: chatAccess{Type: "group", Deactivated: true},
+			want:   SendRights{Type: "group", Reason: "the group was upgraded to a supergroup; post there instead"},
+		},
+		{
+			name:   "kicked",
+			access: chatAccess{Type: "supergroup", Forbidden: true, ForbiddenUntil: later},
+			want:   SendRights{Type: "supergroup", Banned: true, RestrictedUntil: time.Unix(int64(later), 0), Reason: "you are banned from this chat"},
+		},
+		{
+			name:   "banned from viewing",
+			access: chatAccess{Type: "supergroup", Banned: &tg.ChatBannedRights{ViewMessages: true}},
+			want:   SendRights{Type: "supergroup", Banned: true, Reason: "you are banned from this chat"},
+		},
+		{
+			name:   "muted until later",
+			access: chatAccess{Type: "supergroup", Banned: &tg.ChatBannedRights{SendMessages: true, UntilDate: later}},
+			want: SendRights{Type: "supergroup", Restricted: true, RestrictedUntil: time.Unix(int64(later), 0),
+				Reason: "sending messages is restricted"},
+		},
+		{
+			name:   "expired restriction",
+			access: chatAccess{Type: "supergroup", Banned: &tg.ChatBannedRights{SendMessages: true, UntilDate: earlier}},
+			want:   SendRights{Type: "supergroup", CanSendText: true, CanSendMedia: true},
+		},
+		{
+			name:   "personal media restriction",
+			access: chatAccess{Type: "supergroup", Banned: &tg.ChatBannedRights{SendMedia: true}},
+			want:   SendRights{Type: "supergroup", CanSendText: true, Restricted: true, Reason: "sending media is restricted"},
+		},
+		{
+			name: "every media kind restricted",
+			access: chatAccess{Type: "supergroup", Banned: &tg.ChatBannedRights{
+				SendPhotos: true, SendVideos: true, SendDocs: true, SendAudios: true, SendVoices: true, SendRoundvideos: true,
+			}},
+			want: SendRights{Type: "supergroup", CanSendText: true, Restricted: true, Reason: "sending media is restricted"},
+		},
+		{
+			name:   "some media kinds restricted",
+			access: chatAccess{Type: "supergroup", Banned: &tg.ChatBannedRights{SendPhotos: true}},
+			want:   SendRights{Type: "supergroup", CanSendText: true, CanSendMedia: true},
+		},
+		{
+			name:   "plain text restricted",
+			access: chatAccess{Type: "supergroup", Banned: &tg.ChatBannedRights{SendPlain: true}},
+			want:   SendRights{Type: "supergroup", CanSendMedia: true, Restricted: true, Reason: "sending text is restricted"},
+		},
+		{
+			name:   "chat-wide restriction",
+			access: chatAccess{Type: "group", Default: &tg.ChatBannedRights{SendMessages: true}},
+			want:   SendRights{Type: "group", Reason: "sending messages is restricted"},
+		},
+		{
+			name:   "admin ignores chat-wide restriction",
+			access: chatAccess{Type: "group", Admin: &tg.ChatAdminRights{}, Default: &tg.ChatBannedRights{SendMessages: true}},
+			want:   SendRights{Type: "group", CanSendText: true, CanSendMedia: true},
+		},
+		{
+			name:   "slow mode waiting",
+			access: chatAccess{Type: "supergroup", SlowmodeSeconds: 60, SlowmodeNextSendDate: later},
+			want: SendRights{Type: "supergroup", CanSendText: true, CanSendMedia: true,
+				SlowmodeSeconds: 60, SlowmodeNextSendDate: time.Unix(int64(later), 0)},
+		},
+		{
+			name:   "slow mode elapsed",
+			access: chatAccess{Type: "supergroup", SlowmodeSeconds: 60, SlowmodeNextSendDate: earlier},
+			want:   SendRights{Type: "supergroup", CanSendText: true, CanSendMedia: true, SlowmodeSeconds: 60},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := deriveSendRights(0, tt.access, now)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("deriveSendRights() =\n%+v\nwant\n%+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,42 @@
+package tgdata
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/gotd/td/tg"
+)
+
+const (
+	// transcribePollInterval is how long to wait before asking again for a
+	// transcription Telegram is still working on.
+	transcribePollInterval = 2 * time.Second
+	// transcribePollAttempts bounds the wait for a pending transcription.
+	transcribePollAttempts = 15
+)
+
+// ErrTranscriptionPending reports a transcription Telegram didn't finish in time.
+var ErrTranscriptionPending = errors.New("Telegram is still transcribing the message; try again in a minute")
+
+// TranscribeAudio returns Telegram's transcription of a voice or video message,
+// waiting while it is pending. Accounts without Premium get a few free trials.
+func TranscribeAudio(ctx context.Context, client *tg.Client, peer tg.InputPeerClass, msgID int) (string, error) {
+	for attempt := range transcribePollAttempts {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return "", ctx.Err()
+			case <-time.After(transcribePollInterval):
+			}
+		}
+		result, err := client.MessagesTranscribeAudio(ctx, &tg.MessagesTranscribeAudioRequest{Peer: peer, MsgID: msgID})
+		if err != nil {
+			return "", err
+		}
+		if !result.Pending {
+			return result.Text, nil
+		}
+	}
+	return "", ErrTranscriptionPending
+}
@@ -1,5 +1,7 @@
 package tgdata
 
+import "time"
+
 // UserInfo represents information about a Telegram user
 type UserInfo struct {
 	ID        int64  `json:"id"`
@@ -13,22 +15,28 @@ type UserInfo struct {
 
 // ChatInfo represents basic information about a chat
 type ChatInfo struct {
-	ID           int64  `json:"id"`
-	Type         string `json:"type"`
-	Name         string `json:"name"`
-	Username     string `json:"username,omitempty"`
-	UnreadCount  int    `json:"unread_count"`
-	MentionCount int    `json:"mention_count"`
-	Muted        bool   `json:"muted"`
-	Pinned       bool   `json:"pinned"`
-	Archived     bool   `json:"archived"`
+	ID                   int64  `json:"id"`
+	Type                 string `json:"type"`
+	Name                 string `json:"name"`
+	Username             string `json:"username,omitempty"`
+	UnreadCount          int    `json:"unread_count"`
+	MentionCount         int    `json:"mention_count"`
+	UnreadReactionsCount int    `json:"unread_reactions_count"`
+	Muted                bool   `json:"muted"`
+	Pinned               bool   `json:"pinned"`
+	Archived             bool   `json:"archived"`
+	// LastActivity is the date of the chat's latest message, zero when unknown
+	LastActivity time.Time `json:"last_activity,omitzero"`
 }
 
 // ChatFullInfo represents detailed information about a chat
 type ChatFullInfo struct {
 	ChatInfo
-	Description  string `json:"description,omitempty"`
-	MembersCount int    `json:"members_count,omitempty"`
+	Description     string         `json:"description,omitempty"`
+	MembersCount    int            `json:"members_count,omitempty"`
+	PinnedMessageID int            `json:"pinned_message_id,omitempty"`
+	Draft           string         `json:"draft,omitempty"`
+	GroupCall       *GroupCallInfo `json:"group_call,omitempty"`
 }
 
 // ChatsList represents a list of chats
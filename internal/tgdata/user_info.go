@@ -0,0 +1,141 @@
+package tgdata
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gotd/td/tg"
+
+	"github.com/tolmachov/mcp-telegram/internal/tgclient"
+)
+
+// UserFullInfo represents the full profile of a Telegram user
+type UserFullInfo struct {
+	ID        int64  `json:"id"`
+	Name      string `json:"name"`
+	FirstName string `json:"first_name,omitempty"`
+	LastName  string `json:"last_name,omitempty"`
+	Username  string `json:"username,omitempty"`
+	// Usernames lists every active username, collectible ones included
+	Usernames        []string `json:"usernames,omitempty"`
+	Phone            string   `json:"phone,omitempty"` // Empty unless the user shares it with you
+	Bio              string   `json:"bio,omitempty"`
+	Bot              bool     `json:"bot,omitempty"`
+	Verified         bool     `json:"verified,omitempty"`
+	Premium          bool     `json:"premium,omitempty"`
+	Scam             bool     `json:"scam,omitempty"`
+	Fake             bool     `json:"fake,omitempty"`
+	Contact          string   `json:"contact_status"`
+	LastSeen         string   `json:"last_seen,omitempty"`
+	CommonChatsCount int      `json:"common_chats_count"`
+	HasPhoto         bool     `json:"has_photo"`
+	Blocked          bool     `json:"blocked"` // Whether you blocked the user
+}
+
+// GetUserInfo retrieves the full profile of a user.
+func GetUserInfo(ctx context.Context, client *tg.Client, user tg.InputUserClass) (*UserFullInfo, error) {
+	full, err := client.UsersGetFullUser(ctx, user)
+	if err != nil {
+		return nil, fmt.Errorf("getting full user: %w", err)
+	}
+	info, ok := userFullInfo(full, time.Now())
+	if !ok {
+		return nil, fmt.Errorf("user %d missing from response", full.FullUser.ID)
+	}
+	return &info, nil
+}
+
+// userFullInfo maps a users.getFullUser result to UserFullInfo, reporting false when
+// the user itself is missing from the result.
+func userFullInfo(full *tg.UsersUserFull, now time.Time) (UserFullInfo, bool) {
+	var user *tg.User
+	for _, u := range full.Users {
+		if u, ok := u.(*tg.User); ok && u.ID == full.FullUser.ID {
+			user = u
+			break
+		}
+	}
+	if user == nil {
+		return UserFullInfo{}, false
+	}
+
+	info := UserFullInfo{
+		ID:               user.ID,
+		Name:             strings.TrimSpace(user.FirstName + " " + user.LastName),
+		FirstName:        user.FirstName,
+		LastName:         user.LastName,
+		Username:         user.Username,
+		Phone:            user.Phone,
+		Bio:              full.FullUser.About,
+		Bot:              user.Bot,
+		Verified:         user.Verified,
+		Premium:          user.Premium,
+		Scam:             user.Scam,
+		Fake:             user.Fake,
+		Contact:          ContactStatus(user),
+		LastSeen:         LastSeen(user.Status, now),
+		CommonChatsCount: full.FullUser.CommonChatsCount,
+		HasPhoto:         hasPhoto(user, full.FullUser),
+		Blocked:          full.FullUser.Blocked,
+	}
+	if info.Name == "" {
+		info.Name = tgclient.UserName(user)
+	}
+	if user.Username != "" {
+		info.Usernames = append(info.Usernames, user.Username)
+	}
+	for _, u := range user.Usernames {
+		if u.Active && u.Username != user.Username {
+			info.Usernames = append(info.Usernames, u.Username)
+		}
+	}
+	if info.Username == "" && len(info.Usernames) > 0 {
+		info.Username = info.Usernames[0]
+	}
+	return info, true
+}
+
+// hasPhoto reports whether a user has a profile photo visible to you.
+func hasPhoto(user *tg.User, full tg.UserFull) bool {
+	if _, ok := user.Photo.(*tg.UserProfilePhoto); ok {
+		return true
+	}
+	_, ok := full.ProfilePhoto.(*tg.Photo)
+	return ok
+}
+
+// ContactStatus describes whether a user is in my contacts.
+func ContactStatus(user *tg.User) string {
+	switch {
+	case user.Self:
+		return "self"
+	case user.MutualContact:
+		return "mutual_contact"
+	case user.Contact:
+		return "contact"
+	default:
+		return "not_contact"
+	}
+}
+
+// LastSeen describes a user's online status the way Telegram clients show it.
+func LastSeen(status tg.UserStatusClass, now time.Time) string {
+	switch s := status.(type) {
+	case *tg.UserStatusOnline:
+		return "online"
+	case *tg.UserStatusOffline:
+		return "last seen " + now.Sub(time.Unix(int64(s.WasOnline), 0)).Round(time.Minute).String() + " ago"
+	case *tg.UserStatusRecently:
+		return "recently"
+	case *tg.UserStatusLastWeek:
+		return "within a week"
+	case *tg.UserStatusLastMonth:
+		return "within a month"
+	case *tg.UserStatusEmpty:
+		return "long time ago"
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,89 @@
+package tgdata
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/gotd/td/tg"
+)
+
+func TestLastSeen(t *testing.T) {
+	now := time.Date(2024, 1, 15, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		status tg.UserStatusClass
+		want   string
+	}{
+		{name: "online", status: &tg.UserStatusOnline{}, want: "online"},
+		{name: "offline", status: &tg.UserStatusOffline{WasOnline: int(now.Add(-90 * time.Minute).Unix())}, want: "last seen 1h30m0s ago"},
+		{name: "recently", status: &tg.UserStatusRecently{}, want: "recently"},
+		{name: "last week", status: &tg.UserStatusLastWeek{}, want: "within a week"},
+		{name: "last month", status: &tg.UserStatusLastMonth{}, want: "within a month"},
+		{name: "empty", status: &tg.UserStatusEmpty{}, want: "long time ago"},
+		{name: "unknown", status: nil, want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := LastSeen(tt.status, now); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUserFullInfo(t *testing.T) {
+	now := time.Date(2024, 1, 15, 12, 0, 0, 0, time.UTC)
+	full := &tg.UsersUserFull{
+		FullUser: tg.UserFull{ID: 7, About: "Gopher", CommonChatsCount: 3, Blocked: true},
+		Users: []tg.UserClass{
+			&tg.User{ID: 8, FirstName: "Someone else"},
+			&tg.User{
+				ID:        7,
+				FirstName: "Alice",
+				LastName:  "Smith",
+				Phone:     "15551234567",
+				Premium:   true,
+				Contact:   true,
+				Status:    &tg.UserStatusRecently{},
+				Photo:     &tg.UserProfilePhoto{PhotoID: 1},
+				// No plain username: only a collectible one and an inactive one
+				Usernames: []tg.Username{
+					{Username: "alice_nft", Active: true},
+					{Username: "alice_old"},
+				},
+			},
+		},
+	}
+
+	got, ok := userFullInfo(full, now)
+	if !ok {
+		t.Fatal("userFullInfo() didn't find the user")
+	}
+	want := UserFullInfo{
+		ID:               7,
+		Name:             "Alice Smith",
+		FirstName:        "Alice",
+		LastName:         "Smith",
+		Username:         "alice_nft",
+		Usernames:        []string{"alice_nft"},
+		Phone:            "15551234567",
+		Bio:              "Gopher",
+		Premium:          true,
+		Contact:          "contact",
+		LastSeen:         "recently",
+		CommonChatsCount: 3,
+		HasPhoto:         true,
+		Blocked:          true,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("userFullInfo() = %+v, want %+v", got, want)
+	}
+
+	full.Users = full.Users[:1]
+	if _, ok := userFullInfo(full, now); ok {
+		t.Error("userFullInfo() succeeded without the user in the response")
+	}
+}
@@ -0,0 +1,108 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/gotd/td/tg"
+	"github.com/gotd/td/tgerr"
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/tolmachov/mcp-telegram/internal/messages"
+	"github.com/tolmachov/mcp-telegram/internal/tgclient"
+	"github.com/tolmachov/mcp-telegram/internal/tgdata"
+)
+
+const (
+	// defaultAdminLogEvents is how many events GetAdminLog returns by default.
+	defaultAdminLogEvents = 50
+	// maxAdminLogEvents is the most events Telegram returns per request.
+	maxAdminLogEvents = 100
+)
+
+// AdminLogGetHandler handles the GetAdminLog tool
+type AdminLogGetHandler struct {
+	client *tg.Client
+	peers  *tgclient.PeerCache
+}
+
+// NewAdminLogGetHandler creates a new AdminLogGetHandler
+func NewAdminLogGetHandler(client *tg.Client, peers *tgclient.PeerCache) *AdminLogGetHandler {
+	return &AdminLogGetHandler{client: client, peers: peers}
+}
+
+// Tool returns the MCP tool definition
+func (h *AdminLogGetHandler) Tool() mcp.Tool {
+	return mcp.NewTool("GetAdminLog",
+		mcp.WithDescription("Read the admin log of a channel or supergroup from the last 48 hours, newest first: who joined or left, edited or deleted messages with their old and new text, restrictions and bans, and chat info changes, each with its time and the acting user. Only the chat's admins can read it."),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithNumber("chat_id",
+			mcp.Description("The ID of the channel or supergroup"),
+			mcp.Required(),
+		),
+		mcp.WithNumber("limit",
+			mcp.Description(fmt.Sprintf("Maximum number of events (default: %d, max: %d)", defaultAdminLogEvents, maxAdminLogEvents)),
+		),
+		mcp.WithArray("types",
+			mcp.WithStringEnumItems(tgdata.AdminLogTypes),
+			mcp.Description("Only events of these types (default: all events)"),
+		),
+		mcp.WithString("query",
+			mcp.Description("Only events mentioning this text"),
+		),
+	)
+}
+
+// Handle processes the GetAdminLog tool request
+func (h *AdminLogGetHandler) Handle(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	chatID := mcp.ParseInt64(request, "chat_id", 0)
+	if chatID == 0 {
+		return mcp.NewToolResultError("chat_id is required"), nil
+	}
+	limit := mcp.ParseInt(request, "limit", defaultAdminLogEvents)
+	if limit <= 0 || limit > maxAdminLogEvents {
+		limit = maxAdminLogEvents
+	}
+	query := tgdata.AdminLogQuery{
+		Types:  request.GetStringSlice("types", nil),
+		Search: mcp.ParseString(request, "query", ""),
+		Limit:  limit,
+	}
+	if err := query.Validate(); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	var events []tgdata.AdminLogEvent
+	err := h.peers.WithPeer(ctx, h.client, chatID, func(peer tg.InputPeerClass) error {
+		channel, ok := peer.(*tg.InputPeerChannel)
+		if !ok {
+			return errNoAdminLog
+		}
+		var err error
+		events, err = tgdata.GetAdminLog(ctx, h.client, &tg.InputChannel{ChannelID: channel.ChannelID, AccessHash: channel.AccessHash}, query)
+		return err
+	})
+	switch {
+	case errors.Is(err, errNoAdminLog):
+		return mcp.NewToolResultError(fmt.Sprintf("Chat %d has no admin log: only channels and supergroups keep one", chatID)), nil
+	case tgerr.Is(err, "CHAT_ADMIN_REQUIRED"):
+		return mcp.NewToolResultError(fmt.Sprintf("You are not an admin of chat %d: only admins can read its admin log", chatID)), nil
+	case err != nil:
+		return toolError("get admin log", err), nil
+	}
+
+	if len(events) == 0 {
+		return mcp.NewToolResultText(fmt.Sprintf("No admin log events in chat %d for the last 48 hours", chatID)), nil
+	}
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Admin log of chat %d, %d events, newest first:\n\n", chatID, len(events))
+	for _, e := range events {
+		fmt.Fprintf(&sb, "[%s] %s (ID %d) %s\n", e.Date.Format(messages.DateFormat), e.Actor, e.ActorID, e.Description)
+	}
+	return mcp.NewToolResultText(sb.String()), nil
+}
+
+// errNoAdminLog is returned for chats other than channels and supergroups.
+var errNoAdminLog = errors.New("no admin log")
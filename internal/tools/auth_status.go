@@ -0,0 +1,103 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/tolmachov/mcp-telegram/internal/tgclient"
+)
+
+// authStatusTool is the name of the tool that works without a Telegram connection.
+const authStatusTool = "GetAuthStatus"
+
+// loginHint tells the user how to authorize the session.
+const loginHint = "Run 'mcp-telegram login --phone <number>' in a terminal, then restart the MCP server."
+
+// AuthStatusHandler handles the GetAuthStatus tool
+type AuthStatusHandler struct {
+	status *tgclient.Status
+}
+
+// NewAuthStatusHandler creates a new AuthStatusHandler
+func NewAuthStatusHandler(status *tgclient.Status) *AuthStatusHandler {
+	return &AuthStatusHandler{status: status}
+}
+
+// Tool returns the MCP tool definition
+func (h *AuthStatusHandler) Tool() mcp.Tool {
+	return mcp.NewTool(authStatusTool,
+		mcp.WithDescription("Report whether the server is connected and logged in to Telegram, and as whom. Works before the connection is ready; other tools wait for it."),
+		mcp.WithReadOnlyHintAnnotation(true),
+	)
+}
+
+// authStatus is the GetAuthStatus result.
+type authStatus struct {
+	State      tgclient.ConnState `json:"state"`
+	Authorized bool               `json:"authorized"`
+	UserID     int64              `json:"user_id,omitempty"`
+	Username   string             `json:"username,omitempty"`
+	Name       string             `json:"name,omitempty"`
+	Error      string             `json:"error,omitempty"`
+	Hint       string             `json:"hint,omitempty"`
+}
+
+// Handle processes the GetAuthStatus tool request
+func (h *AuthStatusHandler) Handle(_ context.Context, _ mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	state, self, err := h.status.Get()
+	result := authStatus{State: state, Authorized: state == tgclient.ConnAuthorized}
+	if self != nil {
+		result.UserID = self.ID
+		result.Username = self.Username
+		result.Name = strings.TrimSpace(self.FirstName + " " + self.LastName)
+	}
+	if err != nil {
+		result.Error = err.Error()
+	}
+	if state == tgclient.ConnUnauthorized {
+		result.Hint = loginHint
+	}
+	return jsonResult(result)
+}
+
+// connectionError is the structured error of a tool called without a usable
+// Telegram connection.
+type connectionError struct {
+	Error   string             `json:"error"`
+	State   tgclient.ConnState `json:"state"`
+	Message string             `json:"message"`
+	Hint    string             `json:"hint,omitempty"`
+}
+
+// ConnectionMiddleware makes tool calls wait for the Telegram connection made in
+// the background, and answers them with a structured error when the session isn't
+// authorized or the connection failed. GetAuthStatus runs regardless.
+func ConnectionMiddleware(status *tgclient.Status) server.ToolHandlerMiddleware {
+	return func(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+		return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			if request.Params.Name == authStatusTool {
+				return next(ctx, request)
+			}
+			err := status.Wait(ctx)
+			if err == nil {
+				return next(ctx, request)
+			}
+
+			state, _, _ := status.Get()
+			result := connectionError{Error: "not_connected", State: state, Message: "Telegram is not connected: " + err.Error()}
+			if errors.Is(err, tgclient.ErrNotAuthorized) {
+				result = connectionError{Error: "not_authorized", State: state, Message: "The Telegram session is not logged in.", Hint: loginHint}
+			}
+			data, marshalErr := json.MarshalIndent(result, "", "  ")
+			if marshalErr != nil {
+				return toolError("marshal connection error", marshalErr), nil
+			}
+			return mcp.NewToolResultError(string(data)), nil
+		}
+	}
+}
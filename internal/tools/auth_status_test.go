@@ -0,0 +1,94 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/gotd/td/tg"
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/tolmachov/mcp-telegram/internal/tgclient"
+)
+
+func callWithStatus(t *testing.T, status *tgclient.Status, tool string) (*mcp.CallToolResult, bool) {
+	t.Helper()
+	called := false
+	next := func(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		called = true
+		return mcp.NewToolResultText("ok"), nil
+	}
+	var request mcp.CallToolRequest
+	request.Params.Name = tool
+	result, err := ConnectionMiddleware(status)(next)(context.Background(), request)
+	if err != nil {
+		t.Fatalf("middleware: %v", err)
+	}
+	return result, called
+}
+
+func TestConnectionMiddleware(t *testing.T) {
+	status := tgclient.NewStatus()
+	status.SetUnauthorized()
+
+	result, called := callWithStatus(t, status, "GetMe")
+	if called || !result.IsError {
+		t.Fatalf("GetMe ran without authorization")
+	}
+	var got connectionError
+	if err := json.Unmarshal([]byte(resultText(result)), &got); err != nil {
+		t.Fatalf("error isn't structured: %v", err)
+	}
+	if got.Error != "not_authorized" || got.State != tgclient.ConnUnauthorized || got.Hint != loginHint {
+		t.Errorf("error = %+v, want not_authorized with the login hint", got)
+	}
+
+	if _, called := callWithStatus(t, status, authStatusTool); !called {
+		t.Error("GetAuthStatus didn't run without authorization")
+	}
+
+	status.SetFailed(errors.New("dial tcp: connection refused"))
+	result, _ = callWithStatus(t, status, "GetMe")
+	if err := json.Unmarshal([]byte(resultText(result)), &got); err != nil || got.Error != "not_connected" {
+		t.Errorf("error = %s, want not_connected", resultText(result))
+	}
+
+	status.SetAuthorized(&tg.User{ID: 1})
+	if _, called := callWithStatus(t, status, "GetMe"); !called {
+		t.Error("GetMe didn't run once authorized")
+	}
+}
+
+func TestAuthStatusHandler(t *testing.T) {
+	status := tgclient.NewStatus()
+	h := NewAuthStatusHandler(status)
+
+	decode := func() authStatus {
+		t.Helper()
+		result, err := h.Handle(context.Background(), mcp.CallToolRequest{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got authStatus
+		if err := json.Unmarshal([]byte(resultText(result)), &got); err != nil {
+			t.Fatal(err)
+		}
+		return got
+	}
+
+	if got := decode(); got.State != tgclient.ConnConnecting || got.Authorized {
+		t.Errorf("while connecting = %+v", got)
+	}
+
+	status.SetUnauthorized()
+	if got := decode(); got.Authorized || got.Hint != loginHint {
+		t.Errorf("unauthorized = %+v, want the login hint", got)
+	}
+
+	status.SetAuthorized(&tg.User{ID: 42, Username: "alice", FirstName: "Alice", LastName: "Smith"})
+	want := authStatus{State: tgclient.ConnAuthorized, Authorized: true, UserID: 42, Username: "alice", Name: "Alice Smith"}
+	if got := decode(); got != want {
+		t.Errorf("authorized = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,234 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gotd/td/tg"
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/tolmachov/mcp-telegram/internal/autoreply"
+	"github.com/tolmachov/mcp-telegram/internal/messages"
+)
+
+// AutoReplySetHandler handles the SetAutoReply tool
+type AutoReplySetHandler struct {
+	store *autoreply.Store
+}
+
+// NewAutoReplySetHandler creates a new AutoReplySetHandler
+func NewAutoReplySetHandler(store *autoreply.Store) *AutoReplySetHandler {
+	return &AutoReplySetHandler{store: store}
+}
+
+// Tool returns the MCP tool definition
+func (h *AutoReplySetHandler) Tool() mcp.Tool {
+	return mcp.NewTool("SetAutoReply",
+		mcp.WithDescription("Turn on an away auto-reply: incoming private messages from people (not bots) are answered with the given text, at most once per sender per cooldown. Replies are only sent while this server is running. Calling it again replaces the settings."),
+		mcp.WithOpenWorldHintAnnotation(true),
+		mcp.WithString("message",
+			mcp.Description("The auto-reply text"),
+			mcp.Required(),
+		),
+		mcp.WithArray("whitelist",
+			mcp.WithNumberItems(),
+			mcp.Description("Only reply to these user IDs (optional, default: everyone)"),
+		),
+		mcp.WithArray("blacklist",
+			mcp.WithNumberItems(),
+			mcp.Description("Never reply to these user IDs (optional)"),
+		),
+		mcp.WithString("expires_at",
+			mcp.Description("Stop auto-replying at this time (optional, format: YYYY-MM-DD or YYYY-MM-DD HH:MM:SS, local time; a date alone means the start of that day)"),
+		),
+		mcp.WithNumber("cooldown_hours",
+			mcp.Description(fmt.Sprintf("Minimum hours between two auto-replies to the same person (optional, default: %d)", int(autoreply.DefaultCooldown.Hours()))),
+		),
+	)
+}
+
+// Handle processes the SetAutoReply tool request
+func (h *AutoReplySetHandler) Handle(_ context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	message := mcp.ParseString(request, "message", "")
+	if message == "" {
+		return mcp.NewToolResultError("message is required"), nil
+	}
+
+	expiresAt, _, err := parseDate(mcp.ParseString(request, "expires_at", ""))
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if !expiresAt.IsZero() && !expiresAt.After(time.Now()) {
+		return mcp.NewToolResultError("expires_at must be in the future"), nil
+	}
+
+	cooldownHours := mcp.ParseFloat64(request, "cooldown_hours", autoreply.DefaultCooldown.Hours())
+	if cooldownHours <= 0 {
+		return mcp.NewToolResultError("cooldown_hours must be positive"), nil
+	}
+
+	settings := autoreply.Settings{
+		Message:   message,
+		Whitelist: int64Slice(request.GetIntSlice("whitelist", nil)),
+		Blacklist: int64Slice(request.GetIntSlice("blacklist", nil)),
+		ExpiresAt: expiresAt,
+		Cooldown:  time.Duration(cooldownHours * float64(time.Hour)),
+	}
+	if err := h.store.Enable(settings); err != nil {
+		return toolError("save auto-reply settings", err), nil
+	}
+
+	result := fmt.Sprintf("Auto-reply enabled.\nCooldown per sender: %s", settings.Cooldown)
+	if !expiresAt.IsZero() {
+		result += fmt.Sprintf("\nExpires: %s", expiresAt.Format(time.RFC3339))
+	}
+	if len(settings.Whitelist) > 0 {
+		result += fmt.Sprintf("\nOnly replying to %d whitelisted user(s)", len(settings.Whitelist))
+	}
+	if len(settings.Blacklist) > 0 {
+		result += fmt.Sprintf("\nNever replying to %d blacklisted user(s)", len(settings.Blacklist))
+	}
+	result += fmt.Sprintf("\nText: %s", messages.TruncateRunes(message, sentTextSnippetRunes))
+	return mcp.NewToolResultText(result), nil
+}
+
+// AutoReplyStatusHandler handles the GetAutoReplyStatus tool
+type AutoReplyStatusHandler struct {
+	store *autoreply.Store
+}
+
+// NewAutoReplyStatusHandler creates a new AutoReplyStatusHandler
+func NewAutoReplyStatusHandler(store *autoreply.Store) *AutoReplyStatusHandler {
+	return &AutoReplyStatusHandler{store: store}
+}
+
+// Tool returns the MCP tool definition
+func (h *AutoReplyStatusHandler) Tool() mcp.Tool {
+	return mcp.NewTool("GetAutoReplyStatus",
+		mcp.WithDescription("Show whether the away auto-reply is on, its settings, and who got an auto-reply within the cooldown."),
+		mcp.WithReadOnlyHintAnnotation(true),
+	)
+}
+
+// Handle processes the GetAutoReplyStatus tool request
+func (h *AutoReplyStatusHandler) Handle(_ context.Context, _ mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	data, err := json.MarshalIndent(h.store.Status(time.Now()), "", "  ")
+	if err != nil {
+		return toolError("marshal auto-reply status", err), nil
+	}
+	return mcp.NewToolResultText(string(data)), nil
+}
+
+// AutoReplyDisableHandler handles the DisableAutoReply tool
+type AutoReplyDisableHandler struct {
+	store *autoreply.Store
+}
+
+// NewAutoReplyDisableHandler creates a new AutoReplyDisableHandler
+func NewAutoReplyDisableHandler(store *autoreply.Store) *AutoReplyDisableHandler {
+	return &AutoReplyDisableHandler{store: store}
+}
+
+// Tool returns the MCP tool definition
+func (h *AutoReplyDisableHandler) Tool() mcp.Tool {
+	return mcp.NewTool("DisableAutoReply",
+		mcp.WithDescription("Turn off the away auto-reply."),
+		mcp.WithIdempotentHintAnnotation(true),
+	)
+}
+
+// Handle processes the DisableAutoReply tool request
+func (h *AutoReplyDisableHandler) Handle(_ context.Context, _ mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	if err := h.store.Disable(); err != nil {
+		return toolError("disable auto-reply", err), nil
+	}
+	return mcp.NewToolResultText("Auto-reply disabled."), nil
+}
+
+// AutoReplyUpdateHandler returns an update handler that answers incoming private
+// messages from people according to the store. Replies go through the provider's
+// rate limiter and the regular send path.
+func AutoReplyUpdateHandler(provider *messages.Provider, store *autoreply.Store) tg.NewMessageHandler {
+	return func(ctx context.Context, e tg.Entities, update *tg.UpdateNewMessage) error {
+		userID, ok := autoReplySender(update)
+		if !ok {
+			return nil
+		}
+		client := provider.Paced()
+		user, err := autoReplyUser(ctx, client, e, userID)
+		if err != nil {
+			return fmt.Errorf("getting auto-reply sender %d: %w", userID, err)
+		}
+		if !autoReplyRecipient(user) {
+			return nil
+		}
+
+		now := time.Now()
+		text, ok, err := store.Claim(userID, now)
+		if err != nil || !ok {
+			return err
+		}
+
+		// A first-time sender has no dialog to resolve them from, so the reply uses
+		// the access hash the update came with
+		provider.Peers().Remember(userID, &tg.InputPeerUser{UserID: userID, AccessHash: user.AccessHash})
+		if _, err := sendText(ctx, client, provider.Peers(), userID, text); err != nil {
+			_ = store.Release(userID, now)
+			return fmt.Errorf("sending auto-reply to %d: %w", userID, err)
+		}
+		return nil
+	}
+}
+
+// autoReplySender returns the sender of an incoming private message, or false for
+// outgoing messages, service messages, groups and the like.
+func autoReplySender(update *tg.UpdateNewMessage) (int64, bool) {
+	msg, ok := update.Message.(*tg.Message)
+	if !ok || msg.Out {
+		return 0, false
+	}
+	peer, ok := msg.PeerID.(*tg.PeerUser)
+	if !ok {
+		return 0, false
+	}
+	return peer.UserID, true
+}
+
+// autoReplyRecipient reports whether user is a person who may get an auto-reply,
+// rather than a bot, the account itself, Telegram support or a deleted account.
+func autoReplyRecipient(user *tg.User) bool {
+	return !user.Bot && !user.Self && !user.Support && !user.Deleted
+}
+
+// autoReplyUser returns the sender of a private message from the update's entities,
+// or looks them up when the update came without, as short updates do.
+func autoReplyUser(ctx context.Context, client *tg.Client, e tg.Entities, userID int64) (*tg.User, error) {
+	if user, ok := e.Users[userID]; ok {
+		return user, nil
+	}
+	users, err := client.UsersGetUsers(ctx, []tg.InputUserClass{&tg.InputUser{UserID: userID}})
+	if err != nil {
+		return nil, err
+	}
+	for _, u := range users {
+		if user, ok := u.(*tg.User); ok && user.ID == userID {
+			return user, nil
+		}
+	}
+	return nil, errors.New("no such user")
+}
+
+// int64Slice converts tool integer arguments to IDs.
+func int64Slice(values []int) []int64 {
+	if len(values) == 0 {
+		return nil
+	}
+	ids := make([]int64, len(values))
+	for i, v := range values {
+		ids[i] = int64(v)
+	}
+	return ids
+}
@@ -0,0 +1,117 @@
+package tools
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/gotd/td/bin"
+	"github.com/gotd/td/tg"
+
+	"github.com/tolmachov/mcp-telegram/internal/autoreply"
+	"github.com/tolmachov/mcp-telegram/internal/messages"
+	"github.com/tolmachov/mcp-telegram/internal/tgclient"
+)
+
+func TestAutoReplySender(t *testing.T) {
+	incoming := func(peer tg.PeerClass) *tg.UpdateNewMessage {
+		return &tg.UpdateNewMessage{Message: &tg.Message{ID: 10, PeerID: peer, Message: "hi"}}
+	}
+
+	tests := []struct {
+		name   string
+		update *tg.UpdateNewMessage
+		wantID int64
+		wantOK bool
+	}{
+		{"private message", incoming(&tg.PeerUser{UserID: 1}), 1, true},
+		{"outgoing message", &tg.UpdateNewMessage{Message: &tg.Message{Out: true, PeerID: &tg.PeerUser{UserID: 1}}}, 0, false},
+		{"group", incoming(&tg.PeerChat{ChatID: 5}), 0, false},
+		{"service message", &tg.UpdateNewMessage{Message: &tg.MessageService{PeerID: &tg.PeerUser{UserID: 1}}}, 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			id, ok := autoReplySender(tt.update)
+			if id != tt.wantID || ok != tt.wantOK {
+				t.Errorf("autoReplySender() = %d, %v, want %d, %v", id, ok, tt.wantID, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestAutoReplyRecipient(t *testing.T) {
+	tests := []struct {
+		name string
+		user *tg.User
+		want bool
+	}{
+		{"person", &tg.User{ID: 1, FirstName: "Anna"}, true},
+		{"bot", &tg.User{ID: 2, Bot: true}, false},
+		{"self", &tg.User{ID: 3, Self: true}, false},
+		{"support", &tg.User{ID: 4, Support: true}, false},
+		{"deleted", &tg.User{ID: 5, Deleted: true}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := autoReplyRecipient(tt.user); got != tt.want {
+				t.Errorf("autoReplyRecipient() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAutoReplyUpdateHandler(t *testing.T) {
+	update := &tg.UpdateNewMessage{Message: &tg.Message{ID: 10, PeerID: &tg.PeerUser{UserID: 1}, Message: "hi"}}
+	tests := []struct {
+		name         string
+		entities     tg.Entities
+		wantRequests []uint32
+	}{
+		{
+			name:         "sender in the entities",
+			entities:     tg.Entities{Users: map[int64]*tg.User{1: {ID: 1, AccessHash: 77, FirstName: "Anna"}}},
+			wantRequests: []uint32{tg.MessagesSendMessageRequestTypeID},
+		},
+		{
+			name:         "sender looked up for a short update",
+			wantRequests: []uint32{tg.UsersGetUsersRequestTypeID, tg.MessagesSendMessageRequestTypeID},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store, err := autoreply.OpenStore(filepath.Join(t.TempDir(), "autoreply.json"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := store.Enable(autoreply.Settings{Message: "Away until Monday"}); err != nil {
+				t.Fatal(err)
+			}
+			inv := &recordingInvoker{responses: map[uint32]bin.Encoder{
+				tg.UsersGetUsersRequestTypeID:       &tg.UserClassVector{Elems: []tg.UserClass{&tg.User{ID: 1, AccessHash: 77, FirstName: "Anna"}}},
+				tg.MessagesSendMessageRequestTypeID: &tg.Updates{},
+			}}
+			provider := messages.NewProvider(tg.NewClient(inv), tgclient.NewPeerCache(10), tgclient.NewLimiter(0))
+
+			if err := AutoReplyUpdateHandler(provider, store)(t.Context(), tt.entities, update); err != nil {
+				t.Fatalf("handler error = %v", err)
+			}
+			if len(inv.requests) != len(tt.wantRequests) {
+				t.Fatalf("requests = %v, want %d", inv.requests, len(tt.wantRequests))
+			}
+			for i, req := range inv.requests {
+				if id := req.(interface{ TypeID() uint32 }).TypeID(); id != tt.wantRequests[i] {
+					t.Errorf("request %d = %T", i, req)
+				}
+			}
+			send := inv.requests[len(inv.requests)-1].(*tg.MessagesSendMessageRequest)
+			if peer, ok := send.Peer.(*tg.InputPeerUser); !ok || peer.UserID != 1 || peer.AccessHash != 77 {
+				t.Errorf("reply peer = %v, want user 1 with the update's access hash", send.Peer)
+			}
+			if send.Message != "Away until Monday" {
+				t.Errorf("reply = %q", send.Message)
+			}
+		})
+	}
+}
@@ -0,0 +1,150 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// BackupCleanupHandler handles the CleanupBackups tool
+type BackupCleanupHandler struct {
+	allowedPaths    []string
+	maxFilesPerChat int
+}
+
+// NewBackupCleanupHandler creates a new BackupCleanupHandler.
+// maxFilesPerChat is the default number of backups kept per chat (0 = no default).
+func NewBackupCleanupHandler(allowedPaths []string, maxFilesPerChat int) *BackupCleanupHandler {
+	return &BackupCleanupHandler{
+		allowedPaths:    allowedPaths,
+		maxFilesPerChat: maxFilesPerChat,
+	}
+}
+
+// Tool returns the MCP tool definition
+func (h *BackupCleanupHandler) Tool() mcp.Tool {
+	return mcp.NewTool("CleanupBackups",
+		mcp.WithDescription("Delete old auto-named backups created by BackupMessages, keeping the newest ones per chat. Only files matching the auto-generated name pattern directly inside the allowed directories are considered; backups saved to a custom filepath are never touched. Runs as a dry run by default - set dry_run to false to delete."),
+		mcp.WithDestructiveHintAnnotation(true),
+		mcp.WithNumber("chat_id",
+			mcp.Description("Only clean up backups of this chat (optional, default: all chats)"),
+		),
+		mcp.WithNumber("keep",
+			mcp.Description("Number of newest backups to keep per chat (optional, default: the configured max backup files per chat)"),
+		),
+		mcp.WithBoolean("dry_run",
+			mcp.Description("Only report what would be deleted (default: true)"),
+		),
+	)
+}
+
+// Handle processes the CleanupBackups tool request
+func (h *BackupCleanupHandler) Handle(_ context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	chatID := mcp.ParseInt64(request, "chat_id", 0)
+	keep := mcp.ParseInt(request, "keep", h.maxFilesPerChat)
+	dryRun := mcp.ParseBoolean(request, "dry_run", true)
+
+	if keep <= 0 {
+		return mcp.NewToolResultError("keep must be a positive number (no max backup files per chat is configured)"), nil
+	}
+	if len(h.allowedPaths) == 0 {
+		return mcp.NewToolResultError("no allowed paths configured for backup"), nil
+	}
+
+	var files []backupFile
+	for _, dir := range uniqueDirs(h.allowedPaths) {
+		found, err := listBackups(dir)
+		if err != nil {
+			return toolError("list backups", err), nil
+		}
+		files = append(files, found...)
+	}
+
+	prune := planBackupCleanup(files, chatID, keep)
+	if len(prune) == 0 {
+		return mcp.NewToolResultText(fmt.Sprintf("Nothing to clean up (%d auto-named backups found, keeping %d per chat)", len(files), keep)), nil
+	}
+
+	var sb strings.Builder
+	if dryRun {
+		fmt.Fprintf(&sb, "Dry run: %d backup(s) would be deleted (keeping %d per chat):\n", len(prune), keep)
+		for _, f := range prune {
+			fmt.Fprintf(&sb, "- %s\n", f.Path)
+		}
+		sb.WriteString("\nCall again with dry_run=false to delete them.")
+		return mcp.NewToolResultText(sb.String()), nil
+	}
+
+	var removed int
+	var failed []string
+	for _, f := range prune {
+		if err := os.Remove(f.Path); err != nil {
+			failed = append(failed, fmt.Sprintf("%s: %v", f.Path, err))
+			continue
+		}
+		removed++
+		fmt.Fprintf(&sb, "- %s\n", f.Path)
+	}
+
+	result := fmt.Sprintf("Deleted %d backup(s) (keeping %d per chat):\n%s", removed, keep, sb.String())
+	if len(failed) > 0 {
+		result += fmt.Sprintf("\nFailed to delete %d file(s):\n- %s", len(failed), strings.Join(failed, "\n- "))
+	}
+	return mcp.NewToolResultText(result), nil
+}
+
+// planBackupCleanup returns the backups to delete so that at most keep remain per chat,
+// counting the backups of each forum topic apart. A non-zero chatID restricts the cleanup
+// to that chat. Files are grouped by chat ID and topic and listed oldest first within each.
+func planBackupCleanup(files []backupFile, chatID int64, keep int) []backupFile {
+	type group struct {
+		chatID  int64
+		topicID int
+	}
+	var groups []group
+	seen := make(map[group]bool)
+	for _, f := range files {
+		if chatID != 0 && f.ChatID != chatID {
+			continue
+		}
+		g := group{f.ChatID, f.TopicID}
+		if !seen[g] {
+			seen[g] = true
+			groups = append(groups, g)
+		}
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		if groups[i].chatID != groups[j].chatID {
+			return groups[i].chatID < groups[j].chatID
+		}
+		return groups[i].topicID < groups[j].topicID
+	})
+
+	var prune []backupFile
+	for _, g := range groups {
+		prune = append(prune, backupsToPrune(files, g.chatID, g.topicID, keep)...)
+	}
+	return prune
+}
+
+// uniqueDirs returns the cleaned, de-duplicated directories in their original order.
+func uniqueDirs(dirs []string) []string {
+	seen := make(map[string]bool, len(dirs))
+	var result []string
+	for _, dir := range dirs {
+		abs, err := filepath.Abs(dir)
+		if err != nil {
+			continue
+		}
+		if !seen[abs] {
+			seen[abs] = true
+			result = append(result, abs)
+		}
+	}
+	return result
+}
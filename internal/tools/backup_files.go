@@ -0,0 +1,180 @@
+package tools
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tolmachov/mcp-telegram/internal/messages"
+)
+
+// backupTimeLayout is the timestamp layout used in auto-generated backup filenames.
+const backupTimeLayout = "2006-01-02_15-04-05"
+
+// backupChatIDMarker separates the timestamp from the chat ID in auto-generated backup filenames.
+const backupChatIDMarker = "-id"
+
+// backupTopicIDMarker separates the chat ID from the forum topic ID in the
+// auto-generated filenames of topic backups.
+const backupTopicIDMarker = "-t"
+
+// partialBackupSuffix is appended to the name of a backup while it is written, and
+// stays on one that failed or was cancelled, so it never replaces an earlier backup
+// at its path nor is taken for a complete backup of its chat when pruning.
+const partialBackupSuffix = ".partial"
+
+// backupFile is an auto-generated backup found on disk.
+type backupFile struct {
+	Path    string
+	ChatID  int64
+	TopicID int // Forum topic backed up alone, 0 for the whole chat
+	Time    time.Time
+}
+
+// backupFilename builds the auto-generated backup filename for a chat:
+// "<chat name>-<YYYY-MM-DD_HH-MM-SS>-id<chat ID>.<ext>", with the extension of the
+// backup format, or "...-id<chat ID>-t<topic ID>.<ext>" for a forum topic. The IDs let
+// pruning attribute files to a chat or topic even after it is renamed.
+func backupFilename(chatName string, chatID int64, topicID int, t time.Time, format string) string {
+	var topic string
+	if topicID != 0 {
+		topic = backupTopicIDMarker + strconv.Itoa(topicID)
+	}
+	return fmt.Sprintf("%s-%s%s%d%s%s", sanitizeFilename(chatName), t.Format(backupTimeLayout), backupChatIDMarker, chatID, topic, messages.BackupExtension(format))
+}
+
+// parseBackupFilename reports whether name was produced by backupFilename and, if so,
+// returns its chat ID, topic ID and timestamp. Anything that deviates from the template
+// is rejected, so user-named files are never mistaken for auto-generated backups.
+func parseBackupFilename(name string) (chatID int64, topicID int, t time.Time, ok bool) {
+	var base string
+	found := false
+	for _, format := range messages.BackupFormats {
+		if base, found = strings.CutSuffix(name, messages.BackupExtension(format)); found {
+			break
+		}
+	}
+	if !found {
+		return 0, 0, time.Time{}, false
+	}
+
+	idx := strings.LastIndex(base, backupChatIDMarker)
+	if idx < 0 {
+		return 0, 0, time.Time{}, false
+	}
+	idStr := base[idx+len(backupChatIDMarker):]
+	if chatStr, topicStr, isTopic := strings.Cut(idStr, backupTopicIDMarker); isTopic {
+		id, err := strconv.Atoi(topicStr)
+		if err != nil || id <= 0 || strconv.Itoa(id) != topicStr {
+			return 0, 0, time.Time{}, false
+		}
+		idStr, topicID = chatStr, id
+	}
+	chatID, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil || chatID == 0 || strconv.FormatInt(chatID, 10) != idStr {
+		return 0, 0, time.Time{}, false
+	}
+
+	// What remains is "<chat name>-<timestamp>" with a non-empty chat name
+	rest := base[:idx]
+	if len(rest) < len(backupTimeLayout)+2 {
+		return 0, 0, time.Time{}, false
+	}
+	tsStart := len(rest) - len(backupTimeLayout)
+	if rest[tsStart-1] != '-' {
+		return 0, 0, time.Time{}, false
+	}
+	t, err = time.ParseInLocation(backupTimeLayout, rest[tsStart:], time.Local)
+	if err != nil {
+		return 0, 0, time.Time{}, false
+	}
+	return chatID, topicID, t, true
+}
+
+// listBackups returns the auto-generated backups directly inside dir.
+// Subdirectories, symlinks and files not matching the template are skipped.
+// A missing directory yields no backups.
+func listBackups(dir string) ([]backupFile, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("reading backup directory: %w", err)
+	}
+
+	var files []backupFile
+	for _, entry := range entries {
+		if !entry.Type().IsRegular() {
+			continue
+		}
+		chatID, topicID, t, ok := parseBackupFilename(entry.Name())
+		if !ok {
+			continue
+		}
+		files = append(files, backupFile{
+			Path:    filepath.Join(dir, entry.Name()),
+			ChatID:  chatID,
+			TopicID: topicID,
+			Time:    t,
+		})
+	}
+	return files, nil
+}
+
+// backupsToPrune returns the backups of chatID that exceed keep, oldest first. A forum
+// topic's backups are kept apart from those of the whole chat and other topics: topicID
+// selects them, 0 the whole chat's. The newest keep backups (by filename timestamp) are
+// retained. keep <= 0 prunes nothing.
+func backupsToPrune(files []backupFile, chatID int64, topicID int, keep int) []backupFile {
+	if keep <= 0 {
+		return nil
+	}
+
+	var chatFiles []backupFile
+	for _, f := range files {
+		if f.ChatID == chatID && f.TopicID == topicID {
+			chatFiles = append(chatFiles, f)
+		}
+	}
+	if len(chatFiles) <= keep {
+		return nil
+	}
+
+	// Newest first; equal timestamps are ordered by path for a stable result
+	sort.Slice(chatFiles, func(i, j int) bool {
+		if !chatFiles[i].Time.Equal(chatFiles[j].Time) {
+			return chatFiles[i].Time.After(chatFiles[j].Time)
+		}
+		return chatFiles[i].Path > chatFiles[j].Path
+	})
+
+	prune := chatFiles[keep:]
+	// Report oldest first
+	for i, j := 0, len(prune)-1; i < j; i, j = i+1, j-1 {
+		prune[i], prune[j] = prune[j], prune[i]
+	}
+	return prune
+}
+
+// pruneBackups deletes the backups of chatID, or of its forum topic topicID, in dir
+// beyond keep and returns the removed paths.
+func pruneBackups(dir string, chatID int64, topicID int, keep int) ([]string, error) {
+	files, err := listBackups(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var removed []string
+	for _, f := range backupsToPrune(files, chatID, topicID, keep) {
+		if err := os.Remove(f.Path); err != nil {
+			return removed, fmt.Errorf("removing %s: %w", f.Path, err)
+		}
+		removed = append(removed, f.Path)
+	}
+	return removed, nil
+}
@@ -0,0 +1,284 @@
+package tools
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/gotd/td/bin"
+	"github.com/gotd/td/tg"
+
+	"github.com/tolmachov/mcp-telegram/internal/messages"
+	"github.com/tolmachov/mcp-telegram/internal/tgclient"
+)
+
+func TestBackupFilenameRoundTrip(t *testing.T) {
+	ts := time.Date(2024, 1, 15, 10, 30, 0, 0, time.Local)
+	tests := []struct {
+		chatName string
+		chatID   int64
+		topicID  int
+	}{
+		{"Family", 123, 0},
+		{"Channel", -1001234567890, 0},
+		{"Group-2024-01-01_00-00-00-id5", -42, 0},
+		{"a/b:c", 7, 0},
+		{"", 99, 0},
+		{"Forum-Releases", -1001234567890, 15},
+		{"Forum-id-t3", -1001234567890, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.chatName, func(t *testing.T) {
+			for _, format := range messages.BackupFormats {
+				name := backupFilename(tt.chatName, tt.chatID, tt.topicID, ts, format)
+				chatID, topicID, got, ok := parseBackupFilename(name)
+				if !ok {
+					t.Fatalf("parseBackupFilename(%q) did not match", name)
+				}
+				if chatID != tt.chatID || topicID != tt.topicID {
+					t.Errorf("chatID, topicID = %d, %d, want %d, %d", chatID, topicID, tt.chatID, tt.topicID)
+				}
+				if !got.Equal(ts) {
+					t.Errorf("time = %v, want %v", got, ts)
+				}
+			}
+		})
+	}
+}
+
+func TestParseBackupFilenameRejects(t *testing.T) {
+	tests := []struct {
+		name     string
+		filename string
+	}{
+		{"legacy name without chat ID", "Family-2024-01-15_10-30-00.txt"},
+		{"user file", "notes.txt"},
+		{"wrong extension", "Family-2024-01-15_10-30-00-id123.txt.bak"},
+		{"partial backup", "Family-2024-01-15_10-30-00-id123.txt" + partialBackupSuffix},
+		{"no extension", "Family-2024-01-15_10-30-00-id123"},
+		{"other extension", "Family-2024-01-15_10-30-00-id123.csv"},
+		{"missing chat name", "-2024-01-15_10-30-00-id123.txt"},
+		{"only timestamp", "2024-01-15_10-30-00-id123.txt"},
+		{"missing dash before timestamp", "Family2024-01-15_10-30-00-id123.txt"},
+		{"invalid timestamp", "Family-2024-13-45_10-30-00-id123.txt"},
+		{"date-only timestamp", "Family-2024-01-15-id123.txt"},
+		{"non-numeric chat ID", "Family-2024-01-15_10-30-00-idabc.txt"},
+		{"zero chat ID", "Family-2024-01-15_10-30-00-id0.txt"},
+		{"padded chat ID", "Family-2024-01-15_10-30-00-id0123.txt"},
+		{"plus-signed chat ID", "Family-2024-01-15_10-30-00-id+123.txt"},
+		{"empty chat ID", "Family-2024-01-15_10-30-00-id.txt"},
+		{"suffix after chat ID", "Family-2024-01-15_10-30-00-id123 (copy).txt"},
+		{"zero topic ID", "Forum-2024-01-15_10-30-00-id-100123-t0.txt"},
+		{"empty topic ID", "Forum-2024-01-15_10-30-00-id-100123-t.txt"},
+		{"negative topic ID", "Forum-2024-01-15_10-30-00-id-100123-t-5.txt"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if chatID, _, ts, ok := parseBackupFilename(tt.filename); ok {
+				t.Errorf("parseBackupFilename(%q) matched: chatID=%d time=%v", tt.filename, chatID, ts)
+			}
+		})
+	}
+}
+
+func TestBackupsToPrune(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2024, 1, d, 0, 0, 0, 0, time.Local) }
+	files := []backupFile{
+		{Path: "c", ChatID: 1, Time: day(3)},
+		{Path: "a", ChatID: 1, Time: day(1)},
+		{Path: "other", ChatID: 2, Time: day(1)},
+		{Path: "d", ChatID: 1, Time: day(4)},
+		{Path: "b", ChatID: 1, Time: day(2)},
+	}
+
+	tests := []struct {
+		name   string
+		chatID int64
+		keep   int
+		want   []string
+	}{
+		{"keeps newest, prunes oldest first", 1, 2, []string{"a", "b"}},
+		{"keep one", 1, 1, []string{"a", "b", "c"}},
+		{"under cap", 1, 4, nil},
+		{"above count", 1, 10, nil},
+		{"zero keep prunes nothing", 1, 0, nil},
+		{"negative keep prunes nothing", 1, -1, nil},
+		{"other chat untouched", 2, 1, nil},
+		{"unknown chat", 3, 1, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, f := range backupsToPrune(files, tt.chatID, 0, tt.keep) {
+				got = append(got, f.Path)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBackupsToPruneEqualTimestamps(t *testing.T) {
+	ts := time.Date(2024, 1, 1, 0, 0, 0, 0, time.Local)
+	files := []backupFile{
+		{Path: "b", ChatID: 1, Time: ts},
+		{Path: "a", ChatID: 1, Time: ts},
+		{Path: "c", ChatID: 1, Time: ts},
+	}
+	var got []string
+	for _, f := range backupsToPrune(files, 1, 0, 1) {
+		got = append(got, f.Path)
+	}
+	if want := []string{"a", "b"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestPlanBackupCleanup(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2024, 1, d, 0, 0, 0, 0, time.Local) }
+	files := []backupFile{
+		{Path: "b2", ChatID: -100, Time: day(2)},
+		{Path: "a1", ChatID: 5, Time: day(1)},
+		{Path: "b1", ChatID: -100, Time: day(1)},
+		{Path: "a2", ChatID: 5, Time: day(2)},
+		{Path: "c1", ChatID: 9, Time: day(1)},
+	}
+
+	paths := func(fs []backupFile) []string {
+		var out []string
+		for _, f := range fs {
+			out = append(out, f.Path)
+		}
+		return out
+	}
+
+	if got, want := paths(planBackupCleanup(files, 0, 1)), []string{"b1", "a1"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("all chats: got %v, want %v", got, want)
+	}
+	if got, want := paths(planBackupCleanup(files, 5, 1)), []string{"a1"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("single chat: got %v, want %v", got, want)
+	}
+}
+
+func TestPruneBackupsOnDisk(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("x"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	day := func(d int) time.Time { return time.Date(2024, 1, d, 12, 0, 0, 0, time.Local) }
+	old := backupFilename("Family", 1, 0, day(1), messages.BackupFormatText)
+	mid := backupFilename("Family", 1, 0, day(2), messages.BackupFormatText)
+	renamed := backupFilename("Family Renamed", 1, 0, day(3), messages.BackupFormatText)
+	otherChat := backupFilename("Work", 2, 0, day(1), messages.BackupFormatText)
+	for _, name := range []string{old, mid, renamed, otherChat, "Family-2024-01-01_12-00-00.txt", "notes.txt"} {
+		write(name)
+	}
+	// A directory matching the pattern must never be touched
+	if err := os.Mkdir(filepath.Join(dir, backupFilename("Family", 1, 0, day(0), messages.BackupFormatText)), 0o750); err != nil {
+		t.Fatal(err)
+	}
+
+	removed, err := pruneBackups(dir, 1, 0, 1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []string{filepath.Join(dir, old), filepath.Join(dir, mid)}
+	if !reflect.DeepEqual(removed, want) {
+		t.Errorf("removed %v, want %v", removed, want)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var left []string
+	for _, e := range entries {
+		left = append(left, e.Name())
+	}
+	wantLeft := []string{
+		renamed,
+		otherChat,
+		"Family-2024-01-01_12-00-00.txt",
+		"notes.txt",
+		backupFilename("Family", 1, 0, day(0), messages.BackupFormatText),
+	}
+	sort.Strings(left)
+	sort.Strings(wantLeft)
+	if !reflect.DeepEqual(left, wantLeft) {
+		t.Errorf("remaining files %v, want %v", left, wantLeft)
+	}
+}
+
+func TestPruneBackupsPerTopic(t *testing.T) {
+	dir := t.TempDir()
+	day := func(d int) time.Time { return time.Date(2024, 1, d, 12, 0, 0, 0, time.Local) }
+	whole := backupFilename("Forum", -1001, 0, day(1), messages.BackupFormatText)
+	topicA := backupFilename("Forum-Releases", -1001, 2, day(2), messages.BackupFormatText)
+	topicB := backupFilename("Forum-Support", -1001, 3, day(3), messages.BackupFormatText)
+	for _, name := range []string{whole, topicA, topicB} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("x"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Backing up topic B with one file kept per chat leaves the chat's and topic A's backups
+	removed, err := pruneBackups(dir, -1001, 3, 1)
+	if err != nil || len(removed) != 0 {
+		t.Fatalf("topic backup pruned %v (err %v), want nothing", removed, err)
+	}
+	removed, err = pruneBackups(dir, -1001, 0, 1)
+	if err != nil || len(removed) != 0 {
+		t.Fatalf("whole-chat backup pruned %v (err %v), want nothing", removed, err)
+	}
+
+	files, err := listBackups(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := planBackupCleanup(files, 0, 1); len(got) != 0 {
+		t.Errorf("cleanup plans %v, want one backup kept per chat and topic", got)
+	}
+}
+
+func TestListBackupsMissingDir(t *testing.T) {
+	files, err := listBackups(filepath.Join(t.TempDir(), "missing"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(files) != 0 {
+		t.Errorf("got %v, want none", files)
+	}
+}
+
+func TestWriteChatBackupKeepsExistingFileOnFailure(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "family.txt")
+	if err := os.WriteFile(path, []byte("earlier backup"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	// The history request fails before any message is written
+	peers := tgclient.NewPeerCache(10)
+	peers.Remember(1, &tg.InputPeerUser{UserID: 1, AccessHash: 10})
+	provider := messages.NewProvider(tg.NewClient(&recordingInvoker{responses: map[uint32]bin.Encoder{}}), peers, tgclient.NewLimiter(0))
+
+	if _, err := writeChatBackup(t.Context(), provider, chatBackup{chatID: 1, path: path}); err == nil {
+		t.Fatal("writeChatBackup() succeeded with failing requests")
+	}
+	data, err := os.ReadFile(path)
+	if err != nil || string(data) != "earlier backup" {
+		t.Errorf("earlier backup = %q, %v; want it untouched", data, err)
+	}
+	if _, err := os.Stat(path + partialBackupSuffix); !os.IsNotExist(err) {
+		t.Errorf("empty partial backup left behind: %v", err)
+	}
+}
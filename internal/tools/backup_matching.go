@@ -0,0 +1,223 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/gotd/td/tg"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/tolmachov/mcp-telegram/internal/messages"
+	"github.com/tolmachov/mcp-telegram/internal/tgclient"
+	"github.com/tolmachov/mcp-telegram/internal/tgdata"
+)
+
+const (
+	defaultBackupMatchingChats = 50
+	maxBackupMatchingChats     = 200
+)
+
+// matchedChatBackup is one chat of a BackupMatchingChats result.
+type matchedChatBackup struct {
+	ChatID            int64     `json:"chat_id"`
+	Name              string    `json:"name"`
+	Type              string    `json:"type"`
+	Archived          bool      `json:"archived,omitempty"`
+	LastActivity      time.Time `json:"last_activity,omitzero"`
+	EstimatedMessages int       `json:"estimated_messages,omitempty"`
+	File              string    `json:"file,omitempty"`
+	Saved             int       `json:"saved,omitempty"`
+	Error             string    `json:"error,omitempty"`
+	Warning           string    `json:"warning,omitempty"`
+}
+
+// backupMatchingReport is the BackupMatchingChats output.
+type backupMatchingReport struct {
+	DryRun                 bool                `json:"dry_run"`
+	Matched                int                 `json:"matched"`
+	Limited                bool                `json:"limited,omitempty"` // More chats matched than max_chats
+	EstimatedTotalMessages int                 `json:"estimated_total_messages,omitempty"`
+	BackedUp               int                 `json:"backed_up,omitempty"`
+	Failed                 int                 `json:"failed,omitempty"`
+	SavedMessages          int                 `json:"saved_messages,omitempty"`
+	Chats                  []matchedChatBackup `json:"chats"`
+}
+
+// BackupMatchingHandler handles the BackupMatchingChats tool
+type BackupMatchingHandler struct {
+	client          *tg.Client
+	provider        *messages.Provider
+	allowedPaths    []string
+	maxFilesPerChat int
+}
+
+// NewBackupMatchingHandler creates a new BackupMatchingHandler.
+// maxFilesPerChat caps the auto-named backups kept per chat (0 = unlimited).
+func NewBackupMatchingHandler(client *tg.Client, provider *messages.Provider, allowedPaths []string, maxFilesPerChat int) *BackupMatchingHandler {
+	return &BackupMatchingHandler{
+		client:          client,
+		provider:        provider,
+		allowedPaths:    allowedPaths,
+		maxFilesPerChat: maxFilesPerChat,
+	}
+}
+
+// Tool returns the MCP tool definition
+func (h *BackupMatchingHandler) Tool() mcp.Tool {
+	return mcp.NewTool("BackupMatchingChats",
+		mcp.WithDescription("Back up every chat matching a filter, e.g. all groups without messages for over a year, each to its own auto-named file in the default backup directory. By default this is a dry run listing the matched chats with their last activity and estimated message counts; set confirm=true to run the backups. Reports progress and the result per chat."),
+		mcp.WithString("type",
+			mcp.Description("Only chats of this type"),
+			mcp.Enum(tgdata.SelectChannels, tgdata.SelectGroups, tgdata.SelectBots),
+		),
+		mcp.WithNumber("inactive_days",
+			mcp.Description("Only chats whose latest message is older than this many days"),
+		),
+		mcp.WithString("name_contains",
+			mcp.Description("Only chats whose name contains this text (case-insensitive)"),
+		),
+		mcp.WithString("archived",
+			mcp.Description("Archived chats: 'include' (default), 'only' or 'exclude'"),
+			mcp.Enum(tgdata.ArchivedInclude, tgdata.ArchivedOnly, tgdata.ArchivedExclude),
+		),
+		mcp.WithNumber("max_chats",
+			mcp.Description(fmt.Sprintf("Most chats to handle; further matches are left out (default: %d, max: %d)", defaultBackupMatchingChats, maxBackupMatchingChats)),
+		),
+		mcp.WithNumber("count",
+			mcp.Description("Most recent messages to back up per chat (default: the whole history)"),
+		),
+		mcp.WithBoolean("confirm",
+			mcp.Description("Run the backups; without it only the matched chats are listed (default: false)"),
+		),
+	)
+}
+
+// Handle processes the BackupMatchingChats tool request
+func (h *BackupMatchingHandler) Handle(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	inactiveDays := mcp.ParseInt(request, "inactive_days", 0)
+	if inactiveDays < 0 {
+		return mcp.NewToolResultError("inactive_days must not be negative"), nil
+	}
+	filter := tgdata.ChatFilter{
+		Type:         mcp.ParseString(request, "type", ""),
+		InactiveFor:  time.Duration(inactiveDays) * 24 * time.Hour,
+		NameContains: mcp.ParseString(request, "name_contains", ""),
+		Archived:     mcp.ParseString(request, "archived", tgdata.ArchivedInclude),
+	}
+	if filter.Type == "" && filter.InactiveFor == 0 && filter.NameContains == "" && filter.Archived != tgdata.ArchivedOnly {
+		return mcp.NewToolResultError("at least one filter is required: type, inactive_days, name_contains or archived='only'"), nil
+	}
+	if err := filter.Validate(); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	maxChats := mcp.ParseInt(request, "max_chats", defaultBackupMatchingChats)
+	if maxChats <= 0 || maxChats > maxBackupMatchingChats {
+		return mcp.NewToolResultError(fmt.Sprintf("max_chats must be between 1 and %d", maxBackupMatchingChats)), nil
+	}
+	count := mcp.ParseInt(request, "count", 0)
+	if count < 0 {
+		return mcp.NewToolResultError("count must not be negative"), nil
+	}
+	confirm := mcp.ParseBoolean(request, "confirm", false)
+	if confirm && len(h.allowedPaths) == 0 {
+		return mcp.NewToolResultError("no allowed paths configured for backup"), nil
+	}
+
+	srv := server.ServerFromContext(ctx)
+	notify := func(progress, total int, message string) {
+		if srv == nil {
+			return
+		}
+		params := map[string]any{"progress": progress, "message": message}
+		if total > 0 {
+			params["total"] = total
+		}
+		_ = srv.SendNotificationToClient(ctx, "notifications/progress", params)
+	}
+
+	chats, err := tgdata.FilterChats(ctx, h.client, filter, func(current int, message string) { notify(current, 0, message) })
+	if err != nil {
+		return toolError("list chats", err), nil
+	}
+
+	report := backupMatchingReport{DryRun: !confirm, Matched: len(chats), Chats: make([]matchedChatBackup, 0, min(len(chats), maxChats))}
+	if len(chats) > maxChats {
+		chats = chats[:maxChats]
+		report.Limited = true
+	}
+
+	now := time.Now()
+	for i, chat := range chats {
+		if ctx.Err() != nil {
+			return toolError("back up chats", ctx.Err()), nil
+		}
+		entry := matchedChatBackup{
+			ChatID:       chat.ID,
+			Name:         chat.Name,
+			Type:         chat.Type,
+			Archived:     chat.Archived,
+			LastActivity: chat.LastActivity,
+		}
+
+		if confirm {
+			notify(i, len(chats), fmt.Sprintf("Backing up %s (%d of %d)...", chat.Name, i+1, len(chats)))
+			h.backupChat(ctx, &entry, count, now)
+			if entry.Error != "" {
+				report.Failed++
+			} else {
+				report.BackedUp++
+				report.SavedMessages += entry.Saved
+			}
+		} else {
+			// A one-message page reports the chat's total message count
+			page, err := h.provider.Fetch(ctx, chat.ID, messages.FetchOptions{Limit: 1})
+			if err != nil {
+				entry.Error = tgclient.ClassifyError(err).Error()
+			} else {
+				entry.EstimatedMessages = page.Total
+				if count > 0 {
+					entry.EstimatedMessages = min(page.Total, count)
+				}
+				report.EstimatedTotalMessages += entry.EstimatedMessages
+			}
+			notify(i+1, len(chats), fmt.Sprintf("Estimated %d of %d chats...", i+1, len(chats)))
+		}
+		report.Chats = append(report.Chats, entry)
+	}
+	if confirm {
+		notify(len(chats), len(chats), fmt.Sprintf("Backed up %d of %d chats", report.BackedUp, len(chats)))
+	}
+	return jsonResult(report)
+}
+
+// backupChat writes one chat to an auto-named file, recording the outcome in entry.
+// Older auto-named backups of the chat are pruned like in BackupMessages.
+func (h *BackupMatchingHandler) backupChat(ctx context.Context, entry *matchedChatBackup, count int, now time.Time) {
+	path := filepath.Join(h.allowedPaths[0], backupFilename(entry.Name, entry.ChatID, 0, now, messages.BackupFormatText))
+	b := chatBackup{
+		chatID: entry.ChatID,
+		path:   path,
+		opts:   messages.FetchOptions{Limit: 100, MaxCount: count, GroupAlbums: true},
+	}
+	// A whole history has no out-of-range reply parents
+	if count > 0 {
+		b.maxParents = messages.DefaultMaxReplyParents
+	}
+
+	backup, err := writeChatBackup(ctx, h.provider, b)
+	if err != nil {
+		entry.Error = tgclient.ClassifyError(err).Error()
+		return
+	}
+	entry.File, _ = filepath.Abs(path)
+	entry.Saved = backup.Messages
+
+	if h.maxFilesPerChat > 0 {
+		if _, err := pruneBackups(filepath.Dir(path), entry.ChatID, 0, h.maxFilesPerChat); err != nil {
+			entry.Warning = fmt.Sprintf("pruning old backups failed: %v", err)
+		}
+	}
+}
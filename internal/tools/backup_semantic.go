@@ -0,0 +1,297 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/tolmachov/mcp-telegram/internal/messages"
+	"github.com/tolmachov/mcp-telegram/internal/semantic"
+	"github.com/tolmachov/mcp-telegram/internal/summarize"
+)
+
+// embedBatchSize is the number of chunks embedded between progress notifications.
+const embedBatchSize = 32
+
+// maxSemanticResults caps top_k for SemanticSearchBackup.
+const maxSemanticResults = 50
+
+// embedModelName identifies the embedding provider and model an index was built with.
+func embedModelName(cfg summarize.Config) string {
+	provider := cfg.EmbedProvider
+	if provider == "" {
+		provider = summarize.EmbedProviderOllama
+	}
+	model := cfg.EmbedModel
+	if model == "" {
+		if provider == summarize.EmbedProviderOpenAI {
+			model = summarize.DefaultOpenAIEmbedModel
+		} else {
+			model = summarize.DefaultEmbedModel
+		}
+	}
+	return fmt.Sprintf("%s/%s", provider, model)
+}
+
+// BackupIndexHandler handles the IndexBackup tool
+type BackupIndexHandler struct {
+	config       summarize.Config
+	allowedPaths []string
+}
+
+// NewBackupIndexHandler creates a new BackupIndexHandler
+func NewBackupIndexHandler(config summarize.Config, allowedPaths []string) *BackupIndexHandler {
+	return &BackupIndexHandler{
+		config:       config,
+		allowedPaths: allowedPaths,
+	}
+}
+
+// Tool returns the MCP tool definition
+func (h *BackupIndexHandler) Tool() mcp.Tool {
+	return mcp.NewTool("IndexBackup",
+		mcp.WithDescription("Build a semantic search index for a backup file created by BackupMessages. Messages are chunked and embedded with the configured embedding provider, and the vectors are stored in a local index file next to the backup. Re-run after the backup changes. Use SemanticSearchBackup to query it."),
+		mcp.WithIdempotentHintAnnotation(true),
+		mcp.WithOpenWorldHintAnnotation(true),
+		mcp.WithString("filepath",
+			mcp.Description("Path to the backup file to index (must be within allowed paths)"),
+			mcp.Required(),
+		),
+		mcp.WithString("index_path",
+			mcp.Description("Where to store the index (optional, default: '<filepath>.index.gob')"),
+		),
+	)
+}
+
+// Handle processes the IndexBackup tool request
+func (h *BackupIndexHandler) Handle(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	backupPath := mcp.ParseString(request, "filepath", "")
+	if backupPath == "" {
+		return mcp.NewToolResultError("filepath is required"), nil
+	}
+	indexPath := mcp.ParseString(request, "index_path", semantic.IndexPath(backupPath))
+
+	resolved, err := resolveReadPath(backupPath, h.allowedPaths)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if err := isPathAllowed(indexPath, h.allowedPaths); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	// Saving over an existing index writes through its symlinks, so check where they lead
+	if _, err := os.Lstat(indexPath); err == nil {
+		if indexPath, err = resolveReadPath(indexPath, h.allowedPaths); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+	}
+
+	info, err := os.Stat(resolved)
+	if err != nil {
+		return toolError("read backup", err), nil
+	}
+	data, err := os.ReadFile(resolved)
+	if err != nil {
+		return toolError("read backup", err), nil
+	}
+	msgs, err := messages.ReadBackup(data, messages.BackupFormatFromPath(backupPath))
+	if err != nil {
+		return toolError("parse backup", err), nil
+	}
+
+	chunks := semantic.ChunkMessages(msgs, semantic.DefaultChunkRunes)
+	if len(chunks) == 0 {
+		return mcp.NewToolResultError("backup contains no text messages to index"), nil
+	}
+
+	embedder, err := summarize.NewEmbedder(h.config)
+	if err != nil {
+		return toolError("create embedding provider", err), nil
+	}
+
+	srv := server.ServerFromContext(ctx)
+	for start := 0; start < len(chunks); start += embedBatchSize {
+		end := min(start+embedBatchSize, len(chunks))
+		texts := make([]string, 0, end-start)
+		for _, c := range chunks[start:end] {
+			texts = append(texts, c.Text)
+		}
+		vectors, err := embedder.Embed(ctx, texts)
+		if err != nil {
+			return toolError("embed messages", err), nil
+		}
+		if len(vectors) != len(texts) {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to embed messages: got %d embeddings for %d chunks", len(vectors), len(texts))), nil
+		}
+		for i, v := range vectors {
+			chunks[start+i].Vector = v
+		}
+
+		if srv != nil {
+			_ = srv.SendNotificationToClient(ctx, "notifications/progress", map[string]any{
+				"progress": end,
+				"total":    len(chunks),
+				"message":  fmt.Sprintf("Embedded %d of %d chunks...", end, len(chunks)),
+			})
+		}
+	}
+
+	absBackup, _ := filepath.Abs(backupPath)
+	index, err := semantic.NewIndex(absBackup, info.ModTime(), embedModelName(h.config), chunks)
+	if err != nil {
+		return toolError("build index", err), nil
+	}
+	if err := index.Save(indexPath); err != nil {
+		return toolError("save index", err), nil
+	}
+
+	absIndex, _ := filepath.Abs(indexPath)
+	return mcp.NewToolResultText(fmt.Sprintf("Index built!\nMessages: %d\nChunks: %d\nModel: %s\nIndex: %s",
+		len(msgs), len(chunks), index.Model, absIndex)), nil
+}
+
+// BackupSemanticSearchHandler handles the SemanticSearchBackup tool
+type BackupSemanticSearchHandler struct {
+	config       summarize.Config
+	allowedPaths []string
+}
+
+// NewBackupSemanticSearchHandler creates a new BackupSemanticSearchHandler
+func NewBackupSemanticSearchHandler(config summarize.Config, allowedPaths []string) *BackupSemanticSearchHandler {
+	return &BackupSemanticSearchHandler{
+		config:       config,
+		allowedPaths: allowedPaths,
+	}
+}
+
+// Tool returns the MCP tool definition
+func (h *BackupSemanticSearchHandler) Tool() mcp.Tool {
+	return mcp.NewTool("SemanticSearchBackup",
+		mcp.WithDescription("Search a backup indexed with IndexBackup by meaning rather than exact keywords, so paraphrases and synonyms match. Returns the most similar message chunks with similarity scores and dates."),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithOpenWorldHintAnnotation(true),
+		mcp.WithString("filepath",
+			mcp.Description("Path to the indexed backup file, or to its index file"),
+			mcp.Required(),
+		),
+		mcp.WithString("query",
+			mcp.Description("What to search for, in natural language"),
+			mcp.Required(),
+		),
+		mcp.WithNumber("top_k",
+			mcp.Description("Number of results to return (default: 5, max: 50)"),
+		),
+	)
+}
+
+// semanticSearchResult is a single SemanticSearchBackup match.
+type semanticSearchResult struct {
+	Score   float64   `json:"score"`
+	FirstID int       `json:"first_message_id"`
+	LastID  int       `json:"last_message_id"`
+	Start   time.Time `json:"start"`
+	End     time.Time `json:"end"`
+	Text    string    `json:"text"`
+}
+
+// semanticSearchResponse is the SemanticSearchBackup result.
+type semanticSearchResponse struct {
+	Query    string                 `json:"query"`
+	Index    string                 `json:"index"`
+	Model    string                 `json:"model"`
+	Results  []semanticSearchResult `json:"results"`
+	Warnings []string               `json:"warnings,omitempty"`
+}
+
+// Handle processes the SemanticSearchBackup tool request
+func (h *BackupSemanticSearchHandler) Handle(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	path := mcp.ParseString(request, "filepath", "")
+	if path == "" {
+		return mcp.NewToolResultError("filepath is required"), nil
+	}
+	query := strings.TrimSpace(mcp.ParseString(request, "query", ""))
+	if query == "" {
+		return mcp.NewToolResultError("query is required"), nil
+	}
+	topK := mcp.ParseInt(request, "top_k", 5)
+	if topK <= 0 {
+		topK = 5
+	}
+	topK = min(topK, maxSemanticResults)
+
+	indexPath := path
+	if !strings.HasSuffix(path, semantic.IndexSuffix) {
+		indexPath = semantic.IndexPath(path)
+	}
+	if err := isPathAllowed(indexPath, h.allowedPaths); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	resolvedIndex, err := resolveReadPath(indexPath, h.allowedPaths)
+	if errors.Is(err, fs.ErrNotExist) {
+		return mcp.NewToolResultError(fmt.Sprintf("No index found at %s. Run IndexBackup first.", indexPath)), nil
+	}
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	index, err := semantic.LoadIndex(resolvedIndex)
+	if err != nil {
+		return toolError("load index", err), nil
+	}
+
+	model := embedModelName(h.config)
+	if index.Model != model {
+		return mcp.NewToolResultError(fmt.Sprintf("Index was built with %s but the configured embedding model is %s. Run IndexBackup again.", index.Model, model)), nil
+	}
+
+	embedder, err := summarize.NewEmbedder(h.config)
+	if err != nil {
+		return toolError("create embedding provider", err), nil
+	}
+	vectors, err := embedder.Embed(ctx, []string{query})
+	if err != nil {
+		return toolError("embed query", err), nil
+	}
+	if len(vectors) != 1 {
+		return mcp.NewToolResultError("Failed to embed query: no embedding returned"), nil
+	}
+
+	matches, err := index.Search(vectors[0], topK)
+	if err != nil {
+		return toolError("search index", err), nil
+	}
+
+	resp := semanticSearchResponse{
+		Query:   query,
+		Index:   indexPath,
+		Model:   index.Model,
+		Results: make([]semanticSearchResult, 0, len(matches)),
+	}
+	if info, err := os.Stat(index.Source); err == nil && !info.ModTime().Equal(index.SourceAt) {
+		resp.Warnings = append(resp.Warnings, "the backup changed after it was indexed; run IndexBackup again to include new messages")
+	}
+	for _, m := range matches {
+		resp.Results = append(resp.Results, semanticSearchResult{
+			Score:   m.Score,
+			FirstID: m.Chunk.FirstID,
+			LastID:  m.Chunk.LastID,
+			Start:   m.Chunk.Start,
+			End:     m.Chunk.End,
+			Text:    m.Chunk.Text,
+		})
+	}
+
+	data, err := json.MarshalIndent(resp, "", "  ")
+	if err != nil {
+		return toolError("marshal results", err), nil
+	}
+	return mcp.NewToolResultText(string(data)), nil
+}
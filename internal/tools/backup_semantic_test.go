@@ -0,0 +1,36 @@
+package tools
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/tolmachov/mcp-telegram/internal/semantic"
+	"github.com/tolmachov/mcp-telegram/internal/summarize"
+)
+
+func TestSemanticSearchRejectsSymlinkedIndex(t *testing.T) {
+	allowed, outside := t.TempDir(), t.TempDir()
+	secret := filepath.Join(outside, "secret"+semantic.IndexSuffix)
+	if err := os.WriteFile(secret, []byte("data"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	link := filepath.Join(allowed, "chat"+semantic.IndexSuffix)
+	if err := os.Symlink(secret, link); err != nil {
+		t.Skipf("symlinks unsupported: %v", err)
+	}
+
+	var request mcp.CallToolRequest
+	request.Params.Arguments = map[string]any{"filepath": link, "query": "invoice"}
+	result, err := NewBackupSemanticSearchHandler(summarize.Config{}, []string{allowed}).Handle(context.Background(), request)
+	if err != nil {
+		t.Fatalf("Handle: %v", err)
+	}
+	if !result.IsError || !strings.Contains(resultText(result), "not within allowed directories") {
+		t.Errorf("result = %q, want the symlinked index rejected", resultText(result))
+	}
+}
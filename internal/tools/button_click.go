@@ -0,0 +1,181 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/gotd/td/tg"
+	"github.com/gotd/td/tgerr"
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/tolmachov/mcp-telegram/internal/messages"
+)
+
+// ButtonClickHandler handles the ClickButton tool
+type ButtonClickHandler struct {
+	client   *tg.Client
+	provider *messages.Provider
+}
+
+// NewButtonClickHandler creates a new ButtonClickHandler.
+// The provider's rate limiter throttles the callback request and message refetches.
+func NewButtonClickHandler(client *tg.Client, provider *messages.Provider) *ButtonClickHandler {
+	return &ButtonClickHandler{client: client, provider: provider}
+}
+
+// Tool returns the MCP tool definition
+func (h *ButtonClickHandler) Tool() mcp.Tool {
+	return mcp.NewTool("ClickButton",
+		mcp.WithDescription("Press an inline keyboard button of a bot message (see the buttons field of GetMessages). Identify the button by its text, or by row and column. Returns the bot's answer and whether the message was edited as a result. URL buttons are not clicked; their link is returned instead."),
+		mcp.WithOpenWorldHintAnnotation(true),
+		mcp.WithNumber("chat_id",
+			mcp.Description("The ID of the chat containing the message"),
+			mcp.Required(),
+		),
+		mcp.WithNumber("message_id",
+			mcp.Description("The ID of the message with the buttons"),
+			mcp.Required(),
+		),
+		mcp.WithString("button_text",
+			mcp.Description("Text of the button to press, case-insensitive (optional if row and column are given)"),
+		),
+		mcp.WithNumber("row",
+			mcp.Description("Zero-based row of the button (used when button_text is not given)"),
+		),
+		mcp.WithNumber("column",
+			mcp.Description("Zero-based column of the button within its row (used when button_text is not given)"),
+		),
+	)
+}
+
+// clickResult is the result of pressing a button.
+type clickResult struct {
+	Button  string            `json:"button"`
+	Answer  string            `json:"answer,omitempty"` // Notification or alert text shown by the bot
+	Alert   bool              `json:"alert,omitempty"`  // The answer is a modal alert rather than a toast
+	URL     string            `json:"url,omitempty"`    // Link of a URL button, or one the bot asked to open
+	Edited  bool              `json:"message_edited"`
+	Message *messages.Message `json:"message,omitempty"` // The message after the bot edited it
+	Note    string            `json:"note,omitempty"`
+}
+
+// Handle processes the ClickButton tool request
+func (h *ButtonClickHandler) Handle(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	chatID := mcp.ParseInt64(request, "chat_id", 0)
+	if chatID == 0 {
+		return mcp.NewToolResultError("chat_id is required"), nil
+	}
+
+	messageID := mcp.ParseInt(request, "message_id", 0)
+	if messageID == 0 {
+		return mcp.NewToolResultError("message_id is required"), nil
+	}
+
+	buttonText := mcp.ParseString(request, "button_text", "")
+	args := request.GetArguments()
+	_, hasRow := args["row"]
+	_, hasColumn := args["column"]
+	if buttonText == "" && (!hasRow || !hasColumn) {
+		return mcp.NewToolResultError("either button_text or both row and column are required"), nil
+	}
+
+	before, err := h.fetchMessage(ctx, chatID, messageID)
+	if err != nil {
+		return toolError("get message", err), nil
+	}
+
+	button, err := messages.FindButton(before.Raw.ReplyMarkup, buttonText, mcp.ParseInt(request, "row", 0), mcp.ParseInt(request, "column", 0))
+	if err != nil {
+		if errors.Is(err, messages.ErrButtonNotFound) {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		return toolError("find button", err), nil
+	}
+
+	result := clickResult{Button: button.GetText()}
+	var callback *tg.KeyboardButtonCallback
+	switch b := button.(type) {
+	case *tg.KeyboardButtonURL:
+		result.URL = b.URL
+		result.Note = "URL buttons are not clicked; open the link instead"
+		return jsonResult(result)
+	case *tg.KeyboardButtonURLAuth:
+		result.URL = b.URL
+		result.Note = "Login URL buttons are not clicked; open the link instead"
+		return jsonResult(result)
+	case *tg.KeyboardButton:
+		return mcp.NewToolResultError(fmt.Sprintf("%q is a reply keyboard button; pressing it just sends its text, so use SendMessage with that text", b.Text)), nil
+	case *tg.KeyboardButtonCallback:
+		if b.RequiresPassword {
+			return mcp.NewToolResultError(fmt.Sprintf("%q requires the account's 2FA password, which this server cannot provide; press it in a Telegram app", b.Text)), nil
+		}
+		callback = b
+	default:
+		return mcp.NewToolResultError(fmt.Sprintf("%q is not a callback button and can't be pressed from here", button.GetText())), nil
+	}
+
+	var answer *tg.MessagesBotCallbackAnswer
+	client := h.provider.Paced()
+	err = h.provider.Peers().WithPeer(ctx, client, chatID, func(peer tg.InputPeerClass) error {
+		var err error
+		answer, err = client.MessagesGetBotCallbackAnswer(ctx, &tg.MessagesGetBotCallbackAnswerRequest{
+			Peer:  peer,
+			MsgID: messageID,
+			Data:  callback.Data,
+		})
+		return err
+	})
+	if err != nil {
+		if tgerr.Is(err, "BOT_RESPONSE_TIMEOUT") {
+			return mcp.NewToolResultError("The bot did not answer the button press in time. It may still have acted on it; check the chat with GetMessages before pressing again."), nil
+		}
+		return toolError("press button", err), nil
+	}
+	result.Answer = answer.Message
+	result.Alert = answer.Alert
+	result.URL = answer.URL
+
+	after, err := h.fetchMessage(ctx, chatID, messageID)
+	if err != nil {
+		result.Note = fmt.Sprintf("could not check whether the message was edited: %v", err)
+		return jsonResult(result)
+	}
+	if messageEdited(before, after) {
+		result.Edited = true
+		result.Message = after
+	}
+	return jsonResult(result)
+}
+
+// fetchMessage fetches a single message, failing when it doesn't exist.
+func (h *ButtonClickHandler) fetchMessage(ctx context.Context, chatID int64, messageID int) (*messages.Message, error) {
+	fetched, err := h.provider.FetchByIDs(ctx, chatID, []int{messageID})
+	if err != nil {
+		return nil, err
+	}
+	if len(fetched.Messages) == 0 {
+		return nil, fmt.Errorf("message %d not found", messageID)
+	}
+	return &fetched.Messages[0], nil
+}
+
+// messageEdited reports whether a message changed between two fetches.
+func messageEdited(before, after *messages.Message) bool {
+	if before.Raw != nil && after.Raw != nil && before.Raw.EditDate != after.Raw.EditDate {
+		return true
+	}
+	beforeButtons, _ := json.Marshal(before.Buttons)
+	afterButtons, _ := json.Marshal(after.Buttons)
+	return before.Text != after.Text || string(beforeButtons) != string(afterButtons)
+}
+
+// jsonResult marshals v into an indented JSON tool result.
+func jsonResult(v any) (*mcp.CallToolResult, error) {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return toolError("marshal result", err), nil
+	}
+	return mcp.NewToolResultText(string(data)), nil
+}
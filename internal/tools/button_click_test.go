@@ -0,0 +1,33 @@
+package tools
+
+import (
+	"testing"
+
+	"github.com/gotd/td/tg"
+
+	"github.com/tolmachov/mcp-telegram/internal/messages"
+)
+
+func TestMessageEdited(t *testing.T) {
+	confirm := [][]messages.Button{{{Text: "Confirm", Type: messages.ButtonCallback, Data: "yes"}}}
+	base := messages.Message{ID: 1, Text: "Confirm your order?", Buttons: confirm, Raw: &tg.Message{ID: 1}}
+
+	tests := []struct {
+		name  string
+		after messages.Message
+		want  bool
+	}{
+		{"unchanged", base, false},
+		{"edit date changed", messages.Message{ID: 1, Text: base.Text, Buttons: confirm, Raw: &tg.Message{ID: 1, EditDate: 100}}, true},
+		{"text changed", messages.Message{ID: 1, Text: "Order confirmed", Buttons: confirm}, true},
+		{"keyboard removed", messages.Message{ID: 1, Text: base.Text}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := messageEdited(&base, &tt.after); got != tt.want {
+				t.Errorf("messageEdited() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
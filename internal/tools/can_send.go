@@ -0,0 +1,124 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/tolmachov/mcp-telegram/internal/messages"
+	"github.com/tolmachov/mcp-telegram/internal/tgdata"
+)
+
+const (
+	// maxCanSendChats is the most chats CanSendTo checks at once.
+	maxCanSendChats = 50
+	// sendRightsTTL is how long checked rights are reused, so a check right before
+	// sending doesn't repeat the lookups.
+	sendRightsTTL = 30 * time.Second
+)
+
+// CanSendHandler handles the CanSendTo tool
+type CanSendHandler struct {
+	provider *messages.Provider
+	cache    *sendRightsCache
+}
+
+// NewCanSendHandler creates a new CanSendHandler.
+// The provider's rate limiter throttles the chat lookups.
+func NewCanSendHandler(provider *messages.Provider) *CanSendHandler {
+	return &CanSendHandler{provider: provider, cache: newSendRightsCache(sendRightsTTL)}
+}
+
+// Tool returns the MCP tool definition
+func (h *CanSendHandler) Tool() mcp.Tool {
+	return mcp.NewTool("CanSendTo",
+		mcp.WithDescription("Check where you can post before sending to several chats. For each chat reports whether text and media can be sent, whether it is a channel where only admins post, bans and restrictions with their end date, and slow mode with the earliest next post time. Results are cached for 30 seconds."),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithArray("chat_ids",
+			mcp.WithNumberItems(),
+			mcp.Description(fmt.Sprintf("List of chat IDs to check (max %d)", maxCanSendChats)),
+			mcp.Required(),
+		),
+	)
+}
+
+// Handle processes the CanSendTo tool request
+func (h *CanSendHandler) Handle(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	chatIDs := int64Slice(request.GetIntSlice("chat_ids", nil))
+	if len(chatIDs) == 0 {
+		return mcp.NewToolResultError("chat_ids is required and must not be empty"), nil
+	}
+	if len(chatIDs) > maxCanSendChats {
+		return mcp.NewToolResultError(fmt.Sprintf("Cannot check more than %d chats at once", maxCanSendChats)), nil
+	}
+
+	now := time.Now()
+	var missing []int64
+	for _, id := range chatIDs {
+		if _, ok := h.cache.get(id, now); !ok {
+			missing = append(missing, id)
+		}
+	}
+	fetched := make(map[int64]tgdata.SendRights, len(missing))
+	if len(missing) > 0 {
+		for _, rights := range tgdata.GetSendRights(ctx, h.provider.Paced(), h.provider.Peers(), missing) {
+			fetched[rights.ChatID] = rights
+			h.cache.put(rights, now)
+		}
+	}
+
+	results := make([]tgdata.SendRights, len(chatIDs))
+	for i, id := range chatIDs {
+		if rights, ok := fetched[id]; ok {
+			results[i] = rights
+		} else {
+			results[i], _ = h.cache.get(id, now)
+		}
+	}
+	return jsonResult(results)
+}
+
+// sendRightsCache keeps checked send rights for a short time. Failed checks are not kept.
+type sendRightsCache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	entries map[int64]cachedSendRights
+}
+
+type cachedSendRights struct {
+	rights  tgdata.SendRights
+	expires time.Time
+}
+
+func newSendRightsCache(ttl time.Duration) *sendRightsCache {
+	return &sendRightsCache{ttl: ttl, entries: make(map[int64]cachedSendRights)}
+}
+
+// get returns the cached rights of a chat unless they expired by now.
+func (c *sendRightsCache) get(chatID int64, now time.Time) (tgdata.SendRights, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[chatID]
+	if !ok || !now.Before(entry.expires) {
+		return tgdata.SendRights{}, false
+	}
+	return entry.rights, true
+}
+
+// put caches rights checked at now, dropping expired entries.
+func (c *sendRightsCache) put(rights tgdata.SendRights, now time.Time) {
+	if rights.Error != "" {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for id, entry := range c.entries {
+		if !now.Before(entry.expires) {
+			delete(c.entries, id)
+		}
+	}
+	c.entries[rights.ChatID] = cachedSendRights{rights: rights, expires: now.Add(c.ttl)}
+}
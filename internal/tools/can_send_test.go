@@ -0,0 +1,31 @@
+package tools
+
+import (
+	"testing"
+	"time"
+
+	"github.com/tolmachov/mcp-telegram/internal/tgdata"
+)
+
+func TestSendRightsCache(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	cache := newSendRightsCache(30 * time.Second)
+
+	cache.put(tgdata.SendRights{ChatID: 1, CanSendText: true}, now)
+	cache.put(tgdata.SendRights{ChatID: 2, Error: "chat not found"}, now)
+
+	if got, ok := cache.get(1, now.Add(29*time.Second)); !ok || !got.CanSendText {
+		t.Errorf("get(1) before expiry = %+v, %v", got, ok)
+	}
+	if _, ok := cache.get(1, now.Add(30*time.Second)); ok {
+		t.Error("get(1) after expiry should miss")
+	}
+	if _, ok := cache.get(2, now); ok {
+		t.Error("failed checks should not be cached")
+	}
+
+	cache.put(tgdata.SendRights{ChatID: 3}, now.Add(time.Minute))
+	if _, ok := cache.entries[1]; ok {
+		t.Error("expired entries should be dropped on put")
+	}
+}
@@ -0,0 +1,131 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gotd/td/tg"
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/tolmachov/mcp-telegram/internal/messages"
+	"github.com/tolmachov/mcp-telegram/internal/tgclient"
+)
+
+// Peer folder IDs for folders.editPeerFolders.
+const (
+	mainPeerFolder    = 0
+	archivePeerFolder = 1
+)
+
+// ChatArchiveHandler handles the ArchiveChat tool
+type ChatArchiveHandler struct {
+	provider *messages.Provider
+}
+
+// NewChatArchiveHandler creates a new ChatArchiveHandler.
+// The provider's rate limiter throttles archiving each chat.
+func NewChatArchiveHandler(provider *messages.Provider) *ChatArchiveHandler {
+	return &ChatArchiveHandler{provider: provider}
+}
+
+// Tool returns the MCP tool definition
+func (h *ChatArchiveHandler) Tool() mcp.Tool {
+	opts := []mcp.ToolOption{
+		mcp.WithDescription("Move one or more chats to the archive. Returns the result per chat; GetChats reports them as archived right away."),
+		mcp.WithIdempotentHintAnnotation(true),
+	}
+	opts = append(opts, batchChatOptions("archive")...)
+	return mcp.NewTool("ArchiveChat", opts...)
+}
+
+// Handle processes the ArchiveChat tool request
+func (h *ChatArchiveHandler) Handle(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	chatIDs, err := batchChatIDs(request)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	results := runChatBatch(ctx, h.provider, chatIDs, func(ctx context.Context, client *tg.Client, chatID int64) error {
+		return setPeerFolder(ctx, client, h.provider.Peers(), chatID, archivePeerFolder)
+	})
+	return formatChatBatch(results, "Archived %d out of %d chats successfully!"), nil
+}
+
+// ChatUnarchiveHandler handles the UnarchiveChat tool
+type ChatUnarchiveHandler struct {
+	provider *messages.Provider
+}
+
+// NewChatUnarchiveHandler creates a new ChatUnarchiveHandler.
+// The provider's rate limiter throttles unarchiving each chat.
+func NewChatUnarchiveHandler(provider *messages.Provider) *ChatUnarchiveHandler {
+	return &ChatUnarchiveHandler{provider: provider}
+}
+
+// Tool returns the MCP tool definition
+func (h *ChatUnarchiveHandler) Tool() mcp.Tool {
+	opts := []mcp.ToolOption{
+		mcp.WithDescription("Move one or more chats out of the archive back to the main chat list. Returns the result per chat."),
+		mcp.WithIdempotentHintAnnotation(true),
+	}
+	opts = append(opts, batchChatOptions("unarchive")...)
+	return mcp.NewTool("UnarchiveChat", opts...)
+}
+
+// Handle processes the UnarchiveChat tool request
+func (h *ChatUnarchiveHandler) Handle(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	chatIDs, err := batchChatIDs(request)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	results := runChatBatch(ctx, h.provider, chatIDs, func(ctx context.Context, client *tg.Client, chatID int64) error {
+		return setPeerFolder(ctx, client, h.provider.Peers(), chatID, mainPeerFolder)
+	})
+	return formatChatBatch(results, "Unarchived %d out of %d chats successfully!"), nil
+}
+
+// setPeerFolder moves a chat to the archive or back to the main list.
+func setPeerFolder(ctx context.Context, client *tg.Client, peers *tgclient.PeerCache, chatID int64, folderID int) error {
+	return peers.WithPeer(ctx, client, chatID, func(peer tg.InputPeerClass) error {
+		_, err := client.FoldersEditPeerFolders(ctx, []tg.InputFolderPeer{{Peer: peer, FolderID: folderID}})
+		return err
+	})
+}
+
+// batchChatOptions declares the parameters parsed by batchChatIDs.
+func batchChatOptions(verb string) []mcp.ToolOption {
+	return []mcp.ToolOption{
+		mcp.WithNumber("chat_id",
+			mcp.Description(fmt.Sprintf("The chat to %s", verb)),
+		),
+		mcp.WithArray("chat_ids",
+			mcp.WithNumberItems(),
+			mcp.Description(fmt.Sprintf("List of chat IDs to %s (max %d), instead of chat_id", verb, maxBatchChats)),
+		),
+	}
+}
+
+// batchChatIDs reads either a single chat_id or a chat_ids list, without duplicates.
+func batchChatIDs(request mcp.CallToolRequest) ([]int64, error) {
+	chatID := mcp.ParseInt64(request, "chat_id", 0)
+	chatIDs := int64Slice(request.GetIntSlice("chat_ids", nil))
+	switch {
+	case chatID != 0 && len(chatIDs) > 0:
+		return nil, fmt.Errorf("pass either chat_id or chat_ids, not both")
+	case chatID != 0:
+		return []int64{chatID}, nil
+	case len(chatIDs) == 0:
+		return nil, fmt.Errorf("chat_id or chat_ids is required")
+	case len(chatIDs) > maxBatchChats:
+		return nil, fmt.Errorf("cannot process more than %d chats at once", maxBatchChats)
+	}
+
+	seen := make(map[int64]bool, len(chatIDs))
+	unique := chatIDs[:0]
+	for _, id := range chatIDs {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+	return unique, nil
+}
@@ -0,0 +1,58 @@
+package tools
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestBatchChatIDs(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    map[string]any
+		want    []int64
+		wantErr bool
+	}{
+		{name: "single chat", args: map[string]any{"chat_id": float64(-100123)}, want: []int64{-100123}},
+		{name: "list without duplicates", args: map[string]any{"chat_ids": []any{float64(1), float64(2), float64(1)}}, want: []int64{1, 2}},
+		{name: "both", args: map[string]any{"chat_id": float64(1), "chat_ids": []any{float64(2)}}, wantErr: true},
+		{name: "neither", args: map[string]any{}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := batchChatIDs(callTool("ArchiveChat", tt.args))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("batchChatIDs() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("batchChatIDs() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	tooMany := make([]any, maxBatchChats+1)
+	for i := range tooMany {
+		tooMany[i] = float64(i + 1)
+	}
+	if _, err := batchChatIDs(callTool("ArchiveChat", map[string]any{"chat_ids": tooMany})); err == nil {
+		t.Errorf("batchChatIDs() accepted %d chats", len(tooMany))
+	}
+}
+
+func TestFormatChatBatch(t *testing.T) {
+	result := formatChatBatch([]chatBatchResult{
+		{chatID: 1},
+		{chatID: 2, err: errors.New("boom")},
+	}, "Archived %d out of %d chats successfully!")
+
+	text := result.Content[0].(mcp.TextContent).Text
+	if !strings.HasPrefix(text, "Archived 1 out of 2 chats successfully!") {
+		t.Errorf("summary missing from %q", text)
+	}
+	if !strings.Contains(text, "Successful:\n  - Chat 1\n") || !strings.Contains(text, "Failed:\n  - Chat 2: ") {
+		t.Errorf("per-chat results missing from %q", text)
+	}
+}
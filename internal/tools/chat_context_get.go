@@ -0,0 +1,60 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/tolmachov/mcp-telegram/internal/messages"
+	"github.com/tolmachov/mcp-telegram/internal/tgdata"
+)
+
+// ChatContextGetHandler handles the GetChatContext tool
+type ChatContextGetHandler struct {
+	provider *messages.Provider
+}
+
+// NewChatContextGetHandler creates a new ChatContextGetHandler
+func NewChatContextGetHandler(provider *messages.Provider) *ChatContextGetHandler {
+	return &ChatContextGetHandler{provider: provider}
+}
+
+// Tool returns the MCP tool definition
+func (h *ChatContextGetHandler) Tool() mcp.Tool {
+	return mcp.NewTool("GetChatContext",
+		mcp.WithDescription("Get a compact grounding document for a chat in one call: chat info, pinned message, the last 30 messages, unread count and draft."),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithNumber("chat_id",
+			mcp.Description("The chat ID"),
+			mcp.Required(),
+		),
+		mcp.WithNumber("max_chars",
+			mcp.Description(fmt.Sprintf("Maximum document size in characters; sections are truncated to fit (default: %d)", tgdata.DefaultContextBudget)),
+		),
+	)
+}
+
+// Handle processes the GetChatContext tool request
+func (h *ChatContextGetHandler) Handle(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	chatID := mcp.ParseInt64(request, "chat_id", 0)
+	if chatID == 0 {
+		return mcp.NewToolResultError("chat_id is required"), nil
+	}
+
+	budget := mcp.ParseInt(request, "max_chars", tgdata.DefaultContextBudget)
+
+	cc, err := tgdata.GetChatContext(ctx, h.provider, chatID, budget)
+	if err != nil {
+		return toolError("get chat context", err), nil
+	}
+
+	// Compact encoding: the budget is measured on it
+	data, err := json.Marshal(cc)
+	if err != nil {
+		return toolError("marshal chat context", err), nil
+	}
+
+	return mcp.NewToolResultText(string(data)), nil
+}
@@ -0,0 +1,220 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/gotd/td/telegram/uploader"
+	"github.com/gotd/td/tg"
+	"github.com/gotd/td/tgerr"
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/tolmachov/mcp-telegram/internal/tgclient"
+)
+
+// ChatEditHandler handles the EditChat tool
+type ChatEditHandler struct {
+	client       *tg.Client
+	peers        *tgclient.PeerCache
+	allowedPaths []string
+}
+
+// NewChatEditHandler creates a new ChatEditHandler. Only photos within allowedPaths
+// can be uploaded.
+func NewChatEditHandler(client *tg.Client, peers *tgclient.PeerCache, allowedPaths []string) *ChatEditHandler {
+	return &ChatEditHandler{client: client, peers: peers, allowedPaths: allowedPaths}
+}
+
+// Tool returns the MCP tool definition
+func (h *ChatEditHandler) Tool() mcp.Tool {
+	return mcp.NewTool("EditChat",
+		mcp.WithDescription("Change the title, description or photo of a group or channel you administer. Only the given fields are changed; reports what changed and, on a permission failure, which admin right is missing."),
+		mcp.WithOpenWorldHintAnnotation(true),
+		mcp.WithIdempotentHintAnnotation(true),
+		mcp.WithNumber("chat_id",
+			mcp.Description("The ID of the group or channel (or use chat)"),
+		),
+		chatRefOption("chat", "chat_id", "The group or channel"),
+		mcp.WithString("title",
+			mcp.Description("New title"),
+		),
+		mcp.WithString("about",
+			mcp.Description("New description; an empty string removes it"),
+		),
+		mcp.WithString("photo",
+			mcp.Description("Path of an image file to set as the chat photo"),
+		),
+	)
+}
+
+// chatEdit is one change EditChat applies.
+type chatEdit struct {
+	field string
+	apply func(ctx context.Context, peer tg.InputPeerClass) error
+}
+
+// Handle processes the EditChat tool request
+func (h *ChatEditHandler) Handle(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	chatID, errResult := chatIDParam(ctx, h.client, h.peers, request, "chat_id", "chat")
+	if errResult != nil {
+		return errResult, nil
+	}
+
+	var edits []chatEdit
+	if title := strings.TrimSpace(mcp.ParseString(request, "title", "")); title != "" {
+		edits = append(edits, chatEdit{field: "title", apply: func(ctx context.Context, peer tg.InputPeerClass) error {
+			return h.editTitle(ctx, peer, title)
+		}})
+	}
+	if _, ok := request.GetArguments()["about"]; ok {
+		about := mcp.ParseString(request, "about", "")
+		edits = append(edits, chatEdit{field: "description", apply: func(ctx context.Context, peer tg.InputPeerClass) error {
+			_, err := h.client.MessagesEditChatAbout(ctx, &tg.MessagesEditChatAboutRequest{Peer: peer, About: about})
+			return err
+		}})
+	}
+	if path := mcp.ParseString(request, "photo", ""); path != "" {
+		resolved, errResult := h.checkPhoto(path)
+		if errResult != nil {
+			return errResult, nil
+		}
+		edits = append(edits, chatEdit{field: "photo", apply: func(ctx context.Context, peer tg.InputPeerClass) error {
+			return h.editPhoto(ctx, peer, resolved)
+		}})
+	}
+	if len(edits) == 0 {
+		return mcp.NewToolResultError("nothing to change: give title, about or photo"), nil
+	}
+
+	var changed, unchanged []string
+	var isChannel, private bool
+	var failed string
+	err := h.peers.WithPeer(ctx, h.client, chatID, func(peer tg.InputPeerClass) error {
+		switch peer.(type) {
+		case *tg.InputPeerChannel:
+			isChannel = true
+		case *tg.InputPeerChat:
+		default:
+			private = true
+			return nil
+		}
+		changed, unchanged = nil, nil
+		for _, edit := range edits {
+			err := edit.apply(ctx, peer)
+			switch {
+			case tgerr.Is(err, "CHAT_NOT_MODIFIED", "CHAT_ABOUT_NOT_MODIFIED"):
+				unchanged = append(unchanged, edit.field)
+			case err != nil:
+				failed = edit.field
+				return err
+			default:
+				changed = append(changed, edit.field)
+			}
+		}
+		return nil
+	})
+	switch {
+	case private:
+		return mcp.NewToolResultError(fmt.Sprintf("Chat %d is a private chat; only groups and channels can be edited", chatID)), nil
+	case err != nil:
+		return editChatError(chatID, failed, isChannel, changed, err), nil
+	}
+	return mcp.NewToolResultText(editChatText(chatID, changed, unchanged)), nil
+}
+
+// editTitle renames a basic group or channel, which use different methods.
+func (h *ChatEditHandler) editTitle(ctx context.Context, peer tg.InputPeerClass, title string) error {
+	switch p := peer.(type) {
+	case *tg.InputPeerChannel:
+		_, err := h.client.ChannelsEditTitle(ctx, &tg.ChannelsEditTitleRequest{
+			Channel: &tg.InputChannel{ChannelID: p.ChannelID, AccessHash: p.AccessHash},
+			Title:   title,
+		})
+		return err
+	case *tg.InputPeerChat:
+		_, err := h.client.MessagesEditChatTitle(ctx, &tg.MessagesEditChatTitleRequest{ChatID: p.ChatID, Title: title})
+		return err
+	default:
+		return fmt.Errorf("unexpected peer type %T", peer)
+	}
+}
+
+// editPhoto uploads an image and sets it as the chat photo.
+func (h *ChatEditHandler) editPhoto(ctx context.Context, peer tg.InputPeerClass, path string) error {
+	file, err := uploader.NewUploader(h.client).FromPath(ctx, path)
+	if err != nil {
+		return fmt.Errorf("uploading photo: %w", err)
+	}
+	photo := &tg.InputChatUploadedPhoto{File: file}
+	switch p := peer.(type) {
+	case *tg.InputPeerChannel:
+		_, err = h.client.ChannelsEditPhoto(ctx, &tg.ChannelsEditPhotoRequest{
+			Channel: &tg.InputChannel{ChannelID: p.ChannelID, AccessHash: p.AccessHash},
+			Photo:   photo,
+		})
+	case *tg.InputPeerChat:
+		_, err = h.client.MessagesEditChatPhoto(ctx, &tg.MessagesEditChatPhotoRequest{ChatID: p.ChatID, Photo: photo})
+	default:
+		err = fmt.Errorf("unexpected peer type %T", peer)
+	}
+	return err
+}
+
+// checkPhoto validates the photo file before anything is changed, returning its path
+// with symlinks resolved.
+func (h *ChatEditHandler) checkPhoto(path string) (string, *mcp.CallToolResult) {
+	resolved, err := resolveReadPath(path, h.allowedPaths)
+	if err != nil {
+		return "", mcp.NewToolResultError(err.Error())
+	}
+	info, err := os.Stat(resolved)
+	if err != nil {
+		return "", mcp.NewToolResultError(fmt.Sprintf("cannot read photo: %v", err))
+	}
+	if !info.Mode().IsRegular() {
+		return "", mcp.NewToolResultError(fmt.Sprintf("%s is not a regular file", path))
+	}
+	mimeType, err := fileMimeType(resolved)
+	if err != nil {
+		return "", mcp.NewToolResultError(fmt.Sprintf("cannot read photo: %v", err))
+	}
+	if !sendAsPhoto(mimeType, info.Size()) {
+		return "", mcp.NewToolResultError(fmt.Sprintf("%s is not a JPEG, PNG or WebP image of at most %d MB", path, maxPhotoBytes>>20))
+	}
+	return resolved, nil
+}
+
+// editChatText summarizes what EditChat changed.
+func editChatText(chatID int64, changed, unchanged []string) string {
+	var b strings.Builder
+	if len(changed) > 0 {
+		fmt.Fprintf(&b, "Changed the %s of chat %d", strings.Join(changed, ", "), chatID)
+	} else {
+		fmt.Fprintf(&b, "Nothing changed in chat %d", chatID)
+	}
+	if len(unchanged) > 0 {
+		fmt.Fprintf(&b, "; the %s already had the given value", strings.Join(unchanged, ", "))
+	}
+	return b.String()
+}
+
+// editChatError names the failed field and the admin right it needs, and what was
+// changed before the failure.
+func editChatError(chatID int64, field string, isChannel bool, changed []string, err error) *mcp.CallToolResult {
+	var msg string
+	if tgerr.Is(err, "CHAT_ADMIN_REQUIRED", "RIGHT_FORBIDDEN", "CHAT_WRITE_FORBIDDEN") {
+		right := "Change Group Info"
+		if isChannel {
+			right = "Change Channel Info"
+		}
+		msg = fmt.Sprintf("Cannot change the %s of chat %d: you need the %q admin right", field, chatID, right)
+	} else {
+		msg = fmt.Sprintf("Failed to change the %s of chat %d: %v", field, chatID, tgclient.ClassifyError(err))
+	}
+	if len(changed) > 0 {
+		msg += fmt.Sprintf("; already changed: %s", strings.Join(changed, ", "))
+	}
+	return mcp.NewToolResultError(msg)
+}
@@ -0,0 +1,70 @@
+package tools
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/gotd/td/tgerr"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestEditChatText(t *testing.T) {
+	tests := []struct {
+		name      string
+		changed   []string
+		unchanged []string
+		want      string
+	}{
+		{name: "all changed", changed: []string{"title", "photo"}, want: "Changed the title, photo of chat 555"},
+		{name: "partly unchanged", changed: []string{"title"}, unchanged: []string{"description"}, want: "Changed the title of chat 555; the description already had the given value"},
+		{name: "nothing changed", unchanged: []string{"title"}, want: "Nothing changed in chat 555; the title already had the given value"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := editChatText(555, tt.changed, tt.unchanged); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEditChatError(t *testing.T) {
+	tests := []struct {
+		name      string
+		isChannel bool
+		changed   []string
+		err       error
+		want      string
+	}{
+		{
+			name: "group right",
+			err:  tgerr.New(400, "CHAT_ADMIN_REQUIRED"),
+			want: `Cannot change the photo of chat 555: you need the "Change Group Info" admin right`,
+		},
+		{
+			name:      "channel right after a change",
+			isChannel: true,
+			changed:   []string{"title"},
+			err:       tgerr.New(403, "RIGHT_FORBIDDEN"),
+			want:      `Cannot change the photo of chat 555: you need the "Change Channel Info" admin right; already changed: title`,
+		},
+		{
+			name: "other error",
+			err:  errors.New("boom"),
+			want: "Failed to change the photo of chat 555: boom",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := editChatError(555, "photo", tt.isChannel, tt.changed, tt.err)
+			if !result.IsError {
+				t.Fatal("expected an error result")
+			}
+			if got := result.Content[0].(mcp.TextContent).Text; got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
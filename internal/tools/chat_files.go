@@ -0,0 +1,163 @@
+package tools
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/tolmachov/mcp-telegram/internal/messages"
+)
+
+const (
+	defaultChatFilesLimit = 50
+	maxChatFilesLimit     = 100
+)
+
+// ChatFile is a document shared in a chat.
+type ChatFile struct {
+	MessageID int       `json:"message_id"`
+	Date      time.Time `json:"date"`
+	Sender    string    `json:"sender,omitempty"`
+	FileName  string    `json:"file_name,omitempty"`
+	MimeType  string    `json:"mime_type,omitempty"`
+	Size      int64     `json:"size"`
+	URI       string    `json:"uri,omitempty"` // Download with GetMedia
+}
+
+// chatFilesResult is the ListChatFiles output. Count and TotalBytes cover the files of
+// this page; TotalFiles is the number of documents in the chat and date range.
+type chatFilesResult struct {
+	ChatID       int64      `json:"chat_id"`
+	Files        []ChatFile `json:"files"`
+	Count        int        `json:"count"`
+	TotalBytes   int64      `json:"total_bytes"`
+	TotalFiles   int        `json:"total_files"`
+	HasMore      bool       `json:"has_more"`
+	NextOffsetID int        `json:"next_offset_id,omitempty"`
+}
+
+// ChatFilesHandler handles the ListChatFiles tool
+type ChatFilesHandler struct {
+	provider *messages.Provider
+}
+
+// NewChatFilesHandler creates a new ChatFilesHandler
+func NewChatFilesHandler(provider *messages.Provider) *ChatFilesHandler {
+	return &ChatFilesHandler{provider: provider}
+}
+
+// Tool returns the MCP tool definition
+func (h *ChatFilesHandler) Tool() mcp.Tool {
+	return mcp.NewTool("ListChatFiles",
+		mcp.WithDescription("List files (documents) shared in a chat, newest first: filename, MIME type, size, sender, date, message ID and a URI to download the file with GetMedia, with the file count and total bytes. Pages through the chat with offset_id; sort and min_size apply within a page."),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithNumber("chat_id",
+			mcp.Description("The chat ID"),
+			mcp.Required(),
+		),
+		mcp.WithString("since",
+			mcp.Description("Only files sent from this date, YYYY-MM-DD or YYYY-MM-DD HH:MM:SS"),
+		),
+		mcp.WithString("until",
+			mcp.Description("Only files sent up to this date, YYYY-MM-DD (inclusive) or YYYY-MM-DD HH:MM:SS"),
+		),
+		mcp.WithString("sort",
+			mcp.Description("Order of the page: 'date' (newest first, default) or 'size' (largest first)"),
+			mcp.Enum("date", "size"),
+		),
+		mcp.WithNumber("min_size",
+			mcp.Description("Only files of at least this many bytes"),
+		),
+		mcp.WithNumber("limit",
+			mcp.Description(fmt.Sprintf("Documents to fetch per page (default: %d, max: %d)", defaultChatFilesLimit, maxChatFilesLimit)),
+		),
+		mcp.WithNumber("offset_id",
+			mcp.Description("Continue from next_offset_id of the previous page"),
+		),
+	)
+}
+
+// Handle processes the ListChatFiles tool request
+func (h *ChatFilesHandler) Handle(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	chatID := mcp.ParseInt64(request, "chat_id", 0)
+	if chatID == 0 {
+		return mcp.NewToolResultError("chat_id is required"), nil
+	}
+	sortBy := mcp.ParseString(request, "sort", "date")
+	if sortBy != "date" && sortBy != "size" {
+		return mcp.NewToolResultError(fmt.Sprintf("invalid sort: %s (use 'date' or 'size')", sortBy)), nil
+	}
+	limit := mcp.ParseInt(request, "limit", defaultChatFilesLimit)
+	if limit <= 0 || limit > maxChatFilesLimit {
+		return mcp.NewToolResultError(fmt.Sprintf("limit must be between 1 and %d", maxChatFilesLimit)), nil
+	}
+
+	since, _, err := parseDate(mcp.ParseString(request, "since", ""))
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("invalid since: %v", err)), nil
+	}
+	until, untilHasTime, err := parseDate(mcp.ParseString(request, "until", ""))
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("invalid until: %v", err)), nil
+	}
+
+	fetched, err := h.provider.FetchDocuments(ctx, chatID, messages.FetchOptions{
+		Limit:        limit,
+		OffsetID:     mcp.ParseInt(request, "offset_id", 0),
+		MinDate:      since,
+		MaxDate:      until,
+		MaxDateExact: untilHasTime,
+	})
+	if err != nil {
+		return toolError("list files", err), nil
+	}
+
+	files := chatFiles(fetched.Messages, mcp.ParseInt64(request, "min_size", 0), sortBy)
+	result := chatFilesResult{
+		ChatID:     chatID,
+		Files:      files,
+		Count:      len(files),
+		TotalFiles: fetched.Total,
+		HasMore:    fetched.HasMore,
+	}
+	for _, f := range files {
+		result.TotalBytes += f.Size
+	}
+	if fetched.HasMore {
+		result.NextOffsetID = fetched.NextID
+	}
+	return jsonResult(result)
+}
+
+// chatFiles lists the files (documents, videos and audio) among msgs of at least minSize bytes, sorted by date
+// (newest first) or size (largest first).
+func chatFiles(msgs []messages.Message, minSize int64, sortBy string) []ChatFile {
+	files := []ChatFile{}
+	for _, msg := range msgs {
+		if msg.Media == nil || !msg.Media.IsDocument() || msg.Media.Size < minSize {
+			continue
+		}
+		files = append(files, ChatFile{
+			MessageID: msg.ID,
+			Date:      msg.Date,
+			Sender:    msg.SenderName,
+			FileName:  msg.Media.FileName,
+			MimeType:  msg.Media.MimeType,
+			Size:      msg.Media.Size,
+			URI:       msg.Media.ResourceURI,
+		})
+	}
+	slices.SortStableFunc(files, func(a, b ChatFile) int {
+		if sortBy == "size" {
+			if c := cmp.Compare(b.Size, a.Size); c != 0 {
+				return c
+			}
+		}
+		return b.Date.Compare(a.Date)
+	})
+	return files
+}
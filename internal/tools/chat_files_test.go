@@ -0,0 +1,48 @@
+package tools
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/tolmachov/mcp-telegram/internal/messages"
+)
+
+func TestChatFiles(t *testing.T) {
+	day := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	doc := func(id int, size int64, hoursLater int) messages.Message {
+		return messages.Message{
+			ID:    id,
+			Date:  day.Add(time.Duration(hoursLater) * time.Hour),
+			Media: &messages.MediaInfo{Type: "document", FileName: "f.bin", Size: size},
+		}
+	}
+	msgs := []messages.Message{
+		doc(3, 500, 3),
+		doc(2, 9000, 2),
+		{ID: 4, Date: day, Media: &messages.MediaInfo{Type: "photo"}},
+		{ID: 5, Date: day, Text: "no media"},
+		doc(1, 100, 1),
+	}
+
+	ids := func(files []ChatFile) []int {
+		var out []int
+		for _, f := range files {
+			out = append(out, f.MessageID)
+		}
+		return out
+	}
+
+	if got := ids(chatFiles(msgs, 0, "date")); !reflect.DeepEqual(got, []int{3, 2, 1}) {
+		t.Errorf("by date = %v, want [3 2 1]", got)
+	}
+	if got := ids(chatFiles(msgs, 0, "size")); !reflect.DeepEqual(got, []int{2, 3, 1}) {
+		t.Errorf("by size = %v, want [2 3 1]", got)
+	}
+	if got := ids(chatFiles(msgs, 500, "date")); !reflect.DeepEqual(got, []int{3, 2}) {
+		t.Errorf("min_size 500 = %v, want [3 2]", got)
+	}
+	if got := chatFiles(nil, 0, "date"); got == nil || len(got) != 0 {
+		t.Errorf("no messages = %#v, want empty list", got)
+	}
+}
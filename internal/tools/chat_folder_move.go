@@ -0,0 +1,67 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gotd/td/tg"
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/tolmachov/mcp-telegram/internal/tgclient"
+	"github.com/tolmachov/mcp-telegram/internal/tgdata"
+)
+
+// ChatFolderMoveHandler handles the MoveChatToFolder tool
+type ChatFolderMoveHandler struct {
+	client *tg.Client
+	peers  *tgclient.PeerCache
+}
+
+// NewChatFolderMoveHandler creates a new ChatFolderMoveHandler
+func NewChatFolderMoveHandler(client *tg.Client, peers *tgclient.PeerCache) *ChatFolderMoveHandler {
+	return &ChatFolderMoveHandler{client: client, peers: peers}
+}
+
+// Tool returns the MCP tool definition
+func (h *ChatFolderMoveHandler) Tool() mcp.Tool {
+	return mcp.NewTool("MoveChatToFolder",
+		mcp.WithDescription("Add a chat to a chat folder, so GetChats with that folder_id lists it. The chat stays in its other folders and in the main list. Folder IDs are in the telegram://folders resource."),
+		mcp.WithIdempotentHintAnnotation(true),
+		mcp.WithNumber("chat_id",
+			mcp.Description("The ID of the chat to add (or use chat)"),
+		),
+		chatRefOption("chat", "chat_id", "The chat to add"),
+		mcp.WithNumber("folder_id",
+			mcp.Description("The ID of the folder"),
+			mcp.Required(),
+		),
+	)
+}
+
+// Handle processes the MoveChatToFolder tool request
+func (h *ChatFolderMoveHandler) Handle(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	chatID, errResult := chatIDParam(ctx, h.client, h.peers, request, "chat_id", "chat")
+	if errResult != nil {
+		return errResult, nil
+	}
+	folderID := mcp.ParseInt(request, "folder_id", 0)
+	if folderID == 0 {
+		return mcp.NewToolResultError("folder_id is required"), nil
+	}
+
+	var title string
+	var changed bool
+	err := h.peers.WithPeer(ctx, h.client, chatID, func(peer tg.InputPeerClass) error {
+		var err error
+		title, changed, err = tgdata.AddChatToFolder(ctx, h.client, folderID, peer)
+		return err
+	})
+	if err != nil {
+		return toolError("move chat to folder", err), nil
+	}
+
+	if !changed {
+		return mcp.NewToolResultText(fmt.Sprintf("Chat %d is already in folder %q", chatID, title)), nil
+	}
+	return mcp.NewToolResultText(fmt.Sprintf("Chat %d added to folder %q", chatID, title)), nil
+}
@@ -3,22 +3,23 @@ package tools
 import (
 	"context"
 	"encoding/json"
-	"fmt"
 
 	"github.com/gotd/td/tg"
 	"github.com/mark3labs/mcp-go/mcp"
 
+	"github.com/tolmachov/mcp-telegram/internal/tgclient"
 	"github.com/tolmachov/mcp-telegram/internal/tgdata"
 )
 
 // ChatInfoGetHandler handles the GetChatInfo tool
 type ChatInfoGetHandler struct {
 	client *tg.Client
+	peers  *tgclient.PeerCache
 }
 
 // NewChatInfoGetHandler creates a new ChatInfoGetHandler
-func NewChatInfoGetHandler(client *tg.Client) *ChatInfoGetHandler {
-	return &ChatInfoGetHandler{client: client}
+func NewChatInfoGetHandler(client *tg.Client, peers *tgclient.PeerCache) *ChatInfoGetHandler {
+	return &ChatInfoGetHandler{client: client, peers: peers}
 }
 
 // Tool returns the MCP tool definition
@@ -40,14 +41,14 @@ func (h *ChatInfoGetHandler) Handle(ctx context.Context, request mcp.CallToolReq
 		return mcp.NewToolResultError("chat_id is required"), nil
 	}
 
-	info, err := tgdata.GetChatInfo(ctx, h.client, chatID)
+	info, err := tgdata.GetChatInfo(ctx, h.client, h.peers, chatID)
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Failed to get chat info: %v", err)), nil
+		return toolError("get chat info", err), nil
 	}
 
 	data, err := json.MarshalIndent(info, "", "  ")
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal chat info: %v", err)), nil
+		return toolError("marshal chat info", err), nil
 	}
 
 	return mcp.NewToolResultText(string(data)), nil
@@ -0,0 +1,146 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/gotd/td/tg"
+	"github.com/gotd/td/tgerr"
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/tolmachov/mcp-telegram/internal/tgclient"
+	"github.com/tolmachov/mcp-telegram/internal/tgdata"
+)
+
+// ChatJoinHandler handles the JoinChat tool
+type ChatJoinHandler struct {
+	client *tg.Client
+	peers  *tgclient.PeerCache
+}
+
+// NewChatJoinHandler creates a new ChatJoinHandler
+func NewChatJoinHandler(client *tg.Client, peers *tgclient.PeerCache) *ChatJoinHandler {
+	return &ChatJoinHandler{client: client, peers: peers}
+}
+
+// Tool returns the MCP tool definition
+func (h *ChatJoinHandler) Tool() mcp.Tool {
+	return mcp.NewTool("JoinChat",
+		mcp.WithDescription("Join a group or channel by public @username, t.me link or invite link (t.me/+..., t.me/joinchat/...). Use CheckInvite first to see what an invite link leads to. Reports the chat_id of the joined chat."),
+		mcp.WithOpenWorldHintAnnotation(true),
+		mcp.WithIdempotentHintAnnotation(true),
+		mcp.WithString("link",
+			mcp.Description("@username, t.me/username or invite link (t.me/+HASH or +HASH) of the chat to join"),
+			mcp.Required(),
+		),
+	)
+}
+
+// Handle processes the JoinChat tool request
+func (h *ChatJoinHandler) Handle(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	link := strings.TrimSpace(mcp.ParseString(request, "link", ""))
+	if link == "" {
+		return mcp.NewToolResultError("link is required"), nil
+	}
+
+	var result *tgdata.JoinResult
+	var err error
+	if hash, ok := tgclient.ParseInviteLink(link); ok {
+		result, err = tgdata.JoinByInvite(ctx, h.client, hash)
+	} else {
+		result, err = h.joinPublic(ctx, link)
+	}
+	if err != nil {
+		return joinError("join chat", err), nil
+	}
+	return mcp.NewToolResultText(joinText(result)), nil
+}
+
+// joinPublic resolves a public username or link and joins the channel behind it.
+func (h *ChatJoinHandler) joinPublic(ctx context.Context, ref string) (*tgdata.JoinResult, error) {
+	_, peer, err := h.peers.ResolvePeerFlexible(ctx, h.client, ref)
+	if err != nil {
+		return nil, fmt.Errorf("resolving %q: %w", ref, err)
+	}
+	channel, ok := peer.(*tg.InputPeerChannel)
+	if !ok {
+		return nil, fmt.Errorf("%q is not a public group or channel", ref)
+	}
+	return tgdata.JoinPublic(ctx, h.client, &tg.InputChannel{ChannelID: channel.ChannelID, AccessHash: channel.AccessHash})
+}
+
+// joinText describes the outcome of JoinChat.
+func joinText(r *tgdata.JoinResult) string {
+	name := fmt.Sprintf("%s %q", r.Type, r.Title)
+	if r.Title == "" {
+		name = "the chat"
+	}
+	switch {
+	case r.RequestSent:
+		return fmt.Sprintf("Join request sent: an admin of %s has to approve it", name)
+	case r.AlreadyMember:
+		return fmt.Sprintf("Nothing to do: you are already a member of %s (chat_id %d)", name, r.ChatID)
+	default:
+		return fmt.Sprintf("Joined %s (chat_id %d)", name, r.ChatID)
+	}
+}
+
+// joinError explains the invite and join errors a user can act on.
+func joinError(action string, err error) *mcp.CallToolResult {
+	switch {
+	case tgerr.Is(err, "INVITE_HASH_EXPIRED"):
+		return mcp.NewToolResultError("The invite link has expired or was revoked; ask for a new one")
+	case tgerr.Is(err, "INVITE_HASH_INVALID", "INVITE_HASH_EMPTY"):
+		return mcp.NewToolResultError("The invite link is invalid")
+	case tgerr.Is(err, "CHANNELS_TOO_MUCH"):
+		return mcp.NewToolResultError("You are in too many groups and channels; leave some before joining more")
+	case tgerr.Is(err, "USERS_TOO_MUCH"):
+		return mcp.NewToolResultError("The chat is full")
+	}
+	return toolError(action, err)
+}
+
+// InviteCheckHandler handles the CheckInvite tool
+type InviteCheckHandler struct {
+	client *tg.Client
+}
+
+// NewInviteCheckHandler creates a new InviteCheckHandler
+func NewInviteCheckHandler(client *tg.Client) *InviteCheckHandler {
+	return &InviteCheckHandler{client: client}
+}
+
+// Tool returns the MCP tool definition
+func (h *InviteCheckHandler) Tool() mcp.Tool {
+	return mcp.NewTool("CheckInvite",
+		mcp.WithDescription("Preview the group or channel behind an invite link without joining: title, description, type, member count, whether you are already a member and whether joining needs an admin's approval."),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithString("link",
+			mcp.Description("Invite link (t.me/+..., t.me/joinchat/...) or invite hash"),
+			mcp.Required(),
+		),
+	)
+}
+
+// Handle processes the CheckInvite tool request
+func (h *InviteCheckHandler) Handle(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	link := strings.TrimSpace(mcp.ParseString(request, "link", ""))
+	if link == "" {
+		return mcp.NewToolResultError("link is required"), nil
+	}
+	hash, ok := tgclient.ParseInviteLink(link)
+	if !ok {
+		if !strings.ContainsAny(link, "/:@") && !strings.HasPrefix(link, "+") {
+			hash = link
+		} else {
+			return mcp.NewToolResultError(fmt.Sprintf("%q is not an invite link; public chats can be joined with JoinChat directly", link)), nil
+		}
+	}
+
+	preview, err := tgdata.CheckInvite(ctx, h.client, hash)
+	if err != nil {
+		return joinError("check invite", err), nil
+	}
+	return jsonResult(preview)
+}
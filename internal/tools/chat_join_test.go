@@ -0,0 +1,39 @@
+package tools
+
+import (
+	"testing"
+
+	"github.com/tolmachov/mcp-telegram/internal/tgdata"
+)
+
+func TestJoinText(t *testing.T) {
+	tests := []struct {
+		name   string
+		result tgdata.JoinResult
+		want   string
+	}{
+		{
+			name:   "joined",
+			result: tgdata.JoinResult{ChatID: -1001234567890, Title: "Gophers", Type: "supergroup"},
+			want:   `Joined supergroup "Gophers" (chat_id -1001234567890)`,
+		},
+		{
+			name:   "already member",
+			result: tgdata.JoinResult{ChatID: 555, Title: "Family", Type: "group", AlreadyMember: true},
+			want:   `Nothing to do: you are already a member of group "Family" (chat_id 555)`,
+		},
+		{
+			name:   "request sent without preview",
+			result: tgdata.JoinResult{RequestSent: true},
+			want:   "Join request sent: an admin of the chat has to approve it",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := joinText(&tt.result); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,67 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gotd/td/tg"
+	"github.com/gotd/td/tgerr"
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/tolmachov/mcp-telegram/internal/tgclient"
+)
+
+// ChatLeaveHandler handles the LeaveChat tool
+type ChatLeaveHandler struct {
+	client *tg.Client
+	peers  *tgclient.PeerCache
+}
+
+// NewChatLeaveHandler creates a new ChatLeaveHandler
+func NewChatLeaveHandler(client *tg.Client, peers *tgclient.PeerCache) *ChatLeaveHandler {
+	return &ChatLeaveHandler{client: client, peers: peers}
+}
+
+// Tool returns the MCP tool definition
+func (h *ChatLeaveHandler) Tool() mcp.Tool {
+	return mcp.NewTool("LeaveChat",
+		mcp.WithDescription("Leave a group, supergroup or channel. Rejoining a private group or channel needs a new invite. Reports whether you left or weren't a member anymore."),
+		mcp.WithDestructiveHintAnnotation(true),
+		mcp.WithNumber("chat_id",
+			mcp.Description("The ID of the group or channel to leave"),
+			mcp.Required(),
+		),
+	)
+}
+
+// Handle processes the LeaveChat tool request
+func (h *ChatLeaveHandler) Handle(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	chatID := mcp.ParseInt64(request, "chat_id", 0)
+	if chatID == 0 {
+		return mcp.NewToolResultError("chat_id is required"), nil
+	}
+
+	var private bool
+	err := h.peers.WithPeer(ctx, h.client, chatID, func(peer tg.InputPeerClass) error {
+		switch p := peer.(type) {
+		case *tg.InputPeerChannel:
+			_, err := h.client.ChannelsLeaveChannel(ctx, &tg.InputChannel{ChannelID: p.ChannelID, AccessHash: p.AccessHash})
+			return err
+		case *tg.InputPeerChat:
+			_, err := h.client.MessagesDeleteChatUser(ctx, &tg.MessagesDeleteChatUserRequest{ChatID: p.ChatID, UserID: &tg.InputUserSelf{}})
+			return err
+		default:
+			private = true
+			return nil
+		}
+	})
+	switch {
+	case private:
+		return mcp.NewToolResultError(fmt.Sprintf("Chat %d is a private chat, which can't be left; use DeleteDialog to delete it", chatID)), nil
+	case tgerr.Is(err, "USER_NOT_PARTICIPANT", "CHANNEL_PRIVATE"):
+		return mcp.NewToolResultText(fmt.Sprintf("Nothing to do: you are not a member of chat %d anymore", chatID)), nil
+	case err != nil:
+		return toolError("leave chat", err), nil
+	}
+	return mcp.NewToolResultText(fmt.Sprintf("Left chat %d", chatID)), nil
+}
@@ -0,0 +1,88 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gotd/td/tg"
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/tolmachov/mcp-telegram/internal/messages"
+	"github.com/tolmachov/mcp-telegram/internal/tgdata"
+)
+
+const (
+	// defaultChatMembers is how many members GetChatMembers returns by default.
+	defaultChatMembers = 100
+	// maxChatMembers is the most members GetChatMembers returns per call.
+	maxChatMembers = 1000
+)
+
+// ChatMembersGetHandler handles the GetChatMembers tool
+type ChatMembersGetHandler struct {
+	provider *messages.Provider
+}
+
+// NewChatMembersGetHandler creates a new ChatMembersGetHandler.
+// Every request goes through the provider's rate limiter.
+func NewChatMembersGetHandler(provider *messages.Provider) *ChatMembersGetHandler {
+	return &ChatMembersGetHandler{provider: provider}
+}
+
+// Tool returns the MCP tool definition
+func (h *ChatMembersGetHandler) Tool() mcp.Tool {
+	return mcp.NewTool("GetChatMembers",
+		mcp.WithDescription("List members of a group or channel with user ID, name, username, role (creator, admin, member or restricted) and join date. Use offset from next_offset for the next page. Channels often show their members to admins only; the result then has a note instead."),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithNumber("chat_id",
+			mcp.Description("The ID of the group or channel"),
+			mcp.Required(),
+		),
+		mcp.WithString("filter",
+			mcp.Description("Which members to list (default: recent, all members newest first)"),
+			mcp.Enum(tgdata.MembersRecent, tgdata.MembersAdmins, tgdata.MembersBots),
+		),
+		mcp.WithString("query",
+			mcp.Description("Only members whose name or username contains this text; not combinable with the admins and bots filters"),
+		),
+		mcp.WithNumber("limit",
+			mcp.Description(fmt.Sprintf("Maximum number of members (default: %d, max: %d)", defaultChatMembers, maxChatMembers)),
+		),
+		mcp.WithNumber("offset",
+			mcp.Description("Number of members to skip, from next_offset of the previous page (default: 0)"),
+		),
+	)
+}
+
+// Handle processes the GetChatMembers tool request
+func (h *ChatMembersGetHandler) Handle(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	chatID := mcp.ParseInt64(request, "chat_id", 0)
+	if chatID == 0 {
+		return mcp.NewToolResultError("chat_id is required"), nil
+	}
+	limit := mcp.ParseInt(request, "limit", defaultChatMembers)
+	if limit > maxChatMembers {
+		limit = maxChatMembers
+	}
+	query := tgdata.MembersQuery{
+		Filter: mcp.ParseString(request, "filter", tgdata.MembersRecent),
+		Search: mcp.ParseString(request, "query", ""),
+		Limit:  limit,
+		Offset: mcp.ParseInt(request, "offset", 0),
+	}
+	if err := query.Validate(); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	client := h.provider.Paced()
+	var members *tgdata.ChatMembers
+	err := h.provider.Peers().WithPeer(ctx, client, chatID, func(peer tg.InputPeerClass) error {
+		var err error
+		members, err = tgdata.GetChatMembers(ctx, client, chatID, peer, query)
+		return err
+	})
+	if err != nil {
+		return toolError("get chat members", err), nil
+	}
+	return jsonResult(members)
+}
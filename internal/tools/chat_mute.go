@@ -2,114 +2,166 @@ package tools
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gotd/td/tg"
 	"github.com/mark3labs/mcp-go/mcp"
 
+	"github.com/tolmachov/mcp-telegram/internal/messages"
 	"github.com/tolmachov/mcp-telegram/internal/tgclient"
 )
 
 // ChatMuteHandler handles the MuteChat tool
 type ChatMuteHandler struct {
-	client *tg.Client
+	client   *tg.Client
+	provider *messages.Provider
 }
 
-// NewChatMuteHandler creates a new ChatMuteHandler
-func NewChatMuteHandler(client *tg.Client) *ChatMuteHandler {
-	return &ChatMuteHandler{client: client}
+// NewChatMuteHandler creates a new ChatMuteHandler.
+// The provider's rate limiter throttles muting each chat of a chat_ids list.
+func NewChatMuteHandler(client *tg.Client, provider *messages.Provider) *ChatMuteHandler {
+	return &ChatMuteHandler{client: client, provider: provider}
 }
 
 // Tool returns the MCP tool definition
 func (h *ChatMuteHandler) Tool() mcp.Tool {
 	return mcp.NewTool("MuteChat",
-		mcp.WithDescription("Mute notifications for a chat."),
+		mcp.WithDescription("Mute notifications for a chat, or for a list of chats with the result per chat."),
 		mcp.WithIdempotentHintAnnotation(true),
 		mcp.WithNumber("chat_id",
-			mcp.Description("The ID of the chat to mute"),
-			mcp.Required(),
+			mcp.Description("The ID of the chat to mute (or use chat or chat_ids)"),
 		),
-		mcp.WithNumber("duration",
-			mcp.Description("Duration in seconds (0 = forever, default: forever)"),
+		chatRefOption("chat", "chat_id", "The chat to mute"),
+		mcp.WithArray("chat_ids",
+			mcp.WithNumberItems(),
+			mcp.Description(fmt.Sprintf("List of chat IDs to mute (max %d), instead of chat_id", maxBatchChats)),
 		),
+		muteDurationOption(),
 	)
 }
 
 // Handle processes the MuteChat tool request
 func (h *ChatMuteHandler) Handle(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	chatID := mcp.ParseInt64(request, "chat_id", 0)
-	if chatID == 0 {
-		return mcp.NewToolResultError("chat_id is required"), nil
-	}
-
-	// Duration in seconds, 0 = forever
-	duration := mcp.ParseInt(request, "duration", 0)
-
-	// Resolve the peer
-	peer, err := tgclient.ResolvePeer(ctx, h.client, chatID)
+	duration, err := parseMuteDuration(request.GetArguments()["duration"])
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Failed to resolve peer: %v", err)), nil
+		return mcp.NewToolResultError(err.Error()), nil
 	}
+	now := time.Now()
+	muteUntil := muteUntilFor(int(duration.Seconds()), now)
 
-	// Convert InputPeer to InputNotifyPeer
-	var notifyPeer tg.InputNotifyPeerClass
-	switch p := peer.(type) {
-	case *tg.InputPeerUser:
-		notifyPeer = &tg.InputNotifyPeer{
-			Peer: &tg.InputPeerUser{UserID: p.UserID, AccessHash: p.AccessHash},
-		}
-	case *tg.InputPeerChat:
-		notifyPeer = &tg.InputNotifyPeer{
-			Peer: &tg.InputPeerChat{ChatID: p.ChatID},
+	if _, ok := request.GetArguments()["chat_ids"]; ok {
+		chatIDs, err := batchChatIDs(request)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
 		}
-	case *tg.InputPeerChannel:
-		notifyPeer = &tg.InputNotifyPeer{
-			Peer: &tg.InputPeerChannel{ChannelID: p.ChannelID, AccessHash: p.AccessHash},
+		results := runChatBatch(ctx, h.provider, chatIDs, func(ctx context.Context, client *tg.Client, chatID int64) error {
+			return muteChat(ctx, client, h.provider.Peers(), chatID, muteUntil)
+		})
+		for i := range results {
+			if results[i].err == nil {
+				results[i].detail = describeMute(muteUntil)
+			}
 		}
-	default:
-		return mcp.NewToolResultError("Unsupported peer type"), nil
+		return formatChatBatch(results, "Muted %d out of %d chats successfully!"), nil
 	}
 
-	// Set mute_until: 0 = default, max int32 = forever, or a specific Unix timestamp
-	var muteUntil int
-	if duration == 0 {
-		// Mute forever (max int32 value)
-		muteUntil = 2147483647
-	} else {
-		// Mute until specific time (current Unix timestamp + duration in seconds)
-		muteUntil = int(time.Now().Unix()) + duration
+	chatID, errResult := chatIDParam(ctx, h.client, h.provider.Peers(), request, "chat_id", "chat")
+	if errResult != nil {
+		return errResult, nil
+	}
+	if err := muteChat(ctx, h.client, h.provider.Peers(), chatID, muteUntil); err != nil {
+		return toolError("mute chat", err), nil
 	}
+	return mcp.NewToolResultText(fmt.Sprintf("Chat %d %s", chatID, describeMute(muteUntil))), nil
+}
 
-	// Update notification settings
-	_, err = h.client.AccountUpdateNotifySettings(ctx, &tg.AccountUpdateNotifySettingsRequest{
-		Peer: notifyPeer,
-		Settings: tg.InputPeerNotifySettings{
-			MuteUntil: muteUntil,
-		},
+// muteChat sets a chat's mute_until only, keeping previews, sound and other settings
+// intact.
+func muteChat(ctx context.Context, client *tg.Client, peers *tgclient.PeerCache, chatID int64, muteUntil int) error {
+	return peers.WithPeer(ctx, client, chatID, func(peer tg.InputPeerClass) error {
+		_, _, err := updateNotifySettings(ctx, client, peer, notifyUpdate{MuteUntil: &muteUntil})
+		return err
 	})
-	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Failed to mute chat: %v", err)), nil
+}
+
+// muteDurationOption declares the duration parameter read by parseMuteDuration.
+func muteDurationOption() mcp.ToolOption {
+	return mcp.WithString("duration",
+		mcp.Description("How long to mute, e.g. '1h', '8h', '7d', '1d12h' or 'forever' (default: forever). A number is taken as seconds, 0 meaning forever"),
+	)
+}
+
+// daysPrefix matches a leading day count, which time.ParseDuration lacks.
+var daysPrefix = regexp.MustCompile(`^(\d+)d`)
+
+// parseMuteDuration reads a mute duration given as a duration string with an optional
+// day count ("7d", "1d12h", "90m"), "forever", or a number of seconds. Forever, the
+// default, is 0.
+func parseMuteDuration(v any) (time.Duration, error) {
+	var s string
+	switch v := v.(type) {
+	case nil:
+		return 0, nil
+	case float64:
+		s = strconv.FormatFloat(v, 'f', -1, 64)
+	case string:
+		s = strings.ToLower(strings.TrimSpace(v))
+	default:
+		return 0, fmt.Errorf("invalid duration %v", v)
+	}
+	if s == "" || s == "forever" {
+		return 0, nil
 	}
 
-	var result string
-	if duration == 0 {
-		result = fmt.Sprintf("Chat %d muted forever", chatID)
-	} else {
-		result = fmt.Sprintf("Chat %d muted for %d seconds", chatID, duration)
+	if seconds, err := strconv.Atoi(s); err == nil {
+		if seconds < 0 {
+			return 0, errors.New("duration must not be negative")
+		}
+		return time.Duration(seconds) * time.Second, nil
 	}
 
-	return mcp.NewToolResultText(result), nil
+	var d time.Duration
+	rest := s
+	if m := daysPrefix.FindStringSubmatch(s); m != nil {
+		days, _ := strconv.Atoi(m[1])
+		d = time.Duration(days) * 24 * time.Hour
+		rest = s[len(m[0]):]
+	}
+	if rest != "" {
+		parsed, err := time.ParseDuration(rest)
+		if err != nil {
+			return 0, fmt.Errorf("invalid duration %q, expected e.g. '8h', '7d' or 'forever'", s)
+		}
+		d += parsed
+	}
+	if d < time.Second {
+		return 0, fmt.Errorf("duration %q must be at least one second; use 'forever' to mute forever", s)
+	}
+	return d, nil
+}
+
+// describeMute describes a mute_until value, e.g. "muted forever".
+func describeMute(muteUntil int) string {
+	if muteUntil >= muteForever {
+		return "muted forever"
+	}
+	return "muted until " + time.Unix(int64(muteUntil), 0).UTC().Format(time.RFC3339)
 }
 
 // ChatUnmuteHandler handles the UnmuteChat tool
 type ChatUnmuteHandler struct {
 	client *tg.Client
+	peers  *tgclient.PeerCache
 }
 
 // NewChatUnmuteHandler creates a new ChatUnmuteHandler
-func NewChatUnmuteHandler(client *tg.Client) *ChatUnmuteHandler {
-	return &ChatUnmuteHandler{client: client}
+func NewChatUnmuteHandler(client *tg.Client, peers *tgclient.PeerCache) *ChatUnmuteHandler {
+	return &ChatUnmuteHandler{client: client, peers: peers}
 }
 
 // Tool returns the MCP tool definition
@@ -118,54 +170,37 @@ func (h *ChatUnmuteHandler) Tool() mcp.Tool {
 		mcp.WithDescription("Unmute notifications for a chat."),
 		mcp.WithIdempotentHintAnnotation(true),
 		mcp.WithNumber("chat_id",
-			mcp.Description("The ID of the chat to unmute"),
-			mcp.Required(),
+			mcp.Description("The ID of the chat to unmute (or use chat)"),
 		),
+		chatRefOption("chat", "chat_id", "The chat to unmute"),
 	)
 }
 
 // Handle processes the UnmuteChat tool request
 func (h *ChatUnmuteHandler) Handle(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	chatID := mcp.ParseInt64(request, "chat_id", 0)
-	if chatID == 0 {
-		return mcp.NewToolResultError("chat_id is required"), nil
+	chatID, errResult := chatIDParam(ctx, h.client, h.peers, request, "chat_id", "chat")
+	if errResult != nil {
+		return errResult, nil
 	}
 
-	// Resolve the peer
-	peer, err := tgclient.ResolvePeer(ctx, h.client, chatID)
-	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Failed to resolve peer: %v", err)), nil
-	}
-
-	// Convert InputPeer to InputNotifyPeer
-	var notifyPeer tg.InputNotifyPeerClass
-	switch p := peer.(type) {
-	case *tg.InputPeerUser:
-		notifyPeer = &tg.InputNotifyPeer{
-			Peer: &tg.InputPeerUser{UserID: p.UserID, AccessHash: p.AccessHash},
-		}
-	case *tg.InputPeerChat:
-		notifyPeer = &tg.InputNotifyPeer{
-			Peer: &tg.InputPeerChat{ChatID: p.ChatID},
-		}
-	case *tg.InputPeerChannel:
-		notifyPeer = &tg.InputNotifyPeer{
-			Peer: &tg.InputPeerChannel{ChannelID: p.ChannelID, AccessHash: p.AccessHash},
-		}
-	default:
-		return mcp.NewToolResultError("Unsupported peer type"), nil
-	}
-
-	// Reset notification settings (mute_until = 0 means use default/unmuted)
-	_, err = h.client.AccountUpdateNotifySettings(ctx, &tg.AccountUpdateNotifySettingsRequest{
-		Peer: notifyPeer,
-		Settings: tg.InputPeerNotifySettings{
-			MuteUntil: 0,
-		},
+	// mute_until = 0 means unmuted; other settings are preserved
+	unmuted := 0
+	err := h.peers.WithPeer(ctx, h.client, chatID, func(peer tg.InputPeerClass) error {
+		_, _, err := updateNotifySettings(ctx, h.client, peer, notifyUpdate{MuteUntil: &unmuted})
+		return err
 	})
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Failed to unmute chat: %v", err)), nil
+		return toolError("unmute chat", err), nil
 	}
 
 	return mcp.NewToolResultText(fmt.Sprintf("Chat %d unmuted", chatID)), nil
 }
+
+// muteUntilFor returns the mute_until value muting for duration seconds from now:
+// max int32 for forever (0), otherwise a Unix timestamp.
+func muteUntilFor(duration int, now time.Time) int {
+	if duration == 0 {
+		return muteForever
+	}
+	return int(now.Unix()) + duration
+}
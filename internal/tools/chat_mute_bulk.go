@@ -0,0 +1,184 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gotd/td/tg"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/tolmachov/mcp-telegram/internal/messages"
+	"github.com/tolmachov/mcp-telegram/internal/tgclient"
+	"github.com/tolmachov/mcp-telegram/internal/tgdata"
+)
+
+// bulkMuteResult is the outcome of muting or unmuting one chat.
+type bulkMuteResult struct {
+	ChatID int64  `json:"chat_id"`
+	Name   string `json:"name,omitempty"`
+	OK     bool   `json:"ok"`
+	Error  string `json:"error,omitempty"`
+}
+
+// bulkMuteReport is the result of MuteChats and UnmuteChats.
+type bulkMuteReport struct {
+	Selected  int              `json:"selected"`
+	Succeeded int              `json:"succeeded"`
+	Failed    int              `json:"failed"`
+	MuteUntil time.Time        `json:"mute_until,omitzero"` // Zero when unmuting or muting forever
+	Results   []bulkMuteResult `json:"results"`
+}
+
+// ChatsMuteHandler handles the MuteChats tool
+type ChatsMuteHandler struct {
+	provider *messages.Provider
+}
+
+// NewChatsMuteHandler creates a new ChatsMuteHandler.
+// The provider's rate limiter throttles the per-chat updates.
+func NewChatsMuteHandler(provider *messages.Provider) *ChatsMuteHandler {
+	return &ChatsMuteHandler{provider: provider}
+}
+
+// Tool returns the MCP tool definition
+func (h *ChatsMuteHandler) Tool() mcp.Tool {
+	opts := []mcp.ToolOption{
+		mcp.WithDescription("Mute notifications for many chats at once: a list of chat IDs, every channel, group or bot chat, or all chats of a folder. Chats are muted one by one with progress notifications; returns the result per chat."),
+		mcp.WithIdempotentHintAnnotation(true),
+	}
+	opts = append(opts, chatSelectorOptions("mute")...)
+	opts = append(opts, muteDurationOption())
+	return mcp.NewTool("MuteChats", opts...)
+}
+
+// Handle processes the MuteChats tool request
+func (h *ChatsMuteHandler) Handle(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	now := time.Now()
+	duration, err := parseMuteDuration(request.GetArguments()["duration"])
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	muteUntil := muteUntilFor(int(duration.Seconds()), now)
+
+	report, errResult := applyBulkMute(ctx, h.provider, request, muteUntil, "Muted")
+	if errResult != nil {
+		return errResult, nil
+	}
+	if duration != 0 {
+		report.MuteUntil = time.Unix(int64(muteUntil), 0)
+	}
+	return jsonResult(report)
+}
+
+// ChatsUnmuteHandler handles the UnmuteChats tool
+type ChatsUnmuteHandler struct {
+	provider *messages.Provider
+}
+
+// NewChatsUnmuteHandler creates a new ChatsUnmuteHandler.
+// The provider's rate limiter throttles the per-chat updates.
+func NewChatsUnmuteHandler(provider *messages.Provider) *ChatsUnmuteHandler {
+	return &ChatsUnmuteHandler{provider: provider}
+}
+
+// Tool returns the MCP tool definition
+func (h *ChatsUnmuteHandler) Tool() mcp.Tool {
+	opts := []mcp.ToolOption{
+		mcp.WithDescription("Unmute notifications for many chats at once, selected like in MuteChats. Chats are unmuted one by one with progress notifications; returns the result per chat."),
+		mcp.WithIdempotentHintAnnotation(true),
+	}
+	opts = append(opts, chatSelectorOptions("unmute")...)
+	return mcp.NewTool("UnmuteChats", opts...)
+}
+
+// Handle processes the UnmuteChats tool request
+func (h *ChatsUnmuteHandler) Handle(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	report, errResult := applyBulkMute(ctx, h.provider, request, 0, "Unmuted")
+	if errResult != nil {
+		return errResult, nil
+	}
+	return jsonResult(report)
+}
+
+// chatSelectorOptions declares the parameters parsed by parseChatSelector.
+func chatSelectorOptions(verb string) []mcp.ToolOption {
+	return []mcp.ToolOption{
+		mcp.WithArray("chat_ids",
+			mcp.WithNumberItems(),
+			mcp.Description(fmt.Sprintf("Chat IDs to %s", verb)),
+		),
+		mcp.WithString("type",
+			mcp.Description("Select every chat of this type, archived ones included"),
+			mcp.Enum(tgdata.SelectChannels, tgdata.SelectGroups, tgdata.SelectBots),
+		),
+		mcp.WithString("folder",
+			mcp.Description("Select every chat in the folder with this name (case-insensitive)"),
+		),
+	}
+}
+
+// parseChatSelector reads the selector parameters; exactly one must be given.
+func parseChatSelector(request mcp.CallToolRequest) (tgdata.ChatSelector, error) {
+	sel := tgdata.ChatSelector{
+		ChatIDs: int64Slice(request.GetIntSlice("chat_ids", nil)),
+		Type:    mcp.ParseString(request, "type", ""),
+		Folder:  mcp.ParseString(request, "folder", ""),
+	}
+	return sel, sel.Validate()
+}
+
+// applyBulkMute expands the request's chat selector and sets mute_until on each chat in
+// turn through the rate limiter, reporting progress. Failures are recorded per chat.
+func applyBulkMute(ctx context.Context, provider *messages.Provider, request mcp.CallToolRequest, muteUntil int, done string) (bulkMuteReport, *mcp.CallToolResult) {
+	sel, err := parseChatSelector(request)
+	if err != nil {
+		return bulkMuteReport{}, mcp.NewToolResultError(err.Error())
+	}
+
+	srv := server.ServerFromContext(ctx)
+	notify := func(progress, total int, message string) {
+		if srv == nil {
+			return
+		}
+		params := map[string]any{"progress": progress, "message": message}
+		if total > 0 {
+			params["total"] = total
+		}
+		_ = srv.SendNotificationToClient(ctx, "notifications/progress", params)
+	}
+
+	client := provider.Paced()
+	chats, err := tgdata.SelectChats(ctx, client, sel, func(current int, message string) { notify(current, 0, message) })
+	if err != nil {
+		return bulkMuteReport{}, toolError("select chats", err)
+	}
+
+	report := bulkMuteReport{Selected: len(chats), Results: make([]bulkMuteResult, 0, len(chats))}
+	for i, chat := range chats {
+		if ctx.Err() != nil {
+			return bulkMuteReport{}, toolError("update chats", ctx.Err())
+		}
+
+		result := bulkMuteResult{ChatID: chat.ID, Name: chat.Name}
+		if err := setMuteUntil(ctx, client, provider.Peers(), chat.ID, muteUntil); err != nil {
+			result.Error = err.Error()
+			report.Failed++
+		} else {
+			result.OK = true
+			report.Succeeded++
+		}
+		report.Results = append(report.Results, result)
+		notify(i+1, len(chats), fmt.Sprintf("%s %d of %d chats...", done, i+1, len(chats)))
+	}
+	return report, nil
+}
+
+// setMuteUntil updates a chat's mute_until, keeping its other notification settings.
+func setMuteUntil(ctx context.Context, client *tg.Client, peers *tgclient.PeerCache, chatID int64, muteUntil int) error {
+	return peers.WithPeer(ctx, client, chatID, func(peer tg.InputPeerClass) error {
+		_, _, err := updateNotifySettings(ctx, client, peer, notifyUpdate{MuteUntil: &muteUntil})
+		return err
+	})
+}
@@ -0,0 +1,97 @@
+package tools
+
+import (
+	"context"
+	"time"
+
+	"github.com/gotd/td/tg"
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/tolmachov/mcp-telegram/internal/messages"
+	"github.com/tolmachov/mcp-telegram/internal/tgclient"
+)
+
+// muteStatus is whether one chat is muted, as reported by GetMuteStatus.
+type muteStatus struct {
+	ChatID    int64     `json:"chat_id"`
+	Muted     bool      `json:"muted"`
+	MuteUntil time.Time `json:"mute_until,omitzero"` // Zero when not muted or muted forever
+	Forever   bool      `json:"forever,omitempty"`
+	// Default is set when the chat has no mute setting of its own and follows the
+	// notification settings for its kind of chat
+	Default bool   `json:"default,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+// ChatMuteStatusHandler handles the GetMuteStatus tool
+type ChatMuteStatusHandler struct {
+	provider *messages.Provider
+}
+
+// NewChatMuteStatusHandler creates a new ChatMuteStatusHandler.
+// The provider's rate limiter throttles the per-chat lookups.
+func NewChatMuteStatusHandler(provider *messages.Provider) *ChatMuteStatusHandler {
+	return &ChatMuteStatusHandler{provider: provider}
+}
+
+// Tool returns the MCP tool definition
+func (h *ChatMuteStatusHandler) Tool() mcp.Tool {
+	opts := []mcp.ToolOption{
+		mcp.WithDescription("Report whether chats are muted and until when, from their notification settings."),
+		mcp.WithReadOnlyHintAnnotation(true),
+	}
+	opts = append(opts, batchChatOptions("check")...)
+	return mcp.NewTool("GetMuteStatus", opts...)
+}
+
+// Handle processes the GetMuteStatus tool request
+func (h *ChatMuteStatusHandler) Handle(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	chatIDs, err := batchChatIDs(request)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	now := time.Now()
+	statuses := make([]muteStatus, 0, len(chatIDs))
+	client := h.provider.Paced()
+	for _, chatID := range chatIDs {
+		var settings *tg.PeerNotifySettings
+		err := h.provider.Peers().WithPeer(ctx, client, chatID, func(peer tg.InputPeerClass) error {
+			notifyPeer, err := toNotifyPeer(peer)
+			if err != nil {
+				return err
+			}
+			settings, err = client.AccountGetNotifySettings(ctx, notifyPeer)
+			return err
+		})
+		if err != nil {
+			statuses = append(statuses, muteStatus{ChatID: chatID, Error: tgclient.ClassifyError(err).Error()})
+			continue
+		}
+		statuses = append(statuses, muteStatusOf(chatID, settings, now))
+	}
+
+	// A single requested chat that failed is an error, not an empty report
+	if len(statuses) == 1 && statuses[0].Error != "" {
+		return mcp.NewToolResultError("Failed to get mute status: " + statuses[0].Error), nil
+	}
+	return jsonResult(map[string]any{"chats": statuses})
+}
+
+// muteStatusOf reads the mute state of a chat from its notification settings.
+// A mute_until in the past means the mute has expired.
+func muteStatusOf(chatID int64, settings *tg.PeerNotifySettings, now time.Time) muteStatus {
+	status := muteStatus{ChatID: chatID}
+	muteUntil, ok := settings.GetMuteUntil()
+	switch {
+	case !ok:
+		status.Default = true
+	case muteUntil >= muteForever:
+		status.Muted = true
+		status.Forever = true
+	case muteUntil > int(now.Unix()):
+		status.Muted = true
+		status.MuteUntil = time.Unix(int64(muteUntil), 0)
+	}
+	return status
+}
@@ -0,0 +1,72 @@
+package tools
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gotd/td/tg"
+)
+
+func TestParseMuteDuration(t *testing.T) {
+	tests := []struct {
+		input   any
+		want    time.Duration
+		wantErr bool
+	}{
+		{input: nil, want: 0},
+		{input: "forever", want: 0},
+		{input: " Forever ", want: 0},
+		{input: float64(0), want: 0},
+		{input: float64(3600), want: time.Hour},
+		{input: "3600", want: time.Hour},
+		{input: "1h", want: time.Hour},
+		{input: "8h", want: 8 * time.Hour},
+		{input: "90m", want: 90 * time.Minute},
+		{input: "7d", want: 7 * 24 * time.Hour},
+		{input: "1d12h", want: 36 * time.Hour},
+		{input: "0s", wantErr: true},
+		{input: "-1h", wantErr: true},
+		{input: float64(-5), wantErr: true},
+		{input: "a week", wantErr: true},
+		{input: "7days", wantErr: true},
+		{input: true, wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := parseMuteDuration(tt.input)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseMuteDuration(%v) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("parseMuteDuration(%v) = %v, want %v", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestMuteStatusOf(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	settings := func(muteUntil int) *tg.PeerNotifySettings {
+		var s tg.PeerNotifySettings
+		s.SetMuteUntil(muteUntil)
+		return &s
+	}
+
+	tests := []struct {
+		name     string
+		settings *tg.PeerNotifySettings
+		want     muteStatus
+	}{
+		{name: "default", settings: &tg.PeerNotifySettings{}, want: muteStatus{ChatID: 1, Default: true}},
+		{name: "forever", settings: settings(muteForever), want: muteStatus{ChatID: 1, Muted: true, Forever: true}},
+		{name: "until", settings: settings(1_700_003_600), want: muteStatus{ChatID: 1, Muted: true, MuteUntil: time.Unix(1_700_003_600, 0)}},
+		{name: "expired", settings: settings(1_699_999_000), want: muteStatus{ChatID: 1}},
+		{name: "unmuted", settings: settings(0), want: muteStatus{ChatID: 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := muteStatusOf(1, tt.settings, now); got != tt.want {
+				t.Errorf("muteStatusOf() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,225 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gotd/td/tg"
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/tolmachov/mcp-telegram/internal/tgclient"
+)
+
+// muteForever is the mute_until value Telegram treats as "muted forever" (max int32).
+const muteForever = 2147483647
+
+// notifyUpdate holds the notification settings to change. Nil fields keep their current value.
+type notifyUpdate struct {
+	MuteUntil    *int
+	ShowPreviews *bool
+	Silent       *bool
+}
+
+// toNotifyPeer converts an InputPeer to an InputNotifyPeer.
+func toNotifyPeer(peer tg.InputPeerClass) (tg.InputNotifyPeerClass, error) {
+	switch peer.(type) {
+	case *tg.InputPeerUser, *tg.InputPeerChat, *tg.InputPeerChannel:
+		return &tg.InputNotifyPeer{Peer: peer}, nil
+	default:
+		return nil, errors.New("unsupported peer type")
+	}
+}
+
+// mergeNotifySettings builds the settings to send from the current ones and an update.
+// Fields Telegram reports as not set stay unset so they keep inheriting the default,
+// and fields absent from the update keep their current value.
+func mergeNotifySettings(current *tg.PeerNotifySettings, upd notifyUpdate) tg.InputPeerNotifySettings {
+	var s tg.InputPeerNotifySettings
+	if current != nil {
+		if v, ok := current.GetShowPreviews(); ok {
+			s.SetShowPreviews(v)
+		}
+		if v, ok := current.GetSilent(); ok {
+			s.SetSilent(v)
+		}
+		if v, ok := current.GetMuteUntil(); ok {
+			s.SetMuteUntil(v)
+		}
+		if v, ok := current.GetOtherSound(); ok {
+			s.SetSound(v)
+		} else if v, ok := current.GetAndroidSound(); ok {
+			s.SetSound(v)
+		} else if v, ok := current.GetIosSound(); ok {
+			s.SetSound(v)
+		}
+		if v, ok := current.GetStoriesMuted(); ok {
+			s.SetStoriesMuted(v)
+		}
+		if v, ok := current.GetStoriesHideSender(); ok {
+			s.SetStoriesHideSender(v)
+		}
+		if v, ok := current.GetStoriesOtherSound(); ok {
+			s.SetStoriesSound(v)
+		}
+	}
+
+	if upd.MuteUntil != nil {
+		s.SetMuteUntil(*upd.MuteUntil)
+	}
+	if upd.ShowPreviews != nil {
+		s.SetShowPreviews(*upd.ShowPreviews)
+	}
+	if upd.Silent != nil {
+		s.SetSilent(*upd.Silent)
+	}
+	return s
+}
+
+// updateNotifySettings applies an update to a peer's notification settings, preserving
+// everything the update does not mention. It returns the settings before and after.
+func updateNotifySettings(ctx context.Context, client *tg.Client, peer tg.InputPeerClass, upd notifyUpdate) (before, after tg.InputPeerNotifySettings, err error) {
+	notifyPeer, err := toNotifyPeer(peer)
+	if err != nil {
+		return before, after, err
+	}
+
+	current, err := client.AccountGetNotifySettings(ctx, notifyPeer)
+	if err != nil {
+		return before, after, fmt.Errorf("getting notification settings: %w", err)
+	}
+
+	before = mergeNotifySettings(current, notifyUpdate{})
+	after = mergeNotifySettings(current, upd)
+
+	if _, err := client.AccountUpdateNotifySettings(ctx, &tg.AccountUpdateNotifySettingsRequest{
+		Peer:     notifyPeer,
+		Settings: after,
+	}); err != nil {
+		return before, after, fmt.Errorf("updating notification settings: %w", err)
+	}
+	return before, after, nil
+}
+
+// formatNotifySettings renders notification settings as human-readable lines.
+func formatNotifySettings(s tg.InputPeerNotifySettings, now time.Time) string {
+	var sb strings.Builder
+
+	sb.WriteString("  Muted: ")
+	if v, ok := s.GetMuteUntil(); ok {
+		switch {
+		case v >= muteForever:
+			sb.WriteString("forever")
+		case v > int(now.Unix()):
+			sb.WriteString("until " + time.Unix(int64(v), 0).UTC().Format(time.RFC3339))
+		default:
+			sb.WriteString("no")
+		}
+	} else {
+		sb.WriteString("default")
+	}
+
+	sb.WriteString("\n  Show previews: ")
+	sb.WriteString(formatTriState(s.GetShowPreviews()))
+	sb.WriteString("\n  Silent: ")
+	sb.WriteString(formatTriState(s.GetSilent()))
+	return sb.String()
+}
+
+// formatTriState renders an optional boolean setting, where unset means "inherit default".
+func formatTriState(value, ok bool) string {
+	switch {
+	case !ok:
+		return "default"
+	case value:
+		return "yes"
+	default:
+		return "no"
+	}
+}
+
+// ChatNotificationsHandler handles the SetChatNotifications tool
+type ChatNotificationsHandler struct {
+	client *tg.Client
+	peers  *tgclient.PeerCache
+}
+
+// NewChatNotificationsHandler creates a new ChatNotificationsHandler
+func NewChatNotificationsHandler(client *tg.Client, peers *tgclient.PeerCache) *ChatNotificationsHandler {
+	return &ChatNotificationsHandler{client: client, peers: peers}
+}
+
+// Tool returns the MCP tool definition
+func (h *ChatNotificationsHandler) Tool() mcp.Tool {
+	return mcp.NewTool("SetChatNotifications",
+		mcp.WithDescription("Change notification settings for a chat. Only the given settings are changed; everything else is preserved."),
+		mcp.WithIdempotentHintAnnotation(true),
+		mcp.WithNumber("chat_id",
+			mcp.Description("The ID of the chat"),
+			mcp.Required(),
+		),
+		mcp.WithBoolean("show_previews",
+			mcp.Description("Show message text in notifications"),
+		),
+		mcp.WithBoolean("silent",
+			mcp.Description("Deliver notifications without sound"),
+		),
+		mcp.WithNumber("mute_duration",
+			mcp.Description("Mute for this many seconds (0 = unmute, -1 = forever)"),
+		),
+	)
+}
+
+// Handle processes the SetChatNotifications tool request
+func (h *ChatNotificationsHandler) Handle(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	chatID := mcp.ParseInt64(request, "chat_id", 0)
+	if chatID == 0 {
+		return mcp.NewToolResultError("chat_id is required"), nil
+	}
+
+	upd, err := parseNotifyUpdate(request.GetArguments(), time.Now())
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	var before, after tg.InputPeerNotifySettings
+	err = h.peers.WithPeer(ctx, h.client, chatID, func(peer tg.InputPeerClass) error {
+		var err error
+		before, after, err = updateNotifySettings(ctx, h.client, peer, upd)
+		return err
+	})
+	if err != nil {
+		return toolError("update notification settings", err), nil
+	}
+
+	now := time.Now()
+	return mcp.NewToolResultText(fmt.Sprintf("Notification settings for chat %d updated\nBefore:\n%s\nAfter:\n%s",
+		chatID, formatNotifySettings(before, now), formatNotifySettings(after, now))), nil
+}
+
+// parseNotifyUpdate reads the optional settings from tool arguments.
+func parseNotifyUpdate(args map[string]any, now time.Time) (notifyUpdate, error) {
+	var upd notifyUpdate
+	if v, ok := args["show_previews"].(bool); ok {
+		upd.ShowPreviews = &v
+	}
+	if v, ok := args["silent"].(bool); ok {
+		upd.Silent = &v
+	}
+	if v, ok := args["mute_duration"].(float64); ok {
+		var muteUntil int
+		switch {
+		case v < 0:
+			muteUntil = muteForever
+		case v > 0:
+			muteUntil = int(now.Unix()) + int(v)
+		}
+		upd.MuteUntil = &muteUntil
+	}
+	if upd == (notifyUpdate{}) {
+		return upd, errors.New("at least one of show_previews, silent or mute_duration is required")
+	}
+	return upd, nil
+}
@@ -0,0 +1,155 @@
+package tools
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gotd/td/tg"
+)
+
+func TestMergeNotifySettings(t *testing.T) {
+	yes, no := true, false
+	unmuted := 0
+
+	// settingsOf builds current settings with only the given fields set.
+	settingsOf := func(set func(s *tg.PeerNotifySettings)) *tg.PeerNotifySettings {
+		s := &tg.PeerNotifySettings{}
+		if set != nil {
+			set(s)
+		}
+		return s
+	}
+
+	tests := []struct {
+		name             string
+		current          *tg.PeerNotifySettings
+		upd              notifyUpdate
+		wantShowPreviews string
+		wantSilent       string
+		wantMute         string
+	}{
+		{
+			name:             "unset fields stay unset",
+			current:          settingsOf(nil),
+			upd:              notifyUpdate{ShowPreviews: &no},
+			wantShowPreviews: "no",
+			wantSilent:       "default",
+			wantMute:         "default",
+		},
+		{
+			name: "explicit false is preserved",
+			current: settingsOf(func(s *tg.PeerNotifySettings) {
+				s.SetSilent(false)
+			}),
+			upd:              notifyUpdate{ShowPreviews: &yes},
+			wantShowPreviews: "yes",
+			wantSilent:       "no",
+			wantMute:         "default",
+		},
+		{
+			name: "unmute keeps previews and silent",
+			current: settingsOf(func(s *tg.PeerNotifySettings) {
+				s.SetShowPreviews(false)
+				s.SetSilent(true)
+				s.SetMuteUntil(muteForever)
+			}),
+			upd:              notifyUpdate{MuteUntil: &unmuted},
+			wantShowPreviews: "no",
+			wantSilent:       "yes",
+			wantMute:         "no",
+		},
+		{
+			name: "empty update reproduces current settings",
+			current: settingsOf(func(s *tg.PeerNotifySettings) {
+				s.SetShowPreviews(true)
+				s.SetMuteUntil(muteForever)
+			}),
+			wantShowPreviews: "yes",
+			wantSilent:       "default",
+			wantMute:         "forever",
+		},
+		{
+			name:             "nil current settings",
+			current:          nil,
+			upd:              notifyUpdate{Silent: &yes},
+			wantShowPreviews: "default",
+			wantSilent:       "yes",
+			wantMute:         "default",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := mergeNotifySettings(tt.current, tt.upd)
+			if s := formatTriState(got.GetShowPreviews()); s != tt.wantShowPreviews {
+				t.Errorf("show_previews = %s, want %s", s, tt.wantShowPreviews)
+			}
+			if s := formatTriState(got.GetSilent()); s != tt.wantSilent {
+				t.Errorf("silent = %s, want %s", s, tt.wantSilent)
+			}
+			want := "  Muted: " + tt.wantMute + "\n"
+			if s := formatNotifySettings(got, time.Now()); !strings.HasPrefix(s, want) {
+				t.Errorf("formatted settings %q, want prefix %q", s, want)
+			}
+		})
+	}
+}
+
+func TestMergeNotifySettingsPreservesSound(t *testing.T) {
+	current := &tg.PeerNotifySettings{}
+	current.SetOtherSound(&tg.NotificationSoundRingtone{ID: 42})
+
+	no := false
+	got := mergeNotifySettings(current, notifyUpdate{ShowPreviews: &no})
+
+	sound, ok := got.GetSound()
+	if !ok {
+		t.Fatal("custom sound was dropped")
+	}
+	if ringtone, ok := sound.(*tg.NotificationSoundRingtone); !ok || ringtone.ID != 42 {
+		t.Errorf("sound = %+v, want ringtone 42", sound)
+	}
+}
+
+func TestParseNotifyUpdate(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+
+	tests := []struct {
+		name          string
+		args          map[string]any
+		wantMuteUntil *int
+		wantErr       bool
+	}{
+		{name: "nothing to change", args: map[string]any{"chat_id": float64(1)}, wantErr: true},
+		{name: "previews only", args: map[string]any{"show_previews": false}},
+		{name: "unmute", args: map[string]any{"mute_duration": float64(0)}, wantMuteUntil: ptr(0)},
+		{name: "mute forever", args: map[string]any{"mute_duration": float64(-1)}, wantMuteUntil: ptr(muteForever)},
+		{name: "mute for an hour", args: map[string]any{"mute_duration": float64(3600)}, wantMuteUntil: ptr(1_700_003_600)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseNotifyUpdate(tt.args, now)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected error, got %+v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			switch {
+			case tt.wantMuteUntil == nil && got.MuteUntil != nil:
+				t.Errorf("mute_until = %d, want unset", *got.MuteUntil)
+			case tt.wantMuteUntil != nil && (got.MuteUntil == nil || *got.MuteUntil != *tt.wantMuteUntil):
+				t.Errorf("mute_until = %v, want %d", got.MuteUntil, *tt.wantMuteUntil)
+			}
+		})
+	}
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
@@ -0,0 +1,93 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gotd/td/tg"
+	"github.com/gotd/td/tgerr"
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/tolmachov/mcp-telegram/internal/messages"
+	"github.com/tolmachov/mcp-telegram/internal/tgclient"
+)
+
+// ChatPinHandler handles the PinChat tool
+type ChatPinHandler struct {
+	provider *messages.Provider
+}
+
+// NewChatPinHandler creates a new ChatPinHandler.
+// The provider's rate limiter throttles pinning each chat.
+func NewChatPinHandler(provider *messages.Provider) *ChatPinHandler {
+	return &ChatPinHandler{provider: provider}
+}
+
+// Tool returns the MCP tool definition
+func (h *ChatPinHandler) Tool() mcp.Tool {
+	opts := []mcp.ToolOption{
+		mcp.WithDescription("Pin one or more chats to the top of their chat list (the main list, or the archive for archived chats). Telegram limits the number of pinned chats per list. Returns the result per chat."),
+		mcp.WithIdempotentHintAnnotation(true),
+	}
+	opts = append(opts, batchChatOptions("pin")...)
+	return mcp.NewTool("PinChat", opts...)
+}
+
+// Handle processes the PinChat tool request
+func (h *ChatPinHandler) Handle(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	chatIDs, err := batchChatIDs(request)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	results := runChatBatch(ctx, h.provider, chatIDs, func(ctx context.Context, client *tg.Client, chatID int64) error {
+		return setDialogPinned(ctx, client, h.provider.Peers(), chatID, true)
+	})
+	return formatChatBatch(results, "Pinned %d out of %d chats successfully!"), nil
+}
+
+// ChatUnpinHandler handles the UnpinChat tool
+type ChatUnpinHandler struct {
+	provider *messages.Provider
+}
+
+// NewChatUnpinHandler creates a new ChatUnpinHandler.
+// The provider's rate limiter throttles unpinning each chat.
+func NewChatUnpinHandler(provider *messages.Provider) *ChatUnpinHandler {
+	return &ChatUnpinHandler{provider: provider}
+}
+
+// Tool returns the MCP tool definition
+func (h *ChatUnpinHandler) Tool() mcp.Tool {
+	opts := []mcp.ToolOption{
+		mcp.WithDescription("Unpin one or more chats from the top of their chat list. Returns the result per chat."),
+		mcp.WithIdempotentHintAnnotation(true),
+	}
+	opts = append(opts, batchChatOptions("unpin")...)
+	return mcp.NewTool("UnpinChat", opts...)
+}
+
+// Handle processes the UnpinChat tool request
+func (h *ChatUnpinHandler) Handle(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	chatIDs, err := batchChatIDs(request)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	results := runChatBatch(ctx, h.provider, chatIDs, func(ctx context.Context, client *tg.Client, chatID int64) error {
+		return setDialogPinned(ctx, client, h.provider.Peers(), chatID, false)
+	})
+	return formatChatBatch(results, "Unpinned %d out of %d chats successfully!"), nil
+}
+
+// setDialogPinned pins or unpins a chat in its chat list.
+func setDialogPinned(ctx context.Context, client *tg.Client, peers *tgclient.PeerCache, chatID int64, pinned bool) error {
+	return peers.WithPeer(ctx, client, chatID, func(peer tg.InputPeerClass) error {
+		_, err := client.MessagesToggleDialogPin(ctx, &tg.MessagesToggleDialogPinRequest{
+			Pinned: pinned,
+			Peer:   &tg.InputDialogPeer{Peer: peer},
+		})
+		if tgerr.Is(err, "PINNED_DIALOGS_TOO_MUCH") {
+			return fmt.Errorf("too many pinned chats in this list, unpin one first: %w", err)
+		}
+		return err
+	})
+}
@@ -0,0 +1,62 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gotd/td/tg"
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/tolmachov/mcp-telegram/internal/tgclient"
+)
+
+// chatRefOption declares a string parameter naming a chat by @username, username,
+// phone number, t.me link or "me", accepted in place of the numeric ID parameter
+// idParam.
+func chatRefOption(name, idParam, what string) mcp.ToolOption {
+	return mcp.WithString(name,
+		mcp.Description(fmt.Sprintf("%s as @username, username, phone number (+...), t.me link or \"me\" for Saved Messages, instead of %s", what, idParam)),
+	)
+}
+
+// savedMessagesOption declares the saved_messages flag, naming the user's own Saved
+// Messages in place of chat_id and chat.
+func savedMessagesOption() mcp.ToolOption {
+	return mcp.WithBoolean("saved_messages",
+		mcp.Description("Use your own Saved Messages as the chat, instead of chat_id or chat"),
+	)
+}
+
+// chatIDParam returns the chat ID from the numeric idParam or, when that is unset,
+// from refParam, resolving usernames, phone numbers and links. A string idParam such
+// as "me" is taken as a reference too, and saved_messages, for the "chat" parameter,
+// as "me". The error result names the reference when resolving it fails, so it can't
+// be mistaken for a failure of the tool's own request.
+func chatIDParam(ctx context.Context, client *tg.Client, peers *tgclient.PeerCache, request mcp.CallToolRequest, idParam, refParam string) (int64, *mcp.CallToolResult) {
+	if chatID := mcp.ParseInt64(request, idParam, 0); chatID != 0 {
+		return chatID, nil
+	}
+	ref := mcp.ParseString(request, refParam, "")
+	if s, ok := request.GetArguments()[idParam].(string); ok && ref == "" {
+		ref = s
+	}
+	if refParam == "chat" && mcp.ParseBoolean(request, "saved_messages", false) {
+		ref = "me"
+	}
+	if ref == "" {
+		return 0, mcp.NewToolResultError(fmt.Sprintf("%s or %s is required", idParam, refParam))
+	}
+	parsed, err := tgclient.ParseChatRef(ref)
+	if err != nil {
+		return 0, mcp.NewToolResultError(fmt.Sprintf("Invalid %s: %v", refParam, err))
+	}
+	if parsed.Kind == tgclient.ChatRefID {
+		// The tool resolves the peer itself, as for idParam
+		return parsed.ID, nil
+	}
+	chatID, _, err := peers.ResolvePeerFlexible(ctx, client, ref)
+	if err != nil {
+		return 0, toolError(fmt.Sprintf("resolve chat %q", ref), err)
+	}
+	return chatID, nil
+}
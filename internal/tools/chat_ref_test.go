@@ -0,0 +1,37 @@
+package tools
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestChatIDParam(t *testing.T) {
+	ctx := context.Background()
+
+	chatID, errResult := chatIDParam(ctx, nil, nil, callTool("SendMessage", map[string]any{"chat_id": float64(42), "chat": "@ignored"}), "chat_id", "chat")
+	if errResult != nil || chatID != 42 {
+		t.Errorf("chat_id = %d, %v, want 42", chatID, errResult)
+	}
+
+	// Channel links carry the ID, so they need no lookup
+	chatID, errResult = chatIDParam(ctx, nil, nil, callTool("ForwardMessage", map[string]any{"to_chat": "https://t.me/c/1234567890/5"}), "to_chat_id", "to_chat")
+	if errResult != nil || chatID != -1001234567890 {
+		t.Errorf("channel link = %d, %v, want -1001234567890", chatID, errResult)
+	}
+
+	for args, want := range map[string]string{
+		"":      "chat_id or chat is required",
+		"@ab":   "Invalid chat",
+		"x y z": "Invalid chat",
+	} {
+		params := map[string]any{}
+		if args != "" {
+			params["chat"] = args
+		}
+		_, errResult := chatIDParam(ctx, nil, nil, callTool("SendMessage", params), "chat_id", "chat")
+		if errResult == nil || !errResult.IsError || !strings.Contains(resultText(errResult), want) {
+			t.Errorf("chat %q: result = %+v, want error containing %q", args, errResult, want)
+		}
+	}
+}
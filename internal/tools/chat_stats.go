@@ -0,0 +1,262 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/tolmachov/mcp-telegram/internal/messages"
+	"github.com/tolmachov/mcp-telegram/internal/stats"
+)
+
+// compareToPrevious compares with the window of the same length right before.
+const compareToPrevious = "previous"
+
+// defaultStatsTopSenders is the default number of senders ChatStats lists per window.
+const defaultStatsTopSenders = 20
+
+// ChatStatsHandler handles the ChatStats tool
+type ChatStatsHandler struct {
+	provider     *messages.Provider
+	allowedPaths []string
+}
+
+// NewChatStatsHandler creates a new ChatStatsHandler
+func NewChatStatsHandler(provider *messages.Provider, allowedPaths []string) *ChatStatsHandler {
+	return &ChatStatsHandler{provider: provider, allowedPaths: allowedPaths}
+}
+
+// Tool returns the MCP tool definition
+func (h *ChatStatsHandler) Tool() mcp.Tool {
+	return mcp.NewTool("ChatStats",
+		mcp.WithDescription("Activity statistics of a chat over a time window, computed server-side instead of reading every message: message, text and media counts with the media share, messages per day, a histogram of messages per hour of the day (local time), the most linked domains, active days and the most active senders. With compare_to, the same statistics are computed for a second window and a diff is added: change in total messages (absolute and percent), per-sender changes sorted by change, new participants and participants who went silent."),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithNumber("chat_id",
+			mcp.Description("The chat ID (required unless source_path is set)"),
+		),
+		mcp.WithString("source_path",
+			mcp.Description("Compute the statistics from a Telegram Desktop JSON export (result.json or its directory) instead of fetching from Telegram. Without period, since or until, the whole export is covered. Must be within allowed paths"),
+		),
+		mcp.WithString("period",
+			mcp.Description("Window ending now: 'day', 'week', or 'month' (default: 'week'). Ignored when since is set"),
+		),
+		mcp.WithString("since",
+			mcp.Description("Window start, YYYY-MM-DD or YYYY-MM-DD HH:MM:SS (alternative to period)"),
+		),
+		mcp.WithString("until",
+			mcp.Description("Window end, YYYY-MM-DD (inclusive) or YYYY-MM-DD HH:MM:SS (default: now)"),
+		),
+		mcp.WithString("compare_to",
+			mcp.Description("Window to compare with: 'previous' for the window of the same length right before, or 'SINCE..UNTIL' with dates in the since/until format"),
+		),
+		mcp.WithNumber("top_n",
+			mcp.Description(fmt.Sprintf("Number of most active senders to list per window (default: %d, 0 = all); sender_count has the total", defaultStatsTopSenders)),
+		),
+	)
+}
+
+// chatStatsResult is the ChatStats output. Compare is set only with compare_to.
+type chatStatsResult struct {
+	ChatID  int64         `json:"chat_id"`
+	Window  stats.Window  `json:"window"`
+	Compare *stats.Window `json:"compare_window,omitempty"`
+	Diff    *stats.Diff   `json:"diff,omitempty"`
+}
+
+// Handle processes the ChatStats tool request
+func (h *ChatStatsHandler) Handle(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	chatID := mcp.ParseInt64(request, "chat_id", 0)
+	sourcePath := mcp.ParseString(request, "source_path", "")
+	if chatID == 0 && sourcePath == "" {
+		return mcp.NewToolResultError("chat_id or source_path is required"), nil
+	}
+
+	var export *messages.DesktopExport
+	if sourcePath != "" {
+		resolved, err := resolveReadPath(sourcePath, h.allowedPaths)
+		if err != nil {
+			return toolError("read export", err), nil
+		}
+		if export, err = messages.ImportDesktopExport(resolved); err != nil {
+			return toolError("read export", err), nil
+		}
+		chatID = export.ChatID
+	}
+
+	period := mcp.ParseString(request, "period", "")
+	sinceStr := mcp.ParseString(request, "since", "")
+	untilStr := mcp.ParseString(request, "until", "")
+	var since, until time.Time
+	var err error
+	if export != nil && period == "" && sinceStr == "" && untilStr == "" {
+		since, until = exportSpan(export.Messages)
+	} else {
+		if period == "" {
+			period = "week"
+		}
+		since, until, err = statsWindow(period, sinceStr, untilStr, time.Now())
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+	}
+
+	topN := mcp.ParseInt(request, "top_n", defaultStatsTopSenders)
+	if topN < 0 {
+		return mcp.NewToolResultError("top_n must not be negative"), nil
+	}
+	var token mcp.ProgressToken
+	if request.Params.Meta != nil {
+		token = request.Params.Meta.ProgressToken
+	}
+
+	var compareSince, compareUntil time.Time
+	compareTo := mcp.ParseString(request, "compare_to", "")
+	if compareTo != "" {
+		compareSince, compareUntil, err = compareWindow(compareTo, since, until)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+	}
+
+	collect := func(since, until time.Time, what string) (stats.Window, error) {
+		if export != nil {
+			return exportStats(export.Messages, since, until), nil
+		}
+		return h.collect(ctx, token, chatID, since, until, what)
+	}
+
+	window, err := collect(since, until, "")
+	if err != nil {
+		return toolError("get chat stats", err), nil
+	}
+	result := chatStatsResult{ChatID: chatID, Window: window}
+
+	if compareTo != "" {
+		previous, err := collect(compareSince, compareUntil, "compare_to ")
+		if err != nil {
+			return toolError("get chat stats for compare_to window", err), nil
+		}
+		diff := stats.Compare(window, previous)
+		previous.Senders = topSenders(previous.Senders, topN)
+		result.Compare = &previous
+		result.Diff = &diff
+	}
+	// Limited only now: the diff compares every sender
+	result.Window.Senders = topSenders(result.Window.Senders, topN)
+	return jsonResult(result)
+}
+
+// collect fetches the messages between since and until and aggregates them, sending
+// progress notifications like BackupMessages. what names the window in them.
+func (h *ChatStatsHandler) collect(ctx context.Context, token mcp.ProgressToken, chatID int64, since, until time.Time, what string) (stats.Window, error) {
+	progress := newBackupProgress(ctx, server.ServerFromContext(ctx), token, since, until, 0)
+	progress.Start()
+	defer progress.Stop()
+
+	agg := stats.NewAggregator(since, until)
+	result, err := h.provider.FetchAll(ctx, chatID, messages.FetchOptions{
+		MinDate:      since,
+		MaxDate:      until,
+		MaxDateExact: true,
+	}, func(batch int, collected int, earliestTime time.Time) {
+		progress.SetMessage(fmt.Sprintf("Fetching %smessages (batch %d, %d messages so far)...", what, batch, collected))
+		progress.SetMessageCount(collected)
+		if !earliestTime.IsZero() {
+			progress.UpdateEarliestTime(earliestTime)
+		}
+	})
+	if err != nil {
+		return stats.Window{}, err
+	}
+	for _, msg := range result.Messages {
+		agg.Add(msg)
+	}
+	return agg.Window(), nil
+}
+
+// exportSpan returns the dates of the first and last of msgs, in chronological order.
+func exportSpan(msgs []messages.Message) (since, until time.Time) {
+	if len(msgs) == 0 {
+		return time.Time{}, time.Time{}
+	}
+	return msgs[0].Date, msgs[len(msgs)-1].Date
+}
+
+// exportStats aggregates the messages of msgs dated between since and until, inclusive.
+func exportStats(msgs []messages.Message, since, until time.Time) stats.Window {
+	agg := stats.NewAggregator(since, until)
+	for _, msg := range msgs {
+		if !msg.Date.Before(since) && !msg.Date.After(until) {
+			agg.Add(msg)
+		}
+	}
+	return agg.Window()
+}
+
+// topSenders returns the n most active of senders, sorted most active first, or all
+// of them when n is 0.
+func topSenders(senders []stats.SenderStats, n int) []stats.SenderStats {
+	if n == 0 || len(senders) <= n {
+		return senders
+	}
+	return senders[:n]
+}
+
+// statsWindow returns the window given by since/until, or by period ending at now.
+// A date-only until includes that whole day.
+func statsWindow(period, sinceStr, untilStr string, now time.Time) (since, until time.Time, err error) {
+	until = now
+	if untilStr != "" {
+		t, hasTime, err := parseDate(untilStr)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("invalid until: %w", err)
+		}
+		if !hasTime {
+			t = t.AddDate(0, 0, 1).Add(-time.Second)
+		}
+		until = t
+	}
+
+	if sinceStr != "" {
+		since, _, err = parseDate(sinceStr)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("invalid since: %w", err)
+		}
+	} else {
+		switch period {
+		case "day":
+			since = until.Add(-24 * time.Hour)
+		case "week":
+			since = until.Add(-7 * 24 * time.Hour)
+		case "month":
+			since = until.Add(-30 * 24 * time.Hour)
+		default:
+			return time.Time{}, time.Time{}, fmt.Errorf("invalid period: %s (use 'day', 'week', or 'month')", period)
+		}
+	}
+
+	if !since.Before(until) {
+		return time.Time{}, time.Time{}, fmt.Errorf("since must be before until")
+	}
+	return since, until, nil
+}
+
+// compareWindow parses compare_to relative to the window between since and until.
+func compareWindow(compareTo string, since, until time.Time) (time.Time, time.Time, error) {
+	if compareTo == compareToPrevious {
+		return since.Add(-until.Sub(since)), since, nil
+	}
+	sinceStr, untilStr, ok := strings.Cut(compareTo, "..")
+	if !ok || sinceStr == "" || untilStr == "" {
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid compare_to %q: use 'previous' or 'SINCE..UNTIL'", compareTo)
+	}
+	s, u, err := statsWindow("", strings.TrimSpace(sinceStr), strings.TrimSpace(untilStr), time.Time{})
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid compare_to: %w", err)
+	}
+	return s, u, nil
+}
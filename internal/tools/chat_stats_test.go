@@ -0,0 +1,145 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/tolmachov/mcp-telegram/internal/messages"
+	"github.com/tolmachov/mcp-telegram/internal/stats"
+)
+
+func TestStatsWindow(t *testing.T) {
+	now := time.Date(2024, 3, 15, 12, 0, 0, 0, time.Local)
+	day := func(d int) time.Time { return time.Date(2024, 3, d, 0, 0, 0, 0, time.Local) }
+
+	tests := []struct {
+		name                 string
+		period, since, until string
+		wantSince, wantUntil time.Time
+		wantErr              bool
+	}{
+		{name: "period ends now", period: "week", wantSince: now.Add(-7 * 24 * time.Hour), wantUntil: now},
+		{name: "date-only until covers the day", since: "2024-03-01", until: "2024-03-07", wantSince: day(1), wantUntil: day(8).Add(-time.Second)},
+		{name: "exact until", since: "2024-03-01", until: "2024-03-07 10:00:00", wantSince: day(1), wantUntil: day(7).Add(10 * time.Hour)},
+		{name: "since overrides period", period: "day", since: "2024-03-10", wantSince: day(10), wantUntil: now},
+		{name: "bad period", period: "year", wantErr: true},
+		{name: "since after until", since: "2024-03-10", until: "2024-03-01", wantErr: true},
+		{name: "bad date", since: "yesterday", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			since, until, err := statsWindow(tt.period, tt.since, tt.until, now)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("statsWindow() = %v..%v, want error", since, until)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("statsWindow() error = %v", err)
+			}
+			if !since.Equal(tt.wantSince) || !until.Equal(tt.wantUntil) {
+				t.Errorf("statsWindow() = %v..%v, want %v..%v", since, until, tt.wantSince, tt.wantUntil)
+			}
+		})
+	}
+}
+
+func TestCompareWindow(t *testing.T) {
+	since := time.Date(2024, 3, 8, 0, 0, 0, 0, time.Local)
+	until := time.Date(2024, 3, 15, 0, 0, 0, 0, time.Local)
+
+	s, u, err := compareWindow("previous", since, until)
+	if err != nil {
+		t.Fatalf("compareWindow(previous) error = %v", err)
+	}
+	if !s.Equal(time.Date(2024, 3, 1, 0, 0, 0, 0, time.Local)) || !u.Equal(since) {
+		t.Errorf("compareWindow(previous) = %v..%v", s, u)
+	}
+
+	s, u, err = compareWindow("2024-01-01..2024-01-31", since, until)
+	if err != nil {
+		t.Fatalf("compareWindow(range) error = %v", err)
+	}
+	if !s.Equal(time.Date(2024, 1, 1, 0, 0, 0, 0, time.Local)) || !u.Equal(time.Date(2024, 2, 1, 0, 0, 0, 0, time.Local).Add(-time.Second)) {
+		t.Errorf("compareWindow(range) = %v..%v", s, u)
+	}
+
+	for _, bad := range []string{"last", "2024-01-01", "2024-01-01..", "2024-02-01..2024-01-01"} {
+		if _, _, err := compareWindow(bad, since, until); err == nil {
+			t.Errorf("compareWindow(%q) succeeded, want error", bad)
+		}
+	}
+}
+
+func TestTopSenders(t *testing.T) {
+	senders := []stats.SenderStats{{ID: 1, Messages: 5}, {ID: 2, Messages: 3}, {ID: 3, Messages: 1}}
+	if got := topSenders(senders, 2); len(got) != 2 || got[1].ID != 2 {
+		t.Errorf("topSenders(2) = %+v, want the two most active", got)
+	}
+	if got := topSenders(senders, 0); len(got) != 3 {
+		t.Errorf("topSenders(0) = %+v, want all", got)
+	}
+	if got := topSenders(senders, 10); len(got) != 3 {
+		t.Errorf("topSenders(10) = %+v, want all", got)
+	}
+}
+
+func TestChatStatsFromDesktopExport(t *testing.T) {
+	dir := t.TempDir()
+	export := `{"name": "Weekend Plans", "type": "private_group", "id": 4815162342, "messages": [
+		{"id": 1, "type": "message", "date": "2023-06-10T12:00:00", "date_unixtime": "1686398400", "from": "Alice", "from_id": "user1001", "text": "Hiking on Saturday?"},
+		{"id": 2, "type": "message", "date": "2023-06-10T12:05:00", "date_unixtime": "1686398700", "from": "Bob", "from_id": "user1002", "text": "Sure"},
+		{"id": 3, "type": "message", "date": "2023-06-12T09:00:00", "date_unixtime": "1686560400", "from": "Alice", "from_id": "user1001", "text": "See you there"}
+	]}`
+	if err := os.WriteFile(filepath.Join(dir, messages.DesktopExportFile), []byte(export), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	h := NewChatStatsHandler(nil, []string{dir})
+
+	tests := []struct {
+		name         string
+		args         map[string]any
+		wantMessages int
+	}{
+		{name: "whole export", args: map[string]any{"source_path": dir}, wantMessages: 3},
+		{name: "date range", args: map[string]any{"source_path": dir, "since": "2023-06-11", "until": "2023-06-12"}, wantMessages: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var request mcp.CallToolRequest
+			request.Params.Arguments = tt.args
+			result, err := h.Handle(context.Background(), request)
+			if err != nil {
+				t.Fatalf("Handle: %v", err)
+			}
+			if result.IsError {
+				t.Fatalf("unexpected error result: %s", resultText(result))
+			}
+			var got chatStatsResult
+			if err := json.Unmarshal([]byte(resultText(result)), &got); err != nil {
+				t.Fatalf("decoding result: %v", err)
+			}
+			if got.ChatID != 4815162342 || got.Window.Messages != tt.wantMessages {
+				t.Errorf("chat %d, %d messages; want chat 4815162342, %d messages", got.ChatID, got.Window.Messages, tt.wantMessages)
+			}
+		})
+	}
+
+	var request mcp.CallToolRequest
+	request.Params.Arguments = map[string]any{"source_path": t.TempDir()}
+	result, err := h.Handle(context.Background(), request)
+	if err != nil {
+		t.Fatalf("Handle: %v", err)
+	}
+	if !result.IsError || !strings.Contains(resultText(result), "not within allowed directories") {
+		t.Errorf("result = %q, want the path outside allowed directories rejected", resultText(result))
+	}
+}
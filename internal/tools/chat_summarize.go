@@ -5,26 +5,38 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/gotd/td/tg"
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
 
 	"github.com/tolmachov/mcp-telegram/internal/messages"
 	"github.com/tolmachov/mcp-telegram/internal/summarize"
+	"github.com/tolmachov/mcp-telegram/internal/tgclient"
+)
+
+// SummarizeChat output modes.
+const (
+	outputText       = "text"
+	outputStructured = "structured"
 )
 
 // ChatSummarizeHandler handles the SummarizeChat tool
 type ChatSummarizeHandler struct {
-	msgProvider *messages.Provider
-	mcpServer   *server.MCPServer
-	config      summarize.Config
+	client       *tg.Client
+	msgProvider  *messages.Provider
+	mcpServer    *server.MCPServer
+	config       summarize.Config
+	allowedPaths []string
 }
 
 // NewChatSummarizeHandler creates a new ChatSummarizeHandler
-func NewChatSummarizeHandler(msgProvider *messages.Provider, mcpServer *server.MCPServer, config summarize.Config) *ChatSummarizeHandler {
+func NewChatSummarizeHandler(client *tg.Client, msgProvider *messages.Provider, mcpServer *server.MCPServer, config summarize.Config, allowedPaths []string) *ChatSummarizeHandler {
 	return &ChatSummarizeHandler{
-		msgProvider: msgProvider,
-		mcpServer:   mcpServer,
-		config:      config,
+		client:       client,
+		msgProvider:  msgProvider,
+		mcpServer:    mcpServer,
+		config:       config,
+		allowedPaths: allowedPaths,
 	}
 }
 
@@ -32,12 +44,19 @@ func NewChatSummarizeHandler(msgProvider *messages.Provider, mcpServer *server.M
 func (h *ChatSummarizeHandler) Tool() mcp.Tool {
 	return mcp.NewTool("SummarizeChat",
 		mcp.WithDescription("Summarize messages from a Telegram chat using rolling/incremental summarization with AI."),
-		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithDestructiveHintAnnotation(false),
 		mcp.WithOpenWorldHintAnnotation(true),
 		mcp.WithNumber("chat_id",
-			mcp.Description("The chat ID to summarize"),
-			mcp.Required(),
+			mcp.Description("The chat ID to summarize (required unless chat or source_path is set)"),
+		),
+		chatRefOption("chat", "chat_id", "The chat to summarize"),
+		mcp.WithString("source_path",
+			mcp.Description("Summarize a Telegram Desktop JSON export (result.json or its directory) instead of fetching from Telegram. Must be within allowed paths"),
+		),
+		mcp.WithNumber("thread_message_id",
+			mcp.Description("Summarize only the comments on this channel post, or the replies in this thread of a group, as GetReplies returns them. Without period or since, the whole thread is summarized"),
 		),
+		topicIDOption("Summarize"),
 		mcp.WithString("goal",
 			mcp.Description("What you want from the summary. Examples: 'key points and decisions', 'extract all action items and deadlines', 'analyze sentiment and mood', 'identify top 5 discussed topics', 'create meeting minutes', 'find all decisions made', 'summarize bug discussions', 'track project progress'"),
 			mcp.Required(),
@@ -46,7 +65,29 @@ func (h *ChatSummarizeHandler) Tool() mcp.Tool {
 			mcp.Description("Time period: 'day', 'week', or 'month' (default: 'month')"),
 		),
 		mcp.WithString("since",
-			mcp.Description("ISO 8601 date to start from (alternative to period, e.g., '2024-01-15')"),
+			mcp.Description("ISO 8601 date to start from (alternative to period, e.g., '2024-01-15'), or 'last_read' to summarize what arrived after your read position in the chat"),
+		),
+		mcp.WithString("until",
+			mcp.Description("ISO 8601 date or time to summarize up to, with the same formats as since (e.g., '2024-01-31', inclusive, or '2024-01-31T12:00:00Z'). With period but no since, the period counts back from until"),
+		),
+		mcp.WithBoolean("mark_read",
+			mcp.Description("With since='last_read', mark the chat read up to the newest summarized message afterwards (default: false)"),
+		),
+		mcp.WithBoolean("merge_consecutive",
+			mcp.Description("Merge rapid-fire messages from the same sender into one before summarizing (default: true)"),
+		),
+		mcp.WithBoolean("auto_translate_summary",
+			mcp.Description("If the summary comes back in a different language than the goal, translate it with one extra model call (default: true; not applied to structured output)"),
+		),
+		mcp.WithString("output",
+			mcp.Description("'text' (default) for a free-text summary, or 'structured' for a JSON object with topics, decisions, action_items (with assignee and deadline when stated) and open_questions"),
+			mcp.Enum(outputText, outputStructured),
+		),
+		mcp.WithBoolean("citations",
+			mcp.Description("Cite source message IDs after factual claims, e.g. [#123]. Cited IDs are checked against the summarized messages; in channels and supergroups they become t.me links (default: false)"),
+		),
+		mcp.WithBoolean("anonymize_senders",
+			mcp.Description("Call senders Person A, Person B and so on instead of by name, and ask the model to leave out names found in the messages, for privacy-sensitive summaries (default: false)"),
 		),
 	)
 }
@@ -54,8 +95,17 @@ func (h *ChatSummarizeHandler) Tool() mcp.Tool {
 // Handle processes the SummarizeChat tool request
 func (h *ChatSummarizeHandler) Handle(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	chatID := mcp.ParseInt64(request, "chat_id", 0)
-	if chatID == 0 {
-		return mcp.NewToolResultError("chat_id is required"), nil
+	sourcePath := mcp.ParseString(request, "source_path", "")
+	chatRef := mcp.ParseString(request, "chat", "")
+	if chatID == 0 && chatRef == "" && sourcePath == "" {
+		return mcp.NewToolResultError("chat_id, chat or source_path is required"), nil
+	}
+	if chatID == 0 && sourcePath == "" {
+		var errResult *mcp.CallToolResult
+		chatID, errResult = chatIDParam(ctx, h.client, h.msgProvider.Peers(), request, "chat_id", "chat")
+		if errResult != nil {
+			return errResult, nil
+		}
 	}
 
 	goal := mcp.ParseString(request, "goal", "")
@@ -63,13 +113,63 @@ func (h *ChatSummarizeHandler) Handle(ctx context.Context, request mcp.CallToolR
 		return mcp.NewToolResultError("goal is required"), nil
 	}
 
-	since, err := h.parseSinceTime(request)
+	output := mcp.ParseString(request, "output", outputText)
+	if output != outputText && output != outputStructured {
+		return mcp.NewToolResultError(fmt.Sprintf("invalid output: %s (use '%s' or '%s')", output, outputText, outputStructured)), nil
+	}
+
+	lastRead := mcp.ParseString(request, "since", "") == messages.SinceLastRead
+	markRead := mcp.ParseBoolean(request, "mark_read", false)
+	if lastRead && sourcePath != "" {
+		return mcp.NewToolResultError("since='last_read' needs chat_id; exports have no read state"), nil
+	}
+	if markRead && !lastRead {
+		return mcp.NewToolResultError("mark_read requires since='last_read'"), nil
+	}
+	threadID := mcp.ParseInt(request, "thread_message_id", 0)
+	if threadID > 0 && (sourcePath != "" || lastRead) {
+		return mcp.NewToolResultError("thread_message_id can't be combined with source_path or since='last_read'"), nil
+	}
+	if mcp.ParseInt(request, "topic_id", 0) != 0 {
+		if threadID > 0 || sourcePath != "" || lastRead {
+			return mcp.NewToolResultError("topic_id can't be combined with thread_message_id, source_path or since='last_read'"), nil
+		}
+		topic, errResult := topicParam(ctx, h.client, h.msgProvider.Peers(), request, chatID)
+		if errResult != nil {
+			return errResult, nil
+		}
+		threadID = topic.ID
+	}
+
+	until, err := parseUntilTime(request)
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("Invalid time parameters: %v", err)), nil
 	}
 
+	// In last_read mode messages are selected by ID instead of date
+	var since time.Time
+	var readInboxMaxID int
+	if lastRead {
+		readInboxMaxID, err = h.msgProvider.ReadInboxMaxID(ctx, chatID)
+		if err != nil {
+			return toolError("get read position", err), nil
+		}
+	} else {
+		since, err = h.parseSinceTime(request, until)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Invalid time parameters: %v", err)), nil
+		}
+		// A thread is summarized whole unless a window is given
+		if mcp.ParseInt(request, "thread_message_id", 0) > 0 && mcp.ParseString(request, "period", "") == "" && mcp.ParseString(request, "since", "") == "" {
+			since = time.Time{}
+		}
+		if !until.IsZero() && until.Before(since) {
+			return mcp.NewToolResultError(fmt.Sprintf("until (%s) is before since (%s)", until.Format(time.RFC3339), since.Format(time.RFC3339))), nil
+		}
+	}
+
 	// Create a provider based on configuration
-	provider := h.createProvider(ctx)
+	provider := newSummarizeProvider(h.mcpServer, h.config)
 
 	summarizer := summarize.NewSummarizer(provider, h.msgProvider, h.config.BatchTokens)
 
@@ -85,56 +185,191 @@ func (h *ChatSummarizeHandler) Handle(ctx context.Context, request mcp.CallToolR
 		}
 	}
 
-	result, err := summarizer.Summarize(ctx, chatID, goal, since, onProgress)
-	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Summarization failed: %v", err)), nil
+	opts := summarize.Options{
+		Goal:             goal,
+		Since:            since,
+		Until:            until,
+		MinID:            readInboxMaxID,
+		ThreadID:         threadID,
+		MergeConsecutive: mcp.ParseBoolean(request, "merge_consecutive", true),
+		MergeGap:         h.config.MergeGap,
+		Deadline:         h.config.Deadline,
+		AutoTranslate:    mcp.ParseBoolean(request, "auto_translate_summary", true),
+		Structured:       output == outputStructured,
+		Citations:        mcp.ParseBoolean(request, "citations", false),
+		AnonymizeSenders: mcp.ParseBoolean(request, "anonymize_senders", false),
 	}
 
-	return mcp.NewToolResultText(result), nil
-}
+	var result summarize.Result
+	if sourcePath != "" {
+		result, err = h.summarizeExport(ctx, summarizer, sourcePath, request, opts, onProgress)
+	} else {
+		result, err = summarizer.Summarize(ctx, chatID, opts, onProgress)
+	}
+	if err != nil {
+		return toolError("summarize chat", err), nil
+	}
 
-func (h *ChatSummarizeHandler) parseSinceTime(request mcp.CallToolRequest) (time.Time, error) {
-	sinceStr := mcp.ParseString(request, "since", "")
-	if sinceStr != "" {
-		// Try parsing ISO 8601 date
-		t, err := time.Parse("2006-01-02", sinceStr)
-		if err != nil {
-			// Try with time
-			t, err = time.Parse(time.RFC3339, sinceStr)
-			if err != nil {
-				return time.Time{}, fmt.Errorf("invalid since format, use ISO 8601 (e.g., '2024-01-15' or '2024-01-15T00:00:00Z')")
+	var readStatus string
+	if lastRead {
+		switch {
+		case !markRead || result.Stats.LastID == 0:
+			// Nothing to mark
+		case result.Stats.Partial:
+			readStatus = "Not marked read: the summary is partial"
+		default:
+			if err := markChatRead(ctx, h.client, h.msgProvider.Peers(), chatID, result.Stats.LastID); err != nil {
+				readStatus = fmt.Sprintf("Marking read failed: %v", tgclient.ClassifyError(err))
+			} else {
+				readStatus = "Marked read"
 			}
 		}
-		return t, nil
+	}
+
+	if result.Structured != nil {
+		structured := structuredSummaryResult{
+			StructuredSummary: result.Structured,
+			FirstMessage:      result.Stats.First.Format(time.RFC3339),
+			LastMessage:       result.Stats.Last.Format(time.RFC3339),
+			Stats:             result.Stats.String(),
+			Partial:           result.Stats.Partial,
+			ReadStatus:        readStatus,
+		}
+		if lastRead {
+			structured.Unread = &result.Stats.Unread
+		}
+		return jsonResult(structured)
+	}
+
+	text := result.Summary
+	if result.Stats.StructuredFailed {
+		text = "[Warning: the model didn't return valid JSON, even after a repair attempt; the summary is returned as text]\n\n" + text
+	}
+	if !result.Stats.First.IsZero() {
+		text = rangeHeader(result.Stats) + "\n\n" + text
+	}
+	if result.Stats.TotalBatches > 0 {
+		text += fmt.Sprintf("\n\n[Stats: %s]", result.Stats)
+	}
+	if lastRead {
+		text += fmt.Sprintf("\n\n[Since last read: %d unread messages covered]", result.Stats.Unread)
+		if readStatus != "" {
+			text += "\n[" + readStatus + "]"
+		}
+	}
+	return mcp.NewToolResultText(text), nil
+}
+
+// structuredSummaryResult is the JSON result of output='structured'.
+type structuredSummaryResult struct {
+	*summarize.StructuredSummary
+	FirstMessage string `json:"first_message"` // date of the oldest summarized message
+	LastMessage  string `json:"last_message"`  // date of the newest summarized message
+	Stats        string `json:"stats"`
+	Partial      bool   `json:"partial,omitempty"`
+	Unread       *int   `json:"unread,omitempty"`      // in last_read mode
+	ReadStatus   string `json:"read_status,omitempty"` // with mark_read
+}
+
+// summarizeExport summarizes a Telegram Desktop export without contacting Telegram.
+// Unlike live chats, exports are summarized in full unless a period, since or until is given.
+func (h *ChatSummarizeHandler) summarizeExport(ctx context.Context, summarizer *summarize.Summarizer, sourcePath string, request mcp.CallToolRequest, opts summarize.Options, onProgress summarize.ProgressCallback) (summarize.Result, error) {
+	resolved, err := resolveReadPath(sourcePath, h.allowedPaths)
+	if err != nil {
+		return summarize.Result{}, err
+	}
+
+	export, err := messages.ImportDesktopExport(resolved)
+	if err != nil {
+		return summarize.Result{}, err
+	}
+
+	if mcp.ParseString(request, "period", "") == "" && mcp.ParseString(request, "since", "") == "" && opts.Until.IsZero() {
+		opts.Since = time.Time{}
+	}
+	return summarizer.SummarizeMessages(ctx, export.Messages, opts, onProgress)
+}
+
+// rangeHeader states the range of messages a summary covers.
+func rangeHeader(st summarize.Stats) string {
+	const layout = "2006-01-02 15:04"
+	return fmt.Sprintf("[Summarized messages from %s to %s]", st.First.Format(layout), st.Last.Format(layout))
+}
+
+// parseSummaryDate parses a since or until value as an ISO 8601 date or RFC 3339
+// time, reporting whether it had a time of day.
+func parseSummaryDate(name, value string) (t time.Time, hasTime bool, err error) {
+	if t, err := time.Parse("2006-01-02", value); err == nil {
+		return t, false, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, true, nil
+	}
+	return time.Time{}, false, fmt.Errorf("invalid %s format, use ISO 8601 (e.g., '2024-01-15' or '2024-01-15T00:00:00Z')", name)
+}
+
+// parseUntilTime returns the last instant to summarize, or zero for no limit. A
+// date-only until covers its whole day.
+func parseUntilTime(request mcp.CallToolRequest) (time.Time, error) {
+	untilStr := mcp.ParseString(request, "until", "")
+	if untilStr == "" {
+		return time.Time{}, nil
+	}
+	t, hasTime, err := parseSummaryDate("until", untilStr)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if !hasTime {
+		t = t.AddDate(0, 0, 1).Add(-time.Second)
+	}
+	return t, nil
+}
+
+// parseSinceTime returns the first instant to summarize, from since or else from
+// period, counted back from until when set and from now otherwise.
+func (h *ChatSummarizeHandler) parseSinceTime(request mcp.CallToolRequest, until time.Time) (time.Time, error) {
+	if sinceStr := mcp.ParseString(request, "since", ""); sinceStr != "" {
+		t, _, err := parseSummaryDate("since", sinceStr)
+		return t, err
 	}
 
 	period := mcp.ParseString(request, "period", "month")
-	now := time.Now()
+	end := time.Now()
+	if !until.IsZero() {
+		end = until
+	}
 
 	switch period {
 	case "day":
-		return now.Add(-24 * time.Hour), nil
+		return end.Add(-24 * time.Hour), nil
 	case "week":
-		return now.Add(-7 * 24 * time.Hour), nil
+		return end.Add(-7 * 24 * time.Hour), nil
 	case "month":
-		return now.Add(-30 * 24 * time.Hour), nil
+		return end.Add(-30 * 24 * time.Hour), nil
 	default:
 		return time.Time{}, fmt.Errorf("invalid period: %s (use 'day', 'week', or 'month')", period)
 	}
 }
 
-func (h *ChatSummarizeHandler) createProvider(_ context.Context) summarize.Provider {
-	switch h.config.Provider {
-	case summarize.ProviderSampling:
-		return summarize.NewSamplingProvider(h.mcpServer)
-	case summarize.ProviderGemini:
-		return summarize.NewGeminiProvider(h.config.GeminiAPIKey, h.config.Model)
+// newSummarizeProvider creates the summarization provider of config; HTTP providers
+// retry failed calls.
+func newSummarizeProvider(mcpServer *server.MCPServer, config summarize.Config) summarize.Provider {
+	var provider summarize.Provider
+	switch config.Provider {
 	case summarize.ProviderOllama:
-		return summarize.NewOllamaProvider(h.config.OllamaURL, h.config.Model)
+		provider = summarize.NewOllamaProvider(config.OllamaURL, config.Model, config.RequestTimeout)
+	case summarize.ProviderGemini:
+		provider = summarize.NewGeminiProvider(config.GeminiAPIKey, config.Model, config.RequestTimeout)
 	case summarize.ProviderAnthropic:
-		return summarize.NewAnthropicProvider(h.config.AnthropicAPIKey, h.config.Model)
+		provider = summarize.NewAnthropicProvider(config.AnthropicAPIKey, config.Model, config.RequestTimeout)
+	case summarize.ProviderOpenAI:
+		provider = summarize.NewOpenAIProvider(config.OpenAIBaseURL, config.OpenAIAPIKey, config.Model, config.RequestTimeout)
 	default:
-		// Default to sampling
-		return summarize.NewSamplingProvider(h.mcpServer)
+		// Sampling, also the default, goes through the MCP client and isn't retried
+		return summarize.NewSamplingProvider(mcpServer, config.RequestTimeout)
 	}
+	return summarize.WithRetry(provider, summarize.RetryPolicy{
+		MaxAttempts: config.RetryAttempts,
+		BaseDelay:   config.RetryBaseDelay,
+	})
 }
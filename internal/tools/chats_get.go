@@ -27,11 +27,23 @@ func (h *ChatsGetHandler) Tool() mcp.Tool {
 	return mcp.NewTool("GetChats",
 		mcp.WithDescription("Get a list of all chats, groups, and channels."),
 		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithBoolean("include_archived",
+			mcp.Description("Include chats from the archive folder (default: true)"),
+		),
+		mcp.WithNumber("folder_id",
+			mcp.Description("Only list the chats of this folder; folder IDs are in the telegram://folders resource"),
+		),
+		mcp.WithBoolean("paginate_blocks",
+			mcp.Description("Split the list across several content blocks, each a standalone JSON object with part, total_parts and its chats; useful for accounts with thousands of chats (default: false)"),
+		),
+		mcp.WithNumber("chats_per_block",
+			mcp.Description(fmt.Sprintf("Chats per content block when paginate_blocks is set (default: %d)", tgdata.DefaultChatsPerPart)),
+		),
 	)
 }
 
 // Handle processes the GetChats tool request
-func (h *ChatsGetHandler) Handle(ctx context.Context, _ mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+func (h *ChatsGetHandler) Handle(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	onProgress := func(current int, message string) {
 		if srv := server.ServerFromContext(ctx); srv != nil {
 			_ = srv.SendNotificationToClient(ctx, "notifications/progress", map[string]any{
@@ -41,15 +53,27 @@ func (h *ChatsGetHandler) Handle(ctx context.Context, _ mcp.CallToolRequest) (*m
 		}
 	}
 
-	result, err := tgdata.GetChats(ctx, h.client, onProgress)
+	result, err := tgdata.GetChats(ctx, h.client, mcp.ParseBoolean(request, "include_archived", true), mcp.ParseInt(request, "folder_id", 0), onProgress)
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Failed to get chats: %v", err)), nil
+		return toolError("get chats", err), nil
 	}
 
-	data, err := json.MarshalIndent(result, "", "  ")
-	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal chats: %v", err)), nil
+	if !mcp.ParseBoolean(request, "paginate_blocks", false) {
+		data, err := json.MarshalIndent(result, "", "  ")
+		if err != nil {
+			return toolError("marshal chats", err), nil
+		}
+		return mcp.NewToolResultText(string(data)), nil
 	}
 
-	return mcp.NewToolResultText(string(data)), nil
+	parts := tgdata.SplitChats(result, mcp.ParseInt(request, "chats_per_block", tgdata.DefaultChatsPerPart))
+	content := make([]mcp.Content, 0, len(parts))
+	for _, part := range parts {
+		data, err := json.MarshalIndent(part, "", "  ")
+		if err != nil {
+			return toolError("marshal chats", err), nil
+		}
+		content = append(content, mcp.NewTextContent(string(data)))
+	}
+	return &mcp.CallToolResult{Content: content}, nil
 }
@@ -6,30 +6,55 @@ import (
 	"fmt"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/gotd/td/tg"
 	"github.com/lithammer/fuzzysearch/fuzzy"
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
 
+	"github.com/tolmachov/mcp-telegram/internal/messages"
 	"github.com/tolmachov/mcp-telegram/internal/tgclient"
 	"github.com/tolmachov/mcp-telegram/internal/tgdata"
 )
 
+// DefaultNameMatchThreshold is the fuzzy match score above which a name match is
+// considered low-confidence. When every result scores above it, SearchChats may fall
+// back to searching message content.
+const DefaultNameMatchThreshold = 10
+
+// contentSearchLimit caps the number of message hits fetched by the content search.
+const contentSearchLimit = 20
+
+// contentSnippetRunes limits the length of a content match snippet.
+const contentSnippetRunes = 120
+
+// Values of SearchResult.MatchedBy
+const (
+	matchedByName    = "name"
+	matchedByContent = "content"
+)
+
 // ChatsSearchHandler handles the SearchChats tool
 type ChatsSearchHandler struct {
-	client *tg.Client
+	provider      *messages.Provider
+	nameThreshold int
 }
 
-// NewChatsSearchHandler creates a new ChatsSearchHandler
-func NewChatsSearchHandler(client *tg.Client) *ChatsSearchHandler {
-	return &ChatsSearchHandler{client: client}
+// NewChatsSearchHandler creates a new ChatsSearchHandler.
+// All its requests, including the pages of the chat list, go through the provider's
+// rate limiter.
+func NewChatsSearchHandler(provider *messages.Provider) *ChatsSearchHandler {
+	return &ChatsSearchHandler{
+		provider:      provider,
+		nameThreshold: DefaultNameMatchThreshold,
+	}
 }
 
 // Tool returns the MCP tool definition
 func (h *ChatsSearchHandler) Tool() mcp.Tool {
 	return mcp.NewTool("SearchChats",
-		mcp.WithDescription("Search for chats, groups, and channels by name using fuzzy matching."),
+		mcp.WithDescription("Search for chats, groups, and channels by name using fuzzy matching. If the query is a phrase from a conversation rather than a chat name, set search_messages to also find chats by message content when name matches are weak."),
 		mcp.WithReadOnlyHintAnnotation(true),
 		mcp.WithString("query",
 			mcp.Required(),
@@ -38,13 +63,23 @@ func (h *ChatsSearchHandler) Tool() mcp.Tool {
 		mcp.WithNumber("limit",
 			mcp.Description("Maximum number of results to return (default: 10, max: 50)"),
 		),
+		mcp.WithBoolean("search_messages",
+			mcp.Description("When no chat name matches well, search message text for the query and return the chats it was found in, with a matching snippet (default: false)"),
+		),
+		mcp.WithBoolean("include_archived",
+			mcp.Description("Also search chats in the archive folder (default: true)"),
+		),
 	)
 }
 
 // SearchResult represents a single search result with a match score
 type SearchResult struct {
 	tgdata.ChatInfo
-	Score int `json:"score"` // Lower is a better match (Levenshtein distance)
+	Score       int        `json:"score"`      // Lower is a better match (Levenshtein distance)
+	MatchedBy   string     `json:"matched_by"` // "name" or "content"
+	Snippet     string     `json:"snippet,omitempty"`
+	MessageID   int        `json:"message_id,omitempty"`
+	MessageDate *time.Time `json:"message_date,omitempty"`
 }
 
 // SearchResultsList represents the search results
@@ -69,6 +104,9 @@ func (h *ChatsSearchHandler) Handle(ctx context.Context, request mcp.CallToolReq
 		limit = 50
 	}
 
+	// Page through the chat list and search through the shared rate limiter
+	paced := h.provider.Paced()
+
 	// Get all user's chats for local fuzzy search first
 	onProgress := func(current int, message string) {
 		if srv := server.ServerFromContext(ctx); srv != nil {
@@ -78,9 +116,9 @@ func (h *ChatsSearchHandler) Handle(ctx context.Context, request mcp.CallToolReq
 			})
 		}
 	}
-	chatsList, err := tgdata.GetChats(ctx, h.client, onProgress)
+	chatsList, err := tgdata.GetChats(ctx, paced, mcp.ParseBoolean(request, "include_archived", true), 0, onProgress)
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Failed to get chats: %v", err)), nil
+		return toolError("get chats", err), nil
 	}
 
 	// Perform local fuzzy search first
@@ -88,12 +126,21 @@ func (h *ChatsSearchHandler) Handle(ctx context.Context, request mcp.CallToolReq
 
 	// Only search globally if we have room for more results
 	if len(results) < limit {
-		globalResults, err := h.searchGlobal(ctx, query)
+		globalResults, err := h.searchGlobal(ctx, paced, query)
 		if err == nil && len(globalResults) > 0 {
 			results = h.addGlobalResults(query, results, globalResults, limit)
 		}
 	}
 
+	// Fall back to message content when no name matched convincingly
+	if mcp.ParseBoolean(request, "search_messages", false) && lowConfidence(results, h.nameThreshold) {
+		hits, err := tgdata.SearchMessagesGlobal(ctx, paced, query, contentSearchLimit)
+		if err != nil {
+			return toolError("search messages", err), nil
+		}
+		results = mergeContentResults(results, contentResults(query, hits), limit)
+	}
+
 	resultsList := SearchResultsList{
 		Query:   query,
 		Results: results,
@@ -102,15 +149,15 @@ func (h *ChatsSearchHandler) Handle(ctx context.Context, request mcp.CallToolReq
 
 	data, err := json.MarshalIndent(resultsList, "", "  ")
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal results: %v", err)), nil
+		return toolError("marshal results", err), nil
 	}
 
 	return mcp.NewToolResultText(string(data)), nil
 }
 
 // searchGlobal performs Telegram's global search by username
-func (h *ChatsSearchHandler) searchGlobal(ctx context.Context, query string) ([]tgdata.ChatInfo, error) {
-	found, err := h.client.ContactsSearch(ctx, &tg.ContactsSearchRequest{
+func (h *ChatsSearchHandler) searchGlobal(ctx context.Context, client *tg.Client, query string) ([]tgdata.ChatInfo, error) {
+	found, err := client.ContactsSearch(ctx, &tg.ContactsSearchRequest{
 		Q:     query,
 		Limit: 20,
 	})
@@ -154,8 +201,7 @@ func (h *ChatsSearchHandler) searchGlobal(ctx context.Context, query string) ([]
 			if c.Megagroup {
 				chatType = "supergroup"
 			}
-			// Convert to user-facing format with -100 prefix
-			id := -1000000000000 - c.ID
+			id := tgclient.ChannelDialogID(c.ID)
 			results = append(results, tgdata.ChatInfo{
 				ID:       id,
 				Type:     chatType,
@@ -196,8 +242,9 @@ func (h *ChatsSearchHandler) fuzzySearchLocal(query string, chats []tgdata.ChatI
 			if !seen[chat.ID] {
 				seen[chat.ID] = true
 				results = append(results, SearchResult{
-					ChatInfo: chat,
-					Score:    match.Distance,
+					ChatInfo:  chat,
+					Score:     match.Distance,
+					MatchedBy: matchedByName,
 				})
 			}
 		}
@@ -219,7 +266,6 @@ func (h *ChatsSearchHandler) addGlobalResults(query string, localResults []Searc
 	}
 
 	results := localResults
-	queryLower := strings.ToLower(query)
 
 	for _, chat := range globalChats {
 		if len(results) >= limit {
@@ -227,13 +273,114 @@ func (h *ChatsSearchHandler) addGlobalResults(query string, localResults []Searc
 		}
 		if !seen[chat.ID] {
 			seen[chat.ID] = true
-			distance := fuzzy.LevenshteinDistance(queryLower, strings.ToLower(chat.Name))
 			results = append(results, SearchResult{
-				ChatInfo: chat,
-				Score:    distance,
+				ChatInfo:  chat,
+				Score:     nameDistance(query, chat.Name),
+				MatchedBy: matchedByName,
 			})
 		}
 	}
 
 	return results
 }
+
+// lowConfidence reports whether name matching produced nothing useful: there are
+// no results, or every result scores above threshold.
+func lowConfidence(results []SearchResult, threshold int) bool {
+	for _, r := range results {
+		if r.Score <= threshold {
+			return false
+		}
+	}
+	return true
+}
+
+// contentResults turns message hits into one result per chat, keeping the first
+// (newest) hit of each chat.
+func contentResults(query string, hits []tgdata.MessageHit) []SearchResult {
+	seen := make(map[int64]bool)
+	var results []SearchResult
+	for _, hit := range hits {
+		if seen[hit.Chat.ID] {
+			continue
+		}
+		seen[hit.Chat.ID] = true
+		date := hit.Date
+		results = append(results, SearchResult{
+			ChatInfo:    hit.Chat,
+			MatchedBy:   matchedByContent,
+			Snippet:     contentSnippet(hit.Text, query, contentSnippetRunes),
+			MessageID:   hit.MessageID,
+			MessageDate: &date,
+		})
+	}
+	return results
+}
+
+// mergeContentResults puts content matches ahead of the low-confidence name matches,
+// dropping name matches for chats already found by content, and truncates to limit.
+func mergeContentResults(nameResults, content []SearchResult, limit int) []SearchResult {
+	seen := make(map[int64]bool, len(content))
+	results := make([]SearchResult, 0, len(nameResults)+len(content))
+	for _, r := range content {
+		seen[r.ID] = true
+		results = append(results, r)
+	}
+	for _, r := range nameResults {
+		if !seen[r.ID] {
+			results = append(results, r)
+		}
+	}
+	if len(results) > limit {
+		results = results[:limit]
+	}
+	return results
+}
+
+// contentSnippet returns a single-line excerpt of text of at most maxRunes runes,
+// centered on the first case-insensitive occurrence of query when there is one.
+func contentSnippet(text, query string, maxRunes int) string {
+	runes := []rune(strings.Join(strings.Fields(text), " "))
+	if len(runes) <= maxRunes {
+		return string(runes)
+	}
+
+	start := 0
+	lower := []rune(strings.ToLower(string(runes)))
+	queryRunes := []rune(strings.ToLower(strings.Join(strings.Fields(query), " ")))
+	if idx := runeIndex(lower, queryRunes); idx >= 0 {
+		// Leave some leading context before the match
+		start = max(0, idx-(maxRunes-len(queryRunes))/2)
+	}
+	end := min(len(runes), start+maxRunes)
+	start = max(0, end-maxRunes)
+
+	snippet := string(runes[start:end])
+	if start > 0 {
+		snippet = "..." + snippet
+	}
+	if end < len(runes) {
+		snippet += "..."
+	}
+	return snippet
+}
+
+// runeIndex returns the index of the first occurrence of sub in s, or -1.
+func runeIndex(s, sub []rune) int {
+	if len(sub) == 0 {
+		return -1
+	}
+	for i := 0; i+len(sub) <= len(s); i++ {
+		match := true
+		for j := range sub {
+			if s[i+j] != sub[j] {
+				match = false
+				break
+			}
+		}
+		if match {
+			return i
+		}
+	}
+	return -1
+}
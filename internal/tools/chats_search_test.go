@@ -0,0 +1,132 @@
+package tools
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tolmachov/mcp-telegram/internal/tgdata"
+)
+
+func TestLowConfidence(t *testing.T) {
+	scored := func(scores ...int) []SearchResult {
+		var results []SearchResult
+		for _, s := range scores {
+			results = append(results, SearchResult{Score: s})
+		}
+		return results
+	}
+
+	tests := []struct {
+		name      string
+		results   []SearchResult
+		threshold int
+		want      bool
+	}{
+		{"no results", nil, DefaultNameMatchThreshold, true},
+		{"all above threshold", scored(11, 25, 40), 10, true},
+		{"one at threshold", scored(30, 10), 10, false},
+		{"one below threshold", scored(2, 50), 10, false},
+		{"stricter threshold", scored(5, 8), 3, true},
+		{"looser threshold", scored(15, 20), 20, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := lowConfidence(tt.results, tt.threshold); got != tt.want {
+				t.Errorf("lowConfidence() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestContentResults(t *testing.T) {
+	date := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	hits := []tgdata.MessageHit{
+		{Chat: tgdata.ChatInfo{ID: 1, Name: "Anna"}, MessageID: 10, Text: "квартира на лето\nнайдена", Date: date},
+		{Chat: tgdata.ChatInfo{ID: 2, Name: "Flat hunting"}, MessageID: 20, Text: "квартира на лето?", Date: date},
+		{Chat: tgdata.ChatInfo{ID: 1, Name: "Anna"}, MessageID: 5, Text: "older hit", Date: date},
+	}
+
+	results := contentResults("квартира на лето", hits)
+	if len(results) != 2 {
+		t.Fatalf("got %d results, want 2", len(results))
+	}
+	first := results[0]
+	if first.ID != 1 || first.MessageID != 10 || first.MatchedBy != matchedByContent {
+		t.Errorf("first result = %+v, want newest hit of chat 1 matched by content", first)
+	}
+	if first.Snippet != "квартира на лето найдена" {
+		t.Errorf("snippet = %q, want single-line text", first.Snippet)
+	}
+	if first.MessageDate == nil || !first.MessageDate.Equal(date) {
+		t.Errorf("message date = %v, want %v", first.MessageDate, date)
+	}
+}
+
+func TestMergeContentResults(t *testing.T) {
+	name := []SearchResult{
+		{ChatInfo: tgdata.ChatInfo{ID: 1}, MatchedBy: matchedByName},
+		{ChatInfo: tgdata.ChatInfo{ID: 2}, MatchedBy: matchedByName},
+	}
+	content := []SearchResult{
+		{ChatInfo: tgdata.ChatInfo{ID: 2}, MatchedBy: matchedByContent},
+		{ChatInfo: tgdata.ChatInfo{ID: 3}, MatchedBy: matchedByContent},
+	}
+
+	ids := func(results []SearchResult) []int64 {
+		var out []int64
+		for _, r := range results {
+			out = append(out, r.ID)
+		}
+		return out
+	}
+
+	got := mergeContentResults(name, content, 10)
+	if want := []int64{2, 3, 1}; !reflect.DeepEqual(ids(got), want) {
+		t.Errorf("got %v, want %v", ids(got), want)
+	}
+	if got[0].MatchedBy != matchedByContent {
+		t.Errorf("duplicate chat should keep the content match, got %q", got[0].MatchedBy)
+	}
+
+	if got := mergeContentResults(name, content, 2); !reflect.DeepEqual(ids(got), []int64{2, 3}) {
+		t.Errorf("limited: got %v, want [2 3]", ids(got))
+	}
+}
+
+func TestContentSnippet(t *testing.T) {
+	long := strings.Repeat("a", 100) + " квартира на лето " + strings.Repeat("b", 100)
+
+	tests := []struct {
+		name     string
+		text     string
+		query    string
+		maxRunes int
+		want     string
+	}{
+		{"short text kept", "hello\n  world", "world", 50, "hello world"},
+		{"match at start", "Match here " + strings.Repeat("x", 20), "match", 10, "Match here..."},
+		{"no match takes prefix", strings.Repeat("x", 20), "absent", 5, "xxxxx..."},
+		{"match at end", strings.Repeat("x", 20) + " end", "end", 6, "...xx end"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := contentSnippet(tt.text, tt.query, tt.maxRunes); got != tt.want {
+				t.Errorf("contentSnippet() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("match in the middle", func(t *testing.T) {
+		got := contentSnippet(long, "Квартира на лето", 40)
+		if !strings.Contains(got, "квартира на лето") {
+			t.Errorf("snippet %q does not contain the match", got)
+		}
+		if !strings.HasPrefix(got, "...") || !strings.HasSuffix(got, "...") {
+			t.Errorf("snippet %q should be elided on both sides", got)
+		}
+	})
+}
@@ -0,0 +1,250 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gotd/td/tg"
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/tolmachov/mcp-telegram/internal/conditional"
+	"github.com/tolmachov/mcp-telegram/internal/messages"
+	"github.com/tolmachov/mcp-telegram/internal/recent"
+)
+
+// ConditionalScheduleHandler handles the ScheduleConditionalMessage tool
+type ConditionalScheduleHandler struct {
+	provider *messages.Provider
+	store    *conditional.Store
+	chatName recent.NameLookup
+}
+
+// NewConditionalScheduleHandler creates a new ConditionalScheduleHandler.
+// The provider looks up the chat's latest message when no reference message is given.
+func NewConditionalScheduleHandler(provider *messages.Provider, store *conditional.Store, chatName recent.NameLookup) *ConditionalScheduleHandler {
+	return &ConditionalScheduleHandler{provider: provider, store: store, chatName: chatName}
+}
+
+// Tool returns the MCP tool definition
+func (h *ConditionalScheduleHandler) Tool() mcp.Tool {
+	return mcp.NewTool("ScheduleConditionalMessage",
+		mcp.WithDescription("Send a message at a deadline only if a condition still holds then, e.g. \"if Bob doesn't reply by 5pm, send him a reminder\". With the no_incoming_since condition the message is sent only if no incoming message arrived in the chat after the reference message; otherwise it is canceled and the reason recorded. Messages are kept across restarts but only sent while this server is running."),
+		mcp.WithOpenWorldHintAnnotation(true),
+		mcp.WithNumber("chat_id",
+			mcp.Description("The ID of the chat to send the message to"),
+			mcp.Required(),
+		),
+		mcp.WithString("message",
+			mcp.Description("The message text to send"),
+			mcp.Required(),
+		),
+		mcp.WithString("deadline",
+			mcp.Description("When to check the condition and send: a time (YYYY-MM-DD HH:MM:SS or YYYY-MM-DD, local time, or RFC 3339) or a delay from now such as '2h' or '90m'"),
+			mcp.Required(),
+		),
+		mcp.WithString("condition",
+			mcp.Description("Condition checked at the deadline (default: no_incoming_since)"),
+			mcp.Enum(string(conditional.ConditionNoIncomingSince)),
+		),
+		mcp.WithNumber("reference_message_id",
+			mcp.Description("Only incoming messages newer than this message count (optional, default: the chat's latest message now)"),
+		),
+		expectedChatNameOption("the chat"),
+	)
+}
+
+// Handle processes the ScheduleConditionalMessage tool request
+func (h *ConditionalScheduleHandler) Handle(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	chatID := mcp.ParseInt64(request, "chat_id", 0)
+	if chatID == 0 {
+		return mcp.NewToolResultError("chat_id is required"), nil
+	}
+
+	message := mcp.ParseString(request, "message", "")
+	if message == "" {
+		return mcp.NewToolResultError("message is required"), nil
+	}
+
+	now := time.Now()
+	deadline, err := parseDeadline(mcp.ParseString(request, "deadline", ""), now)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if !deadline.After(now) {
+		return mcp.NewToolResultError("deadline must be in the future"), nil
+	}
+
+	condition := mcp.ParseString(request, "condition", string(conditional.ConditionNoIncomingSince))
+	if err := conditional.ValidateCondition(condition); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	if errResult := checkExpectedChatName(ctx, request, h.chatName, chatID); errResult != nil {
+		return errResult, nil
+	}
+
+	referenceID := mcp.ParseInt(request, "reference_message_id", 0)
+	if referenceID == 0 {
+		latest, err := h.provider.Fetch(ctx, chatID, messages.FetchOptions{Limit: 1})
+		if err != nil {
+			return toolError("get latest message", err), nil
+		}
+		if len(latest.Messages) > 0 {
+			referenceID = latest.Messages[0].ID
+		}
+	}
+
+	m, err := h.store.Add(conditional.Message{
+		ChatID:             chatID,
+		Text:               message,
+		Deadline:           deadline,
+		Condition:          conditional.Condition(condition),
+		ReferenceMessageID: referenceID,
+		CreatedAt:          now,
+	})
+	if err != nil {
+		return toolError("save conditional message", err), nil
+	}
+
+	return mcp.NewToolResultText(fmt.Sprintf("Conditional message scheduled.\nID: %d\nTo: %d\nDeadline: %s\nCondition: %s (after message %d)\nText: %s",
+		m.ID, chatID, deadline.Format(time.RFC3339), condition, referenceID, messages.TruncateRunes(message, sentTextSnippetRunes))), nil
+}
+
+// parseDeadline parses an absolute time (see parseDate, or RFC 3339) or a delay from now.
+func parseDeadline(s string, now time.Time) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return time.Time{}, errors.New("deadline is required")
+	}
+	if d, err := time.ParseDuration(strings.TrimPrefix(s, "in ")); err == nil {
+		return now.Add(d), nil
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	if t, _, err := parseDate(s); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("invalid deadline %q, expected YYYY-MM-DD HH:MM:SS, YYYY-MM-DD, RFC 3339 or a delay such as '2h'", s)
+}
+
+// ConditionalListHandler handles the ListConditionalMessages tool
+type ConditionalListHandler struct {
+	store *conditional.Store
+}
+
+// NewConditionalListHandler creates a new ConditionalListHandler
+func NewConditionalListHandler(store *conditional.Store) *ConditionalListHandler {
+	return &ConditionalListHandler{store: store}
+}
+
+// Tool returns the MCP tool definition
+func (h *ConditionalListHandler) Tool() mcp.Tool {
+	return mcp.NewTool("ListConditionalMessages",
+		mcp.WithDescription("List conditional messages waiting for their deadline and, optionally, recently sent, canceled or failed ones with the reason."),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithBoolean("include_resolved",
+			mcp.Description("Also list recently sent, canceled and failed messages (default: false)"),
+		),
+	)
+}
+
+// Handle processes the ListConditionalMessages tool request
+func (h *ConditionalListHandler) Handle(_ context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	msgs := h.store.List(mcp.ParseBoolean(request, "include_resolved", false))
+	if msgs == nil {
+		msgs = []conditional.Message{}
+	}
+	return jsonResult(msgs)
+}
+
+// ConditionalCancelHandler handles the CancelConditionalMessage tool
+type ConditionalCancelHandler struct {
+	store *conditional.Store
+}
+
+// NewConditionalCancelHandler creates a new ConditionalCancelHandler
+func NewConditionalCancelHandler(store *conditional.Store) *ConditionalCancelHandler {
+	return &ConditionalCancelHandler{store: store}
+}
+
+// Tool returns the MCP tool definition
+func (h *ConditionalCancelHandler) Tool() mcp.Tool {
+	return mcp.NewTool("CancelConditionalMessage",
+		mcp.WithDescription("Cancel a pending conditional message so it is never sent."),
+		mcp.WithNumber("id",
+			mcp.Description("The conditional message ID (see ListConditionalMessages)"),
+			mcp.Required(),
+		),
+	)
+}
+
+// Handle processes the CancelConditionalMessage tool request
+func (h *ConditionalCancelHandler) Handle(_ context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	id := mcp.ParseInt(request, "id", 0)
+	if id == 0 {
+		return mcp.NewToolResultError("id is required"), nil
+	}
+	if err := h.store.Cancel(id, "canceled by request", time.Now()); err != nil {
+		if errors.Is(err, conditional.ErrNotPending) {
+			return mcp.NewToolResultError(fmt.Sprintf("No pending conditional message with ID %d", id)), nil
+		}
+		return toolError("cancel conditional message", err), nil
+	}
+	return mcp.NewToolResultText(fmt.Sprintf("Conditional message %d canceled.", id)), nil
+}
+
+// ConditionalIncomingChecker returns a conditional.IncomingChecker that pages through
+// the chat's history after the reference message looking for an incoming message.
+// Requests go through the provider's rate limiter.
+func ConditionalIncomingChecker(provider *messages.Provider) conditional.IncomingChecker {
+	return func(ctx context.Context, chatID int64, afterID int) (int, error) {
+		client := provider.Paced()
+		peer, err := provider.Peers().ResolvePeer(ctx, client, chatID)
+		if err != nil {
+			return 0, fmt.Errorf("resolving peer: %w", err)
+		}
+
+		offsetID := 0
+		for {
+			history, err := client.MessagesGetHistory(ctx, &tg.MessagesGetHistoryRequest{
+				Peer:     peer,
+				OffsetID: offsetID,
+				MinID:    afterID,
+				Limit:    messages.MaxFetchLimit,
+			})
+			if err != nil {
+				return 0, fmt.Errorf("getting history: %w", err)
+			}
+			modified, ok := history.AsModified()
+			if !ok {
+				return 0, nil
+			}
+			batch := modified.GetMessages()
+			for _, msgClass := range batch {
+				if msg, ok := msgClass.(*tg.Message); ok && !msg.Out && msg.ID > afterID {
+					return msg.ID, nil
+				}
+			}
+			if len(batch) < messages.MaxFetchLimit {
+				return 0, nil
+			}
+			offsetID = batch[len(batch)-1].GetID()
+		}
+	}
+}
+
+// ConditionalSender returns a conditional.Sender using the regular send path and the
+// provider's rate limiter.
+func ConditionalSender(provider *messages.Provider) conditional.Sender {
+	return func(ctx context.Context, chatID int64, text string) (int, error) {
+		sent, err := sendText(ctx, provider.Paced(), provider.Peers(), chatID, text)
+		if err != nil {
+			return 0, err
+		}
+		return sent.ID, nil
+	}
+}
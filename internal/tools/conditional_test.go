@@ -0,0 +1,37 @@
+package tools
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseDeadline(t *testing.T) {
+	now := time.Date(2024, 7, 1, 12, 0, 0, 0, time.Local)
+
+	tests := []struct {
+		name    string
+		input   string
+		want    time.Time
+		wantErr bool
+	}{
+		{"delay", "2h30m", now.Add(150 * time.Minute), false},
+		{"delay with in", "in 90m", now.Add(90 * time.Minute), false},
+		{"local time", "2024-07-01 17:00:00", time.Date(2024, 7, 1, 17, 0, 0, 0, time.Local), false},
+		{"date", "2024-07-02", time.Date(2024, 7, 2, 0, 0, 0, 0, time.Local), false},
+		{"RFC 3339", "2024-07-01T17:00:00Z", time.Date(2024, 7, 1, 17, 0, 0, 0, time.UTC), false},
+		{"empty", "", time.Time{}, true},
+		{"garbage", "tomorrow-ish", time.Time{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseDeadline(tt.input, now)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseDeadline(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("parseDeadline(%q) = %v, want %v", tt.input, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,169 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/tolmachov/mcp-telegram/internal/messages"
+	"github.com/tolmachov/mcp-telegram/internal/recent"
+	"github.com/tolmachov/mcp-telegram/internal/tgclient"
+)
+
+// confirmTextRunes is how much of a message's text a confirmation prompt quotes.
+const confirmTextRunes = 200
+
+// confirmTextArgs are the tool arguments holding text sent to a chat.
+var confirmTextArgs = []string{"message", "text", "new_text", "caption"}
+
+// elicitor asks the client's user for input; *server.MCPServer implements it.
+type elicitor interface {
+	RequestElicitation(ctx context.Context, request mcp.ElicitationRequest) (*mcp.ElicitationResult, error)
+}
+
+// ConfirmHandlers wraps the handlers of tools that change something, those not
+// annotated read-only whose destructive or open-world hint is set, so each call is
+// first confirmed by the user through an MCP elicitation request. Read tools that
+// accept mark_read or save_draft are confirmed only for calls setting them. Chat
+// references are looked up in peers and chatName resolves chat names for the prompt.
+func ConfirmHandlers(handlers []Handler, srv *server.MCPServer, peers *tgclient.PeerCache, chatName recent.NameLookup) []Handler {
+	wrapped := make([]Handler, len(handlers))
+	for i, h := range handlers {
+		switch tool := h.Tool(); {
+		case acceptsStateChangingArg(tool):
+			h = &confirmHandler{Handler: h, elicitor: srv, peers: peers, chatName: chatName, onlyStateChanges: true}
+		case needsConfirmation(tool):
+			h = &confirmHandler{Handler: h, elicitor: srv, peers: peers, chatName: chatName}
+		}
+		wrapped[i] = h
+	}
+	return wrapped
+}
+
+func needsConfirmation(tool mcp.Tool) bool {
+	a := tool.Annotations
+	if a.ReadOnlyHint != nil && *a.ReadOnlyHint {
+		return false
+	}
+	return (a.DestructiveHint != nil && *a.DestructiveHint) || (a.OpenWorldHint != nil && *a.OpenWorldHint)
+}
+
+// acceptsStateChangingArg reports whether tool has a mark_read or save_draft parameter.
+func acceptsStateChangingArg(tool mcp.Tool) bool {
+	for _, name := range stateChangingArgs {
+		if _, ok := tool.InputSchema.Properties[name]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// confirmHandler runs its handler only once the user approves the call.
+type confirmHandler struct {
+	Handler
+	elicitor elicitor
+	peers    *tgclient.PeerCache
+	chatName recent.NameLookup
+	// onlyStateChanges asks only for calls setting mark_read or save_draft
+	onlyStateChanges bool
+}
+
+// Handle asks the user to confirm the call before running it.
+func (h *confirmHandler) Handle(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	tool := request.Params.Name
+	if _, ok := setsStateChangingArg(request); h.onlyStateChanges && !ok {
+		return h.Handler.Handle(ctx, request)
+	}
+	if !clientSupportsElicitation(ctx) {
+		return elicitationUnsupported(tool), nil
+	}
+
+	result, err := h.elicitor.RequestElicitation(ctx, mcp.ElicitationRequest{
+		Params: mcp.ElicitationParams{
+			Message:         confirmationMessage(ctx, request, h.peers, h.chatName),
+			RequestedSchema: map[string]any{"type": "object", "properties": map[string]any{}},
+		},
+	})
+	if errors.Is(err, server.ErrNoActiveSession) || errors.Is(err, server.ErrElicitationNotSupported) {
+		return elicitationUnsupported(tool), nil
+	}
+	if err != nil {
+		return toolError("ask for confirmation", err), nil
+	}
+	if result.Action != mcp.ElicitationResponseActionAccept {
+		return mcp.NewToolResultError(fmt.Sprintf("%s was not run: the user didn't confirm it (%s)", tool, result.Action)), nil
+	}
+	return h.Handler.Handle(ctx, request)
+}
+
+func elicitationUnsupported(tool string) *mcp.CallToolResult {
+	return mcp.NewToolResultError(fmt.Sprintf("%s needs confirmation, but the MCP client doesn't support confirmation prompts (elicitation); restart the server without --confirm-destructive to use it", tool))
+}
+
+// clientSupportsElicitation reports whether the client declared elicitation support
+// when it initialized the session. Without a known session RequestElicitation
+// reports the failure itself.
+func clientSupportsElicitation(ctx context.Context) bool {
+	session, ok := server.ClientSessionFromContext(ctx).(server.SessionWithClientInfo)
+	if !ok {
+		return true
+	}
+	return session.GetClientCapabilities().Elicitation != nil
+}
+
+// confirmationMessage describes a tool call for the user, e.g.
+// `Run SendMessage in chat Friends (-100123) with text "hello"?`.
+func confirmationMessage(ctx context.Context, request mcp.CallToolRequest, peers *tgclient.PeerCache, chatName recent.NameLookup) string {
+	args := request.GetArguments()
+	var sb strings.Builder
+	sb.WriteString("Run " + request.Params.Name)
+
+	var chats []string
+	for _, chatID := range requestChatIDs(peers, args) {
+		chats = append(chats, describeChat(ctx, chatID, chatName))
+	}
+	for _, name := range chatRefArgs {
+		// References already resolved are among requestChatIDs
+		if ref, ok := args[name].(string); ok && ref != "" {
+			if _, cached := peers.CachedChatID(ref); !cached {
+				chats = append(chats, ref)
+			}
+		}
+	}
+	switch len(chats) {
+	case 0:
+	case 1:
+		sb.WriteString(" in chat " + chats[0])
+	default:
+		sb.WriteString(" in chats " + strings.Join(chats, ", "))
+	}
+
+	if messageID, ok := args["message_id"].(float64); ok && messageID != 0 {
+		fmt.Fprintf(&sb, " on message %d", int64(messageID))
+	} else if ids := request.GetIntSlice("message_ids", nil); len(ids) > 0 {
+		fmt.Fprintf(&sb, " on %d messages", len(ids))
+	}
+	for _, name := range confirmTextArgs {
+		if text, ok := args[name].(string); ok && text != "" {
+			fmt.Fprintf(&sb, " with text %q", messages.TruncateRunes(text, confirmTextRunes))
+			break
+		}
+	}
+	sb.WriteString("?")
+	return sb.String()
+}
+
+// describeChat names a chat with its ID, or gives the ID alone when the name can't
+// be looked up.
+func describeChat(ctx context.Context, chatID int64, chatName recent.NameLookup) string {
+	if chatName != nil {
+		if name, err := chatName(ctx, chatID); err == nil && name != "" {
+			return fmt.Sprintf("%s (%d)", name, chatID)
+		}
+	}
+	return fmt.Sprintf("%d", chatID)
+}
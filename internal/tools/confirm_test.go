@@ -0,0 +1,179 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/tolmachov/mcp-telegram/internal/summarize"
+)
+
+type fakeElicitor struct {
+	result  *mcp.ElicitationResult
+	err     error
+	message string
+}
+
+func (e *fakeElicitor) RequestElicitation(_ context.Context, request mcp.ElicitationRequest) (*mcp.ElicitationResult, error) {
+	e.message = request.Params.Message
+	return e.result, e.err
+}
+
+type recordingHandler struct {
+	Handler
+	called bool
+}
+
+func (h *recordingHandler) Handle(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	h.called = true
+	return mcp.NewToolResultText("sent"), nil
+}
+
+func sendRequest() mcp.CallToolRequest {
+	var request mcp.CallToolRequest
+	request.Params.Name = "SendMessage"
+	request.Params.Arguments = map[string]any{"chat_id": float64(-100123), "message": "hello"}
+	return request
+}
+
+func friendsName(_ context.Context, chatID int64) (string, error) {
+	if chatID == -100123 {
+		return "Friends", nil
+	}
+	return "", errors.New("not found")
+}
+
+func TestNeedsConfirmation(t *testing.T) {
+	tests := []struct {
+		handler Handler
+		want    bool
+	}{
+		{NewMessageSendHandler(nil, nil, nil), true},
+		{NewMessageDeleteHandler(nil, nil), true},
+		{NewMessageReadHandler(nil), true},
+		{NewMeGetHandler(nil), false},
+	}
+	for _, tt := range tests {
+		tool := tt.handler.Tool()
+		if got := needsConfirmation(tool); got != tt.want {
+			t.Errorf("needsConfirmation(%s) = %v, want %v", tool.Name, got, tt.want)
+		}
+	}
+}
+
+func TestConfirmationMessage(t *testing.T) {
+	got := confirmationMessage(context.Background(), sendRequest(), nil, friendsName)
+	if want := `Run SendMessage in chat Friends (-100123) with text "hello"?`; got != want {
+		t.Errorf("message = %q, want %q", got, want)
+	}
+
+	var request mcp.CallToolRequest
+	request.Params.Name = "DeleteMessage"
+	request.Params.Arguments = map[string]any{"chat_id": float64(42), "message_id": float64(7)}
+	got = confirmationMessage(context.Background(), request, nil, friendsName)
+	if want := "Run DeleteMessage in chat 42 on message 7?"; got != want {
+		t.Errorf("message = %q, want %q", got, want)
+	}
+}
+
+func TestConfirmHandler(t *testing.T) {
+	tests := []struct {
+		name       string
+		elicitor   *fakeElicitor
+		wantCalled bool
+		wantError  string
+	}{
+		{
+			name:       "accepted",
+			elicitor:   &fakeElicitor{result: &mcp.ElicitationResult{ElicitationResponse: mcp.ElicitationResponse{Action: mcp.ElicitationResponseActionAccept}}},
+			wantCalled: true,
+		},
+		{
+			name:      "declined",
+			elicitor:  &fakeElicitor{result: &mcp.ElicitationResult{ElicitationResponse: mcp.ElicitationResponse{Action: mcp.ElicitationResponseActionDecline}}},
+			wantError: "SendMessage was not run: the user didn't confirm it (decline)",
+		},
+		{
+			name:      "not supported",
+			elicitor:  &fakeElicitor{err: server.ErrElicitationNotSupported},
+			wantError: "restart the server without --confirm-destructive",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			inner := &recordingHandler{Handler: NewMessageSendHandler(nil, nil, nil)}
+			h := &confirmHandler{Handler: inner, elicitor: tt.elicitor, chatName: friendsName}
+			result, err := h.Handle(context.Background(), sendRequest())
+			if err != nil {
+				t.Fatalf("Handle: %v", err)
+			}
+			if tt.elicitor.err == nil && !strings.Contains(tt.elicitor.message, "Friends (-100123)") {
+				t.Errorf("prompt = %q, want the chat named", tt.elicitor.message)
+			}
+			if inner.called != tt.wantCalled {
+				t.Errorf("handler called = %v, want %v", inner.called, tt.wantCalled)
+			}
+			if tt.wantError != "" && (!result.IsError || !strings.Contains(resultText(result), tt.wantError)) {
+				t.Errorf("result = %q, want an error containing %q", resultText(result), tt.wantError)
+			}
+		})
+	}
+}
+
+func TestConfirmHandlersWrapsOnlyWritingTools(t *testing.T) {
+	handlers := ConfirmHandlers([]Handler{NewMeGetHandler(nil), NewMessageSendHandler(nil, nil, nil)}, nil, nil, nil)
+	if _, ok := handlers[0].(*confirmHandler); ok {
+		t.Error("GetMe was wrapped")
+	}
+	if _, ok := handlers[1].(*confirmHandler); !ok {
+		t.Error("SendMessage wasn't wrapped")
+	}
+}
+
+func TestConfirmHandlersAskOnlyForStateChanges(t *testing.T) {
+	for _, inner := range []Handler{
+		NewMessagesGetHandler(nil, nil),
+		NewChatSummarizeHandler(nil, nil, nil, summarize.Config{}, nil),
+		NewMentionsGetHandler(nil, nil),
+		NewReactionsGetHandler(nil, nil),
+		NewReplySuggestHandler(nil, nil, nil, summarize.Config{}),
+	} {
+		name := inner.Tool().Name
+		t.Run(name, func(t *testing.T) {
+			recorder := &recordingHandler{Handler: inner}
+			h, ok := ConfirmHandlers([]Handler{recorder}, nil, nil, nil)[0].(*confirmHandler)
+			if !ok {
+				t.Fatal("not wrapped")
+			}
+			elicitor := &fakeElicitor{result: &mcp.ElicitationResult{ElicitationResponse: mcp.ElicitationResponse{Action: mcp.ElicitationResponseActionDecline}}}
+			h.elicitor = elicitor
+
+			var request mcp.CallToolRequest
+			request.Params.Name = name
+			request.Params.Arguments = map[string]any{"chat_id": float64(42)}
+			if _, err := h.Handle(context.Background(), request); err != nil {
+				t.Fatalf("Handle: %v", err)
+			}
+			if !recorder.called || elicitor.message != "" {
+				t.Errorf("plain call: handler called = %v, prompt = %q; want it run without asking", recorder.called, elicitor.message)
+			}
+
+			recorder.called = false
+			for _, arg := range stateChangingArgs {
+				if _, ok := inner.Tool().InputSchema.Properties[arg]; ok {
+					request.Params.Arguments = map[string]any{"chat_id": float64(42), arg: true}
+				}
+			}
+			if _, err := h.Handle(context.Background(), request); err != nil {
+				t.Fatalf("Handle: %v", err)
+			}
+			if recorder.called || elicitor.message == "" {
+				t.Errorf("state-changing call: handler called = %v, prompt = %q; want it declined after asking", recorder.called, elicitor.message)
+			}
+		})
+	}
+}
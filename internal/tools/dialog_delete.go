@@ -0,0 +1,92 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gotd/td/tg"
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/tolmachov/mcp-telegram/internal/tgclient"
+)
+
+// DialogDeleteHandler handles the DeleteDialog tool
+type DialogDeleteHandler struct {
+	client *tg.Client
+	peers  *tgclient.PeerCache
+}
+
+// NewDialogDeleteHandler creates a new DialogDeleteHandler
+func NewDialogDeleteHandler(client *tg.Client, peers *tgclient.PeerCache) *DialogDeleteHandler {
+	return &DialogDeleteHandler{client: client, peers: peers}
+}
+
+// Tool returns the MCP tool definition
+func (h *DialogDeleteHandler) Tool() mcp.Tool {
+	return mcp.NewTool("DeleteDialog",
+		mcp.WithDescription("Delete a private chat with its whole message history, for you only or, with revoke, for the other person too. This cannot be undone, so it requires confirm: true. Use LeaveChat for groups and channels."),
+		mcp.WithDestructiveHintAnnotation(true),
+		mcp.WithNumber("chat_id",
+			mcp.Description("The ID of the user or bot whose chat to delete"),
+			mcp.Required(),
+		),
+		mcp.WithBoolean("revoke",
+			mcp.Description("Delete the history for the other person too (default: false)"),
+		),
+		mcp.WithBoolean("confirm",
+			mcp.Description("Must be true to delete the chat, guarding against accidental history wipes"),
+			mcp.Required(),
+		),
+	)
+}
+
+// Handle processes the DeleteDialog tool request
+func (h *DialogDeleteHandler) Handle(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	chatID := mcp.ParseInt64(request, "chat_id", 0)
+	if chatID == 0 {
+		return mcp.NewToolResultError("chat_id is required"), nil
+	}
+	if !mcp.ParseBoolean(request, "confirm", false) {
+		return mcp.NewToolResultError(fmt.Sprintf("DeleteDialog wipes the whole history of chat %d; call it again with confirm: true to proceed", chatID)), nil
+	}
+	revoke := mcp.ParseBoolean(request, "revoke", false)
+
+	deleted := 0
+	var private bool
+	err := h.peers.WithPeer(ctx, h.client, chatID, func(peer tg.InputPeerClass) error {
+		if _, ok := peer.(*tg.InputPeerUser); !ok {
+			return nil
+		}
+		private = true
+		// Telegram deletes history in portions; offset stays positive until it is done
+		for {
+			affected, err := h.client.MessagesDeleteHistory(ctx, &tg.MessagesDeleteHistoryRequest{Peer: peer, Revoke: revoke})
+			if err != nil {
+				return err
+			}
+			deleted += affected.PtsCount
+			if affected.Offset <= 0 {
+				return nil
+			}
+		}
+	})
+	if err != nil {
+		return toolError("delete dialog", err), nil
+	}
+	if !private {
+		return mcp.NewToolResultError(fmt.Sprintf("Chat %d is a group or channel; use LeaveChat to leave it", chatID)), nil
+	}
+	return mcp.NewToolResultText(deleteDialogText(chatID, deleted, revoke)), nil
+}
+
+// deleteDialogText describes the outcome of DeleteDialog.
+func deleteDialogText(chatID int64, deleted int, revoke bool) string {
+	who := "for you only; the other person still has it"
+	if revoke {
+		who = "for both sides"
+	}
+	if deleted == 0 {
+		return fmt.Sprintf("Deleted chat %d; it had no messages left to delete", chatID)
+	}
+	return fmt.Sprintf("Deleted chat %d and its %d messages %s", chatID, deleted, who)
+}
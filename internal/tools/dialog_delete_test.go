@@ -0,0 +1,37 @@
+package tools
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestDeleteDialogRequiresConfirm(t *testing.T) {
+	h := NewDialogDeleteHandler(nil, nil)
+	for _, args := range []map[string]any{
+		{"chat_id": float64(42)},
+		{"chat_id": float64(42), "confirm": false},
+	} {
+		result, err := h.Handle(context.Background(), callTool("DeleteDialog", args))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !result.IsError || !strings.Contains(result.Content[0].(mcp.TextContent).Text, "confirm: true") {
+			t.Errorf("Handle(%v) = %+v, want an error asking for confirm", args, result)
+		}
+	}
+}
+
+func TestDeleteDialogText(t *testing.T) {
+	if got := deleteDialogText(42, 10, false); got != "Deleted chat 42 and its 10 messages for you only; the other person still has it" {
+		t.Errorf("deleteDialogText(not revoked) = %q", got)
+	}
+	if got := deleteDialogText(42, 10, true); got != "Deleted chat 42 and its 10 messages for both sides" {
+		t.Errorf("deleteDialogText(revoked) = %q", got)
+	}
+	if got := deleteDialogText(42, 0, true); !strings.Contains(got, "no messages") {
+		t.Errorf("deleteDialogText(empty) = %q", got)
+	}
+}
@@ -0,0 +1,15 @@
+package tools
+
+import (
+	"fmt"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/tolmachov/mcp-telegram/internal/tgclient"
+)
+
+// toolError builds the error result for a failed action, classifying known
+// Telegram errors (e.g. Premium-only features) into clearer messages.
+func toolError(action string, err error) *mcp.CallToolResult {
+	return mcp.NewToolResultError(fmt.Sprintf("Failed to %s: %v", action, tgclient.ClassifyError(err)))
+}
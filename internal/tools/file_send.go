@@ -0,0 +1,247 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gotd/td/telegram/uploader"
+	"github.com/gotd/td/tg"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/tolmachov/mcp-telegram/internal/messages"
+	"github.com/tolmachov/mcp-telegram/internal/recent"
+	"github.com/tolmachov/mcp-telegram/internal/tgclient"
+)
+
+const (
+	// maxPhotoBytes is the largest image Telegram accepts as a photo; larger ones are sent as documents.
+	maxPhotoBytes = 10 << 20
+	// maxUploadBytes is Telegram's file size limit for regular accounts.
+	maxUploadBytes = 2000 << 20
+	// uploadProgressMinBytes is the file size from which upload progress is reported.
+	uploadProgressMinBytes = 5 << 20
+	// uploadProgressInterval is the minimum time between two upload progress notifications.
+	uploadProgressInterval = time.Second
+)
+
+// photoMimeTypes are the image formats Telegram accepts as photos. Other images, like
+// GIFs, are sent as documents so they keep their format.
+var photoMimeTypes = []string{"image/jpeg", "image/png", "image/webp"}
+
+// FileSendHandler handles the SendFile tool
+type FileSendHandler struct {
+	client       *tg.Client
+	peers        *tgclient.PeerCache
+	allowedPaths []string
+	chatName     recent.NameLookup
+}
+
+// NewFileSendHandler creates a new FileSendHandler.
+// Only files within allowedPaths can be sent; chatName resolves chat names for the
+// expected_chat_name check.
+func NewFileSendHandler(client *tg.Client, peers *tgclient.PeerCache, allowedPaths []string, chatName recent.NameLookup) *FileSendHandler {
+	return &FileSendHandler{client: client, peers: peers, allowedPaths: allowedPaths, chatName: chatName}
+}
+
+// Tool returns the MCP tool definition
+func (h *FileSendHandler) Tool() mcp.Tool {
+	return mcp.NewTool("SendFile",
+		mcp.WithDescription(fmt.Sprintf("Send a file from disk to a contact, group, or channel, with an optional caption. JPEG, PNG and WebP images up to %d MB are sent as photos, anything else as a document. The file must be within the allowed paths. Reports upload progress for large files.", maxPhotoBytes>>20)),
+		mcp.WithOpenWorldHintAnnotation(true),
+		mcp.WithNumber("chat_id",
+			mcp.Description("The ID of the chat to send the file to"),
+			mcp.Required(),
+		),
+		mcp.WithString("filepath",
+			mcp.Description("Path of the file to send"),
+			mcp.Required(),
+		),
+		mcp.WithString("caption",
+			mcp.Description("Text to send with the file (optional)"),
+		),
+		mcp.WithBoolean("as_document",
+			mcp.Description("Send images as documents too, uncompressed (default: false)"),
+		),
+		expectedChatNameOption("the chat"),
+	)
+}
+
+// Handle processes the SendFile tool request
+func (h *FileSendHandler) Handle(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	chatID := mcp.ParseInt64(request, "chat_id", 0)
+	if chatID == 0 {
+		return mcp.NewToolResultError("chat_id is required"), nil
+	}
+	path := mcp.ParseString(request, "filepath", "")
+	if path == "" {
+		return mcp.NewToolResultError("filepath is required"), nil
+	}
+	resolved, err := resolveReadPath(path, h.allowedPaths)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	info, err := os.Stat(resolved)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("cannot read file: %v", err)), nil
+	}
+	if !info.Mode().IsRegular() {
+		return mcp.NewToolResultError(fmt.Sprintf("%s is not a regular file", path)), nil
+	}
+	if info.Size() == 0 {
+		return mcp.NewToolResultError("the file is empty"), nil
+	}
+	if info.Size() > maxUploadBytes {
+		return mcp.NewToolResultError(fmt.Sprintf("the file is larger than Telegram's %d MB limit", maxUploadBytes>>20)), nil
+	}
+
+	if errResult := checkExpectedChatName(ctx, request, h.chatName, chatID); errResult != nil {
+		return errResult, nil
+	}
+
+	mimeType, err := fileMimeType(resolved)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("cannot read file: %v", err)), nil
+	}
+	asPhoto := !mcp.ParseBoolean(request, "as_document", false) && sendAsPhoto(mimeType, info.Size())
+
+	up := uploader.NewUploader(h.client)
+	if info.Size() >= uploadProgressMinBytes {
+		var progressToken mcp.ProgressToken
+		if request.Params.Meta != nil {
+			progressToken = request.Params.Meta.ProgressToken
+		}
+		up = up.WithProgress(&uploadProgress{srv: server.ServerFromContext(ctx), token: progressToken, name: filepath.Base(path)})
+	}
+	file, err := up.FromPath(ctx, resolved)
+	if err != nil {
+		return toolError("upload file", err), nil
+	}
+
+	var media tg.InputMediaClass
+	if asPhoto {
+		media = &tg.InputMediaUploadedPhoto{File: file}
+	} else {
+		media = &tg.InputMediaUploadedDocument{
+			File:       file,
+			MimeType:   mimeType,
+			ForceFile:  strings.HasPrefix(mimeType, "image/"),
+			Attributes: []tg.DocumentAttributeClass{&tg.DocumentAttributeFilename{FileName: filepath.Base(path)}},
+		}
+	}
+
+	caption := mcp.ParseString(request, "caption", "")
+	var updates tg.UpdatesClass
+	err = h.peers.WithPeer(ctx, h.client, chatID, func(peer tg.InputPeerClass) error {
+		var err error
+		updates, err = h.client.MessagesSendMedia(ctx, &tg.MessagesSendMediaRequest{
+			Peer:     peer,
+			Media:    media,
+			Message:  caption,
+			RandomID: time.Now().UnixNano(),
+		})
+		return err
+	})
+	if err != nil {
+		return toolError("send file", err), nil
+	}
+	sent := extractSentMessage(updates)
+
+	kind := "Document"
+	if asPhoto {
+		kind = "Photo"
+	}
+	result := fmt.Sprintf("%s sent successfully!\nMessage ID: %d\nDate: %s\nTo: %d\nFile: %s (%d bytes, %s)",
+		kind,
+		sent.ID,
+		time.Unix(int64(sent.Date), 0).Format(time.RFC3339),
+		chatID,
+		filepath.Base(path), info.Size(), mimeType,
+	)
+	if sent.Link != "" {
+		result += fmt.Sprintf("\nLink: %s", sent.Link)
+	}
+	if caption != "" {
+		result += fmt.Sprintf("\nCaption: %s", messages.TruncateRunes(caption, sentTextSnippetRunes))
+	}
+	return mcp.NewToolResultText(result), nil
+}
+
+// fileMimeType detects a file's MIME type from its content, falling back to its
+// extension when the content isn't recognized.
+func fileMimeType(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	head := make([]byte, 512)
+	n, err := f.Read(head)
+	if err != nil {
+		return "", err
+	}
+	detected, _, _ := strings.Cut(http.DetectContentType(head[:n]), ";")
+	if detected != "application/octet-stream" && detected != "text/plain" {
+		return detected, nil
+	}
+	if byExt, _, _ := strings.Cut(mime.TypeByExtension(filepath.Ext(path)), ";"); byExt != "" {
+		return byExt, nil
+	}
+	return detected, nil
+}
+
+// sendAsPhoto reports whether a file of the MIME type and size is sent as a photo.
+func sendAsPhoto(mimeType string, size int64) bool {
+	for _, t := range photoMimeTypes {
+		if mimeType == t {
+			return size <= maxPhotoBytes
+		}
+	}
+	return false
+}
+
+// uploadProgress reports upload progress as MCP progress notifications, at most once
+// per uploadProgressInterval. Parts are uploaded concurrently, so Chunk may be called
+// from several goroutines.
+type uploadProgress struct {
+	srv   *server.MCPServer
+	token mcp.ProgressToken
+	name  string
+
+	mu   sync.Mutex
+	last time.Time
+}
+
+// Chunk implements uploader.Progress.
+func (p *uploadProgress) Chunk(ctx context.Context, state uploader.ProgressState) error {
+	if p.srv == nil || state.Total <= 0 {
+		return nil
+	}
+	p.mu.Lock()
+	done := state.Uploaded >= state.Total
+	if !done && time.Since(p.last) < uploadProgressInterval {
+		p.mu.Unlock()
+		return nil
+	}
+	p.last = time.Now()
+	p.mu.Unlock()
+
+	payload := map[string]any{
+		"progress": float64(state.Uploaded) / float64(state.Total) * 100,
+		"total":    100,
+		"message":  fmt.Sprintf("Uploading %s: %d of %d MB", p.name, state.Uploaded>>20, state.Total>>20),
+	}
+	if p.token != nil {
+		payload["progressToken"] = p.token
+	}
+	_ = p.srv.SendNotificationToClient(ctx, "notifications/progress", payload)
+	return nil
+}
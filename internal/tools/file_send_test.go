@@ -0,0 +1,82 @@
+package tools
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSendAsPhoto(t *testing.T) {
+	tests := []struct {
+		mimeType string
+		size     int64
+		want     bool
+	}{
+		{"image/jpeg", 1 << 20, true},
+		{"image/png", maxPhotoBytes, true},
+		{"image/webp", 100, true},
+		{"image/jpeg", maxPhotoBytes + 1, false},
+		{"image/gif", 100, false},
+		{"application/pdf", 100, false},
+	}
+	for _, tt := range tests {
+		if got := sendAsPhoto(tt.mimeType, tt.size); got != tt.want {
+			t.Errorf("sendAsPhoto(%q, %d) = %v, want %v", tt.mimeType, tt.size, got, tt.want)
+		}
+	}
+}
+
+func TestFileMimeType(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string][]byte{
+		"photo.bin":  {0xFF, 0xD8, 0xFF, 0xE0, 0, 0x10, 'J', 'F', 'I', 'F', 0},
+		"report.pdf": []byte("%PDF-1.4\n"),
+		"notes.json": []byte(`{"a": 1}`),
+		"data.xyz":   {0, 1, 2, 3},
+	}
+	want := map[string]string{
+		"photo.bin":  "image/jpeg",
+		"report.pdf": "application/pdf",
+		"notes.json": "application/json",
+		"data.xyz":   "application/octet-stream",
+	}
+	for name, data := range files {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, data, 0o600); err != nil {
+			t.Fatal(err)
+		}
+		got, err := fileMimeType(path)
+		if err != nil {
+			t.Fatalf("fileMimeType(%s) error = %v", name, err)
+		}
+		if got != want[name] {
+			t.Errorf("fileMimeType(%s) = %q, want %q", name, got, want[name])
+		}
+	}
+}
+
+func TestResolveReadPathFollowsSymlinks(t *testing.T) {
+	allowed, outside := t.TempDir(), t.TempDir()
+	secret := filepath.Join(outside, "secret.txt")
+	inside := filepath.Join(allowed, "photo.jpg")
+	for _, path := range []string{secret, inside} {
+		if err := os.WriteFile(path, []byte("data"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	link := filepath.Join(allowed, "link.jpg")
+	if err := os.Symlink(secret, link); err != nil {
+		t.Skipf("symlinks unsupported: %v", err)
+	}
+
+	if _, err := resolveReadPath(link, []string{allowed}); err == nil {
+		t.Error("resolveReadPath() allowed a symlink to a file outside the allowed paths")
+	}
+	resolved, err := resolveReadPath(inside, []string{allowed})
+	if err != nil {
+		t.Fatalf("resolveReadPath() = %v for a file inside the allowed paths", err)
+	}
+	if want, _ := filepath.EvalSymlinks(inside); resolved != want {
+		t.Errorf("resolveReadPath() = %q, want %q", resolved, want)
+	}
+}
@@ -0,0 +1,33 @@
+package tools
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// FilterHandlers returns the handlers whose tools are in enabled, or all of them when
+// enabled is empty, without those in disabled. Names that match no handler's tool are
+// an error listing the valid ones, so a typo doesn't silently expose or hide a tool.
+func FilterHandlers(handlers []Handler, enabled, disabled []string) ([]Handler, error) {
+	names := make([]string, 0, len(handlers))
+	for _, h := range handlers {
+		names = append(names, h.Tool().Name)
+	}
+
+	var unknown []string
+	for _, name := range slices.Concat(enabled, disabled) {
+		if !slices.Contains(names, name) && !slices.Contains(unknown, name) {
+			unknown = append(unknown, name)
+		}
+	}
+	if len(unknown) > 0 {
+		slices.Sort(names)
+		return nil, fmt.Errorf("unknown tools %s; valid tools are %s", strings.Join(unknown, ", "), strings.Join(names, ", "))
+	}
+
+	return slices.DeleteFunc(slices.Clone(handlers), func(h Handler) bool {
+		name := h.Tool().Name
+		return (len(enabled) > 0 && !slices.Contains(enabled, name)) || slices.Contains(disabled, name)
+	}), nil
+}
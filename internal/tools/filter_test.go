@@ -0,0 +1,70 @@
+package tools
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func handlerNames(handlers []Handler) []string {
+	names := make([]string, 0, len(handlers))
+	for _, h := range handlers {
+		names = append(names, h.Tool().Name)
+	}
+	return names
+}
+
+func TestFilterHandlers(t *testing.T) {
+	tests := []struct {
+		name     string
+		enabled  []string
+		disabled []string
+		want     []string
+	}{
+		{
+			name: "no selection",
+			want: []string{"GetMe", "GetMessages", "SendMessage", "MarkAsRead", "MuteChat", "GetScheduledMessages", "DeleteScheduledMessage"},
+		},
+		{
+			name:    "enabled only",
+			enabled: []string{"SendMessage", "GetMe"},
+			want:    []string{"GetMe", "SendMessage"},
+		},
+		{
+			name:     "disabled only",
+			disabled: []string{"DeleteScheduledMessage", "MuteChat"},
+			want:     []string{"GetMe", "GetMessages", "SendMessage", "MarkAsRead", "GetScheduledMessages"},
+		},
+		{
+			name:     "disabled wins over enabled",
+			enabled:  []string{"SendMessage", "GetMe"},
+			disabled: []string{"SendMessage"},
+			want:     []string{"GetMe"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handlers, err := FilterHandlers(testHandlers(), tt.enabled, tt.disabled)
+			if err != nil {
+				t.Fatalf("FilterHandlers: %v", err)
+			}
+			if got := handlerNames(handlers); !slices.Equal(got, tt.want) {
+				t.Errorf("tools = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFilterHandlersUnknown(t *testing.T) {
+	_, err := FilterHandlers(testHandlers(), []string{"SendMessage", "SendMesage"}, []string{"DropChat", "SendMesage"})
+	if err == nil {
+		t.Fatal("FilterHandlers accepted unknown tools")
+	}
+	msg := err.Error()
+	if !strings.Contains(msg, "unknown tools SendMesage, DropChat;") {
+		t.Errorf("error %q doesn't name the unknown tools once each", msg)
+	}
+	if !strings.Contains(msg, "valid tools are DeleteScheduledMessage, GetMe, GetMessages, GetScheduledMessages, MarkAsRead, MuteChat, SendMessage") {
+		t.Errorf("error %q doesn't list the valid tools", msg)
+	}
+}
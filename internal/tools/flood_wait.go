@@ -0,0 +1,73 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/tolmachov/mcp-telegram/internal/tgclient"
+)
+
+// floodWaitCall is the tool call a request waiting out a FLOOD_WAIT belongs to.
+type floodWaitCall struct {
+	token mcp.ProgressToken
+	waits atomic.Int64
+}
+
+type floodWaitCallKey struct{}
+
+// FloodWaitMiddleware remembers the progress token of each tool call, so
+// ReportFloodWaits can tell the client which call is waiting.
+func FloodWaitMiddleware() server.ToolHandlerMiddleware {
+	return func(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+		return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			call := &floodWaitCall{}
+			if request.Params.Meta != nil {
+				call.token = request.Params.Meta.ProgressToken
+			}
+			return next(context.WithValue(ctx, floodWaitCallKey{}, call), request)
+		}
+	}
+}
+
+// ReportFloodWaits sends a progress notification for each FLOOD_WAIT a tool call
+// waits out, so the client sees why it stalls instead of timing out. Waits outside
+// tool calls, e.g. of auto-replies, are skipped. It returns once waits is closed or
+// ctx is done.
+func ReportFloodWaits(ctx context.Context, waits <-chan tgclient.FloodWait) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case wait, ok := <-waits:
+			if !ok {
+				return
+			}
+			srv := server.ServerFromContext(wait.Ctx)
+			if srv == nil {
+				continue
+			}
+			_ = srv.SendNotificationToClient(wait.Ctx, "notifications/progress", floodWaitProgress(wait))
+		}
+	}
+}
+
+// floodWaitProgress returns the progress notification parameters for a wait. The
+// progress counts the waits of the call so far, so it keeps increasing.
+func floodWaitProgress(wait tgclient.FloodWait) map[string]any {
+	params := map[string]any{
+		"progress": 1,
+		"message":  fmt.Sprintf("Rate limited by Telegram, waiting %s…", wait.Duration.Round(time.Second)),
+	}
+	if call, ok := wait.Ctx.Value(floodWaitCallKey{}).(*floodWaitCall); ok {
+		params["progress"] = call.waits.Add(1)
+		if call.token != nil {
+			params["progressToken"] = call.token
+		}
+	}
+	return params
+}
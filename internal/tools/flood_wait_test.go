@@ -0,0 +1,43 @@
+package tools
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/tolmachov/mcp-telegram/internal/tgclient"
+)
+
+func TestFloodWaitProgress(t *testing.T) {
+	var callCtx context.Context
+	handler := FloodWaitMiddleware()(func(ctx context.Context, _ mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		callCtx = ctx
+		return mcp.NewToolResultText("ok"), nil
+	})
+	request := mcp.CallToolRequest{}
+	request.Params.Meta = &mcp.Meta{ProgressToken: "backup-1"}
+	if _, err := handler(context.Background(), request); err != nil {
+		t.Fatal(err)
+	}
+
+	params := floodWaitProgress(tgclient.FloodWait{Ctx: callCtx, Duration: 42 * time.Second})
+	if got, want := params["message"], "Rate limited by Telegram, waiting 42s…"; got != want {
+		t.Errorf("message = %q, want %q", got, want)
+	}
+	if params["progressToken"] != "backup-1" {
+		t.Errorf("progressToken = %v, want the tool call's token", params["progressToken"])
+	}
+	if params["progress"] != int64(1) {
+		t.Errorf("progress = %v, want 1", params["progress"])
+	}
+	if next := floodWaitProgress(tgclient.FloodWait{Ctx: callCtx, Duration: time.Second}); next["progress"] != int64(2) {
+		t.Errorf("progress of the second wait = %v, want 2", next["progress"])
+	}
+
+	// A wait outside a tool call has no token
+	if params := floodWaitProgress(tgclient.FloodWait{Ctx: context.Background(), Duration: time.Second}); params["progressToken"] != nil {
+		t.Errorf("progressToken outside a tool call = %v, want none", params["progressToken"])
+	}
+}
@@ -0,0 +1,103 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/gotd/td/tg"
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/tolmachov/mcp-telegram/internal/tgclient"
+	"github.com/tolmachov/mcp-telegram/internal/tgdata"
+)
+
+// ForumTopicsGetHandler handles the GetForumTopics tool
+type ForumTopicsGetHandler struct {
+	client *tg.Client
+	peers  *tgclient.PeerCache
+}
+
+// NewForumTopicsGetHandler creates a new ForumTopicsGetHandler
+func NewForumTopicsGetHandler(client *tg.Client, peers *tgclient.PeerCache) *ForumTopicsGetHandler {
+	return &ForumTopicsGetHandler{client: client, peers: peers}
+}
+
+// Tool returns the MCP tool definition
+func (h *ForumTopicsGetHandler) Tool() mcp.Tool {
+	return mcp.NewTool("GetForumTopics",
+		mcp.WithDescription("List the topics of a supergroup with topics enabled, most recently active first, with their ID, title, unread count and last message date. Pass a topic's id as topic_id to GetMessages, BackupMessages or SummarizeChat to work on that topic alone."),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithNumber("chat_id",
+			mcp.Description("The ID of the supergroup (or use chat)"),
+		),
+		chatRefOption("chat", "chat_id", "The supergroup"),
+		mcp.WithString("query",
+			mcp.Description("Only list topics whose title contains this text (optional)"),
+		),
+		mcp.WithNumber("limit",
+			mcp.Description("Maximum number of topics to return (default 50, max 100)"),
+		),
+	)
+}
+
+// Handle processes the GetForumTopics tool request
+func (h *ForumTopicsGetHandler) Handle(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	chatID, errResult := chatIDParam(ctx, h.client, h.peers, request, "chat_id", "chat")
+	if errResult != nil {
+		return errResult, nil
+	}
+	limit := mcp.ParseInt(request, "limit", 50)
+	if limit <= 0 || limit > 100 {
+		return mcp.NewToolResultError("limit must be between 1 and 100"), nil
+	}
+
+	var topics []tgdata.ForumTopic
+	err := h.peers.WithPeer(ctx, h.client, chatID, func(peer tg.InputPeerClass) error {
+		var err error
+		topics, err = tgdata.GetForumTopics(ctx, h.client, peer, mcp.ParseString(request, "query", ""), limit)
+		return err
+	})
+	if errors.Is(err, tgdata.ErrNotForum) {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if err != nil {
+		return toolError("get forum topics", err), nil
+	}
+	return jsonResult(map[string]any{"chat_id": chatID, "topics": topics})
+}
+
+// topicParam looks up the topic named by the optional topic_id parameter, returning
+// a zero topic when it is unset and an error result for chats without topics.
+func topicParam(ctx context.Context, client *tg.Client, peers *tgclient.PeerCache, request mcp.CallToolRequest, chatID int64) (tgdata.ForumTopic, *mcp.CallToolResult) {
+	topicID := mcp.ParseInt(request, "topic_id", 0)
+	if topicID == 0 {
+		return tgdata.ForumTopic{}, nil
+	}
+	if topicID < 0 {
+		return tgdata.ForumTopic{}, mcp.NewToolResultError("topic_id must be positive")
+	}
+
+	var topic tgdata.ForumTopic
+	err := peers.WithPeer(ctx, client, chatID, func(peer tg.InputPeerClass) error {
+		var err error
+		topic, err = tgdata.GetForumTopic(ctx, client, peer, topicID)
+		return err
+	})
+	switch {
+	case errors.Is(err, tgdata.ErrNotForum):
+		return tgdata.ForumTopic{}, mcp.NewToolResultError(err.Error())
+	case errors.Is(err, tgdata.ErrTopicNotFound):
+		return tgdata.ForumTopic{}, mcp.NewToolResultError(fmt.Sprintf("Topic %d not found in chat %d; list topics with GetForumTopics", topicID, chatID))
+	case err != nil:
+		return tgdata.ForumTopic{}, toolError("get forum topic", err)
+	}
+	return topic, nil
+}
+
+// topicIDOption is the topic_id parameter of tools that can work on one forum topic.
+func topicIDOption(what string) mcp.ToolOption {
+	return mcp.WithNumber("topic_id",
+		mcp.Description(fmt.Sprintf("%s only the messages of this forum topic, an id from GetForumTopics (optional; only for supergroups with topics)", what)),
+	)
+}
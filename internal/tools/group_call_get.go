@@ -0,0 +1,79 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/tolmachov/mcp-telegram/internal/messages"
+	"github.com/tolmachov/mcp-telegram/internal/tgdata"
+)
+
+// maxGroupCallParticipants caps the limit for GetGroupCall.
+const maxGroupCallParticipants = 500
+
+// GroupCallGetHandler handles the GetGroupCall tool
+type GroupCallGetHandler struct {
+	provider *messages.Provider
+}
+
+// NewGroupCallGetHandler creates a new GroupCallGetHandler.
+// The provider's rate limiter throttles participant paging.
+func NewGroupCallGetHandler(provider *messages.Provider) *GroupCallGetHandler {
+	return &GroupCallGetHandler{provider: provider}
+}
+
+// Tool returns the MCP tool definition
+func (h *GroupCallGetHandler) Tool() mcp.Tool {
+	return mcp.NewTool("GetGroupCall",
+		mcp.WithDescription("Get the live voice or video chat of a group or channel: title, participant count and current participants with whether they are muted or speaking. Read-only; does not join the call."),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithNumber("chat_id",
+			mcp.Description("The ID of the group or channel"),
+			mcp.Required(),
+		),
+		mcp.WithNumber("limit",
+			mcp.Description("Maximum number of participants to list (default: 100, max: 500)"),
+		),
+	)
+}
+
+// groupCallResponse is the GetGroupCall result.
+type groupCallResponse struct {
+	ChatID int64 `json:"chat_id"`
+	*tgdata.GroupCallInfo
+	Message string `json:"message,omitempty"`
+}
+
+// Handle processes the GetGroupCall tool request
+func (h *GroupCallGetHandler) Handle(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	chatID := mcp.ParseInt64(request, "chat_id", 0)
+	if chatID == 0 {
+		return mcp.NewToolResultError("chat_id is required"), nil
+	}
+	limit := mcp.ParseInt(request, "limit", 100)
+	if limit <= 0 {
+		limit = 100
+	}
+	limit = min(limit, maxGroupCallParticipants)
+
+	call, err := tgdata.GetGroupCall(ctx, h.provider.Paced(), h.provider.Peers(), chatID, limit)
+	if err != nil {
+		return toolError("get group call", err), nil
+	}
+
+	resp := groupCallResponse{ChatID: chatID, GroupCallInfo: call}
+	switch {
+	case call.ScheduledFor != nil:
+		resp.Message = "The voice chat is scheduled but hasn't started yet"
+	case !call.Active:
+		resp.Message = "No active voice chat"
+	}
+
+	data, err := json.MarshalIndent(resp, "", "  ")
+	if err != nil {
+		return toolError("marshal group call", err), nil
+	}
+	return mcp.NewToolResultText(string(data)), nil
+}
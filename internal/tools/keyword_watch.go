@@ -0,0 +1,258 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gotd/td/tg"
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/tolmachov/mcp-telegram/internal/messages"
+	"github.com/tolmachov/mcp-telegram/internal/tgclient"
+	"github.com/tolmachov/mcp-telegram/internal/watch"
+)
+
+// KeywordWatchAddHandler handles the AddKeywordWatch tool
+type KeywordWatchAddHandler struct {
+	store *watch.Store
+}
+
+// NewKeywordWatchAddHandler creates a new KeywordWatchAddHandler
+func NewKeywordWatchAddHandler(store *watch.Store) *KeywordWatchAddHandler {
+	return &KeywordWatchAddHandler{store: store}
+}
+
+// Tool returns the MCP tool definition
+func (h *KeywordWatchAddHandler) Tool() mcp.Tool {
+	return mcp.NewTool("AddKeywordWatch",
+		mcp.WithDescription("Watch incoming messages for a keyword, e.g. \"invoice\" or your surname: when a message in any chat (or the given chats) matches, it is forwarded to your Saved Messages, or a notification with a link is sent there. Letters match regardless of case in any script, including Cyrillic. Alerts of a watch are at most once per cooldown, and only sent while this server is running."),
+		mcp.WithOpenWorldHintAnnotation(true),
+		mcp.WithString("pattern",
+			mcp.Description("The text to look for, matched anywhere in a message"),
+			mcp.Required(),
+		),
+		mcp.WithBoolean("regex",
+			mcp.Description("Treat pattern as a regular expression (RE2 syntax) instead of literal text (default: false)"),
+		),
+		mcp.WithBoolean("case_sensitive",
+			mcp.Description("Match letter case exactly (default: false)"),
+		),
+		mcp.WithArray("chat_ids",
+			mcp.WithNumberItems(),
+			mcp.Description("Only watch these chats (optional, default: all chats)"),
+		),
+		mcp.WithString("action",
+			mcp.Description("'forward' the matching message to Saved Messages (default; falls back to a notification when the chat forbids forwarding) or send a 'notify' text with the chat, an excerpt and a link"),
+			mcp.Enum(watch.ActionForward, watch.ActionNotify),
+		),
+		mcp.WithNumber("cooldown_minutes",
+			mcp.Description(fmt.Sprintf("Minimum minutes between two alerts of this watch (optional, default: %d)", int(watch.DefaultCooldown.Minutes()))),
+		),
+	)
+}
+
+// Handle processes the AddKeywordWatch tool request
+func (h *KeywordWatchAddHandler) Handle(_ context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	pattern := mcp.ParseString(request, "pattern", "")
+	if pattern == "" {
+		return mcp.NewToolResultError("pattern is required"), nil
+	}
+	action := mcp.ParseString(request, "action", watch.ActionForward)
+	if action != watch.ActionForward && action != watch.ActionNotify {
+		return mcp.NewToolResultError(fmt.Sprintf("invalid action: %s (use 'forward' or 'notify')", action)), nil
+	}
+	cooldownMinutes := mcp.ParseFloat64(request, "cooldown_minutes", watch.DefaultCooldown.Minutes())
+	if cooldownMinutes <= 0 {
+		return mcp.NewToolResultError("cooldown_minutes must be positive"), nil
+	}
+
+	w, err := h.store.Add(watch.Watch{
+		Pattern:       pattern,
+		Regex:         mcp.ParseBoolean(request, "regex", false),
+		CaseSensitive: mcp.ParseBoolean(request, "case_sensitive", false),
+		ChatIDs:       int64Slice(request.GetIntSlice("chat_ids", nil)),
+		Action:        action,
+		Cooldown:      time.Duration(cooldownMinutes * float64(time.Minute)),
+		CreatedAt:     time.Now(),
+	})
+	if err != nil {
+		if errors.Is(err, watch.ErrInvalidPattern) {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		return toolError("save keyword watch", err), nil
+	}
+	return jsonResult(w)
+}
+
+// KeywordWatchListHandler handles the ListKeywordWatches tool
+type KeywordWatchListHandler struct {
+	store *watch.Store
+}
+
+// NewKeywordWatchListHandler creates a new KeywordWatchListHandler
+func NewKeywordWatchListHandler(store *watch.Store) *KeywordWatchListHandler {
+	return &KeywordWatchListHandler{store: store}
+}
+
+// Tool returns the MCP tool definition
+func (h *KeywordWatchListHandler) Tool() mcp.Tool {
+	return mcp.NewTool("ListKeywordWatches",
+		mcp.WithDescription("List keyword watches with their settings, hit and alert counts, and the latest hit."),
+		mcp.WithReadOnlyHintAnnotation(true),
+	)
+}
+
+// Handle processes the ListKeywordWatches tool request
+func (h *KeywordWatchListHandler) Handle(_ context.Context, _ mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	ws := h.store.List()
+	if ws == nil {
+		ws = []watch.Watch{}
+	}
+	return jsonResult(map[string]any{"watches": ws})
+}
+
+// KeywordWatchRemoveHandler handles the RemoveKeywordWatch tool
+type KeywordWatchRemoveHandler struct {
+	store *watch.Store
+}
+
+// NewKeywordWatchRemoveHandler creates a new KeywordWatchRemoveHandler
+func NewKeywordWatchRemoveHandler(store *watch.Store) *KeywordWatchRemoveHandler {
+	return &KeywordWatchRemoveHandler{store: store}
+}
+
+// Tool returns the MCP tool definition
+func (h *KeywordWatchRemoveHandler) Tool() mcp.Tool {
+	return mcp.NewTool("RemoveKeywordWatch",
+		mcp.WithDescription("Stop a keyword watch."),
+		mcp.WithDestructiveHintAnnotation(true),
+		mcp.WithNumber("id",
+			mcp.Description("The watch ID from AddKeywordWatch or ListKeywordWatches"),
+			mcp.Required(),
+		),
+	)
+}
+
+// Handle processes the RemoveKeywordWatch tool request
+func (h *KeywordWatchRemoveHandler) Handle(_ context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	id := mcp.ParseInt(request, "id", 0)
+	if id <= 0 {
+		return mcp.NewToolResultError("id is required"), nil
+	}
+	if err := h.store.Remove(id); err != nil {
+		if errors.Is(err, watch.ErrNotFound) {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		return toolError("remove keyword watch", err), nil
+	}
+	return mcp.NewToolResultText(fmt.Sprintf("Keyword watch %d removed.", id)), nil
+}
+
+// KeywordWatchUpdateHandler returns a handler checking incoming messages of any chat
+// against the store's watches. Alerts go to Saved Messages through the provider's
+// rate limiter.
+func KeywordWatchUpdateHandler(provider *messages.Provider, store *watch.Store) func(ctx context.Context, e tg.Entities, msg tg.MessageClass) error {
+	return func(ctx context.Context, e tg.Entities, msgClass tg.MessageClass) error {
+		hit, ok := keywordWatchMessage(e, msgClass)
+		if !ok {
+			return nil
+		}
+		alerts, err := store.Hit(hit.chatID, hit.msg.ID, hit.msg.Message, time.Now())
+		if err != nil || len(alerts) == 0 {
+			return err
+		}
+
+		client := provider.Paced()
+		var errs []error
+		for _, w := range alerts {
+			if err := sendKeywordAlert(ctx, client, provider.Peers(), w, hit); err != nil {
+				errs = append(errs, fmt.Errorf("sending keyword watch %d alert: %w", w.ID, err))
+			}
+		}
+		return errors.Join(errs...)
+	}
+}
+
+// keywordHit is an incoming message to check against keyword watches.
+type keywordHit struct {
+	msg      *tg.Message
+	chatID   int64  // Dialog ID (-100 prefixed for channels)
+	chatName string // Empty when the entities don't include the chat
+	link     string // Empty for private chats and basic groups
+}
+
+// keywordWatchMessage returns the message to check, or false for outgoing, empty and
+// service messages. Outgoing messages include the alerts in Saved Messages.
+func keywordWatchMessage(e tg.Entities, msgClass tg.MessageClass) (keywordHit, bool) {
+	msg, ok := msgClass.(*tg.Message)
+	if !ok || msg.Out || msg.Message == "" {
+		return keywordHit{}, false
+	}
+	hit := keywordHit{msg: msg}
+	switch peer := msg.PeerID.(type) {
+	case *tg.PeerUser:
+		hit.chatID = peer.UserID
+		if user, ok := e.Users[peer.UserID]; ok {
+			if user.Self {
+				return keywordHit{}, false
+			}
+			hit.chatName = tgclient.UserName(user)
+		}
+	case *tg.PeerChat:
+		hit.chatID = peer.ChatID
+		if chat, ok := e.Chats[peer.ChatID]; ok {
+			hit.chatName = chat.Title
+		}
+	case *tg.PeerChannel:
+		hit.chatID = tgclient.ChannelDialogID(peer.ChannelID)
+		username := ""
+		if channel, ok := e.Channels[peer.ChannelID]; ok {
+			hit.chatName = channel.Title
+			username = channel.Username
+		}
+		hit.link = messages.MessageLink(username, peer.ChannelID, msg.ID)
+	default:
+		return keywordHit{}, false
+	}
+	return hit, true
+}
+
+// keywordAlertText is the notification sent for a hit of w.
+func keywordAlertText(w watch.Watch, hit keywordHit) string {
+	chat := hit.chatName
+	if chat == "" {
+		chat = fmt.Sprintf("chat %d", hit.chatID)
+	}
+	text := fmt.Sprintf("Keyword watch %d (%q) matched in %s, message %d:\n%s", w.ID, w.Pattern, chat, hit.msg.ID, messages.TruncateRunes(hit.msg.Message, sentTextSnippetRunes))
+	if hit.link != "" {
+		text += "\n" + hit.link
+	}
+	return text
+}
+
+// sendKeywordAlert forwards the hit to Saved Messages or sends a notification there.
+// Chats with protected content can't be forwarded from, so those get a notification.
+func sendKeywordAlert(ctx context.Context, client *tg.Client, peers *tgclient.PeerCache, w watch.Watch, hit keywordHit) error {
+	if w.Action != watch.ActionNotify {
+		err := peers.WithPeer(ctx, client, hit.chatID, func(peer tg.InputPeerClass) error {
+			_, err := client.MessagesForwardMessages(ctx, &tg.MessagesForwardMessagesRequest{
+				FromPeer: peer,
+				ID:       []int{hit.msg.ID},
+				ToPeer:   &tg.InputPeerSelf{},
+				RandomID: []int64{time.Now().UnixNano()},
+			})
+			return err
+		})
+		if err == nil {
+			return nil
+		}
+	}
+	_, err := client.MessagesSendMessage(ctx, &tg.MessagesSendMessageRequest{
+		Peer:     &tg.InputPeerSelf{},
+		Message:  keywordAlertText(w, hit),
+		RandomID: time.Now().UnixNano(),
+	})
+	return err
+}
@@ -0,0 +1,62 @@
+package tools
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gotd/td/tg"
+
+	"github.com/tolmachov/mcp-telegram/internal/watch"
+)
+
+func TestKeywordWatchMessage(t *testing.T) {
+	entities := tg.Entities{
+		Users:    map[int64]*tg.User{1: {ID: 1, FirstName: "Anna"}, 3: {ID: 3, FirstName: "Me", Self: true}},
+		Chats:    map[int64]*tg.Chat{5: {ID: 5, Title: "Family"}},
+		Channels: map[int64]*tg.Channel{7: {ID: 7, Title: "News", Username: "news"}, 8: {ID: 8, Title: "Team", Megagroup: true}},
+	}
+	incoming := func(peer tg.PeerClass) tg.MessageClass {
+		return &tg.Message{ID: 10, PeerID: peer, Message: "invoice"}
+	}
+
+	tests := []struct {
+		name     string
+		msg      tg.MessageClass
+		wantOK   bool
+		wantChat int64
+		wantName string
+		wantLink string
+	}{
+		{"private", incoming(&tg.PeerUser{UserID: 1}), true, 1, "Anna", ""},
+		{"basic group", incoming(&tg.PeerChat{ChatID: 5}), true, 5, "Family", ""},
+		{"public channel", incoming(&tg.PeerChannel{ChannelID: 7}), true, -1000000000007, "News", "https://t.me/news/10"},
+		{"private supergroup", incoming(&tg.PeerChannel{ChannelID: 8}), true, -1000000000008, "Team", "https://t.me/c/8/10"},
+		{"unknown chat", incoming(&tg.PeerChat{ChatID: 6}), true, 6, "", ""},
+		{"saved messages", incoming(&tg.PeerUser{UserID: 3}), false, 0, "", ""},
+		{"outgoing", &tg.Message{Out: true, PeerID: &tg.PeerUser{UserID: 1}, Message: "invoice"}, false, 0, "", ""},
+		{"no text", &tg.Message{PeerID: &tg.PeerUser{UserID: 1}}, false, 0, "", ""},
+		{"service message", &tg.MessageService{PeerID: &tg.PeerUser{UserID: 1}}, false, 0, "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hit, ok := keywordWatchMessage(entities, tt.msg)
+			if ok != tt.wantOK {
+				t.Fatalf("keywordWatchMessage() ok = %v, want %v", ok, tt.wantOK)
+			}
+			if hit.chatID != tt.wantChat || hit.chatName != tt.wantName || hit.link != tt.wantLink {
+				t.Errorf("keywordWatchMessage() = %d %q %q, want %d %q %q", hit.chatID, hit.chatName, hit.link, tt.wantChat, tt.wantName, tt.wantLink)
+			}
+		})
+	}
+}
+
+func TestKeywordAlertText(t *testing.T) {
+	hit := keywordHit{msg: &tg.Message{ID: 10, Message: "Invoice #42"}, chatID: -1000000000008, link: "https://t.me/c/8/10"}
+	got := keywordAlertText(watch.Watch{ID: 2, Pattern: "invoice"}, hit)
+	for _, want := range []string{`Keyword watch 2 ("invoice")`, "chat -1000000000008", "Invoice #42", "https://t.me/c/8/10"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("keywordAlertText() = %q, missing %q", got, want)
+		}
+	}
+}
@@ -0,0 +1,214 @@
+package tools
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/tolmachov/mcp-telegram/internal/messages"
+	"github.com/tolmachov/mcp-telegram/internal/stats"
+)
+
+const (
+	defaultExtractLinksLimit = 200
+	maxExtractLinksLimit     = 1000
+	// linkContextRunes limits the message text shown around a link.
+	linkContextRunes = 200
+)
+
+// SharedLink is a URL posted in a chat, with the newest message that shared it.
+type SharedLink struct {
+	URL       string    `json:"url"`
+	Domain    string    `json:"domain"`
+	MessageID int       `json:"message_id"`
+	Date      time.Time `json:"date"`
+	Sender    string    `json:"sender,omitempty"`
+	Context   string    `json:"context,omitempty"` // Text of the message
+	Link      string    `json:"link,omitempty"`    // t.me link to the message, in channels and supergroups
+	Shares    int       `json:"shares"`            // Messages that shared the URL
+}
+
+// extractLinksResult is the ExtractLinks output.
+type extractLinksResult struct {
+	ChatID    int64               `json:"chat_id"`
+	Since     time.Time           `json:"since"`
+	Until     time.Time           `json:"until"`
+	Scanned   int                 `json:"messages_scanned"`
+	Count     int                 `json:"count"`
+	Domains   []stats.DomainCount `json:"domains"` // Distinct URLs per domain, most first
+	Links     []SharedLink        `json:"links"`   // Newest first
+	Truncated bool                `json:"truncated,omitempty"`
+}
+
+// LinksExtractHandler handles the ExtractLinks tool
+type LinksExtractHandler struct {
+	provider *messages.Provider
+}
+
+// NewLinksExtractHandler creates a new LinksExtractHandler
+func NewLinksExtractHandler(provider *messages.Provider) *LinksExtractHandler {
+	return &LinksExtractHandler{provider: provider}
+}
+
+// Tool returns the MCP tool definition
+func (h *LinksExtractHandler) Tool() mcp.Tool {
+	return mcp.NewTool("ExtractLinks",
+		mcp.WithDescription("List the links shared in a chat over a time window, newest first and without duplicates: each URL with its domain, sender, date, the text of the message as context and how often it was shared, plus a count per domain. Much cheaper than a summary when the question is just which links were shared."),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithNumber("chat_id",
+			mcp.Description("The chat ID"),
+			mcp.Required(),
+		),
+		mcp.WithString("period",
+			mcp.Description("Window ending now: 'day', 'week', or 'month' (default: 'week'). Ignored when since is set"),
+		),
+		mcp.WithString("since",
+			mcp.Description("Window start, YYYY-MM-DD or YYYY-MM-DD HH:MM:SS (alternative to period)"),
+		),
+		mcp.WithString("until",
+			mcp.Description("Window end, YYYY-MM-DD (inclusive) or YYYY-MM-DD HH:MM:SS (default: now)"),
+		),
+		mcp.WithString("domain",
+			mcp.Description("Only links to this domain or its subdomains, e.g. 'github.com'"),
+		),
+		mcp.WithNumber("limit",
+			mcp.Description(fmt.Sprintf("Maximum links to return (default: %d, max: %d)", defaultExtractLinksLimit, maxExtractLinksLimit)),
+		),
+	)
+}
+
+// Handle processes the ExtractLinks tool request
+func (h *LinksExtractHandler) Handle(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	chatID := mcp.ParseInt64(request, "chat_id", 0)
+	if chatID == 0 {
+		return mcp.NewToolResultError("chat_id is required"), nil
+	}
+	limit := mcp.ParseInt(request, "limit", defaultExtractLinksLimit)
+	if limit <= 0 || limit > maxExtractLinksLimit {
+		return mcp.NewToolResultError(fmt.Sprintf("limit must be between 1 and %d", maxExtractLinksLimit)), nil
+	}
+	domain := mcp.ParseString(request, "domain", "")
+	if domain != "" {
+		if domain = messages.LinkDomain(domain); domain == "" {
+			return mcp.NewToolResultError("invalid domain"), nil
+		}
+	}
+
+	since, until, err := statsWindow(
+		mcp.ParseString(request, "period", "week"),
+		mcp.ParseString(request, "since", ""),
+		mcp.ParseString(request, "until", ""),
+		time.Now(),
+	)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	fetched, err := h.provider.FetchAll(ctx, chatID, messages.FetchOptions{
+		MinDate:      since,
+		MaxDate:      until,
+		MaxDateExact: true,
+	}, nil)
+	if err != nil {
+		return toolError("extract links", err), nil
+	}
+
+	links := sharedLinks(fetched.Messages, domain)
+	result := extractLinksResult{
+		ChatID:  chatID,
+		Since:   since,
+		Until:   until,
+		Scanned: len(fetched.Messages),
+		Count:   len(links),
+		Domains: linkDomains(links),
+		Links:   links,
+	}
+	if len(links) > limit {
+		result.Links = links[:limit]
+		result.Truncated = true
+	}
+	return jsonResult(result)
+}
+
+// sharedLinks collects the URLs of msgs from their URL entities and webpage previews,
+// once per URL, newest first. A non-empty domain keeps only links to it or its
+// subdomains.
+func sharedLinks(msgs []messages.Message, domain string) []SharedLink {
+	byURL := make(map[string]*SharedLink)
+	for _, msg := range msgs {
+		urls := slices.Clone(msg.Entities)
+		if msg.Media != nil && msg.Media.URL != "" {
+			urls = append(urls, msg.Media.URL)
+		}
+
+		seen := make(map[string]bool, len(urls))
+		for _, u := range urls {
+			// Links typed without a scheme are the same as their preview's URL
+			if !strings.Contains(u, "://") {
+				u = "https://" + u
+			}
+			if seen[u] {
+				continue
+			}
+			seen[u] = true
+			d := messages.LinkDomain(u)
+			if d == "" || (domain != "" && d != domain && !strings.HasSuffix(d, "."+domain)) {
+				continue
+			}
+
+			link, ok := byURL[u]
+			if !ok {
+				link = &SharedLink{URL: u, Domain: d}
+				byURL[u] = link
+			}
+			link.Shares++
+			if ok && !msg.Date.After(link.Date) {
+				continue
+			}
+			link.MessageID = msg.ID
+			link.Date = msg.Date
+			link.Sender = msg.SenderName
+			link.Context = messages.TruncateRunes(msg.Text, linkContextRunes)
+			link.Link = msg.Link
+		}
+	}
+
+	links := make([]SharedLink, 0, len(byURL))
+	for _, link := range byURL {
+		links = append(links, *link)
+	}
+	slices.SortFunc(links, func(a, b SharedLink) int {
+		if c := b.Date.Compare(a.Date); c != 0 {
+			return c
+		}
+		if c := cmp.Compare(b.MessageID, a.MessageID); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.URL, b.URL)
+	})
+	return links
+}
+
+// linkDomains counts the distinct links per domain, most linked first.
+func linkDomains(links []SharedLink) []stats.DomainCount {
+	counts := make(map[string]int)
+	for _, link := range links {
+		counts[link.Domain]++
+	}
+	domains := make([]stats.DomainCount, 0, len(counts))
+	for d, n := range counts {
+		domains = append(domains, stats.DomainCount{Domain: d, Links: n})
+	}
+	slices.SortFunc(domains, func(a, b stats.DomainCount) int {
+		if c := cmp.Compare(b.Links, a.Links); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.Domain, b.Domain)
+	})
+	return domains
+}
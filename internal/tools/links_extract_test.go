@@ -0,0 +1,46 @@
+package tools
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/tolmachov/mcp-telegram/internal/messages"
+	"github.com/tolmachov/mcp-telegram/internal/stats"
+)
+
+func TestSharedLinks(t *testing.T) {
+	day := time.Date(2024, 3, 1, 10, 0, 0, 0, time.Local)
+	msgs := []messages.Message{
+		{ID: 3, Date: day.Add(2 * time.Hour), SenderName: "Bob", Text: "again https://github.com/golang/go", Entities: []string{"https://github.com/golang/go"}, Link: "https://t.me/group/3"},
+		{ID: 2, Date: day.Add(time.Hour), SenderName: "Anna", Text: "docs", Media: &messages.MediaInfo{Type: "webpage", URL: "https://go.dev/doc"}, Entities: []string{"go.dev/doc"}},
+		{ID: 1, Date: day, SenderName: "Anna", Text: "see https://github.com/golang/go and https://gist.github.com/x", Entities: []string{"https://github.com/golang/go", "https://gist.github.com/x"}},
+		{ID: 0, Date: day.Add(-time.Hour), Text: "no links"},
+	}
+
+	links := sharedLinks(msgs, "")
+	var urls []string
+	for _, l := range links {
+		urls = append(urls, l.URL)
+	}
+	wantURLs := []string{"https://github.com/golang/go", "https://go.dev/doc", "https://gist.github.com/x"}
+	if !reflect.DeepEqual(urls, wantURLs) {
+		t.Fatalf("urls = %v, want %v", urls, wantURLs)
+	}
+	if got := links[1]; got.Shares != 1 {
+		t.Errorf("link typed without a scheme counted apart from its preview: %+v", got)
+	}
+	if got := links[0]; got.Shares != 2 || got.MessageID != 3 || got.Sender != "Bob" || got.Link != "https://t.me/group/3" || got.Domain != "github.com" {
+		t.Errorf("repeated link = %+v, want the newest of 2 shares", got)
+	}
+
+	wantDomains := []stats.DomainCount{{Domain: "gist.github.com", Links: 1}, {Domain: "github.com", Links: 1}, {Domain: "go.dev", Links: 1}}
+	if got := linkDomains(links); !reflect.DeepEqual(got, wantDomains) {
+		t.Errorf("linkDomains() = %+v, want %+v", got, wantDomains)
+	}
+
+	filtered := sharedLinks(msgs, "github.com")
+	if len(filtered) != 2 || filtered[0].Domain != "github.com" || filtered[1].Domain != "gist.github.com" {
+		t.Errorf("domain filter = %+v, want github.com and its subdomain", filtered)
+	}
+}
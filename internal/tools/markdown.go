@@ -0,0 +1,259 @@
+package tools
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/gotd/td/telegram/message/entity"
+	"github.com/gotd/td/tg"
+)
+
+// markdownEmphasis are the emphasis delimiters, longest first so "**" wins over "*".
+var markdownEmphasis = []struct {
+	delim  string
+	format func() entity.Formatter
+}{
+	{"**", entity.Bold},
+	{"__", entity.Bold},
+	{"~~", entity.Strike},
+	{"*", entity.Italic},
+	{"_", entity.Italic},
+}
+
+// markdownEntities converts basic markdown to plain text and Telegram message
+// entities: **bold** or __bold__, *italic* or _italic_, ~~strikethrough~~, `code`,
+// ```pre``` blocks with an optional language, and [links](url). Formats nest, a
+// backslash escapes a marker, and markers that aren't closed are kept as text.
+func markdownEntities(s string) (string, []tg.MessageEntityClass) {
+	var b entity.Builder
+	parseMarkdown(&b, s, nil)
+	text, entities := b.Complete()
+	return text, mergeAdjacentEntities(entities)
+}
+
+// parseMarkdown writes s to b with formats applied to all of it.
+func parseMarkdown(b *entity.Builder, s string, formats []entity.Formatter) {
+	var plain strings.Builder
+	flush := func() {
+		if plain.Len() > 0 {
+			b.Format(plain.String(), formats...)
+			plain.Reset()
+		}
+	}
+
+	for i := 0; i < len(s); {
+		rest := s[i:]
+		switch {
+		case rest[0] == '\\' && len(rest) > 1 && isMarkdownPunct(rest[1]):
+			plain.WriteByte(rest[1])
+			i += 2
+			continue
+
+		case strings.HasPrefix(rest, "```"):
+			if end := strings.Index(rest[3:], "```"); end >= 0 {
+				flush()
+				lang, code := preLanguage(rest[3 : 3+end])
+				b.Format(code, withFormat(formats, entity.Pre(lang))...)
+				i += 3 + end + 3
+				continue
+			}
+
+		case rest[0] == '`':
+			if end := strings.IndexByte(rest[1:], '`'); end > 0 {
+				flush()
+				b.Format(rest[1:1+end], withFormat(formats, entity.Code())...)
+				i += 1 + end + 1
+				continue
+			}
+
+		case rest[0] == '[':
+			if label, url, n, ok := markdownLink(rest); ok {
+				flush()
+				parseMarkdown(b, label, withFormat(formats, entity.TextURL(url)))
+				i += n
+				continue
+			}
+
+		default:
+			if inner, n, format, ok := markdownEmphasisSpan(s, i); ok {
+				flush()
+				parseMarkdown(b, inner, withFormat(formats, format))
+				i += n
+				continue
+			}
+		}
+
+		r, size := utf8.DecodeRuneInString(rest)
+		plain.WriteRune(r)
+		i += size
+	}
+	flush()
+}
+
+// markdownEmphasisSpan matches an emphasis span starting at s[i], returning its
+// content, its length including the delimiters and its format.
+func markdownEmphasisSpan(s string, i int) (string, int, entity.Formatter, bool) {
+	rest := s[i:]
+	for _, e := range markdownEmphasis {
+		if !strings.HasPrefix(rest, e.delim) {
+			continue
+		}
+		// Underscores inside words, as in snake_case, are not emphasis
+		if e.delim[0] == '_' && i > 0 && isWordRune(lastRune(s[:i])) {
+			return "", 0, nil, false
+		}
+		end := closingDelimiter(rest[len(e.delim):], e.delim)
+		if end <= 0 {
+			continue
+		}
+		inner := rest[len(e.delim) : len(e.delim)+end]
+		after := rest[len(e.delim)+end+len(e.delim):]
+		if e.delim[0] == '_' && after != "" && isWordRune(firstRune(after)) {
+			continue
+		}
+		return inner, len(e.delim) + end + len(e.delim), e.format(), true
+	}
+	return "", 0, nil, false
+}
+
+// closingDelimiter returns the index of the delimiter closing an emphasis span in s,
+// or -1. The content must not start or end with a space, escaped characters and code
+// spans are skipped, and a single-character delimiter doesn't match a doubled one.
+func closingDelimiter(s, delim string) int {
+	if s == "" || s[0] == ' ' {
+		return -1
+	}
+	for i := 0; i < len(s); i++ {
+		switch {
+		case s[i] == '\\':
+			i++
+		case s[i] == '`':
+			if end := strings.IndexByte(s[i+1:], '`'); end >= 0 {
+				i += end + 1
+			}
+		case strings.HasPrefix(s[i:], delim):
+			if len(delim) == 1 && i+1 < len(s) && s[i+1] == delim[0] {
+				i++
+				continue
+			}
+			if i > 0 && s[i-1] != ' ' {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// markdownLink matches a [label](url) link at the start of s, returning its label,
+// URL and length.
+func markdownLink(s string) (label, url string, n int, ok bool) {
+	closeLabel := strings.Index(s, "](")
+	if closeLabel <= 1 {
+		return "", "", 0, false
+	}
+	closeURL := strings.IndexByte(s[closeLabel+2:], ')')
+	if closeURL <= 0 {
+		return "", "", 0, false
+	}
+	url = s[closeLabel+2 : closeLabel+2+closeURL]
+	if strings.ContainsAny(url, " \n") {
+		return "", "", 0, false
+	}
+	return s[1:closeLabel], url, closeLabel + 2 + closeURL + 1, true
+}
+
+// preLanguage splits a ``` block into its language, given as a single word on the
+// opening line, and its code without the newlines next to the fences.
+func preLanguage(block string) (lang, code string) {
+	firstLine, rest, found := strings.Cut(block, "\n")
+	if found && firstLine != "" && !strings.ContainsAny(firstLine, " \t") {
+		return firstLine, strings.TrimSuffix(rest, "\n")
+	}
+	return "", strings.TrimSuffix(strings.TrimPrefix(block, "\n"), "\n")
+}
+
+// mergeAdjacentEntities joins entities of the same kind that touch, which nested
+// formats split into pieces, e.g. the bold around an italic word.
+func mergeAdjacentEntities(entities []tg.MessageEntityClass) []tg.MessageEntityClass {
+	sortEntities(entities)
+	merged := make([]tg.MessageEntityClass, 0, len(entities))
+	last := make(map[string]int) // entity kind → index in merged
+	for _, e := range entities {
+		key := entityKind(e)
+		if j, ok := last[key]; ok && merged[j].GetOffset()+merged[j].GetLength() == e.GetOffset() {
+			setEntityLength(merged[j], merged[j].GetLength()+e.GetLength())
+			continue
+		}
+		last[key] = len(merged)
+		merged = append(merged, e)
+	}
+	sortEntities(merged)
+	return merged
+}
+
+// sortEntities orders entities by offset, enclosing ones first. entity.SortEntities
+// isn't a strict ordering and can mix up entities at different offsets.
+func sortEntities(entities []tg.MessageEntityClass) {
+	slices.SortStableFunc(entities, func(a, b tg.MessageEntityClass) int {
+		if a.GetOffset() != b.GetOffset() {
+			return cmp.Compare(a.GetOffset(), b.GetOffset())
+		}
+		return cmp.Compare(b.GetLength(), a.GetLength())
+	})
+}
+
+// entityKind identifies entities that may be merged: the same type and, for links
+// and code blocks, the same URL or language.
+func entityKind(e tg.MessageEntityClass) string {
+	switch e := e.(type) {
+	case *tg.MessageEntityTextURL:
+		return "url:" + e.URL
+	case *tg.MessageEntityPre:
+		return "pre:" + e.Language
+	default:
+		return fmt.Sprintf("%T", e)
+	}
+}
+
+func setEntityLength(e tg.MessageEntityClass, length int) {
+	switch e := e.(type) {
+	case *tg.MessageEntityBold:
+		e.Length = length
+	case *tg.MessageEntityItalic:
+		e.Length = length
+	case *tg.MessageEntityStrike:
+		e.Length = length
+	case *tg.MessageEntityCode:
+		e.Length = length
+	case *tg.MessageEntityPre:
+		e.Length = length
+	case *tg.MessageEntityTextURL:
+		e.Length = length
+	}
+}
+
+func withFormat(formats []entity.Formatter, format entity.Formatter) []entity.Formatter {
+	return append(formats[:len(formats):len(formats)], format)
+}
+
+func isMarkdownPunct(c byte) bool {
+	return strings.IndexByte("\\`*_~[]()", c) >= 0
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+func firstRune(s string) rune {
+	r, _ := utf8.DecodeRuneInString(s)
+	return r
+}
+
+func lastRune(s string) rune {
+	r, _ := utf8.DecodeLastRuneInString(s)
+	return r
+}
@@ -0,0 +1,120 @@
+package tools
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/gotd/td/tg"
+
+	"github.com/tolmachov/mcp-telegram/internal/messages"
+)
+
+func TestMarkdownEntities(t *testing.T) {
+	tests := []struct {
+		name     string
+		in       string
+		text     string
+		entities []tg.MessageEntityClass
+	}{
+		{
+			name: "plain",
+			in:   "just text",
+			text: "just text",
+		},
+		{
+			name: "bold italic code",
+			in:   "**bold** and *italic* and `x := 1`",
+			text: "bold and italic and x := 1",
+			entities: []tg.MessageEntityClass{
+				&tg.MessageEntityBold{Offset: 0, Length: 4},
+				&tg.MessageEntityItalic{Offset: 9, Length: 6},
+				&tg.MessageEntityCode{Offset: 20, Length: 6},
+			},
+		},
+		{
+			name: "nested",
+			in:   "**bold _both_ bold**",
+			text: "bold both bold",
+			entities: []tg.MessageEntityClass{
+				&tg.MessageEntityBold{Offset: 0, Length: 14},
+				&tg.MessageEntityItalic{Offset: 5, Length: 4},
+			},
+		},
+		{
+			name: "link with formatted label",
+			in:   "see [the **docs**](https://example.com/docs)",
+			text: "see the docs",
+			entities: []tg.MessageEntityClass{
+				&tg.MessageEntityTextURL{Offset: 4, Length: 8, URL: "https://example.com/docs"},
+				&tg.MessageEntityBold{Offset: 8, Length: 4},
+			},
+		},
+		{
+			name: "cyrillic",
+			in:   "Привет, **мир** и ~~старый~~ _новый_ день",
+			text: "Привет, мир и старый новый день",
+			entities: []tg.MessageEntityClass{
+				&tg.MessageEntityBold{Offset: 8, Length: 3},
+				&tg.MessageEntityStrike{Offset: 14, Length: 6},
+				&tg.MessageEntityItalic{Offset: 21, Length: 5},
+			},
+		},
+		{
+			name: "emoji counts as two UTF-16 units",
+			in:   "🎉 **готово**",
+			text: "🎉 готово",
+			entities: []tg.MessageEntityClass{
+				&tg.MessageEntityBold{Offset: 3, Length: 6},
+			},
+		},
+		{
+			name: "pre with language",
+			in:   "run:\n```go\nfmt.Println(\"**hi**\")\n```",
+			text: "run:\nfmt.Println(\"**hi**\")",
+			entities: []tg.MessageEntityClass{
+				&tg.MessageEntityPre{Offset: 5, Length: 21, Language: "go"},
+			},
+		},
+		{
+			name: "literal markers",
+			in:   `snake_case_name, 2 * 3 * 4, \*not italic\*, **unclosed`,
+			text: "snake_case_name, 2 * 3 * 4, *not italic*, **unclosed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			text, entities := markdownEntities(tt.in)
+			if text != tt.text {
+				t.Errorf("text = %q, want %q", text, tt.text)
+			}
+			if len(entities) == 0 && len(tt.entities) == 0 {
+				return
+			}
+			if !reflect.DeepEqual(entities, tt.entities) {
+				t.Errorf("entities = %s, want %s", formatEntities(entities), formatEntities(tt.entities))
+			}
+		})
+	}
+}
+
+// Entity offsets are in UTF-16 code units, so emoji before or inside a format shift
+// them by two; every entity must stay within the text as Telegram counts it.
+func TestMarkdownEntitiesWithinText(t *testing.T) {
+	for _, in := range []string{"👋 **hi** there", "**🔥🔥**", "Привет _мир_ 🇺🇸 `x`", "[👋 link](https://example.com) ~~done~~"} {
+		text, entities := markdownEntities(in)
+		for _, e := range entities {
+			if e.GetOffset() < 0 || e.GetOffset()+e.GetLength() > messages.UTF16Len(text) {
+				t.Errorf("markdownEntities(%q): entity %s outside %q", in, formatEntities([]tg.MessageEntityClass{e}), text)
+			}
+		}
+	}
+}
+
+func formatEntities(entities []tg.MessageEntityClass) string {
+	s := ""
+	for _, e := range entities {
+		s += e.String() + " "
+	}
+	return s
+}
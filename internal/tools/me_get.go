@@ -3,7 +3,6 @@ package tools
 import (
 	"context"
 	"encoding/json"
-	"fmt"
 
 	"github.com/gotd/td/tg"
 	"github.com/mark3labs/mcp-go/mcp"
@@ -33,12 +32,12 @@ func (h *MeGetHandler) Tool() mcp.Tool {
 func (h *MeGetHandler) Handle(ctx context.Context, _ mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	info, err := tgdata.GetCurrentUser(ctx, h.client)
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Failed to get current user: %v", err)), nil
+		return toolError("get current user", err), nil
 	}
 
 	data, err := json.MarshalIndent(info, "", "  ")
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal user info: %v", err)), nil
+		return toolError("marshal user info", err), nil
 	}
 
 	return mcp.NewToolResultText(string(data)), nil
@@ -4,40 +4,199 @@ import (
 	"bytes"
 	"context"
 	"encoding/base64"
+	"errors"
 	"fmt"
+	"io"
+	"mime"
+	"net/http"
 	"net/url"
+	"os"
+	"path/filepath"
 	"regexp"
+	"slices"
 	"strconv"
+	"strings"
 
 	"github.com/gotd/td/telegram/downloader"
 	"github.com/gotd/td/tg"
+	"github.com/gotd/td/tgerr"
 	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/tolmachov/mcp-telegram/internal/messages"
+	"github.com/tolmachov/mcp-telegram/internal/tgclient"
+)
+
+const (
+	// maxInlineMediaBytes caps media returned inline as base64; larger files need a filepath.
+	maxInlineMediaBytes = 20 << 20
+	// maxInlineAVBytes caps voice notes and videos returned inline; larger ones are saved
+	// to a file under the allowed paths and the path is returned instead.
+	maxInlineAVBytes = 1 << 20
 )
 
+// errMediaTooLarge is returned once an inline download exceeds maxInlineMediaBytes.
+var errMediaTooLarge = fmt.Errorf("media is larger than the %d MB inline limit; pass filepath to save it to a file instead", maxInlineMediaBytes>>20)
+
+// photoSizeOrder lists Telegram photo size types from smallest to largest:
+// s (100px), m (320px), x (800px), y (1280px) and w (2560px).
+var photoSizeOrder = []string{"s", "m", "x", "y", "w"}
+
+// largestPhotoSize selects the largest available photo size.
+const largestPhotoSize = "largest"
+
 // MediaGetHandler handles the GetMedia tool
 type MediaGetHandler struct {
-	client *tg.Client
+	client       *tg.Client
+	dcs          tgclient.DCConnector
+	allowedPaths []string
 }
 
-// NewMediaGetHandler creates a new MediaGetHandler
-func NewMediaGetHandler(client *tg.Client) *MediaGetHandler {
-	return &MediaGetHandler{client: client}
+// NewMediaGetHandler creates a new MediaGetHandler.
+// dcs connects to other DCs for files stored there; allowedPaths restricts filepath output.
+func NewMediaGetHandler(client *tg.Client, dcs tgclient.DCConnector, allowedPaths []string) *MediaGetHandler {
+	return &MediaGetHandler{client: client, dcs: dcs, allowedPaths: allowedPaths}
 }
 
 // Tool returns the MCP tool definition
 func (h *MediaGetHandler) Tool() mcp.Tool {
 	return mcp.NewTool("GetMedia",
-		mcp.WithDescription("Get media (photo) from Telegram using a resource URI from message media."),
+		mcp.WithDescription(fmt.Sprintf("Get media from Telegram using a resource URI from message media: a photo (telegram://media/...) or a document, video, voice note or audio file (telegram://document/...). Media up to %d MB is returned inline; larger files must be saved with filepath. Voice notes and videos over %d MB are saved to a file under the allowed paths automatically and its path is returned. URIs expire after a while; fetch the message again for a fresh one.", maxInlineMediaBytes>>20, maxInlineAVBytes>>20)),
 		mcp.WithReadOnlyHintAnnotation(true),
 		mcp.WithString("uri",
 			mcp.Required(),
-			mcp.Description("The media resource URI (e.g., telegram://media/...)"),
+			mcp.Description("The media resource URI (e.g., telegram://media/... or telegram://document/...)"),
+		),
+		mcp.WithString("size",
+			mcp.Description("Photo size to download instead of the one in the URI: 's' (100px), 'm' (320px), 'x' (800px), 'y' (1280px), 'w' (2560px) or 'largest'. Falls back to the nearest available size"),
+			mcp.Enum("s", "m", "x", "y", "w", largestPhotoSize),
+		),
+		mcp.WithString("filepath",
+			mcp.Description("Save the media to this file instead of returning it inline. Must be within allowed paths"),
 		),
 	)
 }
 
-// mediaURIPattern matches: telegram://media/{id}/{access_hash}/{dc_id}/{thumb}?ref={base64}
-var mediaURIPattern = regexp.MustCompile(`^telegram://media/(\d+)/(-?\d+)/(\d+)/([a-zA-Z]+)\?ref=(.+)$`)
+// mediaURIPattern matches: telegram://media/{id}/{access_hash}/{dc_id}/{thumb}?ref={base64}[&sizes={types}]
+var mediaURIPattern = regexp.MustCompile(`^telegram://media/(\d+)/(-?\d+)/(\d+)/([a-zA-Z]+)\?ref=([^&]+)(?:&sizes=([a-zA-Z,]*))?$`)
+
+// documentURIPattern matches: telegram://document/{id}/{access_hash}/{dc_id}?ref={base64}[&kind={kind}&size={bytes}&mime={type}]
+var documentURIPattern = regexp.MustCompile(`^telegram://document/(\d+)/(-?\d+)/(\d+)\?ref=([^&]*)(?:&kind=([a-z]+))?(?:&size=(\d+))?(?:&mime=([^&]*))?$`)
+
+// mediaRef is a photo or document parsed from a resource URI.
+type mediaRef struct {
+	photo         bool
+	id            int64
+	accessHash    int64
+	dcID          int
+	thumb         string   // Photo size type to download
+	sizes         []string // Photo size types available, if the URI lists them
+	kind          string   // Document kind (messages.MediaDocument, MediaVideo, ...), empty if the URI predates it
+	size          int64    // Document size in bytes, 0 if unknown
+	mimeType      string   // Document MIME type, empty if unknown
+	fileReference []byte
+}
+
+// parseMediaURI parses a telegram://media/... or telegram://document/... URI.
+func parseMediaURI(uri string) (mediaRef, error) {
+	var ref mediaRef
+	var idStr, hashStr, dcStr, fileRefStr string
+	if m := mediaURIPattern.FindStringSubmatch(uri); m != nil {
+		ref.photo = true
+		idStr, hashStr, dcStr, ref.thumb, fileRefStr = m[1], m[2], m[3], m[4], m[5]
+		if m[6] != "" {
+			ref.sizes = strings.Split(m[6], ",")
+		}
+	} else if m := documentURIPattern.FindStringSubmatch(uri); m != nil {
+		idStr, hashStr, dcStr, fileRefStr = m[1], m[2], m[3], m[4]
+		ref.kind = m[5]
+		if m[6] != "" {
+			ref.size, _ = strconv.ParseInt(m[6], 10, 64)
+		}
+		var err error
+		if ref.mimeType, err = url.QueryUnescape(m[7]); err != nil {
+			return mediaRef{}, fmt.Errorf("invalid MIME type encoding: %w", err)
+		}
+	} else {
+		return mediaRef{}, fmt.Errorf("invalid media URI format: %s", uri)
+	}
+
+	var err error
+	if ref.id, err = strconv.ParseInt(idStr, 10, 64); err != nil {
+		return mediaRef{}, fmt.Errorf("invalid media ID: %w", err)
+	}
+	if ref.accessHash, err = strconv.ParseInt(hashStr, 10, 64); err != nil {
+		return mediaRef{}, fmt.Errorf("invalid access hash: %w", err)
+	}
+	if ref.dcID, err = strconv.Atoi(dcStr); err != nil {
+		return mediaRef{}, fmt.Errorf("invalid DC ID: %w", err)
+	}
+	fileRefEncoded, err := url.QueryUnescape(fileRefStr)
+	if err != nil {
+		return mediaRef{}, fmt.Errorf("invalid file reference encoding: %w", err)
+	}
+	if ref.fileReference, err = base64.URLEncoding.DecodeString(fileRefEncoded); err != nil {
+		return mediaRef{}, fmt.Errorf("invalid file reference: %w", err)
+	}
+	return ref, nil
+}
+
+// location returns the file location to download.
+func (r mediaRef) location() tg.InputFileLocationClass {
+	if r.photo {
+		return &tg.InputPhotoFileLocation{
+			ID:            r.id,
+			AccessHash:    r.accessHash,
+			FileReference: r.fileReference,
+			ThumbSize:     r.thumb,
+		}
+	}
+	return &tg.InputDocumentFileLocation{
+		ID:            r.id,
+		AccessHash:    r.accessHash,
+		FileReference: r.fileReference,
+	}
+}
+
+// selectPhotoSize picks the photo size type to download. Without a list of available
+// sizes the requested one is tried as is, and "largest" keeps the URI's size, which is
+// the largest. A requested size that isn't available falls back to the nearest smaller
+// one, or the smallest larger one.
+func selectPhotoSize(requested, uriThumb string, available []string) (string, error) {
+	if requested == "" {
+		return uriThumb, nil
+	}
+	rank := slices.Index(photoSizeOrder, requested)
+	if rank < 0 && requested != largestPhotoSize {
+		return "", fmt.Errorf("invalid size: %q (use 's', 'm', 'x', 'y', 'w' or 'largest')", requested)
+	}
+
+	var known []string
+	for _, size := range photoSizeOrder {
+		if slices.Contains(available, size) {
+			known = append(known, size)
+		}
+	}
+	if len(known) == 0 {
+		if requested == largestPhotoSize {
+			return uriThumb, nil
+		}
+		return requested, nil
+	}
+	if requested == largestPhotoSize {
+		return known[len(known)-1], nil
+	}
+
+	best := ""
+	for _, size := range known {
+		if slices.Index(photoSizeOrder, size) <= rank {
+			best = size
+		}
+	}
+	if best == "" {
+		best = known[0]
+	}
+	return best, nil
+}
 
 // Handle processes the GetMedia tool request
 func (h *MediaGetHandler) Handle(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
@@ -45,59 +204,219 @@ func (h *MediaGetHandler) Handle(ctx context.Context, request mcp.CallToolReques
 	if uri == "" {
 		return mcp.NewToolResultError("uri parameter is required"), nil
 	}
+	ref, err := parseMediaURI(uri)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
 
-	// Parse the URI
-	matches := mediaURIPattern.FindStringSubmatch(uri)
-	if matches == nil {
-		return mcp.NewToolResultError(fmt.Sprintf("invalid media URI format: %s", uri)), nil
+	what := "document"
+	if ref.photo {
+		what = "photo"
+		ref.thumb, err = selectPhotoSize(mcp.ParseString(request, "size", ""), ref.thumb, ref.sizes)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+	} else if mcp.ParseString(request, "size", "") != "" {
+		return mcp.NewToolResultError("size applies to photos only"), nil
 	}
 
-	mediaID, err := strconv.ParseInt(matches[1], 10, 64)
-	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("invalid media ID: %v", err)), nil
+	switch ref.kind {
+	case "", messages.MediaDocument:
+	case messages.MediaVoice:
+		what = "voice note"
+	default:
+		what = ref.kind
 	}
 
-	accessHash, err := strconv.ParseInt(matches[2], 10, 64)
-	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("invalid access hash: %v", err)), nil
+	if targetPath := mcp.ParseString(request, "filepath", ""); targetPath != "" {
+		return h.saveToFile(ctx, ref, what, targetPath)
+	}
+	if (ref.kind == messages.MediaVoice || ref.kind == messages.MediaVideo) && ref.size > maxInlineAVBytes {
+		if len(h.allowedPaths) == 0 {
+			return mcp.NewToolResultError(fmt.Sprintf("%s is %s, too large to return inline, and no allowed paths are configured to save it", what, formatFileSize(ref.size))), nil
+		}
+		return h.saveToFile(ctx, ref, what, filepath.Join(h.allowedPaths[0], "media", mediaFilename(ref)))
+	}
+	if ref.size > maxInlineMediaBytes {
+		return mcp.NewToolResultError(fmt.Sprintf("%s is %s, larger than the %d MB inline limit; pass filepath to save it to a file instead", what, formatFileSize(ref.size), maxInlineMediaBytes>>20)), nil
 	}
 
-	// DC ID is included in the URI but not used directly - the client handles DC transfer
-	_, err = strconv.Atoi(matches[3])
-	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("invalid DC ID: %v", err)), nil
+	buf := &cappedBuffer{limit: maxInlineMediaBytes}
+	if err := h.download(ctx, ref, buf); err != nil {
+		if errors.Is(err, errMediaTooLarge) {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		return downloadError(what, err), nil
 	}
 
-	thumbSize := matches[4]
+	data := base64.StdEncoding.EncodeToString(buf.Bytes())
+	if ref.photo {
+		return mcp.NewToolResultImage("Photo downloaded successfully", data, "image/jpeg"), nil
+	}
+	mimeType := ref.mimeType
+	if mimeType == "" {
+		mimeType = http.DetectContentType(buf.Bytes())
+	}
+	text := fmt.Sprintf("%s downloaded successfully (%s)", capitalize(what), formatFileSize(int64(buf.Len())))
+	switch {
+	case strings.HasPrefix(mimeType, "image/"):
+		return mcp.NewToolResultImage(text, data, mimeType), nil
+	case strings.HasPrefix(mimeType, "audio/"):
+		return mcp.NewToolResultAudio(text, data, mimeType), nil
+	}
+	return mcp.NewToolResultResource(text, mcp.BlobResourceContents{
+		URI:      uri,
+		MIMEType: mimeType,
+		Blob:     data,
+	}), nil
+}
 
-	fileRefEncoded, err := url.QueryUnescape(matches[5])
-	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("invalid file reference encoding: %v", err)), nil
+// mediaFilename names a file for a document saved without an explicit path, using
+// an extension for its MIME type.
+func mediaFilename(ref mediaRef) string {
+	ext := ".bin"
+	switch ref.mimeType {
+	case "audio/ogg":
+		ext = ".ogg" // mime lists .oga first
+	case "video/mp4":
+		ext = ".mp4"
+	default:
+		if exts, _ := mime.ExtensionsByType(ref.mimeType); len(exts) > 0 {
+			ext = exts[0]
+		}
+	}
+	kind := ref.kind
+	if kind == "" {
+		kind = messages.MediaDocument
+	}
+	return fmt.Sprintf("%s_%d%s", kind, ref.id, ext)
+}
+
+// formatFileSize formats a byte count for messages, in MB from 1 MB up.
+func formatFileSize(n int64) string {
+	if n < 1<<20 {
+		return fmt.Sprintf("%d bytes", n)
+	}
+	return fmt.Sprintf("%.1f MB", float64(n)/(1<<20))
+}
+
+// capitalize upper-cases the first letter of an ASCII word.
+func capitalize(s string) string {
+	if s == "" {
+		return s
 	}
+	return strings.ToUpper(s[:1]) + s[1:]
+}
+
+// saveToFile downloads the media to a file within the allowed paths.
+func (h *MediaGetHandler) saveToFile(ctx context.Context, ref mediaRef, what, targetPath string) (*mcp.CallToolResult, error) {
+	return saveFile(ctx, h.client, h.dcs, h.allowedPaths, ref.location(), what, targetPath)
+}
 
-	fileReference, err := base64.URLEncoding.DecodeString(fileRefEncoded)
+// saveFile downloads a file location to a file within the allowed paths.
+func saveFile(ctx context.Context, client *tg.Client, dcs tgclient.DCConnector, allowedPaths []string, loc tg.InputFileLocationClass, what, targetPath string) (*mcp.CallToolResult, error) {
+	if err := isPathAllowed(targetPath, allowedPaths); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if err := os.MkdirAll(filepath.Dir(targetPath), 0o750); err != nil {
+		return toolError("create directory", err), nil
+	}
+	f, err := os.OpenFile(targetPath, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o600)
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("invalid file reference: %v", err)), nil
+		return toolError("create file", err), nil
 	}
+	defer f.Close()
 
-	// Create the input photo file location
-	location := &tg.InputPhotoFileLocation{
-		ID:            mediaID,
-		AccessHash:    accessHash,
-		FileReference: fileReference,
-		ThumbSize:     thumbSize,
+	out := &fileSink{f: f}
+	if err := downloadFile(ctx, client, dcs, loc, out); err != nil {
+		_ = os.Remove(targetPath)
+		return downloadError(what, err), nil
 	}
+	absPath, _ := filepath.Abs(targetPath)
+	return mcp.NewToolResultText(fmt.Sprintf("Saved %s (%d bytes) to %s", what, out.n, absPath)), nil
+}
 
-	// Download the photo
-	dl := downloader.NewDownloader()
-	var buf bytes.Buffer
+// downloadError reports a failed download. File references in URIs expire after a
+// while, so an expired one asks for the message to be fetched again.
+func downloadError(what string, err error) *mcp.CallToolResult {
+	if tgerr.Is(err, "FILE_REFERENCE_EXPIRED", "FILE_REFERENCE_INVALID") {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to download %s: its file reference has expired. Fetch the message again (e.g. with GetMessages) and use the fresh resource_uri", what))
+	}
+	return toolError("download "+what, err)
+}
+
+// mediaSink receives a download and can be emptied to restart it.
+type mediaSink interface {
+	io.Writer
+	Reset() error
+}
 
-	_, err = dl.Download(h.client, location).Stream(ctx, &buf)
+// download streams the media to out.
+func (h *MediaGetHandler) download(ctx context.Context, ref mediaRef, out mediaSink) error {
+	return downloadFile(ctx, h.client, h.dcs, ref.location(), out)
+}
+
+// downloadFile streams a file location to out. Files stored on another DC fail with
+// FILE_MIGRATE_X; the download is then restarted over a connection to that DC.
+func downloadFile(ctx context.Context, client *tg.Client, dcs tgclient.DCConnector, loc tg.InputFileLocationClass, out mediaSink) error {
+	_, err := downloader.NewDownloader().Download(client, loc).Stream(ctx, out)
+	dc, ok := tgclient.FileMigrateDC(err)
+	if !ok || dcs == nil {
+		return err
+	}
+
+	if err := out.Reset(); err != nil {
+		return err
+	}
+	invoker, err := dcs.DC(ctx, dc, 1)
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("failed to download photo: %v", err)), nil
+		return fmt.Errorf("connecting to DC %d: %w", dc, err)
 	}
+	defer invoker.Close()
 
-	// Return as image
-	imageData := base64.StdEncoding.EncodeToString(buf.Bytes())
-	return mcp.NewToolResultImage("Photo downloaded successfully", imageData, "image/jpeg"), nil
+	_, err = downloader.NewDownloader().Download(tg.NewClient(invoker), loc).Stream(ctx, out)
+	return err
+}
+
+// cappedBuffer collects a download in memory, refusing to grow past limit.
+type cappedBuffer struct {
+	buf   bytes.Buffer
+	limit int
+}
+
+func (b *cappedBuffer) Write(p []byte) (int, error) {
+	if b.buf.Len()+len(p) > b.limit {
+		return 0, errMediaTooLarge
+	}
+	return b.buf.Write(p)
+}
+
+func (b *cappedBuffer) Reset() error {
+	b.buf.Reset()
+	return nil
+}
+
+func (b *cappedBuffer) Bytes() []byte { return b.buf.Bytes() }
+
+func (b *cappedBuffer) Len() int { return b.buf.Len() }
+
+// fileSink writes a download to a file, counting the bytes written.
+type fileSink struct {
+	f *os.File
+	n int64
+}
+
+func (s *fileSink) Write(p []byte) (int, error) {
+	n, err := s.f.Write(p)
+	s.n += int64(n)
+	return n, err
+}
+
+func (s *fileSink) Reset() error {
+	s.n = 0
+	if err := s.f.Truncate(0); err != nil {
+		return err
+	}
+	_, err := s.f.Seek(0, io.SeekStart)
+	return err
 }
@@ -0,0 +1,167 @@
+package tools
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gotd/td/tg"
+	"github.com/gotd/td/tgerr"
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/tolmachov/mcp-telegram/internal/messages"
+)
+
+func TestSelectPhotoSize(t *testing.T) {
+	tests := []struct {
+		name      string
+		requested string
+		uriThumb  string
+		available []string
+		want      string
+		wantErr   bool
+	}{
+		{name: "no size keeps the URI's", uriThumb: "y", available: []string{"s", "m", "y"}, want: "y"},
+		{name: "available size", requested: "m", uriThumb: "y", available: []string{"s", "m", "x", "y"}, want: "m"},
+		{name: "largest", requested: "largest", uriThumb: "x", available: []string{"m", "w", "s", "x"}, want: "w"},
+		{name: "falls back to nearest smaller", requested: "w", uriThumb: "y", available: []string{"s", "m", "y"}, want: "y"},
+		{name: "falls back to smallest larger", requested: "s", uriThumb: "y", available: []string{"x", "y"}, want: "x"},
+		{name: "unknown types ignored", requested: "largest", uriThumb: "y", available: []string{"i", "m", "a"}, want: "m"},
+		{name: "no sizes listed tries requested", requested: "x", uriThumb: "y", want: "x"},
+		{name: "no sizes listed, largest keeps URI's", requested: "largest", uriThumb: "y", want: "y"},
+		{name: "invalid size", requested: "huge", uriThumb: "y", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := selectPhotoSize(tt.requested, tt.uriThumb, tt.available)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("selectPhotoSize() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("selectPhotoSize() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseMediaURI(t *testing.T) {
+	ref, err := parseMediaURI("telegram://media/10/-20/2/y?ref=AQI=&sizes=s,m,x,y")
+	if err != nil {
+		t.Fatalf("parseMediaURI(photo) error = %v", err)
+	}
+	want := mediaRef{photo: true, id: 10, accessHash: -20, dcID: 2, thumb: "y", sizes: []string{"s", "m", "x", "y"}, fileReference: []byte{1, 2}}
+	if !reflect.DeepEqual(ref, want) {
+		t.Errorf("parseMediaURI(photo) = %+v, want %+v", ref, want)
+	}
+
+	// URIs from before sizes were listed
+	ref, err = parseMediaURI("telegram://media/10/20/2/x?ref=AQI=")
+	if err != nil || ref.thumb != "x" || ref.sizes != nil {
+		t.Errorf("parseMediaURI(legacy photo) = %+v, %v", ref, err)
+	}
+
+	ref, err = parseMediaURI("telegram://document/11/0/4?ref=AQI=&kind=voice&size=5000&mime=audio%2Fogg")
+	if err != nil {
+		t.Fatalf("parseMediaURI(document) error = %v", err)
+	}
+	want = mediaRef{id: 11, dcID: 4, kind: "voice", size: 5000, mimeType: "audio/ogg", fileReference: []byte{1, 2}}
+	if !reflect.DeepEqual(ref, want) {
+		t.Errorf("parseMediaURI(document) = %+v, want %+v", ref, want)
+	}
+
+	// URIs from before the kind, size and MIME type were added
+	ref, err = parseMediaURI("telegram://document/11/0/4?ref=")
+	if err != nil || ref.photo || ref.id != 11 || ref.dcID != 4 || ref.kind != "" || ref.size != 0 {
+		t.Errorf("parseMediaURI(legacy document) = %+v, %v", ref, err)
+	}
+
+	for _, bad := range []string{"telegram://chats", "telegram://media/x/1/2/y?ref=AA==", "telegram://media/1/2/3/y?ref=!!"} {
+		if _, err := parseMediaURI(bad); err == nil {
+			t.Errorf("parseMediaURI(%q) succeeded, want error", bad)
+		}
+	}
+}
+
+func TestMediaURIRoundTrip(t *testing.T) {
+	// Bytes whose URL-safe base64 uses '-', '_' and padding
+	fileRef := []byte{0xfb, 0xff, 0xfe, 0x01}
+
+	photo := &tg.Photo{ID: 5_000_000_000_123, AccessHash: -7_000_000_000_456, DCID: 4, FileReference: fileRef}
+	ref, err := parseMediaURI(messages.PhotoURI(photo, "y", []string{"m", "x", "y"}))
+	if err != nil {
+		t.Fatalf("parseMediaURI(PhotoURI) error = %v", err)
+	}
+	want := mediaRef{photo: true, id: photo.ID, accessHash: photo.AccessHash, dcID: 4, thumb: "y", sizes: []string{"m", "x", "y"}, fileReference: fileRef}
+	if !reflect.DeepEqual(ref, want) {
+		t.Errorf("photo round trip = %+v, want %+v", ref, want)
+	}
+
+	doc := &tg.Document{ID: 42, AccessHash: 99, DCID: 2, FileReference: fileRef, Size: 1 << 30, MimeType: "application/vnd.openxmlformats-officedocument.wordprocessingml.document"}
+	ref, err = parseMediaURI(messages.DocumentURI(doc, messages.MediaDocument))
+	if err != nil {
+		t.Fatalf("parseMediaURI(DocumentURI) error = %v", err)
+	}
+	want = mediaRef{id: 42, accessHash: 99, dcID: 2, kind: "document", size: 1 << 30, mimeType: doc.MimeType, fileReference: fileRef}
+	if !reflect.DeepEqual(ref, want) {
+		t.Errorf("document round trip = %+v, want %+v", ref, want)
+	}
+
+	// A MIME type with parameters must not leak into the other query values
+	doc = &tg.Document{ID: 43, DCID: 1, MimeType: "audio/ogg; codecs=opus&x=1", Size: 10}
+	ref, err = parseMediaURI(messages.DocumentURI(doc, messages.MediaVoice))
+	if err != nil || ref.mimeType != doc.MimeType || ref.kind != "voice" || ref.size != 10 {
+		t.Errorf("parseMediaURI(voice) = %+v, %v", ref, err)
+	}
+}
+
+func TestDownloadErrorExpiredReference(t *testing.T) {
+	result := downloadError("photo", fmt.Errorf("downloading: %w", tgerr.New(400, "FILE_REFERENCE_EXPIRED")))
+	if text := result.Content[0].(mcp.TextContent).Text; !result.IsError || !strings.Contains(text, "Fetch the message again") {
+		t.Errorf("downloadError(expired) = %q", text)
+	}
+	result = downloadError("photo", errors.New("boom"))
+	if text := result.Content[0].(mcp.TextContent).Text; text != "Failed to download photo: boom" {
+		t.Errorf("downloadError(other) = %q", text)
+	}
+}
+
+func TestCappedBuffer(t *testing.T) {
+	buf := &cappedBuffer{limit: 4}
+	if _, err := buf.Write([]byte("abc")); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	if _, err := buf.Write([]byte("de")); !errors.Is(err, errMediaTooLarge) {
+		t.Errorf("Write() past the limit error = %v, want errMediaTooLarge", err)
+	}
+	if err := buf.Reset(); err != nil || buf.Len() != 0 {
+		t.Errorf("Reset() = %v, Len() = %d", err, buf.Len())
+	}
+}
+
+func TestMediaFilename(t *testing.T) {
+	tests := []struct {
+		ref  mediaRef
+		want string
+	}{
+		{mediaRef{id: 1, kind: "voice", mimeType: "audio/ogg"}, "voice_1.ogg"},
+		{mediaRef{id: 2, kind: "video", mimeType: "video/mp4"}, "video_2.mp4"},
+		{mediaRef{id: 3, kind: "document", mimeType: "application/pdf"}, "document_3.pdf"},
+		{mediaRef{id: 4}, "document_4.bin"},
+	}
+	for _, tt := range tests {
+		if got := mediaFilename(tt.ref); got != tt.want {
+			t.Errorf("mediaFilename(%+v) = %q, want %q", tt.ref, got, tt.want)
+		}
+	}
+}
+
+func TestFormatFileSize(t *testing.T) {
+	if got := formatFileSize(512); got != "512 bytes" {
+		t.Errorf("formatFileSize(512) = %q", got)
+	}
+	if got := formatFileSize(35 << 20); got != "35.0 MB" {
+		t.Errorf("formatFileSize(35 MB) = %q", got)
+	}
+}
@@ -0,0 +1,98 @@
+package tools
+
+import (
+	"context"
+
+	"github.com/gotd/td/tg"
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/tolmachov/mcp-telegram/internal/tgclient"
+	"github.com/tolmachov/mcp-telegram/internal/tgdata"
+)
+
+// MentionsChat groups unread mentions by chat.
+type MentionsChat struct {
+	ChatID     int64            `json:"chat_id"`
+	ChatName   string           `json:"chat_name,omitempty"`
+	Mentions   []tgdata.Mention `json:"mentions"`
+	MarkedRead bool             `json:"marked_read,omitempty"`
+	UnreadLeft int              `json:"unread_left,omitempty"` // Unread not fetched, so not marked read
+	Error      string           `json:"error,omitempty"`
+}
+
+// MentionsGetHandler handles the GetMentions tool
+type MentionsGetHandler struct {
+	client *tg.Client
+	peers  *tgclient.PeerCache
+}
+
+// NewMentionsGetHandler creates a new MentionsGetHandler
+func NewMentionsGetHandler(client *tg.Client, peers *tgclient.PeerCache) *MentionsGetHandler {
+	return &MentionsGetHandler{client: client, peers: peers}
+}
+
+// Tool returns the MCP tool definition
+func (h *MentionsGetHandler) Tool() mcp.Tool {
+	return mcp.NewTool("GetMentions",
+		mcp.WithDescription("Get unread messages that mention me or reply to my messages, grouped by chat, with sender, text, date and a t.me link where the chat has message links."),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithNumber("chat_id",
+			mcp.Description("Chat to check (default: all chats with unread mentions)"),
+		),
+		chatRefOption("chat", "chat_id", "Chat to check"),
+		mcp.WithNumber("limit",
+			mcp.Description("Maximum mentions per chat (default: 20, max: 100)"),
+		),
+		mcp.WithBoolean("mark_read",
+			mcp.Description("Mark the chat's mentions as read after fetching, only when all of them fit in limit; otherwise unread_left says how many weren't fetched (default: false)"),
+		),
+	)
+}
+
+// Handle processes the GetMentions tool request
+func (h *MentionsGetHandler) Handle(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	limit := min(max(mcp.ParseInt(request, "limit", 20), 1), 100)
+	markRead := mcp.ParseBoolean(request, "mark_read", false)
+
+	var chatID int64
+	if request.GetArguments()["chat_id"] != nil || mcp.ParseString(request, "chat", "") != "" {
+		var errResult *mcp.CallToolResult
+		chatID, errResult = chatIDParam(ctx, h.client, h.peers, request, "chat_id", "chat")
+		if errResult != nil {
+			return errResult, nil
+		}
+	}
+
+	kind := unreadKind[tgdata.Mention]{
+		name:  "mentions",
+		count: func(marks tgdata.UnreadMarks) int { return marks.Mentions },
+		fetch: func(ctx context.Context, peer tg.InputPeerClass, limit int) ([]tgdata.Mention, error) {
+			return tgdata.GetUnreadMentions(ctx, h.client, peer, limit)
+		},
+		read: func(ctx context.Context, peer tg.InputPeerClass) error {
+			return tgdata.ReadMentions(ctx, h.client, peer)
+		},
+	}
+	return listUnread(ctx, h.client, h.peers, chatID, limit, markRead, kind, mentionsChat)
+}
+
+// mentionsChat describes one chat's unread mentions.
+func mentionsChat(unread unreadChat[tgdata.Mention]) MentionsChat {
+	mentions := unread.items
+	if mentions == nil {
+		mentions = []tgdata.Mention{}
+	}
+	for i := range mentions {
+		if mentions[i].Text == "" {
+			mentions[i].Text = "[media]"
+		}
+	}
+	return MentionsChat{
+		ChatID:     unread.chat.ID,
+		ChatName:   unread.chat.Name,
+		Mentions:   mentions,
+		MarkedRead: unread.markedRead,
+		UnreadLeft: unread.unreadLeft,
+		Error:      unread.err,
+	}
+}
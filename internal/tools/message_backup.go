@@ -6,6 +6,7 @@ import (
 	"os"
 	"path/filepath"
 	"runtime"
+	"slices"
 	"strings"
 	"sync"
 	"sync/atomic"
@@ -16,6 +17,7 @@ import (
 	"github.com/mark3labs/mcp-go/server"
 
 	"github.com/tolmachov/mcp-telegram/internal/messages"
+	"github.com/tolmachov/mcp-telegram/internal/summarize"
 	"github.com/tolmachov/mcp-telegram/internal/tgclient"
 )
 
@@ -101,6 +103,31 @@ func isPathAllowed(targetPath string, allowedPaths []string) error {
 		}
 	}
 
+	return pathNotAllowed(targetPath)
+}
+
+// resolveReadPath checks a file to be read, such as an upload, against the allowed
+// directories after resolving its symlinks, so a link within them can't expose a file
+// outside. It returns the resolved path, which is what the caller should open.
+func resolveReadPath(targetPath string, allowedPaths []string) (string, error) {
+	resolved, err := filepath.EvalSymlinks(targetPath)
+	if err != nil {
+		return "", fmt.Errorf("resolving path: %w", err)
+	}
+	// Allowed directories may be reached through symlinks themselves, e.g. /tmp on macOS
+	allowed := make([]string, len(allowedPaths))
+	for i, dir := range allowedPaths {
+		if allowed[i], err = filepath.EvalSymlinks(dir); err != nil {
+			allowed[i] = dir
+		}
+	}
+	if isPathAllowed(resolved, allowed) != nil {
+		return "", pathNotAllowed(targetPath)
+	}
+	return resolved, nil
+}
+
+func pathNotAllowed(targetPath string) error {
 	return fmt.Errorf("path %q is not within allowed directories. Configure --allowed-paths or TELEGRAM_ALLOWED_PATHS", targetPath)
 }
 
@@ -142,28 +169,35 @@ func getChatName(ctx context.Context, raw *tg.Client, peer tg.InputPeerClass, ch
 
 // MessageBackupHandler handles the BackupMessages tool
 type MessageBackupHandler struct {
-	client       *tg.Client
-	provider     *messages.Provider
-	allowedPaths []string
+	client          *tg.Client
+	provider        *messages.Provider
+	allowedPaths    []string
+	maxFilesPerChat int
+	voice           voiceTranscription
 }
 
-// NewMessageBackupHandler creates a new MessageBackupHandler
-func NewMessageBackupHandler(client *tg.Client, provider *messages.Provider, allowedPaths []string) *MessageBackupHandler {
+// NewMessageBackupHandler creates a new MessageBackupHandler.
+// maxFilesPerChat caps the auto-named backups kept per chat (0 = unlimited).
+// config selects how voice messages are transcribed when transcribe_voice is set.
+func NewMessageBackupHandler(client *tg.Client, dcs tgclient.DCConnector, provider *messages.Provider, allowedPaths []string, maxFilesPerChat int, config summarize.Config) *MessageBackupHandler {
 	return &MessageBackupHandler{
-		client:       client,
-		provider:     provider,
-		allowedPaths: allowedPaths,
+		client:          client,
+		provider:        provider,
+		allowedPaths:    allowedPaths,
+		maxFilesPerChat: maxFilesPerChat,
+		voice:           voiceTranscription{client: provider.Paced(), peers: provider.Peers(), dcs: dcs, config: config},
 	}
 }
 
 // Tool returns the MCP tool definition
 func (h *MessageBackupHandler) Tool() mcp.Tool {
 	return mcp.NewTool("BackupMessages",
-		mcp.WithDescription("Backup messages from a chat to a text file. Messages are saved with timestamp, sender name, ID, and reply info. If filepath is not specified, generates automatic filename like 'ChatName-2024-01-15.txt' in default backup directory. All filter parameters are optional - if none specified, backs up last 1000 messages."),
+		mcp.WithDescription("Backup messages from a chat to a file. The text format saves each message with timestamp, sender name, ID, reply info and media type; the json and ndjson formats save every message in full, including media info, entities and reply_to_id. If filepath is not specified, generates automatic filename like 'ChatName-2024-01-15_10-00-00-id123.txt' (with the format's extension) in default backup directory. All filter parameters are optional - if none specified, backs up last 1000 messages."),
 		mcp.WithNumber("chat_id",
-			mcp.Description("The ID of the chat to backup messages from"),
-			mcp.Required(),
+			mcp.Description("The ID of the chat to backup messages from (or use chat)"),
 		),
+		chatRefOption("chat", "chat_id", "The chat to backup messages from"),
+		savedMessagesOption(),
 		mcp.WithString("filepath",
 			mcp.Description("Path to the file where messages will be saved (optional, auto-generated if not provided)"),
 		),
@@ -174,27 +208,32 @@ func (h *MessageBackupHandler) Tool() mcp.Tool {
 			mcp.Description("Start date - backup messages from this date (optional, format: YYYY-MM-DD or YYYY-MM-DD HH:MM:SS)"),
 		),
 		mcp.WithString("to",
-			mcp.Description("End date - backup messages until this date (optional, format: YYYY-MM-DD or YYYY-MM-DD HH:MM:SS)"),
+			mcp.Description("End date - backup messages until this date, inclusive of the whole day unless a time is given (optional, format: YYYY-MM-DD or YYYY-MM-DD HH:MM:SS)"),
+		),
+		mcp.WithBoolean("include_reply_parents",
+			mcp.Description("Also save messages that backed-up messages reply to but that fall outside the requested range, tagged [context] (optional, default: true)"),
+		),
+		mcp.WithNumber("max_reply_parents",
+			mcp.Description(fmt.Sprintf("Maximum number of out-of-range reply parents to fetch, most recent first (optional, default: %d)", messages.DefaultMaxReplyParents)),
+		),
+		mcp.WithString("format",
+			mcp.Description("File format: 'text' (default), 'json' (an array of messages) or 'ndjson' (one JSON message per line, for streaming with tools like jq)"),
+			mcp.Enum(messages.BackupFormats...),
+		),
+		mcp.WithBoolean("transcribe_voice",
+			mcp.Description("Transcribe voice messages and add the transcript to their text, as with TranscribeVoice (optional, default: false)"),
+		),
+		topicIDOption("Back up"),
+		mcp.WithBoolean("verbose",
+			mcp.Description("In the text format, also tag each message with its forward origin, views, forwards and reactions; the json and ndjson formats always include them (optional, default: false)"),
 		),
 	)
 }
 
-// parseDate parses a date string in format YYYY-MM-DD or YYYY-MM-DD HH:MM:SS
-func parseDate(s string) (time.Time, error) {
-	if s == "" {
-		return time.Time{}, nil
-	}
-	// Try the full datetime format first
-	t, err := time.ParseInLocation("2006-01-02 15:04:05", s, time.Local)
-	if err == nil {
-		return t, nil
-	}
-	// Try a date-only format
-	t, err = time.ParseInLocation("2006-01-02", s, time.Local)
-	if err == nil {
-		return t, nil
-	}
-	return time.Time{}, fmt.Errorf("invalid date format %q, expected YYYY-MM-DD or YYYY-MM-DD HH:MM:SS", s)
+// parseDate parses a date string in format YYYY-MM-DD or YYYY-MM-DD HH:MM:SS.
+// hasTime reports whether a time of day was given.
+func parseDate(s string) (t time.Time, hasTime bool, err error) {
+	return messages.ParseDate(s, time.Local)
 }
 
 // backupProgress handles progress tracking and notifications for message backup
@@ -360,22 +399,33 @@ func (bp *backupProgress) Send(message string) {
 
 // Handle processes the BackupMessages tool request
 func (h *MessageBackupHandler) Handle(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	chatID := mcp.ParseInt64(request, "chat_id", 0)
-	if chatID == 0 {
-		return mcp.NewToolResultError("chat_id is required"), nil
+	chatID, errResult := chatIDParam(ctx, h.client, h.provider.Peers(), request, "chat_id", "chat")
+	if errResult != nil {
+		return errResult, nil
 	}
 
 	targetPath := mcp.ParseString(request, "filepath", "")
 	count := mcp.ParseInt(request, "count", 0)
 	fromStr := mcp.ParseString(request, "from", "")
 	toStr := mcp.ParseString(request, "to", "")
+	maxParents := mcp.ParseInt(request, "max_reply_parents", messages.DefaultMaxReplyParents)
+	if maxParents < 0 {
+		return mcp.NewToolResultError("max_reply_parents must not be negative"), nil
+	}
+	if !mcp.ParseBoolean(request, "include_reply_parents", true) {
+		maxParents = 0
+	}
+	format := mcp.ParseString(request, "format", messages.BackupFormatText)
+	if !slices.Contains(messages.BackupFormats, format) {
+		return mcp.NewToolResultError(fmt.Sprintf("invalid format: %s (use 'text', 'json' or 'ndjson')", format)), nil
+	}
 
 	// Parse dates
-	fromDate, err := parseDate(fromStr)
+	fromDate, _, err := parseDate(fromStr)
 	if err != nil {
 		return mcp.NewToolResultError(err.Error()), nil
 	}
-	toDate, err := parseDate(toStr)
+	toDate, toHasTime, err := parseDate(toStr)
 	if err != nil {
 		return mcp.NewToolResultError(err.Error()), nil
 	}
@@ -386,19 +436,27 @@ func (h *MessageBackupHandler) Handle(ctx context.Context, request mcp.CallToolR
 	}
 
 	// Resolve the peer for chat name lookup
-	peer, err := tgclient.ResolvePeer(ctx, h.client, chatID)
+	peer, err := h.provider.Peers().ResolvePeer(ctx, h.client, chatID)
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Failed to resolve peer: %v", err)), nil
+		return toolError("resolve peer", err), nil
+	}
+
+	topic, errResult := topicParam(ctx, h.client, h.provider.Peers(), request, chatID)
+	if errResult != nil {
+		return errResult, nil
 	}
 
 	// Generate filename if not provided
-	if targetPath == "" {
+	autoNamed := targetPath == ""
+	if autoNamed {
 		if len(h.allowedPaths) == 0 {
 			return mcp.NewToolResultError("no allowed paths configured for backup"), nil
 		}
 		chatName := getChatName(ctx, h.client, peer, chatID)
-		filename := fmt.Sprintf("%s-%s.txt", sanitizeFilename(chatName), time.Now().Format("2006-01-02_15-04-05"))
-		targetPath = filepath.Join(h.allowedPaths[0], filename)
+		if topic.ID != 0 {
+			chatName += "-" + topic.Title
+		}
+		targetPath = filepath.Join(h.allowedPaths[0], backupFilename(chatName, chatID, topic.ID, time.Now(), format))
 	}
 
 	// Validate a path against allowed directories
@@ -406,6 +464,13 @@ func (h *MessageBackupHandler) Handle(ctx context.Context, request mcp.CallToolR
 		return mcp.NewToolResultError(err.Error()), nil
 	}
 
+	var transcribe func(ctx context.Context, msg messages.Message) (string, error)
+	if mcp.ParseBoolean(request, "transcribe_voice", false) {
+		transcribe = func(ctx context.Context, msg messages.Message) (string, error) {
+			return h.voice.transcribe(ctx, chatID, msg)
+		}
+	}
+
 	// Initialize progress tracker
 	var progressToken mcp.ProgressToken
 	if request.Params.Meta != nil {
@@ -421,46 +486,213 @@ func (h *MessageBackupHandler) Handle(ctx context.Context, request mcp.CallToolR
 	progress.Start()
 	defer progress.Stop()
 
-	// Configure fetch options
-	opts := messages.FetchOptions{
-		Limit:    100,
-		MinDate:  fromDate,
-		MaxDate:  toDate,
-		MaxCount: count,
+	backup, err := writeChatBackup(ctx, h.provider, chatBackup{
+		chatID: chatID,
+		path:   targetPath,
+		format: format,
+		opts: messages.FetchOptions{
+			Limit:        100,
+			MinDate:      fromDate,
+			MaxDate:      toDate,
+			MaxDateExact: toHasTime,
+			MaxCount:     count,
+			ThreadID:     topic.ID,
+			GroupAlbums:  true,
+		},
+		maxParents: maxParents,
+		transcribe: transcribe,
+		verbose:    mcp.ParseBoolean(request, "verbose", false),
+		onBatch: func(batch int, collected int, earliestTime time.Time) {
+			progress.SetMessage(fmt.Sprintf("Fetching messages (batch %d, %d messages so far)...", batch, collected))
+			progress.SetMessageCount(collected)
+			if !earliestTime.IsZero() {
+				progress.UpdateEarliestTime(earliestTime)
+			}
+		},
+		onStep: progress.SetMessage,
+	})
+	if err != nil {
+		return toolError("back up messages", err), nil
+	}
+
+	// Get an absolute path for clear output
+	absPath, _ := filepath.Abs(targetPath)
+
+	resultMsg := fmt.Sprintf("Backup completed!\nMessages saved: %d\nFile: %s", backup.Messages, absPath)
+	if backup.Parents > 0 {
+		resultMsg += fmt.Sprintf("\nReply parents saved as [context]: %d", backup.Parents)
+	}
+	if backup.SkippedParents > 0 {
+		resultMsg += fmt.Sprintf("\nReply parents skipped (max_reply_parents reached): %d", backup.SkippedParents)
+	}
+	if backup.Transcribed > 0 {
+		resultMsg += fmt.Sprintf("\nVoice messages transcribed: %d", backup.Transcribed)
+	}
+	if backup.TranscribeErr != nil {
+		resultMsg += fmt.Sprintf("\nVoice messages left untranscribed: %d (%v)", backup.Untranscribed, tgclient.ClassifyError(backup.TranscribeErr))
 	}
 
-	// Fetch messages using the provider with a progress callback
-	result, err := h.provider.FetchAll(ctx, chatID, opts, func(batch int, collected int, earliestTime time.Time) {
-		progress.SetMessage(fmt.Sprintf("Fetching messages (batch %d, %d messages so far)...", batch, collected))
-		progress.SetMessageCount(collected)
-		if !earliestTime.IsZero() {
-			progress.UpdateEarliestTime(earliestTime)
+	// Only auto-named backups are pruned; user-specified paths are never touched
+	if autoNamed && h.maxFilesPerChat > 0 {
+		removed, err := pruneBackups(filepath.Dir(targetPath), chatID, topic.ID, h.maxFilesPerChat)
+		if len(removed) > 0 {
+			what := "chat"
+			if topic.ID != 0 {
+				what = "topic"
+			}
+			resultMsg += fmt.Sprintf("\nRemoved %d older backup(s) of this %s", len(removed), what)
+		}
+		if err != nil {
+			resultMsg += fmt.Sprintf("\nWarning: pruning old backups failed: %v", err)
 		}
-	})
-	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Failed to get messages: %v", err)), nil
 	}
 
-	progress.Send(fmt.Sprintf("Collected %d messages", len(result.Messages)))
+	return mcp.NewToolResultText(resultMsg), nil
+}
+
+// chatBackup configures writing the messages of one chat to a backup file.
+type chatBackup struct {
+	chatID     int64
+	path       string
+	format     string // messages.BackupFormatText when empty
+	opts       messages.FetchOptions
+	maxParents int // Out-of-range reply parents to include, 0 for none
+	onBatch    messages.BatchCallback
+	onStep     func(message string) // Optional, reports the current step
+	// transcribe, when set, transcribes voice messages to add to their text
+	transcribe func(ctx context.Context, msg messages.Message) (string, error)
+	verbose    bool // Tag text backups with forward origins, views and reactions
+}
 
-	// Format messages for backup using the messages package
-	content := messages.FormatBatchForBackup(result.Messages)
+// chatBackupResult reports what a backup saved.
+type chatBackupResult struct {
+	Messages       int
+	Parents        int // Reply parents saved as context
+	SkippedParents int // Reply parents left out by maxParents
+	Transcribed    int // Voice messages saved with their transcript
+	Untranscribed  int // Voice messages left without one after TranscribeErr
+	TranscribeErr  error
+}
 
-	// Ensure parent directory exists
-	parentDir := filepath.Dir(targetPath)
-	if err := os.MkdirAll(parentDir, 0o750); err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Failed to create directory: %v", err)), nil
+// transcribeBatch adds transcripts to the voice messages of a batch. After the first
+// failure, e.g. when Telegram's free transcriptions run out, the remaining voice
+// messages are only counted.
+func transcribeBatch(ctx context.Context, batch []messages.Message, transcribe func(context.Context, messages.Message) (string, error), backup *chatBackupResult) {
+	for i, msg := range batch {
+		if msg.Media == nil || msg.Media.Type != messages.MediaVoice {
+			continue
+		}
+		if backup.TranscribeErr != nil {
+			backup.Untranscribed++
+			continue
+		}
+		text, err := transcribe(ctx, msg)
+		if err != nil {
+			backup.TranscribeErr = err
+			backup.Untranscribed++
+			continue
+		}
+		batch[i].Text = withTranscript(msg.Text, text)
+		backup.Transcribed++
 	}
+}
 
-	// Write to a file
-	if err := os.WriteFile(targetPath, []byte(content), 0o600); err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Failed to write file: %v", err)), nil
+// withTranscript appends a voice message's transcript to its text.
+func withTranscript(text, transcript string) string {
+	transcript = "[voice transcript] " + strings.TrimSpace(transcript)
+	if text == "" {
+		return transcript
 	}
+	return text + "\n" + transcript
+}
 
-	// Get an absolute path for clear output
-	absPath, _ := filepath.Abs(targetPath)
+// writeChatBackup streams the messages of a chat to the backup file batch by batch,
+// followed by their out-of-range reply parents so replies don't dangle. Memory use
+// stays flat however large the chat is: only message IDs are kept, to find the
+// parents. The file is written with partialBackupSuffix and renamed to the backup
+// path once complete, so a file already there is only replaced by a complete backup.
+// A failed backup leaves the batches written so far in the partial file.
+func writeChatBackup(ctx context.Context, provider *messages.Provider, b chatBackup) (chatBackupResult, error) {
+	step := func(message string) {
+		if b.onStep != nil {
+			b.onStep(message)
+		}
+	}
 
-	resultMsg := fmt.Sprintf("Backup completed!\nMessages saved: %d\nFile: %s", len(result.Messages), absPath)
+	format := b.format
+	if format == "" {
+		format = messages.BackupFormatText
+	}
+	if err := os.MkdirAll(filepath.Dir(b.path), 0o750); err != nil {
+		return chatBackupResult{}, fmt.Errorf("creating directory: %w", err)
+	}
+	partialPath := b.path + partialBackupSuffix
+	f, err := os.OpenFile(partialPath, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o600)
+	if err != nil {
+		return chatBackupResult{}, fmt.Errorf("creating file: %w", err)
+	}
+	w, err := messages.NewBackupWriter(f, format)
+	if err != nil {
+		_ = f.Close()
+		_ = os.Remove(partialPath)
+		return chatBackupResult{}, err
+	}
+	w.Verbose = b.verbose
+
+	var backup chatBackupResult
+	// partial closes the file, keeping what was written readable, and reports how far
+	// the backup got. A partial file without messages is removed.
+	partial := func(err error) (chatBackupResult, error) {
+		_ = w.Close()
+		_ = f.Close()
+		if backup.Messages == 0 {
+			_ = os.Remove(partialPath)
+			return backup, err
+		}
+		absPath, _ := filepath.Abs(partialPath)
+		return backup, fmt.Errorf("%w (partial backup of %d messages left in %s)", err, backup.Messages, absPath)
+	}
 
-	return mcp.NewToolResultText(resultMsg), nil
+	var refs messages.ReplyRefs
+	backup.Messages, err = provider.FetchStream(ctx, b.chatID, b.opts, func(batch []messages.Message) error {
+		if b.maxParents > 0 {
+			refs.Add(batch)
+		}
+		if b.transcribe != nil {
+			transcribeBatch(ctx, batch, b.transcribe, &backup)
+		}
+		if err := w.Write(batch); err != nil {
+			return fmt.Errorf("writing file: %w", err)
+		}
+		return nil
+	}, b.onBatch)
+	if err != nil {
+		return partial(fmt.Errorf("getting messages: %w", err))
+	}
+	step(fmt.Sprintf("Collected %d messages", backup.Messages))
+
+	if b.maxParents > 0 {
+		step("Fetching reply parents...")
+		ids, skipped := refs.Missing(b.maxParents)
+		parents, err := provider.FetchContext(ctx, b.chatID, ids)
+		if err != nil {
+			return partial(fmt.Errorf("getting reply parents: %w", err))
+		}
+		if err := w.Write(parents); err != nil {
+			return partial(fmt.Errorf("writing file: %w", err))
+		}
+		backup.Parents = len(parents)
+		backup.SkippedParents = skipped
+	}
+
+	if err := w.Close(); err != nil {
+		return partial(fmt.Errorf("writing file: %w", err))
+	}
+	if err := f.Close(); err != nil {
+		return partial(fmt.Errorf("writing file: %w", err))
+	}
+	if err := os.Rename(partialPath, b.path); err != nil {
+		return partial(fmt.Errorf("writing file: %w", err))
+	}
+	return backup, nil
 }
@@ -0,0 +1,112 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gotd/td/tg"
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/tolmachov/mcp-telegram/internal/messages"
+)
+
+// defaultContextCount is the default number of messages on each side of the anchor.
+const defaultContextCount = 10
+
+// MessageContextHandler handles the GetMessageContext tool
+type MessageContextHandler struct {
+	client   *tg.Client
+	provider *messages.Provider
+}
+
+// NewMessageContextHandler creates a new MessageContextHandler
+func NewMessageContextHandler(client *tg.Client, provider *messages.Provider) *MessageContextHandler {
+	return &MessageContextHandler{
+		client:   client,
+		provider: provider,
+	}
+}
+
+// contextMessage is a message of the window, flagged when it is the anchor.
+type contextMessage struct {
+	messages.Message
+	Anchor bool `json:"anchor,omitempty"`
+}
+
+// messageContextResult is the JSON result of GetMessageContext.
+type messageContextResult struct {
+	ChatID      int64            `json:"chat_id"`
+	MessageID   int              `json:"message_id"`
+	AnchorFound bool             `json:"anchor_found"`
+	Messages    []contextMessage `json:"messages"` // chronological order
+	Notes       []string         `json:"notes,omitempty"`
+}
+
+// Tool returns the MCP tool definition
+func (h *MessageContextHandler) Tool() mcp.Tool {
+	return mcp.NewTool("GetMessageContext",
+		mcp.WithDescription("Get the conversation around a message, e.g. one cited by a search or summary: the messages before and after it in chronological order, with the message itself marked anchor."),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithNumber("chat_id",
+			mcp.Description("The ID of the chat with the message (or use chat)"),
+		),
+		chatRefOption("chat", "chat_id", "The chat with the message"),
+		mcp.WithNumber("message_id",
+			mcp.Description("The ID of the message to get the context of"),
+			mcp.Required(),
+		),
+		mcp.WithNumber("before",
+			mcp.Description(fmt.Sprintf("Number of messages before it (default %d, max %d)", defaultContextCount, messages.MaxAroundCount)),
+		),
+		mcp.WithNumber("after",
+			mcp.Description(fmt.Sprintf("Number of messages after it (default %d, max %d)", defaultContextCount, messages.MaxAroundCount)),
+		),
+	)
+}
+
+// Handle processes the GetMessageContext tool request
+func (h *MessageContextHandler) Handle(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	chatID, errResult := chatIDParam(ctx, h.client, h.provider.Peers(), request, "chat_id", "chat")
+	if errResult != nil {
+		return errResult, nil
+	}
+	msgID := mcp.ParseInt(request, "message_id", 0)
+	if msgID <= 0 {
+		return mcp.NewToolResultError("message_id is required"), nil
+	}
+	before := mcp.ParseInt(request, "before", defaultContextCount)
+	after := mcp.ParseInt(request, "after", defaultContextCount)
+	if before < 0 || after < 0 {
+		return mcp.NewToolResultError("before and after must not be negative"), nil
+	}
+
+	around, err := h.provider.FetchAround(ctx, chatID, msgID, before, after)
+	if err != nil {
+		return toolError("get message context", err), nil
+	}
+	return jsonResult(newMessageContextResult(chatID, msgID, around))
+}
+
+// newMessageContextResult marks the anchor of a window and notes why it may be
+// incomplete.
+func newMessageContextResult(chatID int64, msgID int, around *messages.Around) messageContextResult {
+	result := messageContextResult{
+		ChatID:      chatID,
+		MessageID:   msgID,
+		AnchorFound: around.AnchorFound,
+		Messages:    make([]contextMessage, len(around.Messages)),
+	}
+	for i, msg := range around.Messages {
+		result.Messages[i] = contextMessage{Message: msg, Anchor: msg.ID == msgID}
+	}
+	if !around.AnchorFound {
+		result.Notes = append(result.Notes, fmt.Sprintf("message %d was not found (deleted or never existed); showing the messages around where it would be", msgID))
+	}
+	if around.AtStart {
+		result.Notes = append(result.Notes, "reached the start of the chat")
+	}
+	if around.AtEnd {
+		result.Notes = append(result.Notes, "reached the newest message of the chat")
+	}
+	return result
+}
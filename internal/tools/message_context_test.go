@@ -0,0 +1,35 @@
+package tools
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/tolmachov/mcp-telegram/internal/messages"
+)
+
+func TestNewMessageContextResult(t *testing.T) {
+	around := &messages.Around{
+		Messages:    []messages.Message{{ID: 4}, {ID: 5}, {ID: 6}},
+		AnchorFound: true,
+		AtStart:     true,
+	}
+	result := newMessageContextResult(1, 5, around)
+	for _, msg := range result.Messages {
+		if msg.Anchor != (msg.ID == 5) {
+			t.Errorf("message %d anchor = %v", msg.ID, msg.Anchor)
+		}
+	}
+	if len(result.Notes) != 1 || !strings.Contains(result.Notes[0], "start of the chat") {
+		t.Errorf("notes = %q, want the start of the chat", result.Notes)
+	}
+
+	deleted := newMessageContextResult(1, 7, &messages.Around{Messages: []messages.Message{{ID: 6}, {ID: 8}}})
+	if deleted.AnchorFound || len(deleted.Notes) != 1 || !strings.Contains(deleted.Notes[0], "message 7 was not found") {
+		t.Errorf("deleted anchor: found %v, notes %q", deleted.AnchorFound, deleted.Notes)
+	}
+	for _, msg := range deleted.Messages {
+		if msg.Anchor {
+			t.Errorf("message %d marked anchor, want none", msg.ID)
+		}
+	}
+}
@@ -3,6 +3,7 @@ package tools
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	"github.com/gotd/td/tg"
 	"github.com/mark3labs/mcp-go/mcp"
@@ -10,28 +11,38 @@ import (
 	"github.com/tolmachov/mcp-telegram/internal/tgclient"
 )
 
+// maxDeleteMessages is the most messages Telegram deletes in one request.
+const maxDeleteMessages = 100
+
 // MessageDeleteHandler handles the DeleteMessage tool
 type MessageDeleteHandler struct {
 	client *tg.Client
+	peers  *tgclient.PeerCache
 }
 
 // NewMessageDeleteHandler creates a new MessageDeleteHandler
-func NewMessageDeleteHandler(client *tg.Client) *MessageDeleteHandler {
-	return &MessageDeleteHandler{client: client}
+func NewMessageDeleteHandler(client *tg.Client, peers *tgclient.PeerCache) *MessageDeleteHandler {
+	return &MessageDeleteHandler{client: client, peers: peers}
 }
 
 // Tool returns the MCP tool definition
 func (h *MessageDeleteHandler) Tool() mcp.Tool {
 	return mcp.NewTool("DeleteMessage",
-		mcp.WithDescription("Delete a message from a chat. This action cannot be undone. For non-channel chats, the message will be deleted for all participants."),
+		mcp.WithDescription("Delete one message or up to 100 messages from a chat in a single request. This action cannot be undone. In private chats and groups, messages are deleted for all participants unless revoke is false; in channels and supergroups they are always deleted for everyone."),
 		mcp.WithDestructiveHintAnnotation(true),
 		mcp.WithNumber("chat_id",
-			mcp.Description("The ID of the chat containing the message"),
+			mcp.Description("The ID of the chat containing the messages"),
 			mcp.Required(),
 		),
 		mcp.WithNumber("message_id",
-			mcp.Description("The ID of the message to delete"),
-			mcp.Required(),
+			mcp.Description("The ID of the message to delete (or use message_ids)"),
+		),
+		mcp.WithArray("message_ids",
+			mcp.WithNumberItems(),
+			mcp.Description("IDs of the messages to delete (max 100)"),
+		),
+		mcp.WithBoolean("revoke",
+			mcp.Description("Delete the messages for all participants rather than only for you; ignored in channels and supergroups (default: true)"),
 		),
 	)
 }
@@ -43,51 +54,93 @@ func (h *MessageDeleteHandler) Handle(ctx context.Context, request mcp.CallToolR
 		return mcp.NewToolResultError("chat_id is required"), nil
 	}
 
-	messageID := mcp.ParseInt(request, "message_id", 0)
-	if messageID == 0 {
-		return mcp.NewToolResultError("message_id is required"), nil
+	ids := request.GetIntSlice("message_ids", nil)
+	if messageID := mcp.ParseInt(request, "message_id", 0); messageID != 0 {
+		ids = append([]int{messageID}, ids...)
 	}
-
-	// Always revoke (delete for all participants)
-	revoke := true
-
-	// Resolve the peer
-	peer, err := tgclient.ResolvePeer(ctx, h.client, chatID)
-	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Failed to resolve peer: %v", err)), nil
+	ids = uniqueInts(ids)
+	if len(ids) == 0 {
+		return mcp.NewToolResultError("message_id or message_ids is required"), nil
+	}
+	if len(ids) > maxDeleteMessages {
+		return mcp.NewToolResultError(fmt.Sprintf("Cannot delete more than %d messages at once, got %d", maxDeleteMessages, len(ids))), nil
 	}
 
-	// Check if it's a channel
-	switch p := peer.(type) {
-	case *tg.InputPeerChannel:
-		// For channels, use channels.deleteMessages
-		affected, err := h.client.ChannelsDeleteMessages(ctx, &tg.ChannelsDeleteMessagesRequest{
-			Channel: &tg.InputChannel{
-				ChannelID:  p.ChannelID,
-				AccessHash: p.AccessHash,
-			},
-			ID: []int{messageID},
-		})
-		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("Failed to delete message: %v", err)), nil
-		}
+	revoke := mcp.ParseBoolean(request, "revoke", true)
 
-		result := fmt.Sprintf("Message deleted successfully!\nChat ID: %d\nMessage ID: %d\nMessages affected: %d",
-			chatID, messageID, affected.Pts)
-		return mcp.NewToolResultText(result), nil
+	var affected int
+	var channel bool
+	err := h.peers.WithPeer(ctx, h.client, chatID, func(peer tg.InputPeerClass) error {
+		p, ok := peer.(*tg.InputPeerChannel)
+		channel = ok
+		if channel {
+			// For channels, use channels.deleteMessages
+			res, err := h.client.ChannelsDeleteMessages(ctx, &tg.ChannelsDeleteMessagesRequest{
+				Channel: &tg.InputChannel{
+					ChannelID:  p.ChannelID,
+					AccessHash: p.AccessHash,
+				},
+				ID: ids,
+			})
+			if err != nil {
+				return err
+			}
+			affected = res.PtsCount
+			return nil
+		}
 
-	default:
 		// For private chats and groups, use messages.deleteMessages
-		affected, err := h.client.MessagesDeleteMessages(ctx, &tg.MessagesDeleteMessagesRequest{
+		res, err := h.client.MessagesDeleteMessages(ctx, &tg.MessagesDeleteMessagesRequest{
 			Revoke: revoke,
-			ID:     []int{messageID},
+			ID:     ids,
 		})
 		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("Failed to delete message: %v", err)), nil
+			return err
 		}
+		affected = res.PtsCount
+		return nil
+	})
+	if err != nil {
+		return toolError("delete messages", err), nil
+	}
+	return mcp.NewToolResultText(deleteResultText(chatID, ids, affected, channel, revoke)), nil
+}
+
+// deleteResultText reports a deletion. Telegram counts the messages it deleted in
+// the PtsCount of its answer, but doesn't say which ones it skipped, so a shortfall
+// is reported with the reasons likely for the kind of chat.
+func deleteResultText(chatID int64, ids []int, affected int, channel, revoke bool) string {
+	var sb strings.Builder
+	if affected >= len(ids) {
+		sb.WriteString("Messages deleted successfully!")
+	} else {
+		sb.WriteString("Some messages were not deleted.")
+	}
+	fmt.Fprintf(&sb, "\nChat ID: %d\nMessage IDs: %s\nMessages affected: %d of %d", chatID, joinInts(ids), affected, len(ids))
+	if channel {
+		sb.WriteString("\nDeleted for: everyone (channel or supergroup)")
+	} else if revoke {
+		sb.WriteString("\nDeleted for: all participants")
+	} else {
+		sb.WriteString("\nDeleted for: you only")
+	}
+
+	if affected < len(ids) {
+		missing := len(ids) - affected
+		if channel {
+			fmt.Fprintf(&sb, "\n\n%d not deleted: in a channel or supergroup these don't exist, or belong to others and you aren't an admin allowed to delete messages.", missing)
+		} else {
+			fmt.Fprintf(&sb, "\n\n%d not deleted: in a private chat or group these don't exist, were already deleted, or aren't in this chat.", missing)
+		}
+	}
+	return sb.String()
+}
 
-		result := fmt.Sprintf("Message deleted successfully!\nChat ID: %d\nMessage ID: %d\nMessages affected: %d\nRevoked for all: %t",
-			chatID, messageID, affected.Pts, revoke)
-		return mcp.NewToolResultText(result), nil
+// joinInts joins values with commas.
+func joinInts(values []int) string {
+	parts := make([]string, len(values))
+	for i, v := range values {
+		parts[i] = fmt.Sprint(v)
 	}
+	return strings.Join(parts, ", ")
 }
@@ -0,0 +1,59 @@
+package tools
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDeleteResultText(t *testing.T) {
+	tests := []struct {
+		name     string
+		affected int
+		channel  bool
+		revoke   bool
+		want     []string
+		notWant  string
+	}{
+		{
+			name:     "all deleted for everyone",
+			affected: 3,
+			revoke:   true,
+			want:     []string{"Messages deleted successfully!", "Messages affected: 3 of 3", "Deleted for: all participants"},
+			notWant:  "not deleted",
+		},
+		{
+			name:     "only for me",
+			affected: 3,
+			want:     []string{"Deleted for: you only"},
+		},
+		{
+			name:     "partial in a channel",
+			affected: 1,
+			channel:  true,
+			revoke:   true,
+			want:     []string{"Some messages were not deleted.", "Messages affected: 1 of 3", "2 not deleted: in a channel or supergroup", "aren't an admin"},
+		},
+		{
+			name:     "partial in a group",
+			affected: 2,
+			revoke:   true,
+			want:     []string{"1 not deleted: in a private chat or group"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := deleteResultText(42, []int{7, 8, 9}, tt.affected, tt.channel, tt.revoke)
+			for _, want := range tt.want {
+				if !strings.Contains(got, want) {
+					t.Errorf("deleteResultText() = %q, missing %q", got, want)
+				}
+			}
+			if tt.notWant != "" && strings.Contains(got, tt.notWant) {
+				t.Errorf("deleteResultText() = %q, shouldn't contain %q", got, tt.notWant)
+			}
+			if !strings.Contains(got, "Message IDs: 7, 8, 9") {
+				t.Errorf("deleteResultText() = %q, missing the message IDs", got)
+			}
+		})
+	}
+}
@@ -13,11 +13,12 @@ import (
 // MessageDraftHandler handles the DraftMessage tool
 type MessageDraftHandler struct {
 	client *tg.Client
+	peers  *tgclient.PeerCache
 }
 
 // NewMessageDraftHandler creates a new MessageDraftHandler
-func NewMessageDraftHandler(client *tg.Client) *MessageDraftHandler {
-	return &MessageDraftHandler{client: client}
+func NewMessageDraftHandler(client *tg.Client, peers *tgclient.PeerCache) *MessageDraftHandler {
+	return &MessageDraftHandler{client: client, peers: peers}
 }
 
 // Tool returns the MCP tool definition
@@ -25,9 +26,10 @@ func (h *MessageDraftHandler) Tool() mcp.Tool {
 	return mcp.NewTool("DraftMessage",
 		mcp.WithDescription("Draft a message in a given chat, group or channel. The message will be saved as a draft and can be sent later."),
 		mcp.WithNumber("chat_id",
-			mcp.Description("The ID of the chat to save the draft to"),
-			mcp.Required(),
+			mcp.Description("The ID of the chat to save the draft to (or use chat)"),
 		),
+		chatRefOption("chat", "chat_id", "The chat to save the draft to"),
+		savedMessagesOption(),
 		mcp.WithString("message",
 			mcp.Description("The message text to save as draft"),
 			mcp.Required(),
@@ -37,9 +39,9 @@ func (h *MessageDraftHandler) Tool() mcp.Tool {
 
 // Handle processes the DraftMessage tool request
 func (h *MessageDraftHandler) Handle(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	chatID := mcp.ParseInt64(request, "chat_id", 0)
-	if chatID == 0 {
-		return mcp.NewToolResultError("chat_id is required"), nil
+	chatID, errResult := chatIDParam(ctx, h.client, h.peers, request, "chat_id", "chat")
+	if errResult != nil {
+		return errResult, nil
 	}
 
 	message := mcp.ParseString(request, "message", "")
@@ -47,19 +49,16 @@ func (h *MessageDraftHandler) Handle(ctx context.Context, request mcp.CallToolRe
 		return mcp.NewToolResultError("message is required"), nil
 	}
 
-	// Resolve the peer
-	peer, err := tgclient.ResolvePeer(ctx, h.client, chatID)
-	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Failed to resolve peer: %v", err)), nil
-	}
-
 	// Save the draft
-	_, err = h.client.MessagesSaveDraft(ctx, &tg.MessagesSaveDraftRequest{
-		Peer:    peer,
-		Message: message,
+	err := h.peers.WithPeer(ctx, h.client, chatID, func(peer tg.InputPeerClass) error {
+		_, err := h.client.MessagesSaveDraft(ctx, &tg.MessagesSaveDraftRequest{
+			Peer:    peer,
+			Message: message,
+		})
+		return err
 	})
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Failed to save draft: %v", err)), nil
+		return toolError("save draft", err), nil
 	}
 
 	result := fmt.Sprintf("Draft message saved successfully for chat %d", chatID)
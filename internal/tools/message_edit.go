@@ -7,17 +7,19 @@ import (
 	"github.com/gotd/td/tg"
 	"github.com/mark3labs/mcp-go/mcp"
 
+	"github.com/tolmachov/mcp-telegram/internal/messages"
 	"github.com/tolmachov/mcp-telegram/internal/tgclient"
 )
 
 // MessageEditHandler handles the EditMessage tool
 type MessageEditHandler struct {
 	client *tg.Client
+	peers  *tgclient.PeerCache
 }
 
 // NewMessageEditHandler creates a new MessageEditHandler
-func NewMessageEditHandler(client *tg.Client) *MessageEditHandler {
-	return &MessageEditHandler{client: client}
+func NewMessageEditHandler(client *tg.Client, peers *tgclient.PeerCache) *MessageEditHandler {
+	return &MessageEditHandler{client: client, peers: peers}
 }
 
 // Tool returns the MCP tool definition
@@ -57,51 +59,40 @@ func (h *MessageEditHandler) Handle(ctx context.Context, request mcp.CallToolReq
 		return mcp.NewToolResultError("new_text is required"), nil
 	}
 
-	// Resolve the peer
-	peer, err := tgclient.ResolvePeer(ctx, h.client, chatID)
-	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Failed to resolve peer: %v", err)), nil
-	}
+	// Remember the previous text so the result can show what changed, then edit
+	var oldText string
+	var updates tg.UpdatesClass
+	err := h.peers.WithPeer(ctx, h.client, chatID, func(peer tg.InputPeerClass) error {
+		if oldMsg, err := fetchMessage(ctx, h.client, peer, messageID); err == nil {
+			oldText = oldMsg.Message
+		}
 
-	// Edit the message
-	updates, err := h.client.MessagesEditMessage(ctx, &tg.MessagesEditMessageRequest{
-		Peer:    peer,
-		ID:      messageID,
-		Message: newText,
+		var err error
+		updates, err = h.client.MessagesEditMessage(ctx, &tg.MessagesEditMessageRequest{
+			Peer:    peer,
+			ID:      messageID,
+			Message: newText,
+		})
+		return err
 	})
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Failed to edit message: %v", err)), nil
+		return toolError("edit message", err), nil
 	}
 
-	// Extract updated message info
-	var editedMsgID int
-	var date int
+	sent := extractSentMessage(updates)
 
-	switch u := updates.(type) {
-	case *tg.Updates:
-		for _, update := range u.Updates {
-			if editMsg, ok := update.(*tg.UpdateEditMessage); ok {
-				if msg, ok := editMsg.Message.(*tg.Message); ok {
-					editedMsgID = msg.ID
-					date = msg.Date
-					break
-				}
-			}
-			if editMsg, ok := update.(*tg.UpdateEditChannelMessage); ok {
-				if msg, ok := editMsg.Message.(*tg.Message); ok {
-					editedMsgID = msg.ID
-					date = msg.Date
-					break
-				}
-			}
-		}
+	result := fmt.Sprintf("Message edited successfully!\nChat ID: %d\nMessage ID: %d",
+		chatID, sent.ID)
+	if sent.Link != "" {
+		result += fmt.Sprintf("\nLink: %s", sent.Link)
 	}
+	if oldText != "" {
+		result += fmt.Sprintf("\nOld text: %s", messages.TruncateRunes(oldText, sentTextSnippetRunes))
+	}
+	result += fmt.Sprintf("\nNew text: %s", messages.TruncateRunes(newText, sentTextSnippetRunes))
 
-	result := fmt.Sprintf("Message edited successfully!\nChat ID: %d\nMessage ID: %d\nUpdated text: %s",
-		chatID, editedMsgID, newText)
-
-	if date > 0 {
-		result += fmt.Sprintf("\nEdit time: %d", date)
+	if sent.Date > 0 {
+		result += fmt.Sprintf("\nEdit time: %d", sent.Date)
 	}
 
 	return mcp.NewToolResultText(result), nil
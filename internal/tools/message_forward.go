@@ -2,115 +2,235 @@ package tools
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/gotd/td/tg"
 	"github.com/mark3labs/mcp-go/mcp"
 
-	"github.com/tolmachov/mcp-telegram/internal/tgclient"
+	"github.com/tolmachov/mcp-telegram/internal/messages"
+	"github.com/tolmachov/mcp-telegram/internal/recent"
 )
 
+// maxForwardMessages is the most messages Telegram forwards in one request.
+const maxForwardMessages = 100
+
 // MessageForwardHandler handles the ForwardMessage tool
 type MessageForwardHandler struct {
-	client *tg.Client
+	client   *tg.Client
+	provider *messages.Provider
+	chatName recent.NameLookup
 }
 
-// NewMessageForwardHandler creates a new MessageForwardHandler
-func NewMessageForwardHandler(client *tg.Client) *MessageForwardHandler {
-	return &MessageForwardHandler{client: client}
+// NewMessageForwardHandler creates a new MessageForwardHandler.
+// The provider fetches album siblings; chatName resolves chat names for the
+// expected_chat_name check.
+func NewMessageForwardHandler(client *tg.Client, provider *messages.Provider, chatName recent.NameLookup) *MessageForwardHandler {
+	return &MessageForwardHandler{client: client, provider: provider, chatName: chatName}
 }
 
 // Tool returns the MCP tool definition
 func (h *MessageForwardHandler) Tool() mcp.Tool {
 	return mcp.NewTool("ForwardMessage",
-		mcp.WithDescription("Forward a message from one chat to another."),
+		mcp.WithDescription("Forward one message or up to 100 messages from one chat to another in a single request, keeping their order and grouping. A message that is part of an album is forwarded together with the rest of the album unless expand_album is false."),
 		mcp.WithOpenWorldHintAnnotation(true),
 		mcp.WithNumber("from_chat_id",
-			mcp.Description("The ID of the chat to forward from"),
-			mcp.Required(),
+			mcp.Description("The ID of the chat to forward from (or use from_chat)"),
 		),
+		chatRefOption("from_chat", "from_chat_id", "The chat to forward from"),
 		mcp.WithNumber("message_id",
-			mcp.Description("The ID of the message to forward"),
-			mcp.Required(),
+			mcp.Description("The ID of the message to forward (or use message_ids)"),
+		),
+		mcp.WithArray("message_ids",
+			mcp.WithNumberItems(),
+			mcp.Description("IDs of the messages to forward together, in order (max 100)"),
 		),
 		mcp.WithNumber("to_chat_id",
-			mcp.Description("The ID of the chat to forward to"),
-			mcp.Required(),
+			mcp.Description("The ID of the chat to forward to (or use to_chat)"),
+		),
+		chatRefOption("to_chat", "to_chat_id", "The chat to forward to"),
+		mcp.WithBoolean("expand_album",
+			mcp.Description("If a message is part of an album (several photos or files sent together), forward the whole album so it stays grouped (default: true)"),
 		),
+		mcp.WithBoolean("drop_author",
+			mcp.Description("Forward without the \"Forwarded from\" header, as if the messages were sent by you (default: false)"),
+		),
+		expectedChatNameOption("the destination chat"),
 	)
 }
 
 // Handle processes the ForwardMessage tool request
 func (h *MessageForwardHandler) Handle(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	fromChatID := mcp.ParseInt64(request, "from_chat_id", 0)
-	if fromChatID == 0 {
-		return mcp.NewToolResultError("from_chat_id is required"), nil
+	fromChatID, errResult := chatIDParam(ctx, h.client, h.provider.Peers(), request, "from_chat_id", "from_chat")
+	if errResult != nil {
+		return errResult, nil
 	}
 
-	messageID := mcp.ParseInt(request, "message_id", 0)
-	if messageID == 0 {
-		return mcp.NewToolResultError("message_id is required"), nil
+	ids, errResult := forwardMessageIDs(request)
+	if errResult != nil {
+		return errResult, nil
 	}
 
-	toChatID := mcp.ParseInt64(request, "to_chat_id", 0)
-	if toChatID == 0 {
-		return mcp.NewToolResultError("to_chat_id is required"), nil
+	toChatID, errResult := chatIDParam(ctx, h.client, h.provider.Peers(), request, "to_chat_id", "to_chat")
+	if errResult != nil {
+		return errResult, nil
 	}
 
-	// Resolve both peers
-	fromPeer, err := tgclient.ResolvePeer(ctx, h.client, fromChatID)
-	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Failed to resolve source chat: %v", err)), nil
+	if errResult := checkExpectedChatName(ctx, request, h.chatName, toChatID); errResult != nil {
+		return errResult, nil
 	}
 
-	toPeer, err := tgclient.ResolvePeer(ctx, h.client, toChatID)
-	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Failed to resolve destination chat: %v", err)), nil
+	if mcp.ParseBoolean(request, "expand_album", true) {
+		expanded, errResult := h.expandAlbums(ctx, fromChatID, ids)
+		if errResult != nil {
+			return errResult, nil
+		}
+		ids = expanded
+	}
+	if len(ids) > maxForwardMessages {
+		return mcp.NewToolResultError(fmt.Sprintf("Cannot forward more than %d messages at once, got %d with their albums; set expand_album to false or forward fewer", maxForwardMessages, len(ids))), nil
 	}
 
-	// Forward the message
-	updates, err := h.client.MessagesForwardMessages(ctx, &tg.MessagesForwardMessagesRequest{
-		FromPeer: fromPeer,
-		ID:       []int{messageID},
-		ToPeer:   toPeer,
-		RandomID: []int64{time.Now().UnixNano()},
+	// Forward the messages in one call so Telegram keeps an album grouped
+	randomIDs := make([]int64, len(ids))
+	base := time.Now().UnixNano()
+	for i := range randomIDs {
+		randomIDs[i] = base + int64(i)
+	}
+	var updates tg.UpdatesClass
+	err := h.provider.Peers().WithPeers(ctx, h.client, fromChatID, toChatID, func(fromPeer, toPeer tg.InputPeerClass) error {
+		var err error
+		updates, err = h.client.MessagesForwardMessages(ctx, &tg.MessagesForwardMessagesRequest{
+			FromPeer:   fromPeer,
+			ID:         ids,
+			ToPeer:     toPeer,
+			RandomID:   randomIDs,
+			DropAuthor: mcp.ParseBoolean(request, "drop_author", false),
+		})
+		return err
 	})
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Failed to forward message: %v", err)), nil
+		return toolError("forward message", err), nil
 	}
 
-	// Extract forwarded message info
-	var forwardedMsgID int
-	var date int
+	forwarded, date := forwardedMessages(updates, ids, randomIDs)
 
-	switch u := updates.(type) {
-	case *tg.Updates:
-		for _, update := range u.Updates {
-			if newMsg, ok := update.(*tg.UpdateNewMessage); ok {
-				if msg, ok := newMsg.Message.(*tg.Message); ok {
-					forwardedMsgID = msg.ID
-					date = msg.Date
-					break
-				}
-			}
-			if newMsg, ok := update.(*tg.UpdateNewChannelMessage); ok {
-				if msg, ok := newMsg.Message.(*tg.Message); ok {
-					forwardedMsgID = msg.ID
-					date = msg.Date
-					break
-				}
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Messages forwarded successfully!\nFrom chat ID: %d\nTo chat ID: %d\nMessages forwarded: %d", fromChatID, toChatID, len(ids))
+	if date > 0 {
+		fmt.Fprintf(&sb, "\nDate: %s", time.Unix(int64(date), 0).Format(time.RFC3339))
+	}
+	sb.WriteString("\nMessage IDs (original -> new):")
+	for _, f := range forwarded {
+		if f.NewID == 0 {
+			fmt.Fprintf(&sb, "\n  %d -> unknown", f.OldID)
+		} else {
+			fmt.Fprintf(&sb, "\n  %d -> %d", f.OldID, f.NewID)
+		}
+	}
+
+	return mcp.NewToolResultText(sb.String()), nil
+}
+
+// forwardMessageIDs reads message_ids, or message_id for a single message, without
+// duplicates.
+func forwardMessageIDs(request mcp.CallToolRequest) ([]int, *mcp.CallToolResult) {
+	ids := request.GetIntSlice("message_ids", nil)
+	if messageID := mcp.ParseInt(request, "message_id", 0); messageID != 0 {
+		ids = append([]int{messageID}, ids...)
+	}
+	if len(ids) == 0 {
+		return nil, mcp.NewToolResultError("message_id or message_ids is required")
+	}
+	ids = uniqueInts(ids)
+	if len(ids) > maxForwardMessages {
+		return nil, mcp.NewToolResultError(fmt.Sprintf("Cannot forward more than %d messages at once, got %d", maxForwardMessages, len(ids)))
+	}
+	return ids, nil
+}
+
+// expandAlbums replaces each message of an album with the whole album, in order and
+// without repeating messages.
+func (h *MessageForwardHandler) expandAlbums(ctx context.Context, chatID int64, ids []int) ([]int, *mcp.CallToolResult) {
+	albums, err := h.provider.FetchAlbums(ctx, chatID, ids)
+	if errors.Is(err, messages.ErrMessageNotFound) {
+		return nil, mcp.NewToolResultError(fmt.Sprintf("Failed to forward from chat %d: %v", chatID, err))
+	}
+	if err != nil {
+		return nil, toolError("fetch messages", err)
+	}
+	expanded := make([]int, len(albums))
+	for i, msg := range albums {
+		expanded[i] = msg.ID
+	}
+	return expanded, nil
+}
+
+// forwardedMessage maps a forwarded message to its copy; NewID is 0 when the
+// response didn't say.
+type forwardedMessage struct {
+	OldID int
+	NewID int
+}
+
+// forwardedMessages matches the copies in a forward response to the original ids
+// through the random IDs they were sent with, and returns the date they were sent.
+func forwardedMessages(updates tg.UpdatesClass, ids []int, randomIDs []int64) ([]forwardedMessage, int) {
+	forwarded := make([]forwardedMessage, len(ids))
+	for i, id := range ids {
+		forwarded[i].OldID = id
+	}
+	u, ok := updates.(*tg.Updates)
+	if !ok {
+		return forwarded, 0
+	}
+
+	var date int
+	var newIDs []int
+	for _, update := range u.Updates {
+		var msgClass tg.MessageClass
+		switch upd := update.(type) {
+		case *tg.UpdateMessageID:
+			if i := slices.Index(randomIDs, upd.RandomID); i >= 0 {
+				forwarded[i].NewID = upd.ID
 			}
+			continue
+		case *tg.UpdateNewMessage:
+			msgClass = upd.Message
+		case *tg.UpdateNewChannelMessage:
+			msgClass = upd.Message
+		default:
+			continue
+		}
+		if msg, ok := msgClass.(*tg.Message); ok {
+			newIDs = append(newIDs, msg.ID)
+			date = msg.Date
 		}
 	}
 
-	result := fmt.Sprintf("Message forwarded successfully!\nFrom chat ID: %d\nOriginal message ID: %d\nTo chat ID: %d\nNew message ID: %d\nDate: %s",
-		fromChatID,
-		messageID,
-		toChatID,
-		forwardedMsgID,
-		time.Unix(int64(date), 0).Format(time.RFC3339),
-	)
+	// Without random ID updates, rely on the copies arriving in the original order
+	if len(newIDs) == len(ids) {
+		for i := range forwarded {
+			if forwarded[i].NewID == 0 {
+				forwarded[i].NewID = newIDs[i]
+			}
+		}
+	}
+	return forwarded, date
+}
 
-	return mcp.NewToolResultText(result), nil
+// uniqueInts returns values without repeats, keeping the first occurrence of each.
+func uniqueInts(values []int) []int {
+	seen := make(map[int]bool, len(values))
+	unique := values[:0:0]
+	for _, v := range values {
+		if !seen[v] {
+			seen[v] = true
+			unique = append(unique, v)
+		}
+	}
+	return unique
 }
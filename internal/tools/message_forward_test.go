@@ -0,0 +1,68 @@
+package tools
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/gotd/td/tg"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestForwardMessageIDs(t *testing.T) {
+	var request mcp.CallToolRequest
+	request.Params.Arguments = map[string]any{"message_id": float64(5), "message_ids": []any{float64(7), float64(5), float64(9)}}
+	ids, errResult := forwardMessageIDs(request)
+	if errResult != nil {
+		t.Fatalf("forwardMessageIDs() failed: %s", resultText(errResult))
+	}
+	if want := []int{5, 7, 9}; !reflect.DeepEqual(ids, want) {
+		t.Errorf("forwardMessageIDs() = %v, want %v", ids, want)
+	}
+
+	request.Params.Arguments = map[string]any{}
+	if _, errResult := forwardMessageIDs(request); errResult == nil {
+		t.Error("forwardMessageIDs() without IDs succeeded")
+	}
+
+	tooMany := make([]any, maxForwardMessages+1)
+	for i := range tooMany {
+		tooMany[i] = float64(i + 1)
+	}
+	request.Params.Arguments = map[string]any{"message_ids": tooMany}
+	if _, errResult := forwardMessageIDs(request); errResult == nil {
+		t.Errorf("forwardMessageIDs() accepted %d IDs", len(tooMany))
+	}
+}
+
+func TestForwardedMessages(t *testing.T) {
+	ids := []int{10, 11, 12}
+	randomIDs := []int64{100, 101, 102}
+	updates := &tg.Updates{Updates: []tg.UpdateClass{
+		// Telegram doesn't promise the random ID updates come in order
+		&tg.UpdateMessageID{ID: 502, RandomID: 102},
+		&tg.UpdateMessageID{ID: 500, RandomID: 100},
+		&tg.UpdateNewMessage{Message: &tg.Message{ID: 500, Date: 1_700_000_000}},
+		&tg.UpdateNewMessage{Message: &tg.Message{ID: 502, Date: 1_700_000_000}},
+	}}
+
+	got, date := forwardedMessages(updates, ids, randomIDs)
+	want := []forwardedMessage{{OldID: 10, NewID: 500}, {OldID: 11}, {OldID: 12, NewID: 502}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("forwardedMessages() = %v, want %v", got, want)
+	}
+	if date != 1_700_000_000 {
+		t.Errorf("date = %d, want 1700000000", date)
+	}
+
+	// Without random ID updates the copies are matched in order
+	updates = &tg.Updates{Updates: []tg.UpdateClass{
+		&tg.UpdateNewChannelMessage{Message: &tg.Message{ID: 7}},
+		&tg.UpdateNewChannelMessage{Message: &tg.Message{ID: 8}},
+		&tg.UpdateNewChannelMessage{Message: &tg.Message{ID: 9}},
+	}}
+	got, _ = forwardedMessages(updates, ids, randomIDs)
+	want = []forwardedMessage{{OldID: 10, NewID: 7}, {OldID: 11, NewID: 8}, {OldID: 12, NewID: 9}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("forwardedMessages() in order = %v, want %v", got, want)
+	}
+}
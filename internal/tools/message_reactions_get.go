@@ -0,0 +1,76 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/gotd/td/tg"
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/tolmachov/mcp-telegram/internal/tgclient"
+	"github.com/tolmachov/mcp-telegram/internal/tgdata"
+)
+
+// MessageReactionsGetHandler handles the GetMessageReactions tool
+type MessageReactionsGetHandler struct {
+	client *tg.Client
+	peers  *tgclient.PeerCache
+}
+
+// NewMessageReactionsGetHandler creates a new MessageReactionsGetHandler
+func NewMessageReactionsGetHandler(client *tg.Client, peers *tgclient.PeerCache) *MessageReactionsGetHandler {
+	return &MessageReactionsGetHandler{client: client, peers: peers}
+}
+
+// Tool returns the MCP tool definition
+func (h *MessageReactionsGetHandler) Tool() mcp.Tool {
+	return mcp.NewTool("GetMessageReactions",
+		mcp.WithDescription("Get the reactions on a message: how often each was used and who reacted. Telegram hides who reacted in channels and large groups; only the counts are returned there."),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithNumber("chat_id",
+			mcp.Description("The ID of the chat containing the message"),
+			mcp.Required(),
+		),
+		mcp.WithNumber("message_id",
+			mcp.Description("The ID of the message"),
+			mcp.Required(),
+		),
+		mcp.WithNumber("limit",
+			mcp.Description("Maximum people who reacted to list (default: 50, max: 100)"),
+		),
+		mcp.WithString("offset",
+			mcp.Description("next_offset from a previous call, to list more people who reacted"),
+		),
+	)
+}
+
+// Handle processes the GetMessageReactions tool request
+func (h *MessageReactionsGetHandler) Handle(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	chatID := mcp.ParseInt64(request, "chat_id", 0)
+	if chatID == 0 {
+		return mcp.NewToolResultError("chat_id is required"), nil
+	}
+
+	messageID := mcp.ParseInt(request, "message_id", 0)
+	if messageID == 0 {
+		return mcp.NewToolResultError("message_id is required"), nil
+	}
+
+	limit := min(max(mcp.ParseInt(request, "limit", 50), 1), 100)
+
+	var reactions *tgdata.MessageReactions
+	err := h.peers.WithPeer(ctx, h.client, chatID, func(peer tg.InputPeerClass) error {
+		var err error
+		reactions, err = tgdata.GetMessageReactions(ctx, h.client, peer, messageID, limit, mcp.ParseString(request, "offset", ""))
+		return err
+	})
+	if err != nil {
+		return toolError("get reactions", err), nil
+	}
+
+	data, err := json.MarshalIndent(reactions, "", "  ")
+	if err != nil {
+		return toolError("marshal reactions", err), nil
+	}
+	return mcp.NewToolResultText(string(data)), nil
+}
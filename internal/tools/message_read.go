@@ -8,129 +8,151 @@ import (
 	"github.com/gotd/td/tg"
 	"github.com/mark3labs/mcp-go/mcp"
 
+	"github.com/tolmachov/mcp-telegram/internal/messages"
 	"github.com/tolmachov/mcp-telegram/internal/tgclient"
 )
 
 // MessageReadHandler handles the MarkAsRead tool
 type MessageReadHandler struct {
-	client *tg.Client
+	provider *messages.Provider
 }
 
-// NewMessageReadHandler creates a new MessageReadHandler
-func NewMessageReadHandler(client *tg.Client) *MessageReadHandler {
-	return &MessageReadHandler{client: client}
+// NewMessageReadHandler creates a new MessageReadHandler.
+// The provider's rate limiter throttles marking each chat.
+func NewMessageReadHandler(provider *messages.Provider) *MessageReadHandler {
+	return &MessageReadHandler{provider: provider}
 }
 
 // Tool returns the MCP tool definition
 func (h *MessageReadHandler) Tool() mcp.Tool {
 	return mcp.NewTool("MarkAsRead",
-		mcp.WithDescription("Mark all messages in one or more chats as read."),
+		mcp.WithDescription("Mark all messages in one or more chats as read, or only those up to a message."),
 		mcp.WithIdempotentHintAnnotation(true),
 		mcp.WithArray("chat_ids",
 			mcp.WithNumberItems(),
 			mcp.Description("List of chat IDs to mark as read (max 100)"),
 			mcp.Required(),
 		),
+		mcp.WithNumber("max_message_id",
+			mcp.Description("Only mark messages up to and including this ID as read, leaving newer ones unread (default: all)"),
+		),
 	)
 }
 
-// markReadResult represents the result of marking a single chat as read
-type markReadResult struct {
-	chatID  int64
-	success bool
-	err     error
-}
-
 // Handle processes the MarkAsRead tool request
 func (h *MessageReadHandler) Handle(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	chatIDs := request.GetIntSlice("chat_ids", nil)
 	if len(chatIDs) == 0 {
 		return mcp.NewToolResultError("chat_ids is required and must not be empty"), nil
 	}
-	if len(chatIDs) > 100 {
-		return mcp.NewToolResultError("Cannot process more than 100 chats at once"), nil
+	if len(chatIDs) > maxBatchChats {
+		return mcp.NewToolResultError(fmt.Sprintf("Cannot process more than %d chats at once", maxBatchChats)), nil
 	}
 
-	// Collect results
-	results := make([]markReadResult, 0, len(chatIDs))
-
-	// Process sequentially
-	for _, cid := range chatIDs {
-		chatID := int64(cid)
-		err := h.markChatAsRead(ctx, chatID)
-
-		results = append(results, markReadResult{
-			chatID:  chatID,
-			success: err == nil,
-			err:     err,
-		})
-		// Continue even on error
+	maxID := mcp.ParseInt(request, "max_message_id", 0)
+	if maxID < 0 {
+		return mcp.NewToolResultError("max_message_id must be positive"), nil
 	}
 
-	return h.formatResult(results), nil
+	results := runChatBatch(ctx, h.provider, int64Slice(chatIDs), func(ctx context.Context, client *tg.Client, chatID int64) error {
+		return markChatRead(ctx, client, h.provider.Peers(), chatID, maxID)
+	})
+	if maxID > 0 {
+		for i := range results {
+			if results[i].err == nil {
+				results[i].detail = fmt.Sprintf("read up to message %d", maxID)
+			}
+		}
+	}
+	return formatChatBatch(results, "Marked %d out of %d chats as read successfully!"), nil
 }
 
-// markChatAsRead marks a single chat as read
-func (h *MessageReadHandler) markChatAsRead(ctx context.Context, chatID int64) error {
-	// Resolve the peer
-	peer, err := tgclient.ResolvePeer(ctx, h.client, chatID)
-	if err != nil {
-		return fmt.Errorf("failed to resolve peer: %w", err)
-	}
+// markChatRead marks a chat as read up to maxID, or entirely when maxID is 0.
+func markChatRead(ctx context.Context, client *tg.Client, peers *tgclient.PeerCache, chatID int64, maxID int) error {
+	return peers.WithPeer(ctx, client, chatID, func(peer tg.InputPeerClass) error {
+		return readHistory(ctx, client, peer, maxID)
+	})
+}
 
-	// Check if it's a channel
+// readHistory marks the messages of a peer up to maxID as read, or all of them when
+// maxID is 0. Channels and supergroups keep their own read state and take
+// channels.readHistory; private chats and basic groups take messages.readHistory.
+func readHistory(ctx context.Context, client *tg.Client, peer tg.InputPeerClass, maxID int) error {
 	switch p := peer.(type) {
 	case *tg.InputPeerChannel:
-		// For channels, use channels.readHistory
-		_, err = h.client.ChannelsReadHistory(ctx, &tg.ChannelsReadHistoryRequest{
+		_, err := client.ChannelsReadHistory(ctx, &tg.ChannelsReadHistoryRequest{
 			Channel: &tg.InputChannel{
 				ChannelID:  p.ChannelID,
 				AccessHash: p.AccessHash,
 			},
+			MaxID: maxID,
 		})
 		if err != nil {
 			return fmt.Errorf("failed to mark channel as read: %w", err)
 		}
 	default:
-		// For private chats and groups, use messages.readHistory
-		_, err = h.client.MessagesReadHistory(ctx, &tg.MessagesReadHistoryRequest{
-			Peer: peer,
+		_, err := client.MessagesReadHistory(ctx, &tg.MessagesReadHistoryRequest{
+			Peer:  peer,
+			MaxID: maxID,
 		})
 		if err != nil {
 			return fmt.Errorf("failed to mark chat as read: %w", err)
 		}
 	}
-
 	return nil
 }
 
-// formatResult formats the batch processing results into a user-friendly message
-func (h *MessageReadHandler) formatResult(results []markReadResult) *mcp.CallToolResult {
+// maxBatchChats is the most chats a batch tool such as MarkAsRead processes at once.
+const maxBatchChats = 100
+
+// chatBatchResult is the outcome of a batch tool for a single chat.
+type chatBatchResult struct {
+	chatID int64
+	err    error
+	detail string // what was applied to a successful chat, when worth noting
+}
+
+// runChatBatch applies fn to each chat sequentially with the provider's paced client,
+// continuing after failures.
+func runChatBatch(ctx context.Context, provider *messages.Provider, chatIDs []int64, fn func(ctx context.Context, client *tg.Client, chatID int64) error) []chatBatchResult {
+	client := provider.Paced()
+	results := make([]chatBatchResult, 0, len(chatIDs))
+	for _, chatID := range chatIDs {
+		results = append(results, chatBatchResult{chatID: chatID, err: fn(ctx, client, chatID)})
+	}
+	return results
+}
+
+// formatChatBatch formats batch results into a user-friendly message. summary takes
+// the number of successful chats and the total.
+func formatChatBatch(results []chatBatchResult, summary string) *mcp.CallToolResult {
 	successful := 0
-	failed := 0
 
-	var successIDs []int64
+	var successes []chatBatchResult
 	var failures []string
 
 	for _, r := range results {
-		if r.success {
+		if r.err == nil {
 			successful++
-			successIDs = append(successIDs, r.chatID)
+			successes = append(successes, r)
 		} else {
-			failed++
-			failures = append(failures, fmt.Sprintf("  - Chat %d: %v", r.chatID, r.err))
+			failures = append(failures, fmt.Sprintf("  - Chat %d: %v", r.chatID, tgclient.ClassifyError(r.err)))
 		}
 	}
 
 	// Build message
 	var msg strings.Builder
-	msg.WriteString(fmt.Sprintf("Marked %d out of %d chats as read successfully!\n\n",
-		successful, len(results)))
+	msg.WriteString(fmt.Sprintf(summary, successful, len(results)))
+	msg.WriteString("\n\n")
 
-	if len(successIDs) > 0 {
+	if len(successes) > 0 {
 		msg.WriteString("Successful:\n")
-		for _, id := range successIDs {
-			msg.WriteString(fmt.Sprintf("  - Chat %d\n", id))
+		for _, r := range successes {
+			if r.detail != "" {
+				msg.WriteString(fmt.Sprintf("  - Chat %d (%s)\n", r.chatID, r.detail))
+			} else {
+				msg.WriteString(fmt.Sprintf("  - Chat %d\n", r.chatID))
+			}
 		}
 	}
 
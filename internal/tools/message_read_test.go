@@ -0,0 +1,114 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/gotd/td/bin"
+	"github.com/gotd/td/tg"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// recordingInvoker records Telegram requests and answers each with the response
+// registered for its type.
+type recordingInvoker struct {
+	responses map[uint32]bin.Encoder
+	requests  []bin.Encoder
+}
+
+func (r *recordingInvoker) Invoke(_ context.Context, input bin.Encoder, output bin.Decoder) error {
+	r.requests = append(r.requests, input)
+	typed, ok := input.(interface{ TypeID() uint32 })
+	if !ok {
+		return fmt.Errorf("untyped request %T", input)
+	}
+	resp, ok := r.responses[typed.TypeID()]
+	if !ok {
+		return fmt.Errorf("unexpected request %T", input)
+	}
+	var buf bin.Buffer
+	if err := resp.Encode(&buf); err != nil {
+		return err
+	}
+	return output.Decode(&buf)
+}
+
+func TestReadHistory(t *testing.T) {
+	tests := []struct {
+		name  string
+		peer  tg.InputPeerClass
+		maxID int
+		check func(t *testing.T, req bin.Encoder)
+	}{
+		{
+			name:  "channel up to a message",
+			peer:  &tg.InputPeerChannel{ChannelID: 77, AccessHash: 7},
+			maxID: 120,
+			check: func(t *testing.T, req bin.Encoder) {
+				r, ok := req.(*tg.ChannelsReadHistoryRequest)
+				if !ok {
+					t.Fatalf("request = %T, want channels.readHistory", req)
+				}
+				if ch := r.Channel.(*tg.InputChannel); ch.ChannelID != 77 || ch.AccessHash != 7 || r.MaxID != 120 {
+					t.Errorf("request = %+v, want channel 77 up to 120", r)
+				}
+			},
+		},
+		{
+			name:  "basic group up to a message",
+			peer:  &tg.InputPeerChat{ChatID: 55},
+			maxID: 30,
+			check: func(t *testing.T, req bin.Encoder) {
+				r, ok := req.(*tg.MessagesReadHistoryRequest)
+				if !ok {
+					t.Fatalf("request = %T, want messages.readHistory", req)
+				}
+				if p, ok := r.Peer.(*tg.InputPeerChat); !ok || p.ChatID != 55 || r.MaxID != 30 {
+					t.Errorf("request = %+v, want chat 55 up to 30", r)
+				}
+			},
+		},
+		{
+			name: "private chat entirely",
+			peer: &tg.InputPeerUser{UserID: 10, AccessHash: 1},
+			check: func(t *testing.T, req bin.Encoder) {
+				r, ok := req.(*tg.MessagesReadHistoryRequest)
+				if !ok {
+					t.Fatalf("request = %T, want messages.readHistory", req)
+				}
+				if r.MaxID != 0 {
+					t.Errorf("max_id = %d, want 0 to read everything", r.MaxID)
+				}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			inv := &recordingInvoker{responses: map[uint32]bin.Encoder{
+				tg.ChannelsReadHistoryRequestTypeID: &tg.BoolTrue{},
+				tg.MessagesReadHistoryRequestTypeID: &tg.MessagesAffectedMessages{Pts: 1, PtsCount: 1},
+			}}
+			if err := readHistory(t.Context(), tg.NewClient(inv), tt.peer, tt.maxID); err != nil {
+				t.Fatalf("readHistory() error = %v", err)
+			}
+			if len(inv.requests) != 1 {
+				t.Fatalf("got %d requests, want 1", len(inv.requests))
+			}
+			tt.check(t, inv.requests[0])
+		})
+	}
+}
+
+func TestFormatChatBatchDetail(t *testing.T) {
+	result := formatChatBatch([]chatBatchResult{
+		{chatID: -1001234567890, detail: "read up to message 120"},
+		{chatID: 55},
+	}, "Marked %d out of %d chats as read successfully!")
+
+	text := result.Content[0].(mcp.TextContent).Text
+	if !strings.Contains(text, "  - Chat -1001234567890 (read up to message 120)\n  - Chat 55\n") {
+		t.Errorf("per-chat details missing from %q", text)
+	}
+}
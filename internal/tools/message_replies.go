@@ -0,0 +1,105 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gotd/td/tg"
+	"github.com/gotd/td/tgerr"
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/tolmachov/mcp-telegram/internal/messages"
+	"github.com/tolmachov/mcp-telegram/internal/tgclient"
+)
+
+// MessageRepliesHandler handles the GetReplies tool
+type MessageRepliesHandler struct {
+	client   *tg.Client
+	provider *messages.Provider
+}
+
+// NewMessageRepliesHandler creates a new MessageRepliesHandler
+func NewMessageRepliesHandler(client *tg.Client, provider *messages.Provider) *MessageRepliesHandler {
+	return &MessageRepliesHandler{
+		client:   client,
+		provider: provider,
+	}
+}
+
+// repliesResult is the JSON result of GetReplies.
+type repliesResult struct {
+	*messages.FetchResult
+	// DiscussionChatID is the linked discussion group the comments on a channel post
+	// live in, for replying to them
+	DiscussionChatID int64 `json:"discussion_chat_id,omitempty"`
+}
+
+// Tool returns the MCP tool definition
+func (h *MessageRepliesHandler) Tool() mcp.Tool {
+	return mcp.NewTool("GetReplies",
+		mcp.WithDescription("Get the comments on a channel post from the channel's linked discussion group, or the replies in a thread of a group, newest first. Messages carry thread_id and the same details as GetMessages."),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithNumber("chat_id",
+			mcp.Description("The ID of the channel or group with the post (or use chat)"),
+		),
+		chatRefOption("chat", "chat_id", "The channel or group with the post"),
+		mcp.WithNumber("message_id",
+			mcp.Description("The ID of the channel post or of the message starting the thread"),
+			mcp.Required(),
+		),
+		mcp.WithNumber("limit",
+			mcp.Description(fmt.Sprintf("Maximum number of replies to return (default 50, max %d)", messages.MaxFetchLimit)),
+		),
+		mcp.WithNumber("offset_id",
+			mcp.Description("Reply ID to start from for pagination, e.g. next_id of the previous page"),
+		),
+	)
+}
+
+// Handle processes the GetReplies tool request
+func (h *MessageRepliesHandler) Handle(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	chatID, errResult := chatIDParam(ctx, h.client, h.provider.Peers(), request, "chat_id", "chat")
+	if errResult != nil {
+		return errResult, nil
+	}
+	msgID := mcp.ParseInt(request, "message_id", 0)
+	if msgID <= 0 {
+		return mcp.NewToolResultError("message_id is required"), nil
+	}
+
+	opts, err := messages.ParseFetchParams(map[string]any{
+		"limit":     request.GetArguments()["limit"],
+		"offset_id": request.GetArguments()["offset_id"],
+	})
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Invalid parameters: %v", err)), nil
+	}
+	opts.ThreadID = msgID
+	opts.GroupAlbums = true
+
+	result, err := h.provider.Fetch(ctx, chatID, opts)
+	if err != nil {
+		if tgerr.Is(err, "MSG_ID_INVALID") {
+			return mcp.NewToolResultError(fmt.Sprintf("Message %d has no replies thread: the post may not exist, have comments disabled, or the channel has no discussion group", msgID)), nil
+		}
+		return toolError("get replies", err), nil
+	}
+	return jsonResult(repliesResult{FetchResult: result, DiscussionChatID: discussionChatID(chatID, result.Messages)})
+}
+
+// discussionChatID returns the chat ID of the group the replies were posted in when it
+// isn't chatID, i.e. the discussion group of a channel, or 0.
+func discussionChatID(chatID int64, replies []messages.Message) int64 {
+	for _, msg := range replies {
+		if msg.Raw == nil {
+			continue
+		}
+		if channel, ok := msg.Raw.PeerID.(*tg.PeerChannel); ok {
+			if id := tgclient.ChannelDialogID(channel.ChannelID); id != chatID {
+				return id
+			}
+		}
+		return 0
+	}
+	return 0
+}
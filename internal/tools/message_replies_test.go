@@ -0,0 +1,33 @@
+package tools
+
+import (
+	"testing"
+
+	"github.com/gotd/td/tg"
+
+	"github.com/tolmachov/mcp-telegram/internal/messages"
+)
+
+func TestDiscussionChatID(t *testing.T) {
+	inChannel := func(channelID int64) messages.Message {
+		return messages.Message{ID: 1, Raw: &tg.Message{ID: 1, PeerID: &tg.PeerChannel{ChannelID: channelID}}}
+	}
+
+	tests := []struct {
+		name    string
+		chatID  int64
+		replies []messages.Message
+		want    int64
+	}{
+		{"comments in the discussion group", -1000000000005, []messages.Message{inChannel(9)}, -1000000000009},
+		{"thread in the same group", -1000000000009, []messages.Message{inChannel(9)}, 0},
+		{"no replies", -1000000000005, nil, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := discussionChatID(tt.chatID, tt.replies); got != tt.want {
+				t.Errorf("discussionChatID() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
@@ -8,17 +8,22 @@ import (
 	"github.com/gotd/td/tg"
 	"github.com/mark3labs/mcp-go/mcp"
 
+	"github.com/tolmachov/mcp-telegram/internal/messages"
+	"github.com/tolmachov/mcp-telegram/internal/recent"
 	"github.com/tolmachov/mcp-telegram/internal/tgclient"
 )
 
 // MessageReplyHandler handles the ReplyToMessage tool
 type MessageReplyHandler struct {
-	client *tg.Client
+	client   *tg.Client
+	peers    *tgclient.PeerCache
+	chatName recent.NameLookup
 }
 
-// NewMessageReplyHandler creates a new MessageReplyHandler
-func NewMessageReplyHandler(client *tg.Client) *MessageReplyHandler {
-	return &MessageReplyHandler{client: client}
+// NewMessageReplyHandler creates a new MessageReplyHandler.
+// chatName resolves chat names for the expected_chat_name check.
+func NewMessageReplyHandler(client *tg.Client, peers *tgclient.PeerCache, chatName recent.NameLookup) *MessageReplyHandler {
+	return &MessageReplyHandler{client: client, peers: peers, chatName: chatName}
 }
 
 // Tool returns the MCP tool definition
@@ -27,9 +32,9 @@ func (h *MessageReplyHandler) Tool() mcp.Tool {
 		mcp.WithDescription("Reply to a specific message in a chat."),
 		mcp.WithOpenWorldHintAnnotation(true),
 		mcp.WithNumber("chat_id",
-			mcp.Description("The ID of the chat containing the message"),
-			mcp.Required(),
+			mcp.Description("The ID of the chat containing the message (or use chat)"),
 		),
+		chatRefOption("chat", "chat_id", "The chat containing the message"),
 		mcp.WithNumber("message_id",
 			mcp.Description("The ID of the message to reply to"),
 			mcp.Required(),
@@ -38,14 +43,15 @@ func (h *MessageReplyHandler) Tool() mcp.Tool {
 			mcp.Description("The reply text to send"),
 			mcp.Required(),
 		),
+		expectedChatNameOption("the chat"),
 	)
 }
 
 // Handle processes the ReplyToMessage tool request
 func (h *MessageReplyHandler) Handle(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	chatID := mcp.ParseInt64(request, "chat_id", 0)
-	if chatID == 0 {
-		return mcp.NewToolResultError("chat_id is required"), nil
+	chatID, errResult := chatIDParam(ctx, h.client, h.peers, request, "chat_id", "chat")
+	if errResult != nil {
+		return errResult, nil
 	}
 
 	messageID := mcp.ParseInt(request, "message_id", 0)
@@ -58,58 +64,40 @@ func (h *MessageReplyHandler) Handle(ctx context.Context, request mcp.CallToolRe
 		return mcp.NewToolResultError("text is required"), nil
 	}
 
-	// Resolve the peer
-	peer, err := tgclient.ResolvePeer(ctx, h.client, chatID)
-	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Failed to resolve peer: %v", err)), nil
+	if errResult := checkExpectedChatName(ctx, request, h.chatName, chatID); errResult != nil {
+		return errResult, nil
 	}
 
 	// Send the reply
-	updates, err := h.client.MessagesSendMessage(ctx, &tg.MessagesSendMessageRequest{
-		Peer:     peer,
-		Message:  text,
-		RandomID: time.Now().UnixNano(),
-		ReplyTo: &tg.InputReplyToMessage{
-			ReplyToMsgID: messageID,
-		},
+	var updates tg.UpdatesClass
+	err := h.peers.WithPeer(ctx, h.client, chatID, func(peer tg.InputPeerClass) error {
+		var err error
+		updates, err = h.client.MessagesSendMessage(ctx, &tg.MessagesSendMessageRequest{
+			Peer:     peer,
+			Message:  text,
+			RandomID: time.Now().UnixNano(),
+			ReplyTo: &tg.InputReplyToMessage{
+				ReplyToMsgID: messageID,
+			},
+		})
+		return err
 	})
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Failed to send reply: %v", err)), nil
+		return toolError("send reply", err), nil
 	}
 
-	// Extract message ID from updates
-	var sentMsgID int
-	var date int
-
-	switch u := updates.(type) {
-	case *tg.UpdateShortSentMessage:
-		sentMsgID = u.ID
-		date = u.Date
-	case *tg.Updates:
-		for _, update := range u.Updates {
-			if newMsg, ok := update.(*tg.UpdateNewMessage); ok {
-				if msg, ok := newMsg.Message.(*tg.Message); ok {
-					sentMsgID = msg.ID
-					date = msg.Date
-					break
-				}
-			}
-			if newMsg, ok := update.(*tg.UpdateNewChannelMessage); ok {
-				if msg, ok := newMsg.Message.(*tg.Message); ok {
-					sentMsgID = msg.ID
-					date = msg.Date
-					break
-				}
-			}
-		}
-	}
+	sent := extractSentMessage(updates)
 
 	result := fmt.Sprintf("Reply sent successfully!\nChat ID: %d\nReplying to message ID: %d\nNew message ID: %d\nDate: %s",
 		chatID,
 		messageID,
-		sentMsgID,
-		time.Unix(int64(date), 0).Format(time.RFC3339),
+		sent.ID,
+		time.Unix(int64(sent.Date), 0).Format(time.RFC3339),
 	)
+	if sent.Link != "" {
+		result += fmt.Sprintf("\nLink: %s", sent.Link)
+	}
+	result += fmt.Sprintf("\nText: %s", messages.TruncateRunes(text, sentTextSnippetRunes))
 
 	return mcp.NewToolResultText(result), nil
 }
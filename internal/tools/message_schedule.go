@@ -4,21 +4,30 @@ import (
 	"context"
 	"fmt"
 	"time"
+	_ "time/tzdata" // timezone names work without a system zone database, e.g. on Windows
 
 	"github.com/gotd/td/tg"
 	"github.com/mark3labs/mcp-go/mcp"
 
+	"github.com/tolmachov/mcp-telegram/internal/messages"
+	"github.com/tolmachov/mcp-telegram/internal/recent"
 	"github.com/tolmachov/mcp-telegram/internal/tgclient"
 )
 
+// maxScheduleAhead is how far ahead Telegram lets a message be scheduled.
+const maxScheduleAhead = 365 * 24 * time.Hour
+
 // MessageScheduleHandler handles the ScheduleMessage tool
 type MessageScheduleHandler struct {
-	client *tg.Client
+	client   *tg.Client
+	peers    *tgclient.PeerCache
+	chatName recent.NameLookup
 }
 
-// NewMessageScheduleHandler creates a new MessageScheduleHandler
-func NewMessageScheduleHandler(client *tg.Client) *MessageScheduleHandler {
-	return &MessageScheduleHandler{client: client}
+// NewMessageScheduleHandler creates a new MessageScheduleHandler.
+// chatName resolves chat names for the expected_chat_name check.
+func NewMessageScheduleHandler(client *tg.Client, peers *tgclient.PeerCache, chatName recent.NameLookup) *MessageScheduleHandler {
+	return &MessageScheduleHandler{client: client, peers: peers, chatName: chatName}
 }
 
 // Tool returns the MCP tool definition
@@ -35,9 +44,15 @@ func (h *MessageScheduleHandler) Tool() mcp.Tool {
 			mcp.Required(),
 		),
 		mcp.WithNumber("delay_seconds",
-			mcp.Description("Number of seconds from now to send the message"),
-			mcp.Required(),
+			mcp.Description("Number of seconds from now to send the message; give this or send_at"),
+		),
+		mcp.WithString("send_at",
+			mcp.Description("When to send the message: YYYY-MM-DD HH:MM:SS, YYYY-MM-DD (midnight) or RFC3339 with an offset, e.g. 2025-06-01T09:00:00+02:00; give this or delay_seconds. At most 365 days ahead"),
+		),
+		mcp.WithString("timezone",
+			mcp.Description("IANA time zone, e.g. Europe/Berlin, for a send_at without an offset (default: the server's local zone)"),
 		),
+		expectedChatNameOption("the chat"),
 	)
 }
 
@@ -53,61 +68,112 @@ func (h *MessageScheduleHandler) Handle(ctx context.Context, request mcp.CallToo
 		return mcp.NewToolResultError("message is required"), nil
 	}
 
-	delaySeconds := mcp.ParseInt(request, "delay_seconds", 0)
-	if delaySeconds < 0 {
-		return mcp.NewToolResultError("delay_seconds must be a positive number"), nil
+	sendAtParam := mcp.ParseString(request, "send_at", "")
+	_, hasDelay := request.GetArguments()["delay_seconds"]
+	if hasDelay == (sendAtParam != "") {
+		return mcp.NewToolResultError("give either delay_seconds or send_at"), nil
 	}
 
-	// Resolve the peer
-	peer, err := tgclient.ResolvePeer(ctx, h.client, chatID)
-	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Failed to resolve peer: %v", err)), nil
+	now := time.Now()
+	var scheduleTime time.Time
+	var loc *time.Location
+	if sendAtParam != "" {
+		var err error
+		scheduleTime, loc, err = parseSendAt(sendAtParam, mcp.ParseString(request, "timezone", ""), now)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+	} else {
+		delaySeconds := mcp.ParseInt(request, "delay_seconds", 0)
+		if delaySeconds < 0 {
+			return mcp.NewToolResultError("delay_seconds must be a positive number"), nil
+		}
+		scheduleTime = now.Add(time.Duration(delaySeconds) * time.Second)
+	}
+	delaySeconds := int(scheduleTime.Sub(now).Seconds())
+
+	if errResult := checkExpectedChatName(ctx, request, h.chatName, chatID); errResult != nil {
+		return errResult, nil
 	}
 
-	// Calculate schedule time
-	scheduleTime := time.Now().Add(time.Duration(delaySeconds) * time.Second)
 	scheduleTimestamp := int(scheduleTime.Unix())
 
 	// Send the scheduled message
-	updates, err := h.client.MessagesSendMessage(ctx, &tg.MessagesSendMessageRequest{
-		Peer:         peer,
-		Message:      message,
-		RandomID:     time.Now().UnixNano(),
-		ScheduleDate: scheduleTimestamp,
+	var updates tg.UpdatesClass
+	err := h.peers.WithPeer(ctx, h.client, chatID, func(peer tg.InputPeerClass) error {
+		var err error
+		updates, err = h.client.MessagesSendMessage(ctx, &tg.MessagesSendMessageRequest{
+			Peer:         peer,
+			Message:      message,
+			RandomID:     time.Now().UnixNano(),
+			ScheduleDate: scheduleTimestamp,
+		})
+		return err
 	})
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Failed to schedule message: %v", err)), nil
+		return toolError("schedule message", err), nil
 	}
 
-	// Extract message ID from updates
-	var msgID int
-
-	switch u := updates.(type) {
-	case *tg.UpdateShortSentMessage:
-		msgID = u.ID
-	case *tg.Updates:
-		for _, update := range u.Updates {
-			if newMsg, ok := update.(*tg.UpdateNewScheduledMessage); ok {
-				if msg, ok := newMsg.Message.(*tg.Message); ok {
-					msgID = msg.ID
-					break
-				}
-			}
-		}
-	}
+	msgID := extractSentMessage(updates).ID
 
 	var result string
 	if delaySeconds < 10 {
-		result = fmt.Sprintf("Message sent immediately (delay was less than 10 seconds)\nMessage ID: %d\nTo: %d",
-			msgID, chatID)
+		result = fmt.Sprintf("Message sent immediately (delay was less than 10 seconds)\nMessage ID: %d\nTo: %d\nText: %s",
+			msgID, chatID, messages.TruncateRunes(message, sentTextSnippetRunes))
 	} else {
-		result = fmt.Sprintf("Message scheduled successfully!\nScheduled Message ID: %d\nWill be sent at: %s\nTo: %d\nDelay: %d seconds\n\nNote: The message is stored on Telegram's servers and will be sent automatically at the scheduled time, even if you're offline.",
+		result = fmt.Sprintf("Message scheduled successfully!\nScheduled Message ID: %d\nWill be sent at: %s\nTo: %d\nDelay: %d seconds\nText: %s\n\nNote: The message is stored on Telegram's servers and will be sent automatically at the scheduled time, even if you're offline.",
 			msgID,
-			scheduleTime.Format("2006-01-02 15:04:05"),
+			formatScheduleTime(scheduleTime, loc),
 			chatID,
 			delaySeconds,
+			messages.TruncateRunes(message, sentTextSnippetRunes),
 		)
 	}
 
 	return mcp.NewToolResultText(result), nil
 }
+
+// parseSendAt parses send_at as RFC3339 or in parseDate's formats, the latter in the
+// named IANA zone when given and the local one otherwise. It returns the zone the
+// time is reported in, nil for the local one, and rejects times Telegram can't
+// schedule: past ones and those more than a year ahead.
+func parseSendAt(s, timezone string, now time.Time) (time.Time, *time.Location, error) {
+	var loc *time.Location
+	if timezone != "" {
+		var err error
+		loc, err = time.LoadLocation(timezone)
+		if err != nil {
+			return time.Time{}, nil, fmt.Errorf("unknown timezone %q, expected an IANA name like Europe/Berlin", timezone)
+		}
+	}
+
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		parseLoc := loc
+		if parseLoc == nil {
+			parseLoc = time.Local
+		}
+		if t, _, err = messages.ParseDate(s, parseLoc); err != nil {
+			return time.Time{}, nil, fmt.Errorf("invalid send_at %q, expected YYYY-MM-DD HH:MM:SS, YYYY-MM-DD or RFC3339", s)
+		}
+	}
+
+	if !t.After(now) {
+		return time.Time{}, nil, fmt.Errorf("send_at %s is in the past", formatScheduleTime(t, loc))
+	}
+	if t.Sub(now) > maxScheduleAhead {
+		return time.Time{}, nil, fmt.Errorf("send_at %s is more than 365 days ahead, Telegram's scheduling limit", formatScheduleTime(t, loc))
+	}
+	return t, loc, nil
+}
+
+// formatScheduleTime shows t in UTC and in loc, the local zone when nil.
+func formatScheduleTime(t time.Time, loc *time.Location) string {
+	zone := "server local time"
+	if loc != nil {
+		zone = loc.String()
+	} else {
+		loc = time.Local
+	}
+	return fmt.Sprintf("%s UTC / %s in %s", t.UTC().Format("2006-01-02 15:04:05"), t.In(loc).Format("2006-01-02 15:04:05 MST"), zone)
+}
@@ -0,0 +1,86 @@
+package tools
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseSendAt(t *testing.T) {
+	now := time.Date(2025, 5, 31, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name     string
+		sendAt   string
+		timezone string
+		want     time.Time
+		wantErr  string
+	}{
+		{
+			name:     "datetime in a timezone",
+			sendAt:   "2025-06-01 09:00:00",
+			timezone: "Europe/Berlin",
+			want:     time.Date(2025, 6, 1, 7, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "date only in a timezone",
+			sendAt:   "2025-06-02",
+			timezone: "Asia/Tokyo",
+			want:     time.Date(2025, 6, 1, 15, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "RFC3339 offset wins over the timezone",
+			sendAt:   "2025-06-01T09:00:00-04:00",
+			timezone: "Europe/Berlin",
+			want:     time.Date(2025, 6, 1, 13, 0, 0, 0, time.UTC),
+		},
+		{
+			name:    "past",
+			sendAt:  "2025-05-31T11:59:00Z",
+			wantErr: "in the past",
+		},
+		{
+			name:    "more than a year ahead",
+			sendAt:  "2026-06-01T12:00:00Z",
+			wantErr: "more than 365 days ahead",
+		},
+		{
+			name:     "unknown timezone",
+			sendAt:   "2025-06-01 09:00:00",
+			timezone: "Mars/Olympus",
+			wantErr:  "unknown timezone",
+		},
+		{
+			name:    "bad format",
+			sendAt:  "tomorrow 9am",
+			wantErr: "invalid send_at",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, _, err := parseSendAt(tt.sendAt, tt.timezone, now)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("parseSendAt() error = %v, want one containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("parseSendAt() = %v, want %v", got.UTC(), tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatScheduleTime(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := formatScheduleTime(time.Date(2025, 6, 1, 7, 0, 0, 0, time.UTC), berlin)
+	if want := "2025-06-01 07:00:00 UTC / 2025-06-01 09:00:00 CEST in Europe/Berlin"; got != want {
+		t.Errorf("formatScheduleTime() = %q, want %q", got, want)
+	}
+}
@@ -3,22 +3,28 @@ package tools
 import (
 	"context"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/gotd/td/tg"
 	"github.com/mark3labs/mcp-go/mcp"
 
+	"github.com/tolmachov/mcp-telegram/internal/messages"
+	"github.com/tolmachov/mcp-telegram/internal/recent"
 	"github.com/tolmachov/mcp-telegram/internal/tgclient"
 )
 
 // MessageSendHandler handles the SendMessage tool
 type MessageSendHandler struct {
-	client *tg.Client
+	client   *tg.Client
+	peers    *tgclient.PeerCache
+	chatName recent.NameLookup
 }
 
-// NewMessageSendHandler creates a new MessageSendHandler
-func NewMessageSendHandler(client *tg.Client) *MessageSendHandler {
-	return &MessageSendHandler{client: client}
+// NewMessageSendHandler creates a new MessageSendHandler.
+// chatName resolves chat names for the expected_chat_name check.
+func NewMessageSendHandler(client *tg.Client, peers *tgclient.PeerCache, chatName recent.NameLookup) *MessageSendHandler {
+	return &MessageSendHandler{client: client, peers: peers, chatName: chatName}
 }
 
 // Tool returns the MCP tool definition
@@ -27,21 +33,33 @@ func (h *MessageSendHandler) Tool() mcp.Tool {
 		mcp.WithDescription("Send a message to a contact, group, or channel."),
 		mcp.WithOpenWorldHintAnnotation(true),
 		mcp.WithNumber("chat_id",
-			mcp.Description("The ID of the chat to send the message to"),
-			mcp.Required(),
+			mcp.Description("The ID of the chat to send the message to (or use chat)"),
 		),
+		chatRefOption("chat", "chat_id", "The chat to send the message to"),
+		savedMessagesOption(),
 		mcp.WithString("message",
 			mcp.Description("The message text to send"),
 			mcp.Required(),
 		),
+		mcp.WithBoolean("silent",
+			mcp.Description("Send without a notification sound (default: false)"),
+		),
+		mcp.WithBoolean("disable_preview",
+			mcp.Description("Don't show a preview of the first link (default: false)"),
+		),
+		mcp.WithString("parse_mode",
+			mcp.Description("'markdown' to render **bold**, *italic*, ~~strikethrough~~, `code`, ```code blocks``` and [links](url); plain text if unset"),
+			mcp.Enum(parseModeMarkdown),
+		),
+		expectedChatNameOption("the chat"),
 	)
 }
 
 // Handle processes the SendMessage tool request
 func (h *MessageSendHandler) Handle(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	chatID := mcp.ParseInt64(request, "chat_id", 0)
-	if chatID == 0 {
-		return mcp.NewToolResultError("chat_id is required"), nil
+	chatID, errResult := chatIDParam(ctx, h.client, h.peers, request, "chat_id", "chat")
+	if errResult != nil {
+		return errResult, nil
 	}
 
 	message := mcp.ParseString(request, "message", "")
@@ -49,54 +67,96 @@ func (h *MessageSendHandler) Handle(ctx context.Context, request mcp.CallToolReq
 		return mcp.NewToolResultError("message is required"), nil
 	}
 
-	// Resolve the peer
-	peer, err := tgclient.ResolvePeer(ctx, h.client, chatID)
-	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Failed to resolve peer: %v", err)), nil
+	opts := textOptions{
+		Silent:    mcp.ParseBoolean(request, "silent", false),
+		NoWebpage: mcp.ParseBoolean(request, "disable_preview", false),
+	}
+	text := message
+	switch parseMode := mcp.ParseString(request, "parse_mode", ""); parseMode {
+	case "":
+		// Plain text
+	case parseModeMarkdown:
+		text, opts.Entities = markdownEntities(message)
+		if text == "" {
+			return mcp.NewToolResultError("message is empty after markdown formatting"), nil
+		}
+	default:
+		return mcp.NewToolResultError(fmt.Sprintf("invalid parse_mode: %s (use '%s')", parseMode, parseModeMarkdown)), nil
 	}
 
-	// Send the message
-	updates, err := h.client.MessagesSendMessage(ctx, &tg.MessagesSendMessageRequest{
-		Peer:     peer,
-		Message:  message,
-		RandomID: time.Now().UnixNano(),
-	})
-	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Failed to send message: %v", err)), nil
+	if errResult := checkExpectedChatName(ctx, request, h.chatName, chatID); errResult != nil {
+		return errResult, nil
 	}
 
-	// Extract message ID from updates
-	var msgID int
-	var date int
-
-	switch u := updates.(type) {
-	case *tg.UpdateShortSentMessage:
-		msgID = u.ID
-		date = u.Date
-	case *tg.Updates:
-		for _, update := range u.Updates {
-			if newMsg, ok := update.(*tg.UpdateNewMessage); ok {
-				if msg, ok := newMsg.Message.(*tg.Message); ok {
-					msgID = msg.ID
-					date = msg.Date
-					break
-				}
-			}
-			if newMsg, ok := update.(*tg.UpdateNewChannelMessage); ok {
-				if msg, ok := newMsg.Message.(*tg.Message); ok {
-					msgID = msg.ID
-					date = msg.Date
-					break
-				}
-			}
-		}
+	sent, err := sendTextWithOptions(ctx, h.client, h.peers, chatID, text, opts)
+	if err != nil {
+		return toolError("send message", err), nil
 	}
 
 	result := fmt.Sprintf("Message sent successfully!\nMessage ID: %d\nDate: %s\nTo: %d",
-		msgID,
-		time.Unix(int64(date), 0).Format(time.RFC3339),
+		sent.ID,
+		time.Unix(int64(sent.Date), 0).Format(time.RFC3339),
 		chatID,
 	)
+	if sent.Link != "" {
+		result += fmt.Sprintf("\nLink: %s", sent.Link)
+	}
+	result += fmt.Sprintf("\nText: %s", messages.TruncateRunes(text, sentTextSnippetRunes))
+	if applied := opts.applied(); len(applied) > 0 {
+		result += "\nOptions: " + strings.Join(applied, ", ")
+	}
 
 	return mcp.NewToolResultText(result), nil
 }
+
+// parseModeMarkdown is the SendMessage parse_mode for basic markdown.
+const parseModeMarkdown = "markdown"
+
+// textOptions are the optional settings of a sent text message.
+type textOptions struct {
+	Silent    bool // no notification sound
+	NoWebpage bool // no link preview
+	Entities  []tg.MessageEntityClass
+}
+
+// applied describes the options in effect, e.g. for a success message.
+func (o textOptions) applied() []string {
+	var applied []string
+	if o.Silent {
+		applied = append(applied, "silent")
+	}
+	if o.NoWebpage {
+		applied = append(applied, "link preview disabled")
+	}
+	if len(o.Entities) > 0 {
+		applied = append(applied, fmt.Sprintf("markdown (%d formatted spans)", len(o.Entities)))
+	}
+	return applied
+}
+
+// sendText sends a plain text message to a chat.
+func sendText(ctx context.Context, client *tg.Client, peers *tgclient.PeerCache, chatID int64, message string) (sentMessage, error) {
+	return sendTextWithOptions(ctx, client, peers, chatID, message, textOptions{})
+}
+
+// sendTextWithOptions sends a text message to a chat with the given options.
+func sendTextWithOptions(ctx context.Context, client *tg.Client, peers *tgclient.PeerCache, chatID int64, message string, opts textOptions) (sentMessage, error) {
+	var updates tg.UpdatesClass
+	err := peers.WithPeer(ctx, client, chatID, func(peer tg.InputPeerClass) error {
+		var err error
+		updates, err = client.MessagesSendMessage(ctx, &tg.MessagesSendMessageRequest{
+			Peer:      peer,
+			Message:   message,
+			RandomID:  time.Now().UnixNano(),
+			Silent:    opts.Silent,
+			NoWebpage: opts.NoWebpage,
+			Entities:  opts.Entities,
+		})
+		return err
+	})
+	if err != nil {
+		return sentMessage{}, err
+	}
+
+	return extractSentMessage(updates), nil
+}
@@ -0,0 +1,55 @@
+package tools
+
+import (
+	"context"
+
+	"github.com/gotd/td/tg"
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/tolmachov/mcp-telegram/internal/messages"
+	"github.com/tolmachov/mcp-telegram/internal/tgclient"
+)
+
+// MessageUnreadHandler handles the MarkAsUnread tool
+type MessageUnreadHandler struct {
+	provider *messages.Provider
+}
+
+// NewMessageUnreadHandler creates a new MessageUnreadHandler.
+// The provider's rate limiter throttles marking each chat.
+func NewMessageUnreadHandler(provider *messages.Provider) *MessageUnreadHandler {
+	return &MessageUnreadHandler{provider: provider}
+}
+
+// Tool returns the MCP tool definition
+func (h *MessageUnreadHandler) Tool() mcp.Tool {
+	opts := []mcp.ToolOption{
+		mcp.WithDescription("Mark one or more chats as unread, e.g. to flag them for a human to follow up. This sets the chat's unread mark like the Telegram apps do; read messages stay read. Returns the result per chat."),
+		mcp.WithIdempotentHintAnnotation(true),
+	}
+	opts = append(opts, batchChatOptions("mark as unread")...)
+	return mcp.NewTool("MarkAsUnread", opts...)
+}
+
+// Handle processes the MarkAsUnread tool request
+func (h *MessageUnreadHandler) Handle(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	chatIDs, err := batchChatIDs(request)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	results := runChatBatch(ctx, h.provider, chatIDs, func(ctx context.Context, client *tg.Client, chatID int64) error {
+		return markDialogUnread(ctx, client, h.provider.Peers(), chatID)
+	})
+	return formatChatBatch(results, "Marked %d out of %d chats as unread successfully!"), nil
+}
+
+// markDialogUnread sets the unread mark of a chat.
+func markDialogUnread(ctx context.Context, client *tg.Client, peers *tgclient.PeerCache, chatID int64) error {
+	return peers.WithPeer(ctx, client, chatID, func(peer tg.InputPeerClass) error {
+		_, err := client.MessagesMarkDialogUnread(ctx, &tg.MessagesMarkDialogUnreadRequest{
+			Unread: true,
+			Peer:   &tg.InputDialogPeer{Peer: peer},
+		})
+		return err
+	})
+}
@@ -4,76 +4,173 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"slices"
 
+	"github.com/gotd/td/tg"
 	"github.com/mark3labs/mcp-go/mcp"
 
 	"github.com/tolmachov/mcp-telegram/internal/messages"
+	"github.com/tolmachov/mcp-telegram/internal/tgclient"
 )
 
+// fetchParamSchemas declares the tool schema for every parameter accepted by
+// messages.ParseFetchParams. Keep in sync with the messages package; tests verify coverage.
+var fetchParamSchemas = map[string]mcp.ToolOption{
+	"limit": mcp.WithNumber("limit",
+		mcp.Description(fmt.Sprintf("Maximum number of messages to return (default 50, max %d)", messages.MaxFetchLimit)),
+	),
+	"offset_id": mcp.WithNumber("offset_id",
+		mcp.Description("Message ID to start from for pagination"),
+	),
+	"min_id": mcp.WithNumber("min_id",
+		mcp.Description("Only return messages with a greater ID, e.g. the newest ID of a previous sync"),
+	),
+	"max_id": mcp.WithNumber("max_id",
+		mcp.Description("Only return messages with a smaller ID"),
+	),
+	"unread_only": mcp.WithBoolean("unread_only",
+		mcp.Description("Only return unread messages"),
+	),
+	"resolve_replies": mcp.WithBoolean("resolve_replies",
+		mcp.Description(fmt.Sprintf("Add the sender and the first %d characters of each replied-to message as reply_to_sender and reply_to_text, fetching up to %d replied-to messages outside the page in one extra request (default: false)", messages.ReplySnippetLength, messages.MaxResolvedReplies)),
+	),
+	"topic_id": topicIDOption("Return"),
+	"since": mcp.WithString("since",
+		mcp.Description("'last_read' returns only messages after your read position in the chat, newest first, and reports how many unread messages were covered. The read state is left untouched unless mark_read is set"),
+		mcp.Enum(messages.SinceLastRead),
+	),
+	"edited_since": mcp.WithString("edited_since",
+		mcp.Description("Only return messages edited after this time (format: YYYY-MM-DD or YYYY-MM-DD HH:MM:SS). Telegram has no server-side filter for edits, so only the requested window (limit/offset_id) is scanned; page with next_id to look further back"),
+	),
+}
+
+// fetchParamOptions returns tool options for all shared message fetch parameters.
+func fetchParamOptions() []mcp.ToolOption {
+	names := messages.FetchParamNames()
+	opts := make([]mcp.ToolOption, 0, len(names))
+	for _, name := range names {
+		if opt, ok := fetchParamSchemas[name]; ok {
+			opts = append(opts, opt)
+		}
+	}
+	return opts
+}
+
 // MessagesGetHandler handles the GetMessages tool
 type MessagesGetHandler struct {
+	client   *tg.Client
 	provider *messages.Provider
 }
 
-// NewMessagesGetHandler creates a new MessagesGetHandler
-func NewMessagesGetHandler(provider *messages.Provider) *MessagesGetHandler {
+// NewMessagesGetHandler creates a new MessagesGetHandler.
+// The client marks chats read when mark_read is set.
+func NewMessagesGetHandler(client *tg.Client, provider *messages.Provider) *MessagesGetHandler {
 	return &MessagesGetHandler{
+		client:   client,
 		provider: provider,
 	}
 }
 
+// markReadResult is a GetMessages result with mark_read set.
+type markReadResult struct {
+	*messages.FetchResult
+	MarkedRead bool   `json:"marked_read,omitempty"`
+	Note       string `json:"note,omitempty"`
+}
+
 // Tool returns the MCP tool definition
 func (h *MessagesGetHandler) Tool() mcp.Tool {
-	return mcp.NewTool("GetMessages",
-		mcp.WithDescription("Get messages from a specific chat."),
+	opts := []mcp.ToolOption{
+		mcp.WithDescription("Get messages from a specific chat. Media carries its type, file name, MIME type, size, duration and dimensions where known; pass its resource_uri to GetMedia to download it. The photos and files of an album come as one message with an album array and album_ids."),
 		mcp.WithReadOnlyHintAnnotation(true),
 		mcp.WithNumber("chat_id",
-			mcp.Description("The chat ID to get messages from"),
-			mcp.Required(),
-		),
-		mcp.WithNumber("limit",
-			mcp.Description("Maximum number of messages to return (default 50, max 100)"),
-		),
-		mcp.WithNumber("offset_id",
-			mcp.Description("Message ID to start from for pagination"),
+			mcp.Description("The chat ID to get messages from (or use chat)"),
 		),
-		mcp.WithBoolean("unread_only",
-			mcp.Description("Only return unread messages"),
+		chatRefOption("chat", "chat_id", "The chat to get messages from"),
+		savedMessagesOption(),
+		mcp.WithBoolean("mark_read",
+			mcp.Description("With since='last_read', mark the chat read up to the newest returned message afterwards, if every unread message was returned. Can't be combined with edited_since (default: false)"),
 		),
-	)
+	}
+	opts = append(opts, fetchParamOptions()...)
+	return mcp.NewTool("GetMessages", opts...)
 }
 
 // Handle processes the GetMessages tool request
 func (h *MessagesGetHandler) Handle(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	chatID := mcp.ParseInt64(request, "chat_id", 0)
-	if chatID == 0 {
-		return mcp.NewToolResultError("chat_id is required"), nil
+	chatID, errResult := chatIDParam(ctx, h.client, h.provider.Peers(), request, "chat_id", "chat")
+	if errResult != nil {
+		return errResult, nil
 	}
 
-	opts := messages.DefaultFetchOptions()
-
-	if limit := int(mcp.ParseInt64(request, "limit", 0)); limit > 0 {
-		opts.Limit = limit
-		if opts.Limit > 100 {
-			opts.Limit = 100
-		}
+	opts, err := messages.ParseFetchParams(request.GetArguments())
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Invalid parameters: %v", err)), nil
 	}
+	opts.GroupAlbums = true
 
-	if offsetID := int(mcp.ParseInt64(request, "offset_id", 0)); offsetID > 0 {
-		opts.OffsetID = offsetID
+	markRead := mcp.ParseBoolean(request, "mark_read", false)
+	if markRead && !opts.SinceLastRead {
+		return mcp.NewToolResultError("mark_read requires since='last_read'"), nil
+	}
+	// Marking read up to the newest edited message would mark the unedited ones
+	// filtered out read unseen
+	if markRead && !opts.EditedSince.IsZero() {
+		return mcp.NewToolResultError("mark_read can't be combined with edited_since"), nil
 	}
 
-	opts.UnreadOnly = mcp.ParseBoolean(request, "unread_only", false)
+	// The topic is looked up, so a wrong topic_id fails rather than returning nothing
+	topic, errResult := topicParam(ctx, h.client, h.provider.Peers(), request, chatID)
+	if errResult != nil {
+		return errResult, nil
+	}
+	opts.ThreadID = topic.ID
 
 	result, err := h.provider.Fetch(ctx, chatID, opts)
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Failed to get messages: %v", err)), nil
+		return toolError("get messages", err), nil
 	}
 
-	data, err := json.MarshalIndent(result, "", "  ")
+	var out any = result
+	if markRead {
+		marked := markReadResult{FetchResult: result}
+		marked.MarkedRead, marked.Note = h.markCoveredRead(ctx, chatID, result, opts)
+		out = marked
+	}
+
+	data, err := json.MarshalIndent(out, "", "  ")
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal messages: %v", err)), nil
+		return toolError("marshal messages", err), nil
 	}
 
 	return mcp.NewToolResultText(string(data)), nil
 }
+
+// fetchedCount returns how many Telegram messages msgs stand for, counting each item
+// of a grouped album.
+func fetchedCount(msgs []messages.Message) int {
+	n := 0
+	for _, msg := range msgs {
+		n += len(msg.IDs())
+	}
+	return n
+}
+
+// markCoveredRead marks the chat read up to the newest returned message. Messages come
+// newest first, so a full page may have left older unread messages out, and a page
+// after offset_id leaves newer ones out; the chat is then left unread rather than
+// marking messages read unseen.
+func (h *MessagesGetHandler) markCoveredRead(ctx context.Context, chatID int64, result *messages.FetchResult, opts messages.FetchOptions) (bool, string) {
+	switch {
+	case len(result.Messages) == 0:
+		return false, "nothing to mark read"
+	case opts.OffsetID != 0:
+		return false, "not marked read: mark_read only applies to the first page (without offset_id)"
+	case fetchedCount(result.Messages) >= opts.Limit:
+		return false, "not marked read: there may be more unread messages than were returned; raise limit"
+	}
+	if err := markChatRead(ctx, h.client, h.provider.Peers(), chatID, slices.Max(result.Messages[0].IDs())); err != nil {
+		return false, fmt.Sprintf("marking read failed: %v", tgclient.ClassifyError(err))
+	}
+	return true, ""
+}
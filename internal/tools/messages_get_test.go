@@ -0,0 +1,58 @@
+package tools
+
+import (
+	"context"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/tolmachov/mcp-telegram/internal/messages"
+)
+
+func TestMessagesGetToolDeclaresAllFetchParams(t *testing.T) {
+	tool := NewMessagesGetHandler(nil, nil).Tool()
+	for _, name := range messages.FetchParamNames() {
+		if _, ok := tool.InputSchema.Properties[name]; !ok {
+			t.Errorf("GetMessages tool does not declare fetch parameter %q", name)
+		}
+	}
+}
+
+func TestMessagesGetEditedSinceValidation(t *testing.T) {
+	request := mcp.CallToolRequest{}
+	request.Params.Arguments = map[string]any{"chat_id": float64(1), "edited_since": "yesterday"}
+
+	result, err := NewMessagesGetHandler(nil, messages.NewProvider(nil, nil, nil)).Handle(context.Background(), request)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !result.IsError {
+		t.Fatal("expected an error result for an invalid edited_since")
+	}
+}
+
+func TestMessagesGetSinceValidation(t *testing.T) {
+	tests := []struct {
+		name string
+		args map[string]any
+	}{
+		{"unknown since", map[string]any{"chat_id": float64(1), "since": "yesterday"}},
+		{"mark_read without since", map[string]any{"chat_id": float64(1), "mark_read": true}},
+		{"mark_read with edited_since", map[string]any{"chat_id": float64(1), "since": "last_read", "mark_read": true, "edited_since": "2024-01-01"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := mcp.CallToolRequest{}
+			request.Params.Arguments = tt.args
+
+			result, err := NewMessagesGetHandler(nil, messages.NewProvider(nil, nil, nil)).Handle(context.Background(), request)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !result.IsError {
+				t.Fatal("expected an error result")
+			}
+		})
+	}
+}
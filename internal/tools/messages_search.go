@@ -0,0 +1,85 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/tolmachov/mcp-telegram/internal/messages"
+)
+
+// MessagesSearchHandler handles the SearchMessages tool
+type MessagesSearchHandler struct {
+	provider *messages.Provider
+}
+
+// NewMessagesSearchHandler creates a new MessagesSearchHandler
+func NewMessagesSearchHandler(provider *messages.Provider) *MessagesSearchHandler {
+	return &MessagesSearchHandler{provider: provider}
+}
+
+// Tool returns the MCP tool definition
+func (h *MessagesSearchHandler) Tool() mcp.Tool {
+	return mcp.NewTool("SearchMessages",
+		mcp.WithDescription("Search for messages containing a text within a chat, newest first, using Telegram's server-side search. Cheaper than reading the history with GetMessages to find something."),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithNumber("chat_id",
+			mcp.Description("The chat ID to search in"),
+			mcp.Required(),
+		),
+		mcp.WithString("query",
+			mcp.Description("The text to search for"),
+			mcp.Required(),
+		),
+		mcp.WithNumber("limit",
+			mcp.Description(fmt.Sprintf("Maximum number of messages to return (default 50, max %d)", messages.MaxSearchLimit)),
+		),
+		mcp.WithString("from_date",
+			mcp.Description("Only messages sent from this date, YYYY-MM-DD or YYYY-MM-DD HH:MM:SS"),
+		),
+		mcp.WithString("to_date",
+			mcp.Description("Only messages sent up to this date, YYYY-MM-DD (inclusive) or YYYY-MM-DD HH:MM:SS"),
+		),
+		mcp.WithNumber("offset_id",
+			mcp.Description("Continue from next_id of a previous search"),
+		),
+	)
+}
+
+// Handle processes the SearchMessages tool request
+func (h *MessagesSearchHandler) Handle(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	chatID := mcp.ParseInt64(request, "chat_id", 0)
+	if chatID == 0 {
+		return mcp.NewToolResultError("chat_id is required"), nil
+	}
+	query := mcp.ParseString(request, "query", "")
+	if query == "" {
+		return mcp.NewToolResultError("query is required"), nil
+	}
+	limit := mcp.ParseInt(request, "limit", 50)
+	if limit <= 0 || limit > messages.MaxSearchLimit {
+		return mcp.NewToolResultError(fmt.Sprintf("limit must be between 1 and %d", messages.MaxSearchLimit)), nil
+	}
+
+	fromDate, _, err := parseDate(mcp.ParseString(request, "from_date", ""))
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("invalid from_date: %v", err)), nil
+	}
+	toDate, toHasTime, err := parseDate(mcp.ParseString(request, "to_date", ""))
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("invalid to_date: %v", err)), nil
+	}
+
+	result, err := h.provider.Search(ctx, chatID, query, messages.FetchOptions{
+		Limit:        limit,
+		OffsetID:     mcp.ParseInt(request, "offset_id", 0),
+		MinDate:      fromDate,
+		MaxDate:      toDate,
+		MaxDateExact: toHasTime,
+	})
+	if err != nil {
+		return toolError("search messages", err), nil
+	}
+	return jsonResult(result)
+}
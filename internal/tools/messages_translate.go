@@ -0,0 +1,233 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/gotd/td/tg"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/tolmachov/mcp-telegram/internal/messages"
+	"github.com/tolmachov/mcp-telegram/internal/summarize"
+	"github.com/tolmachov/mcp-telegram/internal/tgclient"
+)
+
+// TranslateMessages methods.
+const (
+	translateAuto     = "auto"
+	translateTelegram = "telegram"
+	translateAI       = "ai"
+)
+
+const (
+	defaultTranslateCount = 20
+	maxTranslateCount     = 200
+	// nativeTranslateBatch is how many messages one Telegram translation request takes.
+	nativeTranslateBatch = 20
+)
+
+// languageCodeRe matches the two-letter ISO 639-1 codes Telegram translates to.
+var languageCodeRe = regexp.MustCompile(`^[a-z]{2}$`)
+
+// translatedMessage is a message with its translation.
+type translatedMessage struct {
+	ID          int       `json:"id"`
+	Date        time.Time `json:"date"`
+	Sender      string    `json:"sender,omitempty"`
+	Text        string    `json:"text"`
+	Translation string    `json:"translation,omitempty"` // Empty when the model left the message out
+}
+
+// translateMessagesResult is the TranslateMessages output.
+type translateMessagesResult struct {
+	ChatID         int64  `json:"chat_id"`
+	TargetLanguage string `json:"target_language"`
+	Method         string `json:"method"` // telegram or ai
+	// Fallback is why Telegram's translation wasn't used in auto mode
+	Fallback     string              `json:"fallback,omitempty"`
+	Messages     []translatedMessage `json:"messages"` // Oldest first
+	Untranslated int                 `json:"untranslated,omitempty"`
+}
+
+// MessagesTranslateHandler handles the TranslateMessages tool
+type MessagesTranslateHandler struct {
+	client      *tg.Client
+	msgProvider *messages.Provider
+	mcpServer   *server.MCPServer
+	config      summarize.Config
+}
+
+// NewMessagesTranslateHandler creates a new MessagesTranslateHandler
+func NewMessagesTranslateHandler(client *tg.Client, msgProvider *messages.Provider, mcpServer *server.MCPServer, config summarize.Config) *MessagesTranslateHandler {
+	return &MessagesTranslateHandler{
+		client:      client,
+		msgProvider: msgProvider,
+		mcpServer:   mcpServer,
+		config:      config,
+	}
+}
+
+// Tool returns the MCP tool definition
+func (h *MessagesTranslateHandler) Tool() mcp.Tool {
+	return mcp.NewTool("TranslateMessages",
+		mcp.WithDescription("Translate the latest messages of a chat, or those in an ID range, pairing each original with its translation along with its sender and date. Uses Telegram's own translation when available and falls back to the configured AI provider."),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithOpenWorldHintAnnotation(true),
+		mcp.WithNumber("chat_id",
+			mcp.Description("The chat ID to translate messages of (or use chat)"),
+		),
+		chatRefOption("chat", "chat_id", "The chat to translate messages of"),
+		mcp.WithString("target_language",
+			mcp.Description("Language to translate into: a two-letter ISO 639-1 code such as 'en', or a language name, which only the AI provider takes"),
+			mcp.Required(),
+		),
+		mcp.WithNumber("count",
+			mcp.Description(fmt.Sprintf("Maximum messages to translate, newest first within the range (default: %d, max: %d)", defaultTranslateCount, maxTranslateCount)),
+		),
+		mcp.WithNumber("min_id",
+			mcp.Description("Only translate messages with a greater ID"),
+		),
+		mcp.WithNumber("max_id",
+			mcp.Description("Only translate messages with a smaller ID"),
+		),
+		mcp.WithString("method",
+			mcp.Description("'auto' (default) tries Telegram's translation and falls back to AI, 'telegram' or 'ai' use only that one"),
+			mcp.Enum(translateAuto, translateTelegram, translateAI),
+		),
+	)
+}
+
+// Handle processes the TranslateMessages tool request
+func (h *MessagesTranslateHandler) Handle(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	chatID, errResult := chatIDParam(ctx, h.client, h.msgProvider.Peers(), request, "chat_id", "chat")
+	if errResult != nil {
+		return errResult, nil
+	}
+	lang := strings.TrimSpace(mcp.ParseString(request, "target_language", ""))
+	if lang == "" {
+		return mcp.NewToolResultError("target_language is required"), nil
+	}
+	count := mcp.ParseInt(request, "count", defaultTranslateCount)
+	if count <= 0 || count > maxTranslateCount {
+		return mcp.NewToolResultError(fmt.Sprintf("count must be between 1 and %d", maxTranslateCount)), nil
+	}
+	method := mcp.ParseString(request, "method", translateAuto)
+	code := strings.ToLower(lang)
+	switch method {
+	case translateAuto, translateAI:
+	case translateTelegram:
+		if !languageCodeRe.MatchString(code) {
+			return mcp.NewToolResultError("method 'telegram' needs target_language as a two-letter ISO 639-1 code, e.g. 'en'"), nil
+		}
+	default:
+		return mcp.NewToolResultError(fmt.Sprintf("invalid method: %s (use '%s', '%s' or '%s')", method, translateAuto, translateTelegram, translateAI)), nil
+	}
+
+	fetched, err := h.msgProvider.FetchAll(ctx, chatID, messages.FetchOptions{
+		MinID:    mcp.ParseInt(request, "min_id", 0),
+		MaxID:    mcp.ParseInt(request, "max_id", 0),
+		MaxCount: count,
+	}, nil)
+	if err != nil {
+		return toolError("fetch messages", err), nil
+	}
+	msgs := messages.FilterTextOnly(fetched.Messages)
+	messages.Reverse(msgs)
+
+	result := translateMessagesResult{ChatID: chatID, TargetLanguage: lang}
+	var translations map[int]string
+	if method != translateAI && languageCodeRe.MatchString(code) {
+		translations, err = h.translateNative(ctx, chatID, msgs, code)
+		switch {
+		case err == nil:
+			result.Method = translateTelegram
+		case method == translateTelegram || ctx.Err() != nil:
+			return toolError("translate messages", err), nil
+		default:
+			result.Fallback = tgclient.ClassifyError(err).Error()
+		}
+	} else if method == translateAuto {
+		result.Fallback = "target_language isn't a two-letter language code"
+	}
+
+	if result.Method == "" {
+		result.Method = translateAI
+		onProgress := func(current, total int, message string) {
+			if srv := server.ServerFromContext(ctx); srv != nil {
+				_ = srv.SendNotificationToClient(ctx, "notifications/progress", map[string]any{
+					"progress": current,
+					"total":    total,
+					"message":  message,
+				})
+			}
+		}
+		summarizer := summarize.NewSummarizer(newSummarizeProvider(h.mcpServer, h.config), h.msgProvider, h.config.BatchTokens)
+		translations, err = summarizer.TranslateMessages(ctx, msgs, lang, onProgress)
+		if err != nil {
+			return toolError("translate messages", err), nil
+		}
+	}
+
+	result.Messages = pairTranslations(msgs, translations)
+	for _, m := range result.Messages {
+		if m.Translation == "" {
+			result.Untranslated++
+		}
+	}
+	return jsonResult(result)
+}
+
+// translateNative translates msgs with Telegram's own translation, in batches of
+// nativeTranslateBatch messages.
+func (h *MessagesTranslateHandler) translateNative(ctx context.Context, chatID int64, msgs []messages.Message, code string) (map[int]string, error) {
+	client := h.msgProvider.Paced()
+	translations := make(map[int]string, len(msgs))
+	err := h.msgProvider.Peers().WithPeer(ctx, client, chatID, func(peer tg.InputPeerClass) error {
+		for start := 0; start < len(msgs); start += nativeTranslateBatch {
+			batch := msgs[start:min(start+nativeTranslateBatch, len(msgs))]
+			ids := make([]int, len(batch))
+			for i, msg := range batch {
+				ids[i] = msg.ID
+			}
+
+			req := &tg.MessagesTranslateTextRequest{ToLang: code}
+			req.SetPeer(peer)
+			req.SetID(ids)
+			res, err := client.MessagesTranslateText(ctx, req)
+			if err != nil {
+				return err
+			}
+			if len(res.Result) != len(ids) {
+				return errors.New("translation result doesn't match the requested messages")
+			}
+			for i, text := range res.Result {
+				translations[ids[i]] = text.Text
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return translations, nil
+}
+
+// pairTranslations pairs each message with its translation.
+func pairTranslations(msgs []messages.Message, translations map[int]string) []translatedMessage {
+	paired := make([]translatedMessage, len(msgs))
+	for i, msg := range msgs {
+		paired[i] = translatedMessage{
+			ID:          msg.ID,
+			Date:        msg.Date,
+			Sender:      msg.SenderName,
+			Text:        msg.Text,
+			Translation: translations[msg.ID],
+		}
+	}
+	return paired
+}
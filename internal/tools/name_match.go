@@ -0,0 +1,123 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/lithammer/fuzzysearch/fuzzy"
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/tolmachov/mcp-telegram/internal/recent"
+)
+
+// expectedNameMaxDistance is the normalized Levenshtein distance (0..1) up to which
+// an expected chat name still counts as the chat's actual name.
+const expectedNameMaxDistance = 0.3
+
+// cyrillicToLatin transliterates Russian, Ukrainian and Belarusian letters so that
+// "Алексей" and "Aleksey" compare as close names.
+var cyrillicToLatin = map[rune]string{
+	'а': "a", 'б': "b", 'в': "v", 'г': "g", 'д': "d", 'е': "e", 'ё': "e", 'ж': "zh",
+	'з': "z", 'и': "i", 'й': "y", 'к': "k", 'л': "l", 'м': "m", 'н': "n", 'о': "o",
+	'п': "p", 'р': "r", 'с': "s", 'т': "t", 'у': "u", 'ф': "f", 'х': "kh", 'ц': "ts",
+	'ч': "ch", 'ш': "sh", 'щ': "shch", 'ъ': "", 'ы': "y", 'ь': "", 'э': "e", 'ю': "yu",
+	'я': "ya", 'є': "ye", 'і': "i", 'ї': "yi", 'ґ': "g", 'ў': "u",
+}
+
+// normalizeName lowercases and transliterates a name to Latin, dropping everything
+// but letters and digits (emoji, punctuation) and collapsing whitespace.
+func normalizeName(name string) string {
+	var sb strings.Builder
+	space := false
+	for _, r := range strings.ToLower(name) {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			if space && sb.Len() > 0 {
+				sb.WriteByte(' ')
+			}
+			space = false
+			if latin, ok := cyrillicToLatin[r]; ok {
+				sb.WriteString(latin)
+			} else {
+				sb.WriteRune(r)
+			}
+		default:
+			space = true
+		}
+	}
+	return sb.String()
+}
+
+// nameDistance returns the Levenshtein distance between two normalized names.
+func nameDistance(a, b string) int {
+	return fuzzy.LevenshteinDistance(normalizeName(a), normalizeName(b))
+}
+
+// normalizedDistance returns the Levenshtein distance between already normalized
+// strings divided by the length of the longer one.
+func normalizedDistance(a, b string) float64 {
+	longest := max(utf8.RuneCountInString(a), utf8.RuneCountInString(b))
+	if longest == 0 {
+		return 0
+	}
+	return float64(fuzzy.LevenshteinDistance(a, b)) / float64(longest)
+}
+
+// namesMatch reports whether expected plausibly names the same chat as actual: the
+// whole names are close, or every word of expected is close to some word of actual
+// (so "Anna" matches "Anna Petrova" and word order doesn't matter).
+func namesMatch(expected, actual string) bool {
+	exp, act := normalizeName(expected), normalizeName(actual)
+	if exp == "" || act == "" {
+		return strings.EqualFold(strings.TrimSpace(expected), strings.TrimSpace(actual))
+	}
+	if normalizedDistance(exp, act) <= expectedNameMaxDistance {
+		return true
+	}
+
+	actWords := strings.Fields(act)
+	for _, word := range strings.Fields(exp) {
+		found := false
+		for _, candidate := range actWords {
+			if normalizedDistance(word, candidate) <= expectedNameMaxDistance {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// checkExpectedChatName verifies the optional expected_chat_name argument against the
+// name of chatID. It returns an error result when the names don't match or the name
+// can't be resolved, and nil when sending may proceed.
+func checkExpectedChatName(ctx context.Context, request mcp.CallToolRequest, chatName recent.NameLookup, chatID int64) *mcp.CallToolResult {
+	expected := mcp.ParseString(request, "expected_chat_name", "")
+	if expected == "" {
+		return nil
+	}
+
+	actual, err := chatName(ctx, chatID)
+	if err != nil {
+		return toolError("resolve chat name", err)
+	}
+	if !namesMatch(expected, actual) {
+		return mcp.NewToolResultError(fmt.Sprintf(
+			"Chat name mismatch: expected %q but chat %d is %q. Nothing was sent; check the chat ID (e.g. with SearchChats).",
+			expected, chatID, actual))
+	}
+	return nil
+}
+
+// expectedChatNameOption is the expected_chat_name parameter shared by send-family tools.
+func expectedChatNameOption(target string) mcp.ToolOption {
+	return mcp.WithString("expected_chat_name",
+		mcp.Description(fmt.Sprintf("Display name you expect %s to have (optional). If given, nothing is sent unless it fuzzy-matches the chat's actual name, guarding against a wrong chat ID", target)),
+	)
+}
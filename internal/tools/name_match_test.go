@@ -0,0 +1,110 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestNormalizeName(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"Anna Petrova", "anna petrova"},
+		{"  Anna   🌸 Petrova! ", "anna petrova"},
+		{"Алексей", "aleksey"},
+		{"Щукин", "shchukin"},
+		{"Їжак", "yizhak"},
+		{"Work-Chat #2", "work chat 2"},
+		{"🔥🔥", ""},
+	}
+	for _, tt := range tests {
+		if got := normalizeName(tt.in); got != tt.want {
+			t.Errorf("normalizeName(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestNamesMatch(t *testing.T) {
+	tests := []struct {
+		expected string
+		actual   string
+		want     bool
+	}{
+		{"Anna Petrova", "Anna Petrova", true},
+		{"anna petrova", "ANNA PETROVA", true},
+		{"Anna Petrova", "Anna Petrova 🌸", true},
+		{"Ana Petrova", "Anna Petrova", true},
+		{"Anna", "Anna Petrova", true},
+		{"Petrova Anna", "Anna Petrova", true},
+		// Transliteration
+		{"Aleksey", "Алексей", true},
+		{"Alexey", "Алексей", true},
+		{"Yulia", "Юлия", true},
+		{"Schukin", "Щукин", true},
+		{"Zhanna", "Жанна", true},
+		{"Habib", "Хабиб", true},
+		{"Ирина", "Irina Smirnova", true},
+		// Wrong chats
+		{"Anna Petrova", "Boris Ivanov", false},
+		{"Anna Petrova", "Anna Sidorova", false},
+		{"Mom", "Maxim", false},
+		{"Work chat", "Family", false},
+		{"Алексей", "Александра", false},
+		// Names without letters
+		{"🔥🔥", "🔥🔥", true},
+		{"🔥🔥", "Anna", false},
+	}
+	for _, tt := range tests {
+		if got := namesMatch(tt.expected, tt.actual); got != tt.want {
+			t.Errorf("namesMatch(%q, %q) = %v, want %v", tt.expected, tt.actual, got, tt.want)
+		}
+	}
+}
+
+func TestNameDistance(t *testing.T) {
+	if d := nameDistance("Alexey!", "Алексей"); d != 2 {
+		t.Errorf("nameDistance = %d, want 2", d)
+	}
+	if d := nameDistance("WORK", "work"); d != 0 {
+		t.Errorf("nameDistance = %d, want 0", d)
+	}
+}
+
+func TestCheckExpectedChatName(t *testing.T) {
+	lookup := func(_ context.Context, chatID int64) (string, error) {
+		if chatID == 1 {
+			return "Anna Petrova", nil
+		}
+		return "", errors.New("chat not found")
+	}
+	request := func(expected string) mcp.CallToolRequest {
+		var req mcp.CallToolRequest
+		req.Params.Arguments = map[string]any{"expected_chat_name": expected}
+		return req
+	}
+	ctx := context.Background()
+
+	if res := checkExpectedChatName(ctx, request(""), nil, 1); res != nil {
+		t.Errorf("no expected name: got %v, want nil (lookup must not be called)", res)
+	}
+	if res := checkExpectedChatName(ctx, request("anna"), lookup, 1); res != nil {
+		t.Errorf("matching name: got error %v", resultText(res))
+	}
+
+	res := checkExpectedChatName(ctx, request("Boris"), lookup, 1)
+	if res == nil || !res.IsError {
+		t.Fatal("mismatch: expected error result")
+	}
+	if text := resultText(res); !strings.Contains(text, `"Boris"`) || !strings.Contains(text, `"Anna Petrova"`) {
+		t.Errorf("mismatch error %q should show both names", text)
+	}
+
+	if res := checkExpectedChatName(ctx, request("Anna"), lookup, 2); res == nil || !res.IsError {
+		t.Error("lookup failure: expected error result")
+	}
+}
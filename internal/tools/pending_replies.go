@@ -0,0 +1,209 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/gotd/td/tg"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/tolmachov/mcp-telegram/internal/messages"
+	"github.com/tolmachov/mcp-telegram/internal/tgclient"
+	"github.com/tolmachov/mcp-telegram/internal/tgdata"
+	"github.com/tolmachov/mcp-telegram/internal/triage"
+)
+
+const (
+	defaultPendingTopChats = 20
+	maxPendingChats        = 100
+	defaultPendingPerChat  = 100
+	maxPendingPerChat      = 500
+	defaultPendingResults  = 50
+	// pendingSnippetRunes limits the message text shown for a flagged message.
+	pendingSnippetRunes = 150
+)
+
+// PendingReply is a message flagged by FindPendingReplies.
+type PendingReply struct {
+	ChatID    int64          `json:"chat_id"`
+	ChatName  string         `json:"chat_name,omitempty"`
+	MessageID int            `json:"message_id"`
+	Sender    string         `json:"sender,omitempty"`
+	Snippet   string         `json:"snippet"`
+	Date      time.Time      `json:"date"`
+	Age       string         `json:"age"`
+	Reason    triage.Reason  `json:"reason"`
+	Negative  map[string]int `json:"negative_reactions,omitempty"`
+	Positive  int            `json:"positive_reactions,omitempty"`
+}
+
+// pendingRepliesResult is the FindPendingReplies output.
+type pendingRepliesResult struct {
+	ChatsScanned int            `json:"chats_scanned"`
+	Pending      []PendingReply `json:"pending"`
+	Truncated    bool           `json:"truncated,omitempty"`
+	Errors       []chatError    `json:"errors,omitempty"`
+}
+
+// chatError records a chat that could not be scanned.
+type chatError struct {
+	ChatID int64  `json:"chat_id"`
+	Error  string `json:"error"`
+}
+
+// PendingRepliesHandler handles the FindPendingReplies tool
+type PendingRepliesHandler struct {
+	client   *tg.Client
+	provider *messages.Provider
+}
+
+// NewPendingRepliesHandler creates a new PendingRepliesHandler
+func NewPendingRepliesHandler(client *tg.Client, provider *messages.Provider) *PendingRepliesHandler {
+	return &PendingRepliesHandler{client: client, provider: provider}
+}
+
+// Tool returns the MCP tool definition
+func (h *PendingRepliesHandler) Tool() mcp.Tool {
+	return mcp.NewTool("FindPendingReplies",
+		mcp.WithDescription("Find what needs my attention: questions that reply to my messages with no later message from me in that chat, and my messages that got negative reactions (👎, 😡 and similar). Scans the latest messages of the given chats or of the top chats in the chat list (broadcast channels skipped) and returns a prioritized list with chat, snippet, age and the reason each message was flagged. Unanswered questions come first, oldest first."),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithArray("chat_ids",
+			mcp.WithNumberItems(),
+			mcp.Description(fmt.Sprintf("Chats to scan (max %d). Default: the top chats of the chat list", maxPendingChats)),
+		),
+		mcp.WithNumber("top_chats",
+			mcp.Description(fmt.Sprintf("Without chat_ids, how many chats from the top of the chat list to scan (default: %d, max: %d)", defaultPendingTopChats, maxPendingChats)),
+		),
+		mcp.WithNumber("per_chat_limit",
+			mcp.Description(fmt.Sprintf("How many latest messages to scan per chat (default: %d, max: %d)", defaultPendingPerChat, maxPendingPerChat)),
+		),
+		mcp.WithNumber("limit",
+			mcp.Description(fmt.Sprintf("Maximum flagged messages to return (default: %d)", defaultPendingResults)),
+		),
+	)
+}
+
+// Handle processes the FindPendingReplies tool request
+func (h *PendingRepliesHandler) Handle(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	chatIDs := int64Slice(request.GetIntSlice("chat_ids", nil))
+	if len(chatIDs) > maxPendingChats {
+		return mcp.NewToolResultError(fmt.Sprintf("Cannot scan more than %d chats at once", maxPendingChats)), nil
+	}
+	topN := mcp.ParseInt(request, "top_chats", defaultPendingTopChats)
+	if topN <= 0 || topN > maxPendingChats {
+		return mcp.NewToolResultError(fmt.Sprintf("top_chats must be between 1 and %d", maxPendingChats)), nil
+	}
+	perChat := mcp.ParseInt(request, "per_chat_limit", defaultPendingPerChat)
+	if perChat <= 0 || perChat > maxPendingPerChat {
+		return mcp.NewToolResultError(fmt.Sprintf("per_chat_limit must be between 1 and %d", maxPendingPerChat)), nil
+	}
+	limit := mcp.ParseInt(request, "limit", defaultPendingResults)
+	if limit <= 0 {
+		limit = defaultPendingResults
+	}
+
+	chats := make([]tgdata.ChatInfo, len(chatIDs))
+	for i, id := range chatIDs {
+		chats[i] = tgdata.ChatInfo{ID: id}
+	}
+	if len(chats) == 0 {
+		var err error
+		chats, err = tgdata.GetTopChats(ctx, h.client, topN, func(chat tgdata.ChatInfo) bool {
+			return chat.Type == "channel"
+		})
+		if err != nil {
+			return toolError("list chats", err), nil
+		}
+	}
+
+	srv := server.ServerFromContext(ctx)
+	var flags []pendingFlag
+	result := pendingRepliesResult{Pending: []PendingReply{}}
+	for i, chat := range chats {
+		if ctx.Err() != nil {
+			return toolError("scan chats", ctx.Err()), nil
+		}
+		chatFlags, err := h.scanChat(ctx, chat.ID, perChat)
+		if err != nil {
+			result.Errors = append(result.Errors, chatError{ChatID: chat.ID, Error: tgclient.ClassifyError(err).Error()})
+		} else {
+			result.ChatsScanned++
+			for _, f := range chatFlags {
+				flags = append(flags, pendingFlag{Flag: f, chat: chat})
+			}
+		}
+		if srv != nil {
+			_ = srv.SendNotificationToClient(ctx, "notifications/progress", map[string]any{
+				"progress": i + 1,
+				"total":    len(chats),
+				"message":  fmt.Sprintf("Scanned %d of %d chats...", i+1, len(chats)),
+			})
+		}
+	}
+
+	slices.SortStableFunc(flags, func(a, b pendingFlag) int { return triage.Compare(a.Flag, b.Flag) })
+	if len(flags) > limit {
+		flags = flags[:limit]
+		result.Truncated = true
+	}
+	now := time.Now()
+	for _, f := range flags {
+		result.Pending = append(result.Pending, PendingReply{
+			ChatID:    f.chat.ID,
+			ChatName:  f.chat.Name,
+			MessageID: f.Message.ID,
+			Sender:    f.Message.SenderName,
+			Snippet:   messages.TruncateRunes(f.Message.Text, pendingSnippetRunes),
+			Date:      f.Message.Date,
+			Age:       formatAge(now.Sub(f.Message.Date)),
+			Reason:    f.Reason,
+			Negative:  f.Negative,
+			Positive:  f.Positive,
+		})
+	}
+
+	return jsonResult(result)
+}
+
+// pendingFlag is a triage flag with the chat it was found in.
+type pendingFlag struct {
+	triage.Flag
+	chat tgdata.ChatInfo
+}
+
+// scanChat fetches the latest messages of a chat and flags those needing attention.
+// Messages that questions reply to but that fall outside the window are fetched as
+// context, to tell whether the questions reply to me.
+func (h *PendingRepliesHandler) scanChat(ctx context.Context, chatID int64, perChat int) ([]triage.Flag, error) {
+	fetched, err := h.provider.FetchAll(ctx, chatID, messages.FetchOptions{MaxCount: perChat}, nil)
+	if err != nil {
+		return nil, err
+	}
+	msgs := fetched.Messages
+	if missing := triage.MissingReplyTargets(msgs); len(missing) > 0 {
+		targets, err := h.provider.FetchByIDs(ctx, chatID, missing)
+		if err != nil {
+			return nil, err
+		}
+		for _, msg := range targets.Messages {
+			msg.Context = true
+			msgs = append(msgs, msg)
+		}
+	}
+	return triage.Scan(msgs), nil
+}
+
+// formatAge renders how long ago a message was sent, e.g. "45m", "5h" or "3d".
+func formatAge(d time.Duration) string {
+	switch {
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%dh", int(d.Hours()))
+	default:
+		return fmt.Sprintf("%dd", int(d.Hours()/24))
+	}
+}
@@ -0,0 +1,197 @@
+package tools
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/gotd/td/tg"
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/tolmachov/mcp-telegram/internal/tgclient"
+)
+
+// profilePhoto is the current profile photo of a user or chat, ready to download.
+type profilePhoto struct {
+	id  int64
+	loc tg.InputFileLocationClass
+}
+
+// ProfilePhotoGetHandler handles the GetProfilePhoto tool
+type ProfilePhotoGetHandler struct {
+	client       *tg.Client
+	peers        *tgclient.PeerCache
+	dcs          tgclient.DCConnector
+	allowedPaths []string
+}
+
+// NewProfilePhotoGetHandler creates a new ProfilePhotoGetHandler.
+// dcs connects to other DCs for photos stored there; allowedPaths restricts save_to.
+func NewProfilePhotoGetHandler(client *tg.Client, peers *tgclient.PeerCache, dcs tgclient.DCConnector, allowedPaths []string) *ProfilePhotoGetHandler {
+	return &ProfilePhotoGetHandler{client: client, peers: peers, dcs: dcs, allowedPaths: allowedPaths}
+}
+
+// Tool returns the MCP tool definition
+func (h *ProfilePhotoGetHandler) Tool() mcp.Tool {
+	return mcp.NewTool("GetProfilePhoto",
+		mcp.WithDescription("Get the current profile photo of a user, group or channel. Returns a small version inline as an image, or saves the full-size photo to a file with save_to."),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithNumber("chat_id",
+			mcp.Description("The ID of the user or chat (or use username)"),
+		),
+		mcp.WithString("username",
+			mcp.Description("The username of the user or chat, with or without @, instead of chat_id"),
+		),
+		mcp.WithString("save_to",
+			mcp.Description("Save the full-size photo to this file, or into this directory when it is one. Must be within allowed paths"),
+		),
+	)
+}
+
+// Handle processes the GetProfilePhoto tool request
+func (h *ProfilePhotoGetHandler) Handle(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	chatID := mcp.ParseInt64(request, "chat_id", 0)
+	if chatID == 0 {
+		username := strings.TrimPrefix(mcp.ParseString(request, "username", ""), "@")
+		if username == "" {
+			return mcp.NewToolResultError("chat_id or username is required"), nil
+		}
+		var err error
+		if chatID, _, err = h.peers.ResolvePeerFlexible(ctx, h.client, "@"+username); err != nil {
+			return toolError("resolve username @"+username, err), nil
+		}
+	}
+	saveTo := mcp.ParseString(request, "save_to", "")
+
+	var photo profilePhoto
+	var found bool
+	err := h.peers.WithPeer(ctx, h.client, chatID, func(peer tg.InputPeerClass) error {
+		var err error
+		photo, found, err = h.currentPhoto(ctx, peer, saveTo != "")
+		return err
+	})
+	if err != nil {
+		return toolError("get profile photo", err), nil
+	}
+	if !found {
+		return mcp.NewToolResultText(fmt.Sprintf("Chat %d has no profile photo", chatID)), nil
+	}
+
+	if saveTo != "" {
+		if info, err := os.Stat(saveTo); err == nil && info.IsDir() {
+			saveTo = filepath.Join(saveTo, fmt.Sprintf("profile_%d_%d.jpg", chatID, photo.id))
+		}
+		return saveFile(ctx, h.client, h.dcs, h.allowedPaths, photo.loc, "profile photo", saveTo)
+	}
+
+	buf := &cappedBuffer{limit: maxInlineMediaBytes}
+	if err := downloadFile(ctx, h.client, h.dcs, photo.loc, buf); err != nil {
+		if errors.Is(err, errMediaTooLarge) {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		return toolError("download profile photo", err), nil
+	}
+	data := base64.StdEncoding.EncodeToString(buf.Bytes())
+	return mcp.NewToolResultImage(fmt.Sprintf("Profile photo of chat %d", chatID), data, "image/jpeg"), nil
+}
+
+// currentPhoto finds the peer's current profile photo, in full size when full is set
+// and the small version otherwise. It reports false when there is no photo.
+func (h *ProfilePhotoGetHandler) currentPhoto(ctx context.Context, peer tg.InputPeerClass, full bool) (profilePhoto, bool, error) {
+	var photo tg.ChatPhotoClass
+	switch p := peer.(type) {
+	case *tg.InputPeerUser:
+		photos, err := h.client.PhotosGetUserPhotos(ctx, &tg.PhotosGetUserPhotosRequest{
+			UserID: &tg.InputUser{UserID: p.UserID, AccessHash: p.AccessHash},
+			Limit:  1,
+		})
+		if err != nil {
+			return profilePhoto{}, false, err
+		}
+		return userPhoto(photos.GetPhotos(), full)
+	case *tg.InputPeerChat:
+		chats, err := h.client.MessagesGetChats(ctx, []int64{p.ChatID})
+		if err != nil {
+			return profilePhoto{}, false, err
+		}
+		for _, c := range chats.GetChats() {
+			if chat, ok := c.(*tg.Chat); ok && chat.ID == p.ChatID {
+				photo = chat.Photo
+			}
+		}
+	case *tg.InputPeerChannel:
+		chats, err := h.client.ChannelsGetChannels(ctx, []tg.InputChannelClass{
+			&tg.InputChannel{ChannelID: p.ChannelID, AccessHash: p.AccessHash},
+		})
+		if err != nil {
+			return profilePhoto{}, false, err
+		}
+		for _, c := range chats.GetChats() {
+			if channel, ok := c.(*tg.Channel); ok && channel.ID == p.ChannelID {
+				photo = channel.Photo
+			}
+		}
+	default:
+		return profilePhoto{}, false, fmt.Errorf("unsupported peer type %T", peer)
+	}
+
+	chatPhoto, ok := photo.(*tg.ChatPhoto)
+	if !ok {
+		return profilePhoto{}, false, nil
+	}
+	// Chat photos come in two sizes: 160x160 and, with Big, 640x640
+	return profilePhoto{
+		id:  chatPhoto.PhotoID,
+		loc: &tg.InputPeerPhotoFileLocation{Big: full, Peer: peer, PhotoID: chatPhoto.PhotoID},
+	}, true, nil
+}
+
+// userPhoto picks the largest or smallest size of the first photo in a
+// photos.getUserPhotos result, reporting false when there is none.
+func userPhoto(photos []tg.PhotoClass, largest bool) (profilePhoto, bool, error) {
+	if len(photos) == 0 {
+		return profilePhoto{}, false, nil
+	}
+	photo, ok := photos[0].(*tg.Photo)
+	if !ok {
+		return profilePhoto{}, false, nil
+	}
+	size, ok := pickPhotoSize(photo.Sizes, largest)
+	if !ok {
+		return profilePhoto{}, false, errors.New("profile photo has no downloadable size")
+	}
+	return profilePhoto{
+		id: photo.ID,
+		loc: &tg.InputPhotoFileLocation{
+			ID:            photo.ID,
+			AccessHash:    photo.AccessHash,
+			FileReference: photo.FileReference,
+			ThumbSize:     size,
+		},
+	}, true, nil
+}
+
+// pickPhotoSize returns the type of the largest or smallest downloadable photo size,
+// skipping inline thumbnails and outlines.
+func pickPhotoSize(sizes []tg.PhotoSizeClass, largest bool) (string, bool) {
+	best, bestArea := "", 0
+	for _, s := range sizes {
+		var w, h int
+		switch size := s.(type) {
+		case *tg.PhotoSize:
+			w, h = size.W, size.H
+		case *tg.PhotoSizeProgressive:
+			w, h = size.W, size.H
+		default:
+			continue
+		}
+		if area := w * h; best == "" || (largest && area > bestArea) || (!largest && area < bestArea) {
+			best, bestArea = s.GetType(), area
+		}
+	}
+	return best, best != ""
+}
@@ -0,0 +1,47 @@
+package tools
+
+import (
+	"testing"
+
+	"github.com/gotd/td/tg"
+)
+
+func TestPickPhotoSize(t *testing.T) {
+	sizes := []tg.PhotoSizeClass{
+		&tg.PhotoStrippedSize{Type: "i"},
+		&tg.PhotoSize{Type: "a", W: 160, H: 160},
+		&tg.PhotoSizeProgressive{Type: "c", W: 640, H: 640},
+		&tg.PhotoSize{Type: "b", W: 320, H: 320},
+		&tg.PhotoPathSize{Type: "j"},
+	}
+	if got, ok := pickPhotoSize(sizes, true); !ok || got != "c" {
+		t.Errorf("largest = %q, %v, want c", got, ok)
+	}
+	if got, ok := pickPhotoSize(sizes, false); !ok || got != "a" {
+		t.Errorf("smallest = %q, %v, want a", got, ok)
+	}
+	if _, ok := pickPhotoSize(sizes[:1], true); ok {
+		t.Error("picked an inline thumbnail")
+	}
+}
+
+func TestUserPhoto(t *testing.T) {
+	if _, found, err := userPhoto(nil, true); found || err != nil {
+		t.Errorf("userPhoto(no photos) = %v, %v, want not found", found, err)
+	}
+
+	photos := []tg.PhotoClass{&tg.Photo{
+		ID:            5,
+		AccessHash:    6,
+		FileReference: []byte{1},
+		Sizes:         []tg.PhotoSizeClass{&tg.PhotoSize{Type: "a", W: 160, H: 160}},
+	}}
+	photo, found, err := userPhoto(photos, true)
+	if err != nil || !found {
+		t.Fatalf("userPhoto() = %v, %v", found, err)
+	}
+	loc, ok := photo.loc.(*tg.InputPhotoFileLocation)
+	if !ok || loc.ID != 5 || loc.AccessHash != 6 || loc.ThumbSize != "a" {
+		t.Errorf("location = %#v, want photo 5 size a", photo.loc)
+	}
+}
@@ -0,0 +1,103 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gotd/td/tg"
+	"github.com/gotd/td/tgerr"
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/tolmachov/mcp-telegram/internal/tgclient"
+)
+
+// ReactionSendHandler handles the SendReaction tool
+type ReactionSendHandler struct {
+	client *tg.Client
+	peers  *tgclient.PeerCache
+}
+
+// NewReactionSendHandler creates a new ReactionSendHandler
+func NewReactionSendHandler(client *tg.Client, peers *tgclient.PeerCache) *ReactionSendHandler {
+	return &ReactionSendHandler{client: client, peers: peers}
+}
+
+// Tool returns the MCP tool definition
+func (h *ReactionSendHandler) Tool() mcp.Tool {
+	return mcp.NewTool("SendReaction",
+		mcp.WithDescription("React to a message with an emoji, e.g. 👍 to acknowledge it. Replaces your previous reaction on the message."),
+		mcp.WithOpenWorldHintAnnotation(true),
+		mcp.WithIdempotentHintAnnotation(true),
+		mcp.WithNumber("chat_id",
+			mcp.Description("The ID of the chat containing the message"),
+			mcp.Required(),
+		),
+		mcp.WithNumber("message_id",
+			mcp.Description("The ID of the message to react to"),
+			mcp.Required(),
+		),
+		mcp.WithString("emoji",
+			mcp.Description("The reaction emoji, e.g. 👍, ❤, 🔥; chats may allow only some of them"),
+			mcp.Required(),
+		),
+		mcp.WithBoolean("big",
+			mcp.Description("Play the big reaction animation (default: false)"),
+		),
+	)
+}
+
+// Handle processes the SendReaction tool request
+func (h *ReactionSendHandler) Handle(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	chatID := mcp.ParseInt64(request, "chat_id", 0)
+	if chatID == 0 {
+		return mcp.NewToolResultError("chat_id is required"), nil
+	}
+
+	messageID := mcp.ParseInt(request, "message_id", 0)
+	if messageID == 0 {
+		return mcp.NewToolResultError("message_id is required"), nil
+	}
+
+	emoji := mcp.ParseString(request, "emoji", "")
+	if emoji == "" {
+		return mcp.NewToolResultError("emoji is required"), nil
+	}
+
+	err := h.peers.WithPeer(ctx, h.client, chatID, func(peer tg.InputPeerClass) error {
+		_, err := h.client.MessagesSendReaction(ctx, &tg.MessagesSendReactionRequest{
+			Peer:     peer,
+			MsgID:    messageID,
+			Reaction: []tg.ReactionClass{&tg.ReactionEmoji{Emoticon: emoji}},
+			Big:      mcp.ParseBoolean(request, "big", false),
+		})
+		return err
+	})
+	if err != nil {
+		if msg := reactionErrorMessage(err, emoji); msg != "" {
+			return mcp.NewToolResultError(msg), nil
+		}
+		return toolError("send reaction", err), nil
+	}
+
+	return mcp.NewToolResultText(fmt.Sprintf("Reaction sent!\nChat ID: %d\nMessage ID: %d\nReaction: %s", chatID, messageID, emoji)), nil
+}
+
+// reactionErrorMessage explains the RPC errors of a chat not taking a reaction, or
+// returns "" for other errors.
+func reactionErrorMessage(err error, emoji string) string {
+	rpcErr, ok := tgerr.As(err)
+	if !ok {
+		return ""
+	}
+	switch rpcErr.Type {
+	case "REACTION_INVALID":
+		return fmt.Sprintf("Failed to send reaction: this chat doesn't accept %s; reactions may be disabled in it, or limited to a set of emoji. Check the reactions others used with GetMessageReactions", emoji)
+	case "REACTIONS_TOO_MANY":
+		return "Failed to send reaction: the message already has the most different reactions Telegram allows; use one of the existing ones"
+	case "MSG_ID_INVALID":
+		return "Failed to send reaction: message not found in this chat"
+	case "CHAT_WRITE_FORBIDDEN":
+		return "Failed to send reaction: you can't react in this chat"
+	}
+	return ""
+}
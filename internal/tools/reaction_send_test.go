@@ -0,0 +1,22 @@
+package tools
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/gotd/td/tgerr"
+)
+
+func TestReactionErrorMessage(t *testing.T) {
+	msg := reactionErrorMessage(tgerr.New(400, "REACTION_INVALID"), "🦄")
+	if !strings.Contains(msg, "doesn't accept 🦄") || !strings.Contains(msg, "disabled") {
+		t.Errorf("REACTION_INVALID message = %q, want one explaining the chat doesn't take the reaction", msg)
+	}
+	if msg := reactionErrorMessage(errors.New("network down"), "👍"); msg != "" {
+		t.Errorf("message for a non-RPC error = %q, want none", msg)
+	}
+	if msg := reactionErrorMessage(tgerr.New(400, "PEER_ID_INVALID"), "👍"); msg != "" {
+		t.Errorf("message for an unrelated RPC error = %q, want none", msg)
+	}
+}
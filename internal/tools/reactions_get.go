@@ -0,0 +1,149 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/gotd/td/tg"
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/tolmachov/mcp-telegram/internal/messages"
+	"github.com/tolmachov/mcp-telegram/internal/tgclient"
+	"github.com/tolmachov/mcp-telegram/internal/tgdata"
+)
+
+// reactionSnippetRunes limits the message text shown next to its reactions.
+const reactionSnippetRunes = 100
+
+// UnreadReactionsMessage is one of my messages with unread reactions.
+type UnreadReactionsMessage struct {
+	MessageID int               `json:"message_id"`
+	Snippet   string            `json:"snippet"`
+	Summary   string            `json:"summary"`
+	Reactions []tgdata.Reaction `json:"reactions"`
+}
+
+// UnreadReactionsChat groups messages with unread reactions by chat.
+type UnreadReactionsChat struct {
+	ChatID     int64                    `json:"chat_id"`
+	ChatName   string                   `json:"chat_name,omitempty"`
+	Messages   []UnreadReactionsMessage `json:"messages"`
+	MarkedRead bool                     `json:"marked_read,omitempty"`
+	UnreadLeft int                      `json:"unread_left,omitempty"` // Unread not fetched, so not marked read
+	Error      string                   `json:"error,omitempty"`
+}
+
+// ReactionsGetHandler handles the GetUnreadReactions tool
+type ReactionsGetHandler struct {
+	client *tg.Client
+	peers  *tgclient.PeerCache
+}
+
+// NewReactionsGetHandler creates a new ReactionsGetHandler
+func NewReactionsGetHandler(client *tg.Client, peers *tgclient.PeerCache) *ReactionsGetHandler {
+	return &ReactionsGetHandler{client: client, peers: peers}
+}
+
+// Tool returns the MCP tool definition
+func (h *ReactionsGetHandler) Tool() mcp.Tool {
+	return mcp.NewTool("GetUnreadReactions",
+		mcp.WithDescription("Get reactions to my messages that I haven't seen yet: which messages got which reactions from whom, grouped by chat."),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithNumber("chat_id",
+			mcp.Description("Chat to check (default: all chats with unread reactions)"),
+		),
+		mcp.WithNumber("limit",
+			mcp.Description("Maximum messages per chat (default: 20, max: 100)"),
+		),
+		mcp.WithBoolean("mark_read",
+			mcp.Description("Mark the chat's reactions as read after fetching, only when all of them fit in limit; otherwise unread_left says how many weren't fetched (default: false)"),
+		),
+	)
+}
+
+// Handle processes the GetUnreadReactions tool request
+func (h *ReactionsGetHandler) Handle(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	chatID := mcp.ParseInt64(request, "chat_id", 0)
+	limit := min(max(mcp.ParseInt(request, "limit", 20), 1), 100)
+	markRead := mcp.ParseBoolean(request, "mark_read", false)
+
+	kind := unreadKind[tgdata.ReactedMessage]{
+		name:  "unread reactions",
+		count: func(marks tgdata.UnreadMarks) int { return marks.Reactions },
+		fetch: func(ctx context.Context, peer tg.InputPeerClass, limit int) ([]tgdata.ReactedMessage, error) {
+			return tgdata.GetUnreadReactions(ctx, h.client, peer, limit)
+		},
+		read: func(ctx context.Context, peer tg.InputPeerClass) error {
+			return tgdata.ReadReactions(ctx, h.client, peer)
+		},
+	}
+	return listUnread(ctx, h.client, h.peers, chatID, limit, markRead, kind, reactionsChat)
+}
+
+// reactionsChat describes one chat's messages with unread reactions.
+func reactionsChat(unread unreadChat[tgdata.ReactedMessage]) UnreadReactionsChat {
+	result := UnreadReactionsChat{
+		ChatID:     unread.chat.ID,
+		ChatName:   unread.chat.Name,
+		Messages:   make([]UnreadReactionsMessage, 0, len(unread.items)),
+		MarkedRead: unread.markedRead,
+		UnreadLeft: unread.unreadLeft,
+		Error:      unread.err,
+	}
+	for _, m := range unread.items {
+		snippet := messages.TruncateRunes(m.Text, reactionSnippetRunes)
+		if snippet == "" {
+			snippet = "[media]"
+		}
+		result.Messages = append(result.Messages, UnreadReactionsMessage{
+			MessageID: m.MessageID,
+			Snippet:   snippet,
+			Summary:   summarizeReactions(m.Reactions),
+			Reactions: m.Reactions,
+		})
+	}
+	return result
+}
+
+// summarizeReactions describes reactions in one line, e.g. "Anna and 2 others reacted 👍".
+// Unread reactions are described when there are any, otherwise all of them.
+func summarizeReactions(reactions []tgdata.Reaction) string {
+	var selected []tgdata.Reaction
+	for _, r := range reactions {
+		if r.Unread {
+			selected = append(selected, r)
+		}
+	}
+	if len(selected) == 0 {
+		selected = reactions
+	}
+	if len(selected) == 0 {
+		return ""
+	}
+
+	var people, emoji []string
+	seenPeople := make(map[int64]bool)
+	seenEmoji := make(map[string]bool)
+	for _, r := range selected {
+		if !seenPeople[r.FromID] {
+			seenPeople[r.FromID] = true
+			people = append(people, r.From)
+		}
+		if !seenEmoji[r.Reaction] {
+			seenEmoji[r.Reaction] = true
+			emoji = append(emoji, r.Reaction)
+		}
+	}
+
+	var who string
+	switch len(people) {
+	case 1:
+		who = people[0]
+	case 2:
+		who = people[0] + " and " + people[1]
+	default:
+		who = fmt.Sprintf("%s and %d others", people[0], len(people)-1)
+	}
+	return fmt.Sprintf("%s reacted %s", who, strings.Join(emoji, " "))
+}
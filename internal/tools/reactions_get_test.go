@@ -0,0 +1,50 @@
+package tools
+
+import (
+	"testing"
+
+	"github.com/tolmachov/mcp-telegram/internal/tgdata"
+)
+
+func TestSummarizeReactions(t *testing.T) {
+	anna := tgdata.Reaction{Reaction: "👍", FromID: 1, From: "Anna", Unread: true}
+	bob := tgdata.Reaction{Reaction: "👍", FromID: 2, From: "Bob", Unread: true}
+	carol := tgdata.Reaction{Reaction: "❤", FromID: 3, From: "Carol", Unread: true}
+
+	tests := []struct {
+		name      string
+		reactions []tgdata.Reaction
+		want      string
+	}{
+		{name: "none", reactions: nil, want: ""},
+		{name: "one person", reactions: []tgdata.Reaction{anna}, want: "Anna reacted 👍"},
+		{name: "two people", reactions: []tgdata.Reaction{anna, bob}, want: "Anna and Bob reacted 👍"},
+		{name: "three people, two emoji", reactions: []tgdata.Reaction{anna, bob, carol}, want: "Anna and 2 others reacted 👍 ❤"},
+		{
+			name:      "read reactions ignored when unread exist",
+			reactions: []tgdata.Reaction{{Reaction: "😂", FromID: 4, From: "Dan"}, carol},
+			want:      "Carol reacted ❤",
+		},
+		{
+			name:      "all read",
+			reactions: []tgdata.Reaction{{Reaction: "😂", FromID: 4, From: "Dan"}},
+			want:      "Dan reacted 😂",
+		},
+		{
+			name: "same person twice",
+			reactions: []tgdata.Reaction{
+				anna,
+				{Reaction: "🔥", FromID: 1, From: "Anna", Unread: true},
+			},
+			want: "Anna reacted 👍 🔥",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := summarizeReactions(tt.reactions); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
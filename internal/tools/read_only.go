@@ -0,0 +1,108 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// readOnlyMessage explains why a tool or parameter is unavailable in read-only mode.
+const readOnlyMessage = "server is in read-only mode"
+
+// WriteTools are the tools that send, change or delete something in Telegram, or set
+// up the server to do so later. Read-only mode doesn't register them.
+var WriteTools = []string{
+	"SendMessage",
+	"SendFile",
+	"SendTemplate",
+	"ReplyToMessage",
+	"EditMessage",
+	"DeleteMessage",
+	"LeaveChat",
+	"DeleteDialog",
+	"JoinChat",
+	"EditChat",
+	"ForwardMessage",
+	"ClickButton",
+	"SendReaction",
+	"ScheduleMessage",
+	"EditScheduledMessage",
+	"SendScheduledNow",
+	"DeleteScheduledMessage",
+	"ScheduleConditionalMessage",
+	"SetAutoReply",
+	"AddKeywordWatch",
+	"DraftMessage",
+	"MarkAsRead",
+	"MarkAsUnread",
+	"MuteChat",
+	"UnmuteChat",
+	"MuteChats",
+	"UnmuteChats",
+	"ArchiveChat",
+	"UnarchiveChat",
+	"PinChat",
+	"UnpinChat",
+	"MoveChatToFolder",
+	"SetChatNotifications",
+}
+
+// ReadOnlyHandlers returns the handlers without those of WriteTools.
+func ReadOnlyHandlers(handlers []Handler) []Handler {
+	return slices.DeleteFunc(slices.Clone(handlers), func(h Handler) bool {
+		return slices.Contains(WriteTools, h.Tool().Name)
+	})
+}
+
+// ReadOnlyHook rejects calls of WriteTools, which read-only mode doesn't register,
+// with an error saying why instead of one about an unknown tool.
+func ReadOnlyHook(ctx context.Context, id any, message any) error {
+	raw, ok := message.(json.RawMessage)
+	if !ok {
+		return nil
+	}
+	var request struct {
+		Method string `json:"method"`
+		Params struct {
+			Name string `json:"name"`
+		} `json:"params"`
+	}
+	if err := json.Unmarshal(raw, &request); err != nil {
+		return nil
+	}
+	if request.Method == string(mcp.MethodToolsCall) && slices.Contains(WriteTools, request.Params.Name) {
+		return fmt.Errorf("tool %s is unavailable: %s", request.Params.Name, readOnlyMessage)
+	}
+	return nil
+}
+
+// stateChangingArgs are the parameters of read tools that change the chat in
+// Telegram when set: marking it read or saving its draft.
+var stateChangingArgs = []string{"mark_read", "save_draft"}
+
+// setsStateChangingArg returns the state-changing parameter a request sets, if any.
+func setsStateChangingArg(request mcp.CallToolRequest) (string, bool) {
+	for _, name := range stateChangingArgs {
+		if mcp.ParseBoolean(request, name, false) {
+			return name, true
+		}
+	}
+	return "", false
+}
+
+// ReadOnlyMiddleware rejects mark_read and save_draft in the read tools that accept
+// them, since marking a chat read or saving its draft changes it in Telegram.
+func ReadOnlyMiddleware() server.ToolHandlerMiddleware {
+	return func(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+		return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			if name, ok := setsStateChangingArg(request); ok {
+				return mcp.NewToolResultError(name + " is unavailable: " + readOnlyMessage), nil
+			}
+			return next(ctx, request)
+		}
+	}
+}
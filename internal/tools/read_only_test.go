@@ -0,0 +1,105 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+func testHandlers() []Handler {
+	return []Handler{
+		NewMeGetHandler(nil),
+		NewMessagesGetHandler(nil, nil),
+		NewMessageSendHandler(nil, nil, nil),
+		NewMessageReadHandler(nil),
+		NewChatMuteHandler(nil, nil),
+		NewScheduledGetHandler(nil, nil),
+		NewScheduledDeleteHandler(nil, nil),
+	}
+}
+
+// newTestServer registers handlers as Server.Run does in either mode.
+func newTestServer(readOnly bool) *server.MCPServer {
+	hooks := &server.Hooks{}
+	handlers := testHandlers()
+	var middlewares []server.ToolHandlerMiddleware
+	if readOnly {
+		hooks.AddOnRequestInitialization(ReadOnlyHook)
+		handlers = ReadOnlyHandlers(handlers)
+		middlewares = append(middlewares, ReadOnlyMiddleware())
+	}
+	s := server.NewMCPServer("test", "0", server.WithToolCapabilities(true), server.WithHooks(hooks))
+	RegisterTools(s, handlers, middlewares...)
+	return s
+}
+
+func handleMessage(t *testing.T, s *server.MCPServer, message string) mcp.JSONRPCMessage {
+	t.Helper()
+	return s.HandleMessage(context.Background(), json.RawMessage(message))
+}
+
+func listedTools(t *testing.T, s *server.MCPServer) []string {
+	t.Helper()
+	resp, ok := handleMessage(t, s, `{"jsonrpc":"2.0","id":1,"method":"tools/list"}`).(mcp.JSONRPCResponse)
+	if !ok {
+		t.Fatal("tools/list failed")
+	}
+	var names []string
+	for _, tool := range resp.Result.(mcp.ListToolsResult).Tools {
+		names = append(names, tool.Name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+func TestReadOnlyToolList(t *testing.T) {
+	full := listedTools(t, newTestServer(false))
+	readOnly := listedTools(t, newTestServer(true))
+
+	wantFull := []string{"DeleteScheduledMessage", "GetMe", "GetMessages", "GetScheduledMessages", "MarkAsRead", "MuteChat", "SendMessage"}
+	if !slices.Equal(full, wantFull) {
+		t.Errorf("tools = %v, want %v", full, wantFull)
+	}
+	wantReadOnly := []string{"GetMe", "GetMessages", "GetScheduledMessages"}
+	if !slices.Equal(readOnly, wantReadOnly) {
+		t.Errorf("read-only tools = %v, want %v", readOnly, wantReadOnly)
+	}
+}
+
+func TestReadOnlyRejectsWriteTools(t *testing.T) {
+	s := newTestServer(true)
+
+	resp := handleMessage(t, s, `{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"SendMessage","arguments":{"chat_id":1,"message":"hi"}}}`)
+	rpcErr, ok := resp.(mcp.JSONRPCError)
+	if !ok {
+		t.Fatalf("SendMessage response = %#v, want an error", resp)
+	}
+	if want := "tool SendMessage is unavailable: server is in read-only mode"; rpcErr.Error.Message != want {
+		t.Errorf("error = %q, want %q", rpcErr.Error.Message, want)
+	}
+
+	resp = handleMessage(t, s, `{"jsonrpc":"2.0","id":3,"method":"tools/call","params":{"name":"GetMessages","arguments":{"chat_id":1,"since":"last_read","mark_read":true}}}`)
+	result, ok := resp.(mcp.JSONRPCResponse)
+	if !ok {
+		t.Fatalf("GetMessages response = %#v, want a tool result", resp)
+	}
+	callResult := result.Result.(mcp.CallToolResult)
+	if !callResult.IsError || !strings.Contains(resultText(&callResult), "read-only mode") {
+		t.Errorf("GetMessages with mark_read = %q, want a read-only error", resultText(&callResult))
+	}
+
+	resp = handleMessage(t, s, `{"jsonrpc":"2.0","id":4,"method":"tools/call","params":{"name":"GetMessages","arguments":{"chat_id":1,"save_draft":true}}}`)
+	result, ok = resp.(mcp.JSONRPCResponse)
+	if !ok {
+		t.Fatalf("GetMessages response = %#v, want a tool result", resp)
+	}
+	callResult = result.Result.(mcp.CallToolResult)
+	if !callResult.IsError || !strings.Contains(resultText(&callResult), "save_draft is unavailable") {
+		t.Errorf("call with save_draft = %q, want a read-only error", resultText(&callResult))
+	}
+}
@@ -0,0 +1,123 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/tolmachov/mcp-telegram/internal/recent"
+	"github.com/tolmachov/mcp-telegram/internal/tgclient"
+)
+
+// chatIDArgs are the tool arguments that identify chats.
+var chatIDArgs = []string{"chat_id", "from_chat_id", "to_chat_id", "chat_ids"}
+
+// chatRefArgs are the tool arguments that name chats by username, phone or link,
+// resolved by the handler before the middleware sees the result.
+var chatRefArgs = []string{"chat", "from_chat", "to_chat"}
+
+// RecentChatsMiddleware records the chats touched by successful tool calls and, when a
+// call fails to find a chat or SearchChats finds nothing, appends recently used chats
+// to the result as candidates. Chat references count once resolved in peers.
+func RecentChatsMiddleware(tracker *recent.Tracker, peers *tgclient.PeerCache) server.ToolHandlerMiddleware {
+	return func(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+		return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			result, err := next(ctx, request)
+			if err != nil || result == nil {
+				return result, err
+			}
+
+			if needsRecentHint(request.Params.Name, result) {
+				if hint := recentChatsHint(tracker.List(ctx), time.Now()); hint != "" {
+					result.Content = append(result.Content, mcp.NewTextContent(hint))
+				}
+				return result, nil
+			}
+
+			if !result.IsError {
+				for _, chatID := range requestChatIDs(peers, request.GetArguments()) {
+					tracker.Touch(chatID, request.Params.Name)
+				}
+			}
+			return result, nil
+		}
+	}
+}
+
+// requestChatIDs extracts all chat IDs from tool arguments, including the chat
+// references already resolved in peers.
+func requestChatIDs(peers *tgclient.PeerCache, args map[string]any) []int64 {
+	var ids []int64
+	for _, name := range chatIDArgs {
+		switch v := args[name].(type) {
+		case float64:
+			if v != 0 {
+				ids = append(ids, int64(v))
+			}
+		case []any:
+			for _, item := range v {
+				if id, ok := item.(float64); ok && id != 0 {
+					ids = append(ids, int64(id))
+				}
+			}
+		}
+	}
+	for _, name := range chatRefArgs {
+		if ref, ok := args[name].(string); ok && ref != "" {
+			if id, ok := peers.CachedChatID(ref); ok {
+				ids = append(ids, id)
+			}
+		}
+	}
+	return ids
+}
+
+// needsRecentHint reports whether a result indicates the caller picked the wrong chat.
+func needsRecentHint(tool string, result *mcp.CallToolResult) bool {
+	text := resultText(result)
+	if result.IsError {
+		return strings.Contains(text, tgclient.PeerNotFoundMessage) || strings.Contains(text, tgclient.ChatNotFoundMessage)
+	}
+	if tool == "SearchChats" {
+		var found struct {
+			Count int `json:"count"`
+		}
+		return json.Unmarshal([]byte(text), &found) == nil && found.Count == 0
+	}
+	return false
+}
+
+// resultText concatenates the text content of a result.
+func resultText(result *mcp.CallToolResult) string {
+	var sb strings.Builder
+	for _, c := range result.Content {
+		if tc, ok := mcp.AsTextContent(c); ok {
+			sb.WriteString(tc.Text)
+		}
+	}
+	return sb.String()
+}
+
+// recentChatsHint formats recently used chats as "did you mean" candidates.
+func recentChatsHint(entries []recent.Entry, now time.Time) string {
+	if len(entries) == 0 {
+		return ""
+	}
+
+	var sb strings.Builder
+	sb.WriteString("Recently used chats (did you mean one of these?):")
+	for _, e := range entries {
+		name := e.ChatName
+		if name == "" {
+			name = "Unknown"
+		}
+		ago := now.Sub(e.Time).Round(time.Second)
+		fmt.Fprintf(&sb, "\n  - %s (ID %d), last used by %s %s ago", name, e.ChatID, e.Tool, ago)
+	}
+	return sb.String()
+}
@@ -0,0 +1,106 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gotd/td/tgerr"
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/tolmachov/mcp-telegram/internal/recent"
+)
+
+func callTool(name string, args map[string]any) mcp.CallToolRequest {
+	var request mcp.CallToolRequest
+	request.Params.Name = name
+	request.Params.Arguments = args
+	return request
+}
+
+func TestRecentChatsMiddleware(t *testing.T) {
+	ok := func(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultText("done"), nil
+	}
+	notFound := func(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return toolError("get messages", fmt.Errorf("getting messages: %w", tgerr.New(400, "PEER_ID_INVALID"))), nil
+	}
+	otherError := func(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultError("Failed to send message: MESSAGE_EMPTY"), nil
+	}
+	noResults := func(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultText(`{"query": "famly", "results": [], "count": 0}`), nil
+	}
+
+	tracker := recent.NewTracker(5, func(_ context.Context, chatID int64) (string, error) {
+		return fmt.Sprintf("Chat %d", chatID), nil
+	})
+	mw := RecentChatsMiddleware(tracker, nil)
+	ctx := context.Background()
+
+	// Successful calls are tracked, including multi-chat arguments
+	_, _ = mw(ok)(ctx, callTool("GetMessages", map[string]any{"chat_id": float64(100)}))
+	_, _ = mw(ok)(ctx, callTool("ForwardMessage", map[string]any{"from_chat_id": float64(200), "to_chat_id": float64(300)}))
+	_, _ = mw(ok)(ctx, callTool("MarkAsRead", map[string]any{"chat_ids": []any{float64(400)}}))
+
+	// Failed calls are not tracked
+	_, _ = mw(otherError)(ctx, callTool("SendMessage", map[string]any{"chat_id": float64(500)}))
+
+	entries := tracker.List(ctx)
+	if got := len(entries); got != 4 {
+		t.Fatalf("tracked %d chats, want 4: %+v", got, entries)
+	}
+	if entries[0].ChatID != 400 || entries[0].Tool != "MarkAsRead" {
+		t.Errorf("newest entry = %+v, want chat 400 from MarkAsRead", entries[0])
+	}
+
+	tests := []struct {
+		name     string
+		handler  func(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error)
+		tool     string
+		wantHint bool
+	}{
+		{name: "chat not found", handler: notFound, tool: "GetMessages", wantHint: true},
+		{name: "empty search", handler: noResults, tool: "SearchChats", wantHint: true},
+		{name: "unrelated error", handler: otherError, tool: "SendMessage", wantHint: false},
+		{name: "success", handler: ok, tool: "GetMessages", wantHint: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := mw(tt.handler)(ctx, callTool(tt.tool, map[string]any{"chat_id": float64(999)}))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			text := resultText(result)
+			hasHint := strings.Contains(text, "Recently used chats")
+			if hasHint != tt.wantHint {
+				t.Fatalf("hint present = %v, want %v; result: %q", hasHint, tt.wantHint, text)
+			}
+			if tt.wantHint && !strings.Contains(text, "Chat 100 (ID 100)") {
+				t.Errorf("hint does not list recent chat: %q", text)
+			}
+		})
+	}
+}
+
+func TestRecentChatsHint(t *testing.T) {
+	now := time.Date(2024, 1, 15, 10, 5, 0, 0, time.UTC)
+	entries := []recent.Entry{
+		{ChatID: 1, ChatName: "Family", Tool: "GetMessages", Time: now.Add(-2 * time.Minute)},
+		{ChatID: -1001234567890, Tool: "SendMessage", Time: now.Add(-time.Hour)},
+	}
+
+	want := "Recently used chats (did you mean one of these?):\n" +
+		"  - Family (ID 1), last used by GetMessages 2m0s ago\n" +
+		"  - Unknown (ID -1001234567890), last used by SendMessage 1h0m0s ago"
+	if got := recentChatsHint(entries, now); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+
+	if got := recentChatsHint(nil, now); got != "" {
+		t.Errorf("expected no hint without recent chats, got %q", got)
+	}
+}
@@ -0,0 +1,137 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gotd/td/tg"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/tolmachov/mcp-telegram/internal/messages"
+	"github.com/tolmachov/mcp-telegram/internal/summarize"
+	"github.com/tolmachov/mcp-telegram/internal/tgdata"
+)
+
+const (
+	defaultSuggestReplyMessages = 30
+	maxSuggestReplyMessages     = 100
+	maxReplySuggestions         = 5
+)
+
+// suggestReplyResult is the SuggestReply output.
+type suggestReplyResult struct {
+	ChatID      int64    `json:"chat_id"`
+	Messages    int      `json:"messages"` // Messages fetched as context
+	Suggestions []string `json:"suggestions"`
+	DraftSaved  int      `json:"draft_saved,omitempty"` // Number of the suggestion saved as the chat's draft
+}
+
+// ReplySuggestHandler handles the SuggestReply tool
+type ReplySuggestHandler struct {
+	client      *tg.Client
+	msgProvider *messages.Provider
+	mcpServer   *server.MCPServer
+	config      summarize.Config
+}
+
+// NewReplySuggestHandler creates a new ReplySuggestHandler
+func NewReplySuggestHandler(client *tg.Client, msgProvider *messages.Provider, mcpServer *server.MCPServer, config summarize.Config) *ReplySuggestHandler {
+	return &ReplySuggestHandler{
+		client:      client,
+		msgProvider: msgProvider,
+		mcpServer:   mcpServer,
+		config:      config,
+	}
+}
+
+// Tool returns the MCP tool definition
+func (h *ReplySuggestHandler) Tool() mcp.Tool {
+	return mcp.NewTool("SuggestReply",
+		mcp.WithDescription("Suggest replies to the latest messages of a chat with AI, in the chat's language and matching the tone of your own messages. Nothing is sent: with save_draft one suggestion is saved as the chat's draft to review and send in Telegram."),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithOpenWorldHintAnnotation(true),
+		mcp.WithNumber("chat_id",
+			mcp.Description("The chat ID to suggest a reply in (or use chat)"),
+		),
+		chatRefOption("chat", "chat_id", "The chat to suggest a reply in"),
+		mcp.WithNumber("count",
+			mcp.Description(fmt.Sprintf("Latest messages to read as context (default: %d, max: %d)", defaultSuggestReplyMessages, maxSuggestReplyMessages)),
+		),
+		mcp.WithNumber("suggestions",
+			mcp.Description(fmt.Sprintf("Replies to suggest (default: %d, max: %d)", summarize.DefaultReplySuggestions, maxReplySuggestions)),
+		),
+		mcp.WithString("hint",
+			mcp.Description("What you want to say, e.g. 'decline politely' or 'agree to Friday'"),
+		),
+		mcp.WithBoolean("save_draft",
+			mcp.Description("Save one suggestion as the chat's draft, replacing any draft there (default: false)"),
+		),
+		mcp.WithNumber("draft_choice",
+			mcp.Description("Number of the suggestion to save with save_draft (default: 1)"),
+		),
+	)
+}
+
+// Handle processes the SuggestReply tool request
+func (h *ReplySuggestHandler) Handle(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	chatID, errResult := chatIDParam(ctx, h.client, h.msgProvider.Peers(), request, "chat_id", "chat")
+	if errResult != nil {
+		return errResult, nil
+	}
+	count := mcp.ParseInt(request, "count", defaultSuggestReplyMessages)
+	if count <= 0 || count > maxSuggestReplyMessages {
+		return mcp.NewToolResultError(fmt.Sprintf("count must be between 1 and %d", maxSuggestReplyMessages)), nil
+	}
+	suggestions := mcp.ParseInt(request, "suggestions", summarize.DefaultReplySuggestions)
+	if suggestions <= 0 || suggestions > maxReplySuggestions {
+		return mcp.NewToolResultError(fmt.Sprintf("suggestions must be between 1 and %d", maxReplySuggestions)), nil
+	}
+	saveDraft := mcp.ParseBoolean(request, "save_draft", false)
+	choice := mcp.ParseInt(request, "draft_choice", 1)
+	if choice <= 0 || choice > suggestions {
+		return mcp.NewToolResultError(fmt.Sprintf("draft_choice must be between 1 and %d", suggestions)), nil
+	}
+
+	me, err := tgdata.GetCurrentUser(ctx, h.client)
+	if err != nil {
+		return toolError("get current user", err), nil
+	}
+	fetched, err := h.msgProvider.Fetch(ctx, chatID, messages.FetchOptions{Limit: count})
+	if err != nil {
+		return toolError("fetch messages", err), nil
+	}
+	messages.Reverse(fetched.Messages)
+
+	summarizer := summarize.NewSummarizer(newSummarizeProvider(h.mcpServer, h.config), h.msgProvider, h.config.BatchTokens)
+	replies, err := summarizer.SuggestReplies(ctx, fetched.Messages, me.ID, summarize.ReplyOptions{
+		Suggestions: suggestions,
+		Hint:        mcp.ParseString(request, "hint", ""),
+	})
+	if err != nil {
+		return toolError("suggest replies", err), nil
+	}
+
+	result := suggestReplyResult{
+		ChatID:      chatID,
+		Messages:    len(fetched.Messages),
+		Suggestions: replies,
+	}
+	if saveDraft {
+		if choice > len(replies) {
+			return mcp.NewToolResultError(fmt.Sprintf("draft_choice %d is out of range: the model returned %d suggestions", choice, len(replies))), nil
+		}
+		err := h.msgProvider.Peers().WithPeer(ctx, h.client, chatID, func(peer tg.InputPeerClass) error {
+			_, err := h.client.MessagesSaveDraft(ctx, &tg.MessagesSaveDraftRequest{
+				Peer:    peer,
+				Message: replies[choice-1],
+			})
+			return err
+		})
+		if err != nil {
+			return toolError("save draft", err), nil
+		}
+		result.DraftSaved = choice
+	}
+	return jsonResult(result)
+}
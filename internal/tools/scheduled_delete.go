@@ -13,11 +13,12 @@ import (
 // ScheduledDeleteHandler handles the DeleteScheduledMessage tool
 type ScheduledDeleteHandler struct {
 	client *tg.Client
+	peers  *tgclient.PeerCache
 }
 
 // NewScheduledDeleteHandler creates a new ScheduledDeleteHandler
-func NewScheduledDeleteHandler(client *tg.Client) *ScheduledDeleteHandler {
-	return &ScheduledDeleteHandler{client: client}
+func NewScheduledDeleteHandler(client *tg.Client, peers *tgclient.PeerCache) *ScheduledDeleteHandler {
+	return &ScheduledDeleteHandler{client: client, peers: peers}
 }
 
 // Tool returns the MCP tool definition
@@ -48,19 +49,16 @@ func (h *ScheduledDeleteHandler) Handle(ctx context.Context, request mcp.CallToo
 		return mcp.NewToolResultError("message_id is required"), nil
 	}
 
-	// Resolve the peer
-	peer, err := tgclient.ResolvePeer(ctx, h.client, chatID)
-	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Failed to resolve peer: %v", err)), nil
-	}
-
 	// Delete the scheduled message
-	_, err = h.client.MessagesDeleteScheduledMessages(ctx, &tg.MessagesDeleteScheduledMessagesRequest{
-		Peer: peer,
-		ID:   []int{messageID},
+	err := h.peers.WithPeer(ctx, h.client, chatID, func(peer tg.InputPeerClass) error {
+		_, err := h.client.MessagesDeleteScheduledMessages(ctx, &tg.MessagesDeleteScheduledMessagesRequest{
+			Peer: peer,
+			ID:   []int{messageID},
+		})
+		return err
 	})
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Failed to delete scheduled message: %v", err)), nil
+		return toolError("delete scheduled message", err), nil
 	}
 
 	result := fmt.Sprintf("Scheduled message canceled successfully!\nMessage ID: %d\nChat: %d\n\nThe message has been removed from the schedule queue and will not be sent.",
@@ -0,0 +1,133 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gotd/td/tg"
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/tolmachov/mcp-telegram/internal/messages"
+	"github.com/tolmachov/mcp-telegram/internal/tgclient"
+)
+
+// ScheduledEditHandler handles the EditScheduledMessage tool
+type ScheduledEditHandler struct {
+	client *tg.Client
+	peers  *tgclient.PeerCache
+}
+
+// NewScheduledEditHandler creates a new ScheduledEditHandler
+func NewScheduledEditHandler(client *tg.Client, peers *tgclient.PeerCache) *ScheduledEditHandler {
+	return &ScheduledEditHandler{client: client, peers: peers}
+}
+
+// Tool returns the MCP tool definition
+func (h *ScheduledEditHandler) Tool() mcp.Tool {
+	return mcp.NewTool("EditScheduledMessage",
+		mcp.WithDescription("Change the text or the send time of a scheduled message. Message IDs come from GetScheduledMessages."),
+		mcp.WithOpenWorldHintAnnotation(true),
+		mcp.WithNumber("chat_id",
+			mcp.Description("The ID of the chat containing the scheduled message"),
+			mcp.Required(),
+		),
+		mcp.WithNumber("message_id",
+			mcp.Description("The ID of the scheduled message to edit"),
+			mcp.Required(),
+		),
+		mcp.WithString("new_text",
+			mcp.Description("The new text for the message (default: unchanged)"),
+		),
+		mcp.WithNumber("new_delay_seconds",
+			mcp.Description("Number of seconds from now to send the message at instead (default: unchanged)"),
+		),
+	)
+}
+
+// Handle processes the EditScheduledMessage tool request
+func (h *ScheduledEditHandler) Handle(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	chatID := mcp.ParseInt64(request, "chat_id", 0)
+	if chatID == 0 {
+		return mcp.NewToolResultError("chat_id is required"), nil
+	}
+
+	messageID := mcp.ParseInt(request, "message_id", 0)
+	if messageID == 0 {
+		return mcp.NewToolResultError("message_id is required"), nil
+	}
+
+	newText := mcp.ParseString(request, "new_text", "")
+	delaySeconds := mcp.ParseInt(request, "new_delay_seconds", 0)
+	if newText == "" && delaySeconds == 0 {
+		return mcp.NewToolResultError("new_text or new_delay_seconds is required"), nil
+	}
+	if delaySeconds < 0 {
+		return mcp.NewToolResultError("new_delay_seconds must be a positive number"), nil
+	}
+
+	// Telegram keeps the text or the date of a scheduled message when it's left out
+	var edited tg.InputPeerClass
+	err := h.peers.WithPeer(ctx, h.client, chatID, func(peer tg.InputPeerClass) error {
+		edit := &tg.MessagesEditMessageRequest{
+			Peer:    peer,
+			ID:      messageID,
+			Message: newText,
+		}
+		if delaySeconds > 0 {
+			edit.ScheduleDate = int(time.Now().Add(time.Duration(delaySeconds) * time.Second).Unix())
+		}
+		if _, err := h.client.MessagesEditMessage(ctx, edit); err != nil {
+			return err
+		}
+		edited = peer
+		return nil
+	})
+	if err != nil {
+		return toolError("edit scheduled message", err), nil
+	}
+
+	result := fmt.Sprintf("Scheduled message edited successfully!\nChat ID: %d\nMessage ID: %d", chatID, messageID)
+	msg, err := fetchScheduledMessage(ctx, h.client, edited, messageID)
+	if err != nil {
+		// The edit went through; only the report of the new state is missing
+		return mcp.NewToolResultText(result + fmt.Sprintf("\nCould not read back the message: %v", tgclient.ClassifyError(err))), nil
+	}
+	result += fmt.Sprintf("\nWill be sent at: %s (in %s)\nText: %s",
+		time.Unix(int64(msg.Date), 0).Format("2006-01-02 15:04:05"),
+		scheduledIn(msg.Date, time.Now()),
+		messages.TruncateRunes(msg.Message, sentTextSnippetRunes),
+	)
+	return mcp.NewToolResultText(result), nil
+}
+
+// fetchScheduledMessage retrieves a single scheduled message by ID from the given peer.
+func fetchScheduledMessage(ctx context.Context, client *tg.Client, peer tg.InputPeerClass, msgID int) (*tg.Message, error) {
+	result, err := client.MessagesGetScheduledMessages(ctx, &tg.MessagesGetScheduledMessagesRequest{
+		Peer: peer,
+		ID:   []int{msgID},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("getting scheduled message %d: %w", msgID, err)
+	}
+	modified, ok := result.AsModified()
+	if !ok {
+		return nil, fmt.Errorf("scheduled message %d not found", msgID)
+	}
+	for _, m := range modified.GetMessages() {
+		if msg, ok := m.(*tg.Message); ok && msg.ID == msgID {
+			return msg, nil
+		}
+	}
+	return nil, fmt.Errorf("scheduled message %d not found", msgID)
+}
+
+// scheduledIn describes how long until a scheduled date, for chaining the
+// new_delay_seconds of EditScheduledMessage.
+func scheduledIn(date int, now time.Time) string {
+	d := time.Unix(int64(date), 0).Sub(now).Round(time.Second)
+	if d <= 0 {
+		return "0s, due now"
+	}
+	return fmt.Sprintf("%s, %d seconds", d, int64(d.Seconds()))
+}
@@ -9,17 +9,19 @@ import (
 	"github.com/gotd/td/tg"
 	"github.com/mark3labs/mcp-go/mcp"
 
+	"github.com/tolmachov/mcp-telegram/internal/messages"
 	"github.com/tolmachov/mcp-telegram/internal/tgclient"
 )
 
 // ScheduledGetHandler handles the GetScheduledMessages tool
 type ScheduledGetHandler struct {
 	client *tg.Client
+	peers  *tgclient.PeerCache
 }
 
 // NewScheduledGetHandler creates a new ScheduledGetHandler
-func NewScheduledGetHandler(client *tg.Client) *ScheduledGetHandler {
-	return &ScheduledGetHandler{client: client}
+func NewScheduledGetHandler(client *tg.Client, peers *tgclient.PeerCache) *ScheduledGetHandler {
+	return &ScheduledGetHandler{client: client, peers: peers}
 }
 
 // Tool returns the MCP tool definition
@@ -41,18 +43,17 @@ func (h *ScheduledGetHandler) Handle(ctx context.Context, request mcp.CallToolRe
 		return mcp.NewToolResultError("chat_id is required"), nil
 	}
 
-	// Resolve the peer
-	peer, err := tgclient.ResolvePeer(ctx, h.client, chatID)
-	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Failed to resolve peer: %v", err)), nil
-	}
-
 	// Get scheduled messages
-	scheduled, err := h.client.MessagesGetScheduledHistory(ctx, &tg.MessagesGetScheduledHistoryRequest{
-		Peer: peer,
+	var scheduled tg.MessagesMessagesClass
+	err := h.peers.WithPeer(ctx, h.client, chatID, func(peer tg.InputPeerClass) error {
+		var err error
+		scheduled, err = h.client.MessagesGetScheduledHistory(ctx, &tg.MessagesGetScheduledHistoryRequest{
+			Peer: peer,
+		})
+		return err
 	})
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Failed to get scheduled messages: %v", err)), nil
+		return toolError("get scheduled messages", err), nil
 	}
 
 	var messages []tg.MessageClass
@@ -72,24 +73,28 @@ func (h *ScheduledGetHandler) Handle(ctx context.Context, request mcp.CallToolRe
 		return mcp.NewToolResultText(fmt.Sprintf("No scheduled messages found for chat %d", chatID)), nil
 	}
 
+	now := time.Now()
 	var results []string
-	results = append(results, fmt.Sprintf("Scheduled Messages (%d total):", len(messages)))
+	results = append(results, fmt.Sprintf("Scheduled Messages in chat %d (%d total); edit them with EditScheduledMessage or send them with SendScheduledNow:", chatID, len(messages)))
 
 	for _, msgClass := range messages {
 		msg, ok := msgClass.(*tg.Message)
 		if !ok {
 			continue
 		}
-
-		scheduleTime := time.Unix(int64(msg.Date), 0)
-
-		result := fmt.Sprintf("\n* Message ID: %d\n  Scheduled for: %s\n  Text: %s",
-			msg.ID,
-			scheduleTime.Format("2006-01-02 15:04:05"),
-			truncateRunes(msg.Message, 100),
-		)
-		results = append(results, result)
+		results = append(results, formatScheduledMessage(msg, now))
 	}
 
 	return mcp.NewToolResultText(strings.Join(results, "\n")), nil
 }
+
+// formatScheduledMessage describes a scheduled message with what EditScheduledMessage
+// and SendScheduledNow need.
+func formatScheduledMessage(msg *tg.Message, now time.Time) string {
+	return fmt.Sprintf("\n* Message ID: %d\n  Scheduled for: %s (in %s)\n  Text: %s",
+		msg.ID,
+		time.Unix(int64(msg.Date), 0).Format("2006-01-02 15:04:05"),
+		scheduledIn(msg.Date, now),
+		messages.TruncateRunes(msg.Message, 100),
+	)
+}
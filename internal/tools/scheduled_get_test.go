@@ -0,0 +1,31 @@
+package tools
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gotd/td/tg"
+)
+
+func TestFormatScheduledMessage(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	msg := &tg.Message{ID: 42, Date: int(now.Add(90 * time.Minute).Unix()), Message: "Standup reminder"}
+
+	got := formatScheduledMessage(msg, now)
+	for _, want := range []string{"Message ID: 42", "(in 1h30m0s, 5400 seconds)", "Text: Standup reminder"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("formatScheduledMessage() = %q, missing %q", got, want)
+		}
+	}
+}
+
+func TestScheduledIn(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	if got, want := scheduledIn(int(now.Add(45*time.Second).Unix()), now), "45s, 45 seconds"; got != want {
+		t.Errorf("scheduledIn(45s) = %q, want %q", got, want)
+	}
+	if got, want := scheduledIn(int(now.Add(-time.Minute).Unix()), now), "0s, due now"; got != want {
+		t.Errorf("scheduledIn(past) = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,75 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gotd/td/tg"
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/tolmachov/mcp-telegram/internal/tgclient"
+)
+
+// ScheduledSendNowHandler handles the SendScheduledNow tool
+type ScheduledSendNowHandler struct {
+	client *tg.Client
+	peers  *tgclient.PeerCache
+}
+
+// NewScheduledSendNowHandler creates a new ScheduledSendNowHandler
+func NewScheduledSendNowHandler(client *tg.Client, peers *tgclient.PeerCache) *ScheduledSendNowHandler {
+	return &ScheduledSendNowHandler{client: client, peers: peers}
+}
+
+// Tool returns the MCP tool definition
+func (h *ScheduledSendNowHandler) Tool() mcp.Tool {
+	return mcp.NewTool("SendScheduledNow",
+		mcp.WithDescription("Send a scheduled message right away instead of at its scheduled time. Message IDs come from GetScheduledMessages."),
+		mcp.WithOpenWorldHintAnnotation(true),
+		mcp.WithNumber("chat_id",
+			mcp.Description("The ID of the chat containing the scheduled message"),
+			mcp.Required(),
+		),
+		mcp.WithNumber("message_id",
+			mcp.Description("The ID of the scheduled message to send"),
+			mcp.Required(),
+		),
+	)
+}
+
+// Handle processes the SendScheduledNow tool request
+func (h *ScheduledSendNowHandler) Handle(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	chatID := mcp.ParseInt64(request, "chat_id", 0)
+	if chatID == 0 {
+		return mcp.NewToolResultError("chat_id is required"), nil
+	}
+
+	messageID := mcp.ParseInt(request, "message_id", 0)
+	if messageID == 0 {
+		return mcp.NewToolResultError("message_id is required"), nil
+	}
+
+	var updates tg.UpdatesClass
+	err := h.peers.WithPeer(ctx, h.client, chatID, func(peer tg.InputPeerClass) error {
+		var err error
+		updates, err = h.client.MessagesSendScheduledMessages(ctx, &tg.MessagesSendScheduledMessagesRequest{
+			Peer: peer,
+			ID:   []int{messageID},
+		})
+		return err
+	})
+	if err != nil {
+		return toolError("send scheduled message", err), nil
+	}
+
+	// The sent message gets a new ID; the scheduled one is gone
+	sent := extractSentMessage(updates)
+	result := fmt.Sprintf("Scheduled message sent now!\nChat ID: %d\nScheduled Message ID: %d (removed from the schedule)", chatID, messageID)
+	if sent.ID != 0 {
+		result += fmt.Sprintf("\nMessage ID: %d", sent.ID)
+	}
+	if sent.Link != "" {
+		result += fmt.Sprintf("\nLink: %s", sent.Link)
+	}
+	return mcp.NewToolResultText(result), nil
+}
@@ -0,0 +1,102 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gotd/td/tg"
+
+	"github.com/tolmachov/mcp-telegram/internal/messages"
+)
+
+// sentTextSnippetRunes limits how much of a sent text is echoed back in tool results.
+const sentTextSnippetRunes = 200
+
+// sentMessage describes a message extracted from an updates response.
+type sentMessage struct {
+	ID   int
+	Date int
+	Link string // t.me link for channel and supergroup messages, empty otherwise
+}
+
+// extractSentMessage finds the new, edited or scheduled message in an updates response
+// and builds its t.me link when the message belongs to a channel or supergroup.
+func extractSentMessage(updates tg.UpdatesClass) sentMessage {
+	switch u := updates.(type) {
+	case *tg.UpdateShortSentMessage:
+		return sentMessage{ID: u.ID, Date: u.Date}
+	case *tg.Updates:
+		for _, update := range u.Updates {
+			var msgClass tg.MessageClass
+			scheduled := false
+			switch upd := update.(type) {
+			case *tg.UpdateNewMessage:
+				msgClass = upd.Message
+			case *tg.UpdateNewChannelMessage:
+				msgClass = upd.Message
+			case *tg.UpdateNewScheduledMessage:
+				msgClass = upd.Message
+				scheduled = true
+			case *tg.UpdateEditMessage:
+				msgClass = upd.Message
+			case *tg.UpdateEditChannelMessage:
+				msgClass = upd.Message
+			default:
+				continue
+			}
+
+			msg, ok := msgClass.(*tg.Message)
+			if !ok {
+				continue
+			}
+
+			sent := sentMessage{ID: msg.ID, Date: msg.Date}
+			// Scheduled messages get a new ID once sent, so they have no stable link yet
+			if peer, ok := msg.PeerID.(*tg.PeerChannel); ok && !scheduled {
+				sent.Link = channelMessageLink(u.Chats, peer.ChannelID, msg.ID)
+			}
+			return sent
+		}
+	}
+	return sentMessage{}
+}
+
+// channelMessageLink builds a t.me link using the channel's username from the updates' chats.
+func channelMessageLink(chats []tg.ChatClass, channelID int64, msgID int) string {
+	for _, c := range chats {
+		if channel, ok := c.(*tg.Channel); ok && channel.ID == channelID {
+			return messages.MessageLink(channel.Username, channel.ID, msgID)
+		}
+	}
+	return messages.MessageLink("", channelID, msgID)
+}
+
+// fetchMessage retrieves a single message by ID from the given peer.
+func fetchMessage(ctx context.Context, client *tg.Client, peer tg.InputPeerClass, msgID int) (*tg.Message, error) {
+	ids := []tg.InputMessageClass{&tg.InputMessageID{ID: msgID}}
+
+	var result tg.MessagesMessagesClass
+	var err error
+	if p, ok := peer.(*tg.InputPeerChannel); ok {
+		result, err = client.ChannelsGetMessages(ctx, &tg.ChannelsGetMessagesRequest{
+			Channel: &tg.InputChannel{ChannelID: p.ChannelID, AccessHash: p.AccessHash},
+			ID:      ids,
+		})
+	} else {
+		result, err = client.MessagesGetMessages(ctx, ids)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("getting message %d: %w", msgID, err)
+	}
+
+	modified, ok := result.AsModified()
+	if !ok {
+		return nil, fmt.Errorf("unexpected response type: %T", result)
+	}
+	for _, m := range modified.GetMessages() {
+		if msg, ok := m.(*tg.Message); ok && msg.ID == msgID {
+			return msg, nil
+		}
+	}
+	return nil, fmt.Errorf("message %d not found", msgID)
+}
@@ -0,0 +1,82 @@
+package tools
+
+import (
+	"testing"
+
+	"github.com/gotd/td/tg"
+)
+
+func TestExtractSentMessage(t *testing.T) {
+	tests := []struct {
+		name    string
+		updates tg.UpdatesClass
+		want    sentMessage
+	}{
+		{
+			name:    "short sent message",
+			updates: &tg.UpdateShortSentMessage{ID: 10, Date: 1700000000},
+			want:    sentMessage{ID: 10, Date: 1700000000},
+		},
+		{
+			name: "private chat message has no link",
+			updates: &tg.Updates{Updates: []tg.UpdateClass{
+				&tg.UpdateNewMessage{Message: &tg.Message{ID: 11, Date: 1700000001, PeerID: &tg.PeerUser{UserID: 5}}},
+			}},
+			want: sentMessage{ID: 11, Date: 1700000001},
+		},
+		{
+			name: "public channel message",
+			updates: &tg.Updates{
+				Updates: []tg.UpdateClass{
+					&tg.UpdateMessageID{ID: 12, RandomID: 1},
+					&tg.UpdateNewChannelMessage{Message: &tg.Message{ID: 12, Date: 1700000002, PeerID: &tg.PeerChannel{ChannelID: 777}}},
+				},
+				Chats: []tg.ChatClass{&tg.Channel{ID: 777, Username: "news"}},
+			},
+			want: sentMessage{ID: 12, Date: 1700000002, Link: "https://t.me/news/12"},
+		},
+		{
+			name: "private supergroup message",
+			updates: &tg.Updates{
+				Updates: []tg.UpdateClass{
+					&tg.UpdateNewChannelMessage{Message: &tg.Message{ID: 13, Date: 1700000003, PeerID: &tg.PeerChannel{ChannelID: 888}}},
+				},
+				Chats: []tg.ChatClass{&tg.Channel{ID: 888}},
+			},
+			want: sentMessage{ID: 13, Date: 1700000003, Link: "https://t.me/c/888/13"},
+		},
+		{
+			name: "edited channel message",
+			updates: &tg.Updates{
+				Updates: []tg.UpdateClass{
+					&tg.UpdateEditChannelMessage{Message: &tg.Message{ID: 14, Date: 1700000004, PeerID: &tg.PeerChannel{ChannelID: 777}}},
+				},
+				Chats: []tg.ChatClass{&tg.Channel{ID: 777, Username: "news"}},
+			},
+			want: sentMessage{ID: 14, Date: 1700000004, Link: "https://t.me/news/14"},
+		},
+		{
+			name: "scheduled message has no link",
+			updates: &tg.Updates{
+				Updates: []tg.UpdateClass{
+					&tg.UpdateNewScheduledMessage{Message: &tg.Message{ID: 15, Date: 1700000005, PeerID: &tg.PeerChannel{ChannelID: 777}}},
+				},
+				Chats: []tg.ChatClass{&tg.Channel{ID: 777, Username: "news"}},
+			},
+			want: sentMessage{ID: 15, Date: 1700000005},
+		},
+		{
+			name:    "no updates",
+			updates: &tg.Updates{},
+			want:    sentMessage{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := extractSentMessage(tt.updates); got != tt.want {
+				t.Errorf("extractSentMessage() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,225 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gotd/td/tg"
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/tolmachov/mcp-telegram/internal/templates"
+	"github.com/tolmachov/mcp-telegram/internal/tgclient"
+)
+
+// noTemplatesMessage explains how to configure templates when none are loaded.
+const noTemplatesMessage = "No message templates are configured. Set --message-templates or TELEGRAM_MESSAGE_TEMPLATES to a templates JSON file."
+
+// TemplatesListHandler handles the ListMessageTemplates tool
+type TemplatesListHandler struct {
+	store *templates.Store
+}
+
+// NewTemplatesListHandler creates a new TemplatesListHandler
+func NewTemplatesListHandler(store *templates.Store) *TemplatesListHandler {
+	return &TemplatesListHandler{store: store}
+}
+
+// Tool returns the MCP tool definition
+func (h *TemplatesListHandler) Tool() mcp.Tool {
+	return mcp.NewTool("ListMessageTemplates",
+		mcp.WithDescription("List the configured outgoing message templates with their text, variables and defaults. Use SendTemplate to send one."),
+		mcp.WithReadOnlyHintAnnotation(true),
+	)
+}
+
+// templateInfo describes a template for ListMessageTemplates.
+type templateInfo struct {
+	templates.Template
+	Variables []string `json:"variables"`
+	Required  []string `json:"required"`
+}
+
+// Handle processes the ListMessageTemplates tool request
+func (h *TemplatesListHandler) Handle(_ context.Context, _ mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	list := h.store.List()
+	if len(list) == 0 {
+		return mcp.NewToolResultText(noTemplatesMessage), nil
+	}
+
+	infos := make([]templateInfo, 0, len(list))
+	for _, t := range list {
+		infos = append(infos, templateInfo{
+			Template:  t,
+			Variables: nonNil(t.Variables()),
+			Required:  nonNil(t.Required()),
+		})
+	}
+
+	data, err := json.MarshalIndent(infos, "", "  ")
+	if err != nil {
+		return toolError("marshal templates", err), nil
+	}
+	return mcp.NewToolResultText(string(data)), nil
+}
+
+// nonNil returns an empty slice instead of nil so it marshals as [].
+func nonNil(s []string) []string {
+	if s == nil {
+		return []string{}
+	}
+	return s
+}
+
+// TemplateSendHandler handles the SendTemplate tool
+type TemplateSendHandler struct {
+	client *tg.Client
+	peers  *tgclient.PeerCache
+	store  *templates.Store
+}
+
+// NewTemplateSendHandler creates a new TemplateSendHandler
+func NewTemplateSendHandler(client *tg.Client, peers *tgclient.PeerCache, store *templates.Store) *TemplateSendHandler {
+	return &TemplateSendHandler{client: client, peers: peers, store: store}
+}
+
+// Tool returns the MCP tool definition
+func (h *TemplateSendHandler) Tool() mcp.Tool {
+	return mcp.NewTool("SendTemplate",
+		mcp.WithDescription("Render a configured message template with variables and send it to a chat. Use ListMessageTemplates to see available templates and their variables."),
+		mcp.WithOpenWorldHintAnnotation(true),
+		mcp.WithString("template",
+			mcp.Description("Name of the template to send"),
+			mcp.Required(),
+		),
+		mcp.WithNumber("chat_id",
+			mcp.Description("The ID of the chat to send the message to"),
+			mcp.Required(),
+		),
+		mcp.WithObject("variables",
+			mcp.Description("Values for the template's {{variable}} placeholders, e.g. {\"name\": \"Anna\", \"amount\": \"120 EUR\"}"),
+		),
+	)
+}
+
+// Handle processes the SendTemplate tool request
+func (h *TemplateSendHandler) Handle(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	name := mcp.ParseString(request, "template", "")
+	if name == "" {
+		return mcp.NewToolResultError("template is required"), nil
+	}
+	chatID := mcp.ParseInt64(request, "chat_id", 0)
+	if chatID == 0 {
+		return mcp.NewToolResultError("chat_id is required"), nil
+	}
+
+	tmpl, ok := h.store.Get(name)
+	if !ok {
+		if len(h.store.List()) == 0 {
+			return mcp.NewToolResultError(noTemplatesMessage), nil
+		}
+		return mcp.NewToolResultError(fmt.Sprintf("template %q not found. Use ListMessageTemplates to see available templates.", name)), nil
+	}
+
+	vars, err := templateVariables(request.GetArguments()["variables"])
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	text, err := tmpl.Render(vars)
+	if err != nil {
+		var missing *templates.MissingVariablesError
+		if errors.As(err, &missing) {
+			return mcp.NewToolResultError(fmt.Sprintf("%v. Provide them in variables.", err)), nil
+		}
+		return toolError("render template", err), nil
+	}
+
+	sent, err := sendText(ctx, h.client, h.peers, chatID, text)
+	if err != nil {
+		return toolError("send message", err), nil
+	}
+
+	result := fmt.Sprintf("Template %q sent successfully!\nMessage ID: %d\nDate: %s\nTo: %d",
+		name,
+		sent.ID,
+		time.Unix(int64(sent.Date), 0).Format(time.RFC3339),
+		chatID,
+	)
+	if sent.Link != "" {
+		result += fmt.Sprintf("\nLink: %s", sent.Link)
+	}
+	result += fmt.Sprintf("\nText: %s", text)
+
+	return mcp.NewToolResultText(result), nil
+}
+
+// templateVariables converts the variables argument to strings. Numbers and booleans
+// are formatted as written; nested objects and arrays are rejected.
+func templateVariables(raw any) (map[string]string, error) {
+	if raw == nil {
+		return nil, nil
+	}
+	obj, ok := raw.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("variables must be an object, got %T", raw)
+	}
+
+	vars := make(map[string]string, len(obj))
+	for k, v := range obj {
+		switch val := v.(type) {
+		case string:
+			vars[k] = val
+		case float64, bool, json.Number:
+			vars[k] = fmt.Sprint(val)
+		case nil:
+			vars[k] = ""
+		default:
+			return nil, fmt.Errorf("variable %q must be a string, number or boolean, got %T", k, v)
+		}
+	}
+	return vars, nil
+}
+
+// TemplatesReloadHandler handles the ReloadTemplates tool
+type TemplatesReloadHandler struct {
+	store *templates.Store
+}
+
+// NewTemplatesReloadHandler creates a new TemplatesReloadHandler
+func NewTemplatesReloadHandler(store *templates.Store) *TemplatesReloadHandler {
+	return &TemplatesReloadHandler{store: store}
+}
+
+// Tool returns the MCP tool definition
+func (h *TemplatesReloadHandler) Tool() mcp.Tool {
+	return mcp.NewTool("ReloadTemplates",
+		mcp.WithDescription("Reload message templates from the templates file after it was edited. Reports templates that failed validation."),
+		mcp.WithIdempotentHintAnnotation(true),
+	)
+}
+
+// Handle processes the ReloadTemplates tool request
+func (h *TemplatesReloadHandler) Handle(_ context.Context, _ mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	if h.store.Path() == "" {
+		return mcp.NewToolResultError(noTemplatesMessage), nil
+	}
+
+	problems, err := h.store.Load()
+	if err != nil {
+		return toolError("reload templates", err), nil
+	}
+
+	result := fmt.Sprintf("Loaded %d template(s) from %s", len(h.store.List()), h.store.Path())
+	if len(problems) > 0 {
+		msgs := make([]string, len(problems))
+		for i, p := range problems {
+			msgs[i] = p.Error()
+		}
+		result += fmt.Sprintf("\nSkipped %d invalid template(s):\n- %s", len(problems), strings.Join(msgs, "\n- "))
+	}
+	return mcp.NewToolResultText(result), nil
+}
@@ -0,0 +1,44 @@
+package tools
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestTemplateVariables(t *testing.T) {
+	tests := []struct {
+		name    string
+		raw     any
+		want    map[string]string
+		wantErr bool
+	}{
+		{name: "absent", raw: nil, want: nil},
+		{
+			name: "scalars",
+			raw:  map[string]any{"name": "Anna", "count": float64(3), "amount": 12.5, "paid": false, "note": nil, "n": json.Number("7")},
+			want: map[string]string{"name": "Anna", "count": "3", "amount": "12.5", "paid": "false", "note": "", "n": "7"},
+		},
+		{name: "not an object", raw: "name=Anna", wantErr: true},
+		{name: "nested object", raw: map[string]any{"user": map[string]any{"name": "Anna"}}, wantErr: true},
+		{name: "array", raw: map[string]any{"items": []any{"a"}}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := templateVariables(tt.raw)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected error, got %v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
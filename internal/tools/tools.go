@@ -13,22 +13,14 @@ type Handler interface {
 	Handle(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error)
 }
 
-// RegisterTools registers all handlers with the MCP server
-func RegisterTools(s *server.MCPServer, handlers []Handler) {
+// RegisterTools registers all handlers with the MCP server, wrapping each in the
+// given middlewares (the first one is outermost)
+func RegisterTools(s *server.MCPServer, handlers []Handler, middlewares ...server.ToolHandlerMiddleware) {
 	for _, h := range handlers {
-		s.AddTool(h.Tool(), h.Handle)
-	}
-}
-
-// truncateRunes truncates string to n runes without allocating a full []rune slice.
-// If the string is longer than n runes, it returns the first n runes followed by "...".
-func truncateRunes(s string, n int) string {
-	i := 0
-	for j := range s {
-		if i == n {
-			return s[:j] + "..."
+		handle := server.ToolHandlerFunc(h.Handle)
+		for i := len(middlewares) - 1; i >= 0; i-- {
+			handle = middlewares[i](handle)
 		}
-		i++
+		s.AddTool(h.Tool(), handle)
 	}
-	return s
 }
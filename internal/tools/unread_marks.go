@@ -0,0 +1,103 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gotd/td/tg"
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/tolmachov/mcp-telegram/internal/tgclient"
+	"github.com/tolmachov/mcp-telegram/internal/tgdata"
+)
+
+// unreadKind is a kind of unread mark, such as mentions or reactions, that a tool
+// lists by chat and can mark read.
+type unreadKind[T any] struct {
+	name  string                                                                    // e.g. "mentions", for errors
+	count func(marks tgdata.UnreadMarks) int                                        // Unread items of a chat
+	fetch func(ctx context.Context, peer tg.InputPeerClass, limit int) ([]T, error) // Newest first
+	read  func(ctx context.Context, peer tg.InputPeerClass) error                   // Marks the whole chat read
+}
+
+// unreadChat is what one chat has of an unread kind.
+type unreadChat[T any] struct {
+	chat       tgdata.ChatInfo
+	items      []T
+	markedRead bool
+	unreadLeft int // Unread items not fetched, so not marked read
+	err        string
+}
+
+// listUnread lists the unread items of kind in chatID, or in every chat that has any
+// when chatID is 0, and reports them grouped by chat as converted by group. With
+// markRead a chat is marked read only when limit covered all its unread items, since
+// marking read can't be limited to the fetched ones. A single requested chat that
+// fails is an error, not an empty report.
+func listUnread[T, C any](ctx context.Context, client *tg.Client, peers *tgclient.PeerCache, chatID int64, limit int, markRead bool, kind unreadKind[T], group func(unreadChat[T]) C) (*mcp.CallToolResult, error) {
+	var targets []tgdata.ChatInfo
+	if chatID != 0 {
+		targets = []tgdata.ChatInfo{{ID: chatID}}
+	} else {
+		chatsList, err := tgdata.GetChats(ctx, client, true, 0, nil)
+		if err != nil {
+			return toolError("get chats", err), nil
+		}
+		for _, chat := range chatsList.Chats {
+			if kind.count(tgdata.UnreadMarks{Mentions: chat.MentionCount, Reactions: chat.UnreadReactionsCount}) > 0 {
+				targets = append(targets, chat)
+			}
+		}
+	}
+
+	chats := make([]C, 0, len(targets))
+	for _, chat := range targets {
+		unread := chatUnread(ctx, client, peers, chat, limit, markRead, kind)
+		if chatID != 0 && unread.err != "" {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to get %s: %s", kind.name, unread.err)), nil
+		}
+		chats = append(chats, group(unread))
+	}
+
+	return jsonResult(map[string]any{
+		"chats": chats,
+		"count": len(chats),
+	})
+}
+
+// chatUnread fetches the unread items of kind in one chat, optionally marking them read.
+func chatUnread[T any](ctx context.Context, client *tg.Client, peers *tgclient.PeerCache, chat tgdata.ChatInfo, limit int, markRead bool, kind unreadKind[T]) unreadChat[T] {
+	result := unreadChat[T]{chat: chat}
+	err := peers.WithPeer(ctx, client, chat.ID, func(peer tg.InputPeerClass) error {
+		items, err := kind.fetch(ctx, peer, limit)
+		if err != nil {
+			return err
+		}
+		result.items = items
+		if !markRead || len(items) == 0 {
+			return nil
+		}
+
+		marks, err := tgdata.GetUnreadMarks(ctx, client, peer)
+		if err != nil {
+			return err
+		}
+		if result.unreadLeft = unreadLeft(len(items), kind.count(marks)); result.unreadLeft > 0 {
+			return nil
+		}
+		if err := kind.read(ctx, peer); err != nil {
+			return err
+		}
+		result.markedRead = true
+		return nil
+	})
+	if err != nil {
+		result.err = tgclient.ClassifyError(err).Error()
+	}
+	return result
+}
+
+// unreadLeft returns how many of a chat's unread items weren't fetched.
+func unreadLeft(fetched, unread int) int {
+	return max(unread-fetched, 0)
+}
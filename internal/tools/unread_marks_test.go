@@ -0,0 +1,19 @@
+package tools
+
+import "testing"
+
+func TestUnreadLeft(t *testing.T) {
+	tests := []struct {
+		fetched, unread, want int
+	}{
+		{fetched: 20, unread: 20, want: 0},
+		{fetched: 20, unread: 35, want: 15},
+		// Counted before a reaction was read elsewhere
+		{fetched: 5, unread: 3, want: 0},
+	}
+	for _, tt := range tests {
+		if got := unreadLeft(tt.fetched, tt.unread); got != tt.want {
+			t.Errorf("unreadLeft(%d, %d) = %d, want %d", tt.fetched, tt.unread, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,140 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/gotd/td/tg"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/tolmachov/mcp-telegram/internal/messages"
+	"github.com/tolmachov/mcp-telegram/internal/summarize"
+	"github.com/tolmachov/mcp-telegram/internal/tgclient"
+	"github.com/tolmachov/mcp-telegram/internal/tgdata"
+)
+
+const (
+	defaultUnreadDigestChats = 20
+	maxUnreadDigestChats     = 100
+	// defaultUnreadDigestGoal is the digest goal when none is given.
+	defaultUnreadDigestGoal = "a short briefing of what happened and what needs my attention or a reply"
+)
+
+// UnreadSummarizeHandler handles the SummarizeUnread tool
+type UnreadSummarizeHandler struct {
+	client      *tg.Client
+	msgProvider *messages.Provider
+	mcpServer   *server.MCPServer
+	config      summarize.Config
+}
+
+// NewUnreadSummarizeHandler creates a new UnreadSummarizeHandler
+func NewUnreadSummarizeHandler(client *tg.Client, msgProvider *messages.Provider, mcpServer *server.MCPServer, config summarize.Config) *UnreadSummarizeHandler {
+	return &UnreadSummarizeHandler{
+		client:      client,
+		msgProvider: msgProvider,
+		mcpServer:   mcpServer,
+		config:      config,
+	}
+}
+
+// Tool returns the MCP tool definition
+func (h *UnreadSummarizeHandler) Tool() mcp.Tool {
+	return mcp.NewTool("SummarizeUnread",
+		mcp.WithDescription("Summarize the unread messages of all chats into one digest grouped by chat, e.g. as a morning briefing. Chats are taken in chat list order; archived chats are left out. Chats that can't be read are skipped and listed at the end."),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithOpenWorldHintAnnotation(true),
+		mcp.WithString("goal",
+			mcp.Description(fmt.Sprintf("What you want from the digest (default: '%s')", defaultUnreadDigestGoal)),
+		),
+		mcp.WithNumber("max_chats",
+			mcp.Description(fmt.Sprintf("Maximum chats with unread messages to include (default: %d, max: %d)", defaultUnreadDigestChats, maxUnreadDigestChats)),
+		),
+		mcp.WithBoolean("include_muted",
+			mcp.Description("Include muted chats (default: false)"),
+		),
+	)
+}
+
+// Handle processes the SummarizeUnread tool request
+func (h *UnreadSummarizeHandler) Handle(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	maxChats := mcp.ParseInt(request, "max_chats", defaultUnreadDigestChats)
+	if maxChats <= 0 || maxChats > maxUnreadDigestChats {
+		return mcp.NewToolResultError(fmt.Sprintf("max_chats must be between 1 and %d", maxUnreadDigestChats)), nil
+	}
+	goal := mcp.ParseString(request, "goal", defaultUnreadDigestGoal)
+	includeMuted := mcp.ParseBoolean(request, "include_muted", false)
+
+	list, err := tgdata.GetChats(ctx, h.client, false, 0, nil)
+	if err != nil {
+		return toolError("list chats", err), nil
+	}
+	chats := unreadChats(list.Chats, maxChats, includeMuted)
+	if len(chats) == 0 {
+		return mcp.NewToolResultText("No unread messages."), nil
+	}
+
+	onProgress := func(current, total int, message string) {
+		if srv := server.ServerFromContext(ctx); srv != nil {
+			_ = srv.SendNotificationToClient(ctx, "notifications/progress", map[string]any{
+				"progress": current,
+				"total":    total,
+				"message":  message,
+			})
+		}
+	}
+
+	// A chat that fails, e.g. one deleted since the list was fetched, is skipped
+	var digestChats []summarize.DigestChat
+	var skipped []string
+	for i, chat := range chats {
+		if ctx.Err() != nil {
+			return toolError("fetch unread messages", ctx.Err()), nil
+		}
+		onProgress(i+1, len(chats), fmt.Sprintf("Fetching unread messages of %s (chat %d/%d)", chat.Name, i+1, len(chats)))
+		result, err := h.msgProvider.Fetch(ctx, chat.ID, messages.FetchOptions{
+			Limit:      min(chat.UnreadCount, messages.MaxFetchLimit),
+			UnreadOnly: true,
+		})
+		if err != nil {
+			skipped = append(skipped, fmt.Sprintf("%s (%d): %v", chat.Name, chat.ID, tgclient.ClassifyError(err)))
+			continue
+		}
+		messages.Reverse(result.Messages)
+		digestChats = append(digestChats, summarize.DigestChat{Name: chat.Name, Messages: result.Messages})
+	}
+
+	summarizer := summarize.NewSummarizer(newSummarizeProvider(h.mcpServer, h.config), h.msgProvider, h.config.BatchTokens)
+	digest, err := summarizer.Digest(ctx, digestChats, summarize.Options{
+		Goal:             goal,
+		MergeConsecutive: true,
+		MergeGap:         h.config.MergeGap,
+		Deadline:         h.config.Deadline,
+	}, onProgress)
+	if err != nil {
+		return toolError("summarize unread messages", err), nil
+	}
+
+	text := digest.Summary + fmt.Sprintf("\n\n[Digest of %d chats with unread messages]", digest.Chats)
+	if len(skipped) > 0 {
+		text += "\n[Skipped chats:\n- " + strings.Join(skipped, "\n- ") + "]"
+	}
+	return mcp.NewToolResultText(text), nil
+}
+
+// unreadChats returns up to maxChats chats with unread messages, in list order.
+func unreadChats(chats []tgdata.ChatInfo, maxChats int, includeMuted bool) []tgdata.ChatInfo {
+	var unread []tgdata.ChatInfo
+	for _, chat := range chats {
+		if chat.UnreadCount == 0 || (chat.Muted && !includeMuted) {
+			continue
+		}
+		unread = append(unread, chat)
+		if len(unread) >= maxChats {
+			break
+		}
+	}
+	return unread
+}
@@ -0,0 +1,32 @@
+package tools
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/tolmachov/mcp-telegram/internal/tgdata"
+)
+
+func TestUnreadChats(t *testing.T) {
+	chats := []tgdata.ChatInfo{
+		{ID: 1, Name: "Read", UnreadCount: 0},
+		{ID: 2, Name: "Team", UnreadCount: 5},
+		{ID: 3, Name: "Muted", UnreadCount: 40, Muted: true},
+		{ID: 4, Name: "Family", UnreadCount: 1},
+		{ID: 5, Name: "News", UnreadCount: 3},
+	}
+
+	ids := func(chats []tgdata.ChatInfo) []int64 {
+		var ids []int64
+		for _, chat := range chats {
+			ids = append(ids, chat.ID)
+		}
+		return ids
+	}
+	if got := ids(unreadChats(chats, 2, false)); !reflect.DeepEqual(got, []int64{2, 4}) {
+		t.Errorf("without muted = %v, want [2 4]", got)
+	}
+	if got := ids(unreadChats(chats, 10, true)); !reflect.DeepEqual(got, []int64{2, 3, 4, 5}) {
+		t.Errorf("with muted = %v, want [2 3 4 5]", got)
+	}
+}
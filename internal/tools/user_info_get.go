@@ -0,0 +1,98 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/gotd/td/tg"
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/tolmachov/mcp-telegram/internal/tgclient"
+	"github.com/tolmachov/mcp-telegram/internal/tgdata"
+)
+
+// UserInfoGetHandler handles the GetUserInfo tool
+type UserInfoGetHandler struct {
+	client *tg.Client
+	peers  *tgclient.PeerCache
+}
+
+// NewUserInfoGetHandler creates a new UserInfoGetHandler
+func NewUserInfoGetHandler(client *tg.Client, peers *tgclient.PeerCache) *UserInfoGetHandler {
+	return &UserInfoGetHandler{client: client, peers: peers}
+}
+
+// Tool returns the MCP tool definition
+func (h *UserInfoGetHandler) Tool() mcp.Tool {
+	return mcp.NewTool("GetUserInfo",
+		mcp.WithDescription("Get the full profile of a user: bio, phone (if visible to you), all usernames including collectible ones, last seen, common chats count, bot/verified/premium/scam flags, whether a profile photo is set and whether you blocked the user."),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithNumber("user_id",
+			mcp.Description("The ID of the user (or use username)"),
+		),
+		mcp.WithString("username",
+			mcp.Description("The user's username, with or without @, instead of user_id"),
+		),
+	)
+}
+
+// Handle processes the GetUserInfo tool request
+func (h *UserInfoGetHandler) Handle(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	userID := mcp.ParseInt64(request, "user_id", 0)
+	username := strings.TrimPrefix(mcp.ParseString(request, "username", ""), "@")
+
+	var user tg.InputUserClass
+	switch {
+	case userID > 0:
+		peer, err := h.peers.ResolvePeer(ctx, h.client, userID)
+		if err != nil {
+			return toolError(fmt.Sprintf("resolve user %d", userID), err), nil
+		}
+		p, ok := peer.(*tg.InputPeerUser)
+		if !ok {
+			return mcp.NewToolResultError(fmt.Sprintf("%d is not a user; use GetChatInfo for groups and channels", userID)), nil
+		}
+		user = &tg.InputUser{UserID: p.UserID, AccessHash: p.AccessHash}
+	case userID < 0:
+		return mcp.NewToolResultError(fmt.Sprintf("%d is not a user; use GetChatInfo for groups and channels", userID)), nil
+	case username != "":
+		resolved, err := resolveUsername(ctx, h.client, username)
+		if err != nil {
+			return toolError("resolve username @"+username, err), nil
+		}
+		u, ok := resolvedUser(resolved)
+		if !ok {
+			return mcp.NewToolResultError(fmt.Sprintf("@%s is not a user; use GetChatInfo for groups and channels", username)), nil
+		}
+		user = u.AsInput()
+	default:
+		return mcp.NewToolResultError("user_id or username is required"), nil
+	}
+
+	info, err := tgdata.GetUserInfo(ctx, h.client, user)
+	if err != nil {
+		return toolError("get user info", err), nil
+	}
+
+	data, err := json.MarshalIndent(info, "", "  ")
+	if err != nil {
+		return toolError("marshal user info", err), nil
+	}
+	return mcp.NewToolResultText(string(data)), nil
+}
+
+// resolvedUser returns the user a username resolved to, reporting false for chats.
+func resolvedUser(resolved *tg.ContactsResolvedPeer) (*tg.User, bool) {
+	p, ok := resolved.Peer.(*tg.PeerUser)
+	if !ok {
+		return nil, false
+	}
+	for _, u := range resolved.Users {
+		if user, ok := u.(*tg.User); ok && user.ID == p.UserID {
+			return user, true
+		}
+	}
+	return nil, false
+}
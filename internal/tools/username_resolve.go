@@ -41,12 +41,9 @@ func (h *UsernameResolveHandler) Handle(ctx context.Context, request mcp.CallToo
 	// Remove @ prefix if present
 	username = strings.TrimPrefix(username, "@")
 
-	// Resolve username
-	resolved, err := h.client.ContactsResolveUsername(ctx, &tg.ContactsResolveUsernameRequest{
-		Username: username,
-	})
+	resolved, err := resolveUsername(ctx, h.client, username)
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Failed to resolve username @%s: %v", username, err)), nil
+		return toolError("resolve username @"+username, err), nil
 	}
 
 	var results []string
@@ -111,3 +108,10 @@ func (h *UsernameResolveHandler) Handle(ctx context.Context, request mcp.CallToo
 
 	return mcp.NewToolResultText(strings.Join(results, "\n")), nil
 }
+
+// resolveUsername resolves a username given with or without the @ prefix.
+func resolveUsername(ctx context.Context, client *tg.Client, username string) (*tg.ContactsResolvedPeer, error) {
+	return client.ContactsResolveUsername(ctx, &tg.ContactsResolveUsernameRequest{
+		Username: strings.TrimPrefix(username, "@"),
+	})
+}
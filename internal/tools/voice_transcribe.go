@@ -0,0 +1,154 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/gotd/td/tg"
+	"github.com/gotd/td/tgerr"
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/tolmachov/mcp-telegram/internal/messages"
+	"github.com/tolmachov/mcp-telegram/internal/summarize"
+	"github.com/tolmachov/mcp-telegram/internal/tgclient"
+	"github.com/tolmachov/mcp-telegram/internal/tgdata"
+)
+
+// maxTranscribeBytes is the largest voice message sent to a transcription endpoint,
+// OpenAI's upload limit.
+const maxTranscribeBytes = 25 << 20
+
+// voiceTranscription transcribes voice messages with Telegram or, when configured,
+// an OpenAI-compatible endpoint.
+type voiceTranscription struct {
+	client *tg.Client
+	peers  *tgclient.PeerCache
+	dcs    tgclient.DCConnector
+	config summarize.Config
+}
+
+// transcribe returns the transcript of a voice message of a chat.
+func (v voiceTranscription) transcribe(ctx context.Context, chatID int64, msg messages.Message) (string, error) {
+	if msg.Media == nil || msg.Media.Type != messages.MediaVoice {
+		return "", fmt.Errorf("message %d is not a voice message", msg.ID)
+	}
+	transcriber, err := summarize.NewTranscriber(v.config)
+	if err != nil {
+		return "", err
+	}
+
+	if transcriber == nil {
+		var text string
+		err := v.peers.WithPeer(ctx, v.client, chatID, func(peer tg.InputPeerClass) error {
+			var err error
+			text, err = tgdata.TranscribeAudio(ctx, v.client, peer, msg.ID)
+			return err
+		})
+		return text, err
+	}
+
+	if msg.Media.Size > maxTranscribeBytes {
+		return "", fmt.Errorf("voice message %d is %s, larger than the %d MB transcription limit", msg.ID, formatFileSize(msg.Media.Size), maxTranscribeBytes>>20)
+	}
+	ref, err := parseMediaURI(msg.Media.ResourceURI)
+	if err != nil {
+		return "", fmt.Errorf("voice message %d can't be downloaded: %w", msg.ID, err)
+	}
+	buf := &cappedBuffer{limit: maxTranscribeBytes}
+	if err := downloadFile(ctx, v.client, v.dcs, ref.location(), buf); err != nil {
+		return "", fmt.Errorf("downloading voice message %d: %w", msg.ID, err)
+	}
+	return transcriber.Transcribe(ctx, mediaFilename(ref), buf.Bytes())
+}
+
+// VoiceTranscribeHandler handles the TranscribeVoice tool
+type VoiceTranscribeHandler struct {
+	client   *tg.Client
+	provider *messages.Provider
+	voice    voiceTranscription
+}
+
+// NewVoiceTranscribeHandler creates a new VoiceTranscribeHandler. config selects
+// Telegram's transcription or an OpenAI-compatible endpoint.
+func NewVoiceTranscribeHandler(client *tg.Client, dcs tgclient.DCConnector, provider *messages.Provider, config summarize.Config) *VoiceTranscribeHandler {
+	return &VoiceTranscribeHandler{
+		client:   client,
+		provider: provider,
+		voice:    voiceTranscription{client: client, peers: provider.Peers(), dcs: dcs, config: config},
+	}
+}
+
+// Tool returns the MCP tool definition
+func (h *VoiceTranscribeHandler) Tool() mcp.Tool {
+	return mcp.NewTool("TranscribeVoice",
+		mcp.WithDescription("Transcribe a voice message to text, with Telegram's own transcription (Premium, or a few free trials) or the configured OpenAI-compatible transcription endpoint."),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithNumber("chat_id",
+			mcp.Description("The ID of the chat with the voice message (or use chat)"),
+		),
+		chatRefOption("chat", "chat_id", "The chat with the voice message"),
+		mcp.WithNumber("message_id",
+			mcp.Description("The ID of the voice message"),
+			mcp.Required(),
+		),
+	)
+}
+
+// Handle processes the TranscribeVoice tool request
+func (h *VoiceTranscribeHandler) Handle(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	chatID, errResult := chatIDParam(ctx, h.client, h.provider.Peers(), request, "chat_id", "chat")
+	if errResult != nil {
+		return errResult, nil
+	}
+	msgID := mcp.ParseInt(request, "message_id", 0)
+	if msgID <= 0 {
+		return mcp.NewToolResultError("message_id is required"), nil
+	}
+
+	result, err := h.provider.FetchByIDs(ctx, chatID, []int{msgID})
+	if err != nil {
+		return toolError("get message", err), nil
+	}
+	if len(result.Messages) == 0 {
+		return mcp.NewToolResultError(fmt.Sprintf("Message %d not found in chat %d", msgID, chatID)), nil
+	}
+	msg := result.Messages[0]
+	if msg.Media == nil || msg.Media.Type != messages.MediaVoice {
+		return mcp.NewToolResultError(fmt.Sprintf("Message %d is not a voice message", msgID)), nil
+	}
+
+	text, err := h.voice.transcribe(ctx, chatID, msg)
+	if err != nil {
+		return transcribeError(err), nil
+	}
+	return mcp.NewToolResultText(transcriptText(msg, text)), nil
+}
+
+// transcribeError explains failures of Telegram's transcription, pointing free
+// accounts at the transcription endpoint.
+func transcribeError(err error) *mcp.CallToolResult {
+	switch {
+	case errors.Is(err, tgdata.ErrTranscriptionPending):
+		return mcp.NewToolResultError(err.Error())
+	case tgerr.Is(err, "PREMIUM_ACCOUNT_REQUIRED"):
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to transcribe voice message: %v. Telegram's transcription needs Premium once the free trials are used up; set TRANSCRIBE_PROVIDER=openai to use an OpenAI-compatible endpoint instead", tgclient.ClassifyError(err)))
+	case tgerr.Is(err, "TRANSCRIPTION_FAILED"):
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to transcribe voice message: %v, or set TRANSCRIBE_PROVIDER=openai to use an OpenAI-compatible endpoint", tgclient.ClassifyError(err)))
+	}
+	return toolError("transcribe voice message", err)
+}
+
+// transcriptText presents the transcript of a voice message.
+func transcriptText(msg messages.Message, text string) string {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		text = "(no speech recognized)"
+	}
+	header := fmt.Sprintf("Voice message %d from %s", msg.ID, msg.SenderName)
+	if msg.Media.Duration > 0 {
+		header += fmt.Sprintf(" (%ds)", msg.Media.Duration)
+	}
+	return header + ":\n" + text
+}
@@ -0,0 +1,60 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/tolmachov/mcp-telegram/internal/messages"
+)
+
+func TestTranscribeBatch(t *testing.T) {
+	voice := &messages.MediaInfo{Type: messages.MediaVoice}
+	batch := []messages.Message{
+		{ID: 1, Text: "hi"},
+		{ID: 2, Media: voice},
+		{ID: 3, Text: "listen", Media: voice},
+		{ID: 4, Media: &messages.MediaInfo{Type: "photo"}},
+		{ID: 5, Media: voice},
+		{ID: 6, Media: voice},
+	}
+	errQuota := errors.New("quota exceeded")
+	var calls []int
+	transcribe := func(_ context.Context, msg messages.Message) (string, error) {
+		calls = append(calls, msg.ID)
+		if msg.ID == 5 {
+			return "", errQuota
+		}
+		return " words ", nil
+	}
+
+	var backup chatBackupResult
+	transcribeBatch(context.Background(), batch, transcribe, &backup)
+
+	if got := batch[1].Text; got != "[voice transcript] words" {
+		t.Errorf("voice without text = %q", got)
+	}
+	if got := batch[2].Text; got != "listen\n[voice transcript] words" {
+		t.Errorf("voice with caption = %q", got)
+	}
+	if batch[0].Text != "hi" || batch[3].Text != "" || batch[4].Text != "" || batch[5].Text != "" {
+		t.Errorf("untouched messages changed: %+v", batch)
+	}
+	if len(calls) != 3 {
+		t.Errorf("transcribed messages %v, want the voice messages up to the first failure", calls)
+	}
+	if backup.Transcribed != 2 || backup.Untranscribed != 2 || !errors.Is(backup.TranscribeErr, errQuota) {
+		t.Errorf("backup = %+v", backup)
+	}
+}
+
+func TestTranscriptText(t *testing.T) {
+	msg := messages.Message{ID: 7, SenderName: "Anna", Media: &messages.MediaInfo{Type: messages.MediaVoice, Duration: 12}}
+	if got, want := transcriptText(msg, " see you at five "), "Voice message 7 from Anna (12s):\nsee you at five"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	msg.Media.Duration = 0
+	if got, want := transcriptText(msg, ""), "Voice message 7 from Anna:\n(no speech recognized)"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,196 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gotd/td/tg"
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/tolmachov/mcp-telegram/internal/tgclient"
+	"github.com/tolmachov/mcp-telegram/internal/tgdata"
+)
+
+// IdentityReport is the consolidated result of a WhoIs lookup.
+type IdentityReport struct {
+	ChatID           int64    `json:"chat_id"`
+	Type             string   `json:"type"`
+	Name             string   `json:"name"`
+	Username         string   `json:"username,omitempty"`
+	Phone            string   `json:"phone,omitempty"`
+	Bio              string   `json:"bio,omitempty"`
+	Bot              bool     `json:"bot,omitempty"`
+	Verified         bool     `json:"verified,omitempty"`
+	Premium          bool     `json:"premium,omitempty"`
+	Scam             bool     `json:"scam,omitempty"`
+	Fake             bool     `json:"fake,omitempty"`
+	Contact          string   `json:"contact_status,omitempty"`
+	LastSeen         string   `json:"last_seen,omitempty"`
+	CommonChatsCount *int     `json:"common_chats_count,omitempty"`
+	MembersCount     int      `json:"members_count,omitempty"`
+	Warnings         []string `json:"warnings,omitempty"`
+}
+
+// WhoIsHandler handles the WhoIs tool
+type WhoIsHandler struct {
+	client *tg.Client
+	peers  *tgclient.PeerCache
+}
+
+// NewWhoIsHandler creates a new WhoIsHandler
+func NewWhoIsHandler(client *tg.Client, peers *tgclient.PeerCache) *WhoIsHandler {
+	return &WhoIsHandler{client: client, peers: peers}
+}
+
+// Tool returns the MCP tool definition
+func (h *WhoIsHandler) Tool() mcp.Tool {
+	return mcp.NewTool("WhoIs",
+		mcp.WithDescription("Look up a user, group or channel by numeric ID, @username, phone number or t.me link and return one identity report, including the chat_id to use with other tools."),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithString("identifier",
+			mcp.Description("Numeric ID, @username, phone number with country code (+...), or t.me link"),
+			mcp.Required(),
+		),
+	)
+}
+
+// Handle processes the WhoIs tool request
+func (h *WhoIsHandler) Handle(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	raw := mcp.ParseString(request, "identifier", "")
+	if raw == "" {
+		return mcp.NewToolResultError("identifier is required"), nil
+	}
+
+	ident, err := tgclient.ParseChatRef(raw)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Invalid identifier: %v", err)), nil
+	}
+
+	var report *IdentityReport
+	switch ident.Kind {
+	case tgclient.ChatRefUsername:
+		resolved, err := h.client.ContactsResolveUsername(ctx, &tg.ContactsResolveUsernameRequest{Username: ident.Username})
+		if err != nil {
+			return toolError("resolve username @"+ident.Username, err), nil
+		}
+		report, err = h.reportResolved(ctx, resolved)
+		if err != nil {
+			return toolError("look up @"+ident.Username, err), nil
+		}
+	case tgclient.ChatRefPhone:
+		resolved, err := h.client.ContactsResolvePhone(ctx, ident.Phone)
+		if err != nil {
+			return toolError("resolve phone number", err), nil
+		}
+		report, err = h.reportResolved(ctx, resolved)
+		if err != nil {
+			return toolError("look up phone number", err), nil
+		}
+	case tgclient.ChatRefSelf:
+		selfID, _, err := h.peers.ResolvePeerFlexible(ctx, h.client, raw)
+		if err != nil {
+			return toolError("resolve own user", err), nil
+		}
+		report, err = h.reportByID(ctx, selfID)
+		if err != nil {
+			return toolError("look up own user", err), nil
+		}
+	case tgclient.ChatRefID:
+		report, err = h.reportByID(ctx, ident.ID)
+		if err != nil {
+			return toolError(fmt.Sprintf("look up chat %d", ident.ID), err), nil
+		}
+	}
+
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return toolError("marshal identity report", err), nil
+	}
+
+	return mcp.NewToolResultText(string(data)), nil
+}
+
+// reportResolved builds a report for the peer returned by a username or phone lookup.
+func (h *WhoIsHandler) reportResolved(ctx context.Context, resolved *tg.ContactsResolvedPeer) (*IdentityReport, error) {
+	switch p := resolved.Peer.(type) {
+	case *tg.PeerUser:
+		for _, u := range resolved.Users {
+			if user, ok := u.(*tg.User); ok && user.ID == p.UserID {
+				return h.reportUser(ctx, user), nil
+			}
+		}
+		return nil, fmt.Errorf("user %d missing from response", p.UserID)
+	case *tg.PeerChannel:
+		return h.reportByID(ctx, tgclient.ChannelDialogID(p.ChannelID))
+	case *tg.PeerChat:
+		return h.reportByID(ctx, p.ChatID)
+	default:
+		return nil, fmt.Errorf("unexpected peer type %T", resolved.Peer)
+	}
+}
+
+// reportByID builds a report for a user-facing chat ID.
+func (h *WhoIsHandler) reportByID(ctx context.Context, chatID int64) (*IdentityReport, error) {
+	if chatID > 0 {
+		users, err := h.client.UsersGetUsers(ctx, []tg.InputUserClass{&tg.InputUser{UserID: chatID}})
+		if err == nil && len(users) > 0 {
+			if user, ok := users[0].(*tg.User); ok {
+				return h.reportUser(ctx, user), nil
+			}
+		}
+		// Not a known user: positive IDs may also be basic groups
+	}
+
+	info, err := tgdata.GetChatInfo(ctx, h.client, h.peers, chatID)
+	if err != nil {
+		return nil, err
+	}
+	if info.Name == "" {
+		return nil, fmt.Errorf("%s: no user or chat with ID %d", tgclient.PeerNotFoundMessage, chatID)
+	}
+	return &IdentityReport{
+		ChatID:       chatID,
+		Type:         info.Type,
+		Name:         info.Name,
+		Username:     info.Username,
+		Bio:          info.Description,
+		MembersCount: info.MembersCount,
+	}, nil
+}
+
+// reportUser builds a report for a user, adding full-profile details when available.
+func (h *WhoIsHandler) reportUser(ctx context.Context, user *tg.User) *IdentityReport {
+	report := &IdentityReport{
+		ChatID:   user.ID,
+		Type:     "user",
+		Name:     strings.TrimSpace(user.FirstName + " " + user.LastName),
+		Username: user.Username,
+		Phone:    user.Phone,
+		Bot:      user.Bot,
+		Verified: user.Verified,
+		Premium:  user.Premium,
+		Scam:     user.Scam,
+		Fake:     user.Fake,
+		Contact:  tgdata.ContactStatus(user),
+		LastSeen: tgdata.LastSeen(user.Status, time.Now()),
+	}
+	if user.Bot {
+		report.Type = "bot"
+	}
+	if report.Name == "" {
+		report.Name = tgclient.UserName(user)
+	}
+
+	full, err := h.client.UsersGetFullUser(ctx, &tg.InputUser{UserID: user.ID, AccessHash: user.AccessHash})
+	if err != nil {
+		report.Warnings = append(report.Warnings, fmt.Sprintf("bio and common chats unavailable: %v", tgclient.ClassifyError(err)))
+		return report
+	}
+	report.Bio = full.FullUser.About
+	common := full.FullUser.CommonChatsCount
+	report.CommonChatsCount = &common
+	return report
+}
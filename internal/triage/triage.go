@@ -0,0 +1,223 @@
+// Package triage finds messages that need my attention: questions in replies to my
+// messages that I haven't answered, and my messages that received negative reactions.
+package triage
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/gotd/td/tg"
+
+	"github.com/tolmachov/mcp-telegram/internal/messages"
+)
+
+// Reason is why a message was flagged.
+type Reason string
+
+const (
+	// ReasonUnansweredQuestion is a question replying to my message with no later message from me.
+	ReasonUnansweredQuestion Reason = "unanswered_question"
+	// ReasonNegativeReactions is my message that received negative reactions.
+	ReasonNegativeReactions Reason = "negative_reactions"
+)
+
+// Sentiment groups reactions by the attitude they usually express.
+type Sentiment int
+
+const (
+	Neutral Sentiment = iota
+	Positive
+	Negative
+)
+
+// negativeReactions are the 👎/😡-class reactions: disapproval, anger, disgust and mockery.
+var negativeReactions = map[string]bool{
+	"👎": true, "😡": true, "🤬": true, "💩": true, "🤮": true, "🖕": true, "🤡": true,
+}
+
+var positiveReactions = map[string]bool{
+	"👍": true, "❤": true, "❤️": true, "🔥": true, "🥰": true, "👏": true, "😁": true,
+	"🎉": true, "🤩": true, "🙏": true, "👌": true, "💯": true, "😍": true, "❤‍🔥": true,
+	"🏆": true, "🤝": true, "🫡": true, "⚡": true,
+}
+
+// ReactionSentiment returns the sentiment of an emoji reaction.
+func ReactionSentiment(emoji string) Sentiment {
+	switch {
+	case negativeReactions[emoji]:
+		return Negative
+	case positiveReactions[emoji]:
+		return Positive
+	default:
+		return Neutral
+	}
+}
+
+// Flag is a message that needs attention.
+type Flag struct {
+	Message messages.Message
+	Reason  Reason
+	// Negative counts negative reactions by emoji, for ReasonNegativeReactions
+	Negative map[string]int
+	// Positive is the number of positive reactions on the same message
+	Positive int
+}
+
+// NegativeCount is the total number of negative reactions.
+func (f Flag) NegativeCount() int {
+	n := 0
+	for _, c := range f.Negative {
+		n += c
+	}
+	return n
+}
+
+// Scan flags the messages of one chat that need attention. Messages can be in any
+// order. Context messages are only used to recognize replies to my messages.
+func Scan(msgs []messages.Message) []Flag {
+	mine := make(map[int]bool)
+	lastOut := 0
+	for _, msg := range msgs {
+		if isOutgoing(msg) {
+			mine[msg.ID] = true
+			if !msg.Context {
+				lastOut = max(lastOut, msg.ID)
+			}
+		}
+	}
+
+	var flags []Flag
+	for _, msg := range msgs {
+		if msg.Context {
+			continue
+		}
+		if isOutgoing(msg) {
+			if f, ok := reactionFlag(msg); ok {
+				flags = append(flags, f)
+			}
+			continue
+		}
+		if msg.ReplyToID != 0 && mine[msg.ReplyToID] && msg.ID > lastOut && IsQuestion(msg.Text) {
+			flags = append(flags, Flag{Message: msg, Reason: ReasonUnansweredQuestion})
+		}
+	}
+	return flags
+}
+
+// MissingReplyTargets returns the IDs of messages that incoming questions reply to but
+// that are not among msgs, so it can't be told whether they are mine.
+func MissingReplyTargets(msgs []messages.Message) []int {
+	known := make(map[int]bool, len(msgs))
+	for _, msg := range msgs {
+		known[msg.ID] = true
+	}
+	var ids []int
+	for _, msg := range msgs {
+		if msg.ReplyToID == 0 || known[msg.ReplyToID] || isOutgoing(msg) || !IsQuestion(msg.Text) {
+			continue
+		}
+		known[msg.ReplyToID] = true
+		ids = append(ids, msg.ReplyToID)
+	}
+	return ids
+}
+
+// Prioritize orders flags by urgency, see Compare.
+func Prioritize(flags []Flag) {
+	slices.SortStableFunc(flags, Compare)
+}
+
+// Compare orders flags by urgency: unanswered questions first, oldest first, then
+// messages with negative reactions, most negative first.
+func Compare(a, b Flag) int {
+	if a.Reason != b.Reason {
+		if a.Reason == ReasonUnansweredQuestion {
+			return -1
+		}
+		return 1
+	}
+	if a.Reason == ReasonNegativeReactions {
+		if c := cmp.Compare(b.NegativeCount(), a.NegativeCount()); c != 0 {
+			return c
+		}
+		return b.Message.Date.Compare(a.Message.Date)
+	}
+	return a.Message.Date.Compare(b.Message.Date)
+}
+
+// reactionFlag flags my message if it received negative reactions.
+func reactionFlag(msg messages.Message) (Flag, bool) {
+	if msg.Raw == nil {
+		return Flag{}, false
+	}
+	f := Flag{Message: msg, Reason: ReasonNegativeReactions}
+	for _, rc := range msg.Raw.Reactions.Results {
+		emoji, ok := rc.Reaction.(*tg.ReactionEmoji)
+		if !ok {
+			continue
+		}
+		switch ReactionSentiment(emoji.Emoticon) {
+		case Negative:
+			if f.Negative == nil {
+				f.Negative = make(map[string]int)
+			}
+			f.Negative[emoji.Emoticon] += rc.Count
+		case Positive:
+			f.Positive += rc.Count
+		}
+	}
+	return f, len(f.Negative) > 0
+}
+
+func isOutgoing(msg messages.Message) bool {
+	return msg.Raw != nil && msg.Raw.Out
+}
+
+// questionMarks are the question marks used across scripts, including the fullwidth
+// and Arabic ones.
+const questionMarks = "?？؟"
+
+// IsQuestion reports whether any sentence of text ends with a question mark. A question
+// mark may be followed by more ?, !, dots, brackets and emoji, as in "ну что?))" or
+// "really?!", but not by a letter or digit, so URLs and "a?b" don't count.
+func IsQuestion(text string) bool {
+	runes := []rune(stripURLs(text))
+	for i, r := range runes {
+		if !strings.ContainsRune(questionMarks, r) {
+			continue
+		}
+		j := i + 1
+		for j < len(runes) && isQuestionTail(runes[j]) {
+			j++
+		}
+		if j == len(runes) || unicode.IsSpace(runes[j]) {
+			return true
+		}
+	}
+	return false
+}
+
+// isQuestionTail reports whether r may follow a sentence-ending question mark.
+func isQuestionTail(r rune) bool {
+	if strings.ContainsRune(questionMarks, r) || strings.ContainsRune("!.…)(]»\"'”", r) {
+		return true
+	}
+	// Emoji and other symbols, e.g. "are you coming?🙂"
+	return unicode.Is(unicode.So, r) || unicode.Is(unicode.Sk, r) || r == '\u200d' || r == '\ufe0f'
+}
+
+// stripURLs removes words that look like links, whose query strings contain "?".
+func stripURLs(text string) string {
+	words := strings.Fields(text)
+	kept := words[:0]
+	for _, w := range words {
+		lower := strings.ToLower(w)
+		if strings.Contains(lower, "://") || strings.HasPrefix(lower, "www.") || strings.HasPrefix(lower, "t.me/") {
+			continue
+		}
+		kept = append(kept, w)
+	}
+	return strings.Join(kept, " ")
+}
@@ -0,0 +1,131 @@
+package triage
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/gotd/td/tg"
+
+	"github.com/tolmachov/mcp-telegram/internal/messages"
+)
+
+func TestIsQuestion(t *testing.T) {
+	tests := []struct {
+		text string
+		want bool
+	}{
+		{"Can you check it?", true},
+		{"?", true},
+		{"Ты придешь завтра?", true},
+		{"ну что?))", true},
+		{"как дела ?", true},
+		{"Серьёзно?!", true},
+		{"are you coming?🙂", true},
+		{"Did you see the report? Let me know by Friday.", true},
+		{"Ты видел? Я отправил вчера", true},
+		{"本当ですか？", true},
+		{"Thanks, got it.", false},
+		{"Ок, завтра обсудим", false},
+		{"see https://example.com/page?id=5", false},
+		{"x?y is a placeholder", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		if got := IsQuestion(tt.text); got != tt.want {
+			t.Errorf("IsQuestion(%q) = %v, want %v", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestReactionSentiment(t *testing.T) {
+	for emoji, want := range map[string]Sentiment{"👎": Negative, "😡": Negative, "👍": Positive, "🤔": Neutral} {
+		if got := ReactionSentiment(emoji); got != want {
+			t.Errorf("ReactionSentiment(%s) = %v, want %v", emoji, got, want)
+		}
+	}
+}
+
+func out(id int, text string) messages.Message {
+	return messages.Message{ID: id, Text: text, Raw: &tg.Message{ID: id, Out: true}}
+}
+
+func in(id, replyTo int, text string) messages.Message {
+	return messages.Message{ID: id, ReplyToID: replyTo, Text: text, Raw: &tg.Message{ID: id}}
+}
+
+func reacted(msg messages.Message, counts map[string]int) messages.Message {
+	for emoji, n := range counts {
+		msg.Raw.Reactions.Results = append(msg.Raw.Reactions.Results, tg.ReactionCount{Reaction: &tg.ReactionEmoji{Emoticon: emoji}, Count: n})
+	}
+	return msg
+}
+
+func TestScan(t *testing.T) {
+	old := out(1, "old proposal")
+	old.Context = true
+
+	msgs := []messages.Message{
+		in(9, 1, "What about the old proposal?"),
+		out(2, "Deploy is done"),
+		in(3, 2, "Did it pass the tests?"),
+		in(4, 2, "Great, thanks"),
+		in(5, 8, "Who is on call?"), // Reply to someone else
+		reacted(out(6, "Let's move the meeting"), map[string]int{"👎": 2, "😡": 1, "👍": 3}),
+		reacted(out(7, "Lunch at noon"), map[string]int{"👍": 5}),
+		old,
+	}
+
+	flags := Scan(msgs)
+	got := make(map[int]Reason)
+	for _, f := range flags {
+		got[f.Message.ID] = f.Reason
+		if f.Reason == ReasonNegativeReactions {
+			if want := map[string]int{"👎": 2, "😡": 1}; !reflect.DeepEqual(f.Negative, want) || f.Positive != 3 {
+				t.Errorf("flag for %d: Negative = %v, Positive = %d", f.Message.ID, f.Negative, f.Positive)
+			}
+		}
+	}
+	// Message 3 is answered by my later message 6; the question replying to a context
+	// message counts since it came after everything I wrote.
+	want := map[int]Reason{9: ReasonUnansweredQuestion, 6: ReasonNegativeReactions}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Scan() flagged %v, want %v", got, want)
+	}
+}
+
+func TestMissingReplyTargets(t *testing.T) {
+	msgs := []messages.Message{
+		in(10, 3, "Is it ready?"),
+		in(11, 3, "Hello?"),      // Same target once
+		in(12, 4, "Nice"),        // Not a question
+		in(13, 10, "Why?"),       // Target is known
+		out(14, "Anyone there?"), // My own question
+	}
+	if got, want := MissingReplyTargets(msgs), []int{3}; !reflect.DeepEqual(got, want) {
+		t.Errorf("MissingReplyTargets() = %v, want %v", got, want)
+	}
+}
+
+func TestPrioritize(t *testing.T) {
+	now := time.Now()
+	at := func(msg messages.Message, ago time.Duration) messages.Message {
+		msg.Date = now.Add(-ago)
+		return msg
+	}
+	flags := []Flag{
+		{Message: at(out(1, ""), time.Hour), Reason: ReasonNegativeReactions, Negative: map[string]int{"👎": 1}},
+		{Message: at(in(2, 0, ""), time.Hour), Reason: ReasonUnansweredQuestion},
+		{Message: at(out(3, ""), 2*time.Hour), Reason: ReasonNegativeReactions, Negative: map[string]int{"👎": 4}},
+		{Message: at(in(4, 0, ""), 3*time.Hour), Reason: ReasonUnansweredQuestion},
+	}
+	Prioritize(flags)
+
+	var ids []int
+	for _, f := range flags {
+		ids = append(ids, f.Message.ID)
+	}
+	if want := []int{4, 2, 3, 1}; !reflect.DeepEqual(ids, want) {
+		t.Errorf("Prioritize() order = %v, want %v", ids, want)
+	}
+}
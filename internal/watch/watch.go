@@ -0,0 +1,219 @@
+// Package watch keeps keyword watches: patterns checked against incoming messages,
+// with an alert for each hit at most once per cooldown.
+package watch
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/tolmachov/mcp-telegram/internal/statefile"
+)
+
+// DefaultCooldown is the minimum time between two alerts of the same watch.
+const DefaultCooldown = 10 * time.Minute
+
+// Alert actions: forward the matching message to Saved Messages, or send a
+// notification text with the chat, an excerpt and a link instead.
+const (
+	ActionForward = "forward"
+	ActionNotify  = "notify"
+)
+
+// Watch is a pattern to look for in incoming messages.
+type Watch struct {
+	ID            int           `json:"id"`
+	Pattern       string        `json:"pattern"`
+	Regex         bool          `json:"regex,omitempty"`          // Pattern is a regular expression rather than literal text
+	CaseSensitive bool          `json:"case_sensitive,omitempty"` // Otherwise letters match regardless of case, in any script
+	ChatIDs       []int64       `json:"chat_ids,omitempty"`       // When set, only these chats are watched
+	Action        string        `json:"action"`                   // ActionForward or ActionNotify
+	Cooldown      time.Duration `json:"cooldown"`
+	CreatedAt     time.Time     `json:"created_at"`
+
+	Hits         int       `json:"hits"`                    // Matching messages, including those within the cooldown
+	Alerts       int       `json:"alerts"`                  // Hits that were alerted
+	LastAlertAt  time.Time `json:"last_alert_at,omitzero"`  // Zero if never alerted
+	LastHitAt    time.Time `json:"last_hit_at,omitzero"`    // Zero if never hit
+	LastHitChat  int64     `json:"last_hit_chat,omitempty"` // Chat of the latest hit
+	LastHitMsgID int       `json:"last_hit_message_id,omitempty"`
+}
+
+// ErrInvalidPattern is returned for a regular expression that doesn't compile.
+var ErrInvalidPattern = errors.New("invalid pattern")
+
+// Compile returns the matcher for the watch's pattern. Literal patterns match anywhere
+// in the text; unless the watch is case sensitive, both kinds use Unicode case folding,
+// so "иванов" matches "Иванов" and "ИВАНОВ".
+func (w Watch) Compile() (*regexp.Regexp, error) {
+	expr := w.Pattern
+	if !w.Regex {
+		expr = regexp.QuoteMeta(expr)
+	}
+	if !w.CaseSensitive {
+		expr = "(?i)" + expr
+	}
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPattern, err)
+	}
+	return re, nil
+}
+
+// Watches reports whether chatID is within the watch's chat scope.
+func (w Watch) Watches(chatID int64) bool {
+	return len(w.ChatIDs) == 0 || slices.Contains(w.ChatIDs, chatID)
+}
+
+// ShouldAlert reports whether a hit at now is alerted, given when the watch last
+// alerted (zero if never).
+func ShouldAlert(lastAlert, now time.Time, cooldown time.Duration) bool {
+	if cooldown <= 0 {
+		cooldown = DefaultCooldown
+	}
+	return lastAlert.IsZero() || now.Sub(lastAlert) >= cooldown
+}
+
+// Match returns the watches among ws whose pattern occurs in text of a message in
+// chatID. compiled holds the matcher of each watch by ID.
+func Match(ws []Watch, compiled map[int]*regexp.Regexp, chatID int64, text string) []Watch {
+	var hits []Watch
+	for _, w := range ws {
+		re := compiled[w.ID]
+		if re != nil && w.Watches(chatID) && re.MatchString(text) {
+			hits = append(hits, w)
+		}
+	}
+	return hits
+}
+
+// state is the on-disk format of the store.
+type state struct {
+	NextID  int     `json:"next_id"`
+	Watches []Watch `json:"watches,omitempty"`
+}
+
+// DefaultStatePath returns the default keyword watch state file based on the OS.
+func DefaultStatePath() string {
+	return statefile.DefaultPath("watches.json")
+}
+
+// ErrNotFound is returned when removing a watch that doesn't exist.
+var ErrNotFound = errors.New("no keyword watch with this ID")
+
+// Store keeps keyword watches and their hit counts in a JSON file, so they survive
+// restarts. It is safe for concurrent use.
+type Store struct {
+	path string
+
+	mu       sync.Mutex
+	state    state
+	compiled map[int]*regexp.Regexp
+}
+
+// OpenStore loads the store from path; a missing file means no watches.
+func OpenStore(path string) (*Store, error) {
+	s := &Store{path: path, state: state{NextID: 1}, compiled: make(map[int]*regexp.Regexp)}
+	if err := statefile.Load(path, "keyword watch state", &s.state); err != nil {
+		return nil, err
+	}
+	if s.state.NextID < 1 {
+		s.state.NextID = 1
+	}
+	for _, w := range s.state.Watches {
+		// A pattern that no longer compiles stays listed, so it can be removed
+		if re, err := w.Compile(); err == nil {
+			s.compiled[w.ID] = re
+		}
+	}
+	return s, nil
+}
+
+// Add stores a new watch and returns it with its assigned ID.
+func (s *Store) Add(w Watch) (Watch, error) {
+	re, err := w.Compile()
+	if err != nil {
+		return Watch{}, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	w.ID = s.state.NextID
+	s.state.NextID++
+	s.state.Watches = append(s.state.Watches, w)
+	if err := s.save(); err != nil {
+		s.state.NextID--
+		s.state.Watches = s.state.Watches[:len(s.state.Watches)-1]
+		return Watch{}, err
+	}
+	s.compiled[w.ID] = re
+	return w, nil
+}
+
+// List returns all watches in the order they were added.
+func (s *Store) List() []Watch {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Clone(s.state.Watches)
+}
+
+// Remove deletes a watch.
+func (s *Store) Remove(id int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i := slices.IndexFunc(s.state.Watches, func(w Watch) bool { return w.ID == id })
+	if i < 0 {
+		return ErrNotFound
+	}
+	kept := s.state.Watches
+	s.state.Watches = slices.Delete(slices.Clone(kept), i, i+1)
+	if err := s.save(); err != nil {
+		s.state.Watches = kept
+		return err
+	}
+	delete(s.compiled, id)
+	return nil
+}
+
+// Hit checks a message against the watches, counts the hits and returns the watches
+// to alert about: those outside their cooldown. The alert is recorded before the
+// caller sends it, so concurrent updates can't alert twice.
+func (s *Store) Hit(chatID int64, msgID int, text string, now time.Time) ([]Watch, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	hits := Match(s.state.Watches, s.compiled, chatID, text)
+	if len(hits) == 0 {
+		return nil, nil
+	}
+
+	previous := slices.Clone(s.state.Watches)
+	var alerts []Watch
+	for _, hit := range hits {
+		w := &s.state.Watches[slices.IndexFunc(s.state.Watches, func(w Watch) bool { return w.ID == hit.ID })]
+		w.Hits++
+		w.LastHitAt = now
+		w.LastHitChat = chatID
+		w.LastHitMsgID = msgID
+		if ShouldAlert(w.LastAlertAt, now, w.Cooldown) {
+			w.Alerts++
+			w.LastAlertAt = now
+			alerts = append(alerts, *w)
+		}
+	}
+	if err := s.save(); err != nil {
+		s.state.Watches = previous
+		return nil, err
+	}
+	return alerts, nil
+}
+
+// save writes the state to disk atomically. The caller must hold s.mu.
+func (s *Store) save() error {
+	return statefile.Save(s.path, "keyword watch state", s.state)
+}
@@ -0,0 +1,151 @@
+package watch
+
+import (
+	"path/filepath"
+	"regexp"
+	"testing"
+	"time"
+)
+
+func TestCompile(t *testing.T) {
+	tests := []struct {
+		name  string
+		watch Watch
+		text  string
+		want  bool
+	}{
+		{"literal", Watch{Pattern: "invoice"}, "Here is the invoice for May", true},
+		{"literal folds case", Watch{Pattern: "invoice"}, "INVOICE attached", true},
+		{"literal within a word", Watch{Pattern: "invoice"}, "two invoices", true},
+		{"literal is not a regex", Watch{Pattern: "a.c"}, "abc", false},
+		{"literal special characters", Watch{Pattern: "a.c"}, "see a.c", true},
+		{"cyrillic folding", Watch{Pattern: "иванов"}, "Привет, ИВАНОВ!", true},
+		{"cyrillic folding of the pattern", Watch{Pattern: "Иванов"}, "иванову", true},
+		{"case sensitive", Watch{Pattern: "Иванов", CaseSensitive: true}, "иванов", false},
+		{"no match", Watch{Pattern: "invoice"}, "receipt", false},
+		{"regex", Watch{Pattern: `inv(oice)?\s*#\d+`, Regex: true}, "INV #42 paid", true},
+		{"regex no match", Watch{Pattern: `^invoice$`, Regex: true}, "the invoice", false},
+		{"regex cyrillic folding", Watch{Pattern: `счёт|счет`, Regex: true}, "СЧЁТ на оплату", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			re, err := tt.watch.Compile()
+			if err != nil {
+				t.Fatalf("Compile() error = %v", err)
+			}
+			if got := re.MatchString(tt.text); got != tt.want {
+				t.Errorf("MatchString(%q) = %v, want %v", tt.text, got, tt.want)
+			}
+		})
+	}
+
+	if _, err := (Watch{Pattern: "(", Regex: true}).Compile(); err == nil {
+		t.Error("Compile() of an invalid regex succeeded")
+	}
+}
+
+func TestMatchScope(t *testing.T) {
+	ws := []Watch{
+		{ID: 1, Pattern: "invoice"},
+		{ID: 2, Pattern: "invoice", ChatIDs: []int64{10}},
+		{ID: 3, Pattern: "receipt"},
+	}
+	compiled := make(map[int]*regexp.Regexp)
+	for _, w := range ws {
+		compiled[w.ID], _ = w.Compile()
+	}
+
+	ids := func(hits []Watch) []int {
+		var out []int
+		for _, w := range hits {
+			out = append(out, w.ID)
+		}
+		return out
+	}
+	if got := ids(Match(ws, compiled, 10, "invoice")); len(got) != 2 || got[0] != 1 || got[1] != 2 {
+		t.Errorf("Match(chat 10) = %v, want [1 2]", got)
+	}
+	if got := ids(Match(ws, compiled, 11, "invoice")); len(got) != 1 || got[0] != 1 {
+		t.Errorf("Match(chat 11) = %v, want [1]", got)
+	}
+}
+
+func TestShouldAlert(t *testing.T) {
+	now := time.Date(2024, 7, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name      string
+		lastAlert time.Time
+		cooldown  time.Duration
+		want      bool
+	}{
+		{"first hit", time.Time{}, time.Hour, true},
+		{"within cooldown", now.Add(-59 * time.Minute), time.Hour, false},
+		{"cooldown elapsed", now.Add(-time.Hour), time.Hour, true},
+		{"default cooldown", now.Add(-time.Minute), 0, false},
+		{"default cooldown elapsed", now.Add(-DefaultCooldown), 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ShouldAlert(tt.lastAlert, now, tt.cooldown); got != tt.want {
+				t.Errorf("ShouldAlert() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStoreHitPersists(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state", "watches.json")
+	now := time.Date(2024, 7, 1, 12, 0, 0, 0, time.UTC)
+
+	store, err := OpenStore(path)
+	if err != nil {
+		t.Fatalf("OpenStore: %v", err)
+	}
+	if _, err := store.Add(Watch{Pattern: "(", Regex: true}); err == nil {
+		t.Fatal("Add accepted an invalid regex")
+	}
+	w, err := store.Add(Watch{Pattern: "Invoice", Cooldown: time.Hour, CreatedAt: now})
+	if err != nil || w.ID != 1 {
+		t.Fatalf("Add = %+v, %v", w, err)
+	}
+
+	alerts, err := store.Hit(5, 100, "new invoice", now)
+	if err != nil || len(alerts) != 1 || alerts[0].ID != 1 {
+		t.Fatalf("Hit = %+v, %v", alerts, err)
+	}
+	if alerts, _ := store.Hit(5, 101, "another invoice", now.Add(time.Minute)); len(alerts) != 0 {
+		t.Errorf("Hit within cooldown alerted: %+v", alerts)
+	}
+	if alerts, _ := store.Hit(5, 102, "nothing here", now.Add(time.Minute)); alerts != nil {
+		t.Errorf("Hit without a match = %+v", alerts)
+	}
+
+	// Counts and the cooldown survive a restart
+	reopened, err := OpenStore(path)
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	got := reopened.List()
+	if len(got) != 1 || got[0].Hits != 2 || got[0].Alerts != 1 || got[0].LastHitMsgID != 101 {
+		t.Errorf("List() = %+v", got)
+	}
+	if alerts, _ := reopened.Hit(5, 103, "invoice", now.Add(30*time.Minute)); len(alerts) != 0 {
+		t.Errorf("Hit after restart within cooldown alerted: %+v", alerts)
+	}
+	if alerts, _ := reopened.Hit(5, 104, "invoice", now.Add(time.Hour)); len(alerts) != 1 {
+		t.Errorf("Hit after cooldown = %+v", alerts)
+	}
+
+	if err := reopened.Remove(1); err != nil {
+		t.Fatalf("Remove: %v", err)
+	}
+	if err := reopened.Remove(1); err != ErrNotFound {
+		t.Errorf("second Remove = %v, want ErrNotFound", err)
+	}
+	if alerts, _ := reopened.Hit(5, 105, "invoice", now.Add(2*time.Hour)); alerts != nil {
+		t.Errorf("Hit after removal = %+v", alerts)
+	}
+	if next, _ := reopened.Add(Watch{Pattern: "x"}); next.ID != 2 {
+		t.Errorf("ID after removal = %d, want 2", next.ID)
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"net"
 	"os"
 	"path/filepath"
 	"strings"
@@ -175,6 +176,109 @@ func TestListTools(t *testing.T) {
 	if len(toolsResult.Tools) == 0 {
 		t.Error("expected at least one tool")
 	}
+
+	listed := make(map[string]bool, len(toolsResult.Tools))
+	for _, tool := range toolsResult.Tools {
+		listed[tool.Name] = true
+	}
+	for _, name := range []string{"SendMessage", "ReplyToMessage", "EditMessage", "DeleteMessage", "ForwardMessage", "ScheduleMessage", "EditScheduledMessage", "SendScheduledNow", "GetMedia"} {
+		if !listed[name] {
+			t.Errorf("tool %s is not listed", name)
+		}
+	}
+}
+
+// setupHTTPClient starts the server with the http transport and a bearer token, and
+// connects a client to it once it is listening.
+func setupHTTPClient(t *testing.T) (*client.Client, context.Context, func()) {
+	t.Helper()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		cancel()
+		t.Fatalf("failed to find a free port: %v", err)
+	}
+	addr := listener.Addr().String()
+	_ = listener.Close()
+
+	const token = "integration-test-token"
+	serverCtx, serverCancel := context.WithCancel(ctx)
+	serverDone := make(chan error, 1)
+	go func() {
+		app := internal.New(strings.NewReader(""), io.Discard, io.Discard)
+		serverDone <- app.Run(serverCtx, []string{"mcp-telegram", "run", "--transport", "http", "--listen-addr", addr, "--http-token", token})
+	}()
+
+	c, err := client.NewStreamableHttpClient("http://"+addr+"/mcp",
+		transport.WithHTTPHeaders(map[string]string{"Authorization": "Bearer " + token}))
+	if err != nil {
+		serverCancel()
+		cancel()
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	cleanup := func() {
+		_ = c.Close()
+		serverCancel()
+		select {
+		case err := <-serverDone:
+			if err != nil && !errors.Is(err, context.Canceled) {
+				t.Errorf("server error: %v", err)
+			}
+		case <-time.After(15 * time.Second):
+			t.Error("server did not stop in time")
+		}
+		cancel()
+	}
+
+	initRequest := mcp.InitializeRequest{}
+	initRequest.Params.ProtocolVersion = mcp.LATEST_PROTOCOL_VERSION
+	initRequest.Params.ClientInfo = mcp.Implementation{
+		Name:    "mcp-telegram-test",
+		Version: "1.0.0",
+	}
+
+	// The server listens once it has connected to Telegram
+	for {
+		_, err := c.Initialize(ctx, initRequest)
+		if err == nil {
+			break
+		}
+		select {
+		case err := <-serverDone:
+			cancel()
+			t.Fatalf("server stopped: %v", err)
+		case <-ctx.Done():
+			cleanup()
+			t.Fatalf("failed to initialize: %v", err)
+		case <-time.After(500 * time.Millisecond):
+		}
+	}
+
+	return c, ctx, cleanup
+}
+
+func TestListToolsOverHTTP(t *testing.T) {
+	c, ctx, cleanup := setupHTTPClient(t)
+	defer cleanup()
+
+	toolsResult, err := c.ListTools(ctx, mcp.ListToolsRequest{})
+	if err != nil {
+		t.Fatalf("failed to list tools: %v", err)
+	}
+	t.Logf("Available tools over HTTP: %d", len(toolsResult.Tools))
+
+	listed := make(map[string]bool, len(toolsResult.Tools))
+	for _, tool := range toolsResult.Tools {
+		listed[tool.Name] = true
+	}
+	for _, name := range []string{"GetMe", "GetChats", "SendMessage"} {
+		if !listed[name] {
+			t.Errorf("tool %s is not listed", name)
+		}
+	}
 }
 
 func TestSearchChats(t *testing.T) {
@@ -595,3 +699,52 @@ func logResourceResult(t *testing.T, result *mcp.ReadResourceResult) {
 		}
 	}
 }
+
+// TestGetMediaFromOtherDC downloads media stored on a DC other than the account's,
+// which Telegram answers with FILE_MIGRATE_X before GetMedia retries on that DC.
+// TEST_OTHER_DC_MEDIA_URI is the URI of a photo in such a chat, e.g. a public channel
+// whose files live elsewhere.
+func TestGetMediaFromOtherDC(t *testing.T) {
+	mediaURI := os.Getenv("TEST_OTHER_DC_MEDIA_URI")
+	if mediaURI == "" {
+		t.Skip("TEST_OTHER_DC_MEDIA_URI not set")
+	}
+
+	c, ctx, cleanup := setupClient(t)
+	defer cleanup()
+
+	callRequest := mcp.CallToolRequest{}
+	callRequest.Params.Name = "GetMedia"
+	callRequest.Params.Arguments = map[string]any{
+		"uri": mediaURI,
+	}
+
+	t.Logf("Calling GetMedia with uri=%s", mediaURI)
+
+	result, err := c.CallTool(ctx, callRequest)
+	if err != nil {
+		t.Fatalf("failed to call GetMedia: %v", err)
+	}
+
+	if result.IsError {
+		logToolResult(t, result)
+		t.Fatalf("GetMedia returned error")
+	}
+
+	var hasImage bool
+	for _, content := range result.Content {
+		switch c := content.(type) {
+		case mcp.ImageContent:
+			hasImage = c.Data != ""
+			t.Logf("Received image: mimeType=%s, data length=%d bytes", c.MIMEType, len(c.Data))
+		case mcp.TextContent:
+			if strings.Contains(c.Text, "FILE_MIGRATE") {
+				t.Errorf("download wasn't retried on the file's DC: %s", c.Text)
+			}
+		}
+	}
+
+	if !hasImage {
+		t.Error("expected image content in result")
+	}
+}